@@ -1,8 +1,8 @@
 package main
 
 import (
+	internalai "Backend/internal/ai"
 	"Backend/internal/config"
-	"Backend/internal/openai"
 	"Backend/internal/repositories"
 	"Backend/internal/services"
 	"log"
@@ -18,9 +18,9 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	aiClient, err := openai.NewFromEnv("")
+	aiClient, err := internalai.NewRegistry().New(*config.LoadLLMConfig(), nil)
 	if err != nil {
-		log.Fatalf("Failed to initialize OpenAI client: %v", err)
+		log.Fatalf("Failed to initialize LLM client: %v", err)
 	}
 
 	crawlRepo := repositories.NewCrawlRepository(db)
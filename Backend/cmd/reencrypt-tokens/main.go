@@ -0,0 +1,89 @@
+// reencrypt-tokens は外部サービスのアクセストークンと TOTP シークレット（users.totp_secret）を
+// 現行鍵で暗号化し直すコマンド。
+//
+// 鍵ローテーション手順:
+//
+//	go run ./cmd/reencrypt-tokens -rotate   # 新しい鍵を追加して現行鍵に切り替え、全行を再暗号化
+//	go run ./cmd/reencrypt-tokens           # 平文・旧鍵の行のみ再暗号化（初回導入時の移行にも使う）
+//
+// 鍵ファイルは TOKEN_ENCRYPTION_KEY_FILE で指定する。-init で新規作成できる。
+// 旧鍵は復号に必要なため、全行の再暗号化が完了するまで鍵ファイルから削除しないこと。
+//
+// 稼働中のサーバーは再起動しなくてよい。知らない鍵IDの暗号文を受け取ると鍵ファイルを読み直し、
+// 現行鍵も1分ごとに読み直す。複数台構成では -rotate の前に各サーバーが読む鍵ファイルを
+// 同じ内容に揃えること（揃っていないサーバーは新しい鍵で暗号化された行を復号できない）。
+package main
+
+import (
+	"Backend/internal/config"
+	"Backend/internal/repositories"
+	"Backend/internal/secrets"
+	"flag"
+	"log"
+	"os"
+)
+
+func main() {
+	initKey := flag.Bool("init", false, "鍵ファイルを新規作成する")
+	rotate := flag.Bool("rotate", false, "新しい鍵を生成して現行鍵に切り替えてから再暗号化する")
+	dryRun := flag.Bool("dry-run", false, "更新せずに対象件数のみ表示する")
+	batchSize := flag.Int("batch", 100, "1回に読み込む行数")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	keyPath := os.Getenv("TOKEN_ENCRYPTION_KEY_FILE")
+	if keyPath == "" {
+		log.Fatal("TOKEN_ENCRYPTION_KEY_FILE is required")
+	}
+	if *initKey {
+		if err := secrets.GenerateKeyFile(keyPath); err != nil {
+			log.Fatalf("Failed to create key file: %v", err)
+		}
+		log.Printf("Created key file: %s", keyPath)
+	}
+
+	provider, err := secrets.NewLocalFileKeyProvider(keyPath)
+	if err != nil {
+		log.Fatalf("Failed to load key file: %v", err)
+	}
+	if *rotate && !*dryRun {
+		keyID, err := provider.Rotate()
+		if err != nil {
+			log.Fatalf("Failed to rotate key: %v", err)
+		}
+		log.Printf("Rotated to new key: %s", keyID)
+	}
+
+	db, err := config.ConnectDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	envelope := secrets.NewEnvelope(provider)
+	githubRepo := repositories.NewGitHubRepository(db)
+	githubRepo.SetEnvelope(envelope)
+	n, err := githubRepo.ReencryptAccessTokens(*batchSize, *dryRun)
+	if err != nil {
+		log.Fatalf("Re-encryption of GitHub access tokens failed after %d rows: %v", n, err)
+	}
+	report(*dryRun, n, "GitHub access tokens")
+
+	userRepo := repositories.NewUserRepository(db)
+	n, err = userRepo.ReencryptTOTPSecrets(envelope, *batchSize, *dryRun)
+	if err != nil {
+		log.Fatalf("Re-encryption of TOTP secrets failed after %d rows: %v", n, err)
+	}
+	report(*dryRun, n, "TOTP secrets")
+}
+
+func report(dryRun bool, n int, what string) {
+	if dryRun {
+		log.Printf("[dry-run] %d %s need re-encryption", n, what)
+		return
+	}
+	log.Printf("Re-encrypted %d %s", n, what)
+}
@@ -0,0 +1,65 @@
+// reprice-costs はモデル単価表（model_prices）の訂正を過去の api_call_logs・realtime_usage_logs の料金に反映するコマンド。
+//
+// 単価の訂正手順:
+//
+//	POST /api/admin/costs/pricing/rates で正しい単価を登録（適用開始日は訂正したい最初の日）
+//	go run ./cmd/reprice-costs -from 2025-06-01 -model gpt-5.2 -dry-run   # 件数と差額を確認
+//	go run ./cmd/reprice-costs -from 2025-06-01 -model gpt-5.2            # 料金を書き換える
+//
+// 各行はその発生日時に有効な単価で計算し直す。単価未登録のモデルの呼び出しは料金 0 になる。
+// 管理 API の POST /api/admin/costs/pricing/recalculate でも同じ処理を実行できる。
+package main
+
+import (
+	"Backend/internal/config"
+	"Backend/internal/repositories"
+	"Backend/internal/services"
+	"context"
+	"flag"
+	"log"
+	"time"
+)
+
+func main() {
+	from := flag.String("from", "", "再計算する期間の開始日（YYYY-MM-DD、UTC）")
+	to := flag.String("to", "", "再計算する期間の終了日（YYYY-MM-DD、UTC、この日を含まない。省略時は現在まで）")
+	model := flag.String("model", "", "対象のモデル名（前方一致。省略時は全モデル）")
+	dryRun := flag.Bool("dry-run", false, "更新せずに対象件数と差額のみ表示する")
+	batchSize := flag.Int("batch", 500, "1回に読み込む行数")
+	flag.Parse()
+
+	opts := services.RepricingOptions{Model: *model, DryRun: *dryRun, BatchSize: *batchSize}
+	var err error
+	if opts.From, err = time.Parse("2006-01-02", *from); err != nil {
+		log.Fatalf("-from must be YYYY-MM-DD: %v", err)
+	}
+	if *to != "" {
+		if opts.To, err = time.Parse("2006-01-02", *to); err != nil {
+			log.Fatalf("-to must be YYYY-MM-DD: %v", err)
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	db, err := config.ConnectDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	pricing := services.NewPricingService(repositories.NewModelPriceRepository(db))
+	repricing := services.NewCostRepricingService(pricing, repositories.NewAPICallLogRepository(db), repositories.NewRealtimeUsageRepository(db))
+	result, err := repricing.Recalculate(context.Background(), opts)
+	if err != nil {
+		log.Fatalf("Repricing failed: %v", err)
+	}
+	prefix := ""
+	if *dryRun {
+		prefix = "[dry-run] "
+	}
+	log.Printf("%sapi_call_logs: scanned=%d changed=%d unpriced=%d delta=$%.6f",
+		prefix, result.Calls.Scanned, result.Calls.Changed, result.Calls.Unpriced, result.Calls.DeltaUSD)
+	log.Printf("%srealtime_usage_logs: scanned=%d changed=%d unpriced=%d delta=$%.4f",
+		prefix, result.Realtime.Scanned, result.Realtime.Changed, result.Realtime.Unpriced, result.Realtime.DeltaUSD)
+}
@@ -1,24 +1,39 @@
 package main
 
 import (
+	"Backend/domain/ai"
+	internalai "Backend/internal/ai"
 	"Backend/internal/config"
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
 	"Backend/internal/models"
-	"Backend/internal/openai"
+	"Backend/internal/ratelimit"
 	"Backend/internal/repositories"
 	"Backend/internal/routes"
 	"Backend/internal/scraper"
+	"Backend/internal/secrets"
 	"Backend/internal/services"
+	"Backend/internal/services/prompts"
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 )
 
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		// フロントエンドからは OAuth の state Cookie を受け取るため資格情報付きで許可する
+		if origin := r.Header.Get("Origin"); origin != "" && origin == appOrigin() {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Add("Vary", "Origin")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "Retry-After, X-Request-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -29,6 +44,15 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// appOrigin はフロントエンドのオリジン（APP_URL、未設定なら http://localhost:3000）を返す
+func appOrigin() string {
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:3000"
+	}
+	return strings.TrimRight(appURL, "/")
+}
+
 // checkAnnotationFont はサーバー起動時に PDF アノテーション用フォントの存在を確認し、
 // 設定に問題がある場合は警告ログを出力する。
 // フォントが存在しない場合もサーバー起動は継続するが、PDF 注釈が劣化する旨を明示する。
@@ -77,12 +101,6 @@ func main() {
 	}
 	log.Println("Database seeding completed")
 
-	// OpenAI クライアント初期化
-	aiClient, err := openai.NewFromEnv("")
-	if err != nil {
-		log.Fatalf("Failed to initialize OpenAI client: %v", err)
-	}
-
 	// OAuth設定読み込み
 	oauthConfig := config.LoadOAuthConfig()
 
@@ -90,6 +108,8 @@ func main() {
 	// ユーザー・認証
 	userRepo := repositories.NewUserRepository(db)
 	pendingRegistrationRepo := repositories.NewPendingRegistrationRepository(db)
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(db)
+	userIdentityRepo := repositories.NewUserIdentityRepository(db)
 	// チャット・分析
 	questionWeightRepo := repositories.NewQuestionWeightRepository(db)
 	chatMessageRepo := repositories.NewChatMessageRepository(db)
@@ -123,6 +143,13 @@ func main() {
 	auditLogRepo := repositories.NewAuditLogRepository(db)
 	// GitHub連携
 	githubRepo := repositories.NewGitHubRepository(db)
+	tokenEnvelope, err := secrets.NewEnvelopeFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize token encryption: %v", err)
+	}
+	if tokenEnvelope != nil {
+		githubRepo.SetEnvelope(tokenEnvelope)
+	}
 	skillScoreRepo := repositories.NewSkillScoreRepository(db)
 	// 応募・選考ステータス
 	appStatusRepo := repositories.NewUserApplicationStatusRepository(db)
@@ -132,23 +159,90 @@ func main() {
 
 	// サービス層の初期化
 	emailService := services.NewEmailService()
-	apiCostService := services.NewAPICostService(apiCallLogRepo)
+	auditLogService := services.NewAuditLogService(auditLogRepo)
+	// モデル単価表（空なら初期値を登録）。api_call_logs・realtime_usage_logs の料金はこの単価で計算する
+	pricingService := services.NewPricingService(repositories.NewModelPriceRepository(db))
+	if seeded, err := pricingService.SeedDefaults(); err != nil {
+		log.Printf("Failed to seed model prices: %v", err)
+	} else if seeded > 0 {
+		log.Printf("Seeded %d model prices", seeded)
+	}
+	apiCostService := services.NewAPICostService(apiCallLogRepo, pricingService)
 	realtimeUsageService := services.NewRealtimeUsageService(realtimeUsageRepo, emailService)
-	// OpenAI APIコール時にトークン使用量をロギング
-	aiClient.OnUsage = func(model string, promptTokens, completionTokens int) {
-		apiCostService.LogCall(model, promptTokens, completionTokens)
+	realtimeUsageService.SetPricing(pricingService)
+	costRepricingService := services.NewCostRepricingService(pricingService, apiCallLogRepo, realtimeUsageRepo)
+	// LLM クライアント初期化（LLM_PROVIDER でプロバイダを切り替え、APIコール時にトークン使用量をロギング）
+	llmConfig := config.LoadLLMConfig()
+	aiClient, err := internalai.NewRegistry().New(*llmConfig, apiCostService.LogCall)
+	if err != nil {
+		log.Fatalf("Failed to initialize LLM client: %v", err)
+	}
+	// 冪等な LLM 呼び出しの応答キャッシュ（LLM_CACHE_STORE=memory / mysql / off）
+	var llmCacheStore internalai.CacheStore
+	switch llmConfig.CacheStore {
+	case "memory":
+		llmCacheStore = internalai.NewMemoryCacheStore(llmConfig.CacheSize)
+	case "mysql":
+		llmCacheStore = internalai.NewDBCacheStore(repositories.NewLLMResponseCacheRepository(db))
+	}
+	if llmCacheStore != nil {
+		llmCache := internalai.NewCachingAdapter(aiClient, llmCacheStore)
+		apiCostService.SetResponseCache(llmCache)
+		aiClient = llmCache
+	}
+	// 機能ごとのサーキットブレーカー（open の間はセカンダリのプロバイダに振り替え、未設定なら ai.ErrProviderUnavailable を返す）
+	var secondaryClient ai.LLMClient
+	if llmConfig.Secondary != nil {
+		secondaryClient, err = internalai.NewRegistry().New(*llmConfig.Secondary, apiCostService.LogCall)
+		if err != nil {
+			log.Fatalf("Failed to initialize secondary LLM client: %v", err)
+		}
+	}
+	llmBreaker := internalai.NewCircuitBreakerAdapter(aiClient, secondaryClient, internalai.BreakerConfig{
+		FailureThreshold: llmConfig.BreakerThreshold,
+		OpenTimeout:      llmConfig.BreakerOpenTimeout,
+	})
+	aiClient = llmBreaker
+	// LLM プロバイダーに送る前に個人情報を記号に置き換える（キャッシュ・カセットにも置き換え後のテキストだけが残る）
+	var piiRedactor *services.PIIRedactor
+	if os.Getenv("PII_REDACTION") != "off" {
+		piiRedactor = services.NewPIIRedactor(userRepo)
+		for _, feature := range services.PIIRedactionDisabledFeaturesFromEnv() {
+			piiRedactor.SetPolicy(feature, services.PIIPolicy{})
+		}
+		aiClient = internalai.NewRedactingAdapter(aiClient, piiRedactor)
+	}
+	// 利用者ごとの LLM 予算（超過したら拒否、またはテキスト生成を安価なモデルに格下げ）
+	llmBudgetService := services.NewLLMBudgetService(repositories.NewLLMUserBudgetRepository(db), apiCallLogRepo, services.LLMBudgetConfig{
+		DailyLimitUSD:   llmConfig.UserDailyBudgetUSD,
+		MonthlyLimitUSD: llmConfig.UserMonthlyBudgetUSD,
+		Action:          llmConfig.BudgetAction,
+		DowngradeModel:  llmConfig.BudgetDowngradeModel,
+	})
+	aiClient = internalai.NewBudgetAdapter(aiClient, llmBudgetService)
+	tokenService, err := services.NewTokenServiceFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize token service: %v", err)
 	}
 	authService := services.NewAuthService(userRepo, pendingRegistrationRepo, emailService)
 	authService.SetDB(db)
+	authService.SetTokenService(tokenService)
+	authService.SetLoginAttemptRepository(loginAttemptRepo)
+	authService.SetAuditLogService(auditLogService)
+	if tokenEnvelope != nil {
+		authService.SetEnvelope(tokenEnvelope)
+	}
 	skillScoreService := services.NewSkillScoreService(skillScoreRepo)
 	githubService := services.NewGitHubService(githubRepo, skillScoreService, aiClient)
-	oauthService := services.NewOAuthService(userRepo, oauthConfig, githubService)
+	oauthService := services.NewOAuthService(userRepo, userIdentityRepo, oauthConfig, githubService)
+	oauthService.SetTokenService(tokenService)
+	oauthService.SetAuditLogService(auditLogService)
+	oauthService.SetDB(db)
 	chatService := services.NewChatService(aiClient, questionWeightRepo, chatMessageRepo, userWeightScoreRepo, aiGeneratedQuestionRepo, predefinedQuestionRepo, jobCategoryRepo, userRepo, userEmbeddingRepo, jobEmbeddingRepo, phaseRepo, progressRepo, sessionValidationRepo, conversationContextRepo)
 	questionService := services.NewQuestionGeneratorService(aiClient, questionWeightRepo)
 	matchingService := services.NewMatchingService(userWeightScoreRepo, companyRepo, matchRepo)
 	resumeService := services.NewResumeService(resumeRepo, "storage/resumes", aiClient)
 	crawlService := services.NewCrawlService(crawlRepo, companyRepo, popularityRepo, aiClient)
-	auditLogService := services.NewAuditLogService(auditLogRepo)
 	analysisService := services.NewAnalysisScoringService(
 		userWeightScoreRepo,
 		chatMessageRepo,
@@ -162,22 +256,55 @@ func main() {
 	interviewService := services.NewInterviewService(interviewSessionRepo, interviewUtteranceRepo, interviewReportRepo, userRepo, emailService, aiClient, realtimeUsageService)
 	interviewService.StartWorker()
 
+	// プロンプトレジストリ（ファイルの既定版に管理画面で公開した版を上書きする）
+	promptRegistry := prompts.NewRegistry(repositories.NewPromptVersionRepository(db))
+	chatService.SetPromptRegistry(promptRegistry)
+	resumeService.SetPromptRegistry(promptRegistry)
+	crawlService.SetPromptRegistry(promptRegistry)
+	interviewService.SetPromptRegistry(promptRegistry)
+
+	// 入力ガード（利用者の入力を LLM に渡す前に、指示の上書き・過大な入力・不適切な内容を検出して記録する）
+	inputGuard := services.NewInputGuard(repositories.NewFlaggedInputRepository(db))
+	inputGuard.SetBlocklist(services.InputGuardBlocklistFromEnv())
+	if os.Getenv("INPUT_GUARD_CLASSIFIER") == "true" {
+		inputGuard.SetClassifier(aiClient, promptRegistry)
+	}
+	chatService.SetInputGuard(inputGuard)
+	resumeService.SetInputGuard(inputGuard)
+	resumeService.SetPIIRedactor(piiRedactor)
+	interviewService.SetInputGuard(inputGuard)
+
 	// クロス機能連携サービス（チャットスコア↔面接/職務経歴書レビュー）
 	crossFeatureService := services.NewCrossFeatureIntegrationService(userWeightScoreRepo)
 	interviewService.SetCrossFeatureService(crossFeatureService)
 	resumeService.SetCrossFeatureService(crossFeatureService)
 
+	// 企業・募集職種の意味検索（埋め込みは MySQL に保存し、メモリ上のインデックスを起動時に作り直す）
+	embeddingStore := services.NewEmbeddingStore(repositories.NewEntityEmbeddingRepository(db))
+	if err := embeddingStore.Load(); err != nil {
+		log.Printf("WARNING: 埋め込みインデックスの読み込みに失敗しました（意味検索は再作成まで空です）: %v", err)
+	}
+	semanticSearchService := services.NewSemanticSearchService(aiClient, embeddingStore, companyRepo)
+	if os.Getenv("SEMANTIC_INDEX_ON_STARTUP") == "true" {
+		if err := semanticSearchService.StartReindex(); err != nil {
+			log.Printf("WARNING: 埋め込みの再作成を開始できませんでした: %v", err)
+		}
+	}
+
 	// コントローラー層の初期化
 	authController := controllers.NewAuthController(authService)
 	oauthController := controllers.NewOAuthController(oauthService)
 	chatController := controllers.NewChatController(chatService, matchingService, analysisService, userRepo, emailService)
 	questionController := controllers.NewQuestionController(questionService)
 	relationController := controllers.NewCompanyRelationController(companyQueryRepo, aiClient)
+	relationController.SetSemanticSearchService(semanticSearchService)
 	adminCompanyController := controllers.NewAdminCompanyController(companyRepo, auditLogService, nil, aiClient)
+	adminCompanyController.SetSemanticSearchService(semanticSearchService)
 	adminCrawlController := controllers.NewAdminCrawlController(crawlService, auditLogService)
 	adminJobController := controllers.NewAdminJobController(companyRepo, jobCategoryRepo, graduateRepo, auditLogService)
-	adminUserController := controllers.NewAdminUserController(userRepo, auditLogService)
+	adminUserController := controllers.NewAdminUserController(userRepo, authService, auditLogService)
 	adminAuditController := controllers.NewAdminAuditController(auditLogService)
+	adminAuditController.SetInputGuard(inputGuard)
 	// gBizINFO 公式 API を使った企業データ収集パイプライン
 	// Mynavi・Rikunabi・CareerTasu スクレイパーは利用規約違反リスクのため削除 (#178)
 	gbizToken := os.Getenv("GBIZINFO_API_TOKEN")
@@ -198,39 +325,88 @@ func main() {
 	realtimeController := controllers.NewRealtimeController(interviewService, realtimeUsageService)
 	adminInterviewController := controllers.NewAdminInterviewController(interviewService, videoRepo, s3UploadService)
 	adminDashboardController := controllers.NewAdminDashboardController(userRepo, interviewSessionRepo, interviewReportRepo)
+	adminDashboardController.SetLLMBreaker(llmBreaker)
 	adminCostsController := controllers.NewAdminCostsController(apiCostService, realtimeUsageService)
+	adminCostsController.SetAuditLogService(auditLogService)
+	adminCostsController.SetBudgetService(llmBudgetService)
+	adminCostsController.SetPricingService(pricingService, costRepricingService)
 	profileRecalcService := services.NewProfileRecalculationService(profileRecalcRepo, companyRepo)
 	profileRecalcController := controllers.NewAdminProfileRecalculationController(profileRecalcService)
 	companyEntryController := controllers.NewCompanyEntryController(companyRepo, graduateRepo)
 	githubController := controllers.NewGitHubController(githubService, skillScoreService)
 	esRewriteController := controllers.NewESRewriteController(aiClient)
+	esRewriteController.SetPromptRegistry(promptRegistry)
+	esRewriteController.SetInputGuard(inputGuard)
 	scheduleRepo := repositories.NewScheduleRepository(db)
 	scheduleService := services.NewScheduleService(scheduleRepo)
 	scheduleController := controllers.NewScheduleController(scheduleService)
 	esReviewController := controllers.NewESReviewController()
+	esReviewController.SetInputGuard(inputGuard)
+	esReviewController.SetPIIRedactor(piiRedactor)
 	appService := services.NewApplicationService(appStatusRepo, matchRepo)
 	appController := controllers.NewApplicationController(appService)
+
+	// 教員・クラス管理
+	cohortRepo := repositories.NewCohortRepository(db)
+	cohortService := services.NewCohortService(cohortRepo, userRepo, emailService, interviewService, chatService, analysisService, appService)
+	teacherController := controllers.NewTeacherController(cohortService, auditLogService)
 	integratedProfileController := controllers.NewIntegratedProfileController(crossFeatureService, interviewSessionRepo, resumeRepo)
 	scoreValidationRepo := repositories.NewScoreValidationRepository(db)
 	scoreValidationService := services.NewScoreValidationService(scoreValidationRepo)
+	// セッション開始時に実施中の A/B テストへ割り当てる
+	chatService.SetExperimentService(scoreValidationService)
+	matchingService.SetCalibrationSource(scoreValidationService)
+	analysisService.SetCalibrationSource(scoreValidationService)
+	// 回答の加点ルールと AI 質問テンプレートをチャットに適用する
+	questionRuleRepo := repositories.NewQuestionRuleRepository(db)
+	questionRuleService := services.NewQuestionRuleService(questionRuleRepo, chatMessageRepo)
+	chatService.SetQuestionRuleService(questionRuleService)
 	scoreValidationController := controllers.NewAdminScoreValidationController(scoreValidationService)
+	scoreValidationController.SetAuditLogService(auditLogService)
 	collectiveInsightRepo := repositories.NewCollectiveInsightRepository(db)
 	collectiveInsightService := services.NewCollectiveInsightService(collectiveInsightRepo, userWeightScoreRepo)
 	collectiveInsightController := controllers.NewCollectiveInsightController(collectiveInsightService)
 
+	// 個人データの開示・削除
+	personalDataService := services.NewPersonalDataService(db, repositories.NewPersonalDataJobRepository(db), os.Getenv("PERSONAL_DATA_EXPORT_DIR"))
+	personalDataService.SetAuditLogService(auditLogService)
+	objectStore, err := services.NewS3ObjectStoreFromEnv(context.Background())
+	if err != nil {
+		log.Printf("S3 object store not available (stored files are skipped in export/erasure): %v", err)
+	} else if objectStore != nil {
+		personalDataService.SetObjectStore(objectStore)
+	}
+	personalDataService.ResumePendingJobs()
+	privacyController := controllers.NewPrivacyController(personalDataService)
+
+	// レート制限（既定値に管理画面での変更内容を上書きする）
+	rateLimiter := ratelimit.NewLimiter()
+	rateLimitService := services.NewRateLimitService(repositories.NewRateLimitSettingRepository(db), rateLimiter)
+	if err := rateLimitService.LoadOverrides(); err != nil {
+		log.Printf("WARNING: レート制限設定の読み込みに失敗しました（既定値で起動します）: %v", err)
+	}
+	rateLimitController := controllers.NewAdminRateLimitController(rateLimitService, auditLogService)
+	promptService := services.NewPromptService(repositories.NewPromptVersionRepository(db), promptRegistry)
+	promptController := controllers.NewAdminPromptController(promptService, auditLogService)
+	questionRuleController := controllers.NewAdminQuestionRuleController(questionRuleService, auditLogService)
+
 	// ルーティング設定
-	routes.SetupAuthRoutes(authController, oauthController)
-	routes.SetupChatRoutes(chatController, questionController)
-	routes.SetupCompanyRoutes(relationController)
-	routes.SetupAdminRoutes(adminCompanyController, adminCrawlController, adminJobController, adminUserController, adminAuditController, adminCompanyGraphController, adminInterviewController, adminDashboardController, adminCostsController, profileRecalcController, scoreValidationController, collectiveInsightController, userRepo)
-	routes.SetupResumeRoutes(resumeController)
-	routes.SetupInterviewRoutes(interviewController, realtimeController)
-	routes.SetupGitHubRoutes(githubController)
-	routes.SetupESRoutes(esRewriteController, esReviewController)
-	routes.SetupScheduleRoutes(scheduleController)
-	routes.SetupApplicationRoutes(appController)
-	routes.SetupUserRoutes(integratedProfileController)
-	routes.SetupCollectiveInsightRoutes(collectiveInsightController)
+	authenticator := middleware.NewAuthenticator(tokenService, userRepo)
+	rateLimit := middleware.NewRateLimit(rateLimiter)
+	routes.SetupAuthRoutes(authController, oauthController, authenticator)
+	routes.SetupChatRoutes(chatController, questionController, authenticator, rateLimit)
+	routes.SetupCompanyRoutes(relationController, authenticator, rateLimit)
+	routes.SetupAdminRoutes(adminCompanyController, adminCrawlController, adminJobController, adminUserController, adminAuditController, adminCompanyGraphController, adminInterviewController, adminDashboardController, adminCostsController, profileRecalcController, scoreValidationController, collectiveInsightController, rateLimitController, promptController, questionRuleController, authenticator)
+	routes.SetupResumeRoutes(resumeController, authenticator, rateLimit)
+	routes.SetupInterviewRoutes(interviewController, realtimeController, authenticator, rateLimit)
+	routes.SetupGitHubRoutes(githubController, authenticator, rateLimit)
+	routes.SetupESRoutes(esRewriteController, esReviewController, authenticator, rateLimit)
+	routes.SetupScheduleRoutes(scheduleController, authenticator)
+	routes.SetupApplicationRoutes(appController, authenticator)
+	routes.SetupUserRoutes(integratedProfileController, authenticator)
+	routes.SetupCollectiveInsightRoutes(collectiveInsightController, authenticator)
+	routes.SetupTeacherRoutes(teacherController, authenticator)
+	routes.SetupPrivacyRoutes(privacyController, authenticator)
 	http.HandleFunc("/api/company-entry", companyEntryController.Submit)
 
 	go crawlService.StartScheduler()
@@ -238,13 +414,9 @@ func main() {
 	// ヘルスチェックエンドポイント
 	// /healthz は ECS ターゲットグループ・ALB・Kubernetes の標準パス
 	// /health は後方互換のため維持
-	healthHandler := func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ok"}`))
-	}
-	http.HandleFunc("/health", healthHandler)
-	http.HandleFunc("/healthz", healthHandler)
+	healthController := controllers.NewHealthController(llmBreaker)
+	http.HandleFunc("/health", healthController.Health)
+	http.HandleFunc("/healthz", healthController.Health)
 
 	// サーバー起動
 	port := cfg.ServerPort
@@ -253,7 +425,7 @@ func main() {
 	}
 
 	log.Printf("Starting server on port %s...", port)
-	if err := http.ListenAndServe(":"+port, corsMiddleware(http.DefaultServeMux)); err != nil {
+	if err := http.ListenAndServe(":"+port, corsMiddleware(middleware.RequestID(http.DefaultServeMux))); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
@@ -0,0 +1,29 @@
+package ai
+
+import "time"
+
+// サーキットブレーカーで状態を分ける機能の単位
+const (
+	CapabilityText      = "text"      // GenerateText / GenerateJSON / Chat / WebSearch
+	CapabilityEmbedding = "embedding" // GenerateEmbedding
+	CapabilityAudio     = "audio"     // TranscribeAudio / SynthesizeSpeech
+	CapabilityRealtime  = "realtime"  // CreateRealtimeSession
+)
+
+// サーキットブレーカーの状態
+const (
+	BreakerClosed   = "closed"    // 通常。プライマリのプロバイダを使う
+	BreakerOpen     = "open"      // 障害中。フォールバック・セカンダリに振り替える
+	BreakerHalfOpen = "half_open" // 復旧確認中。1 件だけプライマリで試す
+)
+
+// BreakerStatus は機能ごとのサーキットブレーカーの状態（ヘルスチェック・管理画面用）。
+type BreakerStatus struct {
+	Capability          string     `json:"capability"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Trips               int64      `json:"trips"` // プロセス起動以降に open になった回数
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+}
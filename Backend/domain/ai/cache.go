@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// CachePolicy は応答キャッシュの設定。Scope は呼び出し箇所の名前で、統計と削除の単位になる。
+type CachePolicy struct {
+	Scope string
+	TTL   time.Duration
+}
+
+type cachePolicyKey struct{}
+
+// WithCache は ctx を使った LLM 呼び出しを応答キャッシュの対象にする。
+// 同じ入力に同じ応答を返してよい（冪等な）呼び出し箇所でだけ使う。TTL が 0 以下ならキャッシュしない。
+func WithCache(ctx context.Context, scope string, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, cachePolicyKey{}, CachePolicy{Scope: scope, TTL: ttl})
+}
+
+// CachePolicyFrom は ctx に設定されたキャッシュ設定を返す。
+func CachePolicyFrom(ctx context.Context) (CachePolicy, bool) {
+	p, ok := ctx.Value(cachePolicyKey{}).(CachePolicy)
+	if !ok || p.TTL <= 0 {
+		return CachePolicy{}, false
+	}
+	return p, true
+}
+
+// CacheStat は呼び出し箇所ごとのキャッシュのヒット・ミス数（プロセス起動以降）。
+type CacheStat struct {
+	Scope   string  `json:"scope"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
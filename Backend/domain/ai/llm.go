@@ -3,15 +3,90 @@
 // OpenAI・Claude・Gemini 等の切り替えをアダプター実装で吸収する。
 package ai
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnsupported はプロバイダがその操作に対応していないことを表す。
+var ErrUnsupported = errors.New("operation not supported by this llm provider")
+
+// ErrProviderUnavailable は LLM プロバイダが障害中で、振り替え先のプロバイダも設定されていないことを表す。
+var ErrProviderUnavailable = errors.New("llm provider is temporarily unavailable")
+
+// メッセージの役割
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message は会話履歴の1発話。
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// CallOptions は1回の呼び出しの生成パラメータ。ゼロ値の項目はプロバイダの既定値を使う。
+type CallOptions struct {
+	Model       string
+	Temperature *float32
+	MaxTokens   int
+	// ResponseSchema は GenerateJSON の応答形式（nil なら JSON モード）
+	ResponseSchema *ResponseSchema
+	// RepairRetries は GenerateStructured の再生成の回数（nil なら DefaultRepairRetries）
+	RepairRetries *int
+}
+
+// CallOption は CallOptions を設定する関数。
+type CallOption func(*CallOptions)
+
+// WithModel は呼び出しに使うモデルを指定する（空文字は既定モデル）。
+func WithModel(model string) CallOption {
+	return func(o *CallOptions) { o.Model = model }
+}
+
+// WithTemperature は温度パラメータを指定する。
+func WithTemperature(temperature float32) CallOption {
+	return func(o *CallOptions) { o.Temperature = &temperature }
+}
+
+// WithMaxTokens は出力トークン数の上限を指定する。
+func WithMaxTokens(maxTokens int) CallOption {
+	return func(o *CallOptions) { o.MaxTokens = maxTokens }
+}
+
+// ApplyCallOptions は CallOption を適用した CallOptions を返す（アダプター実装用）。
+func ApplyCallOptions(opts ...CallOption) CallOptions {
+	var o CallOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// TemperatureOr は温度が指定されていればその値を、なければ def を返す。
+func (o CallOptions) TemperatureOr(def float32) float32 {
+	if o.Temperature == nil {
+		return def
+	}
+	return *o.Temperature
+}
 
 // TextClient はテキスト生成の抽象インターフェース。
 type TextClient interface {
 	// GenerateText はシステムプロンプトとユーザープロンプトからテキストを生成する。
-	GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error)
+	// systemPrompt が空の場合はユーザープロンプトのみで生成する。
+	GenerateText(ctx context.Context, systemPrompt, userPrompt string, opts ...CallOption) (string, error)
 
-	// GenerateJSON は JSON 文字列を返すテキスト生成（構造化出力用）。
-	GenerateJSON(ctx context.Context, systemPrompt, userPrompt string) (string, error)
+	// GenerateJSON は JSON 文字列を返すテキスト生成（JSON モード、構造化出力用）。
+	// WithResponseSchema があればその JSON Schema を応答形式として指定する。型付きで受け取る場合は GenerateStructured を使う。
+	GenerateJSON(ctx context.Context, systemPrompt, userPrompt string, opts ...CallOption) (string, error)
+
+	// Chat は会話履歴から次のアシスタント発話を生成する。
+	Chat(ctx context.Context, messages []Message, opts ...CallOption) (string, error)
 }
 
 // EmbeddingClient はベクトル埋め込みの抽象インターフェース。
@@ -24,12 +99,48 @@ type EmbeddingClient interface {
 type AudioClient interface {
 	// TranscribeAudio は音声バイト列をテキストに変換する（Whisper 等）。
 	TranscribeAudio(ctx context.Context, audio []byte, filename string) (string, error)
+
+	// SynthesizeSpeech はテキストを音声（mp3）に変換する。voice が空の場合は既定の声を使う。
+	SynthesizeSpeech(ctx context.Context, text, voice string) ([]byte, error)
+}
+
+// SearchClient は Web 検索付き生成の抽象インターフェース。
+type SearchClient interface {
+	// WebSearch は Web 検索結果を踏まえた回答テキストを返す。
+	WebSearch(ctx context.Context, query string) (string, error)
+}
+
+// RealtimeSessionRequest はリアルタイム音声セッションの設定。
+type RealtimeSessionRequest struct {
+	Model                   string
+	Modalities              []string
+	Voice                   string
+	Instructions            string
+	InputAudioTranscription map[string]interface{}
+	TurnDetection           map[string]interface{}
+	MaxResponseOutputTokens interface{}
+}
+
+// RealtimeSession はブラウザに渡すリアルタイム音声セッションの一時クレデンシャル。
+type RealtimeSession struct {
+	ID           string
+	ClientSecret string
+	ExpiresAt    int64
+}
+
+// RealtimeClient はリアルタイム音声セッションの抽象インターフェース。
+type RealtimeClient interface {
+	// CreateRealtimeSession はセッションを作成し、クライアント用の一時クレデンシャルを返す。
+	CreateRealtimeSession(ctx context.Context, req RealtimeSessionRequest) (*RealtimeSession, error)
 }
 
 // LLMClient は全 AI 操作を統合したインターフェース。
 // 大半のユースケースはこれ一つを依存注入すれば足りる。
+// プロバイダが対応しない操作は ErrUnsupported を返す。
 type LLMClient interface {
 	TextClient
 	EmbeddingClient
 	AudioClient
+	SearchClient
+	RealtimeClient
 }
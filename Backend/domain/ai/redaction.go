@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Redaction は LLM に送るテキストで置き換えた値（個人情報など）と置き換え後の記号（例: [NAME_1]）の対応。
+// 同じ値は 1 回の呼び出しの中で常に同じ記号になる。
+type Redaction struct {
+	byValue map[string]string
+	values  map[string]string
+	counts  map[string]int
+}
+
+func NewRedaction() *Redaction {
+	return &Redaction{byValue: map[string]string{}, values: map[string]string{}, counts: map[string]int{}}
+}
+
+// Placeholder は value を置き換える記号を返す（初出なら tag の連番で作る）。
+func (r *Redaction) Placeholder(tag, value string) string {
+	if p, ok := r.byValue[value]; ok {
+		return p
+	}
+	r.counts[tag]++
+	p := fmt.Sprintf("[%s_%d]", tag, r.counts[tag])
+	r.byValue[value] = p
+	r.values[p] = value
+	return p
+}
+
+// Len は置き換えた値の数を返す（nil なら 0）。
+func (r *Redaction) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.values)
+}
+
+// Values は記号と元の値の対応を返す。
+func (r *Redaction) Values() map[string]string {
+	out := make(map[string]string, r.Len())
+	if r != nil {
+		for p, v := range r.values {
+			out[p] = v
+		}
+	}
+	return out
+}
+
+// Restore は text の記号を元の値に戻す。
+func (r *Redaction) Restore(text string) string {
+	if r.Len() == 0 {
+		return text
+	}
+	return r.replacer(func(v string) string { return v }).Replace(text)
+}
+
+// RestoreJSON は JSON テキストの文字列の中の記号を、JSON の文字列としてエスケープした元の値に戻す。
+func (r *Redaction) RestoreJSON(text string) string {
+	if r.Len() == 0 {
+		return text
+	}
+	return r.replacer(func(v string) string {
+		quoted, _ := json.Marshal(v)
+		return string(quoted[1 : len(quoted)-1])
+	}).Replace(text)
+}
+
+func (r *Redaction) replacer(encode func(string) string) *strings.Replacer {
+	pairs := make([]string, 0, len(r.values)*2)
+	for p, v := range r.values {
+		pairs = append(pairs, p, encode(v))
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// maxPlaceholderLen はストリーミングで記号の途中とみなして送らずに待つ長さの上限
+const maxPlaceholderLen = 24
+
+// StreamRestorer はストリーミングの差分の記号を元の値に戻してから emit に渡す。
+// 差分の境目で分かれた記号は、閉じ括弧が届くまで送らずに待つ。
+type StreamRestorer struct {
+	redaction *Redaction
+	emit      func(delta string)
+	pending   string
+}
+
+func NewStreamRestorer(redaction *Redaction, emit func(delta string)) *StreamRestorer {
+	return &StreamRestorer{redaction: redaction, emit: emit}
+}
+
+// Write は差分を受け取り、記号の途中でない部分を元に戻して送る。
+func (s *StreamRestorer) Write(delta string) {
+	s.pending += delta
+	cut := len(s.pending)
+	if open := strings.LastIndex(s.pending, "["); open >= 0 && !strings.Contains(s.pending[open:], "]") && len(s.pending)-open < maxPlaceholderLen {
+		cut = open
+	}
+	if cut == 0 {
+		return
+	}
+	out := s.redaction.Restore(s.pending[:cut])
+	s.pending = s.pending[cut:]
+	s.emit(out)
+}
+
+// Flush は待っている残りを送る（ストリームの終わりに呼ぶ）。
+func (s *StreamRestorer) Flush() {
+	if s.pending == "" {
+		return
+	}
+	out := s.redaction.Restore(s.pending)
+	s.pending = ""
+	s.emit(out)
+}
@@ -0,0 +1,244 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema は構造化出力に使う JSON Schema のサブセット。
+type Schema struct {
+	Type                 string             `json:"-"`
+	Nullable             bool               `json:"-"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false または *Schema（map のみ）
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+
+	optional map[string]bool // omitempty のプロパティ（null・欠落を許す）
+}
+
+// MarshalJSON は Nullable の場合に type を ["型", "null"] として出力する。
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	var typ interface{} = s.Type
+	if s.Nullable {
+		typ = []string{s.Type, "null"}
+	}
+	return json.Marshal(struct {
+		Type interface{} `json:"type"`
+		plain
+	}{typ, plain(s)})
+}
+
+// SchemaFor は Go の型から JSON Schema を導く。
+// 構造体のフィールドは json タグの名前を使い、次のタグで制約を付けられる:
+// description:"説明" / enum:"a,b,c" / minimum:"0" / maximum:"5" / minItems:"1"。
+// omitempty のフィールドは省略可（strict モードでは null 許容）になる。
+// strict は map を含まず OpenAI の strict モードで送れるかどうか。
+func SchemaFor(t reflect.Type) (schema *Schema, strict bool, err error) {
+	strict = true
+	schema, err = reflectSchema(t, &strict)
+	return schema, strict, err
+}
+
+func reflectSchema(t reflect.Type, strict *bool) (*Schema, error) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		s, err := reflectSchema(t.Elem(), strict)
+		if err != nil {
+			return nil, err
+		}
+		s.Nullable = true
+		return s, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := reflectSchema(t.Elem(), strict)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("schema: map key must be string, got %s", t.Key())
+		}
+		values, err := reflectSchema(t.Elem(), strict)
+		if err != nil {
+			return nil, err
+		}
+		*strict = false
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return reflectObject(t, strict)
+	default:
+		return nil, fmt.Errorf("schema: unsupported type %s", t)
+	}
+}
+
+func reflectObject(t reflect.Type, strict *bool) (*Schema, error) {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false, optional: map[string]bool{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		prop, err := reflectSchema(field.Type, strict)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		prop.Description = field.Tag.Get("description")
+		if enum := field.Tag.Get("enum"); enum != "" {
+			prop.Enum = strings.Split(enum, ",")
+		}
+		if v, err := strconv.ParseFloat(field.Tag.Get("minimum"), 64); err == nil {
+			prop.Minimum = &v
+		}
+		if v, err := strconv.ParseFloat(field.Tag.Get("maximum"), 64); err == nil {
+			prop.Maximum = &v
+		}
+		if v, err := strconv.Atoi(field.Tag.Get("minItems")); err == nil {
+			prop.MinItems = &v
+		}
+		if strings.Contains(opts, "omitempty") {
+			s.optional[name] = true
+			prop.Nullable = true
+		}
+		s.Properties[name] = prop
+		s.Required = append(s.Required, name)
+	}
+	return s, nil
+}
+
+// Validate は JSON をデコードした値（map[string]interface{} など）をスキーマで検証し、違反をパス付きで返す。
+func (s *Schema) Validate(v interface{}) []string {
+	var errs []string
+	s.validate("$", v, &errs)
+	return errs
+}
+
+func (s *Schema) validate(path string, v interface{}, errs *[]string) {
+	if v == nil {
+		if !s.Nullable {
+			*errs = append(*errs, fmt.Sprintf("%s: must not be null (expected %s)", path, s.Type))
+		}
+		return
+	}
+	switch s.Type {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected string, got %s", path, jsonKind(v)))
+			return
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			*errs = append(*errs, fmt.Sprintf("%s: %q is not one of %s", path, str, strings.Join(s.Enum, ", ")))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected boolean, got %s", path, jsonKind(v)))
+		}
+	case "integer", "number":
+		n, ok := v.(float64)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected %s, got %s", path, s.Type, jsonKind(v)))
+			return
+		}
+		if s.Type == "integer" && n != float64(int64(n)) {
+			*errs = append(*errs, fmt.Sprintf("%s: expected integer, got %v", path, n))
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			*errs = append(*errs, fmt.Sprintf("%s: %v is less than minimum %v", path, n, *s.Minimum))
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			*errs = append(*errs, fmt.Sprintf("%s: %v is greater than maximum %v", path, n, *s.Maximum))
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected array, got %s", path, jsonKind(v)))
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			*errs = append(*errs, fmt.Sprintf("%s: expected at least %d items, got %d", path, *s.MinItems, len(arr)))
+		}
+		for i, item := range arr {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected object, got %s", path, jsonKind(v)))
+			return
+		}
+		if values, ok := s.AdditionalProperties.(*Schema); ok {
+			keys := make([]string, 0, len(obj))
+			for k := range obj {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				values.validate(path+"."+k, obj[k], errs)
+			}
+			return
+		}
+		for _, name := range s.Required {
+			val, present := obj[name]
+			if !present {
+				if !s.optional[name] {
+					*errs = append(*errs, fmt.Sprintf("%s.%s: required property is missing", path, name))
+				}
+				continue
+			}
+			s.Properties[name].validate(path+"."+name, val, errs)
+		}
+	}
+}
+
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
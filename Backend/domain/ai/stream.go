@@ -0,0 +1,21 @@
+package ai
+
+import "context"
+
+type textStreamKey struct{}
+
+// WithTextStream は ctx でのテキスト生成（GenerateText）を逐次生成にし、生成途中の差分を onDelta に渡させる。
+// ストリーミングに対応しないプロバイダや、キャッシュ・フォールバックで応答した場合は onDelta は呼ばれず、戻り値の全文だけが返る。
+// 再試行した場合は失敗した試行の差分も渡るため、確定した文章には戻り値を使うこと。
+func WithTextStream(ctx context.Context, onDelta func(delta string)) context.Context {
+	return context.WithValue(ctx, textStreamKey{}, onDelta)
+}
+
+// TextStreamFrom は ctx に設定された差分の受け取り先を返す（なければ nil）。アダプター実装用。
+func TextStreamFrom(ctx context.Context) func(delta string) {
+	if ctx == nil {
+		return nil
+	}
+	onDelta, _ := ctx.Value(textStreamKey{}).(func(delta string))
+	return onDelta
+}
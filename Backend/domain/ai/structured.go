@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidStructuredOutput は修正依頼を繰り返してもスキーマに合う出力が得られなかったことを表す。
+var ErrInvalidStructuredOutput = errors.New("llm output does not match the expected schema")
+
+// DefaultRepairRetries は GenerateStructured が検証エラーを添えて再生成を依頼する既定の回数。
+const DefaultRepairRetries = 2
+
+// ResponseSchema は GenerateJSON に応答形式として渡す JSON Schema。
+type ResponseSchema struct {
+	Name   string
+	Schema *Schema
+	Strict bool
+}
+
+// WithResponseSchema は応答形式の JSON Schema を指定する（対応しないプロバイダは JSON モードとして扱う）。
+func WithResponseSchema(schema *ResponseSchema) CallOption {
+	return func(o *CallOptions) { o.ResponseSchema = schema }
+}
+
+// WithRepairRetries は GenerateStructured の再生成の回数を指定する（0 なら再生成しない）。
+func WithRepairRetries(n int) CallOption {
+	return func(o *CallOptions) { o.RepairRetries = &n }
+}
+
+// GenerateStructured は T から導いた JSON Schema を応答形式に指定して JSON を生成し、
+// スキーマで検証してから T に読み込む。検証に失敗した場合は前回の出力と検証エラーを添えて
+// 再生成を依頼し、それでも合わなければ ErrInvalidStructuredOutput を返す。
+func GenerateStructured[T any](ctx context.Context, client TextClient, systemPrompt, userPrompt string, opts ...CallOption) (*T, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	schema, strict, err := SchemaFor(t)
+	if err != nil {
+		return nil, err
+	}
+	retries := DefaultRepairRetries
+	if o := ApplyCallOptions(opts...); o.RepairRetries != nil {
+		retries = *o.RepairRetries
+	}
+	callOpts := append(append([]CallOption{}, opts...), WithResponseSchema(&ResponseSchema{Name: schemaName(t), Schema: schema, Strict: strict}))
+
+	prompt := userPrompt
+	var problems []string
+	for attempt := 0; attempt <= retries; attempt++ {
+		raw, err := client.GenerateJSON(ctx, systemPrompt, prompt, callOpts...)
+		if err != nil {
+			return nil, err
+		}
+		var out T
+		if problems = decodeStructured(raw, schema, &out); len(problems) == 0 {
+			return &out, nil
+		}
+		prompt = repairPrompt(userPrompt, raw, problems)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidStructuredOutput, strings.Join(problems, "; "))
+}
+
+// decodeStructured は raw を検証して out に読み込み、問題点を返す（問題がなければ nil）。
+func decodeStructured(raw string, schema *Schema, out interface{}) []string {
+	cleaned := ExtractJSONObject(raw)
+	if cleaned == "" {
+		return []string{"response is empty"}
+	}
+	var generic interface{}
+	if err := json.Unmarshal([]byte(cleaned), &generic); err != nil {
+		return []string{"response is not valid JSON: " + err.Error()}
+	}
+	if problems := schema.Validate(generic); len(problems) > 0 {
+		return problems
+	}
+	if err := json.Unmarshal([]byte(cleaned), out); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+func repairPrompt(userPrompt, raw string, problems []string) string {
+	var b strings.Builder
+	b.WriteString(userPrompt)
+	b.WriteString("\n\n## 前回の出力\n")
+	b.WriteString(raw)
+	b.WriteString("\n\n## 前回の出力の問題点\n")
+	for _, p := range problems {
+		b.WriteString("- ")
+		b.WriteString(p)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n問題点を修正し、指定の形式に従った JSON のみを出力してください。")
+	return b.String()
+}
+
+var schemaNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+func schemaName(t reflect.Type) string {
+	name := schemaNamePattern.ReplaceAllString(t.Name(), "")
+	if name == "" {
+		return "response"
+	}
+	return name
+}
+
+// ExtractJSONObject はマークダウンのコードフェンスや前後の説明文を除き、最も外側の JSON オブジェクトを取り出す。
+func ExtractJSONObject(raw string) string {
+	s := strings.TrimSpace(raw)
+	if start := strings.Index(s, "{"); start > 0 {
+		s = s[start:]
+	}
+	if end := strings.LastIndex(s, "}"); end >= 0 && end < len(s)-1 {
+		s = s[:end+1]
+	}
+	return s
+}
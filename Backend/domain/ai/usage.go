@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrBudgetExceeded は呼び出し元ユーザーの LLM 予算（日次・月次）を超えたため呼び出しを拒否したことを表す。
+var ErrBudgetExceeded = errors.New("llm budget exceeded")
+
+// コストを集計する機能の名前（api_call_logs.feature）
+const (
+	FeatureChat            = "chat"
+	FeatureInterview       = "interview"
+	FeatureInterviewReport = "interview_report"
+	FeatureResumeReview    = "resume_review"
+	FeatureESRewrite       = "es_rewrite"
+	FeatureCrawl           = "crawl"
+	FeatureGitHubSummary   = "github_summary"
+	FeatureSemanticSearch  = "semantic_search"
+)
+
+// CallContext は LLM 呼び出しの発生元。使用量フックに渡り、コストをユーザー・機能・リクエストに紐づける。
+type CallContext struct {
+	UserID    uint   // 0 なら利用者に紐づかない呼び出し（クロール・バッチ等）
+	Feature   string // Feature* 定数
+	RequestID string
+}
+
+// TokenUsage は 1 回の呼び出しのトークン使用量。CachedPromptTokens は PromptTokens のうちプロンプトキャッシュに当たった分。
+type TokenUsage struct {
+	PromptTokens       int
+	CachedPromptTokens int
+	CompletionTokens   int
+}
+
+type callContextKey struct{}
+
+// WithCallContext は ctx に呼び出し元を載せる。cc のゼロ値の項目は ctx に載っている値を引き継ぐ。
+func WithCallContext(ctx context.Context, cc CallContext) context.Context {
+	current := CallContextFrom(ctx)
+	if cc.UserID == 0 {
+		cc.UserID = current.UserID
+	}
+	if cc.Feature == "" {
+		cc.Feature = current.Feature
+	}
+	if cc.RequestID == "" {
+		cc.RequestID = current.RequestID
+	}
+	return context.WithValue(ctx, callContextKey{}, cc)
+}
+
+// WithFeature は ctx の呼び出し元の機能名を設定する。
+func WithFeature(ctx context.Context, feature string) context.Context {
+	return WithCallContext(ctx, CallContext{Feature: feature})
+}
+
+// CallContextFrom は ctx に載っている呼び出し元を返す（なければゼロ値）。
+func CallContextFrom(ctx context.Context) CallContext {
+	if ctx == nil {
+		return CallContext{}
+	}
+	cc, _ := ctx.Value(callContextKey{}).(CallContext)
+	return cc
+}
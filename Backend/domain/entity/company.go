@@ -40,6 +40,7 @@ type UserApplicationStatus struct {
 	CompanyID       uint
 	Company         *Company
 	MatchID         uint
+	SessionID       string // マッチングを作った診断セッション
 	Status          string // applied / document_passed / interview / offered / accepted / declined / rejected
 	Notes           string
 	AppliedAt       *time.Time
@@ -96,4 +97,9 @@ type UserCompanyMatch struct {
 	IsApplied          bool
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
+
+	// スコアキャリブレーション
+	CalibrationVersion       int      // MatchScore の計算に使ったキャリブレーションの版（0 は重みなし）
+	ShadowMatchScore         *float64 // シャドーモードで並べて計算した総合マッチ度
+	ShadowCalibrationVersion int      // ShadowMatchScore の計算に使った版
 }
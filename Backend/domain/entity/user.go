@@ -2,6 +2,13 @@ package entity
 
 import "time"
 
+// ユーザーロール
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
 // User ドメインエンティティ（GORM依存なし）
 type User struct {
 	ID                       uint
@@ -10,6 +17,7 @@ type User struct {
 	Name                     string
 	IsGuest                  bool
 	IsAdmin                  bool
+	Role                     string // student / teacher / admin
 	TargetLevel              string // 新卒 or 中途
 	SchoolName               string
 	OAuthProvider            string
@@ -22,6 +30,15 @@ type User struct {
 	LastLoginAt              *time.Time
 	PasswordResetToken       string
 	PasswordResetExpiresAt   *time.Time
+	FailedLoginCount         int
+	LastFailedLoginAt        *time.Time
+	LockedUntil              *time.Time
+	UnlockToken              string
+	TOTPSecret               string // 暗号化済み
+	TOTPEnabledAt            *time.Time
+	TOTPLastCounter          int64
+	TOTPRecoveryCodes        string // 未使用リカバリーコードの SHA-256 ハッシュ（JSON配列）
+	SessionsRevokedAt        *time.Time
 	CreatedAt                time.Time
 	UpdatedAt                time.Time
 }
@@ -36,7 +53,44 @@ func (u *User) IsEmailVerified() bool {
 	return u.EmailVerifiedAt != nil
 }
 
+// IsLocked ログイン失敗によりアカウントがロック中かどうか
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// IsSessionRevoked issuedAt に発行したトークンが管理者によるロック解除・リセットで無効化されているか。
+// トークンの発行日時は秒単位のため、無効化と同じ秒に発行されたトークンは有効とみなす
+func (u *User) IsSessionRevoked(issuedAt time.Time) bool {
+	return u.SessionsRevokedAt != nil && issuedAt.Before(u.SessionsRevokedAt.Truncate(time.Second))
+}
+
+// HasTOTP 2段階認証（TOTP）を有効化済みかどうか
+func (u *User) HasTOTP() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// RequiresMFA 2段階認証が必須のロールかどうか（管理者・教員）
+func (u *User) RequiresMFA() bool {
+	return u.EffectiveRole() != RoleStudent
+}
+
 // HasOAuth OAuth連携済みかどうか
 func (u *User) HasOAuth() bool {
 	return u.OAuthProvider != "" && u.OAuthID != ""
 }
+
+// EffectiveRole 認可判定に用いるロールを返す（is_admin フラグは admin として扱う）
+func (u *User) EffectiveRole() string {
+	if u.IsAdmin || u.Role == RoleAdmin {
+		return RoleAdmin
+	}
+	if u.Role == RoleTeacher {
+		return RoleTeacher
+	}
+	return RoleStudent
+}
+
+// HasAdminRole 管理者ロールかどうか
+func (u *User) HasAdminRole() bool {
+	return u.EffectiveRole() == RoleAdmin
+}
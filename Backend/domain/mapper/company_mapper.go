@@ -51,6 +51,7 @@ func UserApplicationStatusToEntity(m *models.UserApplicationStatus) *entity.User
 		UserID:          m.UserID,
 		CompanyID:       m.CompanyID,
 		MatchID:         m.MatchID,
+		SessionID:       m.SessionID,
 		Status:          m.Status,
 		Notes:           m.Notes,
 		AppliedAt:       m.AppliedAt,
@@ -72,6 +73,7 @@ func UserApplicationStatusFromEntity(e *entity.UserApplicationStatus) *models.Us
 		UserID:          e.UserID,
 		CompanyID:       e.CompanyID,
 		MatchID:         e.MatchID,
+		SessionID:       e.SessionID,
 		Status:          e.Status,
 		Notes:           e.Notes,
 		AppliedAt:       e.AppliedAt,
@@ -109,6 +111,9 @@ func UserCompanyMatchToEntity(m *models.UserCompanyMatch) *entity.UserCompanyMat
 		CreatedAt:          m.CreatedAt,
 		UpdatedAt:          m.UpdatedAt,
 	}
+	e.CalibrationVersion = m.CalibrationVersion
+	e.ShadowMatchScore = m.ShadowMatchScore
+	e.ShadowCalibrationVersion = m.ShadowCalibrationVersion
 	e.Company = CompanyToEntity(&m.Company)
 	return e
 }
@@ -141,5 +146,8 @@ func UserCompanyMatchFromEntity(e *entity.UserCompanyMatch) *models.UserCompanyM
 		CreatedAt:          e.CreatedAt,
 		UpdatedAt:          e.UpdatedAt,
 	}
+	m.CalibrationVersion = e.CalibrationVersion
+	m.ShadowMatchScore = e.ShadowMatchScore
+	m.ShadowCalibrationVersion = e.ShadowCalibrationVersion
 	return m
 }
@@ -0,0 +1,32 @@
+package repository
+
+import "Backend/internal/models"
+
+// CohortRepository はクラス（教員・学生の所属）と教員招待の永続化インターフェース。
+type CohortRepository interface {
+	Create(cohort *models.Cohort) error
+	FindByID(id uint) (*models.Cohort, error)
+	ListAll() ([]models.Cohort, error)
+	// ListByMember は userID が指定ロールで所属するクラスを返す。
+	ListByMember(userID uint, role string) ([]models.Cohort, error)
+	Delete(id uint) error
+
+	AddMember(member *models.CohortMember) error
+	RemoveMember(cohortID, userID uint) error
+	// FindMember は所属が無い場合 nil, nil を返す。
+	FindMember(cohortID, userID uint) (*models.CohortMember, error)
+	ListMembers(cohortID uint, role string) ([]models.CohortMember, error)
+	// ApproveMember は学生本人の承認日時を記録する。
+	ApproveMember(cohortID, userID uint) error
+	// SharesCohort は teacherID が教員として、studentID が承認済みの学生として同じクラスに所属しているかを返す。
+	SharesCohort(teacherID, studentID uint) (bool, error)
+	// ListTeachersOfStudent は studentID が承認済みの学生として所属するクラスの担当教員を返す。
+	ListTeachersOfStudent(studentID uint) ([]models.CohortTeacher, error)
+	// ListCohortsOfStudent は studentID が学生として所属するクラス（承認待ちを含む）を返す。
+	ListCohortsOfStudent(studentID uint) ([]models.StudentCohort, error)
+
+	CreateInvitation(inv *models.CohortInvitation) error
+	FindInvitationByToken(token string) (*models.CohortInvitation, error)
+	UpdateInvitation(inv *models.CohortInvitation) error
+	ListInvitations(cohortID uint) ([]models.CohortInvitation, error)
+}
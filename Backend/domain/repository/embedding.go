@@ -13,3 +13,12 @@ type JobCategoryEmbeddingRepository interface {
 	FindByJobCategoryID(jobCategoryID uint) (*models.JobCategoryEmbedding, error)
 	Upsert(jobCategoryID uint, sourceText, embedding string) error
 }
+
+// EntityEmbeddingRepository は検索用の埋め込み（企業・募集職種）の永続化インターフェース。
+type EntityEmbeddingRepository interface {
+	Find(kind string, entityID uint) (*models.EntityEmbedding, error)
+	Upsert(embedding *models.EntityEmbedding) error
+	// ListByKind は ID が afterID より大きいものを ID 順に最大 limit 件返す（起動時の読み込み用）
+	ListByKind(kind string, afterID uint, limit int) ([]models.EntityEmbedding, error)
+	Delete(kind string, entityID uint) error
+}
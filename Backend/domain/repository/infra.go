@@ -11,6 +11,19 @@ type AuditLogRepository interface {
 	List(limit int) ([]models.AuditLog, error)
 }
 
+// RateLimitSettingRepository はレート制限設定の永続化インターフェース。
+type RateLimitSettingRepository interface {
+	List() ([]models.RateLimitSetting, error)
+	Upsert(setting *models.RateLimitSetting) error
+}
+
+// LoginAttemptRepository はログイン試行履歴の永続化インターフェース。
+type LoginAttemptRepository interface {
+	Create(attempt *models.LoginAttempt) error
+	CountFailuresByIP(ip string, since time.Time) (int64, error)
+	ListByUser(userID uint, limit int) ([]models.LoginAttempt, error)
+}
+
 // CrawlRepository はクロールソース・実行記録の永続化インターフェース。
 type CrawlRepository interface {
 	ListSources() ([]models.CrawlSource, error)
@@ -22,3 +35,72 @@ type CrawlRepository interface {
 	UpdateRun(run *models.CrawlRun) error
 	ListRuns(sourceID uint, limit int) ([]models.CrawlRun, error)
 }
+
+// PersonalDataJobRepository は個人データのエクスポート・削除ジョブの永続化インターフェース。
+type PersonalDataJobRepository interface {
+	Create(job *models.PersonalDataJob) error
+	Update(job *models.PersonalDataJob) error
+	FindByID(id uint) (*models.PersonalDataJob, error)
+	FindByStatusToken(token string) (*models.PersonalDataJob, error)
+	ListByUser(userID uint, kind string) ([]models.PersonalDataJob, error)
+	ListUnfinished() ([]models.PersonalDataJob, error)
+}
+
+// LLMResponseCacheRepository は LLM 応答キャッシュの永続化インターフェース。
+type LLMResponseCacheRepository interface {
+	FindByKey(key string) (*models.LLMResponseCache, error)
+	Upsert(entry *models.LLMResponseCache) error
+	DeleteByScope(scope string) (int64, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// PromptVersionRepository はプロンプトの版の永続化インターフェース。
+type PromptVersionRepository interface {
+	ListActive() ([]models.PromptVersion, error)
+	ListByName(name string) ([]models.PromptVersion, error)
+	FindVersion(name string, version int) (*models.PromptVersion, error)
+	CreateNextVersion(v *models.PromptVersion) error
+	Activate(name string, version int, publishedAt time.Time) error
+	RecordPublication(p *models.PromptPublication) error
+	ListPublications(name string) ([]models.PromptPublication, error)
+	RevertPublication(id uint, revertedAt time.Time) error
+}
+
+// LLMUserBudgetRepository はユーザーごとの LLM 予算設定の永続化インターフェース。
+type LLMUserBudgetRepository interface {
+	FindByUser(userID uint) (*models.LLMUserBudget, error)
+	List() ([]models.LLMUserBudget, error)
+	Upsert(budget *models.LLMUserBudget) error
+	Delete(userID uint) error
+}
+
+// LLMSpendRepository は利用者ごとの LLM 利用額（api_call_logs）の集計インターフェース。
+type LLMSpendRepository interface {
+	UserCostSince(userID uint, since time.Time) (float64, error)
+}
+
+// ModelPriceRepository はモデル単価表の永続化インターフェース。
+type ModelPriceRepository interface {
+	List() ([]models.ModelPrice, error)
+	FindByID(id uint) (*models.ModelPrice, error)
+	Upsert(price *models.ModelPrice) error
+	Delete(id uint) error
+}
+
+// CallCostRepricingRepository は api_call_logs の料金を単価表から計算し直すためのインターフェース。
+type CallCostRepricingRepository interface {
+	ListForRepricing(from, to time.Time, model string, afterID uint, limit int) ([]models.APICallLog, error)
+	UpdateCost(id uint, costUSD float64, modelPriceID *uint) error
+}
+
+// RealtimeCostRepricingRepository は realtime_usage_logs の料金を単価表から計算し直すためのインターフェース。
+type RealtimeCostRepricingRepository interface {
+	ListFinishedForRepricing(from, to time.Time, afterID uint, limit int) ([]models.RealtimeUsageLog, error)
+	UpdateCost(id uint, costUSD float64) error
+}
+
+// FlaggedInputRepository は入力ガードが検出した入力の記録のインターフェース。
+type FlaggedInputRepository interface {
+	Create(input *models.FlaggedInput) error
+	List(feature string, limit int) ([]models.FlaggedInput, error)
+}
@@ -2,7 +2,11 @@
 // Concrete implementations live in internal/repositories/.
 package repository
 
-import "Backend/domain/entity"
+import (
+	"Backend/domain/entity"
+	"Backend/internal/models"
+	"time"
+)
 
 // UserRepository はユーザー永続化の抽象インターフェース。
 type UserRepository interface {
@@ -16,6 +20,9 @@ type UserRepository interface {
 	GetUserByVerificationToken(token string) (*entity.User, error)
 	GetUserByPasswordResetToken(token string) (*entity.User, error)
 	GetUserByOAuth(provider, oauthID string) (*entity.User, error)
+	GetUserByUnlockToken(token string) (*entity.User, error)
+	IncrementFailedLogin(userID uint, at time.Time) (int, error)
+	UpdateLockout(user *entity.User) error
 }
 
 // PendingRegistrationRepository は仮登録の永続化インターフェース。
@@ -25,3 +32,12 @@ type PendingRegistrationRepository interface {
 	DeleteByEmail(email string) error
 	DeleteExpired() error
 }
+
+// UserIdentityRepository は外部 ID 連携の永続化インターフェース。
+type UserIdentityRepository interface {
+	Create(identity *models.UserIdentity) error
+	Update(identity *models.UserIdentity) error
+	FindByProvider(provider, providerUserID string) (*models.UserIdentity, error)
+	ListByUser(userID uint) ([]models.UserIdentity, error)
+	Delete(id uint) error
+}
@@ -0,0 +1,101 @@
+package ai
+
+import (
+	"Backend/domain/ai"
+	"context"
+)
+
+// BudgetChecker は利用者ごとの LLM 予算を判定する（services.LLMBudgetService が実装）。
+// 予算内なら空文字、超過時は格下げ先のモデルか ai.ErrBudgetExceeded を返す。
+type BudgetChecker interface {
+	Admit(ctx context.Context, userID uint) (model string, err error)
+}
+
+// BudgetAdapter は ctx の ai.CallContext の利用者の予算を確認してから内側のクライアントを呼ぶ。
+// 予算を超えた利用者の呼び出しは、テキスト生成なら格下げ先のモデルに切り替え、
+// 格下げできない呼び出し（埋め込み・音声・Web 検索・リアルタイム）は拒否の場合だけ止める。
+type BudgetAdapter struct {
+	ai.LLMClient
+	checker BudgetChecker
+}
+
+// NewBudgetAdapter は inner の前段で checker による予算の判定を行うアダプターを返す。
+func NewBudgetAdapter(inner ai.LLMClient, checker BudgetChecker) *BudgetAdapter {
+	return &BudgetAdapter{LLMClient: inner, checker: checker}
+}
+
+func (b *BudgetAdapter) GenerateText(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	opts, err := b.admitText(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	return b.LLMClient.GenerateText(ctx, systemPrompt, userPrompt, opts...)
+}
+
+func (b *BudgetAdapter) GenerateJSON(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	opts, err := b.admitText(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	return b.LLMClient.GenerateJSON(ctx, systemPrompt, userPrompt, opts...)
+}
+
+func (b *BudgetAdapter) Chat(ctx context.Context, messages []ai.Message, opts ...ai.CallOption) (string, error) {
+	opts, err := b.admitText(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	return b.LLMClient.Chat(ctx, messages, opts...)
+}
+
+func (b *BudgetAdapter) WebSearch(ctx context.Context, query string) (string, error) {
+	if _, err := b.admit(ctx); err != nil {
+		return "", err
+	}
+	return b.LLMClient.WebSearch(ctx, query)
+}
+
+func (b *BudgetAdapter) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	if _, err := b.admit(ctx); err != nil {
+		return nil, err
+	}
+	return b.LLMClient.GenerateEmbedding(ctx, text)
+}
+
+func (b *BudgetAdapter) TranscribeAudio(ctx context.Context, audio []byte, filename string) (string, error) {
+	if _, err := b.admit(ctx); err != nil {
+		return "", err
+	}
+	return b.LLMClient.TranscribeAudio(ctx, audio, filename)
+}
+
+func (b *BudgetAdapter) SynthesizeSpeech(ctx context.Context, text, voice string) ([]byte, error) {
+	if _, err := b.admit(ctx); err != nil {
+		return nil, err
+	}
+	return b.LLMClient.SynthesizeSpeech(ctx, text, voice)
+}
+
+func (b *BudgetAdapter) CreateRealtimeSession(ctx context.Context, req ai.RealtimeSessionRequest) (*ai.RealtimeSession, error) {
+	if _, err := b.admit(ctx); err != nil {
+		return nil, err
+	}
+	return b.LLMClient.CreateRealtimeSession(ctx, req)
+}
+
+// admitText は予算超過時に格下げ先のモデルを呼び出しの指定より優先させる。
+func (b *BudgetAdapter) admitText(ctx context.Context, opts []ai.CallOption) ([]ai.CallOption, error) {
+	model, err := b.admit(ctx)
+	if err != nil || model == "" {
+		return opts, err
+	}
+	return append(opts[:len(opts):len(opts)], ai.WithModel(model)), nil
+}
+
+func (b *BudgetAdapter) admit(ctx context.Context) (string, error) {
+	cc := ai.CallContextFrom(ctx)
+	if b.checker == nil || cc.UserID == 0 {
+		return "", nil
+	}
+	return b.checker.Admit(ctx, cc.UserID)
+}
@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"Backend/domain/ai"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStore は LLM 応答キャッシュの保存先。
+type CacheStore interface {
+	// Get は期限内のキャッシュ値を返す。見つからない・期限切れなら ok=false。
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set は値を TTL 付きで保存する。
+	Set(ctx context.Context, key, scope string, value []byte, ttl time.Duration) error
+	// Purge は scope のエントリを削除する。scope が空なら全件を削除する。
+	Purge(ctx context.Context, scope string) (int64, error)
+}
+
+// CachingAdapter は LLMClient の前段に置く応答キャッシュ。
+// ai.WithCache で印を付けた呼び出しだけを、モデル・プロンプト・生成パラメータをキーにキャッシュする。
+// 音声・リアルタイムセッションはキャッシュせず、そのまま内側のクライアントに渡す。
+type CachingAdapter struct {
+	ai.LLMClient
+	store CacheStore
+
+	mu    sync.Mutex
+	stats map[string]*cacheCounter
+}
+
+type cacheCounter struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCachingAdapter は inner の応答を store にキャッシュするアダプターを返す。
+func NewCachingAdapter(inner ai.LLMClient, store CacheStore) *CachingAdapter {
+	return &CachingAdapter{LLMClient: inner, store: store, stats: make(map[string]*cacheCounter)}
+}
+
+func (c *CachingAdapter) GenerateText(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	return cachedCall(c, ctx, cacheKeyParts("text", opts, systemPrompt, userPrompt), func() (string, error) {
+		return c.LLMClient.GenerateText(ctx, systemPrompt, userPrompt, opts...)
+	})
+}
+
+func (c *CachingAdapter) GenerateJSON(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	return cachedCall(c, ctx, cacheKeyParts("json", opts, systemPrompt, userPrompt), func() (string, error) {
+		return c.LLMClient.GenerateJSON(ctx, systemPrompt, userPrompt, opts...)
+	})
+}
+
+func (c *CachingAdapter) Chat(ctx context.Context, messages []ai.Message, opts ...ai.CallOption) (string, error) {
+	parts := make([]string, 0, len(messages)*2)
+	for _, m := range messages {
+		parts = append(parts, m.Role, m.Content)
+	}
+	return cachedCall(c, ctx, cacheKeyParts("chat", opts, parts...), func() (string, error) {
+		return c.LLMClient.Chat(ctx, messages, opts...)
+	})
+}
+
+func (c *CachingAdapter) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	return cachedCall(c, ctx, cacheKeyParts("embedding", nil, text), func() ([]float32, error) {
+		return c.LLMClient.GenerateEmbedding(ctx, text)
+	})
+}
+
+func (c *CachingAdapter) WebSearch(ctx context.Context, query string) (string, error) {
+	return cachedCall(c, ctx, cacheKeyParts("web_search", nil, query), func() (string, error) {
+		return c.LLMClient.WebSearch(ctx, query)
+	})
+}
+
+// CacheStats は呼び出し箇所ごとのヒット・ミス数を返す。
+func (c *CachingAdapter) CacheStats() []ai.CacheStat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make([]ai.CacheStat, 0, len(c.stats))
+	for scope, counter := range c.stats {
+		stat := ai.CacheStat{Scope: scope, Hits: counter.hits.Load(), Misses: counter.misses.Load()}
+		if total := stat.Hits + stat.Misses; total > 0 {
+			stat.HitRate = float64(stat.Hits) / float64(total)
+		}
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Scope < stats[j].Scope })
+	return stats
+}
+
+// PurgeCache は scope のキャッシュを削除する（空なら全件）。
+func (c *CachingAdapter) PurgeCache(ctx context.Context, scope string) (int64, error) {
+	return c.store.Purge(ctx, scope)
+}
+
+func (c *CachingAdapter) counter(scope string) *cacheCounter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counter, ok := c.stats[scope]
+	if !ok {
+		counter = &cacheCounter{}
+		c.stats[scope] = counter
+	}
+	return counter
+}
+
+// cachedCall は ctx にキャッシュ設定があればキャッシュを引き、なければ call の結果を保存する。
+// 保存先の障害はログに残してキャッシュなしで続行する。
+func cachedCall[T any](c *CachingAdapter, ctx context.Context, parts []string, call func() (T, error)) (T, error) {
+	policy, ok := ai.CachePolicyFrom(ctx)
+	if !ok {
+		return call()
+	}
+	key := cacheKey(policy.Scope, parts)
+	counter := c.counter(policy.Scope)
+
+	if raw, found, err := c.store.Get(ctx, key); err != nil {
+		log.Printf("[LLMCache] get %s failed: %v", policy.Scope, err)
+	} else if found {
+		var cached T
+		if err := json.Unmarshal(raw, &cached); err == nil {
+			counter.hits.Add(1)
+			return cached, nil
+		}
+	}
+	counter.misses.Add(1)
+
+	result, err := call()
+	if err != nil {
+		return result, err
+	}
+	if raw, err := json.Marshal(result); err == nil {
+		if err := c.store.Set(ctx, key, policy.Scope, raw, policy.TTL); err != nil {
+			log.Printf("[LLMCache] set %s failed: %v", policy.Scope, err)
+		}
+	}
+	return result, nil
+}
+
+// cacheKeyParts は操作種別・生成パラメータ・プロンプトをキーの材料として並べる。
+func cacheKeyParts(operation string, opts []ai.CallOption, prompts ...string) []string {
+	o := ai.ApplyCallOptions(opts...)
+	temperature := "default"
+	if o.Temperature != nil {
+		temperature = fmt.Sprintf("%g", *o.Temperature)
+	}
+	return append([]string{operation, o.Model, temperature, fmt.Sprint(o.MaxTokens)}, prompts...)
+}
+
+func cacheKey(scope string, parts []string) string {
+	h := sha256.New()
+	h.Write([]byte(scope))
+	for _, p := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
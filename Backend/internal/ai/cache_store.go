@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCacheStore はプロセス内の LRU キャッシュ。容量を超えると最も古く使われたエントリから捨てる。
+type MemoryCacheStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // 先頭ほど最近使われた
+	items    map[string]*list.Element
+	now      func() time.Time
+}
+
+type memoryCacheEntry struct {
+	key       string
+	scope     string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCacheStore は capacity 件まで保持する LRU キャッシュを返す（0 以下なら 1000 件）。
+func NewMemoryCacheStore(capacity int) *MemoryCacheStore {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &MemoryCacheStore{capacity: capacity, order: list.New(), items: make(map[string]*list.Element), now: time.Now}
+}
+
+func (s *MemoryCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryCacheEntry)
+	if !s.now().Before(entry.expiresAt) {
+		s.order.Remove(el)
+		delete(s.items, key)
+		return nil, false, nil
+	}
+	s.order.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+func (s *MemoryCacheStore) Set(_ context.Context, key, scope string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := &memoryCacheEntry{key: key, scope: scope, value: value, expiresAt: s.now().Add(ttl)}
+	if el, ok := s.items[key]; ok {
+		el.Value = entry
+		s.order.MoveToFront(el)
+		return nil
+	}
+	s.items[key] = s.order.PushFront(entry)
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryCacheEntry).key)
+	}
+	return nil
+}
+
+func (s *MemoryCacheStore) Purge(_ context.Context, scope string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for key, el := range s.items {
+		if scope == "" || el.Value.(*memoryCacheEntry).scope == scope {
+			s.order.Remove(el)
+			delete(s.items, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Len は保持しているエントリ数を返す。
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// DBCacheStore は MySQL の llm_response_caches テーブルに保存するキャッシュ。
+// 複数インスタンス間で共有でき、再起動後も残る。
+type DBCacheStore struct {
+	repo repository.LLMResponseCacheRepository
+	now  func() time.Time
+}
+
+// NewDBCacheStore はリポジトリを使うキャッシュを返す。
+func NewDBCacheStore(repo repository.LLMResponseCacheRepository) *DBCacheStore {
+	return &DBCacheStore{repo: repo, now: time.Now}
+}
+
+func (s *DBCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	entry, err := s.repo.FindByKey(key)
+	if err != nil || entry == nil {
+		return nil, false, err
+	}
+	if !s.now().Before(entry.ExpiresAt) {
+		return nil, false, nil
+	}
+	return []byte(entry.Value), true, nil
+}
+
+func (s *DBCacheStore) Set(_ context.Context, key, scope string, value []byte, ttl time.Duration) error {
+	return s.repo.Upsert(&models.LLMResponseCache{
+		CacheKey:  key,
+		Scope:     scope,
+		Value:     string(value),
+		ExpiresAt: s.now().Add(ttl).UTC(),
+	})
+}
+
+// Purge は scope のエントリと期限切れのエントリを削除する。
+func (s *DBCacheStore) Purge(_ context.Context, scope string) (int64, error) {
+	n, err := s.repo.DeleteByScope(scope)
+	if err != nil {
+		return n, err
+	}
+	expired, err := s.repo.DeleteExpired(s.now().UTC())
+	return n + expired, err
+}
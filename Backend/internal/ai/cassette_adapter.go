@@ -0,0 +1,285 @@
+package ai
+
+import (
+	"Backend/domain/ai"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// カセットの動作モード（LLM_CASSETTE_MODE の値）
+const (
+	// CassetteRecord は常に実プロバイダを呼び出し、結果をフィクスチャに記録（上書き）する。
+	CassetteRecord = "record"
+	// CassetteReplay は記録済みの応答を返し、未記録のプロンプトだけ実プロバイダを呼び出して記録する。
+	CassetteReplay = "replay"
+	// CassetteStrict は記録済みの応答のみを返し、未記録のプロンプトは ErrCassetteMiss で失敗させる（CI 用）。
+	CassetteStrict = "strict"
+)
+
+// ErrCassetteMiss は strict モードで未記録のプロンプトが呼び出されたことを表す。
+var ErrCassetteMiss = errors.New("llm cassette: no recorded response for prompt")
+
+// CassetteConfig はカセットアダプターの設定。
+type CassetteConfig struct {
+	Dir   string       // フィクスチャを置くディレクトリ（1 呼び出し 1 ファイル）
+	Mode  string       // CassetteRecord / CassetteReplay / CassetteStrict（空なら CassetteReplay）
+	Inner ai.LLMClient // 実プロバイダ。strict モードでは nil でよい
+	// Normalize はハッシュ前にプロンプトへ適用する追加の正規化（日時・ID など毎回変わる値の除去用、nil 可）
+	Normalize func(string) string
+}
+
+// cassetteEntry はフィクスチャファイル 1 件の内容。
+type cassetteEntry struct {
+	Key        string       `json:"key"`
+	Operation  string       `json:"operation"`
+	Model      string       `json:"model,omitempty"`
+	Messages   []ai.Message `json:"messages,omitempty"`
+	Input      string       `json:"input,omitempty"`
+	Text       string       `json:"text,omitempty"`
+	Embedding  []float32    `json:"embedding,omitempty"`
+	Audio      []byte       `json:"audio,omitempty"`
+	RecordedAt time.Time    `json:"recorded_at"`
+}
+
+// CassetteAdapter はプロンプトと応答の組をフィクスチャファイルに記録・再生する LLMClient。
+// 正規化したプロンプト（操作種別・モデル・メッセージ）のハッシュで応答を引くため、
+// ネットワークなしでチャット・レポート生成・職務経歴書レビューを決定的に実行できる。
+// 温度・出力上限などの生成パラメータはキーに含めない。リアルタイム音声セッションは記録せず実プロバイダに委譲する。
+type CassetteAdapter struct {
+	cfg     CassetteConfig
+	mu      sync.Mutex
+	entries map[string]*cassetteEntry
+}
+
+// NewCassetteAdapter はディレクトリ内のフィクスチャを読み込んだカセットアダプターを返す。
+func NewCassetteAdapter(cfg CassetteConfig) (*CassetteAdapter, error) {
+	if strings.TrimSpace(cfg.Dir) == "" {
+		return nil, errors.New("llm cassette: directory is required")
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = CassetteReplay
+	}
+	switch cfg.Mode {
+	case CassetteRecord, CassetteReplay:
+		if cfg.Inner == nil {
+			return nil, fmt.Errorf("llm cassette: %s mode requires an inner client", cfg.Mode)
+		}
+	case CassetteStrict:
+	default:
+		return nil, fmt.Errorf("llm cassette: unknown mode %q", cfg.Mode)
+	}
+
+	a := &CassetteAdapter{cfg: cfg, entries: make(map[string]*cassetteEntry)}
+	files, err := filepath.Glob(filepath.Join(cfg.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var entry cassetteEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("llm cassette: invalid fixture %s: %w", filepath.Base(path), err)
+		}
+		if entry.Key != "" {
+			a.entries[entry.Key] = &entry
+		}
+	}
+	return a, nil
+}
+
+// Len は読み込み済み・記録済みのフィクスチャ数を返す。
+func (a *CassetteAdapter) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.entries)
+}
+
+func (a *CassetteAdapter) GenerateText(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	o := ai.ApplyCallOptions(opts...)
+	entry := &cassetteEntry{Operation: "text", Model: o.Model, Messages: promptMessages(systemPrompt, userPrompt)}
+	res, err := a.play(entry, func(e *cassetteEntry) error {
+		text, err := a.cfg.Inner.GenerateText(ctx, systemPrompt, userPrompt, opts...)
+		e.Text = text
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Text, nil
+}
+
+func (a *CassetteAdapter) GenerateJSON(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	o := ai.ApplyCallOptions(opts...)
+	entry := &cassetteEntry{Operation: "json", Model: o.Model, Messages: promptMessages(systemPrompt, userPrompt)}
+	res, err := a.play(entry, func(e *cassetteEntry) error {
+		text, err := a.cfg.Inner.GenerateJSON(ctx, systemPrompt, userPrompt, opts...)
+		e.Text = text
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Text, nil
+}
+
+func (a *CassetteAdapter) Chat(ctx context.Context, messages []ai.Message, opts ...ai.CallOption) (string, error) {
+	o := ai.ApplyCallOptions(opts...)
+	entry := &cassetteEntry{Operation: "chat", Model: o.Model, Messages: messages}
+	res, err := a.play(entry, func(e *cassetteEntry) error {
+		text, err := a.cfg.Inner.Chat(ctx, messages, opts...)
+		e.Text = text
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Text, nil
+}
+
+func (a *CassetteAdapter) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	entry := &cassetteEntry{Operation: "embedding", Input: text}
+	res, err := a.play(entry, func(e *cassetteEntry) error {
+		vec, err := a.cfg.Inner.GenerateEmbedding(ctx, text)
+		e.Embedding = vec
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Embedding, nil
+}
+
+func (a *CassetteAdapter) TranscribeAudio(ctx context.Context, audio []byte, filename string) (string, error) {
+	sum := sha256.Sum256(audio)
+	entry := &cassetteEntry{Operation: "transcription", Input: "sha256:" + hex.EncodeToString(sum[:])}
+	res, err := a.play(entry, func(e *cassetteEntry) error {
+		text, err := a.cfg.Inner.TranscribeAudio(ctx, audio, filename)
+		e.Text = text
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Text, nil
+}
+
+func (a *CassetteAdapter) SynthesizeSpeech(ctx context.Context, text, voice string) ([]byte, error) {
+	entry := &cassetteEntry{Operation: "speech", Model: voice, Input: text}
+	res, err := a.play(entry, func(e *cassetteEntry) error {
+		audio, err := a.cfg.Inner.SynthesizeSpeech(ctx, text, voice)
+		e.Audio = audio
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Audio, nil
+}
+
+func (a *CassetteAdapter) WebSearch(ctx context.Context, query string) (string, error) {
+	entry := &cassetteEntry{Operation: "web_search", Input: query}
+	res, err := a.play(entry, func(e *cassetteEntry) error {
+		text, err := a.cfg.Inner.WebSearch(ctx, query)
+		e.Text = text
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Text, nil
+}
+
+// CreateRealtimeSession は一時クレデンシャルを発行するだけなので記録せず、実プロバイダに委譲する。
+func (a *CassetteAdapter) CreateRealtimeSession(ctx context.Context, req ai.RealtimeSessionRequest) (*ai.RealtimeSession, error) {
+	if a.cfg.Inner == nil {
+		return nil, ai.ErrUnsupported
+	}
+	return a.cfg.Inner.CreateRealtimeSession(ctx, req)
+}
+
+// play は entry のキーで記録済みの応答を探し、モードに応じて再生・実呼び出し・記録を行う。
+func (a *CassetteAdapter) play(entry *cassetteEntry, call func(*cassetteEntry) error) (*cassetteEntry, error) {
+	entry.Key = a.key(entry)
+
+	if a.cfg.Mode != CassetteRecord {
+		a.mu.Lock()
+		recorded, ok := a.entries[entry.Key]
+		a.mu.Unlock()
+		if ok {
+			return recorded, nil
+		}
+		if a.cfg.Mode == CassetteStrict {
+			return nil, fmt.Errorf("%w: %s %s (%s)", ErrCassetteMiss, entry.Operation, entry.Key, entry.excerpt())
+		}
+	}
+
+	if err := call(entry); err != nil {
+		return nil, err
+	}
+	entry.RecordedAt = time.Now().UTC()
+	if err := a.save(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (a *CassetteAdapter) save(entry *cassetteEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := os.MkdirAll(a.cfg.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.json", entry.Operation, entry.Key)
+	if err := os.WriteFile(filepath.Join(a.cfg.Dir, name), append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	a.entries[entry.Key] = entry
+	return nil
+}
+
+// key は操作種別・モデル・正規化したプロンプトから 16 桁のハッシュを作る。
+func (a *CassetteAdapter) key(entry *cassetteEntry) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", entry.Operation, entry.Model, a.normalize(entry.Input))
+	for _, m := range entry.Messages {
+		fmt.Fprintf(h, "\x00%s\x00%s", m.Role, a.normalize(m.Content))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// normalize は空白・改行の揺れを吸収したプロンプトを返す。
+func (a *CassetteAdapter) normalize(s string) string {
+	if a.cfg.Normalize != nil {
+		s = a.cfg.Normalize(s)
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// excerpt はエラーメッセージ用にプロンプトの末尾を短く切り出す。
+func (e *cassetteEntry) excerpt() string {
+	s := e.Input
+	if len(e.Messages) > 0 {
+		s = e.Messages[len(e.Messages)-1].Content
+	}
+	r := []rune(strings.Join(strings.Fields(s), " "))
+	if len(r) > 60 {
+		return string(r[:60]) + "…"
+	}
+	return string(r)
+}
@@ -0,0 +1,202 @@
+package ai
+
+import (
+	"Backend/domain/ai"
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// BreakerConfig はサーキットブレーカーの閾値。
+type BreakerConfig struct {
+	FailureThreshold int           // 連続失敗がこの回数に達したら open（0 以下なら 5）
+	OpenTimeout      time.Duration // open から half-open に移るまでの時間（0 以下なら 30 秒）
+}
+
+// CircuitBreakerAdapter はプライマリの LLMClient を機能（テキスト・埋め込み・音声・リアルタイム）ごとの
+// サーキットブレーカーで包み、open の間は呼び出しをセカンダリ（既定は UnavailableAdapter）に振り替える。
+// closed の間の失敗はそのまま呼び出し元に返し、連続失敗が閾値に達した時点で open にする。
+type CircuitBreakerAdapter struct {
+	primary   ai.LLMClient
+	secondary ai.LLMClient
+	cfg       BreakerConfig
+	breakers  map[string]*breaker
+	now       func() time.Time
+}
+
+type breaker struct {
+	mu            sync.Mutex
+	state         string
+	failures      int
+	trips         int64
+	probing       bool
+	openedAt      time.Time
+	lastError     string
+	lastFailureAt time.Time
+}
+
+// NewCircuitBreakerAdapter は primary を機能ごとのサーキットブレーカーで包んだアダプターを返す。
+// secondary が nil なら open の間は ai.ErrProviderUnavailable を返す。
+func NewCircuitBreakerAdapter(primary, secondary ai.LLMClient, cfg BreakerConfig) *CircuitBreakerAdapter {
+	if secondary == nil {
+		secondary = NewUnavailableAdapter()
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	breakers := make(map[string]*breaker)
+	for _, c := range []string{ai.CapabilityText, ai.CapabilityEmbedding, ai.CapabilityAudio, ai.CapabilityRealtime} {
+		breakers[c] = &breaker{state: ai.BreakerClosed}
+	}
+	return &CircuitBreakerAdapter{primary: primary, secondary: secondary, cfg: cfg, breakers: breakers, now: time.Now}
+}
+
+// SetNow は現在時刻の取得関数を差し替える（テスト用）。
+func (a *CircuitBreakerAdapter) SetNow(now func() time.Time) {
+	a.now = now
+}
+
+func (a *CircuitBreakerAdapter) GenerateText(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	return guarded(a, ai.CapabilityText, func(c ai.LLMClient) (string, error) {
+		return c.GenerateText(ctx, systemPrompt, userPrompt, opts...)
+	})
+}
+
+func (a *CircuitBreakerAdapter) GenerateJSON(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	return guarded(a, ai.CapabilityText, func(c ai.LLMClient) (string, error) {
+		return c.GenerateJSON(ctx, systemPrompt, userPrompt, opts...)
+	})
+}
+
+func (a *CircuitBreakerAdapter) Chat(ctx context.Context, messages []ai.Message, opts ...ai.CallOption) (string, error) {
+	return guarded(a, ai.CapabilityText, func(c ai.LLMClient) (string, error) {
+		return c.Chat(ctx, messages, opts...)
+	})
+}
+
+func (a *CircuitBreakerAdapter) WebSearch(ctx context.Context, query string) (string, error) {
+	return guarded(a, ai.CapabilityText, func(c ai.LLMClient) (string, error) {
+		return c.WebSearch(ctx, query)
+	})
+}
+
+func (a *CircuitBreakerAdapter) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	return guarded(a, ai.CapabilityEmbedding, func(c ai.LLMClient) ([]float32, error) {
+		return c.GenerateEmbedding(ctx, text)
+	})
+}
+
+func (a *CircuitBreakerAdapter) TranscribeAudio(ctx context.Context, audio []byte, filename string) (string, error) {
+	return guarded(a, ai.CapabilityAudio, func(c ai.LLMClient) (string, error) {
+		return c.TranscribeAudio(ctx, audio, filename)
+	})
+}
+
+func (a *CircuitBreakerAdapter) SynthesizeSpeech(ctx context.Context, text, voice string) ([]byte, error) {
+	return guarded(a, ai.CapabilityAudio, func(c ai.LLMClient) ([]byte, error) {
+		return c.SynthesizeSpeech(ctx, text, voice)
+	})
+}
+
+func (a *CircuitBreakerAdapter) CreateRealtimeSession(ctx context.Context, req ai.RealtimeSessionRequest) (*ai.RealtimeSession, error) {
+	return guarded(a, ai.CapabilityRealtime, func(c ai.LLMClient) (*ai.RealtimeSession, error) {
+		return c.CreateRealtimeSession(ctx, req)
+	})
+}
+
+// BreakerStatuses は機能ごとのブレーカーの状態を返す。
+func (a *CircuitBreakerAdapter) BreakerStatuses() []ai.BreakerStatus {
+	now := a.now()
+	statuses := make([]ai.BreakerStatus, 0, len(a.breakers))
+	for _, c := range []string{ai.CapabilityText, ai.CapabilityEmbedding, ai.CapabilityAudio, ai.CapabilityRealtime} {
+		b := a.breakers[c]
+		b.mu.Lock()
+		b.advance(now, a.cfg)
+		status := ai.BreakerStatus{Capability: c, State: b.state, ConsecutiveFailures: b.failures, Trips: b.trips, LastError: b.lastError}
+		if b.state != ai.BreakerClosed {
+			openedAt := b.openedAt
+			status.OpenedAt = &openedAt
+		}
+		if !b.lastFailureAt.IsZero() {
+			lastFailureAt := b.lastFailureAt
+			status.LastFailureAt = &lastFailureAt
+		}
+		b.mu.Unlock()
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// guarded はブレーカーの状態に応じてプライマリかセカンダリで call を実行し、結果をブレーカーに反映する。
+func guarded[T any](a *CircuitBreakerAdapter, capability string, call func(ai.LLMClient) (T, error)) (T, error) {
+	b := a.breakers[capability]
+	usePrimary, probe := b.acquire(a.now(), a.cfg)
+	if !usePrimary {
+		return call(a.secondary)
+	}
+	result, err := call(a.primary)
+	b.release(capability, probe, err, a.now(), a.cfg)
+	return result, err
+}
+
+// advance は open のまま OpenTimeout を過ぎていれば half-open に移す。b.mu を保持して呼ぶ。
+func (b *breaker) advance(now time.Time, cfg BreakerConfig) {
+	if b.state == ai.BreakerOpen && now.Sub(b.openedAt) >= cfg.OpenTimeout {
+		b.state = ai.BreakerHalfOpen
+	}
+}
+
+// acquire はプライマリを使うかどうかを返す。half-open では 1 件だけ試行（probe）を許す。
+func (b *breaker) acquire(now time.Time, cfg BreakerConfig) (usePrimary, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(now, cfg)
+	switch b.state {
+	case ai.BreakerClosed:
+		return true, false
+	case ai.BreakerHalfOpen:
+		if b.probing {
+			return false, false
+		}
+		b.probing = true
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// release はプライマリの呼び出し結果でブレーカーの状態を更新する。
+// 呼び出し元のキャンセルと非対応の操作はプロバイダの障害として数えない。
+func (b *breaker) release(capability string, probe bool, err error, now time.Time, cfg BreakerConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, ai.ErrUnsupported)) {
+		return
+	}
+	if err == nil {
+		if b.state != ai.BreakerClosed {
+			log.Printf("[LLMBreaker] %s: %s -> closed", capability, b.state)
+		}
+		b.state = ai.BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	b.lastFailureAt = now
+	if probe || (b.state == ai.BreakerClosed && b.failures >= cfg.FailureThreshold) {
+		log.Printf("[LLMBreaker] %s: %s -> open after %d consecutive failures: %v", capability, b.state, b.failures, err)
+		b.state = ai.BreakerOpen
+		b.openedAt = now
+		b.trips++
+	}
+}
@@ -0,0 +1,225 @@
+package ai
+
+import (
+	"Backend/domain/ai"
+	"Backend/internal/openai"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	sdk "github.com/sashabaranov/go-openai"
+)
+
+// CompatibleConfig は OpenAI 互換 API サーバーの接続設定。
+type CompatibleConfig struct {
+	BaseURL        string // 例: http://localhost:11434/v1（Ollama）、http://localhost:8000/v1（vLLM）
+	APIKey         string // 認証不要なサーバーでは空でよい
+	Model          string
+	EmbeddingModel string
+	OnUsage        openai.UsageHook
+}
+
+// CompatibleAdapter は OpenAI 互換の Chat Completions API を提供するサーバー
+// （vLLM・Ollama・LM Studio 等のセルフホストモデル）を domain/ai.LLMClient に適合させるアダプター。
+// 開発環境・CI で外部 API を使わずに動かすために使う。Web 検索・リアルタイム音声には対応しない。
+type CompatibleAdapter struct {
+	client *sdk.Client
+	cfg    CompatibleConfig
+}
+
+// NewCompatibleAdapter は OpenAI 互換サーバー向けのアダプターを返す。
+func NewCompatibleAdapter(cfg CompatibleConfig) (ai.LLMClient, error) {
+	if strings.TrimSpace(cfg.BaseURL) == "" {
+		return nil, errors.New("LLM_BASE_URL is required for the openai-compatible provider")
+	}
+	if strings.TrimSpace(cfg.Model) == "" {
+		return nil, errors.New("LLM_MODEL is required for the openai-compatible provider")
+	}
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = "local"
+	}
+	sdkCfg := sdk.DefaultConfig(apiKey)
+	sdkCfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &CompatibleAdapter{client: sdk.NewClientWithConfig(sdkCfg), cfg: cfg}, nil
+}
+
+// GenerateText はシステムプロンプト＋ユーザープロンプトでテキストを生成する。
+// ctx に ai.WithTextStream があればストリーミングで生成する。
+func (a *CompatibleAdapter) GenerateText(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	if onDelta := ai.TextStreamFrom(ctx); onDelta != nil {
+		return a.stream(ctx, promptMessages(systemPrompt, userPrompt), ai.ApplyCallOptions(opts...), onDelta)
+	}
+	return a.complete(ctx, promptMessages(systemPrompt, userPrompt), ai.ApplyCallOptions(opts...), false)
+}
+
+// GenerateJSON は JSON モードでテキストを生成する（JSON モード非対応のサーバーではプロンプトの指示のみに従う）。
+func (a *CompatibleAdapter) GenerateJSON(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	o := ai.ApplyCallOptions(opts...)
+	if o.Temperature == nil {
+		o.Temperature = ptrFloat32(0)
+	}
+	return a.complete(ctx, promptMessages(systemPrompt, userPrompt), o, true)
+}
+
+// Chat は会話履歴から次の発話を生成する。
+func (a *CompatibleAdapter) Chat(ctx context.Context, messages []ai.Message, opts ...ai.CallOption) (string, error) {
+	return a.complete(ctx, messages, ai.ApplyCallOptions(opts...), false)
+}
+
+func (a *CompatibleAdapter) complete(ctx context.Context, messages []ai.Message, o ai.CallOptions, jsonMode bool) (string, error) {
+	model := o.Model
+	if model == "" {
+		model = a.cfg.Model
+	}
+	req := sdk.ChatCompletionRequest{
+		Model:       model,
+		Temperature: o.TemperatureOr(0.7),
+		MaxTokens:   o.MaxTokens,
+	}
+	for _, m := range messages {
+		req.Messages = append(req.Messages, sdk.ChatCompletionMessage{Role: m.Role, Content: m.Content})
+	}
+	if jsonMode {
+		req.ResponseFormat = &sdk.ChatCompletionResponseFormat{Type: sdk.ChatCompletionResponseFormatTypeJSONObject}
+		if rs := o.ResponseSchema; rs != nil && rs.Schema != nil {
+			req.ResponseFormat = &sdk.ChatCompletionResponseFormat{
+				Type:       sdk.ChatCompletionResponseFormatTypeJSONSchema,
+				JSONSchema: &sdk.ChatCompletionResponseFormatJSONSchema{Name: rs.Name, Schema: *rs.Schema, Strict: rs.Strict},
+			}
+		}
+	}
+
+	resp, err := a.client.CreateChatCompletion(ctx, req)
+	if err != nil && jsonMode {
+		// response_format 非対応のサーバー向けに指定なしで再試行する
+		req.ResponseFormat = nil
+		resp, err = a.client.CreateChatCompletion(ctx, req)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("no choices returned from chat API")
+	}
+	if a.cfg.OnUsage != nil && (resp.Usage.PromptTokens > 0 || resp.Usage.CompletionTokens > 0) {
+		a.cfg.OnUsage(ctx, model, openai.TokenUsageOf(resp.Usage))
+	}
+	content := strings.TrimSpace(resp.Choices[0].Message.Content)
+	if content == "" {
+		return "", errors.New("empty response from model")
+	}
+	return content, nil
+}
+
+// stream は Chat Completions をストリーミングで呼び出し、差分を onDelta に渡しながら全文を返す。
+// 使用量はサーバーが stream_options.include_usage に対応している場合だけ記録する。
+func (a *CompatibleAdapter) stream(ctx context.Context, messages []ai.Message, o ai.CallOptions, onDelta func(string)) (string, error) {
+	model := o.Model
+	if model == "" {
+		model = a.cfg.Model
+	}
+	req := sdk.ChatCompletionRequest{
+		Model:         model,
+		Temperature:   o.TemperatureOr(0.7),
+		MaxTokens:     o.MaxTokens,
+		Stream:        true,
+		StreamOptions: &sdk.StreamOptions{IncludeUsage: true},
+	}
+	for _, m := range messages {
+		req.Messages = append(req.Messages, sdk.ChatCompletionMessage{Role: m.Role, Content: m.Content})
+	}
+	stream, err := a.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	content, usage, err := openai.ReadChatStream(stream, onDelta)
+	if a.cfg.OnUsage != nil && usage != nil && (usage.PromptTokens > 0 || usage.CompletionTokens > 0) {
+		a.cfg.OnUsage(ctx, model, openai.TokenUsageOf(*usage))
+	}
+	if err != nil {
+		return "", err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", errors.New("empty response from model")
+	}
+	return content, nil
+}
+
+// GenerateEmbedding は /embeddings でテキストの埋め込みベクトルを返す。
+func (a *CompatibleAdapter) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("embedding input is empty")
+	}
+	model := a.cfg.EmbeddingModel
+	if model == "" {
+		model = a.cfg.Model
+	}
+	resp, err := a.client.CreateEmbeddings(ctx, sdk.EmbeddingRequest{
+		Model: sdk.EmbeddingModel(model),
+		Input: []string{text},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, errors.New("empty embedding response")
+	}
+	return resp.Data[0].Embedding, nil
+}
+
+// TranscribeAudio は /audio/transcriptions で音声をテキストに変換する。
+func (a *CompatibleAdapter) TranscribeAudio(ctx context.Context, audio []byte, filename string) (string, error) {
+	resp, err := a.client.CreateTranscription(ctx, sdk.AudioRequest{
+		Model:    sdk.Whisper1,
+		FilePath: filename,
+		Reader:   bytes.NewReader(audio),
+		Language: "ja",
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Text), nil
+}
+
+// SynthesizeSpeech は /audio/speech でテキストを音声に変換する。
+func (a *CompatibleAdapter) SynthesizeSpeech(ctx context.Context, text, voice string) ([]byte, error) {
+	if voice == "" {
+		voice = "alloy"
+	}
+	resp, err := a.client.CreateSpeech(ctx, sdk.CreateSpeechRequest{
+		Model: sdk.TTSModel1,
+		Input: text,
+		Voice: sdk.SpeechVoice(voice),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+	return io.ReadAll(resp)
+}
+
+// WebSearch は OpenAI 互換サーバーでは利用できない。
+func (a *CompatibleAdapter) WebSearch(_ context.Context, _ string) (string, error) {
+	return "", ai.ErrUnsupported
+}
+
+// CreateRealtimeSession は OpenAI 互換サーバーでは利用できない。
+func (a *CompatibleAdapter) CreateRealtimeSession(_ context.Context, _ ai.RealtimeSessionRequest) (*ai.RealtimeSession, error) {
+	return nil, ai.ErrUnsupported
+}
+
+func promptMessages(systemPrompt, userPrompt string) []ai.Message {
+	var messages []ai.Message
+	if systemPrompt != "" {
+		messages = append(messages, ai.Message{Role: ai.RoleSystem, Content: systemPrompt})
+	}
+	return append(messages, ai.Message{Role: ai.RoleUser, Content: userPrompt})
+}
+
+func ptrFloat32(v float32) *float32 {
+	return &v
+}
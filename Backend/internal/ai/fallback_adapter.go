@@ -16,14 +16,20 @@ func NewFallbackAdapter() ai.LLMClient {
 	return &FallbackAdapter{}
 }
 
-func (f *FallbackAdapter) GenerateText(_ context.Context, _, _ string) (string, error) {
-	return "現在 AI サービスが利用できません。しばらくしてから再度お試しください。", nil
+const fallbackMessage = "現在 AI サービスが利用できません。しばらくしてから再度お試しください。"
+
+func (f *FallbackAdapter) GenerateText(_ context.Context, _, _ string, _ ...ai.CallOption) (string, error) {
+	return fallbackMessage, nil
 }
 
-func (f *FallbackAdapter) GenerateJSON(_ context.Context, _, _ string) (string, error) {
+func (f *FallbackAdapter) GenerateJSON(_ context.Context, _, _ string, _ ...ai.CallOption) (string, error) {
 	return "{}", nil
 }
 
+func (f *FallbackAdapter) Chat(_ context.Context, _ []ai.Message, _ ...ai.CallOption) (string, error) {
+	return fallbackMessage, nil
+}
+
 func (f *FallbackAdapter) GenerateEmbedding(_ context.Context, _ string) ([]float32, error) {
 	return nil, errors.New("embedding unavailable: AI service is temporarily down")
 }
@@ -31,3 +37,15 @@ func (f *FallbackAdapter) GenerateEmbedding(_ context.Context, _ string) ([]floa
 func (f *FallbackAdapter) TranscribeAudio(_ context.Context, _ []byte, _ string) (string, error) {
 	return "", errors.New("transcription unavailable: AI service is temporarily down")
 }
+
+func (f *FallbackAdapter) SynthesizeSpeech(_ context.Context, _, _ string) ([]byte, error) {
+	return nil, errors.New("speech synthesis unavailable: AI service is temporarily down")
+}
+
+func (f *FallbackAdapter) WebSearch(_ context.Context, _ string) (string, error) {
+	return "", errors.New("web search unavailable: AI service is temporarily down")
+}
+
+func (f *FallbackAdapter) CreateRealtimeSession(_ context.Context, _ ai.RealtimeSessionRequest) (*ai.RealtimeSession, error) {
+	return nil, errors.New("realtime session unavailable: AI service is temporarily down")
+}
@@ -10,7 +10,8 @@ import (
 // OpenAIAdapter は openai.Client を domain/ai.LLMClient に適合させるアダプター。
 // 依存注入によって OpenAI 以外の実装に差し替え可能。
 type OpenAIAdapter struct {
-	client *openai.Client
+	client         *openai.Client
+	embeddingModel string // 空なら OPENAI_EMBEDDING_MODEL または既定モデル
 }
 
 // NewOpenAIAdapter は既存の openai.Client をラップしたアダプターを返す。
@@ -19,21 +20,84 @@ func NewOpenAIAdapter(client *openai.Client) ai.LLMClient {
 }
 
 // GenerateText はシステムプロンプト＋ユーザープロンプトでテキストを生成する。
-func (a *OpenAIAdapter) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	return a.client.ResponsesWithTemperature(ctx, systemPrompt, userPrompt, 0.7)
+// システムプロンプトがなければ単一入力の Responses API、出力上限の指定があれば上限付きで呼び出す。
+// ctx に ai.WithTextStream があれば Chat Completions のストリーミングで生成する。
+func (a *OpenAIAdapter) GenerateText(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	o := ai.ApplyCallOptions(opts...)
+	if onDelta := ai.TextStreamFrom(ctx); onDelta != nil {
+		return a.client.ChatCompletionStream(ctx, systemPrompt, userPrompt, o.Temperature, o.MaxTokens, onDelta, o.Model)
+	}
+	switch {
+	case systemPrompt == "":
+		return a.client.Responses(ctx, userPrompt, o.Model)
+	case o.MaxTokens > 0:
+		return a.client.ResponsesWithMaxTokens(ctx, systemPrompt, userPrompt, o.TemperatureOr(0.7), o.MaxTokens, o.Model)
+	default:
+		return a.client.ResponsesWithTemperature(ctx, systemPrompt, userPrompt, o.TemperatureOr(0.7), o.Model)
+	}
 }
 
 // GenerateJSON は JSON 文字列を返すテキスト生成（構造化出力用）。
-func (a *OpenAIAdapter) GenerateJSON(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	return a.client.ChatCompletionJSON(ctx, systemPrompt, userPrompt, 0.0, 4096)
+// ResponseSchema があれば Structured Outputs（json_schema）で生成する。
+func (a *OpenAIAdapter) GenerateJSON(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	o := ai.ApplyCallOptions(opts...)
+	maxTokens := o.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 4096
+	}
+	if rs := o.ResponseSchema; rs != nil && rs.Schema != nil {
+		return a.client.ChatCompletionJSONSchema(ctx, systemPrompt, userPrompt, o.TemperatureOr(0.0), maxTokens, rs.Name, *rs.Schema, rs.Strict, o.Model)
+	}
+	return a.client.ChatCompletionJSON(ctx, systemPrompt, userPrompt, o.TemperatureOr(0.0), maxTokens, o.Model)
+}
+
+// Chat は会話履歴から次の発話を生成する。
+func (a *OpenAIAdapter) Chat(ctx context.Context, messages []ai.Message, opts ...ai.CallOption) (string, error) {
+	o := ai.ApplyCallOptions(opts...)
+	history := make([]map[string]string, 0, len(messages))
+	for _, m := range messages {
+		history = append(history, map[string]string{"role": m.Role, "content": m.Content})
+	}
+	return a.client.ChatMessages(ctx, history, o.Model, o.TemperatureOr(0.7), o.MaxTokens)
 }
 
 // GenerateEmbedding はテキストの埋め込みベクトルを返す。
 func (a *OpenAIAdapter) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
-	return a.client.Embedding(ctx, text)
+	return a.client.Embedding(ctx, text, a.embeddingModel)
 }
 
 // TranscribeAudio は音声バイト列をテキストに変換する。
 func (a *OpenAIAdapter) TranscribeAudio(ctx context.Context, audio []byte, filename string) (string, error) {
 	return a.client.Transcribe(ctx, audio, filename)
 }
+
+// SynthesizeSpeech はテキストを音声（mp3）に変換する。
+func (a *OpenAIAdapter) SynthesizeSpeech(ctx context.Context, text, voice string) ([]byte, error) {
+	return a.client.TTS(ctx, text, voice)
+}
+
+// WebSearch は Responses API の Web 検索ツールで回答を生成する。
+func (a *OpenAIAdapter) WebSearch(ctx context.Context, query string) (string, error) {
+	return a.client.WebSearchQuery(ctx, query)
+}
+
+// CreateRealtimeSession は Realtime API のセッションを作成する。
+func (a *OpenAIAdapter) CreateRealtimeSession(ctx context.Context, req ai.RealtimeSessionRequest) (*ai.RealtimeSession, error) {
+	resp, err := a.client.CreateRealtimeClientSecret(ctx, openai.RealtimeSessionRequest{
+		Model:                   req.Model,
+		Modalities:              req.Modalities,
+		Voice:                   req.Voice,
+		Instructions:            req.Instructions,
+		InputAudioTranscription: req.InputAudioTranscription,
+		TurnDetection:           req.TurnDetection,
+		MaxResponseOutputTokens: req.MaxResponseOutputTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ai.RealtimeSession{
+		ID:           resp.ID,
+		ClientSecret: resp.ClientSecret.Value,
+		ExpiresAt:    resp.ClientSecret.ExpiresAt,
+	}, nil
+}
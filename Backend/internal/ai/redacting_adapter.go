@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"Backend/domain/ai"
+	"context"
+)
+
+// Redactor は LLM に送るテキストから個人情報を記号に置き換える（services.PIIRedactor が実装）。
+// ctx の ai.CallContext の機能・利用者で方針と置き換える値を決め、置き換えなかった場合は nil の Redaction を返す。
+type Redactor interface {
+	Redact(ctx context.Context, texts ...string) ([]string, *ai.Redaction)
+}
+
+// RedactingAdapter はプロンプト・チャットのメッセージ・埋め込みのテキストを置き換えてから内側のクライアントを呼び、
+// 生成したテキストの記号を元の値に戻す。応答キャッシュ・カセットより外側に置くと、それらにも置き換え後のテキストだけが残る。
+// 音声・Web 検索・リアルタイムセッションはそのまま渡す。
+type RedactingAdapter struct {
+	ai.LLMClient
+	redactor Redactor
+}
+
+// NewRedactingAdapter は inner の前段で redactor による個人情報の置き換えを行うアダプターを返す。
+func NewRedactingAdapter(inner ai.LLMClient, redactor Redactor) *RedactingAdapter {
+	return &RedactingAdapter{LLMClient: inner, redactor: redactor}
+}
+
+func (a *RedactingAdapter) GenerateText(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	texts, redaction := a.redactor.Redact(ctx, systemPrompt, userPrompt)
+	if redaction.Len() == 0 {
+		return a.LLMClient.GenerateText(ctx, systemPrompt, userPrompt, opts...)
+	}
+	var restorer *ai.StreamRestorer
+	if onDelta := ai.TextStreamFrom(ctx); onDelta != nil {
+		restorer = ai.NewStreamRestorer(redaction, onDelta)
+		ctx = ai.WithTextStream(ctx, restorer.Write)
+	}
+	out, err := a.LLMClient.GenerateText(ctx, texts[0], texts[1], opts...)
+	if restorer != nil {
+		restorer.Flush()
+	}
+	if err != nil {
+		return "", err
+	}
+	return redaction.Restore(out), nil
+}
+
+func (a *RedactingAdapter) GenerateJSON(ctx context.Context, systemPrompt, userPrompt string, opts ...ai.CallOption) (string, error) {
+	texts, redaction := a.redactor.Redact(ctx, systemPrompt, userPrompt)
+	if redaction.Len() == 0 {
+		return a.LLMClient.GenerateJSON(ctx, systemPrompt, userPrompt, opts...)
+	}
+	out, err := a.LLMClient.GenerateJSON(ctx, texts[0], texts[1], opts...)
+	if err != nil {
+		return "", err
+	}
+	return redaction.RestoreJSON(out), nil
+}
+
+func (a *RedactingAdapter) Chat(ctx context.Context, messages []ai.Message, opts ...ai.CallOption) (string, error) {
+	contents := make([]string, len(messages))
+	for i, m := range messages {
+		contents[i] = m.Content
+	}
+	texts, redaction := a.redactor.Redact(ctx, contents...)
+	if redaction.Len() == 0 {
+		return a.LLMClient.Chat(ctx, messages, opts...)
+	}
+	redacted := make([]ai.Message, len(messages))
+	for i, m := range messages {
+		m.Content = texts[i]
+		redacted[i] = m
+	}
+	out, err := a.LLMClient.Chat(ctx, redacted, opts...)
+	if err != nil {
+		return "", err
+	}
+	return redaction.Restore(out), nil
+}
+
+func (a *RedactingAdapter) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	texts, redaction := a.redactor.Redact(ctx, text)
+	if redaction.Len() == 0 {
+		return a.LLMClient.GenerateEmbedding(ctx, text)
+	}
+	return a.LLMClient.GenerateEmbedding(ctx, texts[0])
+}
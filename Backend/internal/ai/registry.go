@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"Backend/domain/ai"
+	"Backend/internal/config"
+	"Backend/internal/openai"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 組み込みプロバイダ名（LLM_PROVIDER の値）
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai-compatible"
+	ProviderFallback         = "fallback"
+)
+
+// ProviderFactory は設定から LLMClient を生成する。
+type ProviderFactory func(cfg config.LLMConfig, onUsage openai.UsageHook) (ai.LLMClient, error)
+
+// Registry はプロバイダ名と ProviderFactory の対応表。
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]ProviderFactory
+}
+
+// NewRegistry は組み込みプロバイダを登録したレジストリを返す。
+func NewRegistry() *Registry {
+	r := &Registry{factories: make(map[string]ProviderFactory)}
+	r.Register(ProviderOpenAI, newOpenAIProvider)
+	r.Register(ProviderOpenAICompatible, newCompatibleProvider)
+	r.Register(ProviderFallback, func(config.LLMConfig, openai.UsageHook) (ai.LLMClient, error) {
+		return NewFallbackAdapter(), nil
+	})
+	return r
+}
+
+// Register はプロバイダを登録する（同名の登録は上書き）。
+func (r *Registry) Register(name string, factory ProviderFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[strings.ToLower(name)] = factory
+}
+
+// Providers は登録済みのプロバイダ名を返す。
+func (r *Registry) Providers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New は cfg.Provider のプロバイダで LLMClient を生成する。onUsage はトークン使用量の通知先（nil 可）。
+// cfg.CassetteDir が指定されていればカセットアダプターで包む（strict モードではプロバイダを生成しない）。
+func (r *Registry) New(cfg config.LLMConfig, onUsage openai.UsageHook) (ai.LLMClient, error) {
+	if cfg.CassetteDir == "" {
+		return r.newProvider(cfg, onUsage)
+	}
+	var inner ai.LLMClient
+	if cfg.CassetteMode != CassetteStrict {
+		var err error
+		if inner, err = r.newProvider(cfg, onUsage); err != nil {
+			return nil, err
+		}
+	}
+	return NewCassetteAdapter(CassetteConfig{Dir: cfg.CassetteDir, Mode: cfg.CassetteMode, Inner: inner})
+}
+
+func (r *Registry) newProvider(cfg config.LLMConfig, onUsage openai.UsageHook) (ai.LLMClient, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.Provider))
+	if name == "" {
+		name = ProviderOpenAI
+	}
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown llm provider %q (available: %s)", name, strings.Join(r.Providers(), ", "))
+	}
+	return factory(cfg, onUsage)
+}
+
+func newOpenAIProvider(cfg config.LLMConfig, onUsage openai.UsageHook) (ai.LLMClient, error) {
+	client, err := openai.NewFromEnv(cfg.Model)
+	if err != nil {
+		return nil, err
+	}
+	client.OnUsage = onUsage
+	return &OpenAIAdapter{client: client, embeddingModel: cfg.EmbeddingModel}, nil
+}
+
+func newCompatibleProvider(cfg config.LLMConfig, onUsage openai.UsageHook) (ai.LLMClient, error) {
+	return NewCompatibleAdapter(CompatibleConfig{
+		BaseURL:        cfg.BaseURL,
+		APIKey:         cfg.APIKey,
+		Model:          cfg.Model,
+		EmbeddingModel: cfg.EmbeddingModel,
+		OnUsage:        onUsage,
+	})
+}
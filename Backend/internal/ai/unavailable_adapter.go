@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"Backend/domain/ai"
+	"context"
+)
+
+// UnavailableAdapter はすべての呼び出しに ai.ErrProviderUnavailable を返す LLMClient。
+// セカンダリのプロバイダを設定していない場合のサーキットブレーカーの振り替え先として使い、
+// 固定メッセージを正常な応答として返さずに呼び出し元のエラー処理（ルールベースの判定など）に任せる。
+type UnavailableAdapter struct{}
+
+// NewUnavailableAdapter は UnavailableAdapter を返す。
+func NewUnavailableAdapter() ai.LLMClient {
+	return &UnavailableAdapter{}
+}
+
+func (u *UnavailableAdapter) GenerateText(_ context.Context, _, _ string, _ ...ai.CallOption) (string, error) {
+	return "", ai.ErrProviderUnavailable
+}
+
+func (u *UnavailableAdapter) GenerateJSON(_ context.Context, _, _ string, _ ...ai.CallOption) (string, error) {
+	return "", ai.ErrProviderUnavailable
+}
+
+func (u *UnavailableAdapter) Chat(_ context.Context, _ []ai.Message, _ ...ai.CallOption) (string, error) {
+	return "", ai.ErrProviderUnavailable
+}
+
+func (u *UnavailableAdapter) GenerateEmbedding(_ context.Context, _ string) ([]float32, error) {
+	return nil, ai.ErrProviderUnavailable
+}
+
+func (u *UnavailableAdapter) TranscribeAudio(_ context.Context, _ []byte, _ string) (string, error) {
+	return "", ai.ErrProviderUnavailable
+}
+
+func (u *UnavailableAdapter) SynthesizeSpeech(_ context.Context, _, _ string) ([]byte, error) {
+	return nil, ai.ErrProviderUnavailable
+}
+
+func (u *UnavailableAdapter) WebSearch(_ context.Context, _ string) (string, error) {
+	return "", ai.ErrProviderUnavailable
+}
+
+func (u *UnavailableAdapter) CreateRealtimeSession(_ context.Context, _ ai.RealtimeSessionRequest) (*ai.RealtimeSession, error) {
+	return nil, ai.ErrProviderUnavailable
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// LLMConfig は LLM プロバイダの設定。
+// LLM_PROVIDER で使用するプロバイダを切り替える（openai / openai-compatible / fallback）。
+type LLMConfig struct {
+	Provider       string
+	Model          string // 空ならプロバイダの既定モデル
+	EmbeddingModel string
+	// BaseURL・APIKey は openai-compatible（vLLM・Ollama・LM Studio 等のセルフホストサーバー）で使用する
+	BaseURL string
+	APIKey  string
+	// CassetteDir を指定するとプロンプトと応答をフィクスチャに記録・再生する（テスト・オフライン開発用）
+	CassetteDir  string
+	CassetteMode string // record / replay / strict
+	// 応答キャッシュの保存先（memory / mysql / off）と memory 時の最大件数
+	CacheStore string
+	CacheSize  int
+	// サーキットブレーカーの連続失敗回数の閾値と open の継続時間（0 なら既定値）
+	BreakerThreshold   int
+	BreakerOpenTimeout time.Duration
+	// 利用者ごとの 1 日・1 か月（UTC）の LLM 予算（USD、0 なら無制限）と超過時の動作（refuse / downgrade）・格下げ先のモデル
+	UserDailyBudgetUSD   float64
+	UserMonthlyBudgetUSD float64
+	BudgetAction         string
+	BudgetDowngradeModel string
+	// Secondary はブレーカーが open の間に振り替えるプロバイダ（LLM_SECONDARY_*）。
+	// 未設定なら UnavailableAdapter が ai.ErrProviderUnavailable を返し、固定メッセージで応答するのは LLM_SECONDARY_PROVIDER=fallback のときだけ
+	Secondary *LLMConfig
+}
+
+func LoadLLMConfig() *LLMConfig {
+	cacheSize, _ := strconv.Atoi(os.Getenv("LLM_CACHE_SIZE"))
+	breakerThreshold, _ := strconv.Atoi(os.Getenv("LLM_BREAKER_FAILURE_THRESHOLD"))
+	breakerOpenSeconds, _ := strconv.Atoi(os.Getenv("LLM_BREAKER_OPEN_SECONDS"))
+	dailyBudget, _ := strconv.ParseFloat(os.Getenv("LLM_USER_DAILY_BUDGET_USD"), 64)
+	monthlyBudget, _ := strconv.ParseFloat(os.Getenv("LLM_USER_MONTHLY_BUDGET_USD"), 64)
+	cfg := &LLMConfig{
+		Provider:       get("LLM_PROVIDER", "openai"),
+		Model:          getFirst("LLM_MODEL", "OPENAI_MODEL"),
+		EmbeddingModel: getFirst("LLM_EMBEDDING_MODEL", "OPENAI_EMBEDDING_MODEL"),
+		BaseURL:        os.Getenv("LLM_BASE_URL"),
+		APIKey:         os.Getenv("LLM_API_KEY"),
+		CassetteDir:    os.Getenv("LLM_CASSETTE_DIR"),
+		CassetteMode:   get("LLM_CASSETTE_MODE", "replay"),
+		CacheStore:     get("LLM_CACHE_STORE", "memory"),
+		CacheSize:      cacheSize,
+
+		BreakerThreshold:   breakerThreshold,
+		BreakerOpenTimeout: time.Duration(breakerOpenSeconds) * time.Second,
+
+		UserDailyBudgetUSD:   dailyBudget,
+		UserMonthlyBudgetUSD: monthlyBudget,
+		BudgetAction:         get("LLM_BUDGET_ACTION", "refuse"),
+		BudgetDowngradeModel: get("LLM_BUDGET_DOWNGRADE_MODEL", "gpt-4o-mini"),
+	}
+	if provider := os.Getenv("LLM_SECONDARY_PROVIDER"); provider != "" {
+		cfg.Secondary = &LLMConfig{
+			Provider:       provider,
+			Model:          os.Getenv("LLM_SECONDARY_MODEL"),
+			EmbeddingModel: os.Getenv("LLM_SECONDARY_EMBEDDING_MODEL"),
+			BaseURL:        os.Getenv("LLM_SECONDARY_BASE_URL"),
+			APIKey:         os.Getenv("LLM_SECONDARY_API_KEY"),
+		}
+	}
+	return cfg
+}
@@ -9,12 +9,18 @@ import (
 
 type AdminAuditController struct {
 	service *services.AuditLogService
+	guard   *services.InputGuard
 }
 
 func NewAdminAuditController(service *services.AuditLogService) *AdminAuditController {
 	return &AdminAuditController{service: service}
 }
 
+// SetInputGuard 入力ガードの検出履歴の参照に使う
+func (c *AdminAuditController) SetInputGuard(guard *services.InputGuard) {
+	c.guard = guard
+}
+
 func (c *AdminAuditController) List(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -36,3 +42,26 @@ func (c *AdminAuditController) List(w http.ResponseWriter, r *http.Request) {
 		"logs": logs,
 	})
 }
+
+// FlaggedInputs 入力ガードが検出した入力の履歴（?feature= で機能を絞る）
+func (c *AdminAuditController) FlaggedInputs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if v, err := strconv.Atoi(value); err == nil && v > 0 && v <= 200 {
+			limit = v
+		}
+	}
+	inputs, err := c.guard.List(r.URL.Query().Get("feature"), limit)
+	if err != nil {
+		http.Error(w, "failed to fetch flagged inputs", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"inputs": inputs,
+	})
+}
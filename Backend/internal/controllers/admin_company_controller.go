@@ -1,9 +1,9 @@
 package controllers
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/repository"
 	"Backend/internal/models"
-	"Backend/internal/openai"
 	"Backend/internal/services"
 	"context"
 	"encoding/json"
@@ -19,17 +19,23 @@ type AdminCompanyController struct {
 	repo        repository.CompanyRepository
 	audit       *services.AuditLogService
 	gbiz        *services.GBizInfoService
-	openaiClient *openai.Client
+	llm         ai.LLMClient
+	semantic    *services.SemanticSearchService
 }
 
-func NewAdminCompanyController(repo repository.CompanyRepository, audit *services.AuditLogService, gbiz *services.GBizInfoService, openaiClient ...*openai.Client) *AdminCompanyController {
+func NewAdminCompanyController(repo repository.CompanyRepository, audit *services.AuditLogService, gbiz *services.GBizInfoService, llm ...ai.LLMClient) *AdminCompanyController {
 	ctrl := &AdminCompanyController{repo: repo, audit: audit, gbiz: gbiz}
-	if len(openaiClient) > 0 {
-		ctrl.openaiClient = openaiClient[0]
+	if len(llm) > 0 {
+		ctrl.llm = llm[0]
 	}
 	return ctrl
 }
 
+// SetSemanticSearchService 企業の意味検索を注入する（埋め込みの再作成に使う）
+func (c *AdminCompanyController) SetSemanticSearchService(semantic *services.SemanticSearchService) {
+	c.semantic = semantic
+}
+
 func (c *AdminCompanyController) ListOrCreate(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -52,6 +58,10 @@ func (c *AdminCompanyController) Detail(w http.ResponseWriter, r *http.Request)
 		c.searchGBiz(w, r)
 		return
 	}
+	if idStr == "semantic-index" {
+		c.semanticIndex(w, r)
+		return
+	}
 	if strings.HasSuffix(idStr, "/gbiz-sync") {
 		c.syncGBiz(w, r, strings.TrimSuffix(idStr, "/gbiz-sync"))
 		return
@@ -84,6 +94,37 @@ func (c *AdminCompanyController) Detail(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// semanticIndex GET で意味検索のインデックスの状態を返し、POST で埋め込みの再作成をバックグラウンドで始める
+func (c *AdminCompanyController) semanticIndex(w http.ResponseWriter, r *http.Request) {
+	if c.semantic == nil {
+		http.Error(w, "semantic search is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c.semantic.Status())
+	case http.MethodPost:
+		if err := c.semantic.StartReindex(); err != nil {
+			switch {
+			case errors.Is(err, services.ErrSemanticReindexRunning):
+				http.Error(w, "reindex is already running", http.StatusConflict)
+			case errors.Is(err, services.ErrSemanticSearchUnavailable):
+				http.Error(w, "semantic search is not available", http.StatusServiceUnavailable)
+			default:
+				http.Error(w, "failed to start reindex", http.StatusInternalServerError)
+			}
+			return
+		}
+		c.audit.Record(actorEmail(r), "company.semantic_reindex", "company", 0, nil)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(c.semantic.Status())
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 func (c *AdminCompanyController) publish(w http.ResponseWriter, r *http.Request, idStr string) {
 	if r.Method != http.MethodPatch {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -105,7 +146,7 @@ func (c *AdminCompanyController) publish(w http.ResponseWriter, r *http.Request,
 		http.Error(w, "failed to publish company", http.StatusInternalServerError)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "company.publish", "company", company.ID, map[string]interface{}{
 		"name": company.Name,
 	})
@@ -133,7 +174,7 @@ func (c *AdminCompanyController) reject(w http.ResponseWriter, r *http.Request,
 		http.Error(w, "failed to reject company", http.StatusInternalServerError)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "company.reject", "company", company.ID, map[string]interface{}{
 		"name": company.Name,
 	})
@@ -180,7 +221,7 @@ func (c *AdminCompanyController) create(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "failed to create company", http.StatusInternalServerError)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "company.create", "company", payload.ID, map[string]interface{}{
 		"name": payload.Name,
 	})
@@ -218,7 +259,7 @@ func (c *AdminCompanyController) update(w http.ResponseWriter, r *http.Request,
 		http.Error(w, "failed to update company", http.StatusInternalServerError)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "company.update", "company", company.ID, map[string]interface{}{
 		"name": company.Name,
 	})
@@ -274,7 +315,7 @@ func (c *AdminCompanyController) syncGBiz(w http.ResponseWriter, r *http.Request
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "company.gbiz_sync", "company", uint(id), map[string]interface{}{
 		"status": result.Status,
 	})
@@ -294,8 +335,8 @@ func (c *AdminCompanyController) fetchTechStack(w http.ResponseWriter, r *http.R
 		http.Error(w, "invalid company id", http.StatusBadRequest)
 		return
 	}
-	if c.openaiClient == nil {
-		http.Error(w, "openai client not configured", http.StatusServiceUnavailable)
+	if c.llm == nil {
+		http.Error(w, "llm client not configured", http.StatusServiceUnavailable)
 		return
 	}
 	company, err := c.repo.FindByID(uint(id))
@@ -318,7 +359,7 @@ func (c *AdminCompanyController) fetchTechStack(w http.ResponseWriter, r *http.R
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
-	text, err := c.openaiClient.WebSearchQuery(ctx, prompt)
+	text, err := c.llm.WebSearch(ctx, prompt)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("web search failed: %v", err), http.StatusInternalServerError)
 		return
@@ -369,7 +410,7 @@ func (c *AdminCompanyController) fetchTechStack(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "company.tech_stack_search", "company", company.ID, map[string]interface{}{
 		"name": company.Name,
 	})
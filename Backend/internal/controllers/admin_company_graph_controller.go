@@ -131,7 +131,7 @@ func (c *AdminCompanyGraphController) Crawl(w http.ResponseWriter, r *http.Reque
 		"relations_synced": relSynced,
 	})
 
-	adminEmail := r.Header.Get("X-Admin-Email")
+	adminEmail := actorEmail(r)
 	if adminEmail != "" && c.audit != nil {
 		c.audit.Record(adminEmail, "company_graph_crawl", "pipeline", 0, map[string]interface{}{
 			"sites": req.Sites,
@@ -1,15 +1,23 @@
 package controllers
 
 import (
+	"Backend/internal/models"
 	"Backend/internal/services"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type AdminCostsController struct {
 	costService          *services.APICostService
 	realtimeUsageService *services.RealtimeUsageService
+	audit                *services.AuditLogService
+	budget               *services.LLMBudgetService
+	pricing              *services.PricingService
+	repricing            *services.CostRepricingService
 }
 
 func NewAdminCostsController(costService *services.APICostService, realtimeUsageService *services.RealtimeUsageService) *AdminCostsController {
@@ -19,6 +27,22 @@ func NewAdminCostsController(costService *services.APICostService, realtimeUsage
 	}
 }
 
+// SetAuditLogService はキャッシュ削除を記録する監査ログを設定する
+func (c *AdminCostsController) SetAuditLogService(audit *services.AuditLogService) {
+	c.audit = audit
+}
+
+// SetBudgetService は利用者ごとの LLM 予算の参照・上書きを有効にする
+func (c *AdminCostsController) SetBudgetService(budget *services.LLMBudgetService) {
+	c.budget = budget
+}
+
+// SetPricingService はモデル単価表の参照・更新と料金の再計算を有効にする
+func (c *AdminCostsController) SetPricingService(pricing *services.PricingService, repricing *services.CostRepricingService) {
+	c.pricing = pricing
+	c.repricing = repricing
+}
+
 // Summary handles GET /api/admin/costs/summary
 func (c *AdminCostsController) Summary(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -63,6 +87,7 @@ func (c *AdminCostsController) Summary(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"current_month_cost_usd": monthTotal,
 		"model_breakdown":        modelBreakdown,
+		"llm_cache":              c.costService.GetCacheStats(),
 		"realtime": map[string]interface{}{
 			"current_month_cost_usd": realtimeMonthTotal,
 			"active_connections":     activeConnections,
@@ -130,3 +155,365 @@ func (c *AdminCostsController) Monthly(w http.ResponseWriter, r *http.Request) {
 		"realtime_monthly": realtimeRows,
 	})
 }
+
+// LLMCache handles GET /api/admin/llm-cache (ヒット・ミス数) and DELETE /api/admin/llm-cache?scope=company_reading (scope 省略で全件削除)
+func (c *AdminCostsController) LLMCache(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"stats": c.costService.GetCacheStats()})
+	case http.MethodDelete:
+		scope := strings.TrimSpace(r.URL.Query().Get("scope"))
+		deleted, err := c.costService.PurgeResponseCache(r.Context(), scope)
+		if err != nil {
+			if errors.Is(err, services.ErrResponseCacheDisabled) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.audit.Record(actorEmail(r), "llm_cache.purge", "llm_cache", 0, map[string]interface{}{"scope": scope, "deleted": deleted})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"scope": scope, "deleted": deleted})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// costSince は ?days=（既定 30、最大 90）の集計開始日時を返す
+func costSince(r *http.Request) (time.Time, int) {
+	days := parseIntQuery(r, "days", 30)
+	if days > 90 {
+		days = 90
+	}
+	return time.Now().UTC().AddDate(0, 0, -days), days
+}
+
+// Users handles GET /api/admin/costs/users?days=30&limit=50
+func (c *AdminCostsController) Users(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	since, days := costSince(r)
+	limit := parseIntQuery(r, "limit", 50)
+	if limit > 500 {
+		limit = 500
+	}
+	rows, err := c.costService.GetUserBreakdown(since, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"days":  days,
+		"users": rows,
+	})
+}
+
+// UserDetail handles GET /api/admin/costs/users/{userID}?days=30 (機能別の内訳と予算の状況)
+func (c *AdminCostsController) UserDetail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, err := strconv.ParseUint(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/costs/users/"), "/"), 10, 32)
+	if err != nil || userID == 0 {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	since, days := costSince(r)
+	features, err := c.costService.GetFeatureBreakdown(since, uint(userID))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := map[string]interface{}{
+		"user_id":  userID,
+		"days":     days,
+		"features": features,
+	}
+	if c.budget != nil {
+		status, err := c.budget.Status(uint(userID))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp["budget"] = status
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Features handles GET /api/admin/costs/features?days=30
+func (c *AdminCostsController) Features(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	since, days := costSince(r)
+	rows, err := c.costService.GetFeatureBreakdown(since, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"days":     days,
+		"features": rows,
+	})
+}
+
+// Budgets handles GET /api/admin/costs/budgets (既定の予算と利用者ごとの上書き設定)
+func (c *AdminCostsController) Budgets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if c.budget == nil {
+		http.Error(w, "llm budgets are not enabled", http.StatusNotFound)
+		return
+	}
+	overrides, err := c.budget.ListOverrides()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"defaults":  c.budget.Defaults(),
+		"overrides": overrides,
+	})
+}
+
+// UserBudget handles PUT /api/admin/costs/budgets/{userID} (上書き) and DELETE (既定の予算に戻す)
+func (c *AdminCostsController) UserBudget(w http.ResponseWriter, r *http.Request) {
+	if c.budget == nil {
+		http.Error(w, "llm budgets are not enabled", http.StatusNotFound)
+		return
+	}
+	userID, err := strconv.ParseUint(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/costs/budgets/"), "/"), 10, 32)
+	if err != nil || userID == 0 {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var payload struct {
+			DailyLimitUSD   float64 `json:"daily_limit_usd"`
+			MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+			Action          string  `json:"action"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		budget, err := c.budget.SetOverride(uint(userID), payload.DailyLimitUSD, payload.MonthlyLimitUSD, payload.Action, actorEmail(r))
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidBudget) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.audit.Record(actorEmail(r), "llm_budget.update", "user", budget.UserID, map[string]interface{}{
+			"daily_limit_usd":   budget.DailyLimitUSD,
+			"monthly_limit_usd": budget.MonthlyLimitUSD,
+			"action":            budget.Action,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(budget)
+	case http.MethodDelete:
+		if err := c.budget.DeleteOverride(uint(userID)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.audit.Record(actorEmail(r), "llm_budget.delete", "user", uint(userID), nil)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// parsePricingDate は YYYY-MM-DD（UTC の 0 時）または RFC3339 の日時を読む
+func parsePricingDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// Pricing handles GET /api/admin/costs/pricing?days=30 (単価表と、期間内に単価未登録で記録されたモデル)
+func (c *AdminCostsController) Pricing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if c.pricing == nil {
+		http.Error(w, "model pricing is not enabled", http.StatusNotFound)
+		return
+	}
+	prices, err := c.pricing.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	since, days := costSince(r)
+	unpriced, err := c.costService.GetUnpricedModels(since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := map[string]interface{}{
+		"days":            days,
+		"prices":          prices,
+		"unpriced_models": unpriced,
+	}
+	if c.realtimeUsageService != nil {
+		resp["realtime"] = map[string]interface{}{
+			"model":          services.RealtimeModel(),
+			"per_minute_usd": c.realtimeUsageService.RatePerMinute(time.Now()),
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ManagePricing handles POST /api/admin/costs/pricing/rates (単価の追加・更新), DELETE /api/admin/costs/pricing/rates/{id},
+// and POST /api/admin/costs/pricing/recalculate (過去の料金の再計算)
+func (c *AdminCostsController) ManagePricing(w http.ResponseWriter, r *http.Request) {
+	if c.pricing == nil || c.repricing == nil {
+		http.Error(w, "model pricing is not enabled", http.StatusNotFound)
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/costs/pricing/"), "/")
+	switch {
+	case rest == "rates" && r.Method == http.MethodPost:
+		c.upsertPrice(w, r)
+	case strings.HasPrefix(rest, "rates/") && r.Method == http.MethodDelete:
+		id, err := strconv.ParseUint(strings.TrimPrefix(rest, "rates/"), 10, 32)
+		if err != nil || id == 0 {
+			http.Error(w, "invalid price id", http.StatusBadRequest)
+			return
+		}
+		price, err := c.pricing.Delete(uint(id))
+		if err != nil {
+			if errors.Is(err, services.ErrPriceNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.audit.Record(actorEmail(r), "model_price.delete", "model_price", price.ID, map[string]interface{}{
+			"model":          price.Model,
+			"effective_from": price.EffectiveFrom,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	case rest == "recalculate" && r.Method == http.MethodPost:
+		c.recalculate(w, r)
+	case rest == "rates" || strings.HasPrefix(rest, "rates/") || rest == "recalculate":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (c *AdminCostsController) upsertPrice(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Model                 string  `json:"model"`
+		EffectiveFrom         string  `json:"effective_from"`
+		InputPerMTokUSD       float64 `json:"input_per_mtok_usd"`
+		CachedInputPerMTokUSD float64 `json:"cached_input_per_mtok_usd"`
+		OutputPerMTokUSD      float64 `json:"output_per_mtok_usd"`
+		PerMinuteUSD          float64 `json:"per_minute_usd"`
+		Note                  string  `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	effectiveFrom, err := parsePricingDate(payload.EffectiveFrom)
+	if err != nil {
+		http.Error(w, "effective_from must be YYYY-MM-DD or RFC3339", http.StatusBadRequest)
+		return
+	}
+	price, err := c.pricing.Upsert(models.ModelPrice{
+		Model:                 payload.Model,
+		EffectiveFrom:         effectiveFrom,
+		InputPerMTokUSD:       payload.InputPerMTokUSD,
+		CachedInputPerMTokUSD: payload.CachedInputPerMTokUSD,
+		OutputPerMTokUSD:      payload.OutputPerMTokUSD,
+		PerMinuteUSD:          payload.PerMinuteUSD,
+		Note:                  payload.Note,
+	}, actorEmail(r))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPrice) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.audit.Record(actorEmail(r), "model_price.update", "model_price", price.ID, map[string]interface{}{
+		"model":                     price.Model,
+		"effective_from":            price.EffectiveFrom,
+		"input_per_mtok_usd":        price.InputPerMTokUSD,
+		"cached_input_per_mtok_usd": price.CachedInputPerMTokUSD,
+		"output_per_mtok_usd":       price.OutputPerMTokUSD,
+		"per_minute_usd":            price.PerMinuteUSD,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(price)
+}
+
+func (c *AdminCostsController) recalculate(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		From   string `json:"from"`
+		To     string `json:"to"`
+		Model  string `json:"model"`
+		DryRun bool   `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	opts := services.RepricingOptions{Model: payload.Model, DryRun: payload.DryRun}
+	var err error
+	if opts.From, err = parsePricingDate(payload.From); err != nil {
+		http.Error(w, "from must be YYYY-MM-DD or RFC3339", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(payload.To) != "" {
+		if opts.To, err = parsePricingDate(payload.To); err != nil {
+			http.Error(w, "to must be YYYY-MM-DD or RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+	result, err := c.repricing.Recalculate(r.Context(), opts)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRepricing) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !result.Options.DryRun {
+		c.audit.Record(actorEmail(r), "model_price.recalculate", "api_call_log", 0, map[string]interface{}{
+			"from":               result.Options.From,
+			"to":                 result.Options.To,
+			"model":              result.Options.Model,
+			"calls_changed":      result.Calls.Changed,
+			"calls_delta_usd":    result.Calls.DeltaUSD,
+			"realtime_changed":   result.Realtime.Changed,
+			"realtime_delta_usd": result.Realtime.DeltaUSD,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
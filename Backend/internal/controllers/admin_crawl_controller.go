@@ -96,7 +96,7 @@ func (c *AdminCrawlController) createSource(w http.ResponseWriter, r *http.Reque
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "crawl_source.create", "crawl_source", source.ID, map[string]interface{}{
 		"name": source.Name,
 	})
@@ -115,7 +115,7 @@ func (c *AdminCrawlController) updateSource(w http.ResponseWriter, r *http.Reque
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "crawl_source.update", "crawl_source", source.ID, map[string]interface{}{
 		"name": source.Name,
 	})
@@ -141,7 +141,7 @@ func (c *AdminCrawlController) runSource(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "crawl_source.run", "crawl_source", uint(id), map[string]interface{}{
 		"status": run.Status,
 	})
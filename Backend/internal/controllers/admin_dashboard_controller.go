@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"Backend/domain/ai"
 	"Backend/internal/models"
 	"Backend/internal/repositories"
 	"encoding/json"
@@ -15,6 +16,7 @@ type AdminDashboardController struct {
 	userRepo    *repositories.UserRepository
 	sessionRepo *repositories.InterviewSessionRepository
 	reportRepo  *repositories.InterviewReportRepository
+	llm         LLMBreakerStatusProvider
 }
 
 func NewAdminDashboardController(
@@ -29,6 +31,28 @@ func NewAdminDashboardController(
 	}
 }
 
+// SetLLMBreaker sets the LLM circuit breaker whose state is shown on the dashboard (optional).
+func (c *AdminDashboardController) SetLLMBreaker(llm LLMBreakerStatusProvider) {
+	c.llm = llm
+}
+
+// LLMStatus handles GET /api/admin/dashboard/llm-status
+func (c *AdminDashboardController) LLMStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	statuses := []ai.BreakerStatus{}
+	if c.llm != nil {
+		statuses = c.llm.BreakerStatuses()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"degraded":     llmDegraded(statuses),
+		"capabilities": statuses,
+	})
+}
+
 type UserScoreSummary struct {
 	UserID        uint       `json:"user_id"`
 	Name          string     `json:"name"`
@@ -77,7 +77,7 @@ func (c *AdminJobController) JobPositionAction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	switch action {
 	case "publish":
 		position.DataStatus = "published"
@@ -170,7 +170,7 @@ func (c *AdminJobController) GraduateEmploymentDetail(w http.ResponseWriter, r *
 			http.Error(w, "failed to update", http.StatusInternalServerError)
 			return
 		}
-		actor := r.Header.Get("X-Admin-Email")
+		actor := actorEmail(r)
 		c.audit.Record(actor, "graduate_employment.update", "graduate_employment", entry.ID, map[string]interface{}{
 			"company_id": entry.CompanyID,
 		})
@@ -226,7 +226,7 @@ func (c *AdminJobController) createJobPosition(w http.ResponseWriter, r *http.Re
 		http.Error(w, "failed to create job position", http.StatusInternalServerError)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "job_position.create", "company_job_position", payload.ID, map[string]interface{}{
 		"company_id": payload.CompanyID,
 		"title":      payload.Title,
@@ -300,7 +300,7 @@ func (c *AdminJobController) createGraduateEmployment(w http.ResponseWriter, r *
 		http.Error(w, "failed to create graduate employment", http.StatusInternalServerError)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "graduate_employment.create", "graduate_employment", entry.ID, map[string]interface{}{
 		"company_id": entry.CompanyID,
 	})
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"Backend/internal/services"
+	"Backend/internal/services/prompts"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type AdminPromptController struct {
+	service *services.PromptService
+	audit   *services.AuditLogService
+}
+
+func NewAdminPromptController(service *services.PromptService, audit *services.AuditLogService) *AdminPromptController {
+	return &AdminPromptController{service: service, audit: audit}
+}
+
+// List GET /api/admin/prompts
+func (c *AdminPromptController) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	summaries, err := c.service.List()
+	if err != nil {
+		http.Error(w, "failed to list prompts", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"prompts": summaries,
+	})
+}
+
+// Route /api/admin/prompts/{name}/versions|publish|rollback
+func (c *AdminPromptController) Route(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/prompts/"), "/")
+	name, action, _ := strings.Cut(path, "/")
+	if name == "" {
+		http.Error(w, "prompt name is required", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case action == "versions" && r.Method == http.MethodGet:
+		c.versions(w, name)
+	case action == "versions" && r.Method == http.MethodPost:
+		c.createVersion(w, r, name)
+	case action == "publish" && r.Method == http.MethodPost:
+		c.publish(w, r, name)
+	case action == "rollback" && r.Method == http.MethodPost:
+		c.rollback(w, r, name)
+	case action == "versions" || action == "publish" || action == "rollback":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// versions GET /api/admin/prompts/{name}/versions
+func (c *AdminPromptController) versions(w http.ResponseWriter, name string) {
+	versions, err := c.service.Versions(name)
+	if err != nil {
+		writePromptError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"name":     name,
+		"versions": versions,
+	})
+}
+
+// createVersion POST /api/admin/prompts/{name}/versions
+func (c *AdminPromptController) createVersion(w http.ResponseWriter, r *http.Request, name string) {
+	var payload struct {
+		Body        string `json:"body"`
+		Description string `json:"description"`
+		Publish     bool   `json:"publish"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	actor := actorEmail(r)
+	created, err := c.service.CreateVersion(name, payload.Body, payload.Description, payload.Publish, actor)
+	if err != nil {
+		writePromptError(w, err)
+		return
+	}
+	c.audit.Record(actor, "prompt.create", "prompt", 0, map[string]interface{}{
+		"name":      name,
+		"version":   created.Version,
+		"published": payload.Publish,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// publish POST /api/admin/prompts/{name}/publish
+func (c *AdminPromptController) publish(w http.ResponseWriter, r *http.Request, name string) {
+	var payload struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Version == "" {
+		http.Error(w, "version is required", http.StatusBadRequest)
+		return
+	}
+	summary, err := c.service.Publish(name, payload.Version, actorEmail(r))
+	if err != nil {
+		writePromptError(w, err)
+		return
+	}
+	c.audit.Record(actorEmail(r), "prompt.publish", "prompt", 0, map[string]interface{}{
+		"name":    name,
+		"version": summary.ActiveVersion,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// rollback POST /api/admin/prompts/{name}/rollback
+func (c *AdminPromptController) rollback(w http.ResponseWriter, r *http.Request, name string) {
+	summary, err := c.service.Rollback(name)
+	if err != nil {
+		writePromptError(w, err)
+		return
+	}
+	c.audit.Record(actorEmail(r), "prompt.rollback", "prompt", 0, map[string]interface{}{
+		"name":    name,
+		"version": summary.ActiveVersion,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+func writePromptError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, prompts.ErrUnknownPrompt), errors.Is(err, prompts.ErrVersionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, prompts.ErrInvalidTemplate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "failed to update prompt", http.StatusInternalServerError)
+	}
+}
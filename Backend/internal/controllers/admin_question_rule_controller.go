@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"Backend/internal/models"
+	"Backend/internal/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AdminQuestionRuleController 回答の加点ルールと AI 質問テンプレートの管理API
+type AdminQuestionRuleController struct {
+	service *services.QuestionRuleService
+	audit   *services.AuditLogService
+}
+
+func NewAdminQuestionRuleController(service *services.QuestionRuleService, audit *services.AuditLogService) *AdminQuestionRuleController {
+	return &AdminQuestionRuleController{service: service, audit: audit}
+}
+
+// weightRulePayload 加点ルールの登録・更新の内容。condition は JSON オブジェクト（JSON 文字列でもよい）
+type weightRulePayload struct {
+	Name        string          `json:"name"`
+	Condition   json.RawMessage `json:"condition"`
+	WeightBoost int             `json:"weight_boost"`
+	Description string          `json:"description"`
+	Priority    int             `json:"priority"`
+	IsActive    *bool           `json:"is_active"` // 省略時は有効
+}
+
+func (p weightRulePayload) toModel() models.WeightRule {
+	condition := string(p.Condition)
+	var s string
+	if json.Unmarshal(p.Condition, &s) == nil {
+		condition = s
+	}
+	return models.WeightRule{
+		Name:        p.Name,
+		Condition:   condition,
+		WeightBoost: p.WeightBoost,
+		Description: p.Description,
+		Priority:    p.Priority,
+		IsActive:    p.IsActive == nil || *p.IsActive,
+	}
+}
+
+// questionTemplatePayload AI 質問テンプレートの登録・更新の内容
+type questionTemplatePayload struct {
+	Category    string   `json:"category"`
+	Prompt      string   `json:"prompt"`
+	BaseWeight  int      `json:"base_weight"`
+	ContextKeys []string `json:"context_keys"`
+	IsActive    *bool    `json:"is_active"` // 省略時は有効
+}
+
+func (p questionTemplatePayload) toModel() models.AIQuestionTemplate {
+	keys := p.ContextKeys
+	if keys == nil {
+		keys = []string{}
+	}
+	raw, _ := json.Marshal(keys)
+	return models.AIQuestionTemplate{
+		Category:    p.Category,
+		Prompt:      p.Prompt,
+		BaseWeight:  p.BaseWeight,
+		ContextKeys: string(raw),
+		IsActive:    p.IsActive == nil || *p.IsActive,
+	}
+}
+
+// WeightRules GET/POST /api/admin/weight-rules
+func (c *AdminQuestionRuleController) WeightRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		rules, invalid, err := c.service.ListWeightRules()
+		if err != nil {
+			http.Error(w, "failed to list weight rules", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"rules": rules, "invalid_rules": invalid})
+	case http.MethodPost:
+		var payload weightRulePayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		rule, err := c.service.CreateWeightRule(payload.toModel())
+		if err != nil {
+			writeQuestionRuleError(w, err)
+			return
+		}
+		c.audit.Record(actorEmail(r), "weight_rule.create", "weight_rule", rule.ID, map[string]interface{}{
+			"name":         rule.Name,
+			"weight_boost": rule.WeightBoost,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(rule)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// WeightRuleRoute /api/admin/weight-rules/{id} と /api/admin/weight-rules/dry-run
+func (c *AdminQuestionRuleController) WeightRuleRoute(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/weight-rules/"), "/")
+	if rest == "dry-run" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		c.dryRun(w, r)
+		return
+	}
+	id, ok := parseQuestionRuleID(w, rest)
+	if !ok {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		rule, err := c.service.GetWeightRule(id)
+		if err != nil {
+			writeQuestionRuleError(w, err)
+			return
+		}
+		writeJSON(w, rule)
+	case http.MethodPut:
+		var payload weightRulePayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		rule, err := c.service.UpdateWeightRule(id, payload.toModel())
+		if err != nil {
+			writeQuestionRuleError(w, err)
+			return
+		}
+		c.audit.Record(actorEmail(r), "weight_rule.update", "weight_rule", rule.ID, map[string]interface{}{
+			"name":         rule.Name,
+			"weight_boost": rule.WeightBoost,
+			"is_active":    rule.IsActive,
+		})
+		writeJSON(w, rule)
+	case http.MethodDelete:
+		if err := c.service.DeleteWeightRule(id); err != nil {
+			writeQuestionRuleError(w, err)
+			return
+		}
+		c.audit.Record(actorEmail(r), "weight_rule.delete", "weight_rule", id, nil)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// dryRun POST /api/admin/weight-rules/dry-run
+// 会話（session_id または messages）の回答ごとに発火するルールと加点後のスコアを返す。スコアは保存しない
+func (c *AdminQuestionRuleController) dryRun(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		services.WeightRuleDryRunRequest
+		Rules []weightRulePayload `json:"rules"` // 任意: 保存前のルールで評価する
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	req := payload.WeightRuleDryRunRequest
+	if payload.Rules != nil {
+		req.Rules = make([]models.WeightRule, 0, len(payload.Rules))
+		for _, rule := range payload.Rules {
+			req.Rules = append(req.Rules, rule.toModel())
+		}
+	}
+	if req.SessionID == "" && len(req.Messages) == 0 {
+		http.Error(w, "session_id or messages is required", http.StatusBadRequest)
+		return
+	}
+	result, err := c.service.DryRunWeightRules(req)
+	if err != nil {
+		writeQuestionRuleError(w, err)
+		return
+	}
+	writeJSON(w, result)
+}
+
+// QuestionTemplates GET/POST /api/admin/question-templates?category=xxx
+func (c *AdminQuestionRuleController) QuestionTemplates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		templates, err := c.service.ListQuestionTemplates(r.URL.Query().Get("category"))
+		if err != nil {
+			http.Error(w, "failed to list question templates", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"templates":    templates,
+			"context_keys": services.QuestionTemplateContextKeys,
+		})
+	case http.MethodPost:
+		var payload questionTemplatePayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		template, err := c.service.CreateQuestionTemplate(payload.toModel())
+		if err != nil {
+			writeQuestionRuleError(w, err)
+			return
+		}
+		c.audit.Record(actorEmail(r), "question_template.create", "question_template", template.ID, map[string]interface{}{
+			"category": template.Category,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(template)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// QuestionTemplateRoute GET/PUT/DELETE /api/admin/question-templates/{id}
+func (c *AdminQuestionRuleController) QuestionTemplateRoute(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseQuestionRuleID(w, strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/question-templates/"), "/"))
+	if !ok {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		template, err := c.service.GetQuestionTemplate(id)
+		if err != nil {
+			writeQuestionRuleError(w, err)
+			return
+		}
+		writeJSON(w, template)
+	case http.MethodPut:
+		var payload questionTemplatePayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		template, err := c.service.UpdateQuestionTemplate(id, payload.toModel())
+		if err != nil {
+			writeQuestionRuleError(w, err)
+			return
+		}
+		c.audit.Record(actorEmail(r), "question_template.update", "question_template", template.ID, map[string]interface{}{
+			"category":  template.Category,
+			"is_active": template.IsActive,
+		})
+		writeJSON(w, template)
+	case http.MethodDelete:
+		if err := c.service.DeleteQuestionTemplate(id); err != nil {
+			writeQuestionRuleError(w, err)
+			return
+		}
+		c.audit.Record(actorEmail(r), "question_template.delete", "question_template", id, nil)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func parseQuestionRuleID(w http.ResponseWriter, raw string) (uint, bool) {
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || id == 0 {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func writeQuestionRuleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrWeightRuleNotFound), errors.Is(err, services.ErrQuestionTemplateNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidWeightRule), errors.Is(err, services.ErrInvalidQuestionTemplate), errors.Is(err, services.ErrEmptyTranscript):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrQuestionTemplateInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"Backend/internal/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type AdminRateLimitController struct {
+	service *services.RateLimitService
+	audit   *services.AuditLogService
+}
+
+func NewAdminRateLimitController(service *services.RateLimitService, audit *services.AuditLogService) *AdminRateLimitController {
+	return &AdminRateLimitController{service: service, audit: audit}
+}
+
+// List GET /api/admin/rate-limits
+func (c *AdminRateLimitController) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rate_limits": c.service.List(),
+	})
+}
+
+// Update PUT /api/admin/rate-limits/{group}
+func (c *AdminRateLimitController) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	group := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/rate-limits/"), "/")
+	if group == "" {
+		http.Error(w, "group is required", http.StatusBadRequest)
+		return
+	}
+	var payload services.RateLimitUpdate
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	actor := actorEmail(r)
+	updated, err := c.service.Update(group, payload, actor)
+	if err != nil {
+		switch {
+		case err.Error() == "not found":
+			http.Error(w, "rate limit group not found", http.StatusNotFound)
+		case errors.Is(err, services.ErrInvalidRateLimit):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "failed to update rate limit", http.StatusInternalServerError)
+		}
+		return
+	}
+	c.audit.Record(actor, "rate_limit.update", "rate_limit", 0, map[string]interface{}{
+		"group":           group,
+		"enabled":         updated.Enabled,
+		"user_per_minute": payload.UserPerMinute,
+		"user_burst":      payload.UserBurst,
+		"ip_per_minute":   payload.IPPerMinute,
+		"ip_burst":        payload.IPBurst,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"Backend/internal/services"
+	"Backend/internal/services/prompts"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,13 +12,19 @@ import (
 
 // AdminScoreValidationController スコア精度検証・A/Bテスト管理API
 type AdminScoreValidationController struct {
-	svc *services.ScoreValidationService
+	svc   *services.ScoreValidationService
+	audit *services.AuditLogService
 }
 
 func NewAdminScoreValidationController(svc *services.ScoreValidationService) *AdminScoreValidationController {
 	return &AdminScoreValidationController{svc: svc}
 }
 
+// SetAuditLogService はキャリブレーションの版の切り替えを記録する監査ログを設定する
+func (c *AdminScoreValidationController) SetAuditLogService(audit *services.AuditLogService) {
+	c.audit = audit
+}
+
 // Route /api/admin/score-validation/* のルーティング
 func (c *AdminScoreValidationController) Route(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/admin/score-validation")
@@ -33,6 +41,12 @@ func (c *AdminScoreValidationController) Route(w http.ResponseWriter, r *http.Re
 		c.RunCalibration(w, r)
 	case path == "calibration/history" && r.Method == http.MethodGet:
 		c.GetCalibrationHistory(w, r)
+	case path == "calibration/activate" && r.Method == http.MethodPost:
+		c.ActivateCalibration(w, r)
+	case path == "calibration/shadow" && r.Method == http.MethodGet:
+		c.GetCalibrationShadowReport(w, r)
+	case path == "calibration/shadow" && r.Method == http.MethodPut:
+		c.SetShadowCalibration(w, r)
 	case path == "variants" && r.Method == http.MethodGet:
 		c.ListVariants(w, r)
 	case path == "variants" && r.Method == http.MethodPost:
@@ -67,14 +81,14 @@ func (c *AdminScoreValidationController) GetPhaseMetrics(w http.ResponseWriter,
 }
 
 // GetCalibration GET /api/admin/score-validation/calibration
-// 現在有効なキャリブレーション重みを返す
+// 現在有効なキャリブレーション重みとシャドーモードの版を返す
 func (c *AdminScoreValidationController) GetCalibration(w http.ResponseWriter, r *http.Request) {
-	weights, err := c.svc.GetCurrentCalibration()
+	status, err := c.svc.GetCalibrationStatus()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, map[string]interface{}{"weights": weights})
+	writeJSON(w, status)
 }
 
 // RunCalibration POST /api/admin/score-validation/calibration/run
@@ -105,6 +119,73 @@ func (c *AdminScoreValidationController) GetCalibrationHistory(w http.ResponseWr
 	writeJSON(w, map[string]interface{}{"history": history})
 }
 
+// calibrationVersionRequest 版の切り替えのリクエスト（0 なら重みなし・シャドー計算なし）
+type calibrationVersionRequest struct {
+	Version *int `json:"version"`
+}
+
+func decodeCalibrationVersion(w http.ResponseWriter, r *http.Request) (int, bool) {
+	var req calibrationVersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Version == nil || *req.Version < 0 {
+		http.Error(w, "version is required", http.StatusBadRequest)
+		return 0, false
+	}
+	return *req.Version, true
+}
+
+// ActivateCalibration POST /api/admin/score-validation/calibration/activate
+// 指定した版の重みをマッチング・分析スコアに使う（version: 0 なら重みなしに戻す）
+func (c *AdminScoreValidationController) ActivateCalibration(w http.ResponseWriter, r *http.Request) {
+	version, ok := decodeCalibrationVersion(w, r)
+	if !ok {
+		return
+	}
+	actor := actorEmail(r)
+	err := c.svc.ActivateCalibration(version, actor)
+	if errors.Is(err, services.ErrCalibrationVersionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.audit.Record(actor, "score_calibration.activate", "score_calibration", uint(version), nil)
+	c.GetCalibration(w, r)
+}
+
+// SetShadowCalibration PUT /api/admin/score-validation/calibration/shadow
+// 指定した版でのマッチ度を有効な版と並べて記録させる（version: 0 ならシャドー計算をやめる）
+func (c *AdminScoreValidationController) SetShadowCalibration(w http.ResponseWriter, r *http.Request) {
+	version, ok := decodeCalibrationVersion(w, r)
+	if !ok {
+		return
+	}
+	actor := actorEmail(r)
+	err := c.svc.SetShadowCalibration(version, actor)
+	if errors.Is(err, services.ErrCalibrationVersionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.audit.Record(actor, "score_calibration.shadow", "score_calibration", uint(version), nil)
+	c.GetCalibration(w, r)
+}
+
+// GetCalibrationShadowReport GET /api/admin/score-validation/calibration/shadow
+// シャドー計算したマッチ度と有効な版のマッチ度を版の組み合わせごとに比べる
+func (c *AdminScoreValidationController) GetCalibrationShadowReport(w http.ResponseWriter, r *http.Request) {
+	rows, err := c.svc.GetCalibrationShadowReport()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"results": rows})
+}
+
 // ListVariants GET /api/admin/score-validation/variants?experiment=xxx
 func (c *AdminScoreValidationController) ListVariants(w http.ResponseWriter, r *http.Request) {
 	experiments, err := c.svc.ListExperiments()
@@ -118,10 +199,12 @@ func (c *AdminScoreValidationController) ListVariants(w http.ResponseWriter, r *
 // CreateVariant POST /api/admin/score-validation/variants
 func (c *AdminScoreValidationController) CreateVariant(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		ExperimentName string  `json:"experiment_name"`
-		VariantName    string  `json:"variant_name"`
-		Description    string  `json:"description"`
-		TrafficRatio   float64 `json:"traffic_ratio"`
+		ExperimentName string                  `json:"experiment_name"`
+		VariantName    string                  `json:"variant_name"`
+		Description    string                  `json:"description"`
+		TrafficRatio   float64                 `json:"traffic_ratio"`
+		PromptVersions map[string]string       `json:"prompt_versions"` // 任意: プロンプト名→版名の固定
+		Config         *services.VariantConfig `json:"config"`          // 任意: 質問プール・フェーズの質問数・スコアリングの変更
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -135,7 +218,11 @@ func (c *AdminScoreValidationController) CreateVariant(w http.ResponseWriter, r
 		req.TrafficRatio = 0.5
 	}
 
-	variant, err := c.svc.CreateVariant(req.ExperimentName, req.VariantName, req.Description, req.TrafficRatio)
+	variant, err := c.svc.CreateVariant(req.ExperimentName, req.VariantName, req.Description, req.TrafficRatio, req.PromptVersions, req.Config)
+	if errors.Is(err, prompts.ErrUnknownPrompt) || errors.Is(err, prompts.ErrVersionNotFound) || errors.Is(err, services.ErrInvalidVariantConfig) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -1,21 +1,25 @@
 package controllers
 
 import (
+	"Backend/domain/entity"
 	"Backend/domain/repository"
+	"Backend/internal/middleware"
 	"Backend/internal/services"
 	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type AdminUserController struct {
 	repo  repository.UserRepository
+	auth  *services.AuthService
 	audit *services.AuditLogService
 }
 
-func NewAdminUserController(repo repository.UserRepository, audit *services.AuditLogService) *AdminUserController {
-	return &AdminUserController{repo: repo, audit: audit}
+func NewAdminUserController(repo repository.UserRepository, auth *services.AuthService, audit *services.AuditLogService) *AdminUserController {
+	return &AdminUserController{repo: repo, auth: auth, audit: audit}
 }
 
 type adminUserResponse struct {
@@ -24,14 +28,21 @@ type adminUserResponse struct {
 	Name        string `json:"name"`
 	IsGuest     bool   `json:"is_guest"`
 	IsAdmin     bool   `json:"is_admin"`
+	Role        string `json:"role"`
 	TargetLevel string `json:"target_level"`
 	SchoolName  string `json:"school_name"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
+	// ログイン試行制限の状態
+	FailedLoginCount int    `json:"failed_login_count"`
+	LockedUntil      string `json:"locked_until,omitempty"`
+	IsLocked         bool   `json:"is_locked"`
+	MFAEnabled       bool   `json:"mfa_enabled"`
 }
 
 type adminUserUpdateRequest struct {
 	IsAdmin     *bool   `json:"is_admin"`
+	Role        *string `json:"role"`
 	Name        *string `json:"name"`
 	TargetLevel *string `json:"target_level"`
 	SchoolName  *string `json:"school_name"`
@@ -59,18 +70,8 @@ func (c *AdminUserController) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	resp := make([]adminUserResponse, 0, len(users))
-	for _, u := range users {
-		resp = append(resp, adminUserResponse{
-			ID:          u.ID,
-			Email:       u.Email,
-			Name:        u.Name,
-			IsGuest:     u.IsGuest,
-			IsAdmin:     u.IsAdmin,
-			TargetLevel: u.TargetLevel,
-			SchoolName:  u.SchoolName,
-			CreatedAt:   u.CreatedAt.Format(timeLayout()),
-			UpdatedAt:   u.UpdatedAt.Format(timeLayout()),
-		})
+	for i := range users {
+		resp = append(resp, toAdminUserResponse(&users[i]))
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -103,8 +104,25 @@ func (c *AdminUserController) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "user not found", http.StatusNotFound)
 		return
 	}
-	if payload.IsAdmin != nil {
-		user.IsAdmin = *payload.IsAdmin
+	if payload.Role != nil {
+		role := strings.TrimSpace(*payload.Role)
+		if role != entity.RoleStudent && role != entity.RoleTeacher && role != entity.RoleAdmin {
+			http.Error(w, "role must be student, teacher or admin", http.StatusBadRequest)
+			return
+		}
+		setUserRole(user, role)
+	} else if payload.IsAdmin != nil {
+		// is_admin は後方互換。false にした場合は学生ロールへ戻す
+		if *payload.IsAdmin {
+			setUserRole(user, entity.RoleAdmin)
+		} else if user.HasAdminRole() {
+			setUserRole(user, entity.RoleStudent)
+		}
+	}
+	// 自分自身の管理者権限は外せない（管理者不在を防ぐ）
+	if user.ID == middleware.CurrentUserID(r) && !user.HasAdminRole() {
+		http.Error(w, "cannot remove your own admin role", http.StatusBadRequest)
+		return
 	}
 	if payload.Name != nil {
 		user.Name = strings.TrimSpace(*payload.Name)
@@ -124,26 +142,143 @@ func (c *AdminUserController) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to update user", http.StatusInternalServerError)
 		return
 	}
-	actor := r.Header.Get("X-Admin-Email")
+	actor := actorEmail(r)
 	c.audit.Record(actor, "user.update", "user", user.ID, map[string]interface{}{
 		"is_admin":     user.IsAdmin,
+		"role":         user.Role,
 		"target_level": user.TargetLevel,
 		"school_name":  user.SchoolName,
 	})
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(adminUserResponse{
-		ID:          user.ID,
-		Email:       user.Email,
-		Name:        user.Name,
-		IsGuest:     user.IsGuest,
-		IsAdmin:     user.IsAdmin,
-		TargetLevel: user.TargetLevel,
-		SchoolName:  user.SchoolName,
-		CreatedAt:   user.CreatedAt.Format(timeLayout()),
-		UpdatedAt:   user.UpdatedAt.Format(timeLayout()),
+	json.NewEncoder(w).Encode(toAdminUserResponse(user))
+}
+
+// Route /api/admin/users/{id}[/unlock|/login-attempts|/reset-mfa]
+func (c *AdminUserController) Route(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/users/"), "/")
+	switch {
+	case strings.HasSuffix(path, "/unlock"):
+		c.Unlock(w, r)
+	case strings.HasSuffix(path, "/login-attempts"):
+		c.LoginAttempts(w, r)
+	case strings.HasSuffix(path, "/reset-mfa"):
+		c.ResetMFA(w, r)
+	default:
+		c.Update(w, r)
+	}
+}
+
+// Unlock POST /api/admin/users/{id}/unlock
+// ログイン失敗によるアカウントロックを解除する
+func (c *AdminUserController) Unlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := extractAdminUserID(r.URL.Path, "/unlock")
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	user, err := c.auth.UnlockAccount(id, actorEmail(r))
+	if err != nil {
+		if err.Error() == "user not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to unlock user", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAdminUserResponse(user))
+}
+
+// ResetMFA POST /api/admin/users/{id}/reset-mfa
+// 認証アプリとリカバリーコードを紛失した利用者の 2段階認証設定を消去する
+func (c *AdminUserController) ResetMFA(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := extractAdminUserID(r.URL.Path, "/reset-mfa")
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	if id == middleware.CurrentUserID(r) {
+		http.Error(w, "cannot reset your own two-factor authentication", http.StatusBadRequest)
+		return
+	}
+	user, err := c.auth.ResetTOTP(id, actorEmail(r))
+	if err != nil {
+		if err.Error() == "user not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to reset two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAdminUserResponse(user))
+}
+
+// LoginAttempts GET /api/admin/users/{id}/login-attempts
+func (c *AdminUserController) LoginAttempts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := extractAdminUserID(r.URL.Path, "/login-attempts")
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	attempts, err := c.auth.RecentLoginAttempts(id)
+	if err != nil {
+		http.Error(w, "failed to fetch login attempts", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"login_attempts": attempts,
 	})
 }
 
+func extractAdminUserID(path, suffix string) (uint, error) {
+	trimmed := strings.Trim(strings.TrimPrefix(path, "/api/admin/users/"), "/")
+	trimmed = strings.TrimSuffix(trimmed, suffix)
+	id, err := strconv.ParseUint(trimmed, 10, 32)
+	return uint(id), err
+}
+
+func toAdminUserResponse(u *entity.User) adminUserResponse {
+	resp := adminUserResponse{
+		ID:               u.ID,
+		Email:            u.Email,
+		Name:             u.Name,
+		IsGuest:          u.IsGuest,
+		IsAdmin:          u.HasAdminRole(),
+		Role:             u.EffectiveRole(),
+		TargetLevel:      u.TargetLevel,
+		SchoolName:       u.SchoolName,
+		CreatedAt:        u.CreatedAt.Format(timeLayout()),
+		UpdatedAt:        u.UpdatedAt.Format(timeLayout()),
+		FailedLoginCount: u.FailedLoginCount,
+		IsLocked:         u.IsLocked(time.Now()),
+		MFAEnabled:       u.HasTOTP(),
+	}
+	if u.LockedUntil != nil {
+		resp.LockedUntil = u.LockedUntil.Format(timeLayout())
+	}
+	return resp
+}
+
+// setUserRole ロールを設定し、is_admin フラグを同期する
+func setUserRole(user *entity.User, role string) {
+	user.Role = role
+	user.IsAdmin = role == entity.RoleAdmin
+}
+
 func timeLayout() string {
 	return "2006-01-02 15:04:05"
 }
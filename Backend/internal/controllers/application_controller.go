@@ -23,8 +23,12 @@ func (c *ApplicationController) Apply(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
 	var req struct {
-		UserID    uint `json:"user_id"`
 		CompanyID uint `json:"company_id"`
 		MatchID   uint `json:"match_id"`
 	}
@@ -32,12 +36,12 @@ func (c *ApplicationController) Apply(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	if req.UserID == 0 || req.CompanyID == 0 || req.MatchID == 0 {
-		http.Error(w, "user_id, company_id, match_id は必須です", http.StatusBadRequest)
+	if req.CompanyID == 0 || req.MatchID == 0 {
+		http.Error(w, "company_id, match_id は必須です", http.StatusBadRequest)
 		return
 	}
 
-	app, err := c.appService.Apply(req.UserID, req.CompanyID, req.MatchID)
+	app, err := c.appService.Apply(userID, req.CompanyID, req.MatchID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -70,8 +74,12 @@ func (c *ApplicationController) UpdateStatus(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
 	var req struct {
-		UserID uint   `json:"user_id"`
 		Status string `json:"status"`
 		Notes  string `json:"notes"`
 	}
@@ -79,12 +87,12 @@ func (c *ApplicationController) UpdateStatus(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	if req.UserID == 0 || req.Status == "" {
-		http.Error(w, "user_id と status は必須です", http.StatusBadRequest)
+	if req.Status == "" {
+		http.Error(w, "status は必須です", http.StatusBadRequest)
 		return
 	}
 
-	app, err := c.appService.UpdateStatus(uint(id), req.UserID, req.Status, req.Notes)
+	app, err := c.appService.UpdateStatus(uint(id), userID, req.Status, req.Notes)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -98,21 +106,19 @@ func (c *ApplicationController) UpdateStatus(w http.ResponseWriter, r *http.Requ
 	})
 }
 
-// List GET /api/applications?user_id=X - ユーザーの応募一覧取得
+// List GET /api/applications - ログインユーザーの応募一覧取得
 func (c *ApplicationController) List(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	userIDStr := r.URL.Query().Get("user_id")
-	userID, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil || userID == 0 {
-		http.Error(w, "user_id は必須です", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 
-	apps, err := c.appService.GetApplicationsByUser(uint(userID))
+	apps, err := c.appService.GetApplicationsByUser(userID)
 	if err != nil {
 		http.Error(w, "データ取得エラー", http.StatusInternalServerError)
 		return
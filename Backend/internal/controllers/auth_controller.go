@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"Backend/internal/middleware"
 	"Backend/internal/services"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 )
@@ -56,8 +59,13 @@ func (c *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req.IP = middleware.ClientIP(r)
+
 	resp, err := c.authService.Login(req)
 	if err != nil {
+		if writeLoginBlocked(w, err) {
+			return
+		}
 		msg := err.Error()
 		if msg == "invalid email or password" || msg == "guest users cannot login" {
 			http.Error(w, msg, http.StatusUnauthorized)
@@ -77,6 +85,213 @@ func (c *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// writeLoginBlocked ログイン試行制限のエラーであれば Retry-After 付きで 429（ロック中は 423）を書き込み true を返す
+func writeLoginBlocked(w http.ResponseWriter, err error) bool {
+	var blocked *services.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		return false
+	}
+	retryAfter := int(math.Ceil(blocked.RetryAfter.Seconds()))
+	status := http.StatusTooManyRequests
+	if blocked.Code == services.LoginBlockedAccountLocked {
+		status = http.StatusLocked
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": blocked.Code, "retry_after": retryAfter})
+	return true
+}
+
+// LoginMFA POST /api/auth/login/mfa
+// パスワード認証後に返した mfa_token と認証コード（またはリカバリーコード）でログインを完了する
+func (c *AuthController) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.MFAToken == "" {
+		http.Error(w, "mfa_token is required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := c.authService.CompleteMFALogin(body.MFAToken, body.Code, middleware.ClientIP(r))
+	if err != nil {
+		if writeLoginBlocked(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrInvalidToken) || errors.Is(err, services.ErrInvalidMFACode) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// MFAStatus GET /api/auth/mfa
+func (c *AuthController) MFAStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	status, err := c.authService.GetTOTPStatus(userID)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// MFASetup POST /api/auth/mfa/setup
+// 認証アプリに登録するシークレットと otpauth:// URI を発行する
+func (c *AuthController) MFASetup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	enrollment, err := c.authService.BeginTOTPEnrollment(userID)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// MFAActivate POST /api/auth/mfa/activate
+// 認証アプリのコードを確認して有効化し、リカバリーコードと 2段階認証済みのトークンを返す
+func (c *AuthController) MFAActivate(w http.ResponseWriter, r *http.Request) {
+	c.withMFACode(w, r, func(userID uint, code string) (interface{}, error) {
+		return c.authService.ActivateTOTP(userID, code, middleware.ClientIP(r))
+	})
+}
+
+// MFAVerify POST /api/auth/mfa/verify
+// ログイン済みセッション（OAuth ログインなど）で認証コードを入力し、2段階認証済みのトークンを受け取る
+func (c *AuthController) MFAVerify(w http.ResponseWriter, r *http.Request) {
+	c.withMFACode(w, r, func(userID uint, code string) (interface{}, error) {
+		return c.authService.VerifySessionMFA(userID, code, middleware.ClientIP(r))
+	})
+}
+
+// MFARecoveryCodes POST /api/auth/mfa/recovery-codes
+func (c *AuthController) MFARecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	c.withMFACode(w, r, func(userID uint, code string) (interface{}, error) {
+		codes, err := c.authService.RegenerateRecoveryCodes(userID, code, middleware.ClientIP(r))
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]string{"recovery_codes": codes}, nil
+	})
+}
+
+// MFADisable POST /api/auth/mfa/disable
+func (c *AuthController) MFADisable(w http.ResponseWriter, r *http.Request) {
+	c.withMFACode(w, r, func(userID uint, code string) (interface{}, error) {
+		if err := c.authService.DisableTOTP(userID, code, middleware.ClientIP(r)); err != nil {
+			return nil, err
+		}
+		return map[string]string{"message": "2段階認証を無効にしました"}, nil
+	})
+}
+
+// withMFACode 認証コードを受け取る POST エンドポイントの共通処理
+func (c *AuthController) withMFACode(w http.ResponseWriter, r *http.Request, fn func(userID uint, code string) (interface{}, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Code == "" {
+		http.Error(w, "code is required", http.StatusBadRequest)
+		return
+	}
+	resp, err := fn(userID, body.Code)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func writeMFAError(w http.ResponseWriter, err error) {
+	if writeLoginBlocked(w, err) {
+		return
+	}
+	switch {
+	case err.Error() == "user not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidMFACode),
+		err.Error() == "totp is already enabled",
+		err.Error() == "totp is not enabled",
+		err.Error() == "totp enrollment has not been started",
+		err.Error() == "guest users cannot enable totp":
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err.Error() == "totp is required for this role":
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Refresh POST /api/auth/refresh
+// リフレッシュトークンを検証し、新しいアクセス・リフレッシュトークンを発行する
+func (c *AuthController) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.RefreshToken == "" {
+		http.Error(w, "refresh_token is required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := c.authService.RefreshSession(body.RefreshToken)
+	if err != nil {
+		if writeLoginBlocked(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrInvalidToken) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
 // CreateGuest ゲストユーザー作成
 func (c *AuthController) CreateGuest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -95,26 +310,95 @@ func (c *AuthController) CreateGuest(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
-// GetUser ユーザー情報取得
-func (c *AuthController) GetUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+// UpgradeGuest POST /api/auth/guest/upgrade
+// ログイン中のゲストにメールアドレスとパスワードを設定して登録ユーザーにする（データはそのまま引き継ぐ）
+func (c *AuthController) UpgradeGuest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
 
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
+	var req services.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	resp, err := c.authService.UpgradeGuest(userID, req)
 	if err != nil {
-		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		switch err.Error() {
+		case "email already exists":
+			http.Error(w, err.Error(), http.StatusConflict)
+		case "user not found":
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
 		return
 	}
 
-	resp, err := c.authService.GetUser(uint(userID))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// MergeGuest POST /api/auth/guest/merge
+// ゲストとして利用していたデータをログイン中の登録ユーザーに統合する。
+// ゲスト時のトークン（guest_token）でゲストの所有者であることを確認する
+func (c *AuthController) MergeGuest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		GuestToken string `json:"guest_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.GuestToken == "" {
+		http.Error(w, "guest_token is required", http.StatusBadRequest)
+		return
+	}
+
+	guestID, err := c.authService.MergeGuest(userID, body.GuestToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidGuestToken), err.Error() == "cannot merge into a guest user":
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case err.Error() == "user not found":
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":       "ゲストのデータを統合しました",
+		"guest_user_id": guestID,
+	})
+}
+
+// GetUser ユーザー情報取得
+func (c *AuthController) GetUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := c.authService.GetUser(userID)
 	if err != nil {
 		if err.Error() == "user not found" {
 			http.Error(w, err.Error(), http.StatusNotFound)
@@ -186,11 +470,17 @@ func (c *AuthController) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
 	var req services.UpdateProfileRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	req.UserID = userID
 
 	resp, err := c.authService.UpdateProfile(req)
 	if err != nil {
@@ -198,6 +488,10 @@ func (c *AuthController) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, services.ErrSchoolNameLocked) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -253,52 +547,88 @@ func (c *AuthController) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "パスワードをリセットしました"})
 }
 
-// VerifyEmail メール認証トークンを検証してアカウントを有効化する
-func (c *AuthController) VerifyEmail(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+// SetPassword POST /api/auth/password
+// ログイン中のユーザーがパスワードを設定・変更する（OAuth のみのアカウントにパスワードログインを追加できる）
+func (c *AuthController) SetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	token := r.URL.Query().Get("token")
-	if token == "" {
-		var req struct {
-			Token string `json:"token"`
-		}
-		json.NewDecoder(r.Body).Decode(&req)
-		token = req.Token
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
 	}
-	if err := c.authService.VerifyEmail(token); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+
+	var body struct {
+		CurrentPassword string `json:"current_password"`
+		Password        string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.authService.SetPassword(userID, body.CurrentPassword, body.Password); err != nil {
+		switch err.Error() {
+		case "user not found":
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case "current password is incorrect":
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case "password must be at least 8 characters", "guest users cannot set password":
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "メールアドレスを確認しました。ログインしてください。"})
+	json.NewEncoder(w).Encode(map[string]string{"message": "パスワードを設定しました"})
 }
 
-// DeleteAccount アカウントと全データを削除（個人情報保護法第28条対応）
-// DELETE /api/auth/account?user_id=X
-func (c *AuthController) DeleteAccount(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
+// UnlockAccount POST /api/auth/unlock
+// ロック解除メールのトークンでアカウントのロックを解除する
+func (c *AuthController) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
+	var body struct {
+		Token string `json:"token"`
 	}
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if err := c.authService.DeleteAccount(uint(userID)); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := c.authService.UnlockAccountByToken(body.Token); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "アカウントを削除しました"})
+	json.NewEncoder(w).Encode(map[string]string{"message": "アカウントのロックを解除しました。ログインしてください。"})
+}
+
+// VerifyEmail メール認証トークンを検証してアカウントを有効化する
+func (c *AuthController) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		var req struct {
+			Token string `json:"token"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		token = req.Token
+	}
+	if err := c.authService.VerifyEmail(token); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "メールアドレスを確認しました。ログインしてください。"})
 }
@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/entity"
 	"Backend/domain/repository"
 	"Backend/internal/services"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -49,11 +51,17 @@ func (c *ChatController) Chat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
 	var req services.ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	req.UserID = userID
 
 	// バリデーション
 	if req.UserID == 0 || req.SessionID == "" || req.Message == "" {
@@ -63,13 +71,86 @@ func (c *ChatController) Chat(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := c.chatService.ProcessChat(r.Context(), req)
 	if err != nil {
+		if err.Error() == "forbidden" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if writeBudgetExceeded(w, err) {
+			return
+		}
 		// エラーログを詳細に出力
 		println("Error in ProcessChat:", err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// マッチング計算を非同期で実行（レスポンスは待たない）
+	c.calculateMatchingAsync(r, req)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ChatStream チャット処理のストリーミング版（Server-Sent Events）
+// 妥当性の判定・スコアの変化・フェーズ進捗・生成中の質問を data: {"type": ...} で順に送り、
+// 最後に complete（result は Chat と同じレスポンス）か error を送る
+func (c *ChatController) ChatStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.UserID = userID
+	if req.SessionID == "" || req.Message == "" {
+		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	sendEvent := func(v map[string]interface{}) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	resp, err := c.chatService.ProcessChatStream(r.Context(), req, sendEvent)
+	if err != nil {
+		message := err.Error()
+		if message == "forbidden" {
+			message = "Forbidden"
+		} else if errors.Is(err, ai.ErrBudgetExceeded) {
+			message = services.LLMBudgetExceededMessage
+		} else {
+			fmt.Printf("[ChatStream] ProcessChat failed: %v\n", err)
+		}
+		sendEvent(map[string]interface{}{"type": services.ChatEventError, "message": message})
+		return
+	}
+
+	c.calculateMatchingAsync(r, req)
+	sendEvent(map[string]interface{}{"type": services.ChatEventComplete, "result": resp})
+}
+
+// calculateMatchingAsync はマッチング計算を非同期で実行する（レスポンスは待たない）
+func (c *ChatController) calculateMatchingAsync(r *http.Request, req services.ChatRequest) {
 	go func() {
 		if err := c.matchingService.CalculateMatching(r.Context(), req.UserID, req.SessionID); err != nil {
 			fmt.Printf("[Chat] Background matching calculation failed: %v\n", err)
@@ -77,9 +158,6 @@ func (c *ChatController) Chat(w http.ResponseWriter, r *http.Request) {
 			fmt.Printf("[Chat] Background matching calculation completed for user %d\n", req.UserID)
 		}
 	}()
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
 }
 
 // GetHistory チャット履歴取得
@@ -89,14 +167,23 @@ func (c *ChatController) GetHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
 	sessionID := r.URL.Query().Get("session_id")
 	if sessionID == "" {
 		http.Error(w, "session_id is required", http.StatusBadRequest)
 		return
 	}
 
-	history, err := c.chatService.GetChatHistory(sessionID)
+	history, err := c.chatService.GetChatHistoryForUser(userID, sessionID)
 	if err != nil {
+		if err.Error() == "forbidden" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -112,21 +199,18 @@ func (c *ChatController) GetScores(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userIDStr := r.URL.Query().Get("user_id")
-	sessionID := r.URL.Query().Get("session_id")
-
-	if userIDStr == "" || sessionID == "" {
-		http.Error(w, "user_id and session_id are required", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		http.Error(w, "session_id is required", http.StatusBadRequest)
 		return
 	}
 
-	scores, err := c.chatService.GetUserScores(uint(userID), sessionID)
+	scores, err := c.chatService.GetUserScores(userID, sessionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -143,18 +227,16 @@ func (c *ChatController) GetRecommendations(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	userIDStr := r.URL.Query().Get("user_id")
-	sessionID := r.URL.Query().Get("session_id")
-	limitStr := r.URL.Query().Get("limit")
-
-	if userIDStr == "" || sessionID == "" {
-		http.Error(w, "user_id and session_id are required", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+	sessionID := r.URL.Query().Get("session_id")
+	limitStr := r.URL.Query().Get("limit")
+
+	if sessionID == "" {
+		http.Error(w, "session_id is required", http.StatusBadRequest)
 		return
 	}
 
@@ -168,17 +250,17 @@ func (c *ChatController) GetRecommendations(w http.ResponseWriter, r *http.Reque
 
 	// 既存のマッチング結果を取得（事前計算済みを想定）
 	fmt.Printf("[GetRecommendations] Fetching pre-calculated matches for user %d, session %s\n", userID, sessionID)
-	matches, err := c.matchingService.GetTopMatches(r.Context(), uint(userID), sessionID, limit)
+	matches, err := c.matchingService.GetTopMatches(r.Context(), userID, sessionID, limit)
 	fmt.Printf("[GetRecommendations] Retrieved %d matches in fast mode\n", len(matches))
 
 	if err != nil || len(matches) == 0 {
 		fmt.Printf("[GetRecommendations] No matching results found, returning empty result\n")
-		diagnostics, diagErr := c.matchingService.GetDiagnostics(uint(userID), sessionID)
+		diagnostics, diagErr := c.matchingService.GetDiagnostics(userID, sessionID)
 		if diagErr != nil {
 			fmt.Printf("[GetRecommendations] Diagnostics error: %v\n", diagErr)
 		}
 
-		userScores, scoreErr := c.chatService.GetUserScores(uint(userID), sessionID)
+		userScores, scoreErr := c.chatService.GetUserScores(userID, sessionID)
 		if scoreErr != nil {
 			fmt.Printf("[GetRecommendations] Failed to load user scores for provisional status: %v\n", scoreErr)
 			userScores = []entity.UserWeightScore{}
@@ -251,7 +333,7 @@ func (c *ChatController) GetRecommendations(w http.ResponseWriter, r *http.Reque
 		IsProvisional       bool                    `json:"is_provisional"`
 	}
 
-	userScores, err := c.chatService.GetUserScores(uint(userID), sessionID)
+	userScores, err := c.chatService.GetUserScores(userID, sessionID)
 	if err != nil {
 		fmt.Printf("[GetRecommendations] Failed to load user scores: %v\n", err)
 		userScores = []entity.UserWeightScore{}
@@ -320,6 +402,11 @@ func (c *ChatController) ToggleFavorite(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
 	var req struct {
 		MatchID uint `json:"match_id"`
 	}
@@ -328,7 +415,11 @@ func (c *ChatController) ToggleFavorite(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := c.matchingService.ToggleFavorite(req.MatchID); err != nil {
+	if err := c.matchingService.ToggleFavorite(userID, req.MatchID); err != nil {
+		if err.Error() == "forbidden" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -344,16 +435,14 @@ func (c *ChatController) GetAnalysisSummary(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	userIDStr := r.URL.Query().Get("user_id")
-	sessionID := r.URL.Query().Get("session_id")
-	if userIDStr == "" || sessionID == "" {
-		http.Error(w, "user_id and session_id are required", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		http.Error(w, "session_id is required", http.StatusBadRequest)
 		return
 	}
 
@@ -362,7 +451,7 @@ func (c *ChatController) GetAnalysisSummary(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	summary, err := c.analysisService.BuildAnalysisSummary(r.Context(), uint(userID), sessionID)
+	summary, err := c.analysisService.BuildAnalysisSummary(r.Context(), userID, sessionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -472,21 +561,25 @@ func (c *ChatController) SendReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
 	var req struct {
-		UserID    uint   `json:"user_id"`
 		SessionID string `json:"session_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	if req.UserID == 0 || req.SessionID == "" {
-		http.Error(w, "user_id and session_id are required", http.StatusBadRequest)
+	if req.SessionID == "" {
+		http.Error(w, "session_id is required", http.StatusBadRequest)
 		return
 	}
 
 	// ユーザー情報取得
-	user, err := c.userRepo.GetUserByID(req.UserID)
+	user, err := c.userRepo.GetUserByID(userID)
 	if err != nil || user == nil {
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
@@ -499,15 +592,15 @@ func (c *ChatController) SendReport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 分析サマリー取得
-	summary, err := c.analysisService.BuildAnalysisSummary(r.Context(), req.UserID, req.SessionID)
+	summary, err := c.analysisService.BuildAnalysisSummary(r.Context(), userID, req.SessionID)
 	if err != nil {
 		http.Error(w, "Failed to build analysis summary: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// おすすめ企業取得（最大5件）
-	matches, _ := c.matchingService.GetTopMatches(r.Context(), req.UserID, req.SessionID, 5)
-	userScores, _ := c.chatService.GetUserScores(req.UserID, req.SessionID)
+	matches, _ := c.matchingService.GetTopMatches(r.Context(), userID, req.SessionID, 5)
+	userScores, _ := c.chatService.GetUserScores(userID, req.SessionID)
 
 	var companies []services.EmailReportCompany
 	for i, match := range matches {
@@ -542,19 +635,12 @@ func (c *ChatController) GetSessions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 
-	sessions, err := c.chatService.GetUserChatSessions(uint(userID))
+	sessions, err := c.chatService.GetUserChatSessions(userID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
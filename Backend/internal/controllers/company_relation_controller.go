@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/repository"
-	"Backend/internal/openai"
+	"Backend/internal/services"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -14,11 +16,17 @@ import (
 
 type CompanyRelationController struct {
 	repo        repository.CompanyRelationQueryRepository
-	openaiClient *openai.Client
+	llm         ai.LLMClient
+	semantic    *services.SemanticSearchService
 }
 
-func NewCompanyRelationController(repo repository.CompanyRelationQueryRepository, openaiClient *openai.Client) *CompanyRelationController {
-	return &CompanyRelationController{repo: repo, openaiClient: openaiClient}
+func NewCompanyRelationController(repo repository.CompanyRelationQueryRepository, llm ai.LLMClient) *CompanyRelationController {
+	return &CompanyRelationController{repo: repo, llm: llm}
+}
+
+// SetSemanticSearchService 企業の意味検索を注入する（未設定なら /api/companies/semantic-search は 503）
+func (ctrl *CompanyRelationController) SetSemanticSearchService(semantic *services.SemanticSearchService) {
+	ctrl.semantic = semantic
 }
 
 // GetCompanyRelations 企業IDに関連する企業関係を取得
@@ -234,6 +242,63 @@ func (ctrl *CompanyRelationController) WebSearchCompanies(w http.ResponseWriter,
 	json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
 }
 
+// SemanticSearchCompanies 学生が書いた希望の文章（q）に近い企業を埋め込みのインデックスから探す
+func (ctrl *CompanyRelationController) SemanticSearchCompanies(w http.ResponseWriter, r *http.Request) {
+	var query string
+	limit := 10
+	switch r.Method {
+	case http.MethodGet:
+		query = r.URL.Query().Get("q")
+		if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+			limit = l
+		}
+	case http.MethodPost:
+		var req struct {
+			Query string `json:"query"`
+			Limit int    `json:"limit"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		query = req.Query
+		if req.Limit > 0 {
+			limit = req.Limit
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query = trimSpace(query)
+	if query == "" {
+		http.Error(w, "q parameter is required", http.StatusBadRequest)
+		return
+	}
+	if limit > 50 {
+		limit = 50 // 最大50件
+	}
+	if ctrl.semantic == nil {
+		http.Error(w, "Semantic search is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	results, err := ctrl.semantic.SearchCompanies(r.Context(), query, limit)
+	if err != nil {
+		if writeBudgetExceeded(w, err) {
+			return
+		}
+		if errors.Is(err, services.ErrSemanticSearchUnavailable) {
+			http.Error(w, "Semantic search is not available", http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, "Failed to search companies", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+}
+
 // searchCompaniesWithOpenAI はOpenAI Web Search APIを使って企業候補を取得する
 func (ctrl *CompanyRelationController) searchCompaniesWithOpenAI(ctx context.Context, query string) []map[string]string {
 	prompt := fmt.Sprintf(
@@ -245,7 +310,7 @@ func (ctrl *CompanyRelationController) searchCompaniesWithOpenAI(ctx context.Con
 	ctxTimeout, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
-	text, err := ctrl.openaiClient.WebSearchQuery(ctxTimeout, prompt)
+	text, err := ctrl.llm.WebSearch(ctxTimeout, prompt)
 	if err != nil {
 		return []map[string]string{}
 	}
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"Backend/domain/ai"
+	"Backend/internal/services"
 	"bytes"
 	"encoding/json"
 	"io"
@@ -11,12 +13,25 @@ import (
 	"time"
 )
 
-type ESReviewController struct{}
+type ESReviewController struct {
+	guard    *services.InputGuard
+	redactor *services.PIIRedactor
+}
 
 func NewESReviewController() *ESReviewController {
 	return &ESReviewController{}
 }
 
+// SetInputGuard 入力ガードを注入する（未設定なら判定しない）
+func (c *ESReviewController) SetInputGuard(guard *services.InputGuard) {
+	c.guard = guard
+}
+
+// SetPIIRedactor RAG サービスに送る ES の個人情報の置き換えを注入する（未設定なら置き換えない）
+func (c *ESReviewController) SetPIIRedactor(redactor *services.PIIRedactor) {
+	c.redactor = redactor
+}
+
 type esReviewRequest struct {
 	ESText       string `json:"es_text"`
 	QuestionType string `json:"question_type"`
@@ -43,6 +58,15 @@ func (c *ESReviewController) Review(w http.ResponseWriter, r *http.Request) {
 		req.QuestionType = "その他"
 	}
 
+	// ES は RAG サービスで LLM のプロンプトに入るため、送る前に判定する
+	guard := c.guard.Check(r.Context(), services.FeatureESReview, req.ESText)
+	if writeInputRejected(w, guard.Err()) {
+		return
+	}
+	req.ESText = guard.Text
+	texts, redaction := c.redactor.Redact(ai.WithFeature(r.Context(), services.FeatureESReview), req.ESText)
+	req.ESText = texts[0]
+
 	ragURL := strings.TrimSpace(os.Getenv("RAG_REVIEW_URL"))
 	if ragURL == "" {
 		http.Error(w, "RAG_REVIEW_URL is not configured", http.StatusServiceUnavailable)
@@ -81,5 +105,5 @@ func (c *ESReviewController) Review(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(resp.StatusCode)
-	w.Write(respBody)
+	w.Write([]byte(redaction.RestoreJSON(string(respBody))))
 }
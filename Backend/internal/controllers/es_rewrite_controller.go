@@ -1,19 +1,33 @@
 package controllers
 
 import (
-	"Backend/internal/openai"
-	"context"
+	"Backend/domain/ai"
+	"Backend/internal/services"
+	"Backend/internal/services/prompts"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 )
 
 type ESRewriteController struct {
-	openaiClient *openai.Client
+	llm     ai.LLMClient
+	prompts *prompts.Registry
+	guard   *services.InputGuard
 }
 
-func NewESRewriteController(openaiClient *openai.Client) *ESRewriteController {
-	return &ESRewriteController{openaiClient: openaiClient}
+func NewESRewriteController(llm ai.LLMClient) *ESRewriteController {
+	return &ESRewriteController{llm: llm}
+}
+
+// SetPromptRegistry プロンプトレジストリを注入する（未設定ならファイルの既定版を使う）
+func (c *ESRewriteController) SetPromptRegistry(registry *prompts.Registry) {
+	c.prompts = registry
+}
+
+// SetInputGuard 入力ガードを注入する（未設定なら判定しない）
+func (c *ESRewriteController) SetInputGuard(guard *services.InputGuard) {
+	c.guard = guard
 }
 
 type esRewriteRequest struct {
@@ -54,66 +68,36 @@ func (c *ESRewriteController) Rewrite(w http.ResponseWriter, r *http.Request) {
 		req.QuestionType = "その他"
 	}
 
-	systemPrompt := `あなたはエンジニア就職活動の専門アドバイザーです。
-学生が書いたES文章を、採用担当者に刺さるエンジニア向けの表現にリライトしてください。
-JSONのみで返してください。`
-
-	techInfo := ""
-	if req.TechStack != "" {
-		techInfo = "\n使用技術スタック（参考）: " + req.TechStack
+	ctx := ai.WithFeature(r.Context(), ai.FeatureESRewrite)
+	guard := c.guard.Check(ctx, ai.FeatureESRewrite, req.OriginalText)
+	if writeInputRejected(w, guard.Err()) {
+		return
 	}
+	req.OriginalText = guard.Text
 
-	userPrompt := `以下のES文章を、STAR法（Situation/Task/Action/Result）に沿ったエンジニア採用向けの表現にリライトしてください。
-
-【質問種別】` + req.QuestionType + `
-【元のES文章】
-` + req.OriginalText + techInfo + `
-
-## リライトのルール
-- 「頑張りました」「工夫しました」等の抽象表現を、具体的な技術・数値・成果に置き換える
-- STAR法: Situation（状況）/ Task（課題）/ Action（技術的施策）/ Result（成果・数値）の構造で記述する
-- エンジニア採用に刺さる技術的な動詞・名詞を使用する（実装した、設計した、最適化した、削減した等）
-- 元の内容を大きく変えず、言語化を強化する方向でリライトする
-- 文字数は元の文章の120〜150%程度を目安にする
-
-## 出力フォーマット（このキーと型を厳守）
-{
-  "rewritten_text": "リライト後の完成文章",
-  "star": {
-    "situation": "状況（背景・前提）の部分の説明",
-    "task": "課題・目標の部分の説明",
-    "action": "技術的な施策・行動の部分の説明",
-    "result": "成果・結果の部分の説明"
-  }
-}`
-
-	raw, err := c.openaiClient.ChatCompletionJSON(context.Background(), systemPrompt, userPrompt, 0.7, 1500)
+	prompt, err := c.prompts.Render(ctx, prompts.ESRewrite, prompts.Vars{
+		"QuestionType": req.QuestionType,
+		"OriginalText": req.OriginalText,
+		"TechStack":    req.TechStack,
+	})
 	if err != nil {
-		http.Error(w, "AI generation failed: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "failed to build prompt", http.StatusInternalServerError)
 		return
 	}
 
-	// Extract JSON object in case of markdown fences
-	cleaned := extractESJSON(raw)
-
-	var resp esRewriteResponse
-	if err := json.Unmarshal([]byte(cleaned), &resp); err != nil {
+	resp, err := ai.GenerateStructured[esRewriteResponse](ctx, c.llm, prompt.System, prompt.User, ai.WithTemperature(0.7), ai.WithMaxTokens(1500))
+	if errors.Is(err, ai.ErrInvalidStructuredOutput) {
 		http.Error(w, "Failed to parse AI response", http.StatusInternalServerError)
 		return
 	}
+	if writeBudgetExceeded(w, err) {
+		return
+	}
+	if err != nil {
+		http.Error(w, "AI generation failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
-
-// extractESJSON strips markdown code fences and extracts the outermost JSON object.
-func extractESJSON(raw string) string {
-	s := strings.TrimSpace(raw)
-	if start := strings.Index(s, "{"); start > 0 {
-		s = s[start:]
-	}
-	if end := strings.LastIndex(s, "}"); end >= 0 && end < len(s)-1 {
-		s = s[:end+1]
-	}
-	return s
-}
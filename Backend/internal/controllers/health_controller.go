@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"Backend/domain/ai"
+	"encoding/json"
+	"net/http"
+)
+
+// LLMBreakerStatusProvider は LLM サーキットブレーカーの状態を返す（internal/ai.CircuitBreakerAdapter が実装）
+type LLMBreakerStatusProvider interface {
+	BreakerStatuses() []ai.BreakerStatus
+}
+
+type HealthController struct {
+	llm LLMBreakerStatusProvider
+}
+
+// NewHealthController はヘルスチェックのコントローラーを返す。llm は nil 可
+func NewHealthController(llm LLMBreakerStatusProvider) *HealthController {
+	return &HealthController{llm: llm}
+}
+
+// Health handles GET /health and /healthz
+// LLM のブレーカーが open でもフォールバックで応答できるため 200 を返し、status を degraded にする
+func (c *HealthController) Health(w http.ResponseWriter, r *http.Request) {
+	resp := map[string]interface{}{"status": "ok"}
+	if c.llm != nil {
+		statuses := c.llm.BreakerStatuses()
+		if llmDegraded(statuses) {
+			resp["status"] = "degraded"
+		}
+		resp["llm"] = statuses
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func llmDegraded(statuses []ai.BreakerStatus) bool {
+	for _, s := range statuses {
+		if s.State != ai.BreakerClosed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"Backend/internal/services"
+	"errors"
+	"net/http"
+)
+
+// writeInputRejected 入力ガードが入力を受け付けなかったエラーなら 422 を書き込み true を返す
+func writeInputRejected(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, services.ErrInputRejected) {
+		return false
+	}
+	http.Error(w, services.InputRejectedMessage, http.StatusUnprocessableEntity)
+	return true
+}
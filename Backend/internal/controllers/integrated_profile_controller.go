@@ -5,7 +5,6 @@ import (
 	"Backend/internal/services"
 	"encoding/json"
 	"net/http"
-	"strconv"
 )
 
 // IntegratedProfileController ユーザー統合プロファイルAPI
@@ -27,24 +26,17 @@ func NewIntegratedProfileController(
 	}
 }
 
-// GetProfile GET /api/user/profile?user_id=xxx&session_id=xxx
+// GetProfile GET /api/user/profile?session_id=xxx
 func (c *IntegratedProfileController) GetProfile(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
-	userIDParsed, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
-		return
-	}
-	userID := uint(userIDParsed)
 
 	sessionID := r.URL.Query().Get("session_id")
 	if sessionID == "" {
@@ -31,19 +31,13 @@ func NewInterviewController(interviewService *services.InterviewService, videoRe
 }
 
 type interviewCreateRequest struct {
-	UserID            uint   `json:"user_id"`
 	Language          string `json:"language"`
 	InterviewerGender string `json:"interviewer_gender"`
 }
 
-type interviewActionRequest struct {
-	UserID uint `json:"user_id"`
-}
-
 type interviewUtteranceRequest struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
-	Text   string `json:"text"`
+	Role string `json:"role"`
+	Text string `json:"text"`
 }
 
 func (c *InterviewController) ListOrCreate(w http.ResponseWriter, r *http.Request) {
@@ -98,21 +92,15 @@ func (c *InterviewController) Route(w http.ResponseWriter, r *http.Request) {
 	c.Get(w, r)
 }
 
-// GetTrend は GET /api/interviews/trend?user_id=X&limit=N を処理し、
+// GetTrend は GET /api/interviews/trend?limit=N を処理し、
 // 完了済みセッションのスコア時系列を返す。
 func (c *InterviewController) GetTrend(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 	limit := 20
@@ -121,7 +109,7 @@ func (c *InterviewController) GetTrend(w http.ResponseWriter, r *http.Request) {
 			limit = parsed
 		}
 	}
-	points, err := c.interviewService.GetTrend(uint(userID), limit)
+	points, err := c.interviewService.GetTrend(userID, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -142,17 +130,11 @@ func (c *InterviewController) GetReport(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Invalid session ID", http.StatusBadRequest)
 		return
 	}
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
-	report, err := c.interviewService.GetReport(uint(userID), sessionID)
+	report, err := c.interviewService.GetReport(userID, sessionID)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if err.Error() == "forbidden" {
@@ -179,18 +161,15 @@ func (c *InterviewController) GetPhraseSuggestions(w http.ResponseWriter, r *htt
 		http.Error(w, "Invalid session ID", http.StatusBadRequest)
 		return
 	}
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
-	suggestions, err := c.interviewService.GetPhraseSuggestions(r.Context(), uint(userID), sessionID)
+	suggestions, err := c.interviewService.GetPhraseSuggestions(r.Context(), userID, sessionID)
 	if err != nil {
+		if writeBudgetExceeded(w, err) {
+			return
+		}
 		status := http.StatusInternalServerError
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
@@ -214,14 +193,11 @@ func (c *InterviewController) SendReport(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Invalid session ID", http.StatusBadRequest)
 		return
 	}
-	var req struct {
-		UserID uint `json:"user_id"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == 0 {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
-	if err := c.interviewService.SendReportEmail(req.UserID, sessionID); err != nil {
+	if err := c.interviewService.SendReportEmail(userID, sessionID); err != nil {
 		status := http.StatusInternalServerError
 		if err.Error() == "user not found" || err.Error() == "report not found" {
 			status = http.StatusNotFound
@@ -250,6 +226,11 @@ func (c *InterviewController) UploadVideo(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
 	if c.videoRepo == nil || c.s3Service == nil {
 		http.Error(w, "video upload service not configured", http.StatusServiceUnavailable)
 		return
@@ -262,13 +243,6 @@ func (c *InterviewController) UploadVideo(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	userIDStr := r.FormValue("user_id")
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil || userID == 0 {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-
 	file, header, err := r.FormFile("video")
 	if err != nil {
 		http.Error(w, "動画ファイルが見つかりません", http.StatusBadRequest)
@@ -293,7 +267,7 @@ func (c *InterviewController) UploadVideo(w http.ResponseWriter, r *http.Request
 
 	videoRecord := &models.InterviewVideo{
 		SessionID:     sessionID,
-		UserID:        uint(userID),
+		UserID:        userID,
 		FileName:      fileName,
 		FileSizeBytes: header.Size,
 		MimeType:      mimeType,
@@ -336,17 +310,16 @@ func (c *InterviewController) Turn(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid session ID", http.StatusBadRequest)
 		return
 	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
 
 	// multipart から音声と履歴を取得
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
 		http.Error(w, "Failed to parse form", http.StatusBadRequest)
 		return
 	}
-	userIDStr := r.FormValue("user_id")
-	var userID uint
-	if id, err := strconv.ParseUint(userIDStr, 10, 32); err == nil {
-		userID = uint(id)
-	}
 	historyStr := r.FormValue("history")
 	var history []map[string]string
 	if historyStr != "" {
@@ -372,6 +345,9 @@ func (c *InterviewController) Turn(w http.ResponseWriter, r *http.Request) {
 
 	result, err := c.interviewService.Turn(r.Context(), userID, sessionID, audioData, history, companyName, companyReading, position, companyInfo, companyType)
 	if err != nil {
+		if writeBudgetExceeded(w, err) || writeInputRejected(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -402,8 +378,12 @@ func (c *InterviewController) StartTurn(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
 	var req struct {
-		UserID         uint   `json:"user_id"`
 		CompanyName    string `json:"company_name"`
 		CompanyReading string `json:"company_reading"`
 		Position       string `json:"position"`
@@ -412,8 +392,11 @@ func (c *InterviewController) StartTurn(w http.ResponseWriter, r *http.Request)
 	}
 	json.NewDecoder(r.Body).Decode(&req)
 
-	result, err := c.interviewService.StartTurn(r.Context(), req.UserID, sessionID, req.CompanyName, req.CompanyReading, req.Position, req.CompanyInfo, req.CompanyType)
+	result, err := c.interviewService.StartTurn(r.Context(), userID, sessionID, req.CompanyName, req.CompanyReading, req.Position, req.CompanyInfo, req.CompanyType)
 	if err != nil {
+		if writeBudgetExceeded(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -434,16 +417,16 @@ func (c *InterviewController) Create(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
 	var req interviewCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	if req.UserID == 0 {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-	resp, err := c.interviewService.CreateSession(req.UserID, req.Language, req.InterviewerGender)
+	resp, err := c.interviewService.CreateSession(userID, req.Language, req.InterviewerGender)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -462,16 +445,11 @@ func (c *InterviewController) Start(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid interview id", http.StatusBadRequest)
 		return
 	}
-	var req interviewActionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
-	if req.UserID == 0 {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-	resp, err := c.interviewService.StartSession(req.UserID, sessionID)
+	resp, err := c.interviewService.StartSession(userID, sessionID)
 	if err != nil {
 		status := http.StatusBadRequest
 		if err.Error() == "forbidden" {
@@ -494,16 +472,11 @@ func (c *InterviewController) Finish(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid interview id", http.StatusBadRequest)
 		return
 	}
-	var req interviewActionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
-	if req.UserID == 0 {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-	resp, err := c.interviewService.FinishSession(req.UserID, sessionID)
+	resp, err := c.interviewService.FinishSession(userID, sessionID)
 	if err != nil {
 		status := http.StatusBadRequest
 		if err.Error() == "forbidden" {
@@ -521,14 +494,8 @@ func (c *InterviewController) List(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 	page := parseIntQuery(r, "page", 1)
@@ -538,7 +505,7 @@ func (c *InterviewController) List(w http.ResponseWriter, r *http.Request) {
 	}
 	offset := (page - 1) * limit
 	all := r.URL.Query().Get("all") == "1" || strings.ToLower(r.URL.Query().Get("all")) == "true"
-	sessions, total, err := c.interviewService.ListSessions(uint(userID), all, limit, offset)
+	sessions, total, err := c.interviewService.ListSessions(userID, all, limit, offset)
 	if err != nil {
 		status := http.StatusBadRequest
 		if err.Error() == "forbidden" {
@@ -566,21 +533,12 @@ func (c *InterviewController) Get(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid interview id", http.StatusBadRequest)
 		return
 	}
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
+	user, ok := requireUser(w, r)
+	if !ok {
 		return
 	}
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
-		return
-	}
-	role := r.URL.Query().Get("role")
-	if role == "" {
-		role = "student"
-	}
-	resp, err := c.interviewService.GetSessionDetailWithRole(uint(userID), sessionID, role)
+	// 教員用レポートの開示可否はクエリではなく検証済みロールで判定する
+	resp, err := c.interviewService.GetSessionDetailWithRole(user.ID, sessionID, user.EffectiveRole())
 	if err != nil {
 		status := http.StatusBadRequest
 		if err.Error() == "forbidden" {
@@ -603,16 +561,16 @@ func (c *InterviewController) AddUtterance(w http.ResponseWriter, r *http.Reques
 		http.Error(w, "invalid interview id", http.StatusBadRequest)
 		return
 	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
 	var req interviewUtteranceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	if req.UserID == 0 {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-	if err := c.interviewService.SaveUtterance(req.UserID, sessionID, req.Role, req.Text); err != nil {
+	if err := c.interviewService.SaveUtterance(userID, sessionID, req.Role, req.Text); err != nil {
 		status := http.StatusBadRequest
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"Backend/domain/ai"
+	"Backend/internal/services"
+	"errors"
+	"net/http"
+)
+
+// writeBudgetExceeded 利用者の LLM 予算超過によるエラーなら 429 を書き込み true を返す
+func writeBudgetExceeded(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, ai.ErrBudgetExceeded) {
+		return false
+	}
+	http.Error(w, services.LLMBudgetExceededMessage, http.StatusTooManyRequests)
+	return true
+}
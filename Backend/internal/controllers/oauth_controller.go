@@ -3,9 +3,14 @@ package controllers
 import (
 	"Backend/internal/services"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 )
 
 type OAuthController struct {
@@ -23,8 +28,8 @@ func (c *OAuthController) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state := generateStateToken()
-	// 本番環境ではセッションやクッキーに保存してCSRF対策を行う
+	// state はブラウザの Cookie にも置き、コールバックで一致を確かめる
+	state := setOAuthStateCookie(w)
 	url := c.oauthService.GetGoogleAuthURL(state)
 
 	// リダイレクトURLをJSON形式で返す（フロントエンド側でリダイレクト）
@@ -48,20 +53,24 @@ func (c *OAuthController) GoogleCallback(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// 本番環境ではstateの検証を行う
-	// state := r.URL.Query().Get("state")
+	// ログイン中のユーザーによる連携操作の場合は state に署名済みのユーザーIDが入っている
+	state, nonce := r.URL.Query().Get("state"), takeOAuthStateCookie(w, r)
+	if userID, ok := c.oauthService.LinkStateUserID(state, "google", nonce); ok {
+		c.finishLink(w, r, "google", userID, code)
+		return
+	}
+	if !oauthStateMatches(state, nonce) {
+		http.Redirect(w, r, frontendURL()+"?error="+url.QueryEscape(errOAuthStateMismatch), http.StatusTemporaryRedirect)
+		return
+	}
 
 	resp, err := c.oauthService.HandleGoogleCallback(r.Context(), code)
 	if err != nil {
 		// エラー時はフロントエンドにリダイレクトしてエラーを表示
-		http.Redirect(w, r, "http://localhost:3000?error="+err.Error(), http.StatusTemporaryRedirect)
+		http.Redirect(w, r, frontendURL()+"?error="+url.QueryEscape(err.Error()), http.StatusTemporaryRedirect)
 		return
 	}
-
-	// 認証成功時はユーザー情報をクエリパラメータとしてフロントエンドに渡してリダイレクト
-	userData, _ := json.Marshal(resp)
-	redirectURL := "http://localhost:3000/auth/callback?provider=google&code=" + code + "&user=" + base64.URLEncoding.EncodeToString(userData)
-	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+	c.redirectWithLoginCode(w, r, "google", resp, "")
 }
 
 // GitHubLogin GitHub OAuth認証開始
@@ -71,7 +80,7 @@ func (c *OAuthController) GitHubLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state := generateStateToken()
+	state := setOAuthStateCookie(w)
 	url := c.oauthService.GetGitHubAuthURL(state)
 
 	w.Header().Set("Content-Type", "application/json")
@@ -94,22 +103,205 @@ func (c *OAuthController) GitHubCallback(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	state, nonce := r.URL.Query().Get("state"), takeOAuthStateCookie(w, r)
+	if userID, ok := c.oauthService.LinkStateUserID(state, "github", nonce); ok {
+		c.finishLink(w, r, "github", userID, code)
+		return
+	}
+	if !oauthStateMatches(state, nonce) {
+		http.Redirect(w, r, frontendURL()+"?error="+url.QueryEscape(errOAuthStateMismatch), http.StatusTemporaryRedirect)
+		return
+	}
+
 	resp, err := c.oauthService.HandleGitHubCallback(r.Context(), code)
 	if err != nil {
 		// エラー時はフロントエンドにリダイレクトしてエラーを表示
-		http.Redirect(w, r, "http://localhost:3000?error="+err.Error(), http.StatusTemporaryRedirect)
+		http.Redirect(w, r, frontendURL()+"?error="+url.QueryEscape(err.Error()), http.StatusTemporaryRedirect)
+		return
+	}
+	c.redirectWithLoginCode(w, r, "github", resp, "")
+}
+
+// finishLink 連携用のコールバックを処理してプロフィール画面にリダイレクトする。
+// ゲストのアップグレードでセッションが切り替わった場合はログイン時と同じくユーザー情報を渡す
+func (c *OAuthController) finishLink(w http.ResponseWriter, r *http.Request, provider string, userID uint, code string) {
+	resp, err := c.oauthService.HandleLinkCallback(r.Context(), provider, userID, code)
+	if err != nil {
+		http.Redirect(w, r, frontendURL()+"/profile?link_error="+url.QueryEscape(err.Error()), http.StatusTemporaryRedirect)
+		return
+	}
+	if resp != nil {
+		c.redirectWithLoginCode(w, r, provider, resp, "&upgraded=1")
+		return
+	}
+	http.Redirect(w, r, frontendURL()+"/profile?linked="+provider, http.StatusTemporaryRedirect)
+}
+
+// redirectWithLoginCode ログイン結果を一度きりのコードと引き換えに預け、コードだけを付けてフロントエンドにリダイレクトする。
+// トークンは URL に載せず、フロントエンドが POST /api/auth/oauth/exchange で受け取る
+func (c *OAuthController) redirectWithLoginCode(w http.ResponseWriter, r *http.Request, provider string, resp *services.AuthResponse, extra string) {
+	loginCode, err := c.oauthService.IssueLoginCode(resp)
+	if err != nil {
+		http.Redirect(w, r, frontendURL()+"?error="+url.QueryEscape("failed to issue login code"), http.StatusTemporaryRedirect)
+		return
+	}
+	http.Redirect(w, r, frontendURL()+"/auth/callback?provider="+provider+extra+"&login_code="+url.QueryEscape(loginCode), http.StatusTemporaryRedirect)
+}
+
+// ExchangeLoginCode POST /api/auth/oauth/exchange
+// OAuth コールバックのリダイレクトで渡した一度きりのコードをセッション（トークンを含むユーザー情報）と交換する
+func (c *OAuthController) ExchangeLoginCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Code == "" {
+		http.Error(w, "code is required", http.StatusBadRequest)
+		return
+	}
+	resp, err := c.oauthService.ExchangeLoginCode(body.Code)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Identities GET /api/auth/identities
+// ログイン手段（パスワードの有無と連携済みの外部アカウント）を返す
+func (c *OAuthController) Identities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	accounts, err := c.oauthService.ListLinkedAccounts(userID)
+	if err != nil {
+		writeIdentityError(w, err)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accounts)
+}
+
+// IdentityRoute /api/auth/identities/{provider} 配下のディスパッチ
+//
+//	POST   /api/auth/identities/{provider}/link  連携の開始（認証 URL を返す）
+//	DELETE /api/auth/identities/{provider}       連携の解除
+func (c *OAuthController) IdentityRoute(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/auth/identities/"), "/")
+	provider, action, _ := strings.Cut(path, "/")
+	if provider != "google" && provider != "github" {
+		http.Error(w, "unsupported provider", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case action == "link" && r.Method == http.MethodPost:
+		// 連携を始めたブラウザでしかコールバックを受け付けないよう、state に Cookie の nonce のハッシュを含める
+		authURL, state, err := c.oauthService.BeginLink(userID, provider, setOAuthStateCookie(w))
+		if err != nil {
+			writeIdentityError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"auth_url": authURL,
+			"state":    state,
+		})
+	case action == "" && r.Method == http.MethodDelete:
+		if err := c.oauthService.UnlinkIdentity(userID, provider); err != nil {
+			writeIdentityError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"message": "連携を解除しました"})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
 
-	// 認証成功時はユーザー情報をクエリパラメータとしてフロントエンドに渡してリダイレクト
-	userData, _ := json.Marshal(resp)
-	redirectURL := "http://localhost:3000/auth/callback?provider=github&code=" + code + "&user=" + base64.URLEncoding.EncodeToString(userData)
-	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+func writeIdentityError(w http.ResponseWriter, err error) {
+	switch {
+	case err.Error() == "user not found", errors.Is(err, services.ErrIdentityNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrLastLoginMethod):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err.Error() == "guest users cannot link accounts":
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
 }
 
+// frontendURL 連携後のリダイレクト先（APP_URL 未設定時はローカル開発用）
+func frontendURL() string {
+	if appURL := os.Getenv("APP_URL"); appURL != "" {
+		return strings.TrimRight(appURL, "/")
+	}
+	return "http://localhost:3000"
+}
+
+// oauthStateCookie OAuth の開始時にブラウザに置く nonce の Cookie。
+// コールバックは外部サイトからの遷移のため SameSite=Lax にする
+const (
+	oauthStateCookie       = "oauth_state"
+	oauthStateCookieMaxAge = 10 * 60
+	errOAuthStateMismatch  = "invalid oauth state"
+)
+
 // generateStateToken CSRF対策用のランダムなstateトークンを生成
 func generateStateToken() string {
 	b := make([]byte, 32)
 	rand.Read(b)
 	return base64.URLEncoding.EncodeToString(b)
 }
+
+// setOAuthStateCookie は新しい nonce を Cookie に置いて返す
+func setOAuthStateCookie(w http.ResponseWriter) string {
+	nonce := generateStateToken()
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    nonce,
+		Path:     "/api/auth/",
+		MaxAge:   oauthStateCookieMaxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(frontendURL(), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nonce
+}
+
+// takeOAuthStateCookie は OAuth の開始時に置いた nonce を返し、Cookie を消す（一度きり）
+func takeOAuthStateCookie(w http.ResponseWriter, r *http.Request) string {
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil {
+		return ""
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Path:     "/api/auth/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(frontendURL(), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return cookie.Value
+}
+
+// oauthStateMatches はログインの state がブラウザの Cookie の nonce と一致するかを返す
+func oauthStateMatches(state, nonce string) bool {
+	return state != "" && nonce != "" && subtle.ConstantTimeCompare([]byte(state), []byte(nonce)) == 1
+}
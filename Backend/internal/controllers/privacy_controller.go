@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"Backend/internal/services"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PrivacyController 個人データの開示（エクスポート）・削除（個人情報保護法第28条・第35条対応）
+type PrivacyController struct {
+	service *services.PersonalDataService
+}
+
+func NewPrivacyController(service *services.PersonalDataService) *PrivacyController {
+	return &PrivacyController{service: service}
+}
+
+// Export POST /api/privacy/export
+func (c *PrivacyController) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	job, err := c.service.RequestExport(userID, actorEmail(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// Erasure アカウントと全データを削除する。保存済みファイルの削除状況は status_token で確認できる
+// POST /api/privacy/erasure, DELETE /api/auth/account
+func (c *PrivacyController) Erasure(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	job, token, err := c.service.RequestErasure(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":      "アカウントを削除しました",
+		"job":          job,
+		"status_token": token,
+	})
+}
+
+// JobRoute GET /api/privacy/jobs/{id}, GET /api/privacy/jobs/{id}/download
+func (c *PrivacyController) JobRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/api/privacy/jobs/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	jobID, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case "":
+		job, err := c.service.GetJob(userID, uint(jobID))
+		if err != nil {
+			writePrivacyError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	case "download":
+		f, err := c.service.OpenExport(userID, uint(jobID))
+		if err != nil {
+			writePrivacyError(w, err)
+			return
+		}
+		defer f.Close()
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="personal-data-%d.zip"`, jobID))
+		io.Copy(w, f)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ErasureStatus 削除ジョブの進捗（削除後はログインできないため状況確認トークンで参照する）
+// GET /api/privacy/erasure/status?token=
+func (c *PrivacyController) ErasureStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	job, err := c.service.JobByStatusToken(r.URL.Query().Get("token"))
+	if err != nil {
+		writePrivacyError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func writePrivacyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrPersonalDataJobNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrExportNotReady):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
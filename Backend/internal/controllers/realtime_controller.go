@@ -17,7 +17,6 @@ func NewRealtimeController(interviewService *services.InterviewService, realtime
 }
 
 type realtimeTokenRequest struct {
-	UserID      uint `json:"user_id"`
 	InterviewID uint `json:"interview_id"`
 }
 
@@ -30,17 +29,24 @@ func (c *RealtimeController) Token(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
 	var req realtimeTokenRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	if req.UserID == 0 || req.InterviewID == 0 {
-		http.Error(w, "user_id and interview_id are required", http.StatusBadRequest)
+	if req.InterviewID == 0 {
+		http.Error(w, "interview_id is required", http.StatusBadRequest)
 		return
 	}
-	secret, err := c.interviewService.CreateRealtimeToken(r.Context(), req.UserID, req.InterviewID)
+	secret, err := c.interviewService.CreateRealtimeToken(r.Context(), userID, req.InterviewID)
 	if err != nil {
+		if writeBudgetExceeded(w, err) {
+			return
+		}
 		status := http.StatusBadRequest
 		if err.Error() == "forbidden" {
 			status = http.StatusForbidden
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"Backend/domain/entity"
+	"Backend/internal/middleware"
+	"net/http"
+)
+
+// requireUserID 認証ミドルウェアが解決した呼び出し元ユーザーIDを返す。
+// 未認証の場合は 401 を書き込み false を返す。
+func requireUserID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID := middleware.CurrentUserID(r)
+	if userID == 0 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	return userID, true
+}
+
+// requireUser 認証済みユーザーを返す。未認証の場合は 401 を書き込み false を返す。
+func requireUser(w http.ResponseWriter, r *http.Request) (*entity.User, bool) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
+
+// actorEmail 監査ログに記録する操作者（検証済みユーザー）のメールアドレスを返す
+func actorEmail(r *http.Request) string {
+	if user, ok := middleware.UserFromContext(r.Context()); ok {
+		return user.Email
+	}
+	return ""
+}
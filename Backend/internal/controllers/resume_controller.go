@@ -30,21 +30,14 @@ func (c *ResumeController) Upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userIDStr := r.FormValue("user_id")
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
 	sessionID := r.FormValue("session_id")
 	sourceType := r.FormValue("source_type")
 	sourceURL := r.FormValue("source_url")
 
-	if userIDStr == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
-		return
-	}
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid user_id", http.StatusBadRequest)
-		return
-	}
-
 	var fileHeader *multipart.FileHeader
 	file, header, err := r.FormFile("file")
 	if err == nil {
@@ -52,7 +45,7 @@ func (c *ResumeController) Upload(w http.ResponseWriter, r *http.Request) {
 		fileHeader = header
 	}
 
-	result, err := c.resumeService.Upload(uint(userID), sessionID, sourceType, sourceURL, fileHeader)
+	result, err := c.resumeService.Upload(userID, sessionID, sourceType, sourceURL, fileHeader)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -67,6 +60,10 @@ func (c *ResumeController) Review(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
 
 	docIDStr := r.URL.Query().Get("document_id")
 	if docIDStr == "" {
@@ -99,9 +96,16 @@ func (c *ResumeController) Review(w http.ResponseWriter, r *http.Request) {
 		payload.CandidateType,
 	)
 
-	review, items, err := c.resumeService.ReviewDocument(uint(docID), payload.CompanyName, payload.JobTitle, payload.CandidateType)
+	review, items, err := c.resumeService.ReviewDocument(r.Context(), userID, uint(docID), payload.CompanyName, payload.JobTitle, payload.CandidateType)
 	if err != nil {
 		log.Printf("resume_review: failed document_id=%d err=%v", docID, err)
+		if err.Error() == "forbidden" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if writeBudgetExceeded(w, err) {
+			return
+		}
 		var ve *services.ValidationError
 		if errors.As(err, &ve) {
 			http.Error(w, ve.Message, http.StatusUnprocessableEntity)
@@ -125,6 +129,10 @@ func (c *ResumeController) ReviewStream(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
 
 	docIDStr := r.URL.Query().Get("document_id")
 	if docIDStr == "" {
@@ -159,7 +167,7 @@ func (c *ResumeController) ReviewStream(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no")
 
-	if err := c.resumeService.ReviewDocumentStream(r.Context(), uint(docID), payload.CompanyName, payload.JobTitle, payload.CandidateType, w); err != nil {
+	if err := c.resumeService.ReviewDocumentStream(r.Context(), userID, uint(docID), payload.CompanyName, payload.JobTitle, payload.CandidateType, w); err != nil {
 		log.Printf("resume_review_stream: error document_id=%d err=%v", docID, err)
 	}
 }
@@ -169,6 +177,10 @@ func (c *ResumeController) Annotated(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
 	docIDStr := r.URL.Query().Get("document_id")
 	if docIDStr == "" {
 		http.Error(w, "document_id is required", http.StatusBadRequest)
@@ -180,8 +192,12 @@ func (c *ResumeController) Annotated(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, err := c.resumeService.OpenAnnotatedFile(uint(docID))
+	file, err := c.resumeService.OpenAnnotatedFile(userID, uint(docID))
 	if err != nil {
+		if err.Error() == "forbidden" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
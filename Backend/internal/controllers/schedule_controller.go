@@ -33,18 +33,6 @@ func parseScheduleRequest(r *http.Request) (scheduleRequest, error) {
 	return req, nil
 }
 
-func getUserID(r *http.Request) (uint, error) {
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		return 0, errors.New("user_id is required")
-	}
-	id, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil {
-		return 0, errors.New("invalid user_id")
-	}
-	return uint(id), nil
-}
-
 func getEventID(r *http.Request, pathPrefix string) (uint, error) {
 	idStr := r.URL.Path[len(pathPrefix):]
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -54,15 +42,14 @@ func getEventID(r *http.Request, pathPrefix string) (uint, error) {
 	return uint(id), nil
 }
 
-// List GET /api/schedule?user_id=X
+// List GET /api/schedule
 func (c *ScheduleController) List(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	userID, err := getUserID(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 	events, err := c.service.List(userID)
@@ -74,15 +61,14 @@ func (c *ScheduleController) List(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(events)
 }
 
-// Create POST /api/schedule?user_id=X
+// Create POST /api/schedule
 func (c *ScheduleController) Create(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	userID, err := getUserID(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 	req, err := parseScheduleRequest(r)
@@ -105,15 +91,14 @@ func (c *ScheduleController) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(event)
 }
 
-// Get GET /api/schedule/{id}?user_id=X
+// Get GET /api/schedule/{id}
 func (c *ScheduleController) Get(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	userID, err := getUserID(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 	eventID, err := getEventID(r, "/api/schedule/")
@@ -134,15 +119,14 @@ func (c *ScheduleController) Get(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(event)
 }
 
-// Update PUT /api/schedule/{id}?user_id=X
+// Update PUT /api/schedule/{id}
 func (c *ScheduleController) Update(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	userID, err := getUserID(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 	eventID, err := getEventID(r, "/api/schedule/")
@@ -176,15 +160,14 @@ func (c *ScheduleController) Update(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(event)
 }
 
-// Delete DELETE /api/schedule/{id}?user_id=X
+// Delete DELETE /api/schedule/{id}
 func (c *ScheduleController) Delete(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	userID, err := getUserID(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 	eventID, err := getEventID(r, "/api/schedule/")
@@ -229,15 +212,14 @@ func (c *ScheduleController) RouteByID(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// ExportICS GET /api/schedule/export/ics?user_id=X
+// ExportICS GET /api/schedule/export/ics
 func (c *ScheduleController) ExportICS(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	userID, err := getUserID(r)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	userID, ok := requireUserID(w, r)
+	if !ok {
 		return
 	}
 	ics, err := c.service.ExportICS(userID)
@@ -0,0 +1,353 @@
+package controllers
+
+import (
+	"Backend/internal/services"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TeacherController 教員向けのクラス管理・担当学生データ閲覧 API
+type TeacherController struct {
+	cohortService *services.CohortService
+	audit         *services.AuditLogService
+}
+
+func NewTeacherController(cohortService *services.CohortService, audit *services.AuditLogService) *TeacherController {
+	return &TeacherController{cohortService: cohortService, audit: audit}
+}
+
+// Cohorts GET/POST /api/teacher/cohorts - 担当クラス一覧・クラス作成
+func (c *TeacherController) Cohorts(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		cohorts, err := c.cohortService.ListCohorts(user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"cohorts": cohorts})
+	case http.MethodPost:
+		var req struct {
+			SchoolName string `json:"school_name"`
+			Name       string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		cohort, err := c.cohortService.CreateCohort(user, req.SchoolName, req.Name)
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		c.audit.Record(user.Email, "cohort.create", "cohort", cohort.ID, map[string]interface{}{
+			"school_name": cohort.SchoolName,
+			"name":        cohort.Name,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(cohort)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// CohortRoute /api/teacher/cohorts/{id}/... のルーティング
+//
+//	DELETE /api/teacher/cohorts/{id}
+//	GET/POST /api/teacher/cohorts/{id}/students
+//	DELETE /api/teacher/cohorts/{id}/students/{user_id}
+//	GET/POST /api/teacher/cohorts/{id}/invitations
+//	GET /api/teacher/cohorts/{id}/funnel
+//	GET /api/teacher/cohorts/{id}/trends?limit=N
+func (c *TeacherController) CohortRoute(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/teacher/cohorts/"), "/"), "/")
+	cohortID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || cohortID == 0 {
+		http.Error(w, "invalid cohort id", http.StatusBadRequest)
+		return
+	}
+	id := uint(cohortID)
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodDelete:
+		if err := c.cohortService.DeleteCohort(user, id); err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		c.audit.Record(user.Email, "cohort.delete", "cohort", id, nil)
+		w.WriteHeader(http.StatusNoContent)
+	case action == "students" && len(parts) == 3 && r.Method == http.MethodDelete:
+		studentID, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil {
+			http.Error(w, "invalid user id", http.StatusBadRequest)
+			return
+		}
+		if err := c.cohortService.RemoveStudent(user, id, uint(studentID)); err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		c.audit.Record(user.Email, "cohort.remove_student", "cohort", id, map[string]interface{}{"user_id": studentID})
+		w.WriteHeader(http.StatusNoContent)
+	case action == "students" && r.Method == http.MethodGet:
+		students, err := c.cohortService.ListStudents(user, id)
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"students": students})
+	case action == "students" && r.Method == http.MethodPost:
+		var req struct {
+			Email string `json:"email"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+			http.Error(w, "email is required", http.StatusBadRequest)
+			return
+		}
+		student, err := c.cohortService.AddStudent(user, id, req.Email)
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		c.audit.Record(user.Email, "cohort.add_student", "cohort", id, map[string]interface{}{"user_id": student.ID})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"user_id": student.ID, "name": student.Name, "email": student.Email})
+	case action == "invitations" && r.Method == http.MethodGet:
+		invs, err := c.cohortService.ListInvitations(user, id)
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"invitations": invs})
+	case action == "invitations" && r.Method == http.MethodPost:
+		var req struct {
+			Email string `json:"email"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		inv, err := c.cohortService.InviteTeacher(user, id, req.Email)
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		c.audit.Record(user.Email, "cohort.invite_teacher", "cohort", id, map[string]interface{}{"email": inv.Email})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(inv)
+	case action == "funnel" && r.Method == http.MethodGet:
+		funnel, err := c.cohortService.GetCohortFunnel(user, id)
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(funnel)
+	case action == "trends" && r.Method == http.MethodGet:
+		trends, err := c.cohortService.GetCohortTrends(user, id, parseIntQuery(r, "limit", 20))
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"students": trends})
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// StudentRoute /api/teacher/students/{id}/... のルーティング
+//
+//	GET /api/teacher/students/{id}/interviews
+//	GET /api/teacher/students/{id}/interviews/{session_id}
+//	GET /api/teacher/students/{id}/analysis?session_id=X
+func (c *TeacherController) StudentRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/teacher/students/"), "/"), "/")
+	studentID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || studentID == 0 || len(parts) < 2 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case parts[1] == "interviews" && len(parts) == 2:
+		page := parseIntQuery(r, "page", 1)
+		limit := parseIntQuery(r, "limit", 20)
+		if limit > 100 {
+			limit = 100
+		}
+		sessions, total, err := c.cohortService.ListStudentInterviews(user, uint(studentID), limit, (page-1)*limit)
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sessions": sessions,
+			"total":    total,
+			"page":     page,
+			"limit":    limit,
+		})
+	case parts[1] == "interviews" && len(parts) == 3:
+		sessionID, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil {
+			http.Error(w, "invalid interview id", http.StatusBadRequest)
+			return
+		}
+		detail, err := c.cohortService.GetStudentInterview(user, uint(studentID), uint(sessionID))
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(detail)
+	case parts[1] == "analysis":
+		summary, err := c.cohortService.GetStudentAnalysis(r.Context(), user, uint(studentID), r.URL.Query().Get("session_id"))
+		if err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(summary)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// AcceptInvitation POST /api/teacher/invitations/accept - 教員招待の承認
+func (c *TeacherController) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+	cohort, err := c.cohortService.AcceptInvitation(user, req.Token)
+	if err != nil {
+		writeCohortError(w, err)
+		return
+	}
+	c.audit.Record(user.Email, "cohort.accept_invitation", "cohort", cohort.ID, nil)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cohort)
+}
+
+// MyTeachers GET /api/user/teachers - 自分のデータを閲覧できる担当教員の一覧
+func (c *TeacherController) MyTeachers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	teachers, err := c.cohortService.ListTeachersOfStudent(userID)
+	if err != nil {
+		http.Error(w, "failed to fetch teachers", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"teachers": teachers})
+}
+
+// MyCohorts GET /api/user/cohorts - 自分が追加されたクラスの一覧（承認待ちを含む）
+func (c *TeacherController) MyCohorts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	cohorts, err := c.cohortService.ListMyCohorts(userID)
+	if err != nil {
+		http.Error(w, "failed to fetch cohorts", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"cohorts": cohorts})
+}
+
+// MyCohortRoute /api/user/cohorts/{id}/... のルーティング
+//
+//	POST   /api/user/cohorts/{id}/approve  クラスへの追加を承認する（担当教員の閲覧を許可）
+//	DELETE /api/user/cohorts/{id}          クラスへの追加を断る・所属をやめる
+func (c *TeacherController) MyCohortRoute(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/user/cohorts/"), "/"), "/")
+	cohortID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || cohortID == 0 {
+		http.Error(w, "invalid cohort id", http.StatusBadRequest)
+		return
+	}
+	id := uint(cohortID)
+
+	switch {
+	case len(parts) == 2 && parts[1] == "approve" && r.Method == http.MethodPost:
+		if err := c.cohortService.ApproveMembership(user, id); err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		c.audit.Record(user.Email, "cohort.approve_membership", "cohort", id, nil)
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		if err := c.cohortService.LeaveCohort(user, id); err != nil {
+			writeCohortError(w, err)
+			return
+		}
+		c.audit.Record(user.Email, "cohort.leave", "cohort", id, nil)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func writeCohortError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch err.Error() {
+	case "forbidden":
+		status = http.StatusForbidden
+	case "not found":
+		status = http.StatusNotFound
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -17,6 +17,7 @@ func UserToEntity(m *models.User) *entity.User {
 		Name:                     m.Name,
 		IsGuest:                  m.IsGuest,
 		IsAdmin:                  m.IsAdmin,
+		Role:                     m.Role,
 		TargetLevel:              m.TargetLevel,
 		SchoolName:               m.SchoolName,
 		OAuthProvider:            m.OAuthProvider,
@@ -29,6 +30,15 @@ func UserToEntity(m *models.User) *entity.User {
 		LastLoginAt:              m.LastLoginAt,
 		PasswordResetToken:       m.PasswordResetToken,
 		PasswordResetExpiresAt:   m.PasswordResetExpiresAt,
+		FailedLoginCount:         m.FailedLoginCount,
+		LastFailedLoginAt:        m.LastFailedLoginAt,
+		LockedUntil:              m.LockedUntil,
+		UnlockToken:              m.UnlockToken,
+		TOTPSecret:               m.TOTPSecret,
+		TOTPEnabledAt:            m.TOTPEnabledAt,
+		TOTPLastCounter:          m.TOTPLastCounter,
+		TOTPRecoveryCodes:        m.TOTPRecoveryCodes,
+		SessionsRevokedAt:        m.SessionsRevokedAt,
 		CreatedAt:                m.CreatedAt,
 		UpdatedAt:                m.UpdatedAt,
 	}
@@ -46,6 +56,7 @@ func UserFromEntity(e *entity.User) *models.User {
 		Name:                     e.Name,
 		IsGuest:                  e.IsGuest,
 		IsAdmin:                  e.IsAdmin,
+		Role:                     e.Role,
 		TargetLevel:              e.TargetLevel,
 		SchoolName:               e.SchoolName,
 		OAuthProvider:            e.OAuthProvider,
@@ -58,6 +69,15 @@ func UserFromEntity(e *entity.User) *models.User {
 		LastLoginAt:              e.LastLoginAt,
 		PasswordResetToken:       e.PasswordResetToken,
 		PasswordResetExpiresAt:   e.PasswordResetExpiresAt,
+		FailedLoginCount:         e.FailedLoginCount,
+		LastFailedLoginAt:        e.LastFailedLoginAt,
+		LockedUntil:              e.LockedUntil,
+		UnlockToken:              e.UnlockToken,
+		TOTPSecret:               e.TOTPSecret,
+		TOTPEnabledAt:            e.TOTPEnabledAt,
+		TOTPLastCounter:          e.TOTPLastCounter,
+		TOTPRecoveryCodes:        e.TOTPRecoveryCodes,
+		SessionsRevokedAt:        e.SessionsRevokedAt,
 		CreatedAt:                e.CreatedAt,
 		UpdatedAt:                e.UpdatedAt,
 	}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"Backend/domain/ai"
+	"Backend/domain/entity"
+	"Backend/domain/repository"
+	"Backend/internal/services"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type contextKey string
+
+const (
+	authUserKey contextKey = "auth_user"
+	authMFAKey  contextKey = "auth_mfa"
+)
+
+// Authenticator Authorization: Bearer のアクセストークンを検証し、呼び出し元ユーザーをコンテキストに格納する
+type Authenticator struct {
+	tokens   *services.TokenService
+	userRepo repository.UserRepository
+}
+
+func NewAuthenticator(tokens *services.TokenService, userRepo repository.UserRepository) *Authenticator {
+	return &Authenticator{tokens: tokens, userRepo: userRepo}
+}
+
+// Require 認証必須のハンドラをラップする。トークンが無効な場合は 401 を返す
+func (a *Authenticator) Require(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, claims, err := a.authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ctx := WithUser(r.Context(), user)
+		ctx = context.WithValue(ctx, authMFAKey, claims.MFA)
+		ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: user.ID})
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func (a *Authenticator) authenticate(r *http.Request) (*entity.User, *services.TokenClaims, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, nil, errors.New("missing token")
+	}
+	claims, err := a.tokens.VerifyAccessToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := a.userRepo.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, errors.New("user not found")
+	}
+	if user.IsSessionRevoked(time.Unix(claims.IssuedAt, 0)) {
+		return nil, nil, errors.New("session revoked")
+	}
+	return user, claims, nil
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+// WithUser 認証済みユーザーをコンテキストに格納する
+func WithUser(ctx context.Context, user *entity.User) context.Context {
+	return context.WithValue(ctx, authUserKey, user)
+}
+
+// UserFromContext コンテキストから認証済みユーザーを取得する
+func UserFromContext(ctx context.Context) (*entity.User, bool) {
+	user, ok := ctx.Value(authUserKey).(*entity.User)
+	return user, ok && user != nil
+}
+
+// SessionMFAVerified 2段階認証を経たセッションかどうか
+func SessionMFAVerified(ctx context.Context) bool {
+	verified, _ := ctx.Value(authMFAKey).(bool)
+	return verified
+}
+
+// CurrentUserID 認証済みユーザーのIDを返す（未認証時は 0）
+func CurrentUserID(r *http.Request) uint {
+	if user, ok := UserFromContext(r.Context()); ok {
+		return user.ID
+	}
+	return 0
+}
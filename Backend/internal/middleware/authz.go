@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"Backend/domain/entity"
+	"encoding/json"
+	"net/http"
+)
+
+// Permission ルート単位で宣言する操作権限
+type Permission string
+
+const (
+	PermCompaniesManage Permission = "companies:manage" // 企業・企業グラフの編集
+	PermCrawlManage     Permission = "crawl:manage"     // クロールソース・実行管理
+	PermJobsManage      Permission = "jobs:manage"      // 職種・求人・就職実績の編集
+	PermUsersManage     Permission = "users:manage"     // ユーザー管理
+	PermAuditRead       Permission = "audit:read"       // 監査ログ閲覧
+	PermInterviewsRead  Permission = "interviews:read"  // 全ユーザーの面接セッション閲覧
+	PermDashboardRead   Permission = "dashboard:read"   // 管理ダッシュボード・CSV出力
+	PermCostsRead       Permission = "costs:read"       // APIコスト閲覧
+	PermScoringManage   Permission = "scoring:manage"   // プロフィール再計算・スコア検証
+	PermInsightsManage  Permission = "insights:manage"  // 集合知バッチ
+	PermCohortsManage   Permission = "cohorts:manage"   // クラス作成・学生追加・教員招待
+	PermStudentsView    Permission = "students:view"    // 担当学生のレポート・分析閲覧
+	PermSettingsManage  Permission = "settings:manage"  // レート制限などの運用設定
+)
+
+// rolePermissions ロールごとに付与される権限
+var rolePermissions = map[string][]Permission{
+	entity.RoleAdmin: {
+		PermCompaniesManage,
+		PermCrawlManage,
+		PermJobsManage,
+		PermUsersManage,
+		PermAuditRead,
+		PermInterviewsRead,
+		PermDashboardRead,
+		PermCostsRead,
+		PermScoringManage,
+		PermInsightsManage,
+		PermCohortsManage,
+		PermStudentsView,
+		PermSettingsManage,
+	},
+	entity.RoleTeacher: {
+		PermCohortsManage,
+		PermStudentsView,
+	},
+	entity.RoleStudent: {},
+}
+
+// HasPermission ユーザーのロールに権限が付与されているか
+func HasPermission(user *entity.User, perm Permission) bool {
+	if user == nil {
+		return false
+	}
+	for _, p := range rolePermissions[user.EffectiveRole()] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission 認証に加えて権限を検証する。未認証は 401、権限不足は 403 を返す。
+// 管理者・教員の権限は 2段階認証を経たセッションでのみ行使できる（未設定・未入力は 403 と理由コード）
+func (a *Authenticator) RequirePermission(perm Permission, next http.HandlerFunc) http.HandlerFunc {
+	return a.Require(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := UserFromContext(r.Context())
+		if !HasPermission(user, perm) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if user.RequiresMFA() && !SessionMFAVerified(r.Context()) {
+			code := "mfa_required"
+			if !user.HasTOTP() {
+				code = "mfa_enrollment_required"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": code})
+			return
+		}
+		next(w, r)
+	})
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"Backend/internal/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// RateLimit ルートグループ単位でユーザー・IP ごとのレート制限をかける
+type RateLimit struct {
+	limiter *ratelimit.Limiter
+}
+
+func NewRateLimit(limiter *ratelimit.Limiter) *RateLimit {
+	return &RateLimit{limiter: limiter}
+}
+
+// Limit ハンドラにグループの制限をかける。認証済みユーザーを判定するため Authenticator.Require の内側で使う
+func (rl *RateLimit) Limit(group string, next http.HandlerFunc) http.HandlerFunc {
+	return rl.LimitIf(group, nil, next)
+}
+
+// LimitIf match が true を返すリクエストのみ制限する（match が nil なら全リクエスト）
+func (rl *RateLimit) LimitIf(group string, match func(*http.Request) bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || (match != nil && !match(r)) {
+			next(w, r)
+			return
+		}
+		userKey := ""
+		if id := CurrentUserID(r); id != 0 {
+			userKey = strconv.FormatUint(uint64(id), 10)
+		}
+		ok, wait := rl.limiter.Allow(group, userKey, ClientIP(r))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"Backend/domain/ai"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader リクエストIDを受け渡すヘッダー
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID リクエストIDを X-Request-ID から引き継ぐ（なければ採番する）。
+// レスポンスヘッダーに返し、LLM 呼び出しのコスト記録（ai.CallContext）に載せる
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := ai.WithCallContext(r.Context(), ai.CallContext{RequestID: id})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID 外部から受け取った ID はログ・DB に記録するため英数字と - _ . の 64 文字までに限る
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -2,13 +2,15 @@ package models
 
 import "time"
 
+// AIQuestionTemplate カテゴリの質問を AI で作るときの指示
+// ContextKeys はプロンプトに含める文脈の名前（例: ["answer_history", "job_category"]）の JSON 配列
 type AIQuestionTemplate struct {
-	ID          uint   `gorm:"primaryKey"`
-	Category    string `gorm:"size:50;not null;index"`
-	Prompt      string `gorm:"type:text;not null"`
-	BaseWeight  int    `gorm:"default:5"`
-	ContextKeys string `gorm:"type:json"`
-	IsActive    bool   `gorm:"default:true"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Category    string    `gorm:"size:50;not null;index" json:"category"`
+	Prompt      string    `gorm:"type:text;not null" json:"prompt"`
+	BaseWeight  int       `gorm:"default:5" json:"base_weight"`
+	ContextKeys string    `gorm:"type:json" json:"context_keys"`
+	IsActive    bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
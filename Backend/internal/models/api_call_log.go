@@ -11,4 +11,12 @@ type APICallLog struct {
 	TotalTokens      int       `gorm:"not null;default:0"   json:"total_tokens"`
 	CostUSD          float64   `gorm:"type:decimal(14,8);default:0" json:"cost_usd"`
 	CalledAt         time.Time `gorm:"not null;index"       json:"called_at"`
+	// 呼び出し元（ai.CallContext）。UserID が 0 なら利用者に紐づかない呼び出し
+	UserID    uint   `gorm:"not null;default:0;index" json:"user_id"`
+	Feature   string `gorm:"size:50;index"            json:"feature"`
+	RequestID string `gorm:"size:64"                  json:"request_id"`
+	// PromptTokens のうちプロンプトキャッシュに当たった分
+	CachedPromptTokens int `gorm:"not null;default:0" json:"cached_prompt_tokens"`
+	// 料金計算に使った単価（model_prices.id）。nil なら単価未登録のモデルで CostUSD は 0
+	ModelPriceID *uint `gorm:"index" json:"model_price_id"`
 }
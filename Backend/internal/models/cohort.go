@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// 所属ロール
+const (
+	CohortRoleTeacher = "teacher"
+	CohortRoleStudent = "student"
+)
+
+// Cohort 学校単位のクラス（教員が担当する学生グループ）
+type Cohort struct {
+	ID          uint      `gorm:"primaryKey"               json:"id"`
+	SchoolName  string    `gorm:"size:255;not null;index"  json:"school_name"`
+	Name        string    `gorm:"size:100;not null"        json:"name"`
+	CreatedByID uint      `gorm:"index"                    json:"created_by_id"`
+	CreatedAt   time.Time `                                json:"created_at"`
+	UpdatedAt   time.Time `                                json:"updated_at"`
+}
+
+// CohortMember クラスへの所属（教員・学生）。
+// 学生は本人が承認する（ApprovedAt が入る）まで担当教員にデータを閲覧させない
+type CohortMember struct {
+	ID         uint       `gorm:"primaryKey"                                json:"id"`
+	CohortID   uint       `gorm:"not null;uniqueIndex:idx_cohort_member"    json:"cohort_id"`
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_cohort_member;index" json:"user_id"`
+	Role       string     `gorm:"size:20;not null;index"                    json:"role"` // teacher / student
+	ApprovedAt *time.Time `                                                 json:"approved_at,omitempty"`
+	CreatedAt  time.Time  `                                                 json:"created_at"`
+}
+
+// IsApproved 所属が有効か（教員は常に有効、学生は本人の承認後に有効）
+func (m CohortMember) IsApproved() bool {
+	return m.Role == CohortRoleTeacher || m.ApprovedAt != nil
+}
+
+// CohortInvitation 教員招待（メールで送るトークン）
+type CohortInvitation struct {
+	ID             uint       `gorm:"primaryKey"                json:"id"`
+	CohortID       uint       `gorm:"not null;index"            json:"cohort_id"`
+	Email          string     `gorm:"size:255;not null;index"   json:"email"`
+	Token          string     `gorm:"size:64;uniqueIndex"       json:"-"`
+	InvitedByID    uint       `gorm:"index"                     json:"invited_by_id"`
+	ExpiresAt      time.Time  `gorm:"not null"                  json:"expires_at"`
+	AcceptedAt     *time.Time `                                 json:"accepted_at,omitempty"`
+	AcceptedUserID uint       `gorm:"default:0"                 json:"accepted_user_id,omitempty"`
+	CreatedAt      time.Time  `                                 json:"created_at"`
+}
+
+// CohortTeacher 学生から見た担当教員（集計用、テーブルではない）
+type CohortTeacher struct {
+	CohortID   uint   `json:"cohort_id"`
+	CohortName string `json:"cohort_name"`
+	SchoolName string `json:"school_name"`
+	TeacherID  uint   `json:"teacher_id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+}
+
+// StudentCohort 学生から見た所属クラス（承認待ちを含む。集計用、テーブルではない）
+type StudentCohort struct {
+	CohortID   uint       `json:"cohort_id"`
+	CohortName string     `json:"cohort_name"`
+	SchoolName string     `json:"school_name"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+	AddedAt    time.Time  `json:"added_at"`
+}
@@ -130,6 +130,11 @@ type UserCompanyMatch struct {
 	DetailMatch        float64 // 細部志向マッチ度
 	CommunicationMatch float64 // コミュニケーション力マッチ度
 
+	// スコアキャリブレーション
+	CalibrationVersion       int      `gorm:"not null;default:0"` // MatchScore の計算に使ったキャリブレーションの版（0 は重みなし）
+	ShadowMatchScore         *float64 // シャドーモードで並べて計算した総合マッチ度
+	ShadowCalibrationVersion int      `gorm:"not null;default:0"` // ShadowMatchScore の計算に使った版（0 は重みなし）
+
 	// マッチング理由・推薦文
 	MatchReason string `gorm:"type:text"` // AIが生成したマッチング理由
 
@@ -149,7 +154,8 @@ type UserApplicationStatus struct {
 	User      User    `gorm:"foreignKey:UserID"`
 	CompanyID uint    `gorm:"not null;index:idx_user_company_app"`
 	Company   Company `gorm:"foreignKey:CompanyID"`
-	MatchID   uint    `gorm:"not null;index"` // UserCompanyMatch との紐付け
+	MatchID   uint    `gorm:"not null;index"`          // UserCompanyMatch との紐付け
+	SessionID string  `gorm:"type:varchar(255);index"` // マッチングを作った診断セッション（A/Bテストのバリアントとの紐付け）
 
 	// 選考ステータス
 	// applied: 応募済み / document_passed: 書類通過 / interview: 面接中 /
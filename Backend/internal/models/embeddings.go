@@ -23,6 +23,19 @@ type JobCategoryEmbedding struct {
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+// EntityEmbedding stores a search embedding for a company or job position.
+// Vectors are float32 little-endian bytes (vectorindex.EncodeVector) and are loaded into the in-memory index on startup.
+type EntityEmbedding struct {
+	ID         uint      `gorm:"primaryKey"`
+	Kind       string    `gorm:"size:30;not null;uniqueIndex:idx_entity_embedding"` // company, job_position
+	EntityID   uint      `gorm:"not null;uniqueIndex:idx_entity_embedding"`
+	SourceText string    `gorm:"type:text"` // Source text (re-embedded only when it changes)
+	Vector     []byte    `gorm:"type:mediumblob;not null"`
+	Dimensions int       `gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
 func (UserEmbedding) TableName() string {
 	return "user_embeddings"
 }
@@ -30,3 +43,7 @@ func (UserEmbedding) TableName() string {
 func (JobCategoryEmbedding) TableName() string {
 	return "job_category_embeddings"
 }
+
+func (EntityEmbedding) TableName() string {
+	return "entity_embeddings"
+}
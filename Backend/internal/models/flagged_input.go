@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// FlaggedInput 入力ガードが検出した利用者の入力（LLM に渡す前の指示の上書き・過大な入力・不適切な内容）
+// 入力全文は保存せず、検出箇所の抜粋だけを残す
+type FlaggedInput struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"index" json:"user_id"` // 利用者に紐づかない入力は 0
+	Feature     string    `gorm:"size:50;index:idx_flagged_inputs_feature_created" json:"feature"`
+	Action      string    `gorm:"size:20" json:"action"` // continue / sanitize / block
+	Kinds       string    `gorm:"size:100" json:"kinds"` // injection / oversized / disallowed（カンマ区切り）
+	Rules       string    `gorm:"size:255" json:"rules"` // 一致したルール名（カンマ区切り）
+	Source      string    `gorm:"size:20" json:"source"` // heuristic / classifier
+	Excerpt     string    `gorm:"size:500" json:"excerpt"`
+	InputLength int       `json:"input_length"` // 入力の文字数
+	CreatedAt   time.Time `gorm:"index:idx_flagged_inputs_feature_created" json:"created_at"`
+}
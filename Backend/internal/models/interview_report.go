@@ -11,6 +11,7 @@ type InterviewReport struct {
 	StrengthsJSON     string `gorm:"type:json"                     json:"strengths_json"`
 	ImprovementsJSON  string `gorm:"type:json"                     json:"improvements_json"`
 	TeacherReportJSON string `gorm:"type:json"                     json:"teacher_report_json"` // 教員用詳細レポート
+	PromptVersion     string `gorm:"size:150"                      json:"prompt_version"` // 生成に使ったプロンプトの版（例: interview_report@v3）
 	CreatedAt         time.Time                                    `json:"created_at"`
 	UpdatedAt         time.Time                                    `json:"updated_at"`
 }
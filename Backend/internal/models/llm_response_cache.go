@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LLMResponseCache 冪等な LLM 呼び出しの応答キャッシュ（キーはモデル・プロンプト・生成パラメータのハッシュ）
+type LLMResponseCache struct {
+	ID        uint      `gorm:"primaryKey"                  json:"id"`
+	CacheKey  string    `gorm:"size:64;uniqueIndex"         json:"cache_key"`
+	Scope     string    `gorm:"size:100;index"              json:"scope"` // 呼び出し箇所（company_reading 等）
+	Value     string    `gorm:"type:longtext"               json:"-"`
+	ExpiresAt time.Time `gorm:"index"                       json:"expires_at"`
+	CreatedAt time.Time `                                   json:"created_at"`
+	UpdatedAt time.Time `                                   json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LLMUserBudget ユーザーごとの LLM 予算の上書き設定（未設定のユーザーは LLM_USER_*_BUDGET_USD の既定値を使う）
+type LLMUserBudget struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	UserID          uint      `gorm:"not null;uniqueIndex" json:"user_id"`
+	DailyLimitUSD   float64   `gorm:"type:decimal(10,4);not null;default:0" json:"daily_limit_usd"`   // 0 なら無制限
+	MonthlyLimitUSD float64   `gorm:"type:decimal(10,4);not null;default:0" json:"monthly_limit_usd"` // 0 なら無制限
+	Action          string    `gorm:"size:20;not null" json:"action"`                                 // refuse / downgrade
+	UpdatedBy       string    `gorm:"size:255" json:"updated_by"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
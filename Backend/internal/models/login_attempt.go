@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LoginAttempt パスワードログインの試行履歴（IP 単位の試行制限と管理画面での確認に使う）
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"` // 該当ユーザーが存在しない場合は 0
+	Email     string    `gorm:"size:255;index" json:"email"`
+	IPAddress string    `gorm:"size:64;index:idx_login_attempts_ip_created" json:"ip_address"`
+	Success   bool      `json:"success"`
+	Reason    string    `gorm:"size:50" json:"reason"` // invalid_password / unknown_email / locked など
+	CreatedAt time.Time `gorm:"index:idx_login_attempts_ip_created" json:"created_at"`
+}
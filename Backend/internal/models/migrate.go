@@ -3,6 +3,9 @@ package models
 import "gorm.io/gorm"
 
 func AutoMigrate(db *gorm.DB) error {
+	if err := dedupeVariantAssignments(db); err != nil {
+		return err
+	}
 	return db.AutoMigrate(
 		&User{},
 		&Industry{},
@@ -56,6 +59,8 @@ func AutoMigrate(db *gorm.DB) error {
 		&ScheduleEvent{},
 		// APIコストモニタリング
 		&APICallLog{},
+		&LLMUserBudget{},
+		&ModelPrice{},
 		// 集合知レコメンド
 		&CollectiveInsightLog{},
 		&AnonymizedBehaviorSummary{},
@@ -63,11 +68,44 @@ func AutoMigrate(db *gorm.DB) error {
 		&QuestionVariant{},
 		&VariantAssignment{},
 		&ScoreCalibrationWeight{},
+		&ScoreCalibrationSetting{},
 		// GitHub連携
 		&GitHubProfile{},
 		&GitHubRepo{},
 		&GitHubLanguageStat{},
 		&GitHubRepoSummary{},
 		&SkillScore{},
+		// 教員・クラス管理
+		&Cohort{},
+		&CohortMember{},
+		&CohortInvitation{},
+		// レート制限
+		&RateLimitSetting{},
+		// ログイン試行制限
+		&LoginAttempt{},
+		// 外部 ID 連携
+		&UserIdentity{},
+		// 個人データのエクスポート・削除
+		&PersonalDataJob{},
+		// LLM 応答キャッシュ
+		&LLMResponseCache{},
+		// プロンプトの版管理
+		&PromptVersion{},
+		&PromptPublication{},
+		// LLM への入力ガード
+		&FlaggedInput{},
+		// 企業・募集職種の検索用の埋め込み
+		&EntityEmbedding{},
 	)
 }
+
+// dedupeVariantAssignments 一意インデックスを作る前に、同じセッション・実験への重複した割り当てを最初の 1 行だけ残して消す
+func dedupeVariantAssignments(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&VariantAssignment{}) || m.HasIndex(&VariantAssignment{}, "idx_user_session_experiment") {
+		return nil
+	}
+	return db.Exec(`DELETE a FROM variant_assignments a
+		JOIN variant_assignments b
+			ON a.user_id = b.user_id AND a.session_id = b.session_id AND a.experiment_name = b.experiment_name AND a.id > b.id`).Error
+}
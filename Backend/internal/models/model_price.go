@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ModelPrice モデルごとの単価（USD）。同じモデルに複数行あれば、呼び出し日時以前で最も新しい EffectiveFrom の行を使う
+// Model は前方一致（"gpt-4o" は "gpt-4o-2024-08-06" にも当たる）で、より長い名前の行を優先する
+type ModelPrice struct {
+	ID                    uint      `gorm:"primaryKey" json:"id"`
+	Model                 string    `gorm:"size:100;not null;uniqueIndex:idx_model_price_effective" json:"model"`
+	EffectiveFrom         time.Time `gorm:"not null;uniqueIndex:idx_model_price_effective" json:"effective_from"`
+	InputPerMTokUSD       float64   `gorm:"type:decimal(12,6);not null;default:0" json:"input_per_mtok_usd"`        // 入力 100 万トークンあたり
+	CachedInputPerMTokUSD float64   `gorm:"type:decimal(12,6);not null;default:0" json:"cached_input_per_mtok_usd"` // キャッシュ済み入力。0 なら入力と同じ単価
+	OutputPerMTokUSD      float64   `gorm:"type:decimal(12,6);not null;default:0" json:"output_per_mtok_usd"`
+	PerMinuteUSD          float64   `gorm:"type:decimal(10,4);not null;default:0" json:"per_minute_usd"` // リアルタイム音声・文字起こしの 1 分あたり
+	Note                  string    `gorm:"size:255" json:"note"`
+	UpdatedBy             string    `gorm:"size:255" json:"updated_by"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
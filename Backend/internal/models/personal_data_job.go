@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PersonalDataJob 個人データのエクスポート・完全削除ジョブ（S3 オブジェクトの処理に時間がかかるため非同期で実行する）
+type PersonalDataJob struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"index;not null" json:"user_id"`                    // 削除ジョブではユーザー削除後も ID のみ残る
+	Kind   string `gorm:"size:20;not null" json:"kind"`                     // export / erasure
+	Status string `gorm:"size:20;not null;default:'pending'" json:"status"` // pending / running / completed / failed
+	// StatusToken 削除後はログインできないため、完了確認にはこのトークンを使う
+	StatusToken  string     `gorm:"size:64;uniqueIndex" json:"-"`
+	FilesTotal   int        `gorm:"default:0" json:"files_total"`
+	FilesDone    int        `gorm:"default:0" json:"files_done"`
+	FilesFailed  int        `gorm:"default:0" json:"files_failed"`
+	ArtifactPath string     `gorm:"type:text" json:"-"` // エクスポート ZIP の保存先
+	PendingFiles string     `gorm:"type:text" json:"-"` // 削除待ちのファイル（s3:// URI またはローカルパスの JSON 配列）
+	Error        string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"` // エクスポート ZIP のダウンロード期限
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
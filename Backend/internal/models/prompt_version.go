@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// PromptVersion 管理画面で登録したプロンプトの版（公開中の版がないプロンプトはファイルの既定版を使う）
+// 登録後の本文は変更せず、編集は新しい版として登録する
+type PromptVersion struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_prompt_name_version" json:"name"`
+	Version     int        `gorm:"not null;uniqueIndex:idx_prompt_name_version" json:"version"`
+	Body        string     `gorm:"type:text;not null"       json:"body"`
+	Description string     `gorm:"type:varchar(255)" json:"description"`
+	IsActive    bool       `gorm:"not null;default:false;index" json:"is_active"`
+	PublishedAt *time.Time `json:"published_at,omitempty"` // 最後に公開した日時
+	CreatedBy   string     `gorm:"size:255" json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// PromptPublication プロンプトの公開履歴（追記のみ）。
+// ロールバックは取り消されていない最新の公開を取り消し、その1つ前の公開に戻す
+type PromptPublication struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"type:varchar(100);not null;index" json:"name"`
+	Version     int        `gorm:"not null" json:"version"` // 0 はファイルの既定版
+	PublishedBy string     `gorm:"size:255" json:"published_by"`
+	PublishedAt time.Time  `gorm:"not null" json:"published_at"`
+	RevertedAt  *time.Time `json:"reverted_at,omitempty"` // ロールバックで取り消した日時
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RateLimitSetting 管理画面で変更したルートグループ別のレート制限値（未登録のグループは既定値）
+type RateLimitSetting struct {
+	GroupName     string    `gorm:"primaryKey;size:50"  json:"group"`
+	Enabled       bool      `                           json:"enabled"`
+	UserPerMinute float64   `                           json:"user_per_minute"`
+	UserBurst     int       `                           json:"user_burst"`
+	IPPerMinute   float64   `gorm:"column:ip_per_minute" json:"ip_per_minute"`
+	IPBurst       int       `gorm:"column:ip_burst"     json:"ip_burst"`
+	UpdatedBy     string    `gorm:"size:255"            json:"updated_by"`
+	CreatedAt     time.Time `                           json:"created_at"`
+	UpdatedAt     time.Time `                           json:"updated_at"`
+}
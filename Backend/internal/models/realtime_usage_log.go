@@ -7,6 +7,7 @@ type RealtimeUsageLog struct {
 	ID                 uint       `gorm:"primaryKey" json:"id"`
 	UserID             uint       `gorm:"index;not null" json:"user_id"`
 	InterviewSessionID uint       `gorm:"index;not null" json:"interview_session_id"`
+	Model              string     `gorm:"size:100" json:"model"` // 空なら OPENAI_REALTIME_MODEL の既定値
 	Status             string     `gorm:"size:16;index;not null;default:'active'" json:"status"`
 	StartedAt          time.Time  `gorm:"not null;index" json:"started_at"`
 	EndedAt            *time.Time `gorm:"index" json:"ended_at,omitempty"`
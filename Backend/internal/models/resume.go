@@ -29,12 +29,13 @@ type ResumeTextBlock struct {
 }
 
 type ResumeReview struct {
-	ID         uint      `gorm:"primaryKey" json:"id"`
-	DocumentID uint      `gorm:"not null;index" json:"document_id"`
-	Score      int       `gorm:"not null;default:0" json:"score"`
-	Summary    string    `gorm:"type:text" json:"summary"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	DocumentID    uint      `gorm:"not null;index" json:"document_id"`
+	Score         int       `gorm:"not null;default:0" json:"score"`
+	Summary       string    `gorm:"type:text" json:"summary"`
+	PromptVersion string    `gorm:"size:150" json:"prompt_version"` // 生成に使ったプロンプトの版（例: resume_review@v2）
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type ResumeReviewItem struct {
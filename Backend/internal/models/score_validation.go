@@ -4,24 +4,26 @@ import "time"
 
 // QuestionVariant A/Bテスト用の質問セットバリアント定義
 type QuestionVariant struct {
-	ID             uint      `gorm:"primaryKey" json:"id"`
-	ExperimentName string    `gorm:"type:varchar(100);not null;index:idx_exp_variant" json:"experiment_name"` // 実験名（例: "phase1_2024q1"）
-	VariantName    string    `gorm:"type:varchar(50);not null;index:idx_exp_variant" json:"variant_name"`    // バリアント名（例: "control", "treatment_a"）
-	Description    string    `gorm:"type:text" json:"description"`
-	IsActive       bool      `gorm:"default:true" json:"is_active"`
-	TrafficRatio   float64   `gorm:"default:0.5" json:"traffic_ratio"` // 割り当て比率 0-1
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	ExperimentName     string    `gorm:"type:varchar(100);not null;index:idx_exp_variant" json:"experiment_name"` // 実験名（例: "phase1_2024q1"）
+	VariantName        string    `gorm:"type:varchar(50);not null;index:idx_exp_variant" json:"variant_name"`     // バリアント名（例: "control", "treatment_a"）
+	Description        string    `gorm:"type:text" json:"description"`
+	IsActive           bool      `gorm:"default:true" json:"is_active"`
+	TrafficRatio       float64   `gorm:"default:0.5" json:"traffic_ratio"`      // 割り当て比率 0-1
+	PromptVersionsJSON string    `gorm:"type:text" json:"prompt_versions_json"` // プロンプト名→版の固定（例: {"answer_validation":"v3"}）。空なら公開中の版
+	ConfigJSON         string    `gorm:"type:text" json:"config_json"`          // 質問プール・フェーズの質問数・スコアリングの変更（services.VariantConfig）。空なら既定の挙動
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
-// VariantAssignment セッションへのバリアント割り当て記録
+// VariantAssignment セッションへのバリアント割り当て記録（セッション・実験ごとに 1 行）
 type VariantAssignment struct {
 	ID              uint             `gorm:"primaryKey" json:"id"`
-	UserID          uint             `gorm:"not null;index:idx_user_session_variant" json:"user_id"`
-	SessionID       string           `gorm:"type:varchar(100);not null;index:idx_user_session_variant" json:"session_id"`
+	UserID          uint             `gorm:"not null;uniqueIndex:idx_user_session_experiment" json:"user_id"`
+	SessionID       string           `gorm:"type:varchar(100);not null;uniqueIndex:idx_user_session_experiment" json:"session_id"`
 	VariantID       uint             `gorm:"not null;index" json:"variant_id"`
 	Variant         *QuestionVariant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
-	ExperimentName  string           `gorm:"type:varchar(100);not null" json:"experiment_name"`
+	ExperimentName  string           `gorm:"type:varchar(100);not null;uniqueIndex:idx_user_session_experiment" json:"experiment_name"`
 	AssignedVariant string           `gorm:"type:varchar(50);not null" json:"assigned_variant"`
 	CreatedAt       time.Time        `json:"created_at"`
 }
@@ -40,3 +42,13 @@ type ScoreCalibrationWeight struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
+
+// ScoreCalibrationSetting キャリブレーションの運用設定（1 行のみ）
+// 有効な版は ScoreCalibrationWeight.IsActive で表し、ここではシャドーモードで並べて計算する版を持つ
+type ScoreCalibrationSetting struct {
+	ID            uint      `gorm:"primaryKey" json:"-"`
+	ShadowVersion int       `gorm:"not null;default:0" json:"shadow_version"` // 有効な版と並べて計算する版（0 ならシャドー計算をしない）
+	UpdatedBy     string    `gorm:"size:255" json:"updated_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
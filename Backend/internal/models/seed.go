@@ -1,6 +1,11 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
 
 // SeedData データベースに初期データを投入
 func SeedData(db *gorm.DB) error {
@@ -24,6 +29,11 @@ func SeedData(db *gorm.DB) error {
 		return err
 	}
 
+	// スコアキャリブレーションの運用設定
+	if err := seedCalibrationSetting(db); err != nil {
+		return err
+	}
+
 	// AI質問テンプレート
 	if err := seedAIQuestionTemplates(db); err != nil {
 		return err
@@ -137,17 +147,34 @@ func seedJobCategories(db *gorm.DB) error {
 	return db.Create(&jobCategories).Error
 }
 
-func seedWeightRules(db *gorm.DB) error {
+// seedCalibrationSetting キャリブレーションの運用設定の行を作る。
+// 運用設定がまだないデータベースは版を明示的に有効化する前のもので、保存したすべての版が有効になっているため、
+// このときだけ既存の版をすべて無効にし、calibration/activate で選んだ版だけがマッチングに使われるようにする
+func seedCalibrationSetting(db *gorm.DB) error {
 	var count int64
-	db.Model(&WeightRule{}).Count(&count)
+	if err := db.Model(&ScoreCalibrationSetting{}).Count(&count).Error; err != nil {
+		return err
+	}
 	if count > 0 {
 		return nil
 	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&ScoreCalibrationWeight{}).
+			Where("is_active = ?", true).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return tx.Create(&ScoreCalibrationSetting{ID: 1}).Error
+	})
+}
 
+// seedWeightRules 回答のキーワードで加点するルール（条件の書式は services.WeightRuleCondition）
+// 投入済みのデータベースでは、カテゴリの条件がない初期データのルールにカテゴリを補う
+func seedWeightRules(db *gorm.DB) error {
 	weightRules := []WeightRule{
 		{
 			Name:        "技術関連キーワード強化",
-			Condition:   `{"keywords": ["プログラミング", "コード", "技術", "開発", "エンジニア"]}`,
+			Condition:   `{"keywords": ["プログラミング", "コード", "技術", "開発", "エンジニア"], "categories": ["技術志向"]}`,
 			WeightBoost: 5,
 			Description: "技術関連のキーワードが含まれる場合、技術志向のスコアを強化",
 			Priority:    10,
@@ -155,7 +182,7 @@ func seedWeightRules(db *gorm.DB) error {
 		},
 		{
 			Name:        "チーム関連キーワード強化",
-			Condition:   `{"keywords": ["チーム", "協力", "協働", "メンバー", "リーダー"]}`,
+			Condition:   `{"keywords": ["チーム", "協力", "協働", "メンバー", "リーダー"], "categories": ["チームワーク志向", "チームワーク"]}`,
 			WeightBoost: 3,
 			Description: "チームワーク関連のキーワードが含まれる場合、チームワークのスコアを強化",
 			Priority:    8,
@@ -163,7 +190,7 @@ func seedWeightRules(db *gorm.DB) error {
 		},
 		{
 			Name:        "コミュニケーション関連キーワード強化",
-			Condition:   `{"keywords": ["コミュニケーション", "説明", "伝える", "話す", "対話"]}`,
+			Condition:   `{"keywords": ["コミュニケーション", "説明", "伝える", "話す", "対話"], "categories": ["コミュニケーション力"]}`,
 			WeightBoost: 4,
 			Description: "コミュニケーション関連のキーワードが含まれる場合、スコアを強化",
 			Priority:    9,
@@ -171,7 +198,7 @@ func seedWeightRules(db *gorm.DB) error {
 		},
 		{
 			Name:        "分析思考キーワード強化",
-			Condition:   `{"keywords": ["分析", "データ", "論理", "問題解決", "思考"]}`,
+			Condition:   `{"keywords": ["分析", "データ", "論理", "問題解決", "思考"], "categories": ["問題解決力"]}`,
 			WeightBoost: 4,
 			Description: "分析思考関連のキーワードが含まれる場合、スコアを強化",
 			Priority:    7,
@@ -179,7 +206,7 @@ func seedWeightRules(db *gorm.DB) error {
 		},
 		{
 			Name:        "創造性キーワード強化",
-			Condition:   `{"keywords": ["創造", "アイデア", "革新", "新しい", "デザイン"]}`,
+			Condition:   `{"keywords": ["創造", "アイデア", "革新", "新しい", "デザイン"], "categories": ["創造性志向", "創造性"]}`,
 			WeightBoost: 3,
 			Description: "創造性関連のキーワードが含まれる場合、スコアを強化",
 			Priority:    6,
@@ -187,7 +214,7 @@ func seedWeightRules(db *gorm.DB) error {
 		},
 		{
 			Name:        "リーダーシップキーワード強化",
-			Condition:   `{"keywords": ["リーダー", "リード", "指導", "マネジメント", "統率"]}`,
+			Condition:   `{"keywords": ["リーダー", "リード", "指導", "マネジメント", "統率"], "categories": ["リーダーシップ志向", "リーダーシップ"]}`,
 			WeightBoost: 5,
 			Description: "リーダーシップ関連のキーワードが含まれる場合、スコアを強化",
 			Priority:    8,
@@ -195,9 +222,56 @@ func seedWeightRules(db *gorm.DB) error {
 		},
 	}
 
+	var count int64
+	db.Model(&WeightRule{}).Count(&count)
+	if count > 0 {
+		for _, rule := range weightRules {
+			if err := backfillWeightRuleCategories(db, rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	return db.Create(&weightRules).Error
 }
 
+// backfillWeightRuleCategories は seeded と同じ名前のルールのうち、条件にカテゴリがないものに seeded のカテゴリを補う。
+// 管理画面でカテゴリを設定したルールは変えない
+func backfillWeightRuleCategories(db *gorm.DB, seeded WeightRule) error {
+	var seededCondition map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(seeded.Condition), &seededCondition); err != nil {
+		return fmt.Errorf("invalid seed condition for %s: %w", seeded.Name, err)
+	}
+	categories, ok := seededCondition["categories"]
+	if !ok {
+		return nil
+	}
+
+	var rules []WeightRule
+	if err := db.Where("name = ?", seeded.Name).Find(&rules).Error; err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		var condition map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(rule.Condition), &condition); err != nil || condition == nil {
+			continue
+		}
+		if _, ok := condition["categories"]; ok {
+			continue
+		}
+		condition["categories"] = categories
+		raw, err := json.Marshal(condition)
+		if err != nil {
+			return err
+		}
+		if err := db.Model(&WeightRule{}).Where("id = ?", rule.ID).Update("condition", string(raw)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func seedAIQuestionTemplates(db *gorm.DB) error {
 	var count int64
 	db.Model(&AIQuestionTemplate{}).Count(&count)
@@ -9,7 +9,7 @@ type User struct {
 	Password                 string `gorm:"size:255"` // ハッシュ化されたパスワード (OAuth時は空)
 	Name                     string `gorm:"size:100"`
 	IsGuest                  bool   `gorm:"default:false"`                               // ゲストユーザーフラグ
-	Role                     string `gorm:"size:20;default:'student'" json:"role"`       // ユーザーロール: student / teacher
+	Role                     string `gorm:"size:20;default:'student'" json:"role"`       // ユーザーロール: student / teacher / admin
 	TargetLevel              string `gorm:"size:20;default:'新卒'"`                        // 新卒 or 中途
 	SchoolName               string `gorm:"size:255;column:school_name"`                 // 学校名
 	IsAdmin                  bool   `gorm:"default:false" json:"is_admin"`               // 管理者フラグ
@@ -24,6 +24,15 @@ type User struct {
 	PasswordResetToken       string     `gorm:"size:255;column:password_reset_token"`        // パスワードリセットトークン
 	PasswordResetExpiresAt   *time.Time `gorm:"column:password_reset_expires_at"`            // パスワードリセットトークン有効期限
 	AllowCollectiveInsight   bool       `gorm:"default:true;column:allow_collective_insight"` // 集合知レコメンドへの参加同意
+	FailedLoginCount         int        `gorm:"default:0;column:failed_login_count"`          // 連続ログイン失敗回数
+	LastFailedLoginAt        *time.Time `gorm:"column:last_failed_login_at"`                  // 最終ログイン失敗日時
+	LockedUntil              *time.Time `gorm:"column:locked_until"`                          // アカウントロック解除日時
+	UnlockToken              string     `gorm:"size:255;index;column:unlock_token"`           // ロック解除メールのトークン
+	TOTPSecret               string     `gorm:"size:512;column:totp_secret"`                  // TOTP 共有シークレット（暗号化して保存）
+	TOTPEnabledAt            *time.Time `gorm:"column:totp_enabled_at"`                       // 2段階認証の有効化日時（未設定なら無効）
+	TOTPLastCounter          int64      `gorm:"default:0;column:totp_last_counter"`           // 最後に受理したタイムステップ（再利用防止）
+	TOTPRecoveryCodes        string     `gorm:"type:text;column:totp_recovery_codes"`         // 未使用リカバリーコードのハッシュ（JSON配列）
+	SessionsRevokedAt        *time.Time `gorm:"column:sessions_revoked_at"`                   // これより前に発行したセッショントークンを無効にする
 	CreatedAt                time.Time
 	UpdatedAt                time.Time
 }
@@ -0,0 +1,77 @@
+package models
+
+// UserDataTable ユーザーの個人データを保持するテーブル。
+// 個人データのエクスポート・完全削除とゲストのアカウント統合はこの一覧をもとに行うため、
+// ユーザーに紐付くモデルを追加したら UserDataTables にも登録すること
+type UserDataTable struct {
+	Name  string // エクスポート時のファイル名（<Name>.json）
+	Model interface{}
+	// Column ユーザーを表す列。Parent を指定した場合は親テーブルの主キー（id）を参照する列
+	Column string
+	// Parent 親テーブルの Name。親レコード経由でユーザーに紐付く子テーブルで指定する（親より後に並べること）
+	Parent string
+	// EmailColumn メールアドレスで紐付く列（ユーザー登録前の記録など）。Column と併用できる
+	EmailColumn string
+	// Conflict ユーザーごとに一意になる列。アカウント統合で統合先に同じ値の行があれば統合元の行を破棄する。
+	// Column と同じ列を指定するとユーザーごとに1組だけ持つデータ（統合先にあれば統合元をすべて破棄）、空なら重複を気にせず付け替える
+	Conflict string
+	// Redact エクスポートに含めない列（トークンなどの秘密情報）
+	Redact []string
+	// Files 保存済みファイル（s3:// URI またはローカルパス）を指す列
+	Files []string
+	// ObjectKeys S3 のオブジェクトキー（AWS_S3_BUCKET 内）を持つ列
+	ObjectKeys []string
+}
+
+// OwnedByUserID Column がユーザーIDを直接持つテーブルか（アカウント統合で付け替える対象）
+func (t UserDataTable) OwnedByUserID() bool {
+	return t.Column != "" && t.Parent == ""
+}
+
+// UserDataTables ユーザーの個人データを保持するテーブルの一覧（users 本体と匿名化済みの集合知ログを除く）
+func UserDataTables() []UserDataTable {
+	return []UserDataTable{
+		// 適職診断チャット
+		{Name: "chat_messages", Model: &ChatMessage{}, Column: "user_id"},
+		{Name: "user_weight_scores", Model: &UserWeightScore{}, Column: "user_id"},
+		{Name: "user_analysis_progress", Model: &UserAnalysisProgress{}, Column: "user_id"},
+		{Name: "ai_generated_questions", Model: &AIGeneratedQuestion{}, Column: "user_id"},
+		{Name: "conversation_contexts", Model: &ConversationContext{}, Column: "user_id"},
+		{Name: "user_embeddings", Model: &UserEmbedding{}, Column: "user_id"},
+		{Name: "variant_assignments", Model: &VariantAssignment{}, Column: "user_id"},
+		// 企業マッチング・応募
+		{Name: "user_company_matches", Model: &UserCompanyMatch{}, Column: "user_id"},
+		{Name: "user_application_statuses", Model: &UserApplicationStatus{}, Column: "user_id"},
+		{Name: "company_reviews", Model: &CompanyReview{}, Column: "user_id"},
+		{Name: "schedule_events", Model: &ScheduleEvent{}, Column: "user_id"},
+		// 面接練習
+		{Name: "interview_sessions", Model: &InterviewSession{}, Column: "user_id"},
+		{Name: "interview_utterances", Model: &InterviewUtterance{}, Column: "session_id", Parent: "interview_sessions"},
+		{Name: "interview_reports", Model: &InterviewReport{}, Column: "session_id", Parent: "interview_sessions"},
+		{Name: "interview_videos", Model: &InterviewVideo{}, Column: "user_id", ObjectKeys: []string{"drive_file_id"}},
+		{Name: "realtime_usage_logs", Model: &RealtimeUsageLog{}, Column: "user_id"},
+		// 履歴書
+		{Name: "resume_documents", Model: &ResumeDocument{}, Column: "user_id", Files: []string{"stored_path", "normalized_path", "annotated_path"}},
+		{Name: "resume_text_blocks", Model: &ResumeTextBlock{}, Column: "document_id", Parent: "resume_documents"},
+		{Name: "resume_reviews", Model: &ResumeReview{}, Column: "document_id", Parent: "resume_documents"},
+		{Name: "resume_review_items", Model: &ResumeReviewItem{}, Column: "review_id", Parent: "resume_reviews"},
+		// GitHub連携・スキルスコア
+		{Name: "github_profiles", Model: &GitHubProfile{}, Column: "user_id", Conflict: "user_id", Redact: []string{"access_token"}},
+		{Name: "github_repos", Model: &GitHubRepo{}, Column: "user_id", Conflict: "user_id"},
+		{Name: "github_language_stats", Model: &GitHubLanguageStat{}, Column: "user_id", Conflict: "user_id"},
+		{Name: "github_repo_summaries", Model: &GitHubRepoSummary{}, Column: "user_id", Conflict: "full_name"},
+		{Name: "skill_scores", Model: &SkillScore{}, Column: "user_id", Conflict: "user_id"},
+		// クラス
+		{Name: "cohort_members", Model: &CohortMember{}, Column: "user_id", Conflict: "cohort_id"},
+		{Name: "cohort_invitations", Model: &CohortInvitation{}, EmailColumn: "email", Redact: []string{"token"}},
+		// 認証
+		{Name: "user_identities", Model: &UserIdentity{}, Column: "user_id", Conflict: "provider"},
+		{Name: "login_attempts", Model: &LoginAttempt{}, Column: "user_id", EmailColumn: "email"},
+		{Name: "pending_registrations", Model: &PendingRegistration{}, EmailColumn: "email", Redact: []string{"token"}},
+		// LLM への入力ガード
+		{Name: "flagged_inputs", Model: &FlaggedInput{}, Column: "user_id"},
+		// LLM の利用記録・予算
+		{Name: "api_call_logs", Model: &APICallLog{}, Column: "user_id"},
+		{Name: "llm_user_budgets", Model: &LLMUserBudget{}, Column: "user_id", Conflict: "user_id"},
+	}
+}
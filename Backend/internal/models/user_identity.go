@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UserIdentity ユーザーに連携した外部 ID プロバイダのアカウント（1ユーザーにつきプロバイダごとに1件まで）
+type UserIdentity struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"not null;uniqueIndex:idx_user_identities_user_provider" json:"user_id"`
+	Provider       string     `gorm:"size:50;not null;uniqueIndex:idx_user_identities_user_provider;uniqueIndex:idx_user_identities_provider_subject" json:"provider"` // google / github
+	ProviderUserID string     `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"`                                                     // プロバイダ側のユーザーID
+	Email          string     `gorm:"size:255" json:"email"`                                                                                                           // プロバイダ側のメールアドレス
+	Username       string     `gorm:"size:255" json:"username,omitempty"`                                                                                              // GitHub のログイン名など
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`                                                                                                          // 最後にこの連携でログインした日時
+	CreatedAt      time.Time  `json:"linked_at"`
+	UpdatedAt      time.Time  `json:"-"`
+}
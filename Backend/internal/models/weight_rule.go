@@ -2,14 +2,16 @@ package models
 
 import "time"
 
+// WeightRule 回答のスコアを条件に応じて加点するルール
+// Condition は services.WeightRuleCondition の JSON（例: {"keywords": ["チーム"], "categories": ["チームワーク志向"]}）
 type WeightRule struct {
-	ID          uint   `gorm:"primaryKey"`
-	Name        string `gorm:"size:255;not null"`
-	Condition   string `gorm:"type:json;not null"`
-	WeightBoost int    `gorm:"not null"`
-	Description string `gorm:"type:text"`
-	Priority    int    `gorm:"default:0"`
-	IsActive    bool   `gorm:"default:true"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"size:255;not null" json:"name"`
+	Condition   string    `gorm:"type:json;not null" json:"condition"`
+	WeightBoost int       `gorm:"not null" json:"weight_boost"`
+	Description string    `gorm:"type:text" json:"description"`
+	Priority    int       `gorm:"default:0" json:"priority"`
+	IsActive    bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"Backend/domain/ai"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -16,8 +17,18 @@ import (
 )
 
 // UsageHook はAPIコール成功時に呼ばれるコールバック。
-// model: 使用モデル名, promptTokens: 入力トークン数, completionTokens: 出力トークン数
-type UsageHook func(model string, promptTokens, completionTokens int)
+// ctx: 呼び出し元のコンテキスト（ai.CallContext でユーザー・機能を特定する）, model: 使用モデル名,
+// usage: 入力（うちキャッシュ済み）・出力のトークン数
+type UsageHook func(ctx context.Context, model string, usage ai.TokenUsage)
+
+// TokenUsageOf は Chat Completions API の使用量を ai.TokenUsage に変換する。
+func TokenUsageOf(u openai.Usage) ai.TokenUsage {
+	usage := ai.TokenUsage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens}
+	if u.PromptTokensDetails != nil {
+		usage.CachedPromptTokens = u.PromptTokensDetails.CachedTokens
+	}
+	return usage
+}
 
 // Client は go-openai SDK をラップします。
 type Client struct {
@@ -125,8 +136,11 @@ func (cli *Client) callResponsesAPI(ctx context.Context, input interface{}, mode
 		Content []responsesContent `json:"content"`
 	}
 	type responsesUsage struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
+		InputTokens        int `json:"input_tokens"`
+		OutputTokens       int `json:"output_tokens"`
+		InputTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"input_tokens_details"`
 	}
 	type responsesResponse struct {
 		Output            []responsesOutput `json:"output"`
@@ -148,7 +162,11 @@ func (cli *Client) callResponsesAPI(ctx context.Context, input interface{}, mode
 		return "", err
 	}
 	if cli.OnUsage != nil && (parsed.Usage.InputTokens > 0 || parsed.Usage.OutputTokens > 0) {
-		cli.OnUsage(model, parsed.Usage.InputTokens, parsed.Usage.OutputTokens)
+		cli.OnUsage(ctx, model, ai.TokenUsage{
+			PromptTokens:       parsed.Usage.InputTokens,
+			CachedPromptTokens: parsed.Usage.InputTokensDetails.CachedTokens,
+			CompletionTokens:   parsed.Usage.OutputTokens,
+		})
 	}
 	if strings.TrimSpace(parsed.OutputText) != "" {
 		return strings.TrimSpace(parsed.OutputText), nil
@@ -402,6 +420,25 @@ func (cli *Client) ResponsesWithTemperature(ctx context.Context, systemPrompt, u
 
 // ChatCompletionJSON uses the go-openai SDK to request a JSON response.
 func (cli *Client) ChatCompletionJSON(ctx context.Context, systemPrompt, userPrompt string, temperature float32, maxTokens int, modelOverride ...string) (string, error) {
+	format := &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	return cli.chatCompletionWithFormat(ctx, systemPrompt, userPrompt, temperature, maxTokens, format, modelOverride...)
+}
+
+// ChatCompletionJSONSchema は JSON Schema を応答形式（Structured Outputs）に指定して JSON を生成します。
+// 応答形式に対応しないモデルでは指定なしで再試行します。
+func (cli *Client) ChatCompletionJSONSchema(ctx context.Context, systemPrompt, userPrompt string, temperature float32, maxTokens int, name string, schema json.Marshaler, strict bool, modelOverride ...string) (string, error) {
+	format := &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   name,
+			Schema: schema,
+			Strict: strict,
+		},
+	}
+	return cli.chatCompletionWithFormat(ctx, systemPrompt, userPrompt, temperature, maxTokens, format, modelOverride...)
+}
+
+func (cli *Client) chatCompletionWithFormat(ctx context.Context, systemPrompt, userPrompt string, temperature float32, maxTokens int, format *openai.ChatCompletionResponseFormat, modelOverride ...string) (string, error) {
 	if cli == nil || cli.c == nil {
 		return "", errors.New("openai client is nil")
 	}
@@ -432,9 +469,7 @@ func (cli *Client) ChatCompletionJSON(ctx context.Context, systemPrompt, userPro
 			Temperature:         temperature,
 			MaxTokens:           0,
 			MaxCompletionTokens: maxTokens,
-			ResponseFormat: &openai.ChatCompletionResponseFormat{
-				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
-			},
+			ResponseFormat:      format,
 		}
 
 		resp, err := cli.c.CreateChatCompletion(ctxReq, req)
@@ -456,7 +491,7 @@ func (cli *Client) ChatCompletionJSON(ctx context.Context, systemPrompt, userPro
 			content := strings.TrimSpace(resp.Choices[0].Message.Content)
 			if content != "" {
 				if cli.OnUsage != nil {
-					cli.OnUsage(req.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+					cli.OnUsage(ctx, req.Model, TokenUsageOf(resp.Usage))
 				}
 				return content, nil
 			}
@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ReadChatStream は Chat Completions のストリームを最後まで読み、差分を onDelta に渡しながら全文と使用量を返す。
+// 使用量は stream_options.include_usage を指定した場合の最後のチャンクにだけ含まれる（なければ nil）。
+func ReadChatStream(stream *openai.ChatCompletionStream, onDelta func(string)) (string, *openai.Usage, error) {
+	defer stream.Close()
+	var content strings.Builder
+	var usage *openai.Usage
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return content.String(), usage, nil
+		}
+		if err != nil {
+			return content.String(), usage, err
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if onDelta != nil {
+				onDelta(choice.Delta.Content)
+			}
+		}
+	}
+}
+
+// ChatCompletionStream はシステムプロンプト＋ユーザープロンプトの応答をストリーミングで生成し、
+// 差分を onDelta に渡しながら全文を返す。ストリームの途中で失敗した場合は再試行しない。
+func (cli *Client) ChatCompletionStream(ctx context.Context, systemPrompt, userPrompt string, temperature *float32, maxTokens int, onDelta func(string), modelOverride ...string) (string, error) {
+	if cli == nil || cli.c == nil {
+		return "", errors.New("openai client is nil")
+	}
+	model := cli.DefaultModel
+	if len(modelOverride) > 0 && modelOverride[0] != "" {
+		model = modelOverride[0]
+	}
+	if strings.TrimSpace(model) == "" {
+		model = "gpt-5.2"
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model:               model,
+		MaxCompletionTokens: maxTokens,
+		Stream:              true,
+		StreamOptions:       &openai.StreamOptions{IncludeUsage: true},
+	}
+	if temperature != nil {
+		req.Temperature = *temperature
+	}
+	if systemPrompt != "" {
+		req.Messages = append(req.Messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleSystem, Content: systemPrompt})
+	}
+	req.Messages = append(req.Messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: userPrompt})
+
+	ctxReq, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	stream, err := cli.c.CreateChatCompletionStream(ctxReq, req)
+	if err != nil {
+		return "", err
+	}
+	content, usage, err := ReadChatStream(stream, onDelta)
+	if usage != nil && cli.OnUsage != nil {
+		cli.OnUsage(ctx, model, TokenUsageOf(*usage))
+	}
+	if err != nil {
+		return "", err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", errors.New("empty response from model")
+	}
+	return content, nil
+}
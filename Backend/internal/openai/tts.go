@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"Backend/domain/ai"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -118,8 +119,12 @@ func (cli *Client) TTS(ctx context.Context, text, voice string) ([]byte, error)
 	return io.ReadAll(resp.Body)
 }
 
-// ChatInterview は面接官として会話し、次のメッセージを返します
-func (cli *Client) ChatInterview(ctx context.Context, systemPrompt string, history []map[string]string) (string, error) {
+// ChatMessages は会話履歴（system 発話を含む）から次のメッセージを生成します。
+// model が空の場合は DefaultModel、maxTokens が 0 の場合は上限を指定しません
+func (cli *Client) ChatMessages(ctx context.Context, messages []map[string]string, model string, temperature float32, maxTokens int) (string, error) {
+	if cli.apiKey == "" {
+		return "", errors.New("openai api key is not set")
+	}
 	type message struct {
 		Role    string `json:"role"`
 		Content string `json:"content"`
@@ -127,25 +132,27 @@ func (cli *Client) ChatInterview(ctx context.Context, systemPrompt string, histo
 	type request struct {
 		Model       string    `json:"model"`
 		Messages    []message `json:"messages"`
-		MaxTokens   int       `json:"max_tokens"`
+		MaxTokens   int       `json:"max_tokens,omitempty"`
 		Temperature float32   `json:"temperature"`
 	}
 
-	model := os.Getenv("OPENAI_INTERVIEW_MODEL")
+	if model == "" {
+		model = cli.DefaultModel
+	}
 	if model == "" {
 		model = "gpt-4o-mini"
 	}
 
-	msgs := []message{{Role: "system", Content: systemPrompt}}
-	for _, h := range history {
-		msgs = append(msgs, message{Role: h["role"], Content: h["content"]})
+	msgs := make([]message, 0, len(messages))
+	for _, m := range messages {
+		msgs = append(msgs, message{Role: m["role"], Content: m["content"]})
 	}
 
 	payload := request{
 		Model:       model,
 		Messages:    msgs,
-		MaxTokens:   200,
-		Temperature: 0.7,
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
 	}
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -180,6 +187,13 @@ func (cli *Client) ChatInterview(ctx context.Context, systemPrompt string, histo
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage struct {
+			PromptTokens        int `json:"prompt_tokens"`
+			CompletionTokens    int `json:"completion_tokens"`
+			PromptTokensDetails struct {
+				CachedTokens int `json:"cached_tokens"`
+			} `json:"prompt_tokens_details"`
+		} `json:"usage"`
 	}
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		return "", err
@@ -187,5 +201,12 @@ func (cli *Client) ChatInterview(ctx context.Context, systemPrompt string, histo
 	if len(result.Choices) == 0 {
 		return "", errors.New("no choices returned from chat API")
 	}
+	if cli.OnUsage != nil && (result.Usage.PromptTokens > 0 || result.Usage.CompletionTokens > 0) {
+		cli.OnUsage(ctx, model, ai.TokenUsage{
+			PromptTokens:       result.Usage.PromptTokens,
+			CachedPromptTokens: result.Usage.PromptTokensDetails.CachedTokens,
+			CompletionTokens:   result.Usage.CompletionTokens,
+		})
+	}
 	return strings.TrimSpace(result.Choices[0].Message.Content), nil
 }
@@ -0,0 +1,199 @@
+// Package ratelimit はルートグループ単位のトークンバケット型レート制限を提供する。
+package ratelimit
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit ルートグループの制限値。PerMinute は1分あたりの補充量、Burst はバケット容量
+type Limit struct {
+	Enabled       bool    `json:"enabled"`
+	UserPerMinute float64 `json:"user_per_minute"`
+	UserBurst     int     `json:"user_burst"`
+	IPPerMinute   float64 `json:"ip_per_minute"`
+	IPBurst       int     `json:"ip_burst"`
+}
+
+// ルートグループ（OpenAI 呼び出しを伴うエンドポイント群）
+const (
+	GroupChat      = "chat"
+	GroupES        = "es"
+	GroupInterview = "interview"
+	GroupResume    = "resume"
+	GroupGitHub    = "github"
+	GroupSearch    = "search"
+)
+
+// DefaultLimits 管理画面で上書きされるまでの既定値
+var DefaultLimits = map[string]Limit{
+	GroupChat:      {Enabled: true, UserPerMinute: 20, UserBurst: 10, IPPerMinute: 60, IPBurst: 30},
+	GroupES:        {Enabled: true, UserPerMinute: 10, UserBurst: 5, IPPerMinute: 20, IPBurst: 10},
+	GroupInterview: {Enabled: true, UserPerMinute: 30, UserBurst: 15, IPPerMinute: 90, IPBurst: 30},
+	GroupResume:    {Enabled: true, UserPerMinute: 5, UserBurst: 3, IPPerMinute: 15, IPBurst: 5},
+	GroupGitHub:    {Enabled: true, UserPerMinute: 5, UserBurst: 3, IPPerMinute: 15, IPBurst: 5},
+	GroupSearch:    {Enabled: true, UserPerMinute: 20, UserBurst: 10, IPPerMinute: 30, IPBurst: 10},
+}
+
+const (
+	// 最終アクセスから idleTTL 経過したバケットは破棄する（その時点で満杯まで回復している）
+	idleTTL       = 10 * time.Minute
+	sweepInterval = time.Minute
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter グループ・キーごとのトークンバケットを管理する
+type Limiter struct {
+	mu        sync.Mutex
+	limits    map[string]Limit
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewLimiter 既定値で初期化した Limiter を返す
+func NewLimiter() *Limiter {
+	limits := make(map[string]Limit, len(DefaultLimits))
+	for g, l := range DefaultLimits {
+		limits[g] = l
+	}
+	return &Limiter{
+		limits:  limits,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// SetNow テスト用に時刻関数を差し替える
+func (l *Limiter) SetNow(now func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = now
+}
+
+// SetLimit グループの制限値を更新する。既存バケットは新しい容量に切り詰める
+func (l *Limiter) SetLimit(group string, limit Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[group] = limit
+	for key, b := range l.buckets {
+		g, kind := splitKey(key)
+		if g != group {
+			continue
+		}
+		burst := float64(limit.IPBurst)
+		if kind == "u" {
+			burst = float64(limit.UserBurst)
+		}
+		b.tokens = math.Min(b.tokens, burst)
+	}
+}
+
+// Limits 現在の制限値を返す
+func (l *Limiter) Limits() map[string]Limit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[string]Limit, len(l.limits))
+	for g, v := range l.limits {
+		out[g] = v
+	}
+	return out
+}
+
+// Groups 登録済みのグループ名一覧
+func (l *Limiter) Groups() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	groups := make([]string, 0, len(l.limits))
+	for g := range l.limits {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Allow ユーザー（userKey が空なら省略）と IP の両方のバケットから1トークン消費する。
+// 拒否時は再試行までの待ち時間を返す。どちらかが不足する場合はどちらも消費しない。
+func (l *Limiter) Allow(group, userKey, ipKey string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit, ok := l.limits[group]
+	if !ok || !limit.Enabled {
+		return true, 0
+	}
+	now := l.now()
+	l.sweep(now)
+
+	type check struct {
+		b    *bucket
+		rate float64
+	}
+	var checks []check
+	if userKey != "" && limit.UserPerMinute > 0 {
+		checks = append(checks, check{l.refill(group+"|u|"+userKey, limit.UserPerMinute, limit.UserBurst, now), limit.UserPerMinute})
+	}
+	if ipKey != "" && limit.IPPerMinute > 0 {
+		checks = append(checks, check{l.refill(group+"|ip|"+ipKey, limit.IPPerMinute, limit.IPBurst, now), limit.IPPerMinute})
+	}
+
+	var wait time.Duration
+	for _, c := range checks {
+		if c.b.tokens < 1 {
+			d := time.Duration((1 - c.b.tokens) / c.rate * float64(time.Minute))
+			if d > wait {
+				wait = d
+			}
+		}
+	}
+	if wait > 0 {
+		return false, wait
+	}
+	for _, c := range checks {
+		c.b.tokens--
+	}
+	return true, 0
+}
+
+func (l *Limiter) refill(key string, perMinute float64, burst int, now time.Time) *bucket {
+	capacity := math.Max(float64(burst), 1)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+		return b
+	}
+	elapsed := now.Sub(b.last).Minutes()
+	if elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*perMinute)
+		b.last = now
+	}
+	return b
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func splitKey(key string) (group, kind string) {
+	parts := strings.SplitN(key, "|", 3)
+	if len(parts) < 2 {
+		return key, ""
+	}
+	return parts[0], parts[1]
+}
@@ -27,10 +27,10 @@ func (r *AIGeneratedQuestionRepository) FindBySessionID(sessionID string) ([]mod
 	return questions, err
 }
 
-// FindByUserAndSession ユーザーとセッションで質問を取得
+// FindByUserAndSession ユーザーとセッションで質問を取得（作成に使ったテンプレートも読み込む）
 func (r *AIGeneratedQuestionRepository) FindByUserAndSession(userID uint, sessionID string) ([]models.AIGeneratedQuestion, error) {
 	var questions []models.AIGeneratedQuestion
-	err := r.db.Where("user_id = ? AND session_id = ?", userID, sessionID).
+	err := r.db.Preload("Template").Where("user_id = ? AND session_id = ?", userID, sessionID).
 		Order("created_at ASC").
 		Find(&questions).Error
 	return questions, err
@@ -96,3 +96,92 @@ func (r *APICallLogRepository) TotalCostSince(since time.Time) (float64, error)
 		Scan(&total).Error
 	return total, err
 }
+
+type UserCostRow struct {
+	UserID       uint
+	TotalCostUSD float64
+	TotalTokens  int64
+	CallCount    int64
+}
+
+type FeatureCostRow struct {
+	Feature      string
+	TotalCostUSD float64
+	TotalTokens  int64
+	CallCount    int64
+}
+
+// UserBreakdown は利用者別コスト内訳をコストの高い順に返す（利用者に紐づかない呼び出しは除く）
+func (r *APICallLogRepository) UserBreakdown(since time.Time, limit int) ([]UserCostRow, error) {
+	var rows []UserCostRow
+	query := r.db.Raw(`
+		SELECT user_id,
+		       SUM(cost_usd) AS total_cost_usd,
+		       SUM(total_tokens) AS total_tokens,
+		       COUNT(*) AS call_count
+		FROM api_call_logs
+		WHERE called_at >= ? AND user_id <> 0
+		GROUP BY user_id
+		ORDER BY total_cost_usd DESC`, since)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Scan(&rows).Error
+	return rows, err
+}
+
+// FeatureBreakdown は機能別コスト内訳を返す（userID が 0 なら全利用者）
+func (r *APICallLogRepository) FeatureBreakdown(since time.Time, userID uint) ([]FeatureCostRow, error) {
+	var rows []FeatureCostRow
+	query := r.db.Table("api_call_logs").
+		Select("feature, SUM(cost_usd) AS total_cost_usd, SUM(total_tokens) AS total_tokens, COUNT(*) AS call_count").
+		Where("called_at >= ?", since)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Group("feature").Order("total_cost_usd DESC").Scan(&rows).Error
+	return rows, err
+}
+
+// UserCostSince は利用者の指定日時以降の合計コストを返す
+func (r *APICallLogRepository) UserCostSince(userID uint, since time.Time) (float64, error) {
+	var total float64
+	err := r.db.Model(&models.APICallLog{}).
+		Where("user_id = ? AND called_at >= ?", userID, since).
+		Select("COALESCE(SUM(cost_usd), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+type UnpricedModelRow struct {
+	Model     string
+	CallCount int64
+}
+
+// UnpricedModels は単価未登録のため料金を計上できなかったモデルを呼び出し回数の多い順に返す
+func (r *APICallLogRepository) UnpricedModels(since time.Time) ([]UnpricedModelRow, error) {
+	var rows []UnpricedModelRow
+	err := r.db.Table("api_call_logs").
+		Select("model, COUNT(*) AS call_count").
+		Where("called_at >= ? AND model_price_id IS NULL", since).
+		Group("model").Order("call_count DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ListForRepricing は [from, to) の呼び出しを afterID より後から ID 順に最大 limit 件返す（model が空でなければ前方一致で絞る）
+func (r *APICallLogRepository) ListForRepricing(from, to time.Time, model string, afterID uint, limit int) ([]models.APICallLog, error) {
+	var logs []models.APICallLog
+	query := r.db.Where("id > ? AND called_at >= ? AND called_at < ?", afterID, from, to)
+	if model != "" {
+		query = query.Where("model LIKE ?", model+"%")
+	}
+	err := query.Order("id asc").Limit(limit).Find(&logs).Error
+	return logs, err
+}
+
+// UpdateCost は呼び出しの料金と計算に使った単価を更新する
+func (r *APICallLogRepository) UpdateCost(id uint, costUSD float64, modelPriceID *uint) error {
+	return r.db.Model(&models.APICallLog{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"cost_usd": costUSD, "model_price_id": modelPriceID}).Error
+}
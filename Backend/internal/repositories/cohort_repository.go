@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CohortRepository struct {
+	db *gorm.DB
+}
+
+func NewCohortRepository(db *gorm.DB) *CohortRepository {
+	return &CohortRepository{db: db}
+}
+
+func (r *CohortRepository) Create(cohort *models.Cohort) error {
+	return r.db.Create(cohort).Error
+}
+
+func (r *CohortRepository) FindByID(id uint) (*models.Cohort, error) {
+	var cohort models.Cohort
+	if err := r.db.First(&cohort, id).Error; err != nil {
+		return nil, err
+	}
+	return &cohort, nil
+}
+
+func (r *CohortRepository) ListAll() ([]models.Cohort, error) {
+	var cohorts []models.Cohort
+	if err := r.db.Order("school_name asc, name asc").Find(&cohorts).Error; err != nil {
+		return nil, err
+	}
+	return cohorts, nil
+}
+
+func (r *CohortRepository) ListByMember(userID uint, role string) ([]models.Cohort, error) {
+	var cohorts []models.Cohort
+	err := r.db.
+		Joins("JOIN cohort_members ON cohort_members.cohort_id = cohorts.id").
+		Where("cohort_members.user_id = ? AND cohort_members.role = ?", userID, role).
+		Order("cohorts.school_name asc, cohorts.name asc").
+		Find(&cohorts).Error
+	if err != nil {
+		return nil, err
+	}
+	return cohorts, nil
+}
+
+func (r *CohortRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cohort_id = ?", id).Delete(&models.CohortMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("cohort_id = ?", id).Delete(&models.CohortInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Cohort{}, id).Error
+	})
+}
+
+// AddMember 既に所属している場合はロールを更新する
+func (r *CohortRepository) AddMember(member *models.CohortMember) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cohort_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(member).Error
+}
+
+func (r *CohortRepository) RemoveMember(cohortID, userID uint) error {
+	return r.db.Where("cohort_id = ? AND user_id = ?", cohortID, userID).Delete(&models.CohortMember{}).Error
+}
+
+func (r *CohortRepository) FindMember(cohortID, userID uint) (*models.CohortMember, error) {
+	var member models.CohortMember
+	err := r.db.Where("cohort_id = ? AND user_id = ?", cohortID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *CohortRepository) ListMembers(cohortID uint, role string) ([]models.CohortMember, error) {
+	var members []models.CohortMember
+	if err := r.db.Where("cohort_id = ? AND role = ?", cohortID, role).Order("created_at asc").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// ApproveMember 学生本人の承認日時を記録する（承認済みなら何もしない）
+func (r *CohortRepository) ApproveMember(cohortID, userID uint) error {
+	return r.db.Model(&models.CohortMember{}).
+		Where("cohort_id = ? AND user_id = ? AND approved_at IS NULL", cohortID, userID).
+		Update("approved_at", time.Now()).Error
+}
+
+func (r *CohortRepository) SharesCohort(teacherID, studentID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("cohort_members AS t").
+		Joins("JOIN cohort_members AS s ON s.cohort_id = t.cohort_id").
+		Where("t.user_id = ? AND t.role = ? AND s.user_id = ? AND s.role = ? AND s.approved_at IS NOT NULL",
+			teacherID, models.CohortRoleTeacher, studentID, models.CohortRoleStudent).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *CohortRepository) ListTeachersOfStudent(studentID uint) ([]models.CohortTeacher, error) {
+	var teachers []models.CohortTeacher
+	err := r.db.Raw(`
+		SELECT
+			c.id AS cohort_id,
+			c.name AS cohort_name,
+			c.school_name AS school_name,
+			u.id AS teacher_id,
+			u.name AS name,
+			u.email AS email
+		FROM cohort_members s
+		JOIN cohorts c ON c.id = s.cohort_id
+		JOIN cohort_members t ON t.cohort_id = s.cohort_id AND t.role = ?
+		JOIN users u ON u.id = t.user_id
+		WHERE s.user_id = ? AND s.role = ? AND s.approved_at IS NOT NULL
+		ORDER BY c.name ASC, u.name ASC
+	`, models.CohortRoleTeacher, studentID, models.CohortRoleStudent).Scan(&teachers).Error
+	return teachers, err
+}
+
+func (r *CohortRepository) ListCohortsOfStudent(studentID uint) ([]models.StudentCohort, error) {
+	var cohorts []models.StudentCohort
+	err := r.db.Raw(`
+		SELECT
+			c.id AS cohort_id,
+			c.name AS cohort_name,
+			c.school_name AS school_name,
+			s.approved_at AS approved_at,
+			s.created_at AS added_at
+		FROM cohort_members s
+		JOIN cohorts c ON c.id = s.cohort_id
+		WHERE s.user_id = ? AND s.role = ?
+		ORDER BY s.created_at DESC
+	`, studentID, models.CohortRoleStudent).Scan(&cohorts).Error
+	return cohorts, err
+}
+
+func (r *CohortRepository) CreateInvitation(inv *models.CohortInvitation) error {
+	return r.db.Create(inv).Error
+}
+
+func (r *CohortRepository) FindInvitationByToken(token string) (*models.CohortInvitation, error) {
+	var inv models.CohortInvitation
+	if err := r.db.Where("token = ?", token).First(&inv).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *CohortRepository) UpdateInvitation(inv *models.CohortInvitation) error {
+	return r.db.Save(inv).Error
+}
+
+func (r *CohortRepository) ListInvitations(cohortID uint) ([]models.CohortInvitation, error) {
+	var invs []models.CohortInvitation
+	if err := r.db.Where("cohort_id = ?", cohortID).Order("created_at desc").Find(&invs).Error; err != nil {
+		return nil, err
+	}
+	return invs, nil
+}
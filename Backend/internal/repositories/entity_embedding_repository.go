@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EntityEmbeddingRepository struct {
+	db *gorm.DB
+}
+
+func NewEntityEmbeddingRepository(db *gorm.DB) *EntityEmbeddingRepository {
+	return &EntityEmbeddingRepository{db: db}
+}
+
+func (r *EntityEmbeddingRepository) Find(kind string, entityID uint) (*models.EntityEmbedding, error) {
+	var embedding models.EntityEmbedding
+	err := r.db.Where("kind = ? AND entity_id = ?", kind, entityID).First(&embedding).Error
+	if err != nil {
+		return nil, err
+	}
+	return &embedding, nil
+}
+
+func (r *EntityEmbeddingRepository) Upsert(embedding *models.EntityEmbedding) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "entity_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source_text", "vector", "dimensions", "updated_at"}),
+	}).Create(embedding).Error
+}
+
+func (r *EntityEmbeddingRepository) ListByKind(kind string, afterID uint, limit int) ([]models.EntityEmbedding, error) {
+	var embeddings []models.EntityEmbedding
+	err := r.db.Where("kind = ? AND id > ?", kind, afterID).
+		Order("id asc").
+		Limit(limit).
+		Find(&embeddings).Error
+	return embeddings, err
+}
+
+func (r *EntityEmbeddingRepository) Delete(kind string, entityID uint) error {
+	return r.db.Where("kind = ? AND entity_id = ?", kind, entityID).Delete(&models.EntityEmbedding{}).Error
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type FlaggedInputRepository struct {
+	db *gorm.DB
+}
+
+func NewFlaggedInputRepository(db *gorm.DB) *FlaggedInputRepository {
+	return &FlaggedInputRepository{db: db}
+}
+
+func (r *FlaggedInputRepository) Create(input *models.FlaggedInput) error {
+	return r.db.Create(input).Error
+}
+
+// List 直近の検出履歴（feature が空なら全機能）
+func (r *FlaggedInputRepository) List(feature string, limit int) ([]models.FlaggedInput, error) {
+	var inputs []models.FlaggedInput
+	q := r.db.Order("created_at desc, id desc").Limit(limit)
+	if feature != "" {
+		q = q.Where("feature = ?", feature)
+	}
+	err := q.Find(&inputs).Error
+	return inputs, err
+}
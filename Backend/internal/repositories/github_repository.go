@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"Backend/internal/models"
+	"Backend/internal/secrets"
 	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,22 +13,38 @@ import (
 
 // GitHubRepository GitHub連携データのDB操作
 type GitHubRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	envelope *secrets.Envelope
 }
 
 func NewGitHubRepository(db *gorm.DB) *GitHubRepository {
 	return &GitHubRepository{db: db}
 }
 
-// UpsertProfile GitHubプロフィールを保存/更新
+// SetEnvelope アクセストークンの保存時暗号化を有効にする（未設定時は平文で保存）
+func (r *GitHubRepository) SetEnvelope(envelope *secrets.Envelope) {
+	r.envelope = envelope
+}
+
+// UpsertProfile GitHubプロフィールを保存/更新（アクセストークンは暗号化して保存）
 func (r *GitHubRepository) UpsertProfile(profile *models.GitHubProfile) error {
+	plaintext := profile.AccessToken
+	if r.envelope != nil {
+		encrypted, err := r.envelope.Encrypt(plaintext)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt access token: %w", err)
+		}
+		profile.AccessToken = encrypted
+		// 呼び出し元には平文を返す
+		defer func() { profile.AccessToken = plaintext }()
+	}
 	return r.db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "user_id"}},
 		DoUpdates: clause.AssignmentColumns([]string{"git_hub_login", "access_token", "total_contributions", "public_repos", "followers", "following", "synced_at", "updated_at"}),
 	}).Create(profile).Error
 }
 
-// GetProfile ユーザーIDでGitHubプロフィールを取得
+// GetProfile ユーザーIDでGitHubプロフィールを取得（アクセストークンは復号済み）
 func (r *GitHubRepository) GetProfile(userID uint) (*models.GitHubProfile, error) {
 	var profile models.GitHubProfile
 	if err := r.db.Where("user_id = ?", userID).First(&profile).Error; err != nil {
@@ -35,9 +53,25 @@ func (r *GitHubRepository) GetProfile(userID uint) (*models.GitHubProfile, error
 		}
 		return nil, err
 	}
+	if r.envelope != nil {
+		token, err := r.envelope.Decrypt(profile.AccessToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt access token: %w", err)
+		}
+		profile.AccessToken = token
+	}
 	return &profile, nil
 }
 
+// ReencryptAccessTokens 平文または旧鍵で暗号化されたアクセストークンを現行鍵で暗号化し直す。
+// 更新件数を返す。dryRun の場合は件数の集計のみ行う。
+func (r *GitHubRepository) ReencryptAccessTokens(batchSize int, dryRun bool) (int, error) {
+	if r.envelope == nil {
+		return 0, errors.New("encryption is not configured")
+	}
+	return reencryptColumn(r.db, r.envelope, &models.GitHubProfile{}, "access_token", batchSize, dryRun)
+}
+
 // ReplaceRepositories ユーザーのリポジトリ一覧を全件置換
 func (r *GitHubRepository) ReplaceRepositories(userID uint, repos []models.GitHubRepo) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
@@ -89,6 +123,13 @@ func (r *GitHubRepository) UpdateSyncedAt(userID uint, t time.Time) error {
 		Update("synced_at", t).Error
 }
 
+// ClearAccessToken 連携解除時にアクセストークンを削除する（同期済みデータは残す）
+func (r *GitHubRepository) ClearAccessToken(userID uint) error {
+	return r.db.Model(&models.GitHubProfile{}).
+		Where("user_id = ?", userID).
+		Update("access_token", "").Error
+}
+
 // GetRepoSummary ユーザーID+リポジトリ名でAI要約を取得
 func (r *GitHubRepository) GetRepoSummary(userID uint, fullName string) (*models.GitHubRepoSummary, error) {
 	var s models.GitHubRepoSummary
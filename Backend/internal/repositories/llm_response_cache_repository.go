@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LLMResponseCacheRepository struct {
+	db *gorm.DB
+}
+
+func NewLLMResponseCacheRepository(db *gorm.DB) *LLMResponseCacheRepository {
+	return &LLMResponseCacheRepository{db: db}
+}
+
+func (r *LLMResponseCacheRepository) FindByKey(key string) (*models.LLMResponseCache, error) {
+	var entry models.LLMResponseCache
+	if err := r.db.Where("cache_key = ?", key).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *LLMResponseCacheRepository) Upsert(entry *models.LLMResponseCache) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scope", "value", "expires_at", "updated_at"}),
+	}).Create(entry).Error
+}
+
+// DeleteByScope は scope のエントリを削除する（空なら全件）
+func (r *LLMResponseCacheRepository) DeleteByScope(scope string) (int64, error) {
+	q := r.db.Session(&gorm.Session{AllowGlobalUpdate: true})
+	if scope != "" {
+		q = q.Where("scope = ?", scope)
+	}
+	res := q.Delete(&models.LLMResponseCache{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *LLMResponseCacheRepository) DeleteExpired(now time.Time) (int64, error) {
+	res := r.db.Where("expires_at <= ?", now).Delete(&models.LLMResponseCache{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LLMUserBudgetRepository struct {
+	db *gorm.DB
+}
+
+func NewLLMUserBudgetRepository(db *gorm.DB) *LLMUserBudgetRepository {
+	return &LLMUserBudgetRepository{db: db}
+}
+
+func (r *LLMUserBudgetRepository) FindByUser(userID uint) (*models.LLMUserBudget, error) {
+	var budget models.LLMUserBudget
+	if err := r.db.Where("user_id = ?", userID).First(&budget).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *LLMUserBudgetRepository) List() ([]models.LLMUserBudget, error) {
+	var budgets []models.LLMUserBudget
+	err := r.db.Order("user_id asc").Find(&budgets).Error
+	return budgets, err
+}
+
+func (r *LLMUserBudgetRepository) Upsert(budget *models.LLMUserBudget) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"daily_limit_usd", "monthly_limit_usd", "action", "updated_by", "updated_at"}),
+	}).Create(budget).Error
+}
+
+func (r *LLMUserBudgetRepository) Delete(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.LLMUserBudget{}).Error
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) Create(attempt *models.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+// CountFailuresByIP since 以降の IP アドレスからのログイン失敗回数
+func (r *LoginAttemptRepository) CountFailuresByIP(ip string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.LoginAttempt{}).
+		Where("ip_address = ? AND success = ? AND created_at >= ?", ip, false, since).
+		Count(&count).Error
+	return count, err
+}
+
+// ListByUser ユーザーの直近のログイン試行
+func (r *LoginAttemptRepository) ListByUser(userID uint, limit int) ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Limit(limit).Find(&attempts).Error
+	return attempts, err
+}
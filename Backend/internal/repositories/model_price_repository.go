@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ModelPriceRepository struct {
+	db *gorm.DB
+}
+
+func NewModelPriceRepository(db *gorm.DB) *ModelPriceRepository {
+	return &ModelPriceRepository{db: db}
+}
+
+func (r *ModelPriceRepository) List() ([]models.ModelPrice, error) {
+	var prices []models.ModelPrice
+	err := r.db.Order("model asc, effective_from asc").Find(&prices).Error
+	return prices, err
+}
+
+func (r *ModelPriceRepository) FindByID(id uint) (*models.ModelPrice, error) {
+	var price models.ModelPrice
+	if err := r.db.First(&price, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &price, nil
+}
+
+func (r *ModelPriceRepository) Upsert(price *models.ModelPrice) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "model"}, {Name: "effective_from"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"input_per_m_tok_usd", "cached_input_per_m_tok_usd", "output_per_m_tok_usd", "per_minute_usd", "note", "updated_by", "updated_at",
+		}),
+	}).Create(price).Error
+}
+
+func (r *ModelPriceRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ModelPrice{}, id).Error
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type PersonalDataJobRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalDataJobRepository(db *gorm.DB) *PersonalDataJobRepository {
+	return &PersonalDataJobRepository{db: db}
+}
+
+func (r *PersonalDataJobRepository) Create(job *models.PersonalDataJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *PersonalDataJobRepository) Update(job *models.PersonalDataJob) error {
+	return r.db.Save(job).Error
+}
+
+// FindByID ジョブを取得（存在しない場合は nil）
+func (r *PersonalDataJobRepository) FindByID(id uint) (*models.PersonalDataJob, error) {
+	var job models.PersonalDataJob
+	if err := r.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindByStatusToken 状況確認トークンでジョブを取得（存在しない場合は nil）
+func (r *PersonalDataJobRepository) FindByStatusToken(token string) (*models.PersonalDataJob, error) {
+	var job models.PersonalDataJob
+	if err := r.db.Where("status_token = ?", token).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListByUser ユーザーのジョブ一覧（新しい順）。kind が空なら全種別
+func (r *PersonalDataJobRepository) ListByUser(userID uint, kind string) ([]models.PersonalDataJob, error) {
+	var jobs []models.PersonalDataJob
+	q := r.db.Where("user_id = ?", userID)
+	if kind != "" {
+		q = q.Where("kind = ?", kind)
+	}
+	err := q.Order("created_at desc").Find(&jobs).Error
+	return jobs, err
+}
+
+// ListUnfinished 未完了のジョブ（サーバー再起動時の再開用）
+func (r *PersonalDataJobRepository) ListUnfinished() ([]models.PersonalDataJob, error) {
+	var jobs []models.PersonalDataJob
+	err := r.db.Where("status IN ?", []string{"pending", "running"}).Order("id asc").Find(&jobs).Error
+	return jobs, err
+}
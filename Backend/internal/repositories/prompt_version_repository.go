@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PromptVersionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromptVersionRepository(db *gorm.DB) *PromptVersionRepository {
+	return &PromptVersionRepository{db: db}
+}
+
+// ListActive は公開中の版をプロンプトごとに返す
+func (r *PromptVersionRepository) ListActive() ([]models.PromptVersion, error) {
+	var versions []models.PromptVersion
+	err := r.db.Where("is_active = ?", true).Order("name asc").Find(&versions).Error
+	return versions, err
+}
+
+// ListByName はプロンプトの版を新しい順に返す
+func (r *PromptVersionRepository) ListByName(name string) ([]models.PromptVersion, error) {
+	var versions []models.PromptVersion
+	err := r.db.Where("name = ?", name).Order("version desc").Find(&versions).Error
+	return versions, err
+}
+
+func (r *PromptVersionRepository) FindVersion(name string, version int) (*models.PromptVersion, error) {
+	var v models.PromptVersion
+	if err := r.db.Where("name = ? AND version = ?", name, version).First(&v).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+// CreateNextVersion は同じプロンプトの最新の版の次の番号を v.Version に設定して登録する
+func (r *PromptVersionRepository) CreateNextVersion(v *models.PromptVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.PromptVersion{}).Where("name = ?", v.Name).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		v.Version = latest + 1
+		return tx.Create(v).Error
+	})
+}
+
+// Activate はプロンプトの公開中の版を version に切り替える（0 なら公開中の版をなくし、ファイルの既定版に戻す）
+func (r *PromptVersionRepository) Activate(name string, version int, publishedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PromptVersion{}).Where("name = ? AND is_active = ?", name, true).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+		if version == 0 {
+			return nil
+		}
+		res := tx.Model(&models.PromptVersion{}).Where("name = ? AND version = ?", name, version).
+			Updates(map[string]interface{}{"is_active": true, "published_at": publishedAt})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// RecordPublication は公開履歴を追記する
+func (r *PromptVersionRepository) RecordPublication(p *models.PromptPublication) error {
+	return r.db.Create(p).Error
+}
+
+// ListPublications は取り消されていない公開履歴を新しい順に返す
+func (r *PromptVersionRepository) ListPublications(name string) ([]models.PromptPublication, error) {
+	var pubs []models.PromptPublication
+	err := r.db.Where("name = ? AND reverted_at IS NULL", name).Order("id desc").Find(&pubs).Error
+	return pubs, err
+}
+
+// RevertPublication は公開履歴をロールバックで取り消したものとして記録する
+func (r *PromptVersionRepository) RevertPublication(id uint, revertedAt time.Time) error {
+	return r.db.Model(&models.PromptPublication{}).Where("id = ? AND reverted_at IS NULL", id).
+		Update("reverted_at", revertedAt).Error
+}
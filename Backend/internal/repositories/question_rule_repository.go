@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// QuestionRuleRepository 回答の加点ルール（WeightRule）と AI 質問テンプレート（AIQuestionTemplate）の永続化
+type QuestionRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewQuestionRuleRepository(db *gorm.DB) *QuestionRuleRepository {
+	return &QuestionRuleRepository{db: db}
+}
+
+// ── 加点ルール ───────────────────────────────────────────────────────────────
+
+// ListWeightRules は加点ルールを優先度の高い順に返す（activeOnly なら有効なルールのみ）
+func (r *QuestionRuleRepository) ListWeightRules(activeOnly bool) ([]models.WeightRule, error) {
+	var rules []models.WeightRule
+	q := r.db.Order("priority desc, id asc")
+	if activeOnly {
+		q = q.Where("is_active = ?", true)
+	}
+	err := q.Find(&rules).Error
+	return rules, err
+}
+
+func (r *QuestionRuleRepository) FindWeightRule(id uint) (*models.WeightRule, error) {
+	var rule models.WeightRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateWeightRule はルールを登録する（IsActive が false でも列の既定値で有効にならないようにする）
+func (r *QuestionRuleRepository) CreateWeightRule(rule *models.WeightRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rule).Error; err != nil {
+			return err
+		}
+		if rule.IsActive {
+			return nil
+		}
+		return tx.Model(rule).Update("is_active", false).Error
+	})
+}
+
+func (r *QuestionRuleRepository) SaveWeightRule(rule *models.WeightRule) error {
+	return r.db.Save(rule).Error
+}
+
+// DeleteWeightRule はルールを削除する（なければ gorm.ErrRecordNotFound）
+func (r *QuestionRuleRepository) DeleteWeightRule(id uint) error {
+	result := r.db.Delete(&models.WeightRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ── AI 質問テンプレート ───────────────────────────────────────────────────────
+
+// ListQuestionTemplates はテンプレートをカテゴリ・基本重みの高い順に返す（category が空なら全カテゴリ、activeOnly なら有効なもののみ）
+func (r *QuestionRuleRepository) ListQuestionTemplates(category string, activeOnly bool) ([]models.AIQuestionTemplate, error) {
+	var templates []models.AIQuestionTemplate
+	q := r.db.Order("category asc, base_weight desc, id asc")
+	if category != "" {
+		q = q.Where("category = ?", category)
+	}
+	if activeOnly {
+		q = q.Where("is_active = ?", true)
+	}
+	err := q.Find(&templates).Error
+	return templates, err
+}
+
+func (r *QuestionRuleRepository) FindQuestionTemplate(id uint) (*models.AIQuestionTemplate, error) {
+	var template models.AIQuestionTemplate
+	if err := r.db.First(&template, id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreateQuestionTemplate はテンプレートを登録する（IsActive が false でも列の既定値で有効にならないようにする）
+func (r *QuestionRuleRepository) CreateQuestionTemplate(template *models.AIQuestionTemplate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(template).Error; err != nil {
+			return err
+		}
+		if template.IsActive {
+			return nil
+		}
+		return tx.Model(template).Update("is_active", false).Error
+	})
+}
+
+func (r *QuestionRuleRepository) SaveQuestionTemplate(template *models.AIQuestionTemplate) error {
+	return r.db.Save(template).Error
+}
+
+// CountTemplateQuestions はテンプレートから作った AI 生成質問の数を返す
+func (r *QuestionRuleRepository) CountTemplateQuestions(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AIGeneratedQuestion{}).Where("template_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// DeleteQuestionTemplate はテンプレートを削除する（なければ gorm.ErrRecordNotFound）
+func (r *QuestionRuleRepository) DeleteQuestionTemplate(id uint) error {
+	result := r.db.Delete(&models.AIQuestionTemplate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RateLimitSettingRepository struct {
+	db *gorm.DB
+}
+
+func NewRateLimitSettingRepository(db *gorm.DB) *RateLimitSettingRepository {
+	return &RateLimitSettingRepository{db: db}
+}
+
+func (r *RateLimitSettingRepository) List() ([]models.RateLimitSetting, error) {
+	var settings []models.RateLimitSetting
+	err := r.db.Order("group_name asc").Find(&settings).Error
+	return settings, err
+}
+
+func (r *RateLimitSettingRepository) Upsert(setting *models.RateLimitSetting) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "user_per_minute", "user_burst", "ip_per_minute", "ip_burst", "updated_by", "updated_at"}),
+	}).Create(setting).Error
+}
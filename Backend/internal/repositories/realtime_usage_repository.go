@@ -153,3 +153,16 @@ func (r *RealtimeUsageRepository) CurrentMonthTotalCostEstimated(currentRatePerM
 	`, currentRatePerMinute, since).Scan(&total).Error
 	return total, err
 }
+
+// ListFinishedForRepricing は [from, to) に開始した終了済みのセッションを afterID より後から ID 順に最大 limit 件返す
+func (r *RealtimeUsageRepository) ListFinishedForRepricing(from, to time.Time, afterID uint, limit int) ([]models.RealtimeUsageLog, error) {
+	var logs []models.RealtimeUsageLog
+	err := r.db.Where("id > ? AND started_at >= ? AND started_at < ? AND ended_at IS NOT NULL", afterID, from, to).
+		Order("id asc").Limit(limit).Find(&logs).Error
+	return logs, err
+}
+
+// UpdateCost はセッションの料金を更新する
+func (r *RealtimeUsageRepository) UpdateCost(id uint, costUSD float64) error {
+	return r.db.Model(&models.RealtimeUsageLog{}).Where("id = ?", id).Update("cost_usd", costUSD).Error
+}
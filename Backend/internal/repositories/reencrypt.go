@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"Backend/internal/secrets"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// encryptedColumnRow 再暗号化対象の行（主キーと暗号化済みの値）
+type encryptedColumnRow struct {
+	ID    uint
+	Value string
+}
+
+// reencryptColumn model のテーブルの column を id 順に走査し、平文または旧鍵で暗号化された値を現行鍵で暗号化し直す。
+// 読み込み後に値が変わった行は上書きしない。更新件数を返す。dryRun の場合は件数の集計のみ行う
+func reencryptColumn(db *gorm.DB, envelope *secrets.Envelope, model interface{}, column string, batchSize int, dryRun bool) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	updated := 0
+	var lastID uint
+	for {
+		var rows []encryptedColumnRow
+		if err := db.Model(model).
+			Select("id", column+" AS value").
+			Where("id > ? AND "+column+" <> ''", lastID).
+			Order("id asc").
+			Limit(batchSize).
+			Scan(&rows).Error; err != nil {
+			return updated, err
+		}
+		if len(rows) == 0 {
+			return updated, nil
+		}
+		for _, row := range rows {
+			lastID = row.ID
+			if !envelope.NeedsReencrypt(row.Value) {
+				continue
+			}
+			plain, err := envelope.Decrypt(row.Value)
+			if err != nil {
+				return updated, fmt.Errorf("id %d: %w", row.ID, err)
+			}
+			encrypted, err := envelope.Encrypt(plain)
+			if err != nil {
+				return updated, fmt.Errorf("id %d: %w", row.ID, err)
+			}
+			if !dryRun {
+				if err := db.Model(model).
+					Where("id = ? AND "+column+" = ?", row.ID, row.Value).
+					Update(column, encrypted).Error; err != nil {
+					return updated, fmt.Errorf("id %d: %w", row.ID, err)
+				}
+			}
+			updated++
+		}
+	}
+}
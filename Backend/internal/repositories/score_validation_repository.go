@@ -2,11 +2,13 @@ package repositories
 
 import (
 	"Backend/internal/models"
+	"errors"
 	"fmt"
 	"math"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ScoreValidationRepository struct {
@@ -131,6 +133,16 @@ func (r *ScoreValidationRepository) ListActiveVariants(experimentName string) ([
 	return variants, err
 }
 
+// ListActiveExperiments アクティブなバリアントがある実験名の一覧
+func (r *ScoreValidationRepository) ListActiveExperiments() ([]string, error) {
+	var names []string
+	err := r.db.Model(&models.QuestionVariant{}).
+		Where("is_active = ?", true).
+		Distinct("experiment_name").
+		Pluck("experiment_name", &names).Error
+	return names, err
+}
+
 func (r *ScoreValidationRepository) ListAllExperiments() ([]string, error) {
 	var names []string
 	err := r.db.Model(&models.QuestionVariant{}).
@@ -139,8 +151,13 @@ func (r *ScoreValidationRepository) ListAllExperiments() ([]string, error) {
 	return names, err
 }
 
-func (r *ScoreValidationRepository) AssignVariant(a *models.VariantAssignment) error {
-	return r.db.Create(a).Error
+// AssignVariant 割り当てを保存する。同じセッション・実験の割り当てが既にあれば保存せず false を返す
+func (r *ScoreValidationRepository) AssignVariant(a *models.VariantAssignment) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(a)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
 }
 
 func (r *ScoreValidationRepository) FindAssignment(userID uint, sessionID string) (*models.VariantAssignment, error) {
@@ -153,32 +170,68 @@ func (r *ScoreValidationRepository) FindAssignment(userID uint, sessionID string
 	return &a, nil
 }
 
+// FindExperimentAssignment セッションの実験ごとの割り当て
+func (r *ScoreValidationRepository) FindExperimentAssignment(userID uint, sessionID, experimentName string) (*models.VariantAssignment, error) {
+	var a models.VariantAssignment
+	err := r.db.Where("user_id = ? AND session_id = ? AND experiment_name = ?", userID, sessionID, experimentName).
+		Preload("Variant").First(&a).Error
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListAssignments セッションのすべての割り当て（割り当て順）
+func (r *ScoreValidationRepository) ListAssignments(userID uint, sessionID string) ([]models.VariantAssignment, error) {
+	var assignments []models.VariantAssignment
+	err := r.db.Where("user_id = ? AND session_id = ?", userID, sessionID).
+		Preload("Variant").Order("id").Find(&assignments).Error
+	return assignments, err
+}
+
 // VariantResultRow A/Bテスト結果比較行
 type VariantResultRow struct {
 	ExperimentName  string
 	VariantName     string
 	SessionCount    int
-	PassCount       int
-	PassRate        float64
-	AvgScoreSum     float64
+	AppliedCount    int     // 応募まで進んだセッション数
+	PassCount       int     // 応募のいずれかが通過したセッション数
+	PassRate        float64 // PassCount / AppliedCount（%）
+	AvgScoreSum     float64 // セッションのカテゴリスコア合計の平均
 }
 
 // GetVariantResults 実験バリアント別の通過率を集計する
+// 応募（user_application_statuses.session_id）とスコア（user_weight_scores.session_id）は割り当てたセッションで結び付ける
 func (r *ScoreValidationRepository) GetVariantResults(experimentName string) ([]VariantResultRow, error) {
 	rows := []VariantResultRow{}
 	err := r.db.Raw(`
 		SELECT
 			va.experiment_name,
 			va.assigned_variant AS variant_name,
-			COUNT(DISTINCT va.session_id) AS session_count,
-			COUNT(DISTINCT CASE WHEN uas.status IN ('document_passed','interview','offered','accepted') THEN uas.user_id END) AS pass_count,
+			COUNT(*) AS session_count,
+			SUM(CASE WHEN app.applied > 0 THEN 1 ELSE 0 END) AS applied_count,
+			SUM(CASE WHEN app.passed > 0 THEN 1 ELSE 0 END) AS pass_count,
 			CASE
-				WHEN COUNT(DISTINCT va.user_id) = 0 THEN 0
-				ELSE CAST(COUNT(DISTINCT CASE WHEN uas.status IN ('document_passed','interview','offered','accepted') THEN uas.user_id END) AS FLOAT) /
-					COUNT(DISTINCT va.user_id) * 100
-			END AS pass_rate
+				WHEN SUM(CASE WHEN app.applied > 0 THEN 1 ELSE 0 END) = 0 THEN 0
+				ELSE CAST(SUM(CASE WHEN app.passed > 0 THEN 1 ELSE 0 END) AS FLOAT) /
+					SUM(CASE WHEN app.applied > 0 THEN 1 ELSE 0 END) * 100
+			END AS pass_rate,
+			AVG(COALESCE(uws.score_sum, 0)) AS avg_score_sum
 		FROM variant_assignments va
-		LEFT JOIN user_application_statuses uas ON uas.user_id = va.user_id
+		LEFT JOIN (
+			SELECT
+				session_id,
+				COUNT(*) AS applied,
+				SUM(CASE WHEN status IN ('document_passed','interview','offered','accepted') THEN 1 ELSE 0 END) AS passed
+			FROM user_application_statuses
+			WHERE session_id != ''
+			GROUP BY session_id
+		) app ON app.session_id = va.session_id
+		LEFT JOIN (
+			SELECT session_id, SUM(score) AS score_sum
+			FROM user_weight_scores
+			GROUP BY session_id
+		) uws ON uws.session_id = va.session_id
 		WHERE va.experiment_name = ?
 		GROUP BY va.experiment_name, va.assigned_variant
 	`, experimentName).Scan(&rows).Error
@@ -193,16 +246,105 @@ func (r *ScoreValidationRepository) GetLatestCalibrationWeights() ([]models.Scor
 	return weights, err
 }
 
+// SaveCalibrationWeights 新しい版の重みを保存する（有効化は ActivateCalibrationVersion で行う）
 func (r *ScoreValidationRepository) SaveCalibrationWeights(weights []models.ScoreCalibrationWeight) error {
-	// 現行アクティブ版を非アクティブ化
-	if err := r.db.Model(&models.ScoreCalibrationWeight{}).
-		Where("is_active = ?", true).
-		Update("is_active", false).Error; err != nil {
-		return err
-	}
 	return r.db.Create(&weights).Error
 }
 
+// GetCalibrationWeights 指定した版の重み
+func (r *ScoreValidationRepository) GetCalibrationWeights(version int) ([]models.ScoreCalibrationWeight, error) {
+	var weights []models.ScoreCalibrationWeight
+	err := r.db.Where("version = ?", version).Find(&weights).Error
+	return weights, err
+}
+
+// ActivateCalibrationVersion 指定した版だけを有効にする（version が 0 ならすべて無効にする）
+func (r *ScoreValidationRepository) ActivateCalibrationVersion(version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ScoreCalibrationWeight{}).
+			Where("is_active = ?", true).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+		if version == 0 {
+			return nil
+		}
+		res := tx.Model(&models.ScoreCalibrationWeight{}).
+			Where("version = ?", version).
+			Update("is_active", true)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// GetCalibrationSetting キャリブレーションの運用設定（未保存なら既定値）
+func (r *ScoreValidationRepository) GetCalibrationSetting() (*models.ScoreCalibrationSetting, error) {
+	var setting models.ScoreCalibrationSetting
+	err := r.db.Order("id").First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.ScoreCalibrationSetting{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// SaveCalibrationSetting キャリブレーションの運用設定を保存する
+func (r *ScoreValidationRepository) SaveCalibrationSetting(setting *models.ScoreCalibrationSetting) error {
+	if setting.ID == 0 {
+		setting.ID = 1
+	}
+	return r.db.Save(setting).Error
+}
+
+// CalibrationShadowRow シャドーモードで計算したマッチ度と有効な版のマッチ度の比較行
+type CalibrationShadowRow struct {
+	CalibrationVersion       int
+	ShadowCalibrationVersion int
+	MatchCount               int
+	SessionCount             int
+	AvgScore                 float64
+	AvgShadowScore           float64
+	AvgAbsDiff               float64 // |MatchScore - ShadowMatchScore| の平均
+	AppliedCount             int
+	PassedAvgScore           float64 // 選考を通過した応募のマッチ度の平均
+	PassedAvgShadowScore     float64
+	RejectedAvgScore         float64 // 不合格だった応募のマッチ度の平均
+	RejectedAvgShadowScore   float64
+}
+
+// GetCalibrationShadowReport シャドー計算したマッチングを版の組み合わせごとに集計する
+func (r *ScoreValidationRepository) GetCalibrationShadowReport() ([]CalibrationShadowRow, error) {
+	rows := []CalibrationShadowRow{}
+	err := r.db.Raw(`
+		SELECT
+			ucm.calibration_version,
+			ucm.shadow_calibration_version,
+			COUNT(*) AS match_count,
+			COUNT(DISTINCT ucm.session_id) AS session_count,
+			AVG(ucm.match_score) AS avg_score,
+			AVG(ucm.shadow_match_score) AS avg_shadow_score,
+			AVG(ABS(ucm.match_score - ucm.shadow_match_score)) AS avg_abs_diff,
+			COUNT(uas.id) AS applied_count,
+			COALESCE(AVG(CASE WHEN uas.status IN ('document_passed','interview','offered','accepted') THEN ucm.match_score END), 0) AS passed_avg_score,
+			COALESCE(AVG(CASE WHEN uas.status IN ('document_passed','interview','offered','accepted') THEN ucm.shadow_match_score END), 0) AS passed_avg_shadow_score,
+			COALESCE(AVG(CASE WHEN uas.status = 'rejected' THEN ucm.match_score END), 0) AS rejected_avg_score,
+			COALESCE(AVG(CASE WHEN uas.status = 'rejected' THEN ucm.shadow_match_score END), 0) AS rejected_avg_shadow_score
+		FROM user_company_matches ucm
+		LEFT JOIN user_application_statuses uas ON uas.match_id = ucm.id
+		WHERE ucm.shadow_match_score IS NOT NULL
+		GROUP BY ucm.calibration_version, ucm.shadow_calibration_version
+		ORDER BY ucm.shadow_calibration_version DESC, ucm.calibration_version DESC
+	`).Scan(&rows).Error
+	return rows, err
+}
+
 // CategoryPassStats キャリブレーション計算用の生データ
 type CategoryPassStats struct {
 	Category  string
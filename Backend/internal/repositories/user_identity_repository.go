@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"Backend/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: db}
+}
+
+func (r *UserIdentityRepository) Create(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *UserIdentityRepository) Update(identity *models.UserIdentity) error {
+	return r.db.Save(identity).Error
+}
+
+// FindByProvider プロバイダ側のユーザーIDで連携を取得（存在しない場合は nil）
+func (r *UserIdentityRepository) FindByProvider(provider, providerUserID string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.Where("provider = ? AND provider_user_id = ?", provider, providerUserID).First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListByUser ユーザーの連携一覧（連携日時順）
+func (r *UserIdentityRepository) ListByUser(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at asc").Find(&identities).Error
+	return identities, err
+}
+
+func (r *UserIdentityRepository) Delete(id uint) error {
+	return r.db.Delete(&models.UserIdentity{}, id).Error
+}
@@ -4,7 +4,9 @@ import (
 	"Backend/domain/entity"
 	"Backend/domain/mapper"
 	"Backend/internal/models"
+	"Backend/internal/secrets"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -98,6 +100,44 @@ func (r *UserRepository) UpdateUser(user *entity.User) error {
 	return r.db.Save(m).Error
 }
 
+// IncrementFailedLogin ログイン失敗回数を加算し、加算後の回数を返す（同時に失敗しても取りこぼさない）
+func (r *UserRepository) IncrementFailedLogin(userID uint, at time.Time) (int, error) {
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"failed_login_count":   gorm.Expr("failed_login_count + 1"),
+		"last_failed_login_at": at,
+	}).Error
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := r.db.Model(&models.User{}).Where("id = ?", userID).Pluck("failed_login_count", &count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// UpdateLockout ログイン失敗回数・ロック状態の列だけを保存する
+func (r *UserRepository) UpdateLockout(user *entity.User) error {
+	return r.db.Model(&models.User{}).Where("id = ?", user.ID).
+		Select("failed_login_count", "last_failed_login_at", "locked_until", "unlock_token", "sessions_revoked_at").
+		Updates(map[string]interface{}{
+			"failed_login_count":   user.FailedLoginCount,
+			"last_failed_login_at": user.LastFailedLoginAt,
+			"locked_until":         user.LockedUntil,
+			"unlock_token":         user.UnlockToken,
+			"sessions_revoked_at":  user.SessionsRevokedAt,
+		}).Error
+}
+
+// ReencryptTOTPSecrets 平文または旧鍵で暗号化された TOTP シークレットを現行鍵で暗号化し直す。
+// 更新件数を返す。dryRun の場合は件数の集計のみ行う。
+func (r *UserRepository) ReencryptTOTPSecrets(envelope *secrets.Envelope, batchSize int, dryRun bool) (int, error) {
+	if envelope == nil {
+		return 0, errors.New("encryption is not configured")
+	}
+	return reencryptColumn(r.db, envelope, &models.User{}, "totp_secret", batchSize, dryRun)
+}
+
 // DeleteUser ユーザー削除
 func (r *UserRepository) DeleteUser(id uint) error {
 	return r.db.Delete(&models.User{}, id).Error
@@ -138,3 +178,15 @@ func (r *UserRepository) GetUserByOAuth(provider, oauthID string) (*entity.User,
 	}
 	return mapper.UserToEntity(&m), nil
 }
+
+// GetUserByUnlockToken アカウントロック解除トークンでユーザー取得
+func (r *UserRepository) GetUserByUnlockToken(token string) (*entity.User, error) {
+	var m models.User
+	if err := r.db.Where("unlock_token = ?", token).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return mapper.UserToEntity(&m), nil
+}
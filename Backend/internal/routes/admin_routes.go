@@ -3,7 +3,6 @@ package routes
 import (
 	"Backend/internal/controllers"
 	"Backend/internal/middleware"
-	"Backend/internal/repositories"
 	"net/http"
 )
 
@@ -20,52 +19,80 @@ func SetupAdminRoutes(
 	profileRecalcController *controllers.AdminProfileRecalculationController,
 	scoreValidationController *controllers.AdminScoreValidationController,
 	collectiveInsightController *controllers.CollectiveInsightController,
-	userRepo *repositories.UserRepository,
+	rateLimitController *controllers.AdminRateLimitController,
+	promptController *controllers.AdminPromptController,
+	questionRuleController *controllers.AdminQuestionRuleController,
+	authn *middleware.Authenticator,
 ) {
-	auth := func(f http.HandlerFunc) http.HandlerFunc {
-		return middleware.AdminAuthFunc(userRepo, f)
+	// 各ルートに必要な権限を宣言する（ロールと権限の対応は middleware.rolePermissions）
+	allow := func(perm middleware.Permission, f http.HandlerFunc) http.HandlerFunc {
+		return authn.RequirePermission(perm, f)
 	}
 
-	http.HandleFunc("/api/admin/companies", auth(adminCompanyController.ListOrCreate))
-	// http.HandleFunc("/api/admin/companies/search-gbiz", auth(adminCompanyController.SearchGBizRoute)) // gBizINFO停止中
-	http.HandleFunc("/api/admin/companies/", auth(adminCompanyController.Detail))
-	http.HandleFunc("/api/admin/crawl-sources", auth(adminCrawlController.Sources))
-	http.HandleFunc("/api/admin/crawl-sources/", auth(adminCrawlController.SourceDetail))
-	http.HandleFunc("/api/admin/crawl-runs", auth(adminCrawlController.Runs))
-	http.HandleFunc("/api/admin/job-categories", auth(adminJobController.JobCategories))
-	http.HandleFunc("/api/admin/job-positions", auth(adminJobController.JobPositions))
-	http.HandleFunc("/api/admin/job-positions/", auth(adminJobController.JobPositionAction))
-	http.HandleFunc("/api/admin/graduate-employments", auth(adminJobController.GraduateEmployments))
-	http.HandleFunc("/api/admin/graduate-employments/", auth(adminJobController.GraduateEmploymentDetail))
-	http.HandleFunc("/api/admin/users", auth(adminUserController.List))
-	http.HandleFunc("/api/admin/users/", auth(adminUserController.Update))
-	http.HandleFunc("/api/admin/audit-logs", auth(adminAuditController.List))
+	http.HandleFunc("/api/admin/companies", allow(middleware.PermCompaniesManage, adminCompanyController.ListOrCreate))
+	// http.HandleFunc("/api/admin/companies/search-gbiz", allow(middleware.PermCompaniesManage, adminCompanyController.SearchGBizRoute)) // gBizINFO停止中
+	http.HandleFunc("/api/admin/companies/", allow(middleware.PermCompaniesManage, adminCompanyController.Detail))
+	http.HandleFunc("/api/admin/crawl-sources", allow(middleware.PermCrawlManage, adminCrawlController.Sources))
+	http.HandleFunc("/api/admin/crawl-sources/", allow(middleware.PermCrawlManage, adminCrawlController.SourceDetail))
+	http.HandleFunc("/api/admin/crawl-runs", allow(middleware.PermCrawlManage, adminCrawlController.Runs))
+	http.HandleFunc("/api/admin/job-categories", allow(middleware.PermJobsManage, adminJobController.JobCategories))
+	http.HandleFunc("/api/admin/job-positions", allow(middleware.PermJobsManage, adminJobController.JobPositions))
+	http.HandleFunc("/api/admin/job-positions/", allow(middleware.PermJobsManage, adminJobController.JobPositionAction))
+	http.HandleFunc("/api/admin/graduate-employments", allow(middleware.PermJobsManage, adminJobController.GraduateEmployments))
+	http.HandleFunc("/api/admin/graduate-employments/", allow(middleware.PermJobsManage, adminJobController.GraduateEmploymentDetail))
+	http.HandleFunc("/api/admin/users", allow(middleware.PermUsersManage, adminUserController.List))
+	http.HandleFunc("/api/admin/users/", allow(middleware.PermUsersManage, adminUserController.Route))
+	http.HandleFunc("/api/admin/audit-logs", allow(middleware.PermAuditRead, adminAuditController.List))
+	http.HandleFunc("/api/admin/flagged-inputs", allow(middleware.PermAuditRead, adminAuditController.FlaggedInputs))
 
 	// Company graph (scraping pipeline)
 	http.HandleFunc("/api/admin/company-graph/target-year", adminCompanyGraphController.TargetYear)
-	http.HandleFunc("/api/admin/company-graph/crawl", auth(adminCompanyGraphController.Crawl))
+	http.HandleFunc("/api/admin/company-graph/crawl", allow(middleware.PermCompaniesManage, adminCompanyGraphController.Crawl))
 
 	// Interview management
-	http.HandleFunc("/api/admin/interviews", auth(adminInterviewController.ListSessions))
-	http.HandleFunc("/api/admin/interviews/", auth(adminInterviewController.Route))
+	http.HandleFunc("/api/admin/interviews", allow(middleware.PermInterviewsRead, adminInterviewController.ListSessions))
+	http.HandleFunc("/api/admin/interviews/", allow(middleware.PermInterviewsRead, adminInterviewController.Route))
 
 	// Dashboard
-	http.HandleFunc("/api/admin/dashboard/users", auth(adminDashboardController.ListUsers))
-	http.HandleFunc("/api/admin/dashboard/users/", auth(adminDashboardController.UserSessions))
-	http.HandleFunc("/api/admin/dashboard/export/csv", auth(adminDashboardController.ExportCSV))
+	http.HandleFunc("/api/admin/dashboard/users", allow(middleware.PermDashboardRead, adminDashboardController.ListUsers))
+	http.HandleFunc("/api/admin/dashboard/users/", allow(middleware.PermDashboardRead, adminDashboardController.UserSessions))
+	http.HandleFunc("/api/admin/dashboard/export/csv", allow(middleware.PermDashboardRead, adminDashboardController.ExportCSV))
+	http.HandleFunc("/api/admin/dashboard/llm-status", allow(middleware.PermDashboardRead, adminDashboardController.LLMStatus))
 
 	// API Cost monitoring
-	http.HandleFunc("/api/admin/costs/summary", auth(adminCostsController.Summary))
-	http.HandleFunc("/api/admin/costs/daily", auth(adminCostsController.Daily))
-	http.HandleFunc("/api/admin/costs/monthly", auth(adminCostsController.Monthly))
+	http.HandleFunc("/api/admin/costs/summary", allow(middleware.PermCostsRead, adminCostsController.Summary))
+	http.HandleFunc("/api/admin/costs/daily", allow(middleware.PermCostsRead, adminCostsController.Daily))
+	http.HandleFunc("/api/admin/costs/monthly", allow(middleware.PermCostsRead, adminCostsController.Monthly))
+	http.HandleFunc("/api/admin/costs/users", allow(middleware.PermCostsRead, adminCostsController.Users))
+	http.HandleFunc("/api/admin/costs/users/", allow(middleware.PermCostsRead, adminCostsController.UserDetail))
+	http.HandleFunc("/api/admin/costs/features", allow(middleware.PermCostsRead, adminCostsController.Features))
+	http.HandleFunc("/api/admin/costs/budgets", allow(middleware.PermCostsRead, adminCostsController.Budgets))
+	http.HandleFunc("/api/admin/costs/budgets/", allow(middleware.PermSettingsManage, adminCostsController.UserBudget))
+	http.HandleFunc("/api/admin/costs/pricing", allow(middleware.PermCostsRead, adminCostsController.Pricing))
+	http.HandleFunc("/api/admin/costs/pricing/", allow(middleware.PermSettingsManage, adminCostsController.ManagePricing))
+	http.HandleFunc("/api/admin/llm-cache", allow(middleware.PermSettingsManage, adminCostsController.LLMCache))
 
 	// Profile recalculation
-	http.HandleFunc("/api/admin/profile-recalculation", auth(profileRecalcController.Route))
-	http.HandleFunc("/api/admin/profile-recalculation/", auth(profileRecalcController.Route))
+	http.HandleFunc("/api/admin/profile-recalculation", allow(middleware.PermScoringManage, profileRecalcController.Route))
+	http.HandleFunc("/api/admin/profile-recalculation/", allow(middleware.PermScoringManage, profileRecalcController.Route))
 
 	// Score validation (correlation, calibration, A/B test)
-	http.HandleFunc("/api/admin/score-validation/", auth(scoreValidationController.Route))
+	http.HandleFunc("/api/admin/score-validation/", allow(middleware.PermScoringManage, scoreValidationController.Route))
 
 	// Collective insight batch
-	http.HandleFunc("/api/admin/collective-insights/rebuild-summaries", auth(collectiveInsightController.RebuildSummaries))
+	http.HandleFunc("/api/admin/collective-insights/rebuild-summaries", allow(middleware.PermInsightsManage, collectiveInsightController.RebuildSummaries))
+
+	// Rate limits
+	http.HandleFunc("/api/admin/rate-limits", allow(middleware.PermSettingsManage, rateLimitController.List))
+	http.HandleFunc("/api/admin/rate-limits/", allow(middleware.PermSettingsManage, rateLimitController.Update))
+
+	// Prompt registry (versions, publish, rollback)
+	http.HandleFunc("/api/admin/prompts", allow(middleware.PermSettingsManage, promptController.List))
+	http.HandleFunc("/api/admin/prompts/", allow(middleware.PermSettingsManage, promptController.Route))
+
+	// Weight rules and AI question templates (CRUD, dry run)
+	http.HandleFunc("/api/admin/weight-rules", allow(middleware.PermScoringManage, questionRuleController.WeightRules))
+	http.HandleFunc("/api/admin/weight-rules/", allow(middleware.PermScoringManage, questionRuleController.WeightRuleRoute))
+	http.HandleFunc("/api/admin/question-templates", allow(middleware.PermScoringManage, questionRuleController.QuestionTemplates))
+	http.HandleFunc("/api/admin/question-templates/", allow(middleware.PermScoringManage, questionRuleController.QuestionTemplateRoute))
 }
@@ -2,27 +2,46 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
 	"net/http"
 )
 
 // SetupAuthRoutes 認証関連のルーティング設定
-func SetupAuthRoutes(authController *controllers.AuthController, oauthController *controllers.OAuthController) {
+func SetupAuthRoutes(authController *controllers.AuthController, oauthController *controllers.OAuthController, authn *middleware.Authenticator) {
 	// 認証エンドポイント
 	http.HandleFunc("/api/auth/request-registration", authController.RequestRegistration)
 	http.HandleFunc("/api/auth/verify-registration", authController.VerifyRegistration)
 	http.HandleFunc("/api/auth/register", authController.Register)
 	http.HandleFunc("/api/auth/login", authController.Login)
+	http.HandleFunc("/api/auth/login/mfa", authController.LoginMFA)
 	http.HandleFunc("/api/auth/guest", authController.CreateGuest)
-	http.HandleFunc("/api/auth/user", authController.GetUser)
-	http.HandleFunc("/api/auth/profile", authController.UpdateProfile)
+	http.HandleFunc("/api/auth/guest/upgrade", authn.Require(authController.UpgradeGuest))
+	http.HandleFunc("/api/auth/guest/merge", authn.Require(authController.MergeGuest))
+	http.HandleFunc("/api/auth/refresh", authController.Refresh)
+	http.HandleFunc("/api/auth/user", authn.Require(authController.GetUser))
+	http.HandleFunc("/api/auth/profile", authn.Require(authController.UpdateProfile))
 	http.HandleFunc("/api/auth/verify-email", authController.VerifyEmail)
 	http.HandleFunc("/api/auth/forgot-password", authController.RequestPasswordReset)
 	http.HandleFunc("/api/auth/reset-password", authController.ResetPassword)
-	http.HandleFunc("/api/auth/account", authController.DeleteAccount)
+	http.HandleFunc("/api/auth/unlock", authController.UnlockAccount)
+	http.HandleFunc("/api/auth/password", authn.Require(authController.SetPassword))
+
+	// 2段階認証（TOTP）
+	http.HandleFunc("/api/auth/mfa", authn.Require(authController.MFAStatus))
+	http.HandleFunc("/api/auth/mfa/setup", authn.Require(authController.MFASetup))
+	http.HandleFunc("/api/auth/mfa/activate", authn.Require(authController.MFAActivate))
+	http.HandleFunc("/api/auth/mfa/verify", authn.Require(authController.MFAVerify))
+	http.HandleFunc("/api/auth/mfa/recovery-codes", authn.Require(authController.MFARecoveryCodes))
+	http.HandleFunc("/api/auth/mfa/disable", authn.Require(authController.MFADisable))
 
 	// OAuth エンドポイント
 	http.HandleFunc("/api/auth/google", oauthController.GoogleLogin)
 	http.HandleFunc("/api/auth/google/callback", oauthController.GoogleCallback)
 	http.HandleFunc("/api/auth/github", oauthController.GitHubLogin)
 	http.HandleFunc("/api/auth/github/callback", oauthController.GitHubCallback)
+	http.HandleFunc("/api/auth/oauth/exchange", oauthController.ExchangeLoginCode)
+
+	// 外部アカウント連携（ログイン中のユーザー）
+	http.HandleFunc("/api/auth/identities", authn.Require(oauthController.Identities))
+	http.HandleFunc("/api/auth/identities/", authn.Require(oauthController.IdentityRoute))
 }
@@ -2,23 +2,26 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
+	"Backend/internal/ratelimit"
 	"net/http"
 )
 
 // SetupChatRoutes チャット関連のルーティング設定
-func SetupChatRoutes(chatController *controllers.ChatController, questionController *controllers.QuestionController) {
+func SetupChatRoutes(chatController *controllers.ChatController, questionController *controllers.QuestionController, authn *middleware.Authenticator, rl *middleware.RateLimit) {
 	// チャットエンドポイント
-	http.HandleFunc("/api/chat", chatController.Chat)
-	http.HandleFunc("/api/chat/history", chatController.GetHistory)
-	http.HandleFunc("/api/chat/scores", chatController.GetScores)
-	http.HandleFunc("/api/chat/recommendations", chatController.GetRecommendations)
-	http.HandleFunc("/api/chat/analysis", chatController.GetAnalysisSummary)
-	http.HandleFunc("/api/chat/sessions", chatController.GetSessions)
-	http.HandleFunc("/api/chat/send-report", chatController.SendReport)
-	http.HandleFunc("/api/chat/favorite", chatController.ToggleFavorite)
+	http.HandleFunc("/api/chat", authn.Require(rl.Limit(ratelimit.GroupChat, chatController.Chat)))
+	http.HandleFunc("/api/chat/stream", authn.Require(rl.Limit(ratelimit.GroupChat, chatController.ChatStream)))
+	http.HandleFunc("/api/chat/history", authn.Require(chatController.GetHistory))
+	http.HandleFunc("/api/chat/scores", authn.Require(chatController.GetScores))
+	http.HandleFunc("/api/chat/recommendations", authn.Require(chatController.GetRecommendations))
+	http.HandleFunc("/api/chat/analysis", authn.Require(chatController.GetAnalysisSummary))
+	http.HandleFunc("/api/chat/sessions", authn.Require(chatController.GetSessions))
+	http.HandleFunc("/api/chat/send-report", authn.Require(chatController.SendReport))
+	http.HandleFunc("/api/chat/favorite", authn.Require(chatController.ToggleFavorite))
 
 	// 質問管理エンドポイント
-	http.HandleFunc("/api/questions/generate", questionController.GenerateQuestions)
+	http.HandleFunc("/api/questions/generate", authn.Require(rl.Limit(ratelimit.GroupChat, questionController.GenerateQuestions)))
 	http.HandleFunc("/api/questions/create", questionController.CreateQuestion)
 	http.HandleFunc("/api/questions/list", questionController.GetQuestionsByCategory)
 }
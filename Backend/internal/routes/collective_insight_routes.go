@@ -2,12 +2,13 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
 	"net/http"
 )
 
-func SetupCollectiveInsightRoutes(controller *controllers.CollectiveInsightController) {
-	http.HandleFunc("/api/collective-insights/recommendations", controller.Route)
-	http.HandleFunc("/api/collective-insights/top-companies", controller.Route)
-	http.HandleFunc("/api/collective-insights/consent", controller.Route)
-	http.HandleFunc("/api/collective-insights/actions", controller.Route)
+func SetupCollectiveInsightRoutes(controller *controllers.CollectiveInsightController, authn *middleware.Authenticator) {
+	http.HandleFunc("/api/collective-insights/recommendations", authn.Require(controller.Route))
+	http.HandleFunc("/api/collective-insights/top-companies", authn.Require(controller.Route))
+	http.HandleFunc("/api/collective-insights/consent", authn.Require(controller.Route))
+	http.HandleFunc("/api/collective-insights/actions", authn.Require(controller.Route))
 }
@@ -2,16 +2,20 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
+	"Backend/internal/ratelimit"
 	"net/http"
 	"strings"
 )
 
 // SetupCompanyRoutes 企業関連のルーティング設定
-func SetupCompanyRoutes(relationController *controllers.CompanyRelationController) {
+func SetupCompanyRoutes(relationController *controllers.CompanyRelationController, authn *middleware.Authenticator, rl *middleware.RateLimit) {
 	http.HandleFunc("/api/companies", relationController.GetCompanies)
 	http.HandleFunc("/api/companies/relations", relationController.GetAllCompanyRelations)
 	http.HandleFunc("/api/companies/market-info", relationController.GetAllMarketInfo)
 	http.HandleFunc("/api/companies/web-search", relationController.WebSearchCompanies)
+	// 検索語の埋め込みに LLM を呼ぶため、ログインした利用者に限る
+	http.HandleFunc("/api/companies/semantic-search", authn.Require(rl.Limit(ratelimit.GroupSearch, relationController.SemanticSearchCompanies)))
 	http.HandleFunc("/api/companies/", func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/api/companies/")
 		if strings.HasSuffix(path, "/job-positions") {
@@ -2,10 +2,12 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
+	"Backend/internal/ratelimit"
 	"net/http"
 )
 
-func SetupESRoutes(esRewriteController *controllers.ESRewriteController, esReviewController *controllers.ESReviewController) {
-	http.HandleFunc("/api/es/rewrite", esRewriteController.Rewrite)
-	http.HandleFunc("/api/es/review", esReviewController.Review)
+func SetupESRoutes(esRewriteController *controllers.ESRewriteController, esReviewController *controllers.ESReviewController, authn *middleware.Authenticator, rl *middleware.RateLimit) {
+	http.HandleFunc("/api/es/rewrite", authn.Require(rl.Limit(ratelimit.GroupES, esRewriteController.Rewrite)))
+	http.HandleFunc("/api/es/review", authn.Require(rl.Limit(ratelimit.GroupES, esReviewController.Review)))
 }
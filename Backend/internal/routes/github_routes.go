@@ -2,15 +2,17 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
+	"Backend/internal/ratelimit"
 	"net/http"
 )
 
 // SetupGitHubRoutes GitHub連携関連のルーティング設定
-func SetupGitHubRoutes(githubController *controllers.GitHubController) {
-	http.HandleFunc("/api/github/profile", githubController.GetProfile)
-	http.HandleFunc("/api/github/sync", githubController.Sync)
-	http.HandleFunc("/api/github/sync/wait", githubController.SyncAndWait)
-	http.HandleFunc("/api/github/skills", githubController.GetSkills)
-	http.HandleFunc("/api/github/repo/summaries", githubController.ListRepoSummaries)
-	http.HandleFunc("/api/github/repo/summarize", githubController.SummarizeRepo)
+func SetupGitHubRoutes(githubController *controllers.GitHubController, authn *middleware.Authenticator, rl *middleware.RateLimit) {
+	http.HandleFunc("/api/github/profile", authn.Require(githubController.GetProfile))
+	http.HandleFunc("/api/github/sync", authn.Require(rl.Limit(ratelimit.GroupGitHub, githubController.Sync)))
+	http.HandleFunc("/api/github/sync/wait", authn.Require(rl.Limit(ratelimit.GroupGitHub, githubController.SyncAndWait)))
+	http.HandleFunc("/api/github/skills", authn.Require(githubController.GetSkills))
+	http.HandleFunc("/api/github/repo/summaries", authn.Require(githubController.ListRepoSummaries))
+	http.HandleFunc("/api/github/repo/summarize", authn.Require(rl.Limit(ratelimit.GroupGitHub, githubController.SummarizeRepo)))
 }
@@ -2,15 +2,28 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
+	"Backend/internal/ratelimit"
 	"net/http"
+	"strings"
 )
 
 // SetupInterviewRoutes 面接関連のルーティング設定
-func SetupInterviewRoutes(interviewController *controllers.InterviewController, realtimeController *controllers.RealtimeController) {
+func SetupInterviewRoutes(interviewController *controllers.InterviewController, realtimeController *controllers.RealtimeController, authn *middleware.Authenticator, rl *middleware.RateLimit) {
 	// /api/interviews/trend はワイルドカード /api/interviews/ より先に登録する必要がある
-	http.HandleFunc("/api/interviews/trend", interviewController.GetTrend)
-	http.HandleFunc("/api/interviews", interviewController.ListOrCreate)
-	http.HandleFunc("/api/interviews/", interviewController.Route)
-	http.HandleFunc("/api/realtime/token", realtimeController.Token)
+	http.HandleFunc("/api/interviews/trend", authn.Require(interviewController.GetTrend))
+	http.HandleFunc("/api/interviews", authn.Require(interviewController.ListOrCreate))
+	http.HandleFunc("/api/interviews/", authn.Require(rl.LimitIf(ratelimit.GroupInterview, isInterviewAIRequest, interviewController.Route)))
+	http.HandleFunc("/api/realtime/token", authn.Require(rl.Limit(ratelimit.GroupInterview, realtimeController.Token)))
 	http.HandleFunc("/api/realtime/session-info", realtimeController.SessionInfo)
 }
+
+// isInterviewAIRequest OpenAI を呼び出す面接サブルート（ターン生成・レポート生成・言い換え提案）か
+func isInterviewAIRequest(r *http.Request) bool {
+	for _, suffix := range []string{"/turn", "/start-turn", "/finish", "/phrase-suggestions"} {
+		if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), suffix) {
+			return true
+		}
+	}
+	return false
+}
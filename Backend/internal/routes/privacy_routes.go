@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"Backend/internal/controllers"
+	"Backend/internal/middleware"
+	"net/http"
+)
+
+// SetupPrivacyRoutes 個人データの開示・削除のルーティング設定
+func SetupPrivacyRoutes(privacyController *controllers.PrivacyController, authn *middleware.Authenticator) {
+	http.HandleFunc("/api/privacy/export", authn.Require(privacyController.Export))
+	http.HandleFunc("/api/privacy/erasure", authn.Require(privacyController.Erasure))
+	http.HandleFunc("/api/privacy/erasure/status", privacyController.ErasureStatus)
+	http.HandleFunc("/api/privacy/jobs/", authn.Require(privacyController.JobRoute))
+	// 従来のアカウント削除エンドポイント
+	http.HandleFunc("/api/auth/account", authn.Require(privacyController.Erasure))
+}
@@ -2,12 +2,14 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
+	"Backend/internal/ratelimit"
 	"net/http"
 )
 
-func SetupResumeRoutes(resumeController *controllers.ResumeController) {
-	http.HandleFunc("/api/resume/upload", resumeController.Upload)
-	http.HandleFunc("/api/resume/review", resumeController.Review)
-	http.HandleFunc("/api/resume/review/stream", resumeController.ReviewStream)
-	http.HandleFunc("/api/resume/annotated", resumeController.Annotated)
+func SetupResumeRoutes(resumeController *controllers.ResumeController, authn *middleware.Authenticator, rl *middleware.RateLimit) {
+	http.HandleFunc("/api/resume/upload", authn.Require(resumeController.Upload))
+	http.HandleFunc("/api/resume/review", authn.Require(rl.Limit(ratelimit.GroupResume, resumeController.Review)))
+	http.HandleFunc("/api/resume/review/stream", authn.Require(rl.Limit(ratelimit.GroupResume, resumeController.ReviewStream)))
+	http.HandleFunc("/api/resume/annotated", authn.Require(resumeController.Annotated))
 }
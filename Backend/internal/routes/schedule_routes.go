@@ -2,11 +2,12 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
 	"net/http"
 )
 
-func SetupScheduleRoutes(scheduleController *controllers.ScheduleController) {
-	http.HandleFunc("/api/schedule/export/ics", scheduleController.ExportICS)
-	http.HandleFunc("/api/schedule/", scheduleController.RouteByID)
-	http.HandleFunc("/api/schedule", scheduleController.RouteList)
+func SetupScheduleRoutes(scheduleController *controllers.ScheduleController, authn *middleware.Authenticator) {
+	http.HandleFunc("/api/schedule/export/ics", authn.Require(scheduleController.ExportICS))
+	http.HandleFunc("/api/schedule/", authn.Require(scheduleController.RouteByID))
+	http.HandleFunc("/api/schedule", authn.Require(scheduleController.RouteList))
 }
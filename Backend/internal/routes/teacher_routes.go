@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"Backend/internal/controllers"
+	"Backend/internal/middleware"
+	"net/http"
+)
+
+// SetupTeacherRoutes 教員・クラス管理のルーティング設定
+func SetupTeacherRoutes(teacherController *controllers.TeacherController, authn *middleware.Authenticator) {
+	http.HandleFunc("/api/teacher/cohorts", authn.RequirePermission(middleware.PermCohortsManage, teacherController.Cohorts))
+	http.HandleFunc("/api/teacher/cohorts/", authn.RequirePermission(middleware.PermCohortsManage, teacherController.CohortRoute))
+	http.HandleFunc("/api/teacher/students/", authn.RequirePermission(middleware.PermStudentsView, teacherController.StudentRoute))
+
+	// 招待の承認（ロールは変えない。教員ロールでないユーザーはサービスで拒否する）
+	http.HandleFunc("/api/teacher/invitations/accept", authn.Require(teacherController.AcceptInvitation))
+
+	// 学生が自分のデータを閲覧できる教員を確認する
+	http.HandleFunc("/api/user/teachers", authn.Require(teacherController.MyTeachers))
+
+	// 学生が教員によるクラスへの追加を承認・拒否する（承認するまで教員は閲覧できない）
+	http.HandleFunc("/api/user/cohorts", authn.Require(teacherController.MyCohorts))
+	http.HandleFunc("/api/user/cohorts/", authn.Require(teacherController.MyCohortRoute))
+}
@@ -2,9 +2,10 @@ package routes
 
 import (
 	"Backend/internal/controllers"
+	"Backend/internal/middleware"
 	"net/http"
 )
 
-func SetupUserRoutes(profileController *controllers.IntegratedProfileController) {
-	http.HandleFunc("/api/user/profile", profileController.GetProfile)
+func SetupUserRoutes(profileController *controllers.IntegratedProfileController, authn *middleware.Authenticator) {
+	http.HandleFunc("/api/user/profile", authn.Require(profileController.GetProfile))
 }
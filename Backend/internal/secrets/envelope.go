@@ -0,0 +1,162 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// envelopePrefix 暗号化済み値の接頭辞。これが無い値は移行前の平文として扱う
+const envelopePrefix = "enc:v1:"
+
+// ErrMalformedCiphertext 暗号文の形式が不正
+var ErrMalformedCiphertext = errors.New("malformed ciphertext")
+
+// Envelope エンベロープ暗号化。値ごとにデータ鍵（DEK）を生成して AES-256-GCM で暗号化し、
+// DEK は KeyProvider の鍵（KEK）で包んで暗号文と一緒に保存する。
+//
+// 形式: enc:v1:<keyID>:<base64(包んだDEK)>:<base64(nonce+暗号文)>
+type Envelope struct {
+	keys KeyProvider
+}
+
+func NewEnvelope(keys KeyProvider) *Envelope {
+	return &Envelope{keys: keys}
+}
+
+// NewEnvelopeFromEnv TOKEN_ENCRYPTION_KEY_FILE の鍵ファイルから Envelope を作る。
+// 未設定の場合、本番環境ではエラー、それ以外は nil（平文保存）を返す。
+func NewEnvelopeFromEnv() (*Envelope, error) {
+	path := os.Getenv("TOKEN_ENCRYPTION_KEY_FILE")
+	if path == "" {
+		if os.Getenv("APP_ENV") == "production" {
+			return nil, errors.New("TOKEN_ENCRYPTION_KEY_FILE is required in production")
+		}
+		log.Println("WARNING: TOKEN_ENCRYPTION_KEY_FILE が設定されていません。外部サービスのトークンを平文で保存します。")
+		return nil, nil
+	}
+	provider, err := NewLocalFileKeyProvider(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewEnvelope(provider), nil
+}
+
+// IsEncrypted 値が暗号化済みかどうか
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+// Encrypt 平文を現行鍵で暗号化する（空文字はそのまま返す）
+func (e *Envelope) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	keyID, kek, err := e.keys.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := seal(kek, dek)
+	if err != nil {
+		return "", err
+	}
+	body, err := seal(dek, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + keyID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(body), nil
+}
+
+// Decrypt 暗号文を復号する。接頭辞の無い値は移行前の平文としてそのまま返す
+func (e *Envelope) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformedCiphertext
+	}
+	kek, err := e.keys.Key(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("key %q: %w", parts[0], err)
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformedCiphertext
+	}
+	body, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformedCiphertext
+	}
+	dek, err := open(kek, wrapped)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dek, body)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencrypt 平文、または現行鍵以外で暗号化された値かどうか
+func (e *Envelope) NeedsReencrypt(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	keyID, _, err := e.keys.CurrentKey()
+	if err != nil {
+		return false
+	}
+	return !strings.HasPrefix(value, envelopePrefix+keyID+":")
+}
+
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrMalformedCiphertext
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,221 @@
+// Package secrets は保存時暗号化（エンベロープ暗号化）と鍵管理を提供する。
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound 指定された鍵IDが存在しない
+var ErrKeyNotFound = errors.New("encryption key not found")
+
+// KeyProvider データ鍵を包む鍵暗号鍵（KEK）を提供する。
+// ローテーション後も過去の鍵IDで復号できるよう、旧鍵は保持し続ける必要がある。
+type KeyProvider interface {
+	// CurrentKey 新規暗号化に使う鍵IDと鍵（32バイト）を返す
+	CurrentKey() (keyID string, key []byte, err error)
+	// Key 鍵IDに対応する鍵を返す
+	Key(keyID string) ([]byte, error)
+}
+
+// keyFile ローカル鍵ファイルの形式
+//
+//	{"current": "k2", "keys": {"k1": "<base64>", "k2": "<base64>"}}
+type keyFile struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"`
+}
+
+const (
+	// keyFileReloadInterval 現行鍵を鍵ファイルから読み直す間隔（稼働中のローテーションに追従する）
+	keyFileReloadInterval = 1 * time.Minute
+	// keyFileRetryInterval 知らない鍵IDで鍵ファイルを読み直す最短間隔（不正な鍵IDで読み込みが続かないようにする）
+	keyFileRetryInterval = 5 * time.Second
+)
+
+// LocalFileKeyProvider JSON ファイルに保存した鍵を使う KeyProvider。
+// 別プロセス（reencrypt-tokens -rotate）が稼働中に鍵を追加しても復号できるよう、
+// 知らない鍵IDを求められたときと、現行鍵を一定間隔ごとに鍵ファイルを読み直す
+type LocalFileKeyProvider struct {
+	path       string
+	mu         sync.RWMutex
+	current    string
+	keys       map[string][]byte
+	loadedAt   time.Time
+	reloadedAt time.Time // 最後に読み直しを試みた時刻（失敗を含む）
+	now        func() time.Time
+}
+
+// NewLocalFileKeyProvider 鍵ファイルを読み込む
+func NewLocalFileKeyProvider(path string) (*LocalFileKeyProvider, error) {
+	p := &LocalFileKeyProvider{path: path, now: time.Now}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *LocalFileKeyProvider) load() error {
+	raw, err := os.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to read key file: %w", err)
+	}
+	var f keyFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return fmt.Errorf("failed to parse key file: %w", err)
+	}
+	keys := make(map[string][]byte, len(f.Keys))
+	for id, enc := range f.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return fmt.Errorf("invalid key id %q", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return fmt.Errorf("key %q is not valid base64: %w", id, err)
+		}
+		if len(key) != 32 {
+			return fmt.Errorf("key %q must be 32 bytes", id)
+		}
+		keys[id] = key
+	}
+	if _, ok := keys[f.Current]; !ok {
+		return fmt.Errorf("current key %q is not defined", f.Current)
+	}
+	p.mu.Lock()
+	p.current = f.Current
+	p.keys = keys
+	p.loadedAt = p.now()
+	p.mu.Unlock()
+	return nil
+}
+
+// reload は前回の試行から minInterval 以上経っていれば鍵ファイルを読み直す。
+// 読み直しに失敗した場合は読み込み済みの鍵を使い続ける
+func (p *LocalFileKeyProvider) reload(minInterval time.Duration) {
+	p.mu.Lock()
+	if p.now().Sub(p.reloadedAt) < minInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.reloadedAt = p.now()
+	p.mu.Unlock()
+	if err := p.load(); err != nil {
+		log.Printf("[secrets] failed to reload key file: %v", err)
+	}
+}
+
+func (p *LocalFileKeyProvider) CurrentKey() (string, []byte, error) {
+	p.mu.RLock()
+	stale := p.now().Sub(p.loadedAt) >= keyFileReloadInterval
+	p.mu.RUnlock()
+	if stale {
+		p.reload(keyFileReloadInterval)
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current, p.keys[p.current], nil
+}
+
+// Key 鍵IDに対応する鍵を返す。知らない鍵IDの場合は鍵ファイルを読み直してから探す
+func (p *LocalFileKeyProvider) Key(keyID string) ([]byte, error) {
+	if key, ok := p.lookup(keyID); ok {
+		return key, nil
+	}
+	p.reload(keyFileRetryInterval)
+	if key, ok := p.lookup(keyID); ok {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (p *LocalFileKeyProvider) lookup(keyID string) ([]byte, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	key, ok := p.keys[keyID]
+	return key, ok
+}
+
+// Rotate 新しい鍵を生成して現行鍵に切り替え、鍵ファイルへ書き戻す。旧鍵は復号用に残す
+func (p *LocalFileKeyProvider) Rotate() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	keyID, err := newKeyID()
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.keys[keyID]; exists {
+		return "", fmt.Errorf("key %q already exists", keyID)
+	}
+	f := keyFile{Current: keyID, Keys: make(map[string]string, len(p.keys)+1)}
+	for id, k := range p.keys {
+		f.Keys[id] = base64.StdEncoding.EncodeToString(k)
+	}
+	f.Keys[keyID] = base64.StdEncoding.EncodeToString(key)
+	raw, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(p.path, raw); err != nil {
+		return "", err
+	}
+	p.keys[keyID] = key
+	p.current = keyID
+	return keyID, nil
+}
+
+// GenerateKeyFile 鍵を1つ持つ新しい鍵ファイルを作成する（既存ファイルは上書きしない）
+func GenerateKeyFile(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("key file already exists: %s", path)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	keyID, err := newKeyID()
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(keyFile{
+		Current: keyID,
+		Keys:    map[string]string{keyID: base64.StdEncoding.EncodeToString(key)},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, raw)
+}
+
+// newKeyID 生成日時とランダム値から鍵IDを作る（例: k20260101120000-1a2b3c4d）
+func newKeyID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate key id: %w", err)
+	}
+	return "k" + time.Now().UTC().Format("20060102150405") + "-" + hex.EncodeToString(b), nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace key file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"Backend/internal/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// mergeGuestInto ゲストユーザーのデータをすべて登録済みユーザーに付け替え、ゲストユーザーを削除する（1トランザクション）
+func mergeGuestInto(db *gorm.DB, guestID, targetID uint) error {
+	if db == nil {
+		return errors.New("database not configured")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return mergeGuestTx(tx, guestID, targetID)
+	})
+}
+
+// mergeGuestTx mergeGuestInto の本体（呼び出し側のトランザクション内で実行する）
+func mergeGuestTx(tx *gorm.DB, guestID, targetID uint) error {
+	if err := mergeUserData(tx, guestID, targetID); err != nil {
+		return err
+	}
+	if err := tx.Delete(&models.User{}, guestID).Error; err != nil {
+		return fmt.Errorf("failed to delete guest user: %w", err)
+	}
+	return nil
+}
+
+// mergeUserData from が所有するデータを to に付け替える（呼び出し側のトランザクション内で実行する）。
+// 論理削除済みの行も含めて付け替え、ユーザーごとに一意なデータが重複する場合は to の行を残す
+func mergeUserData(tx *gorm.DB, fromID, toID uint) error {
+	for _, t := range models.UserDataTables() {
+		// 親レコード経由の子テーブルは親の付け替えで追従し、メールアドレスのみで紐付く記録は対象外
+		if !t.OwnedByUserID() {
+			continue
+		}
+		q := tx.Unscoped()
+		where := t.Column + " = ?"
+		switch t.Conflict {
+		case "":
+		case t.Column:
+			var count int64
+			if err := q.Model(t.Model).Where(where, toID).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to count %T: %w", t.Model, err)
+			}
+			if count > 0 {
+				if err := q.Where(where, fromID).Delete(t.Model).Error; err != nil {
+					return fmt.Errorf("failed to discard %T: %w", t.Model, err)
+				}
+				continue
+			}
+		default:
+			// MySQL は同じテーブルを参照するサブクエリで DELETE できないため、先に値を取得する
+			var taken []string
+			if err := q.Model(t.Model).Where(where, toID).Pluck(t.Conflict, &taken).Error; err != nil {
+				return fmt.Errorf("failed to list %T: %w", t.Model, err)
+			}
+			if len(taken) > 0 {
+				if err := q.Where(t.Column+" = ? AND "+t.Conflict+" IN ?", fromID, taken).Delete(t.Model).Error; err != nil {
+					return fmt.Errorf("failed to discard %T: %w", t.Model, err)
+				}
+			}
+		}
+		if err := q.Model(t.Model).Where(where, fromID).Update(t.Column, toID).Error; err != nil {
+			return fmt.Errorf("failed to move %T: %w", t.Model, err)
+		}
+	}
+
+	// 集合知ログはユーザーIDを匿名化したハッシュで紐付いている
+	if err := tx.Model(&models.CollectiveInsightLog{}).
+		Where("anonymous_user_id = ?", anonymizeUserID(fromID)).
+		Update("anonymous_user_id", anonymizeUserID(toID)).Error; err != nil {
+		return fmt.Errorf("failed to move collective insight logs: %w", err)
+	}
+	return nil
+}
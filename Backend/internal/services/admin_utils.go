@@ -8,13 +8,14 @@ import (
 )
 
 func promoteAdminIfMatched(user *entity.User, repo repository.UserRepository) {
-	if user == nil || repo == nil || user.IsAdmin {
+	if user == nil || repo == nil || user.HasAdminRole() {
 		return
 	}
 	if !isAdminIdentity(user.Email, user.Name) {
 		return
 	}
 	user.IsAdmin = true
+	user.Role = entity.RoleAdmin
 	_ = repo.UpdateUser(user)
 }
 
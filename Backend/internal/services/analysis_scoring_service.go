@@ -63,6 +63,7 @@ type AnalysisSummary struct {
 	JobSuitabilityComment string                  `json:"job_suitability_comment,omitempty"`
 	SuggestedRoles        []JobSuitabilityRole    `json:"suggested_roles,omitempty"`
 	ScoreComment          string                  `json:"score_comment,omitempty"`
+	CalibrationVersion    int                     `json:"calibration_version,omitempty"` // 適性スコアの重み付けに使ったキャリブレーションの版
 }
 
 type FutureAnalyzer interface {
@@ -124,6 +125,7 @@ type AnalysisScoringService struct {
 	jobEmbeddingRepo        repository.JobCategoryEmbeddingRepository
 	matchRepo               repository.UserCompanyMatchRepository
 	futureAnalyzer          FutureAnalyzer
+	calibration             CalibrationSource
 }
 
 func NewAnalysisScoringService(
@@ -151,6 +153,11 @@ func NewAnalysisScoringService(
 	}
 }
 
+// SetCalibrationSource 適性スコアのカテゴリの重み付けに使うキャリブレーションの取得元を注入する（未設定なら単純平均）
+func (s *AnalysisScoringService) SetCalibrationSource(source CalibrationSource) {
+	s.calibration = source
+}
+
 func (s *AnalysisScoringService) BuildAnalysisSummary(ctx context.Context, userID uint, sessionID string) (*AnalysisSummary, error) {
 	_ = ctx
 	jobScore, err := s.calculateJobScore(userID, sessionID)
@@ -158,7 +165,11 @@ func (s *AnalysisScoringService) BuildAnalysisSummary(ctx context.Context, userI
 		return nil, err
 	}
 	interestScore := s.calculateInterestScore(userID, sessionID)
-	aptitudeScore, axes := s.calculateAptitudeScore(userID, sessionID)
+	var calibration *CalibrationWeights
+	if s.calibration != nil {
+		calibration = s.calibration.ActiveCalibration()
+	}
+	aptitudeScore, axes := s.calculateAptitudeScore(userID, sessionID, calibration)
 	futureScore, signals := s.calculateFutureScore(sessionID)
 
 	finalScore := (jobScore * 0.4) + (interestScore * 0.25) + (aptitudeScore * 0.2) + (futureScore * 0.15)
@@ -187,6 +198,7 @@ func (s *AnalysisScoringService) BuildAnalysisSummary(ctx context.Context, userI
 		JobSuitabilityComment: jobSuitabilityComment,
 		SuggestedRoles:        suggestedRoles,
 		ScoreComment:          scoreComment,
+		CalibrationVersion:    calibration.VersionNumber(),
 	}, nil
 }
 
@@ -260,7 +272,7 @@ func (s *AnalysisScoringService) calculateInterestScore(userID uint, sessionID s
 	return clamp01(raw / max)
 }
 
-func (s *AnalysisScoringService) calculateAptitudeScore(userID uint, sessionID string) (float64, []AxisScore) {
+func (s *AnalysisScoringService) calculateAptitudeScore(userID uint, sessionID string, calibration *CalibrationWeights) (float64, []AxisScore) {
 	scores, err := s.userWeightScoreRepo.FindByUserAndSession(userID, sessionID)
 	if err != nil {
 		return 0, nil
@@ -282,7 +294,7 @@ func (s *AnalysisScoringService) calculateAptitudeScore(userID uint, sessionID s
 	var count float64
 
 	for axis, categories := range axisCategories {
-		axisScore := averageCategoryScore(scoreMap, categories)
+		axisScore := averageCategoryScore(scoreMap, categories, calibration)
 		axisScores = append(axisScores, AxisScore{
 			Axis:  axis,
 			Score: axisScore,
@@ -598,22 +610,24 @@ func clamp01(value float64) float64 {
 	return value
 }
 
-func averageCategoryScore(scoreMap map[string]float64, categories []string) float64 {
+// averageCategoryScore はカテゴリのスコアをキャリブレーション重みで加重平均する（weights が nil なら単純平均）
+func averageCategoryScore(scoreMap map[string]float64, categories []string, weights *CalibrationWeights) float64 {
 	if len(categories) == 0 {
 		return 0
 	}
 	var sum float64
-	var count float64
+	var weightSum float64
 	for _, category := range categories {
 		score, ok := scoreMap[category]
 		if !ok {
 			continue
 		}
-		sum += clamp01(score / 100.0)
-		count++
+		w := weights.Weight(category)
+		sum += clamp01(score/100.0) * w
+		weightSum += w
 	}
-	if count == 0 {
+	if weightSum == 0 {
 		return 0
 	}
-	return clamp01(sum / count)
+	return clamp01(sum / weightSum)
 }
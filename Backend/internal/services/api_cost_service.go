@@ -1,82 +1,92 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/internal/models"
 	"Backend/internal/repositories"
+	"context"
+	"errors"
 	"log"
 	"os"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 )
 
-// モデル別単価テーブル (USD per 1M tokens)
-var modelPricing = map[string][2]float64{
-	// input, output per 1M tokens
-	"gpt-4o":              {2.50, 10.00},
-	"gpt-4o-mini":         {0.15, 0.60},
-	"gpt-4-turbo":         {10.00, 30.00},
-	"gpt-4":               {30.00, 60.00},
-	"gpt-3.5-turbo":       {0.50, 1.50},
-	"gpt-5.2":             {2.50, 10.00},  // treated as gpt-4o class
-	"o1":                  {15.00, 60.00},
-	"o1-mini":             {3.00, 12.00},
-	"o3":                  {10.00, 40.00},
-	"text-embedding-3-small": {0.02, 0.02},
-	"text-embedding-3-large": {0.13, 0.13},
-}
-
-// calculateCost は入力/出力トークン数とモデル名からUSDコストを計算する
-func calculateCost(model string, promptTokens, completionTokens int) float64 {
-	lower := strings.ToLower(strings.TrimSpace(model))
-
-	// prefix match for versioned model names (e.g. gpt-4o-2024-08-06)
-	var pricing [2]float64
-	var found bool
-	for k, v := range modelPricing {
-		if lower == k || strings.HasPrefix(lower, k+"-") || strings.HasPrefix(lower, k+":") {
-			pricing = v
-			found = true
-			break
-		}
-	}
-	if !found {
-		// Default to gpt-4o pricing
-		pricing = [2]float64{2.50, 10.00}
-	}
+// ErrResponseCacheDisabled は LLM 応答キャッシュが無効な状態で操作したことを表す
+var ErrResponseCacheDisabled = errors.New("llm response cache is disabled")
 
-	inputCost := float64(promptTokens) * pricing[0] / 1_000_000
-	outputCost := float64(completionTokens) * pricing[1] / 1_000_000
-	return inputCost + outputCost
+// LLMResponseCache は LLM 応答キャッシュの統計・削除操作（internal/ai.CachingAdapter が実装）
+type LLMResponseCache interface {
+	CacheStats() []ai.CacheStat
+	PurgeCache(ctx context.Context, scope string) (int64, error)
 }
 
 // APICostService はAPIコスト記録・集計を担当する
 type APICostService struct {
 	repo            *repositories.APICallLogRepository
 	alertThresholdUSD float64 // 月額閾値
+	cache           LLMResponseCache
+	pricing         *PricingService
+
+	mu             sync.Mutex
+	warnedUnpriced map[string]bool
 }
 
-func NewAPICostService(repo *repositories.APICallLogRepository) *APICostService {
+// NewAPICostService は pricing の単価表で料金を計算するコストサービスを返す。pricing が nil なら全呼び出しを単価未登録として記録する
+func NewAPICostService(repo *repositories.APICallLogRepository, pricing *PricingService) *APICostService {
 	threshold := 100.0
 	if v := os.Getenv("API_COST_ALERT_THRESHOLD_USD"); v != "" {
 		if f, err := strconv.ParseFloat(v, 64); err == nil {
 			threshold = f
 		}
 	}
-	return &APICostService{repo: repo, alertThresholdUSD: threshold}
+	return &APICostService{repo: repo, alertThresholdUSD: threshold, pricing: pricing, warnedUnpriced: make(map[string]bool)}
 }
 
-// LogCall は非同期でAPIコールログをDBに記録する
-func (s *APICostService) LogCall(model string, promptTokens, completionTokens int) {
+// SetResponseCache は LLM 応答キャッシュを設定する（オプション）。ヒット・ミス数をコスト集計と並べて返す
+func (s *APICostService) SetResponseCache(cache LLMResponseCache) {
+	s.cache = cache
+}
+
+// GetCacheStats は呼び出し箇所ごとのキャッシュのヒット・ミス数を返す（キャッシュ無効時は空）
+func (s *APICostService) GetCacheStats() []ai.CacheStat {
+	if s.cache == nil {
+		return []ai.CacheStat{}
+	}
+	return s.cache.CacheStats()
+}
+
+// PurgeResponseCache は scope のキャッシュを削除する（空なら全件）
+func (s *APICostService) PurgeResponseCache(ctx context.Context, scope string) (int64, error) {
+	if s.cache == nil {
+		return 0, ErrResponseCacheDisabled
+	}
+	return s.cache.PurgeCache(ctx, scope)
+}
+
+// LogCall は非同期でAPIコールログをDBに記録する。ctx の ai.CallContext から利用者・機能・リクエストIDを記録する
+// 単価未登録のモデルは料金 0・model_price_id なしで記録し、GetUnpricedModels で一覧できるようにする
+func (s *APICostService) LogCall(ctx context.Context, model string, usage ai.TokenUsage) {
+	cc := ai.CallContextFrom(ctx)
 	go func() {
-		cost := calculateCost(model, promptTokens, completionTokens)
+		calledAt := time.Now().UTC()
+		cost, priceID := s.pricing.TokenCost(model, calledAt, usage)
+		if priceID == nil {
+			s.warnUnpriced(model)
+		}
 		entry := &models.APICallLog{
-			Model:            model,
-			PromptTokens:     promptTokens,
-			CompletionTokens: completionTokens,
-			TotalTokens:      promptTokens + completionTokens,
-			CostUSD:          cost,
-			CalledAt:         time.Now().UTC(),
+			Model:              model,
+			PromptTokens:       usage.PromptTokens,
+			CachedPromptTokens: usage.CachedPromptTokens,
+			CompletionTokens:   usage.CompletionTokens,
+			TotalTokens:        usage.PromptTokens + usage.CompletionTokens,
+			CostUSD:            cost,
+			ModelPriceID:       priceID,
+			CalledAt:           calledAt,
+			UserID:             cc.UserID,
+			Feature:            cc.Feature,
+			RequestID:          cc.RequestID,
 		}
 		if err := s.repo.Create(entry); err != nil {
 			log.Printf("[APICost] failed to log: %v", err)
@@ -85,6 +95,17 @@ func (s *APICostService) LogCall(model string, promptTokens, completionTokens in
 	}()
 }
 
+// warnUnpriced は単価未登録のモデルをモデルごとに一度だけログに出す
+func (s *APICostService) warnUnpriced(model string) {
+	s.mu.Lock()
+	warned := s.warnedUnpriced[model]
+	s.warnedUnpriced[model] = true
+	s.mu.Unlock()
+	if !warned {
+		log.Printf("[APICost] WARNING: no price registered for model %q; recording cost as 0 until a rate is added", model)
+	}
+}
+
 // checkThreshold は月額閾値を超えていたらログ警告を出す
 func (s *APICostService) checkThreshold() {
 	since := time.Now().UTC().AddDate(0, -1, 0)
@@ -169,6 +190,75 @@ func (s *APICostService) GetModelBreakdown(since time.Time) ([]ModelCostSummary,
 	return result, nil
 }
 
+type UserCostSummary struct {
+	UserID       uint    `json:"user_id"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	TotalTokens  int64   `json:"total_tokens"`
+	CallCount    int64   `json:"call_count"`
+}
+
+type FeatureCostSummary struct {
+	Feature      string  `json:"feature"` // 空文字は機能名のない呼び出し
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	TotalTokens  int64   `json:"total_tokens"`
+	CallCount    int64   `json:"call_count"`
+}
+
+// GetUserBreakdown は利用者別のコスト内訳をコストの高い順に最大 limit 件返す
+func (s *APICostService) GetUserBreakdown(since time.Time, limit int) ([]UserCostSummary, error) {
+	rows, err := s.repo.UserBreakdown(since, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]UserCostSummary, len(rows))
+	for i, r := range rows {
+		result[i] = UserCostSummary{
+			UserID:       r.UserID,
+			TotalCostUSD: r.TotalCostUSD,
+			TotalTokens:  r.TotalTokens,
+			CallCount:    r.CallCount,
+		}
+	}
+	return result, nil
+}
+
+// GetFeatureBreakdown は機能別のコスト内訳を返す（userID が 0 なら全利用者）
+func (s *APICostService) GetFeatureBreakdown(since time.Time, userID uint) ([]FeatureCostSummary, error) {
+	rows, err := s.repo.FeatureBreakdown(since, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]FeatureCostSummary, len(rows))
+	for i, r := range rows {
+		result[i] = FeatureCostSummary{
+			Feature:      r.Feature,
+			TotalCostUSD: r.TotalCostUSD,
+			TotalTokens:  r.TotalTokens,
+			CallCount:    r.CallCount,
+		}
+	}
+	return result, nil
+}
+
+// UnpricedModelSummary 単価未登録のため料金を計上できなかったモデル
+type UnpricedModelSummary struct {
+	Model     string `json:"model"`
+	CallCount int64  `json:"call_count"`
+}
+
+// GetUnpricedModels は since 以降に単価未登録で記録された呼び出しをモデル別に返す
+func (s *APICostService) GetUnpricedModels(since time.Time) ([]UnpricedModelSummary, error) {
+	rows, err := s.repo.UnpricedModels(since)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]UnpricedModelSummary, len(rows))
+	for i, r := range rows {
+		result[i] = UnpricedModelSummary{Model: r.Model, CallCount: r.CallCount}
+	}
+	return result, nil
+}
+
 func (s *APICostService) GetCurrentMonthTotal() (float64, error) {
 	now := time.Now().UTC()
 	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
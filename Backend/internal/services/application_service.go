@@ -51,11 +51,18 @@ func (s *ApplicationService) Apply(userID, companyID, matchID uint) (*entity.Use
 		return nil, fmt.Errorf("この企業にはすでに応募済みです")
 	}
 
+	// マッチング結果の所有権確認
+	match, err := s.matchRepo.FindByID(matchID)
+	if err != nil || match == nil || match.UserID != userID {
+		return nil, fmt.Errorf("権限がありません")
+	}
+
 	now := time.Now()
 	app := &entity.UserApplicationStatus{
 		UserID:    userID,
 		CompanyID: companyID,
 		MatchID:   matchID,
+		SessionID: match.SessionID,
 		Status:    "applied",
 		AppliedAt: &now,
 	}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidGuestToken 統合元として指定されたゲストのトークンが無効
+var ErrInvalidGuestToken = errors.New("invalid guest token")
+
+// UpgradeGuest ゲストユーザーにメールアドレスとパスワードを設定して登録ユーザーにする。
+// ユーザーIDは変わらないため、チャット履歴・スコア・マッチング・面接などのデータはそのまま引き継がれる
+func (s *AuthService) UpgradeGuest(guestID uint, req RegisterRequest) (*AuthResponse, error) {
+	user, err := s.userRepo.GetUserByID(guestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.IsGuest {
+		return nil, errors.New("user is not a guest")
+	}
+	if err := s.validateRegisterRequest(&req); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.Email = req.Email
+	user.Password = string(hashedPassword)
+	if strings.TrimSpace(req.Name) != "" {
+		user.Name = req.Name
+	}
+	user.IsGuest = false
+	user.TargetLevel = req.TargetLevel
+	user.SchoolName = req.SchoolName
+	user.CertificationsAcquired = req.CertificationsAcquired
+	user.CertificationsInProgress = req.CertificationsInProgress
+	user.IsAdmin = isAdminIdentity(user.Email, user.Name)
+
+	// メール認証トークン生成
+	tokenBytes := make([]byte, 24)
+	rand.Read(tokenBytes)
+	user.EmailVerificationToken = base64.URLEncoding.EncodeToString(tokenBytes)
+
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	s.audit.Record(user.Email, "auth.guest_upgraded", "user", user.ID, map[string]interface{}{
+		"method": "password",
+	})
+
+	// 認証メール送信（失敗してもアップグレードは成功扱い）
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:3000"
+	}
+	go s.emailService.SendVerificationEmail(user, user.EmailVerificationToken, appURL)
+
+	// ゲストとして利用中のセッションはそのまま継続する
+	resp := &AuthResponse{
+		UserID:                   user.ID,
+		Email:                    user.Email,
+		Name:                     user.Name,
+		IsGuest:                  user.IsGuest,
+		TargetLevel:              user.TargetLevel,
+		SchoolName:               user.SchoolName,
+		IsAdmin:                  user.HasAdminRole(),
+		Role:                     user.EffectiveRole(),
+		CertificationsAcquired:   user.CertificationsAcquired,
+		CertificationsInProgress: user.CertificationsInProgress,
+		EmailVerified:            false,
+	}
+	if err := attachTokens(s.tokens, resp, false); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// MergeGuest ゲストとして作成したデータをログイン中の登録ユーザーに統合し、ゲストユーザーを削除する。
+// guestToken はゲストのアクセストークンまたはリフレッシュトークン（ゲストの所有者であることの証明）
+func (s *AuthService) MergeGuest(targetID uint, guestToken string) (uint, error) {
+	if s.tokens == nil {
+		return 0, errors.New("token service not configured")
+	}
+	claims, err := s.tokens.VerifyRefreshToken(guestToken)
+	if err != nil {
+		if claims, err = s.tokens.VerifyAccessToken(guestToken); err != nil {
+			return 0, ErrInvalidGuestToken
+		}
+	}
+
+	guest, err := s.userRepo.GetUserByID(claims.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	if guest == nil || !guest.IsGuest || guest.ID == targetID {
+		return 0, ErrInvalidGuestToken
+	}
+	target, err := s.userRepo.GetUserByID(targetID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	if target == nil {
+		return 0, errors.New("user not found")
+	}
+	if target.IsGuest {
+		return 0, errors.New("cannot merge into a guest user")
+	}
+
+	if err := mergeGuestInto(s.db, guest.ID, target.ID); err != nil {
+		return 0, fmt.Errorf("failed to merge guest data: %w", err)
+	}
+	s.audit.Record(target.Email, "auth.guest_merged", "user", target.ID, map[string]interface{}{
+		"guest_user_id": guest.ID,
+	})
+	return guest.ID, nil
+}
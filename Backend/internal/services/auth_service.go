@@ -3,7 +3,7 @@ package services
 import (
 	"Backend/domain/entity"
 	"Backend/domain/repository"
-	"Backend/internal/models"
+	"Backend/internal/secrets"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
@@ -16,56 +16,48 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrSchoolNameLocked 教員の学校名は管理者のみ変更できる
+var ErrSchoolNameLocked = errors.New("school_name of a teacher can only be changed by an administrator")
+
 type AuthService struct {
 	userRepo     repository.UserRepository
 	pendingRepo  repository.PendingRegistrationRepository
 	emailService *EmailService
+	tokens       *TokenService
 	db           *gorm.DB
+	attempts     repository.LoginAttemptRepository
+	audit        *AuditLogService
+	policy       LoginPolicy
+	envelope     *secrets.Envelope
 }
 
 func NewAuthService(userRepo repository.UserRepository, pendingRepo repository.PendingRegistrationRepository, emailService *EmailService) *AuthService {
-	return &AuthService{userRepo: userRepo, pendingRepo: pendingRepo, emailService: emailService}
+	return &AuthService{userRepo: userRepo, pendingRepo: pendingRepo, emailService: emailService, policy: LoginPolicyFromEnv()}
 }
 
-// SetDB はアカウント削除に使用する DB を設定する
+// SetDB はゲストのアカウント統合に使用する DB を設定する
 func (s *AuthService) SetDB(db *gorm.DB) {
 	s.db = db
 }
 
-// DeleteAccount ユーザーアカウントとその全データを削除する（個人情報保護法第28条対応）
-func (s *AuthService) DeleteAccount(userID uint) error {
-	if s.db == nil {
-		return errors.New("database not configured")
-	}
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		// チャット履歴・重みスコア
-		if err := tx.Where("user_id = ?", userID).Delete(&models.ChatMessage{}).Error; err != nil {
-			return err
-		}
-		if err := tx.Where("user_id = ?", userID).Delete(&models.UserWeightScore{}).Error; err != nil {
-			return err
-		}
-		// マッチング結果
-		if err := tx.Where("user_id = ?", userID).Delete(&models.UserCompanyMatch{}).Error; err != nil {
-			return err
-		}
-		// 面接セッション・発話・レポート・動画
-		var sessionIDs []uint
-		tx.Model(&models.InterviewSession{}).Where("user_id = ?", userID).Pluck("id", &sessionIDs)
-		if len(sessionIDs) > 0 {
-			tx.Where("session_id IN ?", sessionIDs).Delete(&models.InterviewUtterance{})
-			tx.Where("session_id IN ?", sessionIDs).Delete(&models.InterviewReport{})
-			tx.Where("session_id IN ?", sessionIDs).Delete(&models.InterviewVideo{})
-		}
-		if err := tx.Where("user_id = ?", userID).Delete(&models.InterviewSession{}).Error; err != nil {
-			return err
-		}
-		// ユーザー本体
-		if err := tx.Delete(&models.User{}, userID).Error; err != nil {
-			return err
-		}
-		return nil
-	})
+// SetTokenService はログイン時に発行するセッショントークンの署名サービスを設定する
+func (s *AuthService) SetTokenService(tokens *TokenService) {
+	s.tokens = tokens
+}
+
+// SetLoginAttemptRepository は IP 単位の試行制限に使うログイン試行履歴の保存先を設定する
+func (s *AuthService) SetLoginAttemptRepository(attempts repository.LoginAttemptRepository) {
+	s.attempts = attempts
+}
+
+// SetAuditLogService はログイン失敗・アカウントロックを記録する監査ログを設定する
+func (s *AuthService) SetAuditLogService(audit *AuditLogService) {
+	s.audit = audit
+}
+
+// SetLoginPolicy はログイン失敗時の遅延・ロックの閾値を差し替える
+func (s *AuthService) SetLoginPolicy(policy LoginPolicy) {
+	s.policy = policy
 }
 
 // RegisterRequest ユーザー登録リクエスト
@@ -84,6 +76,7 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	IP       string `json:"-"` // 試行制限に使うクライアント IP（コントローラーで設定）
 }
 
 // UpdateProfileRequest プロフィール更新リクエスト
@@ -98,20 +91,28 @@ type UpdateProfileRequest struct {
 
 // AuthResponse 認証レスポンス
 type AuthResponse struct {
-	UserID                   uint   `json:"user_id"`
-	Email                    string `json:"email"`
-	Name                     string `json:"name"`
-	IsGuest                  bool   `json:"is_guest"`
-	TargetLevel              string `json:"target_level"`
-	SchoolName               string `json:"school_name,omitempty"`
-	IsAdmin                  bool   `json:"is_admin"`
-	CertificationsAcquired   string `json:"certifications_acquired,omitempty"`
-	CertificationsInProgress string `json:"certifications_in_progress,omitempty"`
-	AvatarURL                string `json:"avatar_url,omitempty"`
-	OAuthProvider            string `json:"oauth_provider,omitempty"` // OAuth連携プロバイダ
-	Token                    string `json:"token,omitempty"`          // 将来的なトークン認証用
-	EmailVerified            bool   `json:"email_verified"`
-	RequiresReVerification   bool   `json:"requires_re_verification,omitempty"`
+	UserID                   uint       `json:"user_id"`
+	Email                    string     `json:"email"`
+	Name                     string     `json:"name"`
+	IsGuest                  bool       `json:"is_guest"`
+	TargetLevel              string     `json:"target_level"`
+	SchoolName               string     `json:"school_name,omitempty"`
+	IsAdmin                  bool       `json:"is_admin"`
+	Role                     string     `json:"role"` // student / teacher / admin
+	CertificationsAcquired   string     `json:"certifications_acquired,omitempty"`
+	CertificationsInProgress string     `json:"certifications_in_progress,omitempty"`
+	AvatarURL                string     `json:"avatar_url,omitempty"`
+	OAuthProvider            string     `json:"oauth_provider,omitempty"`          // OAuth連携プロバイダ
+	Token                    string     `json:"token,omitempty"`                   // アクセストークン（Authorization: Bearer で送信）
+	RefreshToken             string     `json:"refresh_token,omitempty"`           // アクセストークン再発行用
+	TokenExpiresAt           *time.Time `json:"token_expires_at,omitempty"`        // アクセストークン有効期限
+	MFARequired              bool       `json:"mfa_required,omitempty"`            // 2段階認証コードの入力が必要（トークンは未発行）
+	MFAToken                 string     `json:"mfa_token,omitempty"`               // POST /api/auth/login/mfa に渡す入力待ちトークン
+	MFAEnabled               bool       `json:"mfa_enabled"`                       // 2段階認証を設定済みか
+	MFAEnrollmentRequired    bool       `json:"mfa_enrollment_required,omitempty"` // 管理者・教員で 2段階認証が未設定
+	RecoveryCodes            []string   `json:"recovery_codes,omitempty"`          // 2段階認証の有効化時のみ返すリカバリーコード
+	EmailVerified            bool       `json:"email_verified"`
+	RequiresReVerification   bool       `json:"requires_re_verification,omitempty"`
 }
 
 // RequestRegistration メールアドレスに確認URLを送信して仮登録を作成
@@ -163,21 +164,21 @@ func (s *AuthService) ValidateRegistrationToken(token string) (string, error) {
 	return pending.Email, nil
 }
 
-// Register 新規ユーザー登録
-func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
+// validateRegisterRequest 登録内容を検証して既定値を補い、未登録のメールアドレスであることを確認する（新規登録・ゲストのアップグレード共通）
+func (s *AuthService) validateRegisterRequest(req *RegisterRequest) error {
 	// バリデーション
 	if req.Email == "" || req.Password == "" {
-		return nil, errors.New("email and password are required")
+		return errors.New("email and password are required")
 	}
 
 	// トークン検証
 	if req.RegistrationToken != "" {
 		pending, err := s.pendingRepo.FindByToken(req.RegistrationToken)
 		if err != nil {
-			return nil, fmt.Errorf("failed to validate token: %w", err)
+			return fmt.Errorf("failed to validate token: %w", err)
 		}
 		if pending == nil || pending.Email != req.Email {
-			return nil, errors.New("invalid or expired registration token")
+			return errors.New("invalid or expired registration token")
 		}
 		// 使用済みトークンを削除
 		_ = s.pendingRepo.DeleteByEmail(req.Email)
@@ -186,7 +187,7 @@ func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
 		req.TargetLevel = "新卒"
 	}
 	if req.TargetLevel != "新卒" && req.TargetLevel != "中途" {
-		return nil, errors.New("target_level must be '新卒' or '中途'")
+		return errors.New("target_level must be '新卒' or '中途'")
 	}
 	if strings.TrimSpace(req.SchoolName) == "" {
 		req.SchoolName = "学校法人岩崎学園情報科学専門学校"
@@ -195,10 +196,18 @@ func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
 	// 既存ユーザーチェック
 	existingUser, err := s.userRepo.GetUserByEmail(req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check existing user: %w", err)
+		return fmt.Errorf("failed to check existing user: %w", err)
 	}
 	if existingUser != nil {
-		return nil, errors.New("email already exists")
+		return errors.New("email already exists")
+	}
+	return nil
+}
+
+// Register 新規ユーザー登録
+func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
+	if err := s.validateRegisterRequest(&req); err != nil {
+		return nil, err
 	}
 
 	// パスワードハッシュ化
@@ -243,7 +252,8 @@ func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
 		IsGuest:                  user.IsGuest,
 		TargetLevel:              user.TargetLevel,
 		SchoolName:               user.SchoolName,
-		IsAdmin:                  user.IsAdmin,
+		IsAdmin:                  user.HasAdminRole(),
+		Role:                     user.EffectiveRole(),
 		CertificationsAcquired:   user.CertificationsAcquired,
 		CertificationsInProgress: user.CertificationsInProgress,
 		EmailVerified:            false,
@@ -257,12 +267,18 @@ func (s *AuthService) Login(req LoginRequest) (*AuthResponse, error) {
 		return nil, errors.New("email and password are required")
 	}
 
+	// IP 単位の試行制限
+	if err := s.checkIPThrottle(req.IP); err != nil {
+		return nil, err
+	}
+
 	// ユーザー取得
 	user, err := s.userRepo.GetUserByEmail(req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
+		s.recordLoginAttempt(0, req.Email, req.IP, false, "unknown_email")
 		return nil, errors.New("invalid email or password")
 	}
 
@@ -271,15 +287,24 @@ func (s *AuthService) Login(req LoginRequest) (*AuthResponse, error) {
 		return nil, errors.New("guest users cannot login")
 	}
 
+	// アカウントロック・段階的な待機時間
+	now := time.Now()
+	if err := s.checkAccountThrottle(user, now); err != nil {
+		s.recordLoginAttempt(user.ID, user.Email, req.IP, false, err.Error())
+		return nil, err
+	}
+
 	// パスワード検証
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		if lockErr := s.registerFailedLogin(user, req.IP, "invalid_password", now); lockErr != nil {
+			return nil, lockErr
+		}
 		return nil, errors.New("invalid email or password")
 	}
 	promoteAdminIfMatched(user, s.userRepo)
 
 	isOAuth := user.OAuthProvider != ""
 	emailVerified := user.EmailVerifiedAt != nil
-	requiresReVerification := false
 
 	if !isOAuth {
 		// メール認証チェック
@@ -299,30 +324,46 @@ func (s *AuthService) Login(req LoginRequest) (*AuthResponse, error) {
 				appURL = "http://localhost:3000"
 			}
 			go s.emailService.SendReVerificationEmail(user, user.EmailVerificationToken, appURL)
-			requiresReVerification = true
 			return nil, errors.New("re_verification_required")
 		}
 	}
 
-	// 最終ログイン更新
+	// 2段階認証が有効な場合はコード入力を求める（セッショントークンはコード検証後に発行）
+	if user.HasTOTP() {
+		return s.mfaChallenge(user)
+	}
+	s.recordLoginAttempt(user.ID, user.Email, req.IP, true, "")
+	return s.completeLogin(user, false)
+}
+
+// completeLogin ログイン失敗回数をリセットして最終ログイン日時を更新し、セッショントークンを発行する。
+// mfa は 2段階認証を経たログインかどうか
+func (s *AuthService) completeLogin(user *entity.User, mfa bool) (*AuthResponse, error) {
+	resetFailedLogins(user)
 	now := time.Now()
 	user.LastLoginAt = &now
 	s.userRepo.UpdateUser(user)
 
-	return &AuthResponse{
+	resp := &AuthResponse{
 		UserID:                   user.ID,
 		Email:                    user.Email,
 		Name:                     user.Name,
 		IsGuest:                  user.IsGuest,
 		TargetLevel:              user.TargetLevel,
 		SchoolName:               user.SchoolName,
-		IsAdmin:                  user.IsAdmin,
+		IsAdmin:                  user.HasAdminRole(),
+		Role:                     user.EffectiveRole(),
 		CertificationsAcquired:   user.CertificationsAcquired,
 		CertificationsInProgress: user.CertificationsInProgress,
 		AvatarURL:                user.AvatarURL,
-		EmailVerified:            emailVerified,
-		RequiresReVerification:   requiresReVerification,
-	}, nil
+		EmailVerified:            user.EmailVerifiedAt != nil,
+		MFAEnabled:               user.HasTOTP(),
+		MFAEnrollmentRequired:    user.RequiresMFA() && !user.HasTOTP(),
+	}
+	if err := attachTokens(s.tokens, resp, mfa); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // CreateGuestUser ゲストユーザー作成
@@ -347,18 +388,59 @@ func (s *AuthService) CreateGuestUser() (*AuthResponse, error) {
 		return nil, fmt.Errorf("failed to create guest user: %w", err)
 	}
 
-	return &AuthResponse{
+	resp := &AuthResponse{
 		UserID:                   user.ID,
 		Email:                    user.Email,
 		Name:                     user.Name,
 		IsGuest:                  user.IsGuest,
 		TargetLevel:              user.TargetLevel,
 		SchoolName:               user.SchoolName,
-		IsAdmin:                  user.IsAdmin,
+		IsAdmin:                  user.HasAdminRole(),
+		Role:                     user.EffectiveRole(),
 		CertificationsAcquired:   user.CertificationsAcquired,
 		CertificationsInProgress: user.CertificationsInProgress,
 		AvatarURL:                user.AvatarURL,
-	}, nil
+	}
+	if err := attachTokens(s.tokens, resp, false); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RefreshSession リフレッシュトークンを検証し、新しいトークンの組を発行する
+func (s *AuthService) RefreshSession(refreshToken string) (*AuthResponse, error) {
+	if s.tokens == nil {
+		return nil, errors.New("token service not configured")
+	}
+	claims, err := s.tokens.VerifyRefreshToken(refreshToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.userRepo.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.IsSessionRevoked(time.Unix(claims.IssuedAt, 0)) {
+		return nil, ErrInvalidToken
+	}
+	// ロック中は再発行しない（ロック解除後も管理者による解除の場合は上で無効になる）
+	now := time.Now()
+	if user.IsLocked(now) {
+		return nil, &LoginBlockedError{Code: LoginBlockedAccountLocked, RetryAfter: user.LockedUntil.Sub(now)}
+	}
+	resp, err := s.GetUser(claims.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	// 2段階認証済みのセッションは、発行後に 2段階認証を無効化・再設定していない場合のみ引き継ぐ
+	mfa := claims.MFA && user.HasTOTP() && !time.Unix(claims.IssuedAt, 0).Before(user.TOTPEnabledAt.Truncate(time.Second))
+	if err := attachTokens(s.tokens, resp, mfa); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // GetUser ユーザー情報取得
@@ -378,11 +460,14 @@ func (s *AuthService) GetUser(userID uint) (*AuthResponse, error) {
 		IsGuest:                  user.IsGuest,
 		TargetLevel:              user.TargetLevel,
 		SchoolName:               user.SchoolName,
-		IsAdmin:                  user.IsAdmin,
+		IsAdmin:                  user.HasAdminRole(),
+		Role:                     user.EffectiveRole(),
 		CertificationsAcquired:   user.CertificationsAcquired,
 		CertificationsInProgress: user.CertificationsInProgress,
 		AvatarURL:                user.AvatarURL,
 		OAuthProvider:            user.OAuthProvider,
+		MFAEnabled:               user.HasTOTP(),
+		MFAEnrollmentRequired:    user.RequiresMFA() && !user.HasTOTP(),
 	}, nil
 }
 
@@ -409,6 +494,10 @@ func (s *AuthService) UpdateProfile(req UpdateProfileRequest) (*AuthResponse, er
 	if req.TargetLevel != "" {
 		user.TargetLevel = req.TargetLevel
 	}
+	// 教員の学校名は担当できるクラスを決めるため、管理者のみ変更できる
+	if user.EffectiveRole() == entity.RoleTeacher && req.SchoolName != user.SchoolName {
+		return nil, ErrSchoolNameLocked
+	}
 	// Always persist the provided school name, even when it is an empty string.
 	user.SchoolName = req.SchoolName
 	user.CertificationsAcquired = req.CertificationsAcquired
@@ -425,7 +514,8 @@ func (s *AuthService) UpdateProfile(req UpdateProfileRequest) (*AuthResponse, er
 		IsGuest:                  user.IsGuest,
 		TargetLevel:              user.TargetLevel,
 		SchoolName:               user.SchoolName,
-		IsAdmin:                  user.IsAdmin,
+		IsAdmin:                  user.HasAdminRole(),
+		Role:                     user.EffectiveRole(),
 		CertificationsAcquired:   user.CertificationsAcquired,
 		CertificationsInProgress: user.CertificationsInProgress,
 		AvatarURL:                user.AvatarURL,
@@ -438,8 +528,8 @@ func (s *AuthService) RequestPasswordReset(email string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
-	// ユーザーが存在しない・OAuthユーザー・ゲストの場合でも成功を返す（情報漏洩防止）
-	if user == nil || user.OAuthProvider != "" || user.IsGuest {
+	// ユーザーが存在しない・パスワード未設定（OAuthのみ）・ゲストの場合でも成功を返す（情報漏洩防止）
+	if user == nil || user.Password == "" || user.IsGuest {
 		return nil
 	}
 
@@ -490,10 +580,58 @@ func (s *AuthService) ResetPassword(token, newPassword string) error {
 	user.Password = string(hashedPassword)
 	user.PasswordResetToken = ""
 	user.PasswordResetExpiresAt = nil
+	// リセット前に発行したセッションは無効にする
+	now := time.Now()
+	user.SessionsRevokedAt = &now
 
 	return s.userRepo.UpdateUser(user)
 }
 
+// SetPassword ログイン中のユーザーがパスワードを設定・変更する。
+// OAuth のみで登録したユーザーはパスワードを追加してメールアドレスでもログインできるようになる。
+// 既にパスワードがある場合は現在のパスワードの確認が必要
+func (s *AuthService) SetPassword(userID uint, currentPassword, newPassword string) error {
+	if len(newPassword) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if user.IsGuest {
+		return errors.New("guest users cannot set password")
+	}
+	hadPassword := user.Password != ""
+	if hadPassword {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+			return errors.New("current password is incorrect")
+		}
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = string(hashedPassword)
+	// OAuth で登録したユーザーのメールアドレスはプロバイダが確認済み
+	if user.EmailVerifiedAt == nil && user.HasOAuth() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	action := "auth.password_set"
+	if hadPassword {
+		action = "auth.password_changed"
+	}
+	s.audit.Record(user.Email, action, "user", user.ID, nil)
+	return nil
+}
+
 // VerifyEmail トークンを検証してメールを認証済みにする
 func (s *AuthService) VerifyEmail(token string) error {
 	if token == "" {
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"Backend/domain/entity"
+	"Backend/internal/secrets"
+	"Backend/internal/totp"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMFACode 認証コード・リカバリーコードが一致しない
+var ErrInvalidMFACode = errors.New("invalid verification code")
+
+const (
+	recoveryCodeCount = 10
+	defaultTOTPIssuer = "SOC AI Agent"
+	totpSkew          = 1 // 前後 30 秒の時計ずれを許容する
+)
+
+// TOTPEnrollment 認証アプリへの登録情報（有効化前）
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // QR コードにして表示する otpauth:// URI
+}
+
+// TOTPStatus 2段階認証の設定状況
+type TOTPStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	Required               bool       `json:"required"` // 管理者・教員は必須
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// SetEnvelope は TOTP シークレットの保存時暗号化を有効にする（未設定時は平文で保存）
+func (s *AuthService) SetEnvelope(envelope *secrets.Envelope) {
+	s.envelope = envelope
+}
+
+// mfaChallenge パスワード認証済みで 2段階認証コードの入力を待つレスポンスを返す
+func (s *AuthService) mfaChallenge(user *entity.User) (*AuthResponse, error) {
+	return mfaChallengeResponse(s.tokens, user)
+}
+
+// mfaChallengeResponse パスワード・外部アカウントでの認証後、2段階認証コードの入力を待つレスポンスを返す
+// （セッショントークンは POST /api/auth/login/mfa でコードを検証してから発行する）
+func mfaChallengeResponse(tokens *TokenService, user *entity.User) (*AuthResponse, error) {
+	if tokens == nil {
+		return nil, errors.New("token service not configured")
+	}
+	token, err := tokens.IssueMFAChallenge(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue mfa challenge: %w", err)
+	}
+	return &AuthResponse{
+		UserID:      user.ID,
+		Email:       user.Email,
+		MFARequired: true,
+		MFAToken:    token,
+		MFAEnabled:  true,
+	}, nil
+}
+
+// CompleteMFALogin パスワード認証後の入力待ちトークンと認証コード（またはリカバリーコード）でログインを完了する
+func (s *AuthService) CompleteMFALogin(mfaToken, code, ip string) (*AuthResponse, error) {
+	if s.tokens == nil {
+		return nil, errors.New("token service not configured")
+	}
+	claims, err := s.tokens.VerifyMFAChallenge(mfaToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.userRepo.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || !user.HasTOTP() {
+		return nil, ErrInvalidToken
+	}
+	if err := s.verifySecondFactor(user, code, ip); err != nil {
+		return nil, err
+	}
+	s.recordLoginAttempt(user.ID, user.Email, ip, true, "")
+	return s.completeLogin(user, true)
+}
+
+// VerifySessionMFA ログイン済みセッション（OAuth ログインなど）で認証コードを検証し、
+// 2段階認証済みのトークンを発行し直す
+func (s *AuthService) VerifySessionMFA(userID uint, code, ip string) (*AuthResponse, error) {
+	user, err := s.getUserForMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasTOTP() {
+		return nil, errors.New("totp is not enabled")
+	}
+	if err := s.verifySecondFactor(user, code, ip); err != nil {
+		return nil, err
+	}
+	return s.mfaSession(user.ID)
+}
+
+// mfaSession 2段階認証済みのトークンを付けたユーザー情報を返す
+func (s *AuthService) mfaSession(userID uint) (*AuthResponse, error) {
+	resp, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachTokens(s.tokens, resp, true); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// BeginTOTPEnrollment 新しいシークレットを発行する。ActivateTOTP でコードを確認するまで有効にならない
+func (s *AuthService) BeginTOTPEnrollment(userID uint) (*TOTPEnrollment, error) {
+	user, err := s.getUserForMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsGuest {
+		return nil, errors.New("guest users cannot enable totp")
+	}
+	if user.HasTOTP() {
+		return nil, errors.New("totp is already enabled")
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.sealTOTPSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = stored
+	user.TOTPLastCounter = 0
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+	return &TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(issuer, user.Email, secret),
+	}, nil
+}
+
+// ActivateTOTP 認証アプリのコードを確認して 2段階認証を有効化する。
+// リカバリーコード（この時だけ平文で返す）と 2段階認証済みのトークンを返す
+func (s *AuthService) ActivateTOTP(userID uint, code, ip string) (*AuthResponse, error) {
+	user, err := s.getUserForMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HasTOTP() {
+		return nil, errors.New("totp is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("totp enrollment has not been started")
+	}
+	counter, ok, err := s.validateTOTP(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	user.TOTPLastCounter = counter
+	user.TOTPRecoveryCodes = hashes
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	s.audit.Record(user.Email, "auth.mfa_enabled", "user", user.ID, map[string]interface{}{"ip": ip})
+
+	resp, err := s.mfaSession(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodes = codes
+	return resp, nil
+}
+
+// RegenerateRecoveryCodes 認証コードを確認してリカバリーコードを発行し直す（既存のコードは無効になる）
+func (s *AuthService) RegenerateRecoveryCodes(userID uint, code, ip string) ([]string, error) {
+	user, err := s.getUserForMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasTOTP() {
+		return nil, errors.New("totp is not enabled")
+	}
+	if err := s.verifySecondFactor(user, code, ip); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPRecoveryCodes = hashes
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	s.audit.Record(user.Email, "auth.mfa_recovery_codes_regenerated", "user", user.ID, map[string]interface{}{"ip": ip})
+	return codes, nil
+}
+
+// DisableTOTP 認証コードを確認して 2段階認証を無効化する（管理者・教員は必須のため無効化できない）
+func (s *AuthService) DisableTOTP(userID uint, code, ip string) error {
+	user, err := s.getUserForMFA(userID)
+	if err != nil {
+		return err
+	}
+	if !user.HasTOTP() {
+		return errors.New("totp is not enabled")
+	}
+	if user.RequiresMFA() {
+		return errors.New("totp is required for this role")
+	}
+	if err := s.verifySecondFactor(user, code, ip); err != nil {
+		return err
+	}
+	clearTOTP(user)
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	s.audit.Record(user.Email, "auth.mfa_disabled", "user", user.ID, map[string]interface{}{"ip": ip})
+	return nil
+}
+
+// ResetTOTP 端末とリカバリーコードを紛失した利用者のため、管理者が 2段階認証の設定を消去する。
+// 対象者は次回ログイン後に再登録する
+func (s *AuthService) ResetTOTP(userID uint, actorEmail string) (*entity.User, error) {
+	user, err := s.getUserForMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	clearTOTP(user)
+	// リセット前に発行したセッションは無効にする
+	now := time.Now()
+	user.SessionsRevokedAt = &now
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	s.audit.Record(actorEmail, "auth.mfa_reset", "user", user.ID, nil)
+	return user, nil
+}
+
+// GetTOTPStatus 2段階認証の設定状況
+func (s *AuthService) GetTOTPStatus(userID uint) (*TOTPStatus, error) {
+	user, err := s.getUserForMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &TOTPStatus{
+		Enabled:                user.HasTOTP(),
+		EnabledAt:              user.TOTPEnabledAt,
+		Required:               user.RequiresMFA(),
+		RecoveryCodesRemaining: len(decodeRecoveryHashes(user.TOTPRecoveryCodes)),
+	}, nil
+}
+
+func (s *AuthService) getUserForMFA(userID uint) (*entity.User, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// verifySecondFactor 6桁の認証コード、またはリカバリーコード（使用すると消費）を検証して保存する。
+// 失敗はログイン失敗として数え、閾値に達するとアカウントをロックする
+func (s *AuthService) verifySecondFactor(user *entity.User, code, ip string) error {
+	now := time.Now()
+	if err := s.checkAccountThrottle(user, now); err != nil {
+		s.recordLoginAttempt(user.ID, user.Email, ip, false, err.Error())
+		return err
+	}
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+
+	counter, ok, err := s.validateTOTP(user, code)
+	if err != nil {
+		return err
+	}
+	if ok {
+		user.TOTPLastCounter = counter
+	} else if remaining, used := consumeRecoveryCode(user.TOTPRecoveryCodes, code); used {
+		user.TOTPRecoveryCodes = remaining
+		s.audit.Record(user.Email, "auth.mfa_recovery_code_used", "user", user.ID, map[string]interface{}{
+			"ip":        ip,
+			"remaining": len(decodeRecoveryHashes(remaining)),
+		})
+	} else {
+		if lockErr := s.registerFailedLogin(user, ip, "invalid_mfa_code", now); lockErr != nil {
+			return lockErr
+		}
+		return ErrInvalidMFACode
+	}
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// validateTOTP 認証コードを検証する。受理済みのタイムステップ以前のコードは再利用として拒否する
+func (s *AuthService) validateTOTP(user *entity.User, code string) (int64, bool, error) {
+	secret, err := s.openTOTPSecret(user.TOTPSecret)
+	if err != nil {
+		return 0, false, err
+	}
+	counter, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok || counter <= user.TOTPLastCounter {
+		return 0, false, nil
+	}
+	return counter, true, nil
+}
+
+func (s *AuthService) sealTOTPSecret(secret string) (string, error) {
+	if s.envelope == nil {
+		return secret, nil
+	}
+	sealed, err := s.envelope.Encrypt(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt totp secret: %w", err)
+	}
+	return sealed, nil
+}
+
+func (s *AuthService) openTOTPSecret(stored string) (string, error) {
+	if s.envelope == nil {
+		if secrets.IsEncrypted(stored) {
+			return "", errors.New("token encryption is not configured")
+		}
+		return stored, nil
+	}
+	secret, err := s.envelope.Decrypt(stored)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+	return secret, nil
+}
+
+func clearTOTP(user *entity.User) {
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.TOTPLastCounter = 0
+	user.TOTPRecoveryCodes = ""
+}
+
+// generateRecoveryCodes xxxxx-xxxxx 形式のリカバリーコードと、保存用のハッシュ（JSON配列）を返す
+func generateRecoveryCodes() ([]string, string, error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, "", fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := strings.ToLower(enc.EncodeToString(b))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	encoded, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, "", err
+	}
+	return codes, string(encoded), nil
+}
+
+// hashRecoveryCode 十分なエントロピーがあるため、ソルトなしの SHA-256 で保存する
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(code, "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func decodeRecoveryHashes(stored string) []string {
+	var hashes []string
+	if stored == "" {
+		return hashes
+	}
+	_ = json.Unmarshal([]byte(stored), &hashes)
+	return hashes
+}
+
+// consumeRecoveryCode 一致するリカバリーコードを取り除いた残りを返す
+func consumeRecoveryCode(stored, code string) (string, bool) {
+	if code == "" {
+		return stored, false
+	}
+	target := hashRecoveryCode(code)
+	hashes := decodeRecoveryHashes(stored)
+	for i, h := range hashes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(target)) == 1 {
+			remaining := append(hashes[:i:i], hashes[i+1:]...)
+			encoded, err := json.Marshal(remaining)
+			if err != nil {
+				return stored, false
+			}
+			return string(encoded), true
+		}
+	}
+	return stored, false
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/internal/models"
 	"Backend/internal/services/prompts"
 	"context"
@@ -12,8 +13,10 @@ import (
 // checkAnswerValidity: 直近の assistant メッセージが質問かを判定し、ユーザー入力がその質問に対する有効な回答かを判定する。
 // 無効な場合はアシスタントの「書かれた内容にはお答えできません」メッセージを保存して true を返す。
 // 3回連続で無効な場合はセッションを強制終了する。
+// 入力ガードで受け付けなかった回答は AI に判定させずに無効とする。
+// 入力ガードが検出した回答は誤検出がありうるため、無効でも 3 回の回数に数えずに回答し直してもらう。
 // 戻り値: handled(bool) - true の場合は処理を終了してよい、response(string) - 保存したアシスタント応答（ある場合）、error
-func (s *ChatService) checkAnswerValidity(ctx context.Context, history []models.ChatMessage, userMessage string, userID uint, sessionID string) (bool, string, error) {
+func (s *ChatService) checkAnswerValidity(ctx context.Context, history []models.ChatMessage, userMessage string, guard *GuardResult, userID uint, sessionID string) (bool, string, error) {
 	// 直近の assistant メッセージを探す
 	var lastAssistant *models.ChatMessage
 	for i := len(history) - 1; i >= 0; i-- {
@@ -38,13 +41,16 @@ func (s *ChatService) checkAnswerValidity(ctx context.Context, history []models.
 	}
 
 	// ユーザー回答が質問に対する答えかどうか判定
-	isValid, err := s.validateAnswerRelevance(ctx, questionText, userMessage)
-	if err != nil {
+	isValid := false
+	if guard.Blocked() {
+		fmt.Printf("[Validation] Answer rejected by input guard for session: %s\n", sessionID)
+	} else if valid, err := s.validateAnswerRelevance(ctx, questionText, userMessage); err != nil {
 		// AI判定エラー時は基本的な検証のみ
 		fmt.Printf("[Validation] AI validation failed: %v, using basic validation\n", err)
 		isValid = isLikelyAnswer(userMessage, questionText)
 		fmt.Printf("[Validation] Basic validation result: %v for message: %s\n", isValid, userMessage)
 	} else {
+		isValid = valid
 		fmt.Printf("[Validation] AI validation result: %v for message: %s\n", isValid, userMessage)
 	}
 
@@ -57,24 +63,30 @@ func (s *ChatService) checkAnswerValidity(ctx context.Context, history []models.
 		return false, "", nil
 	}
 
-	// 無効な回答と判断 -> カウントをインクリメント
-	fmt.Printf("[Validation] Invalid answer detected for message: %s\n", userMessage)
-	validation, err := s.sessionValidationRepo.IncrementInvalidCount(sessionID)
-	if err != nil {
-		return true, "", fmt.Errorf("failed to increment invalid count: %w", err)
-	}
-	fmt.Printf("[Validation] Invalid count incremented to: %d/3\n", validation.InvalidAnswerCount)
-
 	var assistantText string
-	if validation.InvalidAnswerCount >= 3 {
-		// 3回目の無効回答 -> セッションを強制終了
-		if err := s.sessionValidationRepo.TerminateSession(sessionID); err != nil {
-			fmt.Printf("Warning: failed to terminate session: %v\n", err)
-		}
-		assistantText = "申し訳ございませんが、質問と関係のない内容が3回続いたため、チャットを終了させていただきます。新しいセッションで最初からやり直してください。"
+	if guard.Suspicious() {
+		// 入力ガードが検出した回答 -> カウントせずに回答し直してもらう
+		fmt.Printf("[Validation] Invalid answer flagged by input guard, not counted for session: %s\n", sessionID)
+		assistantText = InputRejectedMessage
 	} else {
-		// 1-2回目の無効回答 -> 警告メッセージ
-		assistantText = fmt.Sprintf("書かれた内容にはお答えできません。質問に回答してください。（%d/3回目の警告）", validation.InvalidAnswerCount)
+		// 無効な回答と判断 -> カウントをインクリメント
+		fmt.Printf("[Validation] Invalid answer detected for message: %s\n", userMessage)
+		validation, err := s.sessionValidationRepo.IncrementInvalidCount(sessionID)
+		if err != nil {
+			return true, "", fmt.Errorf("failed to increment invalid count: %w", err)
+		}
+		fmt.Printf("[Validation] Invalid count incremented to: %d/3\n", validation.InvalidAnswerCount)
+
+		if validation.InvalidAnswerCount >= 3 {
+			// 3回目の無効回答 -> セッションを強制終了
+			if err := s.sessionValidationRepo.TerminateSession(sessionID); err != nil {
+				fmt.Printf("Warning: failed to terminate session: %v\n", err)
+			}
+			assistantText = "申し訳ございませんが、質問と関係のない内容が3回続いたため、チャットを終了させていただきます。新しいセッションで最初からやり直してください。"
+		} else {
+			// 1-2回目の無効回答 -> 警告メッセージ
+			assistantText = fmt.Sprintf("書かれた内容にはお答えできません。質問に回答してください。（%d/3回目の警告）", validation.InvalidAnswerCount)
+		}
 	}
 
 	assistantMsg := &models.ChatMessage{
@@ -103,11 +115,13 @@ func (s *ChatService) validateAnswerRelevance(ctx context.Context, question, ans
 	// 選択肢型の質問: AI判定を使用
 	fmt.Printf("[Validation] Choice-based question detected, using AI validation\n")
 
-	systemPrompt := prompts.AnswerValidationSystemPrompt
-	userPrompt := prompts.BuildAnswerValidationUserPrompt(question, answer)
+	prompt, err := s.promptRegistry.Render(ctx, prompts.AnswerValidation, prompts.Vars{"Question": question, "Answer": answer})
+	if err != nil {
+		return false, err
+	}
 
 	// temperature=0で安定した判定を行う
-	response, err := s.aiClient.ResponsesWithTemperature(ctx, systemPrompt, userPrompt, 0.0)
+	response, err := s.aiClient.GenerateText(ctx, prompt.System, prompt.User, ai.WithTemperature(0.0))
 	if err != nil {
 		return false, fmt.Errorf("AI validation error: %w", err)
 	}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/entity"
 	"Backend/internal/models"
 	"context"
@@ -72,7 +73,7 @@ func (s *ChatService) ensureUserEmbedding(ctx context.Context, userID uint, sess
 	ctxReq, cancel := context.WithTimeout(ctx, 45*time.Second)
 	defer cancel()
 
-	vector, err := s.aiClient.Embedding(ctxReq, text)
+	vector, err := s.aiClient.GenerateEmbedding(ctxReq, text)
 	if err != nil {
 		return fmt.Errorf("create user embedding: %w", err)
 	}
@@ -85,6 +86,9 @@ func (s *ChatService) ensureUserEmbedding(ctx context.Context, userID uint, sess
 	return s.userEmbeddingRepo.Upsert(userID, sessionID, text, embeddingJSON)
 }
 
+// 職種の埋め込みは職種テキストだけで決まるため、同じテキストの再計算はキャッシュで省く
+const jobCategoryEmbeddingCacheTTL = 30 * 24 * time.Hour
+
 func (s *ChatService) ensureJobCategoryEmbedding(ctx context.Context, jobCategoryID uint) error {
 	if s.jobEmbeddingRepo == nil || s.jobCategoryRepo == nil {
 		return nil
@@ -111,10 +115,10 @@ func (s *ChatService) ensureJobCategoryEmbedding(ctx context.Context, jobCategor
 		return nil
 	}
 
-	ctxReq, cancel := context.WithTimeout(ctx, 45*time.Second)
+	ctxReq, cancel := context.WithTimeout(ai.WithCache(ctx, "job_category_embedding", jobCategoryEmbeddingCacheTTL), 45*time.Second)
 	defer cancel()
 
-	vector, err := s.aiClient.Embedding(ctxReq, text)
+	vector, err := s.aiClient.GenerateEmbedding(ctxReq, text)
 	if err != nil {
 		return fmt.Errorf("create job category embedding: %w", err)
 	}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"Backend/domain/entity"
+	"context"
+	"fmt"
+)
+
+// sessionVariantsKey ctx に載せるセッションのバリアント設定のキー
+type sessionVariantsKey struct{}
+
+// withSessionVariants はセッションに割り当てたバリアントの設定を ctx に載せる
+func withSessionVariants(ctx context.Context, variants *SessionVariants) context.Context {
+	if variants == nil {
+		return ctx
+	}
+	return variants.PromptContext(context.WithValue(ctx, sessionVariantsKey{}, variants))
+}
+
+// sessionVariantsFrom は ctx のバリアント設定を返す（nil なら既定の挙動）
+func sessionVariantsFrom(ctx context.Context) *SessionVariants {
+	variants, _ := ctx.Value(sessionVariantsKey{}).(*SessionVariants)
+	return variants
+}
+
+// assignExperiments はセッション開始時に実施中の実験のバリアントを割り当てる
+func (s *ChatService) assignExperiments(userID uint, sessionID string) *SessionVariants {
+	if s.experiments == nil {
+		return nil
+	}
+	assignments, err := s.experiments.AssignActiveExperiments(userID, sessionID)
+	if err != nil {
+		fmt.Printf("Warning: failed to assign experiments: %v\n", err)
+	}
+	for _, a := range assignments {
+		fmt.Printf("[Experiment] session %s assigned to %s/%s\n", sessionID, a.ExperimentName, a.AssignedVariant)
+	}
+	return NewSessionVariants(assignments)
+}
+
+// sessionPhases は全フェーズにセッションのバリアントの質問数を当てはめて返す
+func (s *ChatService) sessionPhases(ctx context.Context) ([]entity.AnalysisPhase, error) {
+	phases, err := s.phaseRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return sessionVariantsFrom(ctx).ApplyPhases(phases), nil
+}
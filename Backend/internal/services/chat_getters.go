@@ -1,8 +1,10 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/entity"
 	"Backend/internal/models"
+	"Backend/internal/services/prompts"
 	"context"
 	"errors"
 	"fmt"
@@ -15,6 +17,32 @@ func (s *ChatService) GetChatHistory(sessionID string) ([]models.ChatMessage, er
 	return s.chatMessageRepo.FindBySessionID(sessionID)
 }
 
+// GetChatHistoryForUser 呼び出し元ユーザーが所有するセッションのチャット履歴を取得
+func (s *ChatService) GetChatHistoryForUser(userID uint, sessionID string) ([]models.ChatMessage, error) {
+	history, err := s.chatMessageRepo.FindBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range history {
+		if msg.UserID != userID {
+			return nil, errors.New("forbidden")
+		}
+	}
+	return history, nil
+}
+
+// ensureSessionOwner 既存セッションが別ユーザーのものであれば forbidden を返す
+func (s *ChatService) ensureSessionOwner(userID uint, sessionID string) error {
+	recent, err := s.chatMessageRepo.FindRecentBySessionID(sessionID, 1)
+	if err != nil {
+		return fmt.Errorf("failed to get chat history: %w", err)
+	}
+	if len(recent) > 0 && recent[0].UserID != userID {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
 // GetUserScores ユーザーのスコアを取得
 func (s *ChatService) GetUserScores(userID uint, sessionID string) ([]entity.UserWeightScore, error) {
 	return s.userWeightScoreRepo.FindByUserAndSession(userID, sessionID)
@@ -31,15 +59,19 @@ func (s *ChatService) GetUserChatSessions(userID uint) ([]models.ChatSession, er
 }
 
 // aiCallWithRetries AI呼び出しをリトライして安定化させる（最大3回）
-func (s *ChatService) aiCallWithRetries(ctx context.Context, prompt string) (string, error) {
+func (s *ChatService) aiCallWithRetries(ctx context.Context, prompt *prompts.Rendered) (string, error) {
 	var resp string
 	var err error
 	backoffs := []time.Duration{500 * time.Millisecond, 1 * time.Second, 2 * time.Second}
 	for i := 0; i < len(backoffs); i++ {
-		resp, err = s.aiClient.Responses(ctx, prompt)
+		resp, err = s.aiClient.GenerateText(withQuestionAttempt(ctx, i+1), prompt.System, prompt.User)
 		if err == nil && strings.TrimSpace(resp) != "" {
 			return resp, nil
 		}
+		if errors.Is(err, ai.ErrBudgetExceeded) {
+			// 予算超過は再試行しても結果が変わらない
+			return "", err
+		}
 		if err == nil {
 			err = errors.New("empty response")
 		}
@@ -56,7 +88,7 @@ func (s *ChatService) aiCallWithRetries(ctx context.Context, prompt string) (str
 		}
 	}
 	// last attempt with final call (no extra wait)
-	resp, err = s.aiClient.Responses(ctx, prompt)
+	resp, err = s.aiClient.GenerateText(withQuestionAttempt(ctx, len(backoffs)+1), prompt.System, prompt.User)
 	if err != nil {
 		return "", err
 	}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"Backend/internal/models"
+	"Backend/internal/services/prompts"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,7 +10,7 @@ import (
 	"time"
 )
 
-func (s *ChatService) completeJobAnalysisPhase(userID uint, sessionID string) error {
+func (s *ChatService) completeJobAnalysisPhase(ctx context.Context, userID uint, sessionID string) error {
 	if s.phaseRepo == nil || s.progressRepo == nil {
 		return nil
 	}
@@ -17,6 +18,8 @@ func (s *ChatService) completeJobAnalysisPhase(userID uint, sessionID string) er
 	if err != nil || phase == nil {
 		return nil
 	}
+	adjusted := sessionVariantsFrom(ctx).ApplyPhase(*phase)
+	phase = &adjusted
 	progress, err := s.progressRepo.FindOrCreate(userID, sessionID, phase.ID)
 	if err != nil {
 		return err
@@ -110,42 +113,18 @@ func (s *ChatService) evaluateJobFitScoreWithAI(ctx context.Context, jobCategory
 		questionType = "選択肢"
 	}
 
-	prompt := fmt.Sprintf(`あなたは就職適性診断の採点者です。以下のルールに従って採点してください。
-
-## 職種
-%s (%s)
-
-## 質問（%s）
-%s
-
-## 回答
-%s
-
-## 職種理解キーワード
-- 必須キーワード: %s
-- 関連キーワード: %s
-
-## 採点ルール
-### 選択肢問題
-- 回答が職種に最も適している場合: 90〜100点
-- 適しても不適切でもない場合: 40〜70点
-- 全く適していない場合: 0〜20点
-
-### 文章問題
-- 必須キーワードがすべて含まれる場合: 90〜100点
-- 1語以上含まれる場合: 含まれた語数に応じて加点（1語=10点、最大80点）
-- 1語も含まれない場合: 0点
-
-## 出力形式（JSONのみ）
-{"score": 0, "reason": "理由", "matched_keywords": ["キーワード"]}`,
-		jobName,
-		jobCode,
-		questionType,
-		question,
-		answer,
-		strings.Join(coreKeywords, ", "),
-		strings.Join(relatedKeywords, ", "),
-	)
+	prompt, err := s.promptRegistry.Render(ctx, prompts.JobFitScoring, prompts.Vars{
+		"JobName":         jobName,
+		"JobCode":         jobCode,
+		"QuestionType":    questionType,
+		"Question":        question,
+		"Answer":          answer,
+		"CoreKeywords":    strings.Join(coreKeywords, ", "),
+		"RelatedKeywords": strings.Join(relatedKeywords, ", "),
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	response, err := s.aiCallWithRetries(ctx, prompt)
 	if err != nil {
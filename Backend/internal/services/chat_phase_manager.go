@@ -10,7 +10,7 @@ import (
 
 // getCurrentOrNextPhase 現在のフェーズを取得または次のフェーズを開始
 func (s *ChatService) getCurrentOrNextPhase(ctx context.Context, userID uint, sessionID string) (*entity.UserAnalysisProgress, error) {
-	allPhases, err := s.phaseRepo.FindAll()
+	allPhases, err := s.sessionPhases(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -21,19 +21,22 @@ func (s *ChatService) getCurrentOrNextPhase(ctx context.Context, userID uint, se
 		progressMap[progresses[i].PhaseID] = &progresses[i]
 	}
 
-	// 次の未完了フェーズを見つける
+	// 次の未完了フェーズを見つける（質問数はバリアントで変えたものを使う）
 	for _, phase := range allPhases {
+		phaseCopy := phase
 		if progress, exists := progressMap[phase.ID]; exists {
-			if progress.Phase == nil {
-				phaseCopy := phase
-				progress.Phase = &phaseCopy
-			}
+			progress.Phase = &phaseCopy
 			if isPhaseComplete(progress.ValidAnswers, progress.Phase) {
 				continue
 			}
 			return progress, nil
 		}
-		return s.progressRepo.FindOrCreate(userID, sessionID, phase.ID)
+		progress, err := s.progressRepo.FindOrCreate(userID, sessionID, phase.ID)
+		if err != nil {
+			return nil, err
+		}
+		progress.Phase = &phaseCopy
+		return progress, nil
 	}
 
 	// 全フェーズ完了
@@ -74,9 +77,9 @@ func (s *ChatService) updatePhaseProgress(progress *entity.UserAnalysisProgress,
 }
 
 // buildPhaseProgressResponse フェーズ進捗レスポンスを構築
-func (s *ChatService) buildPhaseProgressResponse(userID uint, sessionID string) ([]PhaseProgress, *PhaseProgress, error) {
+func (s *ChatService) buildPhaseProgressResponse(ctx context.Context, userID uint, sessionID string) ([]PhaseProgress, *PhaseProgress, error) {
 	progresses, _ := s.progressRepo.FindByUserAndSession(userID, sessionID)
-	allPhases, err := s.phaseRepo.FindAll()
+	allPhases, err := s.sessionPhases(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
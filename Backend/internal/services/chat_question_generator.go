@@ -13,6 +13,9 @@ import (
 func (s *ChatService) handleSessionStart(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
 	fmt.Printf("Starting new session: %s\n", req.SessionID)
 
+	// 実施中の A/B テストのバリアントを割り当て、初回の質問から反映する
+	ctx = withSessionVariants(ctx, s.assignExperiments(req.UserID, req.SessionID))
+
 	// ユーザー情報を取得
 	user, err := s.userRepo.GetUserByID(req.UserID)
 	userName := "あなた"
@@ -71,17 +74,7 @@ func (s *ChatService) generateStrategicQuestion(ctx context.Context, history []m
 	}
 
 	// 既に聞いた質問のリスト（重複防止を徹底）
-	askedQuestionsText := "\n## 【重要】既に聞いた質問（絶対に重複させないこと）\n"
-	if len(askedTexts) == 0 {
-		askedQuestionsText += "（まだ質問していません）\n"
-	} else {
-		questionCount := 0
-		for text := range askedTexts {
-			questionCount++
-			askedQuestionsText += fmt.Sprintf("%d. %s\n", questionCount, text)
-		}
-		askedQuestionsText += fmt.Sprintf("\n**上記%d個の質問と類似・重複する質問は絶対に生成しないでください**\n", questionCount)
-	}
+	askedQuestionsText := numberedQuestions(askedTexts)
 
 	phaseCategories := map[string][]string{
 		"job_analysis":      {"技術志向", "創造性志向", "成長志向", "安定志向"},
@@ -100,7 +93,7 @@ func (s *ChatService) generateStrategicQuestion(ctx context.Context, history []m
 	}
 
 	// スコア状況の分析（フェーズ対象カテゴリのみ）
-	scoreAnalysis := "## 現在の評価状況\n"
+	scoreAnalysis := ""
 	evaluatedCategories := []string{}
 	unevaluatedCategories := []string{}
 
@@ -181,50 +174,19 @@ func (s *ChatService) generateStrategicQuestion(ctx context.Context, history []m
 		"ワークライフバランス": "仕事と私生活のバランス観 → ワークライフバランス重視企業か成果主義企業か",
 	}
 
-	// フェーズ情報を追加
-	phaseContext := ""
+	// フェーズの進み具合と、フェーズに応じた質問形式の方針はテンプレート側で組み立てる
+	phaseVars := prompts.Vars{"PhaseDisplayName": "", "PhaseDescription": "", "PhaseMinQuestions": 0, "PhaseMaxQuestions": 0, "QuestionNumber": 0}
 	if currentPhase != nil && currentPhase.Phase != nil {
-		if currentPhase.Phase.MaxQuestions > 0 {
-			phaseContext = fmt.Sprintf(`
-## 現在の分析フェーズ: %s
-%s
-このフェーズでは%dつ〜%dつの質問を行います。現在%d個目の質問です。
-フェーズの目的に沿った質問を生成してください。
-`, currentPhase.Phase.DisplayName, currentPhase.Phase.Description,
-				currentPhase.Phase.MinQuestions, currentPhase.Phase.MaxQuestions,
-				currentPhase.QuestionsAsked+1)
-		} else {
-			phaseContext = fmt.Sprintf(`
-## 現在の分析フェーズ: %s
-%s
-このフェーズでは最低%dつの質問を行います。現在%d個目の質問です。
-フェーズの目的に沿った質問を生成してください。
-`, currentPhase.Phase.DisplayName, currentPhase.Phase.Description,
-				currentPhase.Phase.MinQuestions,
-				currentPhase.QuestionsAsked+1)
+		phaseVars = prompts.Vars{
+			"PhaseDisplayName":  currentPhase.Phase.DisplayName,
+			"PhaseDescription":  currentPhase.Phase.Description,
+			"PhaseMinQuestions": currentPhase.Phase.MinQuestions,
+			"PhaseMaxQuestions": currentPhase.Phase.MaxQuestions,
+			"QuestionNumber":    currentPhase.QuestionsAsked + 1,
 		}
 	}
-	choiceGuidance := ""
 	// phaseName はフェーズカテゴリ選定で取得済み
 	forceTextQuestion := shouldForceTextQuestion(history, currentPhase)
-	if phaseName != "" {
-		switch phaseName {
-		case "job_analysis":
-			choiceGuidance = "- 職種分析では選択肢中心で質問を構成する\n- 4〜5択で興味や方向性を選ばせ、最後に「その他（自由記述）」を用意する\n- 選択肢は必ず「A)」「B)」または「1)」「2)」形式で改行区切りで列挙する\n- 出力は『質問文 + 選択肢列挙』の形式とし、選択肢がない質問は不可\n- 文章でないと判定できない場合のみ自由記述にする（その場合も「その他（自由記述）」として選択肢に含める）"
-		case "interest_analysis":
-			choiceGuidance = "- 興味分析では選択肢中心で質問を構成する\n- 可能な限り4〜5択で提示し、最後に「その他（自由記述）」を用意する\n- 選択肢は必ず「A)」「B)」または「1)」「2)」形式で改行区切りで列挙する\n- 出力は『質問文 + 選択肢列挙』の形式とし、選択肢がない質問は不可\n- 文章必須の深掘りが必要な場合のみ自由記述にする（その場合も「その他（自由記述）」として選択肢に含める）"
-		case "aptitude_analysis":
-			choiceGuidance = "- 適性分析では選択肢中心で質問を構成する\n- 4〜5択で具体的な行動や傾向を選ばせる\n- 選択肢は必ず「A)」「B)」または「1)」「2)」形式で改行区切りで列挙する\n- 出力は『質問文 + 選択肢列挙』の形式とし、選択肢がない質問は不可\n- 文章でないと判定できない場合のみ自由記述にする（その場合も「その他（自由記述）」として選択肢に含める）"
-		case "future_analysis":
-			choiceGuidance = "- 将来分析（待遇・働き方の希望を含む）では選択肢中心で質問を構成する\n- 4〜5択で希望や優先順位を選ばせ、最後に「その他（自由記述）」を用意する\n- 選択肢は必ず「A)」「B)」または「1)」「2)」形式で改行区切りで列挙する\n- 出力は『質問文 + 選択肢列挙』の形式とし、選択肢がない質問は不可\n- 理由や背景が必要な場合のみ自由記述にする（その場合も「その他（自由記述）」として選択肢に含める）"
-		}
-	}
-	if forceTextQuestion {
-		choiceGuidance = "- このフェーズでは最低限の自由記述質問が必要です\n- 今回は必ず自由記述で質問を作成する\n- 選択肢は出さない"
-	}
-	if choiceGuidance != "" {
-		choiceGuidance = fmt.Sprintf("## 質問形式の方針\n%s\n", choiceGuidance)
-	}
 
 	if strings.TrimSpace(targetLevel) == "" {
 		targetLevel = "新卒"
@@ -237,14 +199,38 @@ func (s *ChatService) generateStrategicQuestion(ctx context.Context, history []m
 		description = categoryDescriptionsMid[targetCategory]
 	}
 
-	prompt := prompts.BuildStrategicQuestionPromptWithPhase(
-		targetLevel, phaseName, phaseContext, choiceGuidance,
-		historyText, scoreAnalysis, askedQuestionsText,
-		questionPurpose, targetCategory, description,
-		jobCategoryName, industryID, jobCategoryID,
-	)
+	// カテゴリの AI 質問テンプレートがあれば、その指示と文脈を添える
+	questionTemplate := s.questionRules.QuestionTemplate(targetCategory)
+	if questionTemplate != nil {
+		values := questionTemplateContext(history, scoreAnalysis, phaseName, targetLevel, jobCategoryName, industryID, jobCategoryID)
+		description = strings.TrimSpace(description + "\n\n" + RenderQuestionTemplate(*questionTemplate, values))
+	}
+
+	vars := prompts.Vars{
+		"MidCareer":         targetLevel == "中途",
+		"Phase":             phaseName,
+		"ForceTextQuestion": forceTextQuestion,
+		"History":           historyText,
+		"Scores":            strings.TrimSpace(scoreAnalysis),
+		"AskedQuestions":    askedQuestionsText,
+		"AskedCount":        len(askedTexts),
+		"Purpose":           questionPurpose,
+		"Category":          targetCategory,
+		"Description":       description,
+		"JobCategoryName":   jobCategoryName,
+		"IndustryID":        industryID,
+		"JobCategoryID":     jobCategoryID,
+	}
+	for k, v := range phaseVars {
+		vars[k] = v
+	}
+	prompt, err := s.promptRegistry.Render(ctx, prompts.QuestionStrategic, vars)
+	if err != nil {
+		return "", 0, err
+	}
 
-	questionText, err := s.aiCallWithRetries(ctx, prompt)
+	// ストリーミング中は生成途中の質問をプレビューとして送る（後段の整形・再生成の結果は question イベントで確定する）
+	questionText, err := s.aiCallWithRetries(withQuestionStream(ctx), prompt)
 	if err != nil {
 		return "", 0, err
 	}
@@ -266,13 +252,10 @@ func (s *ChatService) generateStrategicQuestion(ctx context.Context, history []m
 	// 選択肢必須フェーズで選択肢がない場合は再生成
 	if requiresChoice && isTextBasedQuestion(questionText) {
 		for attempt := 0; attempt < 2; attempt++ {
-			choicePrompt := fmt.Sprintf(`以下の質問は選択肢が不足しています。
-"%s"
-
-必ず4〜5個の選択肢を「A)」「B)」「C)」「D)」「E)」または「1)」「2)」「3)」「4)」「5)」形式で改行区切りで列挙し、最後に「その他（自由記述）」を含めてください。
-
-質問文は1つのみ。説明は不要です。質問文の後に選択肢を列挙してください。`, questionText)
-
+			choicePrompt, err := s.promptRegistry.Render(ctx, prompts.QuestionAddChoices, prompts.Vars{"Question": questionText})
+			if err != nil {
+				break
+			}
 			regenerated, err := s.aiCallWithRetries(ctx, choicePrompt)
 			if err != nil {
 				break
@@ -320,26 +303,14 @@ func (s *ChatService) generateStrategicQuestion(ctx context.Context, history []m
 		fmt.Printf("Retry %d: Duplicate detected (%s)\n", attempt+1, duplicateReason)
 
 		// 再生成プロンプト
-		retryPrompt := fmt.Sprintf(`以下の質問は既に聞いているか類似しています：
-"%s"
-
-既に聞いた全ての質問：
-%s
-
-これらと完全に異なる新しい質問を生成してください。
-対象カテゴリ: %s
-**質問のみ**を返してください。説明は不要です。`,
-			questionText,
-			func() string {
-				var list string
-				count := 0
-				for q := range askedTexts {
-					count++
-					list += fmt.Sprintf("%d. %s\n", count, q)
-				}
-				return list
-			}(),
-			targetCategory)
+		retryPrompt, err := s.promptRegistry.Render(ctx, prompts.QuestionRegenerate, prompts.Vars{
+			"Question":       questionText,
+			"AskedQuestions": askedQuestionsText,
+			"Category":       targetCategory,
+		})
+		if err != nil {
+			return "", 0, err
+		}
 
 		questionText, err = s.aiCallWithRetries(ctx, retryPrompt)
 		if err != nil {
@@ -364,12 +335,16 @@ func (s *ChatService) generateStrategicQuestion(ctx context.Context, history []m
 	aiGenQuestion := &models.AIGeneratedQuestion{
 		UserID:       userID,
 		SessionID:    sessionID,
-		TemplateID:   nil, // AI生成の場合はNULL
+		TemplateID:   nil, // テンプレートを使わない場合はNULL（下で設定）
 		QuestionText: questionText,
 		Weight:       7, // 戦略的質問は重み高め
 		IsAnswered:   false,
 		ContextData:  fmt.Sprintf(`{"target_category": "%s", "purpose": "%s"}`, targetCategory, questionPurpose),
 	}
+	if questionTemplate != nil {
+		aiGenQuestion.TemplateID = &questionTemplate.ID
+		aiGenQuestion.Weight = questionTemplate.BaseWeight
+	}
 
 	if err := s.aiGeneratedQuestionRepo.Create(aiGenQuestion); err != nil {
 		return "", 0, fmt.Errorf("failed to save AI generated question: %w", err)
@@ -428,10 +403,15 @@ func (s *ChatService) generateQuestionWithAI(ctx context.Context, history []mode
 		}
 	}
 
-	var prompt string
+	var prompt *prompts.Rendered
 	if hasLowConfidenceAnswer {
 		// わからない回答の場合は、同じカテゴリで別の角度から質問
-		prompt = prompts.BuildLowConfidenceQuestionPrompt(historyText, lastQuestion, industryID, jobCategoryID)
+		prompt, err = s.promptRegistry.Render(ctx, prompts.QuestionLowConfidence, prompts.Vars{
+			"History":       historyText,
+			"LastQuestion":  lastQuestion,
+			"IndustryID":    industryID,
+			"JobCategoryID": jobCategoryID,
+		})
 	} else if len(unevaluatedCategories) > 0 {
 		// 未評価のカテゴリがある場合は、それを重点的に評価
 		targetCategory := unevaluatedCategories[0]
@@ -449,7 +429,13 @@ func (s *ChatService) generateQuestionWithAI(ctx context.Context, history []mode
 			"ワークライフバランス": "仕事と私生活のバランス観",
 		}
 		description := categoryDescriptions[targetCategory]
-		prompt = prompts.BuildUnevaluatedCategoryQuestionPrompt(historyText, targetCategory, description, industryID, jobCategoryID)
+		prompt, err = s.promptRegistry.Render(ctx, prompts.QuestionUnevaluatedCategory, prompts.Vars{
+			"History":       historyText,
+			"Category":      targetCategory,
+			"Description":   description,
+			"IndustryID":    industryID,
+			"JobCategoryID": jobCategoryID,
+		})
 	} else {
 		// 全カテゴリ評価済みの場合は、深掘り質問
 		var highestCategory string
@@ -460,7 +446,16 @@ func (s *ChatService) generateQuestionWithAI(ctx context.Context, history []mode
 				highestCategory = cat
 			}
 		}
-		prompt = prompts.BuildDeepeningQuestionPrompt(historyText, highestCategory, highestScore, industryID, jobCategoryID)
+		prompt, err = s.promptRegistry.Render(ctx, prompts.QuestionDeepening, prompts.Vars{
+			"History":       historyText,
+			"Category":      highestCategory,
+			"Score":         highestScore,
+			"IndustryID":    industryID,
+			"JobCategoryID": jobCategoryID,
+		})
+	}
+	if err != nil {
+		return "", 0, err
 	}
 
 	questionText, err := s.aiCallWithRetries(ctx, prompt)
@@ -785,13 +780,17 @@ func (s *ChatService) getCategoryOrder(jobCategoryID uint) []string {
 }
 
 func (s *ChatService) simplifyQuestionWithAI(ctx context.Context, question string) (string, error) {
-	prompt := prompts.BuildSimplifyQuestionPrompt(question)
+	prompt, err := s.promptRegistry.Render(ctx, prompts.QuestionSimplify, prompts.Vars{"Question": question})
+	if err != nil {
+		return "", err
+	}
 	return s.aiCallWithRetries(ctx, prompt)
 }
 
-func (s *ChatService) tryGetPredefinedQuestion(userID uint, sessionID string, prioritizeCategory string, industryID, jobCategoryID uint, targetLevel string, askedTexts map[string]bool, currentPhase string) (*models.PredefinedQuestion, error) {
-	if jobCategoryID == 0 {
-		// 職種未決定の場合はAI質問に任せる
+func (s *ChatService) tryGetPredefinedQuestion(ctx context.Context, userID uint, sessionID string, prioritizeCategory string, industryID, jobCategoryID uint, targetLevel string, askedTexts map[string]bool, currentPhase string) (*models.PredefinedQuestion, error) {
+	variants := sessionVariantsFrom(ctx)
+	if jobCategoryID == 0 || variants.PredefinedQuestionsDisabled() {
+		// 職種未決定の場合・バリアントで事前定義質問を使わない場合はAI質問に任せる
 		return nil, nil
 	}
 	if strings.TrimSpace(targetLevel) == "" {
@@ -804,12 +803,15 @@ func (s *ChatService) tryGetPredefinedQuestion(userID uint, sessionID string, pr
 		return nil, err
 	}
 
-	// 職種に合う質問のみ残す（汎用質問はAIに任せる）
+	// 職種に合う質問のみ残す（汎用質問はAIに任せる）。バリアントの質問プールにない質問も除く
 	jobSpecificQuestions := make([]*models.PredefinedQuestion, 0, len(allQuestions))
 	for _, q := range allQuestions {
 		if q.JobCategoryID == nil || *q.JobCategoryID != jobCategoryID {
 			continue
 		}
+		if !variants.AllowsPredefinedQuestion(q.ID) {
+			continue
+		}
 		jobSpecificQuestions = append(jobSpecificQuestions, q)
 	}
 
@@ -861,3 +863,14 @@ func (s *ChatService) shouldValidateJobCategory(history []models.ChatMessage) bo
 	}
 	return s.isJobSelectionQuestion(lastAssistant)
 }
+
+// numberedQuestions 既に聞いた質問を番号付きの一覧にする（まだなければ空）
+func numberedQuestions(askedTexts map[string]bool) string {
+	var b strings.Builder
+	count := 0
+	for text := range askedTexts {
+		count++
+		fmt.Fprintf(&b, "%d. %s\n", count, text)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"Backend/domain/entity"
+	"Backend/internal/models"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// weightRulesKey ctx に載せる加点ルールと判定に使う利用者・フェーズの情報のキー
+type weightRulesKey struct{}
+
+type weightRuleScope struct {
+	engine *WeightRuleEngine
+	facts  WeightRuleFacts
+}
+
+// withWeightRules は有効な加点ルールと、判定に使う利用者の属性・フェーズを ctx に載せる（ルールがなければそのまま）
+func (s *ChatService) withWeightRules(ctx context.Context, userID, industryID, jobCategoryID uint, currentPhase *entity.UserAnalysisProgress) context.Context {
+	engine := s.questionRules.ActiveWeightRules()
+	if engine == nil {
+		return ctx
+	}
+	facts := WeightRuleFacts{
+		TargetLevel:   s.getUserTargetLevel(userID),
+		IndustryID:    industryID,
+		JobCategoryID: jobCategoryID,
+	}
+	if currentPhase != nil && currentPhase.Phase != nil {
+		facts.Phase = currentPhase.Phase.PhaseName
+	}
+	return context.WithValue(ctx, weightRulesKey{}, &weightRuleScope{engine: engine, facts: facts})
+}
+
+// applyWeightRules は回答で発火した加点ルールの加点を回答のスコアに足す
+func (s *ChatService) applyWeightRules(ctx context.Context, category, answer string, score int) int {
+	scope, _ := ctx.Value(weightRulesKey{}).(*weightRuleScope)
+	if scope == nil {
+		return score
+	}
+	facts := scope.facts
+	facts.Category = category
+	facts.Answer = answer
+	facts.AnswerScore = score
+	boosted, fired := scope.engine.Apply(facts)
+	for _, f := range fired {
+		fmt.Printf("[WeightRule] rule %d (%s) fired for %s: %+d\n", f.RuleID, f.Name, category, f.WeightBoost)
+	}
+	return boosted
+}
+
+// questionTemplateContext は AI 質問テンプレートの ContextKeys に当てはめる値を集める
+func questionTemplateContext(history []models.ChatMessage, scoreAnalysis, phaseName, targetLevel, jobCategoryName string, industryID, jobCategoryID uint) map[string]string {
+	var answers []string
+	for i := len(history) - 1; i >= 0 && len(answers) < 5; i-- {
+		if history[i].Role == "user" {
+			answers = append([]string{strings.TrimSpace(history[i].Content)}, answers...)
+		}
+	}
+	values := map[string]string{
+		"answer_history": strings.Join(answers, " / "),
+		"scores":         strings.TrimSpace(scoreAnalysis),
+		"phase":          phaseName,
+		"target_level":   targetLevel,
+		"job_category":   jobCategoryName,
+	}
+	if jobCategoryID != 0 {
+		values["job_category_ids"] = fmt.Sprint(jobCategoryID)
+	}
+	if industryID != 0 {
+		values["industry_ids"] = fmt.Sprint(industryID)
+	}
+	return values
+}
@@ -47,7 +47,8 @@ func (s *ChatService) analyzeAndUpdateWeights(ctx context.Context, userID uint,
 		return nil
 	}
 
-	return s.updateCategoryScore(userID, sessionID, targetCategory, result.Score)
+	score := s.applyWeightRules(ctx, targetCategory, message, result.Score)
+	return s.updateCategoryScore(ctx, userID, sessionID, targetCategory, score)
 }
 
 // processChoiceAnswer 選択肢回答を処理してスコアを更新
@@ -99,10 +100,10 @@ func (s *ChatService) processChoiceAnswer(ctx context.Context, userID uint, sess
 		fmt.Printf("Skipping choice scoring due to precheck: %s\n", result.Reason)
 		return nil
 	}
-	score := result.Score
+	score := s.applyWeightRules(ctx, targetCategory, answer, result.Score)
 
 	// スコアを保存または更新
-	return s.updateCategoryScore(userID, sessionID, targetCategory, score)
+	return s.updateCategoryScore(ctx, userID, sessionID, targetCategory, score)
 }
 
 // convertChoiceToScore 選択肢をスコアに変換
@@ -126,6 +127,11 @@ func (s *ChatService) convertChoiceToScore(choice string) int {
 
 // inferCategoryFromQuestion 質問文からカテゴリを推測
 func (s *ChatService) inferCategoryFromQuestion(question string) string {
+	return inferQuestionCategory(question)
+}
+
+// inferQuestionCategory 質問文のキーワードからカテゴリを推測する（該当なしは技術志向）
+func inferQuestionCategory(question string) string {
 	categoryKeywords := map[string][]string{
 		"技術志向":       {"技術", "プログラミング", "コーディング", "アルゴリズム", "システム設計", "新しい技術", "技術的"},
 		"チームワーク":     {"チーム", "協力", "協働", "連携", "メンバー", "共同"},
@@ -152,7 +158,11 @@ func (s *ChatService) inferCategoryFromQuestion(question string) string {
 }
 
 // updateCategoryScore カテゴリスコアを更新
-func (s *ChatService) updateCategoryScore(userID uint, sessionID, category string, score int) error {
+func (s *ChatService) updateCategoryScore(ctx context.Context, userID uint, sessionID, category string, score int) error {
+	// バリアントのスコアリングの変更を当てはめる
+	variants := sessionVariantsFrom(ctx)
+	score = variants.ScaleScore(category, score)
+
 	// 既存のスコアを取得
 	existingScore, err := s.userWeightScoreRepo.FindByUserSessionAndCategory(userID, sessionID, category)
 
@@ -164,7 +174,8 @@ func (s *ChatService) updateCategoryScore(userID uint, sessionID, category strin
 		fmt.Printf("[Choice Answer] Created new score: %s = %d\n", category, score)
 	} else {
 		// 移動平均で更新（直近回答の影響を反映）
-		newScore := int(math.Round(float64(existingScore.Score)*0.7 + float64(score)*0.3))
+		recentWeight := variants.RecentAnswerWeight()
+		newScore := int(math.Round(float64(existingScore.Score)*(1-recentWeight) + float64(score)*recentWeight))
 		delta := newScore - existingScore.Score
 		if delta == 0 {
 			fmt.Printf("[Choice Answer] Score unchanged: %s = %d\n", category, existingScore.Score)
@@ -181,6 +192,11 @@ func (s *ChatService) updateCategoryScore(userID uint, sessionID, category strin
 
 // isChoiceAnswer 選択肢回答かどうかを判定
 func (s *ChatService) isChoiceAnswer(answer string) bool {
+	return isChoiceAnswerText(answer)
+}
+
+// isChoiceAnswerText 回答が選択肢の記号（A-E または 1-5）かどうかを返す
+func isChoiceAnswerText(answer string) bool {
 	answer = strings.ToUpper(strings.TrimSpace(answer))
 	// A-E または 1-5 の形式
 	return answer == "A" || answer == "B" || answer == "C" || answer == "D" || answer == "E" ||
@@ -1,17 +1,18 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/entity"
 	"Backend/domain/repository"
 	"Backend/internal/models"
-	"Backend/internal/openai"
+	"Backend/internal/services/prompts"
 	"context"
 	"fmt"
 	"strings"
 )
 
 type ChatService struct {
-	aiClient                *openai.Client
+	aiClient                ai.LLMClient
 	questionWeightRepo      repository.QuestionWeightRepository
 	chatMessageRepo         repository.ChatMessageRepository
 	userWeightScoreRepo     repository.UserWeightScoreRepository
@@ -27,10 +28,14 @@ type ChatService struct {
 	conversationContextRepo repository.ConversationContextRepository
 	answerEvaluator         *AnswerEvaluator
 	jobValidator            *JobCategoryValidator
+	promptRegistry          *prompts.Registry
+	inputGuard              *InputGuard
+	experiments             *ScoreValidationService
+	questionRules           *QuestionRuleService
 }
 
 func NewChatService(
-	aiClient *openai.Client,
+	aiClient ai.LLMClient,
 	questionWeightRepo repository.QuestionWeightRepository,
 	chatMessageRepo repository.ChatMessageRepository,
 	userWeightScoreRepo repository.UserWeightScoreRepository,
@@ -65,6 +70,29 @@ func NewChatService(
 	}
 }
 
+// SetPromptRegistry プロンプトレジストリを注入する（未設定ならファイルの既定版を使う）
+func (s *ChatService) SetPromptRegistry(registry *prompts.Registry) {
+	s.promptRegistry = registry
+}
+
+// SetInputGuard 利用者の回答を LLM に渡す前の入力ガードを注入する（未設定なら判定しない）
+func (s *ChatService) SetInputGuard(guard *InputGuard) {
+	s.inputGuard = guard
+}
+
+// SetExperimentService A/B テストのバリアントを割り当てるサービスを注入する（未設定なら実験に割り当てない）
+func (s *ChatService) SetExperimentService(experiments *ScoreValidationService) {
+	s.experiments = experiments
+}
+
+// SetQuestionRuleService 回答の加点ルールと AI 質問テンプレートの取得元を注入する（未設定なら加点せず、テンプレートも使わない）
+func (s *ChatService) SetQuestionRuleService(questionRules *QuestionRuleService) {
+	s.questionRules = questionRules
+}
+
+// blockedChatMessage 入力ガードで受け付けなかった回答の代わりに履歴に残す文言（以降のプロンプトに元の入力を含めない）
+const blockedChatMessage = "（入力ガードにより除外された回答）"
+
 // ChatRequest チャットリクエスト
 type ChatRequest struct {
 	UserID        uint   `json:"user_id"`
@@ -105,11 +133,20 @@ type PhaseProgress struct {
 
 // ProcessChat チャット処理のメインロジック
 func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: req.UserID, Feature: ai.FeatureChat})
+	// 他ユーザーのセッションへの書き込みを防ぐ
+	if err := s.ensureSessionOwner(req.UserID, req.SessionID); err != nil {
+		return nil, err
+	}
+
 	// セッション開始の特殊処理
 	if req.Message == "START_SESSION" {
 		return s.handleSessionStart(ctx, req)
 	}
 
+	// セッションに割り当てたバリアントの設定（質問プール・フェーズの質問数・プロンプトの版・スコアリング）を以降の処理に渡す
+	ctx = withSessionVariants(ctx, s.experiments.SessionVariants(req.UserID, req.SessionID))
+
 	// セッション終了チェック
 	isTerminated, err := s.sessionValidationRepo.IsTerminated(req.SessionID)
 	if err != nil {
@@ -133,6 +170,13 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 		}, nil
 	}
 
+	// 1-0. 入力ガード（受け付けない入力は履歴に残さず、無効な回答の回数に数えずに回答し直してもらう）
+	guard := s.inputGuard.Check(ctx, ai.FeatureChat, req.Message)
+	req.Message = guard.Text
+	if guard.Blocked() {
+		req.Message = blockedChatMessage
+	}
+
 	// 1. ユーザーのメッセージを保存
 	userMsg := &models.ChatMessage{
 		SessionID: req.SessionID,
@@ -168,7 +212,7 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 	}
 
 	jobJustResolved := false
-	if jobCategoryID == 0 && s.shouldValidateJobCategory(history) {
+	if jobCategoryID == 0 && !guard.Blocked() && s.shouldValidateJobCategory(history) {
 		fmt.Printf("[JobValidation] Validating job category answer: %s\n", req.Message)
 		jobValidation, err := s.jobValidator.ValidateJobCategory(ctx, req.Message)
 		if err != nil {
@@ -210,13 +254,13 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 	}
 
 	if jobCategoryID != 0 {
-		if err := s.completeJobAnalysisPhase(req.UserID, req.SessionID); err != nil {
+		if err := s.completeJobAnalysisPhase(ctx, req.UserID, req.SessionID); err != nil {
 			fmt.Printf("Warning: failed to complete job analysis phase: %v\n", err)
 		}
 	}
 
 	// 2.5. 回答の妥当性チェック（保存後のhistoryを使用）
-	handled, response, err := s.checkAnswerValidity(ctx, history, req.Message, req.UserID, req.SessionID)
+	handled, response, err := s.checkAnswerValidity(ctx, history, req.Message, guard, req.UserID, req.SessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -234,8 +278,14 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 		if err != nil {
 			fmt.Printf("Warning: failed to get validation: %v\n", err)
 		}
+		validationEvent := map[string]interface{}{"valid": false, "message": response}
+		if validation != nil {
+			validationEvent["invalid_answer_count"] = validation.InvalidAnswerCount
+			validationEvent["is_terminated"] = validation.IsTerminated
+		}
+		emitChatEvent(ctx, ChatEventValidation, validationEvent)
 
-		allPhases, currentPhaseInfo, _ := s.buildPhaseProgressResponse(req.UserID, req.SessionID)
+		allPhases, currentPhaseInfo, _ := s.buildPhaseProgressResponse(ctx, req.UserID, req.SessionID)
 
 		chatResponse := &ChatResponse{
 			Response:          response,
@@ -260,6 +310,7 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 	}
 
 	// 有効な回答の場合のみ、以降の処理を実行
+	emitChatEvent(ctx, ChatEventValidation, map[string]interface{}{"valid": true})
 	// 2.6. 現在のフェーズを取得または開始
 	currentPhase, err := s.getCurrentOrNextPhase(ctx, req.UserID, req.SessionID)
 	if err != nil {
@@ -277,7 +328,7 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 				fmt.Printf("Warning: failed to save completion message: %v\n", err)
 			}
 
-			allPhases, currentPhaseInfo, _ := s.buildPhaseProgressResponse(req.UserID, req.SessionID)
+			allPhases, currentPhaseInfo, _ := s.buildPhaseProgressResponse(ctx, req.UserID, req.SessionID)
 			return &ChatResponse{
 				Response:            completionMsg,
 				IsComplete:          true,
@@ -292,7 +343,7 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 		return nil, fmt.Errorf("failed to get current phase: %w", err)
 	}
 
-	allPhases, err := s.phaseRepo.FindAll()
+	allPhases, err := s.sessionPhases(ctx)
 	if err != nil {
 		fmt.Printf("Warning: failed to get phases: %v\n", err)
 	}
@@ -303,9 +354,9 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 		phaseByID[allPhases[i].ID] = &allPhases[i]
 	}
 	for _, p := range completedProgresses {
-		phase := p.Phase
+		phase := phaseByID[p.PhaseID]
 		if phase == nil {
-			phase = phaseByID[p.PhaseID]
+			phase = p.Phase
 		}
 		if isPhaseComplete(p.ValidAnswers, phase) {
 			completedPhaseCount++
@@ -322,7 +373,7 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 		if err := s.chatMessageRepo.Create(assistantMsg); err != nil {
 			fmt.Printf("Warning: failed to save completion message: %v\n", err)
 		}
-		allPhasesInfo, currentPhaseInfo, _ := s.buildPhaseProgressResponse(req.UserID, req.SessionID)
+		allPhasesInfo, currentPhaseInfo, _ := s.buildPhaseProgressResponse(ctx, req.UserID, req.SessionID)
 		evaluatedCategoriesCount := 0
 		scores, err := s.userWeightScoreRepo.FindByUserAndSession(req.UserID, req.SessionID)
 		if err != nil {
@@ -359,6 +410,12 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 	// 3. ユーザーの回答から重み係数を判定・更新
 	// 3. ユーザーの回答から重み係数を判定・更新し、結果に応じてフェーズ進捗を更新
 	// スコア更新に成功した場合のみ有効回答としてカウントする
+	// 加点ルールの判定に使う利用者の属性とフェーズ
+	ctx = s.withWeightRules(ctx, req.UserID, req.IndustryID, jobCategoryID, currentPhase)
+	var scoresBefore []entity.UserWeightScore
+	if chatEventSink(ctx) != nil {
+		scoresBefore, _ = s.userWeightScoreRepo.FindByUserAndSession(req.UserID, req.SessionID)
+	}
 	trimmedAnswer := strings.TrimSpace(req.Message)
 	fmt.Printf("[ProcessChat] Checking if choice answer: '%s' (len=%d)\n", trimmedAnswer, len(trimmedAnswer))
 	scoreUpdated := false
@@ -385,6 +442,7 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 	if err := s.updatePhaseProgress(currentPhase, scoreUpdated); err != nil {
 		fmt.Printf("Warning: failed to update phase progress: %v\n", err)
 	}
+	s.emitPhaseProgress(ctx, req.UserID, req.SessionID)
 
 	// 4. 既に聞いた質問を全て収集（重複防止を徹底）
 	askedTexts := make(map[string]bool)
@@ -422,6 +480,11 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 	scores, err := s.userWeightScoreRepo.FindByUserAndSession(req.UserID, req.SessionID)
 	if err != nil {
 		fmt.Printf("Warning: failed to get scores for question selection: %v\n", err)
+	} else if scoreUpdated {
+		emitChatEvent(ctx, ChatEventScores, map[string]interface{}{
+			"deltas":         scoreDeltas(scoresBefore, scores),
+			"current_scores": scores,
+		})
 	}
 
 	// スコア分布を分析
@@ -490,7 +553,7 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 	if currentPhase != nil && currentPhase.Phase != nil {
 		currentPhaseName = currentPhase.Phase.PhaseName
 	}
-	predefinedQ, err := s.tryGetPredefinedQuestion(req.UserID, req.SessionID, targetCategory, req.IndustryID, jobCategoryID, targetLevel, askedTexts, currentPhaseName)
+	predefinedQ, err := s.tryGetPredefinedQuestion(ctx, req.UserID, req.SessionID, targetCategory, req.IndustryID, jobCategoryID, targetLevel, askedTexts, currentPhaseName)
 
 	if err == nil && predefinedQ != nil {
 		fmt.Printf("[RuleBased] Using predefined question (ID: %d) for category: %s\n", predefinedQ.ID, predefinedQ.Category)
@@ -521,9 +584,9 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 	// 全フェーズが完了しているかチェック
 	completedPhaseCount = 0
 	for _, p := range completedProgresses {
-		phase := p.Phase
+		phase := phaseByID[p.PhaseID]
 		if phase == nil {
-			phase = phaseByID[p.PhaseID]
+			phase = p.Phase
 		}
 		if isPhaseComplete(p.ValidAnswers, phase) {
 			completedPhaseCount++
@@ -563,6 +626,10 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 			aiResponse = sanitizeForNewGrad(aiResponse)
 		}
 
+		emitChatEvent(ctx, ChatEventQuestion, map[string]interface{}{
+			"text":               aiResponse,
+			"question_weight_id": questionWeightID,
+		})
 		assistantMsg := &models.ChatMessage{
 			SessionID:        req.SessionID,
 			UserID:           req.UserID,
@@ -593,7 +660,7 @@ func (s *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatRe
 	}
 
 	// フェーズ情報を構築
-	allPhasesInfo, currentPhaseInfo, _ := s.buildPhaseProgressResponse(req.UserID, req.SessionID)
+	allPhasesInfo, currentPhaseInfo, _ := s.buildPhaseProgressResponse(ctx, req.UserID, req.SessionID)
 
 	// フェーズの質問数合計を計算（最大が無い場合は最小を採用）
 	totalMaxQuestions := 0
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"Backend/domain/ai"
+	"Backend/domain/entity"
+	"context"
+)
+
+// ストリーミング版のチャット（ProcessChatStream）で送るイベントの種類
+const (
+	ChatEventValidation    = "validation"     // 回答の妥当性の判定結果
+	ChatEventScores        = "scores"         // 回答の分析によるスコアの変化
+	ChatEventPhase         = "phase"          // フェーズ進捗
+	ChatEventQuestionDelta = "question_delta" // AI が生成中の次の質問の差分（プレビュー。attempt が変わったらそれまでの差分を破棄する。確定した質問は question）
+	ChatEventQuestion      = "question"       // 確定した次の質問
+	ChatEventComplete      = "complete"       // ChatResponse 全体（最後に 1 回）
+	ChatEventError         = "error"
+)
+
+// ChatEventSink はチャット処理の途中経過を受け取る。event["type"] は ChatEvent* 定数
+type ChatEventSink func(event map[string]interface{})
+
+type chatEventsKey struct{}
+
+type questionStreamKey struct{}
+
+// ScoreDelta 1 回の回答によるカテゴリのスコアの変化
+type ScoreDelta struct {
+	Category string `json:"category"`
+	Before   int    `json:"before"`
+	After    int    `json:"after"`
+	Delta    int    `json:"delta"`
+}
+
+// ProcessChatStream は ProcessChat と同じ処理を行い、妥当性の判定・スコアの変化・フェーズ進捗・質問の生成を
+// 終わった順に sink に送る。complete・error イベントは呼び出し元が戻り値から送る
+func (s *ChatService) ProcessChatStream(ctx context.Context, req ChatRequest, sink ChatEventSink) (*ChatResponse, error) {
+	if sink != nil {
+		ctx = context.WithValue(ctx, chatEventsKey{}, sink)
+	}
+	return s.ProcessChat(ctx, req)
+}
+
+// chatEventSink は ctx に設定されたイベントの送り先を返す（ストリーミングでなければ nil）
+func chatEventSink(ctx context.Context) ChatEventSink {
+	sink, _ := ctx.Value(chatEventsKey{}).(ChatEventSink)
+	return sink
+}
+
+// emitChatEvent はストリーミング中なら eventType のイベントを送る
+func emitChatEvent(ctx context.Context, eventType string, fields map[string]interface{}) {
+	sink := chatEventSink(ctx)
+	if sink == nil {
+		return
+	}
+	event := map[string]interface{}{"type": eventType}
+	for k, v := range fields {
+		event[k] = v
+	}
+	sink(event)
+}
+
+// withQuestionStream はストリーミング中なら、ctx での質問の生成を question_delta イベントとして送らせる。
+// 差分の送信は withQuestionAttempt で試行ごとに設定する
+func withQuestionStream(ctx context.Context) context.Context {
+	if chatEventSink(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, questionStreamKey{}, true)
+}
+
+// withQuestionAttempt は質問の生成中なら、attempt 回目の試行の差分を question_delta イベントとして送らせる。
+// 再試行ではそれまでのプレビューに続けず、attempt を見て生成し直したことをクライアントが判断できるようにする
+func withQuestionAttempt(ctx context.Context, attempt int) context.Context {
+	if streaming, _ := ctx.Value(questionStreamKey{}).(bool); !streaming {
+		return ctx
+	}
+	return ai.WithTextStream(ctx, func(delta string) {
+		emitChatEvent(ctx, ChatEventQuestionDelta, map[string]interface{}{"text": delta, "attempt": attempt})
+	})
+}
+
+// emitPhaseProgress はストリーミング中ならフェーズ進捗を送る
+func (s *ChatService) emitPhaseProgress(ctx context.Context, userID uint, sessionID string) {
+	if chatEventSink(ctx) == nil {
+		return
+	}
+	allPhases, currentPhase, err := s.buildPhaseProgressResponse(ctx, userID, sessionID)
+	if err != nil {
+		return
+	}
+	emitChatEvent(ctx, ChatEventPhase, map[string]interface{}{
+		"current_phase": currentPhase,
+		"all_phases":    allPhases,
+	})
+}
+
+// scoreDeltas は回答前後のスコアから変化したカテゴリを返す
+func scoreDeltas(before, after []entity.UserWeightScore) []ScoreDelta {
+	prev := make(map[string]int, len(before))
+	for _, s := range before {
+		prev[s.WeightCategory] = s.Score
+	}
+	deltas := []ScoreDelta{}
+	for _, s := range after {
+		if d := s.Score - prev[s.WeightCategory]; d != 0 {
+			deltas = append(deltas, ScoreDelta{Category: s.WeightCategory, Before: prev[s.WeightCategory], After: s.Score, Delta: d})
+		}
+	}
+	return deltas
+}
@@ -0,0 +1,508 @@
+package services
+
+import (
+	"Backend/domain/entity"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const cohortInvitationTTL = 7 * 24 * time.Hour
+
+// funnelStages 選考ファネルの段階（後段に進んだ応募は前段にも計上する）
+var funnelStages = []string{"applied", "document_passed", "interview", "offered", "accepted"}
+
+// CohortService 教員・クラス管理と、担当教員による学生データ閲覧を扱うサービス
+type CohortService struct {
+	cohortRepo       repository.CohortRepository
+	userRepo         repository.UserRepository
+	emailService     *EmailService
+	interviewService *InterviewService
+	chatService      *ChatService
+	analysisService  *AnalysisScoringService
+	appService       *ApplicationService
+}
+
+func NewCohortService(
+	cohortRepo repository.CohortRepository,
+	userRepo repository.UserRepository,
+	emailService *EmailService,
+	interviewService *InterviewService,
+	chatService *ChatService,
+	analysisService *AnalysisScoringService,
+	appService *ApplicationService,
+) *CohortService {
+	return &CohortService{
+		cohortRepo:       cohortRepo,
+		userRepo:         userRepo,
+		emailService:     emailService,
+		interviewService: interviewService,
+		chatService:      chatService,
+		analysisService:  analysisService,
+		appService:       appService,
+	}
+}
+
+// CohortStudent クラス所属学生の一覧項目（Approved が false の学生は本人の承認待ちでデータを閲覧できない）
+type CohortStudent struct {
+	UserID     uint      `json:"user_id"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	SchoolName string    `json:"school_name"`
+	Approved   bool      `json:"approved"`
+	JoinedAt   time.Time `json:"joined_at"`
+}
+
+// FunnelStage 選考ファネルの1段階
+type FunnelStage struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+// CohortFunnel クラス単位の選考ファネル
+type CohortFunnel struct {
+	CohortID     uint          `json:"cohort_id"`
+	Students     int           `json:"students"`
+	Applications int           `json:"applications"`
+	Stages       []FunnelStage `json:"stages"`
+	Declined     int           `json:"declined"`
+	Rejected     int           `json:"rejected"`
+}
+
+// StudentTrend 学生ごとの面接スコア推移
+type StudentTrend struct {
+	UserID uint                  `json:"user_id"`
+	Name   string                `json:"name"`
+	Points []InterviewTrendPoint `json:"points"`
+}
+
+// CreateCohort クラスを作成する。教員は自分の学校名でのみ作成でき、作成者は担当教員になる
+func (s *CohortService) CreateCohort(actor *entity.User, schoolName, name string) (*models.Cohort, error) {
+	schoolName = strings.TrimSpace(schoolName)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if actor.EffectiveRole() == entity.RoleTeacher {
+		if schoolName == "" {
+			schoolName = actor.SchoolName
+		}
+		if schoolName == "" || schoolName != actor.SchoolName {
+			return nil, errors.New("forbidden")
+		}
+	}
+	if schoolName == "" {
+		return nil, errors.New("school_name is required")
+	}
+	cohort := &models.Cohort{SchoolName: schoolName, Name: name, CreatedByID: actor.ID}
+	if err := s.cohortRepo.Create(cohort); err != nil {
+		return nil, err
+	}
+	if actor.EffectiveRole() == entity.RoleTeacher {
+		if err := s.cohortRepo.AddMember(teacherMember(cohort.ID, actor.ID)); err != nil {
+			return nil, err
+		}
+	}
+	return cohort, nil
+}
+
+// ListCohorts 閲覧者が担当するクラス一覧（管理者は全件）
+func (s *CohortService) ListCohorts(actor *entity.User) ([]models.Cohort, error) {
+	if actor.HasAdminRole() {
+		return s.cohortRepo.ListAll()
+	}
+	return s.cohortRepo.ListByMember(actor.ID, models.CohortRoleTeacher)
+}
+
+// DeleteCohort クラスを削除する（担当教員または管理者のみ）
+func (s *CohortService) DeleteCohort(actor *entity.User, cohortID uint) error {
+	if _, err := s.authorizeCohort(actor, cohortID); err != nil {
+		return err
+	}
+	return s.cohortRepo.Delete(cohortID)
+}
+
+// ListStudents クラス所属学生の一覧
+func (s *CohortService) ListStudents(actor *entity.User, cohortID uint) ([]CohortStudent, error) {
+	if _, err := s.authorizeCohort(actor, cohortID); err != nil {
+		return nil, err
+	}
+	members, err := s.cohortRepo.ListMembers(cohortID, models.CohortRoleStudent)
+	if err != nil {
+		return nil, err
+	}
+	students := make([]CohortStudent, 0, len(members))
+	for _, m := range members {
+		u, err := s.userRepo.GetUserByID(m.UserID)
+		if err != nil || u == nil {
+			continue
+		}
+		students = append(students, CohortStudent{
+			UserID:     u.ID,
+			Name:       u.Name,
+			Email:      u.Email,
+			SchoolName: u.SchoolName,
+			Approved:   m.IsApproved(),
+			JoinedAt:   m.CreatedAt,
+		})
+	}
+	return students, nil
+}
+
+// approvedStudents 本人が承認済みの所属学生
+func (s *CohortService) approvedStudents(cohortID uint) ([]models.CohortMember, error) {
+	members, err := s.cohortRepo.ListMembers(cohortID, models.CohortRoleStudent)
+	if err != nil {
+		return nil, err
+	}
+	approved := make([]models.CohortMember, 0, len(members))
+	for _, m := range members {
+		if m.IsApproved() {
+			approved = append(approved, m)
+		}
+	}
+	return approved, nil
+}
+
+// AddStudent 学校名が一致する学生をクラスに追加する。
+// 追加しただけでは承認待ちで、学生本人が承認するまで担当教員はデータを閲覧できない
+func (s *CohortService) AddStudent(actor *entity.User, cohortID uint, email string) (*entity.User, error) {
+	cohort, err := s.authorizeCohort(actor, cohortID)
+	if err != nil {
+		return nil, err
+	}
+	student, err := s.userRepo.GetUserByEmail(strings.TrimSpace(email))
+	if err != nil {
+		return nil, err
+	}
+	if student == nil || student.IsGuest {
+		return nil, errors.New("学生が見つかりません")
+	}
+	if student.EffectiveRole() != entity.RoleStudent {
+		return nil, errors.New("学生ロールのユーザーのみ追加できます")
+	}
+	if student.SchoolName != cohort.SchoolName {
+		return nil, errors.New("学校名がクラスと一致しません")
+	}
+	if err := s.cohortRepo.AddMember(&models.CohortMember{CohortID: cohortID, UserID: student.ID, Role: models.CohortRoleStudent}); err != nil {
+		return nil, err
+	}
+	return student, nil
+}
+
+// RemoveStudent 学生をクラスから外す
+func (s *CohortService) RemoveStudent(actor *entity.User, cohortID, studentID uint) error {
+	if _, err := s.authorizeCohort(actor, cohortID); err != nil {
+		return err
+	}
+	member, err := s.cohortRepo.FindMember(cohortID, studentID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Role != models.CohortRoleStudent {
+		return errors.New("not found")
+	}
+	return s.cohortRepo.RemoveMember(cohortID, studentID)
+}
+
+// ListMyCohorts 学生本人の所属クラス（承認待ちを含む）
+func (s *CohortService) ListMyCohorts(studentID uint) ([]models.StudentCohort, error) {
+	return s.cohortRepo.ListCohortsOfStudent(studentID)
+}
+
+// ApproveMembership 学生本人がクラスへの追加を承認し、担当教員に自分のデータの閲覧を許可する
+func (s *CohortService) ApproveMembership(student *entity.User, cohortID uint) error {
+	member, err := s.cohortRepo.FindMember(cohortID, student.ID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Role != models.CohortRoleStudent {
+		return errors.New("not found")
+	}
+	if member.ApprovedAt != nil {
+		return nil
+	}
+	return s.cohortRepo.ApproveMember(cohortID, student.ID)
+}
+
+// LeaveCohort 学生本人がクラスへの追加を断る、または所属をやめる（担当教員は閲覧できなくなる）
+func (s *CohortService) LeaveCohort(student *entity.User, cohortID uint) error {
+	member, err := s.cohortRepo.FindMember(cohortID, student.ID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Role != models.CohortRoleStudent {
+		return errors.New("not found")
+	}
+	return s.cohortRepo.RemoveMember(cohortID, student.ID)
+}
+
+// InviteTeacher 教員をクラスに招待し、承認用トークンをメールで送る
+func (s *CohortService) InviteTeacher(actor *entity.User, cohortID uint, email string) (*models.CohortInvitation, error) {
+	cohort, err := s.authorizeCohort(actor, cohortID)
+	if err != nil {
+		return nil, err
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, errors.New("email is invalid")
+	}
+	token, err := randomHexToken(32)
+	if err != nil {
+		return nil, err
+	}
+	inv := &models.CohortInvitation{
+		CohortID:    cohortID,
+		Email:       email,
+		Token:       token,
+		InvitedByID: actor.ID,
+		ExpiresAt:   time.Now().Add(cohortInvitationTTL),
+	}
+	if err := s.cohortRepo.CreateInvitation(inv); err != nil {
+		return nil, err
+	}
+	if s.emailService != nil {
+		appURL := os.Getenv("APP_URL")
+		if appURL == "" {
+			appURL = "http://localhost:3000"
+		}
+		inviter := actor.Name
+		if inviter == "" {
+			inviter = actor.Email
+		}
+		go s.emailService.SendCohortInvitationEmail(email, cohort.Name, inviter, token, appURL)
+	}
+	return inv, nil
+}
+
+// ListInvitations クラスの招待履歴
+func (s *CohortService) ListInvitations(actor *entity.User, cohortID uint) ([]models.CohortInvitation, error) {
+	if _, err := s.authorizeCohort(actor, cohortID); err != nil {
+		return nil, err
+	}
+	return s.cohortRepo.ListInvitations(cohortID)
+}
+
+// AcceptInvitation 招待を承認し、担当教員としてクラスに所属させる。
+// ロールは変更しない。教員ロール（管理者が付与する）でクラスと同じ学校のユーザー、または管理者のみ承認できる。
+func (s *CohortService) AcceptInvitation(user *entity.User, token string) (*models.Cohort, error) {
+	inv, err := s.cohortRepo.FindInvitationByToken(strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("招待が見つかりません")
+		}
+		return nil, err
+	}
+	if inv.AcceptedAt != nil {
+		return nil, errors.New("この招待は使用済みです")
+	}
+	if time.Now().After(inv.ExpiresAt) {
+		return nil, errors.New("招待の有効期限が切れています")
+	}
+	if !strings.EqualFold(inv.Email, user.Email) {
+		return nil, errors.New("forbidden")
+	}
+	cohort, err := s.cohortRepo.FindByID(inv.CohortID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasAdminRole() {
+		if user.EffectiveRole() != entity.RoleTeacher {
+			return nil, errors.New("教員ロールのユーザーのみ承認できます（管理者に教員ロールの付与を依頼してください）")
+		}
+		if user.SchoolName != cohort.SchoolName {
+			return nil, errors.New("学校名がクラスと一致しません")
+		}
+	}
+	if err := s.cohortRepo.AddMember(teacherMember(cohort.ID, user.ID)); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	inv.AcceptedAt = &now
+	inv.AcceptedUserID = user.ID
+	if err := s.cohortRepo.UpdateInvitation(inv); err != nil {
+		return nil, err
+	}
+	return cohort, nil
+}
+
+// ListTeachersOfStudent 学生のデータを閲覧できる担当教員の一覧（学生本人が承認したクラスのみ）
+func (s *CohortService) ListTeachersOfStudent(studentID uint) ([]models.CohortTeacher, error) {
+	return s.cohortRepo.ListTeachersOfStudent(studentID)
+}
+
+// CanViewStudent 閲覧者が学生のデータを閲覧できるか（管理者、または学生本人が承認したクラスの担当教員）
+func (s *CohortService) CanViewStudent(actor *entity.User, studentID uint) (bool, error) {
+	if actor == nil {
+		return false, nil
+	}
+	if actor.HasAdminRole() {
+		return true, nil
+	}
+	if actor.EffectiveRole() != entity.RoleTeacher {
+		return false, nil
+	}
+	return s.cohortRepo.SharesCohort(actor.ID, studentID)
+}
+
+// ListStudentInterviews 担当学生の面接セッション一覧
+func (s *CohortService) ListStudentInterviews(actor *entity.User, studentID uint, limit, offset int) ([]InterviewSessionResponse, int64, error) {
+	if err := s.authorizeStudent(actor, studentID); err != nil {
+		return nil, 0, err
+	}
+	return s.interviewService.ListSessions(studentID, false, limit, offset)
+}
+
+// GetStudentInterview 担当学生の面接詳細（教員用レポートを含む）
+func (s *CohortService) GetStudentInterview(actor *entity.User, studentID, sessionID uint) (*InterviewDetailResponse, error) {
+	if err := s.authorizeStudent(actor, studentID); err != nil {
+		return nil, err
+	}
+	// 学生本人を主体として取得することで、セッションがその学生のものであることも検証される
+	return s.interviewService.GetSessionDetailWithRole(studentID, sessionID, entity.RoleTeacher)
+}
+
+// GetStudentAnalysis 担当学生のチャット分析サマリー（session_id 省略時は最新セッション）
+func (s *CohortService) GetStudentAnalysis(ctx context.Context, actor *entity.User, studentID uint, sessionID string) (*AnalysisSummary, error) {
+	if err := s.authorizeStudent(actor, studentID); err != nil {
+		return nil, err
+	}
+	if s.analysisService == nil {
+		return nil, errors.New("analysis service not available")
+	}
+	if sessionID == "" {
+		sessions, err := s.chatService.GetUserChatSessions(studentID)
+		if err != nil {
+			return nil, err
+		}
+		if len(sessions) == 0 {
+			return nil, errors.New("not found")
+		}
+		sessionID = sessions[0].SessionID
+	} else if err := s.chatService.ensureSessionOwner(studentID, sessionID); err != nil {
+		return nil, err
+	}
+	return s.analysisService.BuildAnalysisSummary(ctx, studentID, sessionID)
+}
+
+// GetCohortFunnel クラス全体の選考ファネルを集計する
+func (s *CohortService) GetCohortFunnel(actor *entity.User, cohortID uint) (*CohortFunnel, error) {
+	if _, err := s.authorizeCohort(actor, cohortID); err != nil {
+		return nil, err
+	}
+	members, err := s.approvedStudents(cohortID)
+	if err != nil {
+		return nil, err
+	}
+	stageIndex := make(map[string]int, len(funnelStages))
+	for i, st := range funnelStages {
+		stageIndex[st] = i
+	}
+	counts := make([]int, len(funnelStages))
+	funnel := &CohortFunnel{CohortID: cohortID, Students: len(members)}
+	for _, m := range members {
+		apps, err := s.appService.GetApplicationsByUser(m.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load applications: %w", err)
+		}
+		for _, app := range apps {
+			funnel.Applications++
+			switch app.Status {
+			case "declined":
+				funnel.Declined++
+				counts[0]++
+			case "rejected":
+				funnel.Rejected++
+				counts[0]++
+			default:
+				idx, ok := stageIndex[app.Status]
+				if !ok {
+					continue
+				}
+				for i := 0; i <= idx; i++ {
+					counts[i]++
+				}
+			}
+		}
+	}
+	for i, st := range funnelStages {
+		funnel.Stages = append(funnel.Stages, FunnelStage{Status: st, Count: counts[i]})
+	}
+	return funnel, nil
+}
+
+// GetCohortTrends クラス所属学生（本人が承認済み）ごとの面接スコア推移
+func (s *CohortService) GetCohortTrends(actor *entity.User, cohortID uint, limit int) ([]StudentTrend, error) {
+	students, err := s.ListStudents(actor, cohortID)
+	if err != nil {
+		return nil, err
+	}
+	trends := make([]StudentTrend, 0, len(students))
+	for _, st := range students {
+		if !st.Approved {
+			continue
+		}
+		points, err := s.interviewService.GetTrend(st.UserID, limit)
+		if err != nil {
+			return nil, err
+		}
+		trends = append(trends, StudentTrend{UserID: st.UserID, Name: st.Name, Points: points})
+	}
+	return trends, nil
+}
+
+// authorizeCohort クラスの担当教員（または管理者）であることを確認する
+func (s *CohortService) authorizeCohort(actor *entity.User, cohortID uint) (*models.Cohort, error) {
+	cohort, err := s.cohortRepo.FindByID(cohortID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("not found")
+		}
+		return nil, err
+	}
+	if actor.HasAdminRole() {
+		return cohort, nil
+	}
+	member, err := s.cohortRepo.FindMember(cohortID, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.Role != models.CohortRoleTeacher {
+		return nil, errors.New("forbidden")
+	}
+	return cohort, nil
+}
+
+func (s *CohortService) authorizeStudent(actor *entity.User, studentID uint) error {
+	ok, err := s.CanViewStudent(actor, studentID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+// teacherMember 担当教員の所属（教員の所属は承認を必要としない）
+func teacherMember(cohortID, userID uint) *models.CohortMember {
+	now := time.Now()
+	return &models.CohortMember{CohortID: cohortID, UserID: userID, Role: models.CohortRoleTeacher, ApprovedAt: &now}
+}
+
+func randomHexToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
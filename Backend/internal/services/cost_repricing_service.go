@@ -0,0 +1,177 @@
+package services
+
+import (
+	"Backend/domain/ai"
+	"Backend/domain/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRepricing は再計算の指定が不正なことを表す。
+var ErrInvalidRepricing = errors.New("invalid repricing request")
+
+// RepricingOptions 再計算の対象
+type RepricingOptions struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`              // ゼロ値なら現在時刻まで
+	Model     string    `json:"model,omitempty"` // 空なら全モデル。前方一致で絞る
+	DryRun    bool      `json:"dry_run"`         // true なら件数と差額だけを数えて更新しない
+	BatchSize int       `json:"batch_size,omitempty"`
+}
+
+// RepricingCount 再計算した件数と料金の差額
+type RepricingCount struct {
+	Scanned  int     `json:"scanned"`
+	Changed  int     `json:"changed"`
+	Unpriced int     `json:"unpriced"` // 単価未登録のため記録済みの料金を残した件数
+	DeltaUSD float64 `json:"delta_usd"`
+}
+
+// RepricingResult 再計算の結果
+type RepricingResult struct {
+	Options  RepricingOptions `json:"options"`
+	Calls    RepricingCount   `json:"calls"`
+	Realtime RepricingCount   `json:"realtime"`
+}
+
+// CostRepricingService 単価を訂正したときに api_call_logs・realtime_usage_logs の料金を単価表から計算し直す
+type CostRepricingService struct {
+	pricing  *PricingService
+	calls    repository.CallCostRepricingRepository
+	realtime repository.RealtimeCostRepricingRepository
+	now      func() time.Time
+}
+
+func NewCostRepricingService(pricing *PricingService, calls repository.CallCostRepricingRepository, realtime repository.RealtimeCostRepricingRepository) *CostRepricingService {
+	return &CostRepricingService{pricing: pricing, calls: calls, realtime: realtime, now: time.Now}
+}
+
+// SetNow は現在時刻の取得関数を差し替える（テスト用）。
+func (s *CostRepricingService) SetNow(now func() time.Time) {
+	s.now = now
+}
+
+// Recalculate は opts の期間の呼び出し・リアルタイム音声セッションの料金を、それぞれの発生日時に有効な単価で計算し直す
+func (s *CostRepricingService) Recalculate(ctx context.Context, opts RepricingOptions) (*RepricingResult, error) {
+	if opts.To.IsZero() {
+		opts.To = s.now().UTC()
+	}
+	if opts.From.IsZero() || !opts.From.Before(opts.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidRepricing)
+	}
+	if opts.BatchSize <= 0 || opts.BatchSize > 5000 {
+		opts.BatchSize = 500
+	}
+	opts.Model = strings.ToLower(strings.TrimSpace(opts.Model))
+	s.pricing.Reload()
+
+	result := &RepricingResult{Options: opts}
+	if err := s.repriceCalls(ctx, opts, &result.Calls); err != nil {
+		return result, err
+	}
+	if s.realtime != nil {
+		if err := s.repriceRealtime(ctx, opts, &result.Realtime); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (s *CostRepricingService) repriceCalls(ctx context.Context, opts RepricingOptions, count *RepricingCount) error {
+	var afterID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		logs, err := s.calls.ListForRepricing(opts.From, opts.To, opts.Model, afterID, opts.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, l := range logs {
+			afterID = l.ID
+			count.Scanned++
+			cost, priceID := s.pricing.TokenCost(l.Model, l.CalledAt, ai.TokenUsage{
+				PromptTokens:       l.PromptTokens,
+				CachedPromptTokens: l.CachedPromptTokens,
+				CompletionTokens:   l.CompletionTokens,
+			})
+			if priceID == nil {
+				// 単価未登録の呼び出しは記録済みの料金を残す
+				count.Unpriced++
+				continue
+			}
+			// cost_usd は decimal(14,8)
+			if math.Abs(cost-l.CostUSD) < 5e-9 && samePriceID(priceID, l.ModelPriceID) {
+				continue
+			}
+			count.Changed++
+			count.DeltaUSD += cost - l.CostUSD
+			if opts.DryRun {
+				continue
+			}
+			if err := s.calls.UpdateCost(l.ID, cost, priceID); err != nil {
+				return fmt.Errorf("failed to update api call log %d: %w", l.ID, err)
+			}
+		}
+		if len(logs) < opts.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *CostRepricingService) repriceRealtime(ctx context.Context, opts RepricingOptions, count *RepricingCount) error {
+	var afterID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		logs, err := s.realtime.ListFinishedForRepricing(opts.From, opts.To, afterID, opts.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, l := range logs {
+			afterID = l.ID
+			model := l.Model
+			if model == "" {
+				model = RealtimeModel()
+			}
+			if opts.Model != "" && !strings.HasPrefix(strings.ToLower(model), opts.Model) {
+				continue
+			}
+			count.Scanned++
+			rate, ok := s.pricing.PerMinuteRate(model, l.StartedAt)
+			if !ok {
+				// 単価未登録のセッションは記録済みの料金を残す
+				count.Unpriced++
+				continue
+			}
+			cost := float64(l.DurationSeconds) / 60.0 * rate
+			// cost_usd は decimal(10,4)
+			if math.Abs(cost-l.CostUSD) < 5e-5 {
+				continue
+			}
+			count.Changed++
+			count.DeltaUSD += cost - l.CostUSD
+			if opts.DryRun {
+				continue
+			}
+			if err := s.realtime.UpdateCost(l.ID, cost); err != nil {
+				return fmt.Errorf("failed to update realtime usage log %d: %w", l.ID, err)
+			}
+		}
+		if len(logs) < opts.BatchSize {
+			return nil
+		}
+	}
+}
+
+func samePriceID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -1,9 +1,10 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/repository"
 	"Backend/internal/models"
-	"Backend/internal/openai"
+	"Backend/internal/services/prompts"
 	"context"
 	"encoding/json"
 	"errors"
@@ -20,17 +21,23 @@ import (
 )
 
 type CrawlService struct {
-	repo        repository.CrawlRepository
-	companyRepo repository.CompanyRepository
-	popularRepo repository.CompanyPopularityRepository
-	aiClient    *openai.Client
-	mu          sync.Mutex
+	repo           repository.CrawlRepository
+	companyRepo    repository.CompanyRepository
+	popularRepo    repository.CompanyPopularityRepository
+	aiClient       ai.LLMClient
+	promptRegistry *prompts.Registry
+	mu             sync.Mutex
 }
 
-func NewCrawlService(repo repository.CrawlRepository, companyRepo repository.CompanyRepository, popularRepo repository.CompanyPopularityRepository, aiClient *openai.Client) *CrawlService {
+func NewCrawlService(repo repository.CrawlRepository, companyRepo repository.CompanyRepository, popularRepo repository.CompanyPopularityRepository, aiClient ai.LLMClient) *CrawlService {
 	return &CrawlService{repo: repo, companyRepo: companyRepo, popularRepo: popularRepo, aiClient: aiClient}
 }
 
+// SetPromptRegistry プロンプトレジストリを注入する（未設定ならファイルの既定版を使う）
+func (s *CrawlService) SetPromptRegistry(registry *prompts.Registry) {
+	s.promptRegistry = registry
+}
+
 type CrawlSourcePayload struct {
 	Name         string `json:"name"`
 	TargetType   string `json:"target_type"`
@@ -273,7 +280,7 @@ type popularCompanyExtraction struct {
 
 func (s *CrawlService) executePopularCompaniesCrawl(source *models.CrawlSource) error {
 	if s.aiClient == nil {
-		return errors.New("openai client is required for popular_companies crawl")
+		return errors.New("llm client is required for popular_companies crawl")
 	}
 	body, err := fetchText(source.SourceURL)
 	if err != nil {
@@ -350,7 +357,7 @@ type jobSiteCompanyExtraction struct {
 
 func (s *CrawlService) executeJobSiteCompanyCrawl(source *models.CrawlSource) error {
 	if s.aiClient == nil {
-		return errors.New("openai client is required for job_site_company crawl")
+		return errors.New("llm client is required for job_site_company crawl")
 	}
 	body, err := fetchText(source.SourceURL)
 	if err != nil {
@@ -458,7 +465,7 @@ type jobListingExtraction struct {
 
 func (s *CrawlService) executeJobListingCrawl(source *models.CrawlSource) error {
 	if s.aiClient == nil {
-		return errors.New("openai client is required for job_listing crawl")
+		return errors.New("llm client is required for job_listing crawl")
 	}
 	body, err := fetchText(source.SourceURL)
 	if err != nil {
@@ -561,36 +568,13 @@ func (s *CrawlService) extractJobListings(source *models.CrawlSource, rawHTML st
 	if len(clean) > 12000 {
 		clean = clean[:12000]
 	}
-	systemPrompt := `You are a data extraction assistant. Extract job listing information from new graduate job site pages. Use only the provided text. Do not infer or guess values not present in the text.`
-	userPrompt := fmt.Sprintf(`Extract company name and job positions from the job site page text below.
-Return JSON with the following shape:
-{
-  "company_name": "会社名",
-  "positions": [
-    {
-      "title": "職種名",
-      "description": "仕事内容",
-      "employment_type": "正社員",
-      "work_location": "東京都",
-      "remote_option": false,
-      "min_salary": 300,
-      "max_salary": 500,
-      "required_skills": "[\"Java\",\"Spring Boot\"]",
-      "preferred_skills": "[\"AWS\"]"
-    }
-  ]
-}
-Rules:
-- Return 0 for salary fields not found in the text.
-- Return "" for string fields not found in the text.
-- required_skills and preferred_skills must be JSON arrays serialized as a string (e.g. "[\"Java\"]"), or "" if not found.
-- min_salary and max_salary are annual salary in 万円 (integer).
-- Do not fabricate data.
-
-Text:
-%s`, clean)
+	ctx := ai.WithFeature(context.Background(), ai.FeatureCrawl)
+	prompt, err := s.promptRegistry.Render(ctx, prompts.CrawlJobListings, prompts.Vars{"Text": clean})
+	if err != nil {
+		return nil, err
+	}
 
-	content, err := s.aiClient.ChatCompletionJSON(context.Background(), systemPrompt, userPrompt, 0.2, 1200)
+	content, err := s.aiClient.GenerateJSON(ctx, prompt.System, prompt.User, ai.WithTemperature(0.2), ai.WithMaxTokens(1200))
 	if err != nil {
 		return nil, err
 	}
@@ -606,35 +590,13 @@ func (s *CrawlService) extractJobSiteCompany(source *models.CrawlSource, rawHTML
 	if len(clean) > 12000 {
 		clean = clean[:12000]
 	}
-	systemPrompt := `You are a data extraction assistant. Extract company information from new graduate job site pages. Use only the provided text. Do not infer or guess values not present in the text.`
-	userPrompt := fmt.Sprintf(`Extract company information from the job site page text below.
-Return JSON with the following shape:
-{
-  "name": "会社名",
-  "description": "会社概要",
-  "industry": "業界・業種",
-  "employee_count": 1000,
-  "founded_year": 2000,
-  "location": "本社所在地",
-  "website_url": "https://...",
-  "culture": "企業文化・社風",
-  "work_style": "リモート/ハイブリッド/オフィス",
-  "welfare_details": "福利厚生",
-  "main_business": "主要事業内容",
-  "average_age": 32.5,
-  "female_ratio": 40.0
-}
-Rules:
-- Return 0 for numeric fields not found in the text.
-- Return "" for string fields not found in the text.
-- employee_count must be an integer.
-- average_age and female_ratio must be floating-point numbers.
-- Do not fabricate data.
-
-Text:
-%s`, clean)
+	ctx := ai.WithFeature(context.Background(), ai.FeatureCrawl)
+	prompt, err := s.promptRegistry.Render(ctx, prompts.CrawlJobSiteCompany, prompts.Vars{"Text": clean})
+	if err != nil {
+		return nil, err
+	}
 
-	content, err := s.aiClient.ChatCompletionJSON(context.Background(), systemPrompt, userPrompt, 0.2, 800)
+	content, err := s.aiClient.GenerateJSON(ctx, prompt.System, prompt.User, ai.WithTemperature(0.2), ai.WithMaxTokens(800))
 	if err != nil {
 		return nil, err
 	}
@@ -650,28 +612,13 @@ func (s *CrawlService) extractPopularCompanies(source *models.CrawlSource, rawHT
 	if len(clean) > 12000 {
 		clean = clean[:12000]
 	}
-	systemPrompt := `You are a data extraction assistant. Use only the provided text. Do not infer or guess.`
-	userPrompt := fmt.Sprintf(`Extract popular companies mentioned in the text below.
-Return JSON with the following shape:
-{
-  "companies": [
-    {
-      "name": "Company Name",
-      "evidence": "Exact excerpt from the text",
-      "summary": "Why the company is described as popular, based only on the text",
-      "rank": 1
-    }
-  ]
-}
-Rules:
-- If rank is not shown, omit it or set it to null.
-- evidence must be a verbatim excerpt from the text.
-- summary must be a short, factual sentence based on the evidence only.
-
-Text:
-%s`, clean)
+	ctx := ai.WithFeature(context.Background(), ai.FeatureCrawl)
+	prompt, err := s.promptRegistry.Render(ctx, prompts.CrawlPopularCompanies, prompts.Vars{"Text": clean})
+	if err != nil {
+		return nil, err
+	}
 
-	content, err := s.aiClient.ChatCompletionJSON(context.Background(), systemPrompt, userPrompt, 0.2, 800)
+	content, err := s.aiClient.GenerateJSON(ctx, prompt.System, prompt.User, ai.WithTemperature(0.2), ai.WithMaxTokens(800))
 	if err != nil {
 		return nil, err
 	}
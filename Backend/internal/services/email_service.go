@@ -435,6 +435,64 @@ func (s *EmailService) SendPasswordResetEmail(email, token, appURL string) error
 	return smtp.SendMail(addr, auth, s.from, []string{email}, []byte(msg))
 }
 
+// SendAccountLockedEmail ログイン失敗が続いたためアカウントをロックした旨とロック解除リンクを送信
+func (s *EmailService) SendAccountLockedEmail(email, token, appURL string, lockedUntil time.Time) error {
+	unlockURL := appURL + "/unlock-account?token=" + token
+	body := fmt.Sprintf(`<!DOCTYPE html>
+<html lang="ja"><head><meta charset="UTF-8"><title>アカウントをロックしました</title></head>
+<body style="font-family:sans-serif;background:#f5f5f5;padding:20px;">
+<div style="max-width:500px;margin:0 auto;background:#fff;border-radius:8px;padding:32px;">
+<h2 style="color:#1976D2;">アカウントをロックしました</h2>
+<p>パスワードの入力誤りが続いたため、安全のためアカウントを一時的にロックしました。</p>
+<p>ロックは %s に自動で解除されます。ご本人の操作であれば、以下のボタンからすぐにロックを解除できます。</p>
+<a href="%s" style="display:inline-block;background:#1976D2;color:#fff;padding:12px 24px;border-radius:6px;text-decoration:none;font-weight:bold;margin:16px 0;">ロックを解除する</a>
+<p style="color:#888;font-size:12px;">身に覚えのない場合は、第三者による不正ログインの可能性があります。パスワードの変更をおすすめします。</p>
+</div>
+</body></html>`, lockedUntil.Format("2006/01/02 15:04"), unlockURL)
+
+	if s.host == "" {
+		fmt.Printf("[EmailService] Account locked email for %s: %s\n", email, unlockURL)
+		return nil
+	}
+
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: アカウントをロックしました\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s",
+		s.from, email, body,
+	)
+	addr := fmt.Sprintf("%s:%d", s.host, s.port)
+	auth := smtp.PlainAuth("", s.user, s.password, s.host)
+	return smtp.SendMail(addr, auth, s.from, []string{email}, []byte(msg))
+}
+
+// SendCohortInvitationEmail 教員招待メールを送信
+func (s *EmailService) SendCohortInvitationEmail(email, cohortName, inviterName, token, appURL string) error {
+	acceptURL := appURL + "/teacher/invitations/accept?token=" + token
+	body := fmt.Sprintf(`<!DOCTYPE html>
+<html lang="ja"><head><meta charset="UTF-8"><title>担当教員への招待</title></head>
+<body style="font-family:sans-serif;background:#f5f5f5;padding:20px;">
+<div style="max-width:500px;margin:0 auto;background:#fff;border-radius:8px;padding:32px;">
+<h2 style="color:#1976D2;">担当教員への招待</h2>
+<p>%s さんから、クラス「%s」の担当教員として招待されました。</p>
+<p>以下のボタンをクリックし、ログインして招待を承認してください。</p>
+<a href="%s" style="display:inline-block;background:#1976D2;color:#fff;padding:12px 24px;border-radius:6px;text-decoration:none;font-weight:bold;margin:16px 0;">招待を承認する</a>
+<p style="color:#888;font-size:12px;">このリンクは7日間有効です。身に覚えのない場合は無視してください。</p>
+</div>
+</body></html>`, template.HTMLEscapeString(inviterName), template.HTMLEscapeString(cohortName), acceptURL)
+
+	if s.host == "" {
+		fmt.Printf("[EmailService] Cohort invitation email for %s: %s\n", email, acceptURL)
+		return nil
+	}
+
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: 担当教員への招待\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s",
+		s.from, email, body,
+	)
+	addr := fmt.Sprintf("%s:%d", s.host, s.port)
+	auth := smtp.PlainAuth("", s.user, s.password, s.host)
+	return smtp.SendMail(addr, auth, s.from, []string{email}, []byte(msg))
+}
+
 // SendInterviewReport 面接練習レポートをメールで送信
 func (s *EmailService) SendInterviewReport(user *entity.User, data InterviewReportEmailData) error {
 	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
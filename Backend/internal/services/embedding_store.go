@@ -0,0 +1,130 @@
+package services
+
+import (
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/vectorindex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// 検索用の埋め込みの種類（entity_embeddings.kind）
+const (
+	EmbeddingKindCompany     = "company"
+	EmbeddingKindJobPosition = "job_position"
+)
+
+// embeddingKinds 起動時にインデックスを作る種類
+var embeddingKinds = []string{EmbeddingKindCompany, EmbeddingKindJobPosition}
+
+// embeddingLoadBatchSize 起動時に MySQL から 1 回に読み込む件数
+const embeddingLoadBatchSize = 500
+
+// EmbeddingStore 検索用の埋め込みを MySQL に保存し、種類ごとのメモリ上の HNSW インデックスで近傍を探す。
+// インデックスは起動時に Load で MySQL から作り直す。
+// 利用者・職種の埋め込み（user_embeddings・job_category_embeddings）は近傍を探さずセッションの職種と 1 対 1 で比べるため、ここでは扱わない
+type EmbeddingStore struct {
+	repo repository.EntityEmbeddingRepository
+	cfg  vectorindex.Config
+
+	mu      sync.RWMutex
+	indexes map[string]*vectorindex.HNSW
+}
+
+func NewEmbeddingStore(repo repository.EntityEmbeddingRepository) *EmbeddingStore {
+	return &EmbeddingStore{repo: repo, indexes: map[string]*vectorindex.HNSW{}}
+}
+
+// Load はすべての種類のインデックスを MySQL の内容で作り直す
+func (s *EmbeddingStore) Load() error {
+	for _, kind := range embeddingKinds {
+		index := vectorindex.NewHNSW(s.cfg)
+		var afterID uint
+		for {
+			rows, err := s.repo.ListByKind(kind, afterID, embeddingLoadBatchSize)
+			if err != nil {
+				return fmt.Errorf("load %s embeddings: %w", kind, err)
+			}
+			for _, row := range rows {
+				afterID = row.ID
+				vec, err := vectorindex.DecodeVector(row.Vector)
+				if err == nil {
+					err = index.Add(row.EntityID, vec)
+				}
+				if err != nil {
+					log.Printf("[EmbeddingStore] skip %s %d: %v", kind, row.EntityID, err)
+				}
+			}
+			if len(rows) < embeddingLoadBatchSize {
+				break
+			}
+		}
+		s.mu.Lock()
+		s.indexes[kind] = index
+		s.mu.Unlock()
+		log.Printf("[EmbeddingStore] loaded %d %s embeddings", index.Len(), kind)
+	}
+	return nil
+}
+
+// SourceText は保存済みの埋め込みの元のテキストを返す（なければ false）
+func (s *EmbeddingStore) SourceText(kind string, id uint) (string, bool, error) {
+	row, err := s.repo.Find(kind, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return row.SourceText, true, nil
+}
+
+// Put は埋め込みを保存してインデックスに登録する（保存に失敗した埋め込みはインデックスに載せない）
+func (s *EmbeddingStore) Put(kind string, id uint, sourceText string, vec []float32) error {
+	if err := s.repo.Upsert(&models.EntityEmbedding{
+		Kind:       kind,
+		EntityID:   id,
+		SourceText: sourceText,
+		Vector:     vectorindex.EncodeVector(vec),
+		Dimensions: len(vec),
+	}); err != nil {
+		return err
+	}
+	return s.index(kind).Add(id, vec)
+}
+
+// Delete は埋め込みを削除してインデックスから取り除く
+func (s *EmbeddingStore) Delete(kind string, id uint) error {
+	s.index(kind).Remove(id)
+	return s.repo.Delete(kind, id)
+}
+
+// Search は kind の中から query に近い順に最大 k 件を返す
+func (s *EmbeddingStore) Search(kind string, query []float32, k int) []vectorindex.Result {
+	return s.index(kind).Search(query, k)
+}
+
+// Len は kind のインデックスに登録されている件数を返す
+func (s *EmbeddingStore) Len(kind string) int {
+	return s.index(kind).Len()
+}
+
+func (s *EmbeddingStore) index(kind string) *vectorindex.HNSW {
+	s.mu.RLock()
+	index, ok := s.indexes[kind]
+	s.mu.RUnlock()
+	if ok {
+		return index
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index, ok = s.indexes[kind]; !ok {
+		index = vectorindex.NewHNSW(s.cfg)
+		s.indexes[kind] = index
+	}
+	return index
+}
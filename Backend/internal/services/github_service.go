@@ -1,8 +1,8 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/internal/models"
-	"Backend/internal/openai"
 	"Backend/internal/repositories"
 	"bytes"
 	"context"
@@ -33,14 +33,14 @@ type GitHubService struct {
 	skillScoreService *SkillScoreService
 	apiBaseURL        string // テスト用オーバーライド（空なら githubAPIBase を使用）
 	graphQLURL        string // テスト用オーバーライド（空なら githubGraphQLURL を使用）
-	openaiClient      *openai.Client
+	llm               ai.LLMClient
 }
 
-func NewGitHubService(githubRepo *repositories.GitHubRepository, skillScoreService *SkillScoreService, openaiClient *openai.Client) *GitHubService {
+func NewGitHubService(githubRepo *repositories.GitHubRepository, skillScoreService *SkillScoreService, llm ai.LLMClient) *GitHubService {
 	return &GitHubService{
 		githubRepo:        githubRepo,
 		skillScoreService: skillScoreService,
-		openaiClient:      openaiClient,
+		llm:               llm,
 	}
 }
 
@@ -73,6 +73,11 @@ func (s *GitHubService) StoreAccessToken(userID uint, login, accessToken string)
 	return s.githubRepo.UpsertProfile(profile)
 }
 
+// DisconnectAccount GitHub連携の解除時に保存済みアクセストークンを破棄する
+func (s *GitHubService) DisconnectAccount(userID uint) error {
+	return s.githubRepo.ClearAccessToken(userID)
+}
+
 // TriggerAsyncSync 非同期でGitHubデータ同期を開始する（ノンブロッキング）
 // force=true でキャッシュを無視して強制同期する
 func (s *GitHubService) TriggerAsyncSync(userID uint, force bool) {
@@ -88,6 +93,7 @@ func (s *GitHubService) TriggerAsyncSync(userID uint, force bool) {
 // SyncUserData GitHubからリポジトリ・言語比率・コントリビューション数を取得してDBに保存する
 // force=true でキャッシュを無視して強制同期する
 func (s *GitHubService) SyncUserData(ctx context.Context, userID uint, force bool) error {
+	ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: userID, Feature: ai.FeatureGitHubSummary})
 	profile, err := s.githubRepo.GetProfile(userID)
 	if err != nil {
 		return fmt.Errorf("get profile: %w", err)
@@ -95,6 +101,9 @@ func (s *GitHubService) SyncUserData(ctx context.Context, userID uint, force boo
 	if profile == nil {
 		return fmt.Errorf("github profile not found for user %d", userID)
 	}
+	if profile.AccessToken == "" {
+		return fmt.Errorf("github account is not linked for user %d", userID)
+	}
 
 	// キャッシュチェック: 1時間以内に同期済みならスキップ（強制同期時はスキップしない）
 	if !force && profile.SyncedAt != nil && time.Since(*profile.SyncedAt) < syncCacheDuration {
@@ -248,10 +257,12 @@ func (s *GitHubService) fetchREADME(ctx context.Context, client *http.Client, to
 	return resp.Content, nil
 }
 
+const repoSummaryCacheTTL = 7 * 24 * time.Hour
+
 // generateRepoSummary OpenAIを使ってリポジトリの技術的強みを要約する
 func (s *GitHubService) generateRepoSummary(ctx context.Context, fullName, readme string) (*models.GitHubRepoSummary, error) {
-	if s.openaiClient == nil {
-		return nil, fmt.Errorf("openai client not configured")
+	if s.llm == nil {
+		return nil, fmt.Errorf("llm client not configured")
 	}
 
 	readmeSection := "（READMEなし）"
@@ -277,22 +288,15 @@ GitHubリポジトリのREADMEを読み、技術的な強みを簡潔にまと
 
 ※ 情報が不足している場合はREADMEから推測して記述してください。各フィールドは1〜2文で簡潔に。`, fullName, readmeSection)
 
-	raw, err := s.openaiClient.ChatCompletionJSON(ctx, systemPrompt, userPrompt, 0.5, 800)
+	// README が同じなら要約も同じでよいので、再同期時はキャッシュを使う
+	payload, err := ai.GenerateStructured[repoSummaryPayload](ai.WithCache(ctx, "repo_summary", repoSummaryCacheTTL), s.llm, systemPrompt, userPrompt, ai.WithTemperature(0.5), ai.WithMaxTokens(800))
+	if errors.Is(err, ai.ErrInvalidStructuredOutput) {
+		return nil, fmt.Errorf("parse summary json: %w", err)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	cleaned := extractRepoSummaryJSON(raw)
-	var payload struct {
-		SummaryText string `json:"summary_text"`
-		TechReason  string `json:"tech_reason"`
-		Challenge   string `json:"challenge"`
-		Achievement string `json:"achievement"`
-	}
-	if err := json.Unmarshal([]byte(cleaned), &payload); err != nil {
-		return nil, fmt.Errorf("parse summary json: %w", err)
-	}
-
 	// FullName からユーザーIDは呼び出し元で設定するため0を仮置き
 	return &models.GitHubRepoSummary{
 		FullName:    fullName,
@@ -303,16 +307,12 @@ GitHubリポジトリのREADMEを読み、技術的な強みを簡潔にまと
 	}, nil
 }
 
-// extractRepoSummaryJSON マークダウンコードフェンスを除去してJSONを抽出する
-func extractRepoSummaryJSON(raw string) string {
-	s := strings.TrimSpace(raw)
-	if start := strings.Index(s, "{"); start > 0 {
-		s = s[start:]
-	}
-	if end := strings.LastIndex(s, "}"); end >= 0 && end < len(s)-1 {
-		s = s[:end+1]
-	}
-	return s
+// repoSummaryPayload リポジトリ要約の LLM 応答の形式
+type repoSummaryPayload struct {
+	SummaryText string `json:"summary_text"`
+	TechReason  string `json:"tech_reason"`
+	Challenge   string `json:"challenge"`
+	Achievement string `json:"achievement"`
 }
 
 // --- 内部ヘルパー ---
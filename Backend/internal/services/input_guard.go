@@ -0,0 +1,464 @@
+package services
+
+import (
+	"Backend/domain/ai"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/services/prompts"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInputRejected は入力ガードが利用者の入力を受け付けなかったことを表す。
+var ErrInputRejected = errors.New("input rejected by guard")
+
+// InputRejectedMessage 入力ガードで入力を受け付けなかったことを利用者に伝える文言
+const InputRejectedMessage = "入力内容を受け付けられませんでした。AI への指示や不適切な表現を含めずに、もう一度入力してください"
+
+// 入力ガードの対処（機能ごとに検出の種類に応じて選ぶ）
+const (
+	GuardContinue = "continue" // 記録だけして入力をそのまま使う
+	GuardSanitize = "sanitize" // 該当箇所を取り除いた入力を使う
+	GuardBlock    = "block"    // 入力を受け付けない
+)
+
+// 入力ガードが検出する種類
+const (
+	GuardKindInjection  = "injection"  // 指示の上書き・プロンプトの聞き出し・評価結果の指定
+	GuardKindOversized  = "oversized"  // 機能ごとの上限を超える長さ
+	GuardKindDisallowed = "disallowed" // 脅迫などサービスで扱わない内容
+)
+
+// 検出元
+const (
+	GuardSourceHeuristic  = "heuristic"
+	GuardSourceClassifier = "classifier"
+)
+
+// FeatureESReview ES 添削（RAG サービス経由で LLM に渡る）の機能名。入力ガード・個人情報の置き換えの方針に使う
+const FeatureESReview = "es_review"
+
+// InputGuardPolicy 機能ごとの入力の上限と検出時の対処
+type InputGuardPolicy struct {
+	MaxRunes      int    // これを超える入力は oversized（0 なら無制限）
+	OnInjection   string // Guard* 定数
+	OnOversized   string // sanitize なら MaxRunes 文字で切り詰める
+	OnDisallowed  string
+	UseClassifier bool // ヒューリスティックで検出しなかった入力を LLM の分類器でも判定する（分類器を設定した場合のみ）
+	// BlockOnAgreement はヒューリスティックと分類器の両方が指示の上書きと判定した入力だけ受け付けない。
+	// ヒューリスティックで検出した入力も分類器で判定し、片方だけの検出には OnInjection を使う（UseClassifier と併用する）
+	BlockOnAgreement bool
+}
+
+// defaultInputGuardPolicy 方針を設定していない機能に使う
+var defaultInputGuardPolicy = InputGuardPolicy{MaxRunes: 10000, OnInjection: GuardSanitize, OnOversized: GuardSanitize, OnDisallowed: GuardBlock}
+
+// DefaultInputGuardPolicies 機能ごとの既定の方針
+// 指示の上書きは該当箇所を除いて続ける。チャットは普通の回答を誤検出しやすいため、
+// ヒューリスティックと分類器の両方が検出した場合だけ受け付けない
+func DefaultInputGuardPolicies() map[string]InputGuardPolicy {
+	return map[string]InputGuardPolicy{
+		ai.FeatureChat:         {MaxRunes: 2000, OnInjection: GuardSanitize, OnOversized: GuardSanitize, OnDisallowed: GuardBlock, UseClassifier: true, BlockOnAgreement: true},
+		ai.FeatureESRewrite:    {MaxRunes: 4000, OnInjection: GuardSanitize, OnOversized: GuardBlock, OnDisallowed: GuardBlock, UseClassifier: true},
+		FeatureESReview:        {MaxRunes: 4000, OnInjection: GuardSanitize, OnOversized: GuardBlock, OnDisallowed: GuardBlock, UseClassifier: true},
+		ai.FeatureResumeReview: {MaxRunes: 30000, OnInjection: GuardSanitize, OnOversized: GuardSanitize, OnDisallowed: GuardContinue},
+		ai.FeatureInterview:    {MaxRunes: 2000, OnInjection: GuardSanitize, OnOversized: GuardSanitize, OnDisallowed: GuardContinue},
+	}
+}
+
+// actionFor は kind の検出に対する対処を返す
+func (p InputGuardPolicy) actionFor(kind string) string {
+	var action string
+	switch kind {
+	case GuardKindInjection:
+		action = p.OnInjection
+	case GuardKindOversized:
+		action = p.OnOversized
+	case GuardKindDisallowed:
+		action = p.OnDisallowed
+	}
+	if action == "" {
+		return GuardContinue
+	}
+	return action
+}
+
+// GuardFinding 入力ガードの検出 1 件
+type GuardFinding struct {
+	Kind    string `json:"kind"`
+	Rule    string `json:"rule"`
+	Source  string `json:"source"`
+	Excerpt string `json:"excerpt"`
+}
+
+// GuardResult 入力ガードの判定結果
+type GuardResult struct {
+	Text     string         `json:"-"`      // LLM に渡してよい入力（block なら空）
+	Action   string         `json:"action"` // 検出に対する対処のうち最も強いもの
+	Findings []GuardFinding `json:"findings"`
+}
+
+// Flagged は何か検出したかを返す
+func (r *GuardResult) Flagged() bool {
+	return len(r.Findings) > 0
+}
+
+// Suspicious は指示の上書きか不適切な内容を検出したかを返す（過大な入力の検出だけなら false）
+func (r *GuardResult) Suspicious() bool {
+	return hasFindingKind(r.Findings, GuardKindInjection, GuardKindDisallowed)
+}
+
+// Blocked は入力を受け付けないと判定したかを返す
+func (r *GuardResult) Blocked() bool {
+	return r.Action == GuardBlock
+}
+
+// Err は入力を受け付けない場合に ErrInputRejected を返す
+func (r *GuardResult) Err() error {
+	if !r.Blocked() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInputRejected, strings.Join(r.kinds(), ","))
+}
+
+func (r *GuardResult) kinds() []string {
+	var kinds []string
+	for _, f := range r.Findings {
+		if !slices.Contains(kinds, f.Kind) {
+			kinds = append(kinds, f.Kind)
+		}
+	}
+	return kinds
+}
+
+type guardRule struct {
+	name string
+	kind string
+	re   *regexp.Regexp
+}
+
+// inputGuardRules ヒューリスティックの検出ルール（英語・日本語の既知の手口）
+var inputGuardRules = []guardRule{
+	{"ignore_instructions", GuardKindInjection, regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override|bypass)\b[^.\n。]{0,40}?\b(previous|prior|above|earlier|preceding|all|any|your|the|system)\b[^.\n。]{0,20}?\b(instructions?|prompts?|rules|directions|guidelines)\b`)},
+	{"reveal_prompt", GuardKindInjection, regexp.MustCompile(`(?i)\b(reveal|show|print|repeat|output|display|tell me|what (is|are))\b[^.\n。]{0,30}?\b(system|initial|hidden|original|developer)\s+(prompt|instructions?|message)`)},
+	{"role_override", GuardKindInjection, regexp.MustCompile(`(?i)\b(you are now|from now on,? you (are|will)|pretend (to be|you are)|act as if you|developer mode|jailbreak|DAN mode|do anything now)\b`)},
+	{"role_marker", GuardKindInjection, regexp.MustCompile(`(?im)^\s*(system|assistant|developer)\s*[:：]|<\|im_(start|end)\|>|\[/?INST\]|<</?SYS>>|</?(system|instructions?)>|^\s*#{1,6}\s*(system|instructions?)\b`)},
+	{"ja_ignore_instructions", GuardKindInjection, regexp.MustCompile(`(これまで|今まで|以前|前|上記|上|先ほど|最初)の(すべての|全ての|全部の)?(指示|命令|プロンプト|ルール|設定|制約)(は|を)?(すべて|全て|全部)?(無視|忘れ|破棄|リセット|取り消)`)},
+	{"ja_reveal_prompt", GuardKindInjection, regexp.MustCompile(`(システムプロンプト|内部の?指示|元の指示|初期設定|プロンプト)(の内容)?を(表示|出力|教え|見せ|開示|繰り返)`)},
+	{"ja_role_override", GuardKindInjection, regexp.MustCompile(`(今から|これから|以降)は?(あなた|君|お前)は|(開発者|デベロッパー)モード|脱獄|(制限|制約|ルール)を(解除|無視)(しろ|せよ|しなさい|して(ください|下さい|ほしい|欲しい|くれ|答え|回答))`)},
+	{"score_manipulation", GuardKindInjection, regexp.MustCompile(`(?i)(満点|100\s*点|百点|最高評価|最高点)(を|と|に)(つけ|付け|与え|評価し|採点し|出力し|し)(て(ください|下さい|ほしい|欲しい|くれ)|ろ|なさい|ること)|(評価|採点|スコア|点数)は(必ず)?(満点|100\s*点|最高評価|最高点)(に|と)(する|して|しろ|すること)|\b(give|assign|rate)\b[^.\n]{0,30}?(100\s*(/\s*100|points)|full marks|perfect score|maximum score|highest score)`)},
+	{"threat", GuardKindDisallowed, regexp.MustCompile(`(?i)(殺す|殺し|ぶっ殺|爆破|放火)(ぞ|てやる|します|する予定|予告)|死ね|氏ね|\b(kill yourself|kys)\b|\bi('m| am| will| am going to)\s+(kill|shoot|bomb)\b`)},
+}
+
+// isHiddenControl は文字の向きの制御・不可視の演算子・タグ文字（指示を隠して埋め込む手口に使われる）かを返す
+func isHiddenControl(r rune) bool {
+	return (r >= 0x202A && r <= 0x202E) || (r >= 0x2066 && r <= 0x2069) || (r >= 0x2060 && r <= 0x2064) || (r >= 0xE0000 && r <= 0xE007F)
+}
+
+// inputGuardVerdict LLM の分類器の判定
+type inputGuardVerdict struct {
+	Injection  bool   `json:"injection"`
+	Disallowed bool   `json:"disallowed"`
+	Reason     string `json:"reason"`
+}
+
+// classifierMaxRunes 分類器に渡す入力の上限
+const classifierMaxRunes = 4000
+
+// InputGuard 利用者の入力を LLM に渡す前に、指示の上書き・過大な入力・不適切な内容を検出する
+// 検出した入力は flagged_inputs に記録し、機能ごとの方針（InputGuardPolicy）で続行・除去・拒否を決める
+// nil の InputGuard は何も検出しない
+type InputGuard struct {
+	repo       repository.FlaggedInputRepository
+	policies   map[string]InputGuardPolicy
+	blocklist  []string
+	classifier ai.LLMClient
+	prompts    *prompts.Registry
+}
+
+func NewInputGuard(repo repository.FlaggedInputRepository) *InputGuard {
+	return &InputGuard{repo: repo, policies: DefaultInputGuardPolicies()}
+}
+
+// SetPolicy は機能の方針を上書きする（起動時に呼ぶ）
+func (g *InputGuard) SetPolicy(feature string, policy InputGuardPolicy) {
+	g.policies[feature] = policy
+}
+
+// SetBlocklist は disallowed として扱う語句を追加する（大文字・小文字は区別しない）
+func (g *InputGuard) SetBlocklist(words []string) {
+	g.blocklist = nil
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			g.blocklist = append(g.blocklist, w)
+		}
+	}
+}
+
+// SetClassifier は LLM の分類器を有効にする（未設定ならヒューリスティックだけで判定する）
+func (g *InputGuard) SetClassifier(llm ai.LLMClient, registry *prompts.Registry) {
+	g.classifier = llm
+	g.prompts = registry
+}
+
+// InputGuardBlocklistFromEnv は INPUT_GUARD_BLOCKLIST（カンマ区切り）の語句を返す
+func InputGuardBlocklistFromEnv() []string {
+	value := strings.TrimSpace(os.Getenv("INPUT_GUARD_BLOCKLIST"))
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// Policy は feature の方針を返す
+func (g *InputGuard) Policy(feature string) InputGuardPolicy {
+	if p, ok := g.policies[feature]; ok {
+		return p
+	}
+	return defaultInputGuardPolicy
+}
+
+// Check は feature の入力 text を判定し、方針に従って LLM に渡す入力を返す。
+// 分類器の判定は該当箇所を特定できないため、sanitize の方針では入力をそのまま使い記録だけ残す。
+// BlockOnAgreement の方針ではヒューリスティックと分類器の両方が検出した指示の上書きを block にする。
+// 分類器の呼び出しに失敗した場合はヒューリスティックの判定だけを使う
+func (g *InputGuard) Check(ctx context.Context, feature, text string) *GuardResult {
+	result := &GuardResult{Text: text, Action: GuardContinue}
+	if g == nil || strings.TrimSpace(text) == "" {
+		return result
+	}
+	policy := g.Policy(feature)
+	result.Findings = g.scan(text, policy)
+	confirm := policy.BlockOnAgreement && hasFindingKind(result.Findings, GuardKindInjection)
+	if policy.UseClassifier && g.classifier != nil && !hasFindingKind(result.Findings, GuardKindDisallowed) &&
+		(confirm || !hasFindingKind(result.Findings, GuardKindInjection)) {
+		result.Findings = append(result.Findings, g.classify(ctx, feature, text)...)
+	}
+	agreed := confirm && hasFindingFrom(result.Findings, GuardKindInjection, GuardSourceClassifier)
+	if !result.Flagged() {
+		return result
+	}
+
+	sanitizeNeeded := false
+	for _, f := range result.Findings {
+		action := policy.actionFor(f.Kind)
+		if f.Source == GuardSourceClassifier && action == GuardSanitize {
+			action = GuardContinue
+		}
+		if agreed && f.Kind == GuardKindInjection {
+			action = GuardBlock
+		}
+		if guardActionRank(action) > guardActionRank(result.Action) {
+			result.Action = action
+		}
+		sanitizeNeeded = sanitizeNeeded || action == GuardSanitize
+	}
+	switch {
+	case result.Blocked():
+		result.Text = ""
+	case sanitizeNeeded:
+		result.Text = g.sanitize(text, policy)
+	}
+	g.record(ctx, feature, text, result)
+	return result
+}
+
+// Sanitize は feature の方針で sanitize とした種類の該当箇所を text から取り除く（記録・分類器の判定はしない）。
+// 長い文書を Check で判定したあと、文書の断片ごとに同じ除去をかける場合に使う
+func (g *InputGuard) Sanitize(feature, text string) string {
+	if g == nil {
+		return text
+	}
+	return g.sanitize(text, g.Policy(feature))
+}
+
+// List は直近の検出履歴を返す（管理画面用）
+func (g *InputGuard) List(feature string, limit int) ([]models.FlaggedInput, error) {
+	if g == nil || g.repo == nil {
+		return []models.FlaggedInput{}, nil
+	}
+	return g.repo.List(feature, limit)
+}
+
+// scan はヒューリスティックで text を判定する
+func (g *InputGuard) scan(text string, policy InputGuardPolicy) []GuardFinding {
+	var findings []GuardFinding
+	if policy.MaxRunes > 0 {
+		if n := utf8.RuneCountInString(text); n > policy.MaxRunes {
+			findings = append(findings, GuardFinding{
+				Kind:    GuardKindOversized,
+				Rule:    fmt.Sprintf("max_%d_runes", policy.MaxRunes),
+				Source:  GuardSourceHeuristic,
+				Excerpt: truncateRunes(text, 100),
+			})
+		}
+	}
+	if i := strings.IndexFunc(text, isHiddenControl); i >= 0 {
+		findings = append(findings, GuardFinding{
+			Kind:    GuardKindInjection,
+			Rule:    "hidden_characters",
+			Source:  GuardSourceHeuristic,
+			Excerpt: excerptAround(text, i, i),
+		})
+	}
+	for _, rule := range inputGuardRules {
+		if loc := rule.re.FindStringIndex(text); loc != nil {
+			findings = append(findings, GuardFinding{Kind: rule.kind, Rule: rule.name, Source: GuardSourceHeuristic, Excerpt: excerptAround(text, loc[0], loc[1])})
+		}
+	}
+	lower := strings.ToLower(text)
+	for _, word := range g.blocklist {
+		if i := strings.Index(lower, word); i >= 0 {
+			findings = append(findings, GuardFinding{Kind: GuardKindDisallowed, Rule: "blocklist", Source: GuardSourceHeuristic, Excerpt: excerptAround(lower, i, i+len(word))})
+			break
+		}
+	}
+	return findings
+}
+
+// sanitize は方針で sanitize とした種類の該当箇所を取り除く
+func (g *InputGuard) sanitize(text string, policy InputGuardPolicy) string {
+	if policy.actionFor(GuardKindInjection) == GuardSanitize {
+		text = strings.Map(func(r rune) rune {
+			if isHiddenControl(r) {
+				return -1
+			}
+			return r
+		}, text)
+		for _, rule := range inputGuardRules {
+			if rule.kind == GuardKindInjection {
+				text = rule.re.ReplaceAllString(text, "[filtered]")
+			}
+		}
+	}
+	if policy.actionFor(GuardKindDisallowed) == GuardSanitize {
+		for _, rule := range inputGuardRules {
+			if rule.kind == GuardKindDisallowed {
+				text = rule.re.ReplaceAllString(text, "***")
+			}
+		}
+		for _, word := range g.blocklist {
+			text = regexp.MustCompile(`(?i)`+regexp.QuoteMeta(word)).ReplaceAllString(text, "***")
+		}
+	}
+	if policy.actionFor(GuardKindOversized) == GuardSanitize && policy.MaxRunes > 0 {
+		text = truncateRunes(text, policy.MaxRunes)
+	}
+	return text
+}
+
+// classify は LLM の分類器で text を判定する
+func (g *InputGuard) classify(ctx context.Context, feature, text string) []GuardFinding {
+	prompt, err := g.prompts.Render(ctx, prompts.InputGuard, prompts.Vars{"Feature": feature, "Text": truncateRunes(text, classifierMaxRunes)})
+	if err != nil {
+		log.Printf("[InputGuard] failed to build classifier prompt: %v", err)
+		return nil
+	}
+	verdict, err := ai.GenerateStructured[inputGuardVerdict](ctx, g.classifier, prompt.System, prompt.User,
+		ai.WithTemperature(0), ai.WithMaxTokens(200), ai.WithRepairRetries(0))
+	if err != nil {
+		log.Printf("[InputGuard] classifier failed (feature=%s): %v", feature, err)
+		return nil
+	}
+	excerpt := truncateRunes(text, 150)
+	if reason := strings.TrimSpace(verdict.Reason); reason != "" {
+		excerpt = truncateRunes(reason, 50) + ": " + excerpt
+	}
+	var findings []GuardFinding
+	if verdict.Injection {
+		findings = append(findings, GuardFinding{Kind: GuardKindInjection, Rule: "classifier", Source: GuardSourceClassifier, Excerpt: excerpt})
+	}
+	if verdict.Disallowed {
+		findings = append(findings, GuardFinding{Kind: GuardKindDisallowed, Rule: "classifier", Source: GuardSourceClassifier, Excerpt: excerpt})
+	}
+	return findings
+}
+
+// record は検出した入力を flagged_inputs に記録する（入力全文は残さない）
+func (g *InputGuard) record(ctx context.Context, feature, text string, result *GuardResult) {
+	var rules, sources []string
+	for _, f := range result.Findings {
+		if !slices.Contains(rules, f.Rule) {
+			rules = append(rules, f.Rule)
+		}
+		if !slices.Contains(sources, f.Source) {
+			sources = append(sources, f.Source)
+		}
+	}
+	userID := ai.CallContextFrom(ctx).UserID
+	log.Printf("[InputGuard] flagged input: feature=%s user=%d action=%s rules=%s", feature, userID, result.Action, strings.Join(rules, ","))
+	if g.repo == nil {
+		return
+	}
+	entry := &models.FlaggedInput{
+		UserID:      userID,
+		Feature:     feature,
+		Action:      result.Action,
+		Kinds:       strings.Join(result.kinds(), ","),
+		Rules:       truncateRunes(strings.Join(rules, ","), 255),
+		Source:      strings.Join(sources, ","),
+		Excerpt:     truncateRunes(result.Findings[0].Excerpt, 500),
+		InputLength: utf8.RuneCountInString(text),
+	}
+	if err := g.repo.Create(entry); err != nil {
+		log.Printf("[InputGuard] failed to record flagged input: %v", err)
+	}
+}
+
+func guardActionRank(action string) int {
+	switch action {
+	case GuardBlock:
+		return 2
+	case GuardSanitize:
+		return 1
+	}
+	return 0
+}
+
+func hasFindingKind(findings []GuardFinding, kinds ...string) bool {
+	for _, f := range findings {
+		if slices.Contains(kinds, f.Kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFindingFrom(findings []GuardFinding, kind, source string) bool {
+	for _, f := range findings {
+		if f.Kind == kind && f.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// excerptAround は text の [start, end) の前後 30 文字を含む抜粋を返す
+func excerptAround(text string, start, end int) string {
+	from := start
+	for i := 0; i < 30 && from > 0; i++ {
+		_, size := utf8.DecodeLastRuneInString(text[:from])
+		from -= size
+	}
+	to := end
+	for i := 0; i < 30 && to < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[to:])
+		to += size
+	}
+	return truncateRunes(text[from:to], 200)
+}
+
+// truncateRunes は s を先頭から最大 n 文字にする
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
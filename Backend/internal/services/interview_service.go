@@ -1,9 +1,11 @@
 package services
 
 import (
+	"Backend/domain/ai"
+	"Backend/domain/entity"
 	"Backend/domain/repository"
 	"Backend/internal/models"
-	"Backend/internal/openai"
+	"Backend/internal/services/prompts"
 	"context"
 	"encoding/json"
 	"errors"
@@ -23,9 +25,11 @@ type InterviewService struct {
 	reportRepo           repository.InterviewReportRepository
 	userRepo             repository.UserRepository
 	emailService         *EmailService
-	openaiClient         *openai.Client
+	llm                  ai.LLMClient
 	realtimeUsageService *RealtimeUsageService
 	crossFeature         *CrossFeatureIntegrationService
+	promptRegistry       *prompts.Registry
+	inputGuard           *InputGuard
 	jobCh                chan uint
 	workerOnce           sync.Once
 }
@@ -36,7 +40,7 @@ func NewInterviewService(
 	reportRepo repository.InterviewReportRepository,
 	userRepo repository.UserRepository,
 	emailService *EmailService,
-	openaiClient *openai.Client,
+	llm ai.LLMClient,
 	realtimeUsageService *RealtimeUsageService,
 ) *InterviewService {
 	return &InterviewService{
@@ -45,7 +49,7 @@ func NewInterviewService(
 		reportRepo:           reportRepo,
 		userRepo:             userRepo,
 		emailService:         emailService,
-		openaiClient:         openaiClient,
+		llm:                  llm,
 		realtimeUsageService: realtimeUsageService,
 		jobCh:                make(chan uint, 100),
 	}
@@ -56,6 +60,16 @@ func (s *InterviewService) SetCrossFeatureService(cf *CrossFeatureIntegrationSer
 	s.crossFeature = cf
 }
 
+// SetPromptRegistry プロンプトレジストリを注入する（未設定ならファイルの既定版を使う）
+func (s *InterviewService) SetPromptRegistry(registry *prompts.Registry) {
+	s.promptRegistry = registry
+}
+
+// SetInputGuard 面接の回答を LLM に渡す前の入力ガードを注入する（未設定なら判定しない）
+func (s *InterviewService) SetInputGuard(guard *InputGuard) {
+	s.inputGuard = guard
+}
+
 func (s *InterviewService) StartWorker() {
 	s.workerOnce.Do(func() {
 		go s.runWorker()
@@ -208,7 +222,7 @@ func (s *InterviewService) ListAllSessionsAdmin(limit int, offset int) ([]Interv
 func (s *InterviewService) ListSessions(userID uint, all bool, limit int, offset int) ([]InterviewSessionResponse, int64, error) {
 	if all {
 		user, err := s.userRepo.GetUserByID(userID)
-		if err != nil || user == nil || !user.IsAdmin {
+		if err != nil || user == nil || !user.HasAdminRole() {
 			return nil, 0, errors.New("forbidden")
 		}
 		total, err := s.sessionRepo.CountAll()
@@ -256,8 +270,8 @@ func (s *InterviewService) GetSessionDetailWithRole(userID uint, sessionID uint,
 		}
 		report = nil
 	}
-	// 教員以外には教員用レポートを返さない
-	if report != nil && role != "teacher" {
+	// 教員・管理者以外には教員用レポートを返さない
+	if report != nil && role != entity.RoleTeacher && role != entity.RoleAdmin {
 		sanitized := *report
 		sanitized.TeacherReportJSON = ""
 		report = &sanitized
@@ -295,6 +309,7 @@ type PhraseSuggestion struct {
 
 // GetPhraseSuggestions はセッションのユーザー発話を分析し、言い換え提案を返す。
 func (s *InterviewService) GetPhraseSuggestions(ctx context.Context, userID uint, sessionID uint) ([]PhraseSuggestion, error) {
+	ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: userID, Feature: ai.FeatureInterview})
 	session, err := s.sessionRepo.FindByID(sessionID)
 	if err != nil {
 		return nil, err
@@ -330,20 +345,21 @@ func (s *InterviewService) GetPhraseSuggestions(ctx context.Context, userID uint
 %s`, transcript)
 
 	model := getEnv("INTERVIEW_REPORT_MODEL", "")
-	raw, err := s.openaiClient.ChatCompletionJSON(ctx, systemPrompt, userPrompt, 0.5, 1000, model)
+	payload, err := ai.GenerateStructured[phraseSuggestionsPayload](ctx, s.llm, systemPrompt, userPrompt, ai.WithTemperature(0.5), ai.WithMaxTokens(1000), ai.WithModel(model))
+	if errors.Is(err, ai.ErrInvalidStructuredOutput) {
+		return nil, fmt.Errorf("failed to parse suggestions: %w", err)
+	}
 	if err != nil {
 		return nil, err
 	}
-	raw = ExtractJSONObject(raw)
-	var payload struct {
-		Suggestions []PhraseSuggestion `json:"suggestions"`
-	}
-	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
-		return nil, fmt.Errorf("failed to parse suggestions: %w", err)
-	}
 	return payload.Suggestions, nil
 }
 
+// phraseSuggestionsPayload は言い換え提案の LLM 応答の形式。
+type phraseSuggestionsPayload struct {
+	Suggestions []PhraseSuggestion `json:"suggestions"`
+}
+
 // InterviewTrendPoint は面接トレンド分析の1データポイント。
 type InterviewTrendPoint struct {
 	SessionID     uint      `json:"session_id"`
@@ -412,6 +428,7 @@ func (s *InterviewService) GetTrend(userID uint, limit int) ([]InterviewTrendPoi
 }
 
 func (s *InterviewService) CreateRealtimeToken(ctx context.Context, userID uint, sessionID uint) (string, error) {
+	ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: userID, Feature: ai.FeatureInterview})
 	session, err := s.sessionRepo.FindByID(sessionID)
 	if err != nil {
 		return "", err
@@ -439,11 +456,11 @@ func (s *InterviewService) CreateRealtimeToken(ctx context.Context, userID uint,
 	if gender == "" {
 		gender = "female"
 	}
-	model := getEnv("OPENAI_REALTIME_MODEL", "gpt-4o-realtime-preview")
+	model := RealtimeModel()
 	voice := realtimeVoiceForLangAndGender(lang, gender)
 	transcribeModel := getEnv("OPENAI_REALTIME_TRANSCRIBE_MODEL", "gpt-4o-mini-transcribe")
 	maxTokens := getIntEnv("OPENAI_REALTIME_MAX_OUTPUT_TOKENS", 120)
-	req := openai.RealtimeSessionRequest{
+	req := ai.RealtimeSessionRequest{
 		Model:        model,
 		Modalities:   []string{"audio"},
 		Voice:        voice,
@@ -460,7 +477,7 @@ func (s *InterviewService) CreateRealtimeToken(ctx context.Context, userID uint,
 		},
 		MaxResponseOutputTokens: maxTokens,
 	}
-	resp, err := s.openaiClient.CreateRealtimeClientSecret(ctx, req)
+	resp, err := s.llm.CreateRealtimeSession(ctx, req)
 	if err != nil {
 		return "", err
 	}
@@ -469,7 +486,7 @@ func (s *InterviewService) CreateRealtimeToken(ctx context.Context, userID uint,
 			return "", err
 		}
 	}
-	return resp.ClientSecret.Value, nil
+	return resp.ClientSecret, nil
 }
 
 func (s *InterviewService) estimateCost(start, end *time.Time) float64 {
@@ -480,6 +497,9 @@ func (s *InterviewService) estimateCost(start, end *time.Time) float64 {
 	if minutes < 0 {
 		return 0
 	}
+	if s.realtimeUsageService != nil {
+		return minutes * s.realtimeUsageService.RatePerMinute(*start)
+	}
 	rate := getFloatEnv("INTERVIEW_COST_PER_MIN_USD", 0.18)
 	return minutes * rate
 }
@@ -493,6 +513,7 @@ type TurnResult struct {
 
 // Turn はユーザー音声を受け取り、STT→Chat→TTSを実行してTurnResultを返します
 func (s *InterviewService) Turn(ctx context.Context, userID uint, sessionID uint, audioData []byte, history []map[string]string, companyName, companyReading, position, companyInfo, companyType string) (*TurnResult, error) {
+	ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: userID, Feature: ai.FeatureInterview})
 	session, err := s.sessionRepo.FindByID(sessionID)
 	if err != nil {
 		return nil, err
@@ -502,7 +523,7 @@ func (s *InterviewService) Turn(ctx context.Context, userID uint, sessionID uint
 	}
 
 	// STT: Whisper でユーザー音声をテキスト化
-	userText, err := s.openaiClient.Transcribe(ctx, audioData, "audio.webm")
+	userText, err := s.llm.TranscribeAudio(ctx, audioData, "audio.webm")
 	if err != nil {
 		return nil, fmt.Errorf("transcribe error: %w", err)
 	}
@@ -520,8 +541,20 @@ func (s *InterviewService) Turn(ctx context.Context, userID uint, sessionID uint
 		companyInfo = s.lookupCompanyProfile(ctx, companyName)
 	}
 
+	// 入力ガード（返す文字起こしはそのまま、面接官の AI には判定後の発言を渡す）
+	guard := s.inputGuard.Check(ctx, ai.FeatureInterview, userText)
+	if err := guard.Err(); err != nil {
+		return nil, err
+	}
+	// 過去の発言はクライアントから送られるため、判定済みでも同じ除去をかけ直す
+	for _, m := range history {
+		if m["role"] == "user" {
+			m["content"] = s.inputGuard.Sanitize(ai.FeatureInterview, m["content"])
+		}
+	}
+
 	// 履歴にユーザー発言を追加
-	history = append(history, map[string]string{"role": "user", "content": userText})
+	history = append(history, map[string]string{"role": "user", "content": guard.Text})
 
 	// Chat: 面接官として返答生成
 	systemPrompt := buildInterviewSystemPrompt(companyName, companyReading, position, companyInfo, companyType)
@@ -530,14 +563,14 @@ func (s *InterviewService) Turn(ctx context.Context, userID uint, sessionID uint
 			systemPrompt = profileCtx + "\n" + systemPrompt
 		}
 	}
-	aiText, err := s.openaiClient.ChatInterview(ctx, systemPrompt, history)
+	aiText, err := s.chatAsInterviewer(ctx, systemPrompt, history)
 	if err != nil {
 		return nil, fmt.Errorf("chat error: %w", err)
 	}
 
 	// TTS: AI返答を音声化
 	voice := ttsVoiceForGenderAndLang(session.InterviewerGender, session.Language)
-	audio, err := s.openaiClient.TTS(ctx, aiText, voice)
+	audio, err := s.llm.SynthesizeSpeech(ctx, aiText, voice)
 	if err != nil {
 		return nil, fmt.Errorf("tts error: %w", err)
 	}
@@ -547,6 +580,7 @@ func (s *InterviewService) Turn(ctx context.Context, userID uint, sessionID uint
 
 // StartTurn は面接開始の最初のAI発話を生成します
 func (s *InterviewService) StartTurn(ctx context.Context, userID uint, sessionID uint, companyName, companyReading, position, companyInfo, companyType string) (*TurnResult, error) {
+	ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: userID, Feature: ai.FeatureInterview})
 	session, err := s.sessionRepo.FindByID(sessionID)
 	if err != nil {
 		return nil, err
@@ -571,7 +605,7 @@ func (s *InterviewService) StartTurn(ctx context.Context, userID uint, sessionID
 			systemPromptStart = profileCtx + "\n" + systemPromptStart
 		}
 	}
-	aiText, err := s.openaiClient.ChatInterview(ctx, systemPromptStart, []map[string]string{
+	aiText, err := s.chatAsInterviewer(ctx, systemPromptStart, []map[string]string{
 		{"role": "user", "content": "面接を開始してください。最初の自己紹介・志望動機の質問からお願いします。"},
 	})
 	if err != nil {
@@ -579,7 +613,7 @@ func (s *InterviewService) StartTurn(ctx context.Context, userID uint, sessionID
 	}
 
 	voice := ttsVoiceForGenderAndLang(session.InterviewerGender, session.Language)
-	audio, err := s.openaiClient.TTS(ctx, aiText, voice)
+	audio, err := s.llm.SynthesizeSpeech(ctx, aiText, voice)
 	if err != nil {
 		return nil, fmt.Errorf("tts error: %w", err)
 	}
@@ -587,13 +621,19 @@ func (s *InterviewService) StartTurn(ctx context.Context, userID uint, sessionID
 	return &TurnResult{AIText: aiText, Audio: audio}, nil
 }
 
+// 面接ターンごとに繰り返される企業情報の Web 検索は応答キャッシュを使う
+const (
+	companyReadingCacheTTL = 30 * 24 * time.Hour
+	companyProfileCacheTTL = 7 * 24 * time.Hour
+)
+
 // lookupCompanyReading はWeb検索を使って企業名の日本語読み（ふりがな）を取得します。
 // 取得に失敗した場合は空文字を返します（エラーは無視）。
 func (s *InterviewService) lookupCompanyReading(ctx context.Context, companyName string) string {
 	query := fmt.Sprintf("「%s」の正しい日本語読み（ふりがな）をカタカナで1行だけ答えてください。", companyName)
-	ctxTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	ctxTimeout, cancel := context.WithTimeout(ai.WithCache(ctx, "company_reading", companyReadingCacheTTL), 10*time.Second)
 	defer cancel()
-	result, err := s.openaiClient.WebSearchQuery(ctxTimeout, query)
+	result, err := s.llm.WebSearch(ctxTimeout, query)
 	if err != nil {
 		return ""
 	}
@@ -612,15 +652,28 @@ func (s *InterviewService) lookupCompanyReading(ctx context.Context, companyName
 // 取得に失敗した場合は空文字を返します（エラーは無視）。
 func (s *InterviewService) lookupCompanyProfile(ctx context.Context, companyName string) string {
 	query := fmt.Sprintf("%s 公式サイト 求める人物像 企業理念 事業内容", companyName)
-	ctxTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
+	ctxTimeout, cancel := context.WithTimeout(ai.WithCache(ctx, "company_profile", companyProfileCacheTTL), 15*time.Second)
 	defer cancel()
-	result, err := s.openaiClient.WebSearchQuery(ctxTimeout, query)
+	result, err := s.llm.WebSearch(ctxTimeout, query)
 	if err != nil {
 		return ""
 	}
 	return strings.TrimSpace(result)
 }
 
+// chatAsInterviewer は面接官として会話履歴から次の発話を生成します
+func (s *InterviewService) chatAsInterviewer(ctx context.Context, systemPrompt string, history []map[string]string) (string, error) {
+	messages := []ai.Message{{Role: ai.RoleSystem, Content: systemPrompt}}
+	for _, h := range history {
+		messages = append(messages, ai.Message{Role: h["role"], Content: h["content"]})
+	}
+	return s.llm.Chat(ctx, messages,
+		ai.WithModel(getEnv("OPENAI_INTERVIEW_MODEL", "gpt-4o-mini")),
+		ai.WithTemperature(0.7),
+		ai.WithMaxTokens(200),
+	)
+}
+
 func buildInterviewSystemPrompt(companyName, companyReading, position, companyInfo, companyType string) string {
 	base := `あなたは日本語の就活面接官です。以下を守ってください。
 - 1回の返答は2〜3文以内で短くまとめる
@@ -673,7 +726,7 @@ func (s *InterviewService) isAllowed(actorID uint, ownerID uint) bool {
 	if err != nil || user == nil {
 		return false
 	}
-	return user.IsAdmin
+	return user.HasAdminRole()
 }
 
 // buildTranscript formats utterances into a plain-text transcript for the LLM prompt.
@@ -693,18 +746,41 @@ func BuildTranscript(utterances []models.InterviewUtterance) string {
 	return b.String()
 }
 
-// extractJSONObject strips surrounding markdown code fences and extracts the
-// outermost JSON object from an LLM response.
-// Some models wrap their output in ```json ... ``` even when instructed not to.
-func ExtractJSONObject(raw string) string {
-	s := strings.TrimSpace(raw)
-	if start := strings.Index(s, "{"); start > 0 {
-		s = s[start:]
-	}
-	if end := strings.LastIndex(s, "}"); end >= 0 && end < len(s)-1 {
-		s = s[:end+1]
-	}
-	return s
+// interviewScores は面接レポートの観点別スコア（0〜5 の整数）。
+type interviewScores struct {
+	Logic         int `json:"logic" minimum:"0" maximum:"5"`
+	Specificity   int `json:"specificity" minimum:"0" maximum:"5"`
+	Ownership     int `json:"ownership" minimum:"0" maximum:"5"`
+	Communication int `json:"communication" minimum:"0" maximum:"5"`
+	Enthusiasm    int `json:"enthusiasm" minimum:"0" maximum:"5"`
+}
+
+// interviewEvidence は観点別スコアの根拠となった発言。
+type interviewEvidence struct {
+	Logic         string `json:"logic"`
+	Specificity   string `json:"specificity"`
+	Ownership     string `json:"ownership"`
+	Communication string `json:"communication"`
+	Enthusiasm    string `json:"enthusiasm"`
+}
+
+// teacherReport は教員向けの詳細レポート。
+type teacherReport struct {
+	OverallComment      string            `json:"overall_comment"`
+	DetailedEvidence    map[string]string `json:"detailed_evidence"`
+	CoachingPoints      []string          `json:"coaching_points"`
+	StrengthsForTeacher []string          `json:"strengths_for_teacher"`
+	NextSteps           []string          `json:"next_steps"`
+}
+
+// reportPayload は面接レポートの LLM 応答の形式。
+type reportPayload struct {
+	Summary      string            `json:"summary"`
+	Scores       interviewScores   `json:"scores"`
+	Evidence     interviewEvidence `json:"evidence"`
+	Strengths    []string          `json:"strengths"`
+	Improvements []string          `json:"improvements"`
+	Teacher      *teacherReport    `json:"teacher,omitempty"`
 }
 
 func (s *InterviewService) generateReport(ctx context.Context, sessionID uint) error {
@@ -712,6 +788,7 @@ func (s *InterviewService) generateReport(ctx context.Context, sessionID uint) e
 	if err != nil {
 		return err
 	}
+	ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: session.UserID, Feature: ai.FeatureInterviewReport})
 	lang := session.Language
 	if lang == "" {
 		lang = "ja"
@@ -735,71 +812,19 @@ func (s *InterviewService) generateReport(ctx context.Context, sessionID uint) e
 		return s.reportRepo.Upsert(empty)
 	}
 	transcript := BuildTranscript(utterances)
-	systemPrompt := buildReportSystemPrompt(lang)
-	userPrompt := fmt.Sprintf(`以下の面接ログを読み、下記の評価基準に従ってJSONのみで出力してください。
-出力言語: %s
-
-## 評価基準（各スコアは0〜5の整数）
-- logic（論理性）: 回答が筋道立っているか、主張に一貫性があるか
-- specificity（具体性）: 具体的なエピソードや数値が含まれているか
-- ownership（主体性）: 「私が〜した」という自分起点の表現があるか
-- communication（コミュニケーション力）: 簡潔・明確に伝えられているか、聞き返しが少ないか
-- enthusiasm（積極性・熱意）: 志望動機や意欲が伝わっているか
-
-## 出力フォーマット（このキーと型を厳守してください）
-{
-  "summary": "面接全体の総合評価コメント（2〜3文、生徒向けのやさしい言葉で）",
-  "scores": {"logic": 3, "specificity": 2, "ownership": 4, "communication": 3, "enthusiasm": 4},
-  "evidence": {
-    "logic": "論理性の根拠となった発言",
-    "specificity": "具体性の根拠となった発言",
-    "ownership": "主体性の根拠となった発言",
-    "communication": "コミュニケーション力の根拠となった発言",
-    "enthusiasm": "積極性・熱意の根拠となった発言"
-  },
-  "strengths": ["強み1", "強み2", "強み3"],
-  "improvements": ["改善点1", "改善点2", "改善点3"],
-  "teacher": {
-    "overall_comment": "教員向け総評（指導観点・クラス内での位置づけ等）",
-    "detailed_evidence": {"logic": "詳細な根拠と指導ポイント", "specificity": "詳細な根拠と指導ポイント", "ownership": "詳細な根拠と指導ポイント"},
-    "coaching_points": ["具体的な改善指導ポイント1", "ポイント2", "ポイント3"],
-    "strengths_for_teacher": ["指導者が把握すべき強み1", "強み2"],
-    "next_steps": ["次回面接に向けた具体的な課題1", "課題2"]
-  }
-}
-
-※ scoresは実際の会話内容に基づいて正直に採点してください（全て同じ値は避ける）。
-※ strengths/improvementsは各2〜4件のリスト形式で具体的に記述してください。
-※ teacher以下は教員専用の詳細情報として出力してください。
-
-Interview transcript:
-%s`, lang, transcript)
-
-	model := getEnv("INTERVIEW_REPORT_MODEL", "")
-	raw, err := s.openaiClient.ChatCompletionJSON(ctx, systemPrompt, userPrompt, 0.4, 2000, model)
+	prompt, err := s.promptRegistry.Render(ctx, prompts.InterviewReport, prompts.Vars{"Language": lang, "Transcript": transcript})
 	if err != nil {
 		return err
 	}
-	type teacherReport struct {
-		OverallComment      string            `json:"overall_comment"`
-		DetailedEvidence    map[string]string `json:"detailed_evidence"`
-		CoachingPoints      []string          `json:"coaching_points"`
-		StrengthsForTeacher []string          `json:"strengths_for_teacher"`
-		NextSteps           []string          `json:"next_steps"`
-	}
-	type reportPayload struct {
-		Summary      string            `json:"summary"`
-		Scores       map[string]int    `json:"scores"`
-		Evidence     map[string]string `json:"evidence"`
-		Strengths    []string          `json:"strengths"`
-		Improvements []string          `json:"improvements"`
-		Teacher      *teacherReport    `json:"teacher"`
-	}
-	var payload reportPayload
-	cleaned := ExtractJSONObject(raw)
-	if err := json.Unmarshal([]byte(cleaned), &payload); err != nil {
+
+	model := getEnv("INTERVIEW_REPORT_MODEL", "")
+	payload, err := ai.GenerateStructured[reportPayload](ctx, s.llm, prompt.System, prompt.User, ai.WithTemperature(0.4), ai.WithMaxTokens(2000), ai.WithModel(model))
+	if errors.Is(err, ai.ErrInvalidStructuredOutput) {
 		return fmt.Errorf("invalid report json: %w", err)
 	}
+	if err != nil {
+		return err
+	}
 	scoresJSON, _ := json.Marshal(payload.Scores)
 	evidenceJSON, _ := json.Marshal(payload.Evidence)
 	strengthsJSON, _ := json.Marshal(payload.Strengths)
@@ -817,6 +842,7 @@ Interview transcript:
 		StrengthsJSON:     string(strengthsJSON),
 		ImprovementsJSON:  string(improvementsJSON),
 		TeacherReportJSON: string(teacherJSON),
+		PromptVersion:     prompt.Ref(),
 	}
 	if err := s.reportRepo.Upsert(report); err != nil {
 		return err
@@ -938,32 +964,6 @@ func getFloatEnv(key string, def float64) float64 {
 	return n
 }
 
-// buildReportSystemPrompt 言語コードに応じたレポート生成用システムプロンプトを返す。
-func buildReportSystemPrompt(lang string) string {
-	known := map[string]string{
-		"ja": "あなたは就活面接のアシスタントです。面接ログを読み、要約・評価をJSONで返してください。",
-		"en": "You are a job interview assessment assistant. Read the interview transcript and return evaluation as JSON.",
-		"zh": "你是一位求职面试评估助手。请阅读面试记录并以JSON格式返回评估结果。",
-		"ko": "당신은 취업 면접 평가 어시스턴트입니다. 면접 기록을 읽고 JSON 형식으로 평가를 반환하세요。",
-		"fr": "Vous êtes un assistant d'évaluation d'entretien d'embauche. Lisez la transcription et retournez l'évaluation en JSON.",
-		"es": "Eres un asistente de evaluación de entrevistas de trabajo. Lee la transcripción y devuelve la evaluación en JSON.",
-		"de": "Sie sind ein Assistent zur Bewertung von Vorstellungsgesprächen. Lesen Sie das Transkript und geben Sie die Bewertung als JSON zurück.",
-		"pt": "Você é um assistente de avaliação de entrevistas de emprego. Leia a transcrição e retorne a avaliação em JSON.",
-		"it": "Sei un assistente per la valutazione dei colloqui di lavoro. Leggi la trascrizione e restituisci la valutazione in JSON.",
-		"ar": "أنت مساعد تقييم مقابلات العمل. اقرأ النص وأعد التقييم بصيغة JSON.",
-		"ru": "Вы ассистент по оценке собеседований. Прочитайте транскрипт и верните оценку в формате JSON.",
-		"hi": "आप नौकरी साक्षात्कार मूल्यांकन सहायक हैं। साक्षात्कार का विवरण पढ़ें और मूल्यांकन JSON में लौटाएं।",
-		"th": "คุณเป็นผู้ช่วยประเมินการสัมภาษณ์งาน อ่านบทสนทนาแล้วส่งคืนการประเมินในรูปแบบ JSON",
-		"vi": "Bạn là trợ lý đánh giá phỏng vấn tuyển dụng. Đọc bản ghi và trả về đánh giá dưới dạng JSON.",
-		"id": "Anda adalah asisten evaluasi wawancara kerja. Baca transkrip dan kembalikan evaluasi dalam format JSON.",
-		"tr": "Siz bir iş görüşmesi değerlendirme asistanısınız. Metni okuyun ve değerlendirmeyi JSON formatında döndürün.",
-	}
-	if prompt, ok := known[lang]; ok {
-		return prompt
-	}
-	return fmt.Sprintf("You are a job interview assessment assistant. Read the interview transcript and return evaluation as JSON. Use language code \"%s\" for the summary and evidence fields.", lang)
-}
-
 // ttsVoiceForGenderAndLang 性別と言語に応じたTTSボイスを返す。
 // male: onyx(ja/ko) / echo(en/other), female: nova(ja/ko) / shimmer(en/other)
 func ttsVoiceForGenderAndLang(gender, lang string) string {
@@ -1,9 +1,9 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/repository"
 	"Backend/internal/models"
-	"Backend/internal/openai"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -12,11 +12,11 @@ import (
 
 // JobCategoryValidator 職種判定サービス
 type JobCategoryValidator struct {
-	aiClient        *openai.Client
+	aiClient        ai.LLMClient
 	jobCategoryRepo repository.JobCategoryRepository
 }
 
-func NewJobCategoryValidator(aiClient *openai.Client, jobCategoryRepo repository.JobCategoryRepository) *JobCategoryValidator {
+func NewJobCategoryValidator(aiClient ai.LLMClient, jobCategoryRepo repository.JobCategoryRepository) *JobCategoryValidator {
 	return &JobCategoryValidator{
 		aiClient:        aiClient,
 		jobCategoryRepo: jobCategoryRepo,
@@ -125,7 +125,7 @@ D) 調べてまとめる」
 
 JSONのみを返してください。説明は不要です。`, userAnswer, string(categoryJSON))
 
-	responseText, err := v.aiClient.Responses(ctx, prompt)
+	responseText, err := v.aiClient.GenerateText(ctx, "", prompt)
 	if err != nil {
 		return nil, fmt.Errorf("AI validation failed: %w", err)
 	}
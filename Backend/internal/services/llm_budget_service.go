@@ -0,0 +1,232 @@
+package services
+
+import (
+	"Backend/domain/ai"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidBudget は LLM 予算の設定値が不正なことを表す。
+var ErrInvalidBudget = errors.New("invalid llm budget")
+
+// LLMBudgetExceededMessage 予算超過で AI 機能を使えないことを利用者に伝える文言
+const LLMBudgetExceededMessage = "AI機能の利用上限に達しました。時間をおいて再度お試しください"
+
+// 予算超過時の動作
+const (
+	BudgetActionRefuse    = "refuse"    // ai.ErrBudgetExceeded を返して呼び出しを拒否する
+	BudgetActionDowngrade = "downgrade" // テキスト生成を格下げ先のモデルで続ける
+)
+
+// LLMBudgetConfig 全ユーザー共通の既定の予算（LLM_USER_*_BUDGET_USD）
+type LLMBudgetConfig struct {
+	DailyLimitUSD   float64 `json:"daily_limit_usd"`   // 0 なら無制限
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"` // 0 なら無制限
+	Action          string  `json:"action"`
+	DowngradeModel  string  `json:"downgrade_model"`
+}
+
+// LLMBudgetStatus 利用者の予算と当日・当月（UTC）の利用額
+type LLMBudgetStatus struct {
+	UserID          uint    `json:"user_id"`
+	DailyLimitUSD   float64 `json:"daily_limit_usd"`
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+	DailySpentUSD   float64 `json:"daily_spent_usd"`
+	MonthlySpentUSD float64 `json:"monthly_spent_usd"`
+	Action          string  `json:"action"`
+	Override        bool    `json:"override"` // 利用者ごとの上書き設定があるか
+	Exceeded        bool    `json:"exceeded"`
+}
+
+// LLMBudgetService 利用者ごとの LLM 予算（日次・月次）の判定と上書き設定の管理
+// 利用額は api_call_logs の集計を spendTTL の間キャッシュするため、超過の判定は最大 spendTTL 遅れる
+type LLMBudgetService struct {
+	repo     repository.LLMUserBudgetRepository
+	spend    repository.LLMSpendRepository
+	cfg      LLMBudgetConfig
+	spendTTL time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[uint]*LLMBudgetStatus
+	at    map[uint]time.Time
+}
+
+func NewLLMBudgetService(repo repository.LLMUserBudgetRepository, spend repository.LLMSpendRepository, cfg LLMBudgetConfig) *LLMBudgetService {
+	if cfg.Action != BudgetActionDowngrade {
+		cfg.Action = BudgetActionRefuse
+	}
+	return &LLMBudgetService{
+		repo:     repo,
+		spend:    spend,
+		cfg:      cfg,
+		spendTTL: 30 * time.Second,
+		now:      time.Now,
+		cache:    make(map[uint]*LLMBudgetStatus),
+		at:       make(map[uint]time.Time),
+	}
+}
+
+// SetNow は現在時刻の取得関数を差し替える（テスト用）。
+func (s *LLMBudgetService) SetNow(now func() time.Time) {
+	s.now = now
+}
+
+// Defaults は全ユーザー共通の既定の予算を返す
+func (s *LLMBudgetService) Defaults() LLMBudgetConfig {
+	return s.cfg
+}
+
+// Admit は userID の LLM 呼び出しを予算と照らし合わせる（internal/ai.BudgetAdapter から呼ばれる）。
+// 予算内なら空文字を、超過していて動作が downgrade なら格下げ先のモデルを返し、refuse なら ai.ErrBudgetExceeded を返す。
+// 利用者に紐づかない呼び出しと、予算・利用額を読めなかった場合は止めない。
+func (s *LLMBudgetService) Admit(ctx context.Context, userID uint) (string, error) {
+	if s == nil || userID == 0 {
+		return "", nil
+	}
+	status, err := s.cachedStatus(userID)
+	if err != nil {
+		log.Printf("[LLMBudget] failed to check budget for user %d: %v", userID, err)
+		return "", nil
+	}
+	if !status.Exceeded {
+		return "", nil
+	}
+	if status.Action == BudgetActionDowngrade && s.cfg.DowngradeModel != "" {
+		return s.cfg.DowngradeModel, nil
+	}
+	return "", fmt.Errorf("%w: user %d spent $%.4f today and $%.4f this month", ai.ErrBudgetExceeded, userID, status.DailySpentUSD, status.MonthlySpentUSD)
+}
+
+// Status は利用者の予算と利用額を集計し直して返す（管理画面用）
+func (s *LLMBudgetService) Status(userID uint) (*LLMBudgetStatus, error) {
+	status, err := s.loadStatus(userID, true)
+	if err != nil {
+		return nil, err
+	}
+	s.store(userID, status)
+	copied := *status
+	return &copied, nil
+}
+
+// ListOverrides は利用者ごとの上書き設定を返す
+func (s *LLMBudgetService) ListOverrides() ([]models.LLMUserBudget, error) {
+	return s.repo.List()
+}
+
+// SetOverride は利用者の予算を上書きする（上限 0 は無制限、action が空なら既定の動作）
+func (s *LLMBudgetService) SetOverride(userID uint, dailyLimitUSD, monthlyLimitUSD float64, action, actorEmail string) (*models.LLMUserBudget, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidBudget)
+	}
+	if dailyLimitUSD < 0 || monthlyLimitUSD < 0 {
+		return nil, fmt.Errorf("%w: limits must not be negative", ErrInvalidBudget)
+	}
+	action = strings.TrimSpace(action)
+	if action == "" {
+		action = s.cfg.Action
+	}
+	switch action {
+	case BudgetActionRefuse:
+	case BudgetActionDowngrade:
+		if s.cfg.DowngradeModel == "" {
+			return nil, fmt.Errorf("%w: LLM_BUDGET_DOWNGRADE_MODEL is not configured", ErrInvalidBudget)
+		}
+	default:
+		return nil, fmt.Errorf("%w: action must be %s or %s", ErrInvalidBudget, BudgetActionRefuse, BudgetActionDowngrade)
+	}
+	budget := &models.LLMUserBudget{
+		UserID:          userID,
+		DailyLimitUSD:   dailyLimitUSD,
+		MonthlyLimitUSD: monthlyLimitUSD,
+		Action:          action,
+		UpdatedBy:       actorEmail,
+	}
+	if err := s.repo.Upsert(budget); err != nil {
+		return nil, fmt.Errorf("failed to save llm budget: %w", err)
+	}
+	s.invalidate(userID)
+	return budget, nil
+}
+
+// DeleteOverride は利用者の上書き設定を削除し、既定の予算に戻す
+func (s *LLMBudgetService) DeleteOverride(userID uint) error {
+	if err := s.repo.Delete(userID); err != nil {
+		return fmt.Errorf("failed to delete llm budget: %w", err)
+	}
+	s.invalidate(userID)
+	return nil
+}
+
+func (s *LLMBudgetService) cachedStatus(userID uint) (*LLMBudgetStatus, error) {
+	s.mu.Lock()
+	status, ok := s.cache[userID]
+	fresh := ok && s.now().Sub(s.at[userID]) < s.spendTTL
+	s.mu.Unlock()
+	if fresh {
+		return status, nil
+	}
+	status, err := s.loadStatus(userID, false)
+	if err != nil {
+		return nil, err
+	}
+	s.store(userID, status)
+	return status, nil
+}
+
+// loadStatus は予算と利用額を読み込む。withSpend が false なら無制限の利用者の利用額は集計しない
+func (s *LLMBudgetService) loadStatus(userID uint, withSpend bool) (*LLMBudgetStatus, error) {
+	status := &LLMBudgetStatus{
+		UserID:          userID,
+		DailyLimitUSD:   s.cfg.DailyLimitUSD,
+		MonthlyLimitUSD: s.cfg.MonthlyLimitUSD,
+		Action:          s.cfg.Action,
+	}
+	override, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		status.DailyLimitUSD = override.DailyLimitUSD
+		status.MonthlyLimitUSD = override.MonthlyLimitUSD
+		status.Action = override.Action
+		status.Override = true
+	}
+	if !withSpend && status.DailyLimitUSD <= 0 && status.MonthlyLimitUSD <= 0 {
+		return status, nil
+	}
+
+	now := s.now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if status.DailySpentUSD, err = s.spend.UserCostSince(userID, dayStart); err != nil {
+		return nil, err
+	}
+	if status.MonthlySpentUSD, err = s.spend.UserCostSince(userID, monthStart); err != nil {
+		return nil, err
+	}
+	status.Exceeded = (status.DailyLimitUSD > 0 && status.DailySpentUSD >= status.DailyLimitUSD) ||
+		(status.MonthlyLimitUSD > 0 && status.MonthlySpentUSD >= status.MonthlyLimitUSD)
+	return status, nil
+}
+
+func (s *LLMBudgetService) store(userID uint, status *LLMBudgetStatus) {
+	s.mu.Lock()
+	s.cache[userID] = status
+	s.at[userID] = s.now()
+	s.mu.Unlock()
+}
+
+func (s *LLMBudgetService) invalidate(userID uint) {
+	s.mu.Lock()
+	delete(s.cache, userID)
+	delete(s.at, userID)
+	s.mu.Unlock()
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"Backend/domain/entity"
+	"Backend/internal/models"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ログイン試行制限の理由コード（LoginBlockedError.Code）
+const (
+	LoginBlockedAccountLocked   = "account_locked"
+	LoginBlockedTooManyAttempts = "too_many_attempts"
+)
+
+// LoginBlockedError ログイン試行が制限されている（パスワードは検証していない）
+type LoginBlockedError struct {
+	Code       string
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Code
+}
+
+// LoginPolicy ログイン失敗時の遅延・ロックの閾値
+type LoginPolicy struct {
+	FreeFailures     int           // 遅延なしで許容する連続失敗回数
+	MaxDelay         time.Duration // 段階的な待機時間の上限
+	MaxFailures      int           // この回数連続で失敗するとアカウントをロックする
+	LockDuration     time.Duration // ロック時間
+	IPMaxFailures    int           // IP 単位で IPWindow 内に許容する失敗回数
+	IPWindow         time.Duration
+	AttemptsPageSize int // 管理画面に表示する試行履歴の件数
+}
+
+// LoginPolicyFromEnv LOGIN_MAX_FAILURES / LOGIN_LOCKOUT_MINUTES / LOGIN_IP_MAX_FAILURES で閾値を上書きする
+func LoginPolicyFromEnv() LoginPolicy {
+	p := LoginPolicy{
+		FreeFailures:     3,
+		MaxDelay:         time.Minute,
+		MaxFailures:      10,
+		LockDuration:     30 * time.Minute,
+		IPMaxFailures:    50,
+		IPWindow:         15 * time.Minute,
+		AttemptsPageSize: 50,
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && n > 0 {
+		p.MaxFailures = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MINUTES")); err == nil && n > 0 {
+		p.LockDuration = time.Duration(n) * time.Minute
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_IP_MAX_FAILURES")); err == nil && n > 0 {
+		p.IPMaxFailures = n
+	}
+	return p
+}
+
+// delayAfter failures 回連続で失敗した後、次の試行まで待たせる時間（1, 2, 4, ... 秒）
+func (p LoginPolicy) delayAfter(failures int) time.Duration {
+	over := failures - p.FreeFailures
+	if over <= 0 {
+		return 0
+	}
+	if over > 16 {
+		return p.MaxDelay
+	}
+	d := time.Duration(1<<(over-1)) * time.Second
+	if d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// checkIPThrottle IP アドレスからの失敗回数が上限に達していれば LoginBlockedError を返す
+func (s *AuthService) checkIPThrottle(ip string) error {
+	if s.attempts == nil || ip == "" {
+		return nil
+	}
+	count, err := s.attempts.CountFailuresByIP(ip, time.Now().Add(-s.policy.IPWindow))
+	if err != nil {
+		return fmt.Errorf("failed to count login attempts: %w", err)
+	}
+	if int(count) >= s.policy.IPMaxFailures {
+		return &LoginBlockedError{Code: LoginBlockedTooManyAttempts, RetryAfter: s.policy.IPWindow}
+	}
+	return nil
+}
+
+// checkAccountThrottle ロック中または段階的な待機時間内であれば LoginBlockedError を返す。
+// ロック期限が過ぎていれば失敗回数をリセットして保存する
+func (s *AuthService) checkAccountThrottle(user *entity.User, now time.Time) error {
+	if user.LockedUntil != nil {
+		if user.IsLocked(now) {
+			return &LoginBlockedError{Code: LoginBlockedAccountLocked, RetryAfter: user.LockedUntil.Sub(now)}
+		}
+		user.LockedUntil = nil
+		user.UnlockToken = ""
+		user.FailedLoginCount = 0
+		if err := s.userRepo.UpdateLockout(user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+	if user.LastFailedLoginAt != nil {
+		if wait := user.LastFailedLoginAt.Add(s.policy.delayAfter(user.FailedLoginCount)).Sub(now); wait > 0 {
+			return &LoginBlockedError{Code: LoginBlockedTooManyAttempts, RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+// recordLoginAttempt 試行履歴を保存する（失敗しても認証処理は継続する）
+func (s *AuthService) recordLoginAttempt(userID uint, email, ip string, success bool, reason string) {
+	if s.attempts == nil {
+		return
+	}
+	if err := s.attempts.Create(&models.LoginAttempt{
+		UserID:    userID,
+		Email:     email,
+		IPAddress: ip,
+		Success:   success,
+		Reason:    reason,
+	}); err != nil {
+		log.Printf("[Auth] failed to record login attempt: %v", err)
+	}
+}
+
+// registerFailedLogin パスワード・認証コードの誤りを記録し、閾値に達したらアカウントをロックしてロック解除メールを送る。
+// 同時に失敗しても回数を取りこぼさないよう、失敗回数は DB 上で加算してから読み直す。
+// ロックした場合は LoginBlockedError を返す
+func (s *AuthService) registerFailedLogin(user *entity.User, ip, reason string, now time.Time) error {
+	count, err := s.userRepo.IncrementFailedLogin(user.ID, now)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	user.FailedLoginCount = count
+	user.LastFailedLoginAt = &now
+	s.recordLoginAttempt(user.ID, user.Email, ip, false, reason)
+	s.audit.Record(user.Email, "auth.login_failed", "user", user.ID, map[string]interface{}{
+		"ip":              ip,
+		"reason":          reason,
+		"failed_attempts": user.FailedLoginCount,
+	})
+
+	if user.FailedLoginCount < s.policy.MaxFailures {
+		return nil
+	}
+	lockedUntil := now.Add(s.policy.LockDuration)
+	user.LockedUntil = &lockedUntil
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("failed to generate unlock token: %w", err)
+	}
+	user.UnlockToken = base64.URLEncoding.EncodeToString(b)
+	if err := s.userRepo.UpdateLockout(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.audit.Record(user.Email, "auth.account_locked", "user", user.ID, map[string]interface{}{
+		"ip":           ip,
+		"locked_until": user.LockedUntil.Format(time.RFC3339),
+	})
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:3000"
+	}
+	go s.emailService.SendAccountLockedEmail(user.Email, user.UnlockToken, appURL, *user.LockedUntil)
+	return &LoginBlockedError{Code: LoginBlockedAccountLocked, RetryAfter: s.policy.LockDuration}
+}
+
+// resetFailedLogins 失敗回数とロック状態をリセットする（保存は呼び出し側で行う）
+func resetFailedLogins(user *entity.User) {
+	user.FailedLoginCount = 0
+	user.LastFailedLoginAt = nil
+	user.LockedUntil = nil
+	user.UnlockToken = ""
+}
+
+// unlock ロック状態を解除して保存する
+func (s *AuthService) unlock(user *entity.User) error {
+	resetFailedLogins(user)
+	if err := s.userRepo.UpdateLockout(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// UnlockAccountByToken ロック解除メールのトークンでアカウントのロックを解除する
+func (s *AuthService) UnlockAccountByToken(token string) error {
+	if token == "" {
+		return errors.New("token is required")
+	}
+	user, err := s.userRepo.GetUserByUnlockToken(token)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user == nil {
+		return errors.New("invalid or expired token")
+	}
+	if err := s.unlock(user); err != nil {
+		return err
+	}
+	s.audit.Record(user.Email, "auth.account_unlocked", "user", user.ID, map[string]interface{}{
+		"via": "email",
+	})
+	return nil
+}
+
+// UnlockAccount 管理者がアカウントのロックを解除する。
+// ロック中に発行済みのセッションは第三者のものである可能性があるため無効にする
+func (s *AuthService) UnlockAccount(userID uint, actorEmail string) (*entity.User, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	now := time.Now()
+	wasLocked := user.IsLocked(now)
+	user.SessionsRevokedAt = &now
+	if err := s.unlock(user); err != nil {
+		return nil, err
+	}
+	s.audit.Record(actorEmail, "auth.account_unlocked", "user", user.ID, map[string]interface{}{
+		"via":        "admin",
+		"was_locked": wasLocked,
+	})
+	return user, nil
+}
+
+// RecentLoginAttempts 管理画面向けにユーザーの直近のログイン試行を返す
+func (s *AuthService) RecentLoginAttempts(userID uint) ([]models.LoginAttempt, error) {
+	if s.attempts == nil {
+		return []models.LoginAttempt{}, nil
+	}
+	return s.attempts.ListByUser(userID, s.policy.AttemptsPageSize)
+}
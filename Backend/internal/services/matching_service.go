@@ -5,6 +5,7 @@ import (
 	"Backend/domain/repository"
 	"Backend/internal/models"
 	"context"
+	"errors"
 	"fmt"
 	"math"
 )
@@ -13,6 +14,7 @@ type MatchingService struct {
 	userWeightScoreRepo repository.UserWeightScoreRepository
 	companyRepo         repository.CompanyRepository
 	matchRepo           repository.UserCompanyMatchRepository
+	calibration         CalibrationSource
 }
 
 func NewMatchingService(
@@ -27,6 +29,11 @@ func NewMatchingService(
 	}
 }
 
+// SetCalibrationSource カテゴリ別のキャリブレーション重みの取得元を注入する（未設定なら全カテゴリを同じ重みで平均する）
+func (s *MatchingService) SetCalibrationSource(source CalibrationSource) {
+	s.calibration = source
+}
+
 // CalculateMatching ユーザーと企業のマッチングを計算
 func (s *MatchingService) CalculateMatching(ctx context.Context, userID uint, sessionID string) error {
 	fmt.Printf("[CalculateMatching] Starting matching calculation for user %d, session %s\n", userID, sessionID)
@@ -58,6 +65,17 @@ func (s *MatchingService) CalculateMatching(ctx context.Context, userID uint, se
 
 	fmt.Printf("[CalculateMatching] Found %d active companies\n", len(companies))
 
+	// 有効なキャリブレーションの版で重み付けし、シャドーモードの版があれば並べて計算する
+	var calibration, shadow *CalibrationWeights
+	if s.calibration != nil {
+		calibration = s.calibration.ActiveCalibration()
+		shadow = s.calibration.ShadowCalibration()
+		if shadow != nil && shadow.Version == calibration.VersionNumber() {
+			shadow = nil
+		}
+	}
+	fmt.Printf("[CalculateMatching] Calibration version: %d (shadow: %d)\n", calibration.VersionNumber(), shadow.VersionNumber())
+
 	// 3. 各企業とのマッチングを計算
 	matchCount := 0
 	for _, company := range companies {
@@ -69,7 +87,12 @@ func (s *MatchingService) CalculateMatching(ctx context.Context, userID uint, se
 		}
 
 		// マッチングスコアを計算
-		match := s.calculateMatchScore(scoreMap, profile)
+		match := s.calculateMatchScore(scoreMap, profile, calibration)
+		if shadow != nil {
+			shadowScore := s.calculateMatchScore(scoreMap, profile, shadow).MatchScore
+			match.ShadowMatchScore = &shadowScore
+			match.ShadowCalibrationVersion = shadow.Version
+		}
 		match.UserID = userID
 		match.SessionID = sessionID
 		match.CompanyID = company.ID
@@ -87,30 +110,32 @@ func (s *MatchingService) CalculateMatching(ctx context.Context, userID uint, se
 }
 
 // calculateMatchScore ユーザースコアと企業プロファイルからマッチングスコアを計算
+// 総合マッチ度はカテゴリ別マッチ度をキャリブレーション重みで加重平均する（weights が nil なら単純平均）
 func (s *MatchingService) calculateMatchScore(
 	userScores map[string]float64,
 	companyProfile *models.CompanyWeightProfile,
+	weights *CalibrationWeights,
 ) *entity.UserCompanyMatch {
-	match := &entity.UserCompanyMatch{}
-	evaluatedCount := 0
+	match := &entity.UserCompanyMatch{CalibrationVersion: weights.VersionNumber()}
+	weightSum := 0.0
 	totalScore := 0.0
 
 	// 各カテゴリのマッチ度を計算（0-100のスケールで）
 	// マッチ度 = 100 - |ユーザースコア - 企業重視度|
-	match.TechnicalMatch, evaluatedCount, totalScore = scoredMatch(userScores, "技術志向", float64(companyProfile.TechnicalOrientation), evaluatedCount, totalScore)
-	match.TeamworkMatch, evaluatedCount, totalScore = scoredMatch(userScores, "チームワーク志向", float64(companyProfile.TeamworkOrientation), evaluatedCount, totalScore)
-	match.LeadershipMatch, evaluatedCount, totalScore = scoredMatch(userScores, "リーダーシップ志向", float64(companyProfile.LeadershipOrientation), evaluatedCount, totalScore)
-	match.CreativityMatch, evaluatedCount, totalScore = scoredMatch(userScores, "創造性志向", float64(companyProfile.CreativityOrientation), evaluatedCount, totalScore)
-	match.StabilityMatch, evaluatedCount, totalScore = scoredMatch(userScores, "安定志向", float64(companyProfile.StabilityOrientation), evaluatedCount, totalScore)
-	match.GrowthMatch, evaluatedCount, totalScore = scoredMatch(userScores, "成長志向", float64(companyProfile.GrowthOrientation), evaluatedCount, totalScore)
-	match.WorkLifeMatch, evaluatedCount, totalScore = scoredMatch(userScores, "ワークライフバランス", float64(companyProfile.WorkLifeBalance), evaluatedCount, totalScore)
-	match.ChallengeMatch, evaluatedCount, totalScore = scoredMatch(userScores, "チャレンジ志向", float64(companyProfile.ChallengeSeeking), evaluatedCount, totalScore)
-	match.DetailMatch, evaluatedCount, totalScore = scoredMatch(userScores, "細部志向", float64(companyProfile.DetailOrientation), evaluatedCount, totalScore)
-	match.CommunicationMatch, evaluatedCount, totalScore = scoredMatch(userScores, "コミュニケーション力", float64(companyProfile.CommunicationSkill), evaluatedCount, totalScore)
-
-	// 総合マッチ度を計算（全カテゴリの平均）
-	if evaluatedCount > 0 {
-		match.MatchScore = totalScore / float64(evaluatedCount)
+	match.TechnicalMatch, weightSum, totalScore = scoredMatch(userScores, "技術志向", float64(companyProfile.TechnicalOrientation), weights, weightSum, totalScore)
+	match.TeamworkMatch, weightSum, totalScore = scoredMatch(userScores, "チームワーク志向", float64(companyProfile.TeamworkOrientation), weights, weightSum, totalScore)
+	match.LeadershipMatch, weightSum, totalScore = scoredMatch(userScores, "リーダーシップ志向", float64(companyProfile.LeadershipOrientation), weights, weightSum, totalScore)
+	match.CreativityMatch, weightSum, totalScore = scoredMatch(userScores, "創造性志向", float64(companyProfile.CreativityOrientation), weights, weightSum, totalScore)
+	match.StabilityMatch, weightSum, totalScore = scoredMatch(userScores, "安定志向", float64(companyProfile.StabilityOrientation), weights, weightSum, totalScore)
+	match.GrowthMatch, weightSum, totalScore = scoredMatch(userScores, "成長志向", float64(companyProfile.GrowthOrientation), weights, weightSum, totalScore)
+	match.WorkLifeMatch, weightSum, totalScore = scoredMatch(userScores, "ワークライフバランス", float64(companyProfile.WorkLifeBalance), weights, weightSum, totalScore)
+	match.ChallengeMatch, weightSum, totalScore = scoredMatch(userScores, "チャレンジ志向", float64(companyProfile.ChallengeSeeking), weights, weightSum, totalScore)
+	match.DetailMatch, weightSum, totalScore = scoredMatch(userScores, "細部志向", float64(companyProfile.DetailOrientation), weights, weightSum, totalScore)
+	match.CommunicationMatch, weightSum, totalScore = scoredMatch(userScores, "コミュニケーション力", float64(companyProfile.CommunicationSkill), weights, weightSum, totalScore)
+
+	// 総合マッチ度を計算（評価済みカテゴリの加重平均）
+	if weightSum > 0 {
+		match.MatchScore = totalScore / weightSum
 	} else {
 		match.MatchScore = 0
 	}
@@ -118,13 +143,15 @@ func (s *MatchingService) calculateMatchScore(
 	return match
 }
 
-func scoredMatch(userScores map[string]float64, category string, companyWeight float64, evaluatedCount int, totalScore float64) (float64, int, float64) {
+// scoredMatch はカテゴリのマッチ度を返し、評価済みなら重みの合計と重み付きの合計に加える
+func scoredMatch(userScores map[string]float64, category string, companyWeight float64, weights *CalibrationWeights, weightSum, totalScore float64) (float64, float64, float64) {
 	userScore, ok := userScores[category]
 	if !ok {
-		return 0, evaluatedCount, totalScore
+		return 0, weightSum, totalScore
 	}
 	matchScore := calculateCategoryMatch(userScore, companyWeight)
-	return matchScore, evaluatedCount + 1, totalScore + matchScore
+	w := weights.Weight(category)
+	return matchScore, weightSum + w, totalScore + matchScore*w
 }
 
 // calculateCategoryMatch カテゴリごとのマッチ度を計算
@@ -139,8 +166,15 @@ func (s *MatchingService) GetTopMatches(ctx context.Context, userID uint, sessio
 	return s.matchRepo.FindTopMatchesByUserAndSession(userID, sessionID, limit)
 }
 
-// ToggleFavorite お気に入りをトグル
-func (s *MatchingService) ToggleFavorite(matchID uint) error {
+// ToggleFavorite お気に入りをトグル（呼び出し元ユーザーのマッチング結果のみ）
+func (s *MatchingService) ToggleFavorite(userID, matchID uint) error {
+	match, err := s.matchRepo.FindByID(matchID)
+	if err != nil {
+		return err
+	}
+	if match == nil || match.UserID != userID {
+		return errors.New("forbidden")
+	}
 	return s.matchRepo.ToggleFavorite(matchID)
 }
 
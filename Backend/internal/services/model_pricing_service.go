@@ -0,0 +1,260 @@
+package services
+
+import (
+	"Backend/domain/ai"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidPrice はモデル単価の設定値が不正なことを表す。
+	ErrInvalidPrice = errors.New("invalid model price")
+	// ErrPriceNotFound は指定した単価の行が存在しないことを表す。
+	ErrPriceNotFound = errors.New("model price not found")
+)
+
+// pricingSeedFrom 初期値の適用開始日（これより前の呼び出しは単価未登録として扱う）
+var pricingSeedFrom = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// defaultModelPrices 単価表が空のときに登録する初期値（USD）。公開価格の改定は行を追加して表す
+var defaultModelPrices = []models.ModelPrice{
+	{Model: "gpt-4o", InputPerMTokUSD: 2.50, CachedInputPerMTokUSD: 1.25, OutputPerMTokUSD: 10.00},
+	{Model: "gpt-4o-mini", InputPerMTokUSD: 0.15, CachedInputPerMTokUSD: 0.075, OutputPerMTokUSD: 0.60},
+	{Model: "gpt-4-turbo", InputPerMTokUSD: 10.00, OutputPerMTokUSD: 30.00},
+	{Model: "gpt-4", InputPerMTokUSD: 30.00, OutputPerMTokUSD: 60.00},
+	{Model: "gpt-3.5-turbo", InputPerMTokUSD: 0.50, OutputPerMTokUSD: 1.50},
+	{Model: "gpt-5.2", InputPerMTokUSD: 2.50, OutputPerMTokUSD: 10.00, Note: "暫定値（gpt-4o と同額）。正式な単価で行を追加し、再計算すること"},
+	{Model: "o1", InputPerMTokUSD: 15.00, CachedInputPerMTokUSD: 7.50, OutputPerMTokUSD: 60.00},
+	{Model: "o1-mini", InputPerMTokUSD: 3.00, CachedInputPerMTokUSD: 1.50, OutputPerMTokUSD: 12.00},
+	{Model: "o3", InputPerMTokUSD: 10.00, CachedInputPerMTokUSD: 2.50, OutputPerMTokUSD: 40.00},
+	{Model: "o3", EffectiveFrom: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC), InputPerMTokUSD: 2.00, CachedInputPerMTokUSD: 0.50, OutputPerMTokUSD: 8.00, Note: "値下げ"},
+	{Model: "text-embedding-3-small", InputPerMTokUSD: 0.02, OutputPerMTokUSD: 0.02},
+	{Model: "text-embedding-3-large", InputPerMTokUSD: 0.13, OutputPerMTokUSD: 0.13},
+	{Model: "whisper-1", PerMinuteUSD: 0.006},
+}
+
+// RealtimeModel はリアルタイム面接で使うモデル名（OPENAI_REALTIME_MODEL）を返す
+func RealtimeModel() string {
+	return getEnv("OPENAI_REALTIME_MODEL", "gpt-4o-realtime-preview")
+}
+
+// PricingService モデル単価表（model_prices）による LLM・リアルタイム音声の料金計算
+// 単価表は ttl の間メモリに保持する。管理 API から更新した場合はすぐに読み直す
+type PricingService struct {
+	repo repository.ModelPriceRepository
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu       sync.Mutex
+	prices   []models.ModelPrice
+	loadedAt time.Time
+	loaded   bool
+}
+
+func NewPricingService(repo repository.ModelPriceRepository) *PricingService {
+	return &PricingService{repo: repo, ttl: time.Minute, now: time.Now}
+}
+
+// SetNow は現在時刻の取得関数を差し替える（テスト用）。
+func (s *PricingService) SetNow(now func() time.Time) {
+	s.now = now
+}
+
+// SeedDefaults は単価表が空なら初期値を登録し、登録した件数を返す。
+// リアルタイム音声の 1 分あたりの単価は INTERVIEW_COST_PER_MIN_USD（既定 0.18）を RealtimeModel() の行として登録する
+func (s *PricingService) SeedDefaults() (int, error) {
+	existing, err := s.repo.List()
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) > 0 {
+		return 0, nil
+	}
+	seeds := append([]models.ModelPrice{}, defaultModelPrices...)
+	seeds = append(seeds, models.ModelPrice{
+		Model:        strings.ToLower(RealtimeModel()),
+		PerMinuteUSD: getFloatEnv("INTERVIEW_COST_PER_MIN_USD", 0.18),
+		Note:         "音声の入出力を含む 1 分あたりの概算",
+	})
+	for i := range seeds {
+		price := seeds[i]
+		if price.EffectiveFrom.IsZero() {
+			price.EffectiveFrom = pricingSeedFrom
+		}
+		price.UpdatedBy = "seed"
+		if err := s.repo.Upsert(&price); err != nil {
+			return i, fmt.Errorf("failed to seed model price %s: %w", price.Model, err)
+		}
+	}
+	s.Reload()
+	return len(seeds), nil
+}
+
+// Reload は次の参照時に単価表を読み直させる
+func (s *PricingService) Reload() {
+	s.mu.Lock()
+	s.loaded = false
+	s.mu.Unlock()
+}
+
+// Lookup は at の時点で model に適用する単価を返す。
+// 前方一致するうち最も長いモデル名の行から、at 以前で最も新しい EffectiveFrom の行を選ぶ。
+// その名前の行がすべて at より後に始まる場合は、短い名前の単価で代用せず nil を返す
+func (s *PricingService) Lookup(model string, at time.Time) *models.ModelPrice {
+	if s == nil {
+		return nil
+	}
+	lower := strings.ToLower(strings.TrimSpace(model))
+	if lower == "" {
+		return nil
+	}
+	prices := s.snapshot()
+	name := ""
+	for _, p := range prices {
+		if matchesModel(lower, p.Model) && len(p.Model) > len(name) {
+			name = p.Model
+		}
+	}
+	var best *models.ModelPrice
+	for i := range prices {
+		p := prices[i]
+		if p.Model != name || p.EffectiveFrom.After(at) {
+			continue
+		}
+		if best == nil || p.EffectiveFrom.After(best.EffectiveFrom) {
+			best = &p
+		}
+	}
+	return best
+}
+
+// TokenCost は at の時点の単価で usage の料金を計算し、使った単価の ID を返す（単価未登録なら 0, nil）
+func (s *PricingService) TokenCost(model string, at time.Time, usage ai.TokenUsage) (float64, *uint) {
+	price := s.Lookup(model, at)
+	if price == nil {
+		return 0, nil
+	}
+	cached := usage.CachedPromptTokens
+	if cached > usage.PromptTokens {
+		cached = usage.PromptTokens
+	}
+	if cached < 0 {
+		cached = 0
+	}
+	cachedRate := price.CachedInputPerMTokUSD
+	if cachedRate == 0 {
+		cachedRate = price.InputPerMTokUSD
+	}
+	cost := (float64(usage.PromptTokens-cached)*price.InputPerMTokUSD +
+		float64(cached)*cachedRate +
+		float64(usage.CompletionTokens)*price.OutputPerMTokUSD) / 1_000_000
+	id := price.ID
+	return cost, &id
+}
+
+// PerMinuteRate は at の時点の model の 1 分あたりの単価を返す（未登録か 0 なら false）
+func (s *PricingService) PerMinuteRate(model string, at time.Time) (float64, bool) {
+	price := s.Lookup(model, at)
+	if price == nil || price.PerMinuteUSD <= 0 {
+		return 0, false
+	}
+	return price.PerMinuteUSD, true
+}
+
+// List は単価表の全行をモデル名・適用開始日順に返す（管理画面用）
+func (s *PricingService) List() ([]models.ModelPrice, error) {
+	prices, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	sortPrices(prices)
+	return prices, nil
+}
+
+// Upsert はモデルと適用開始日が同じ行があれば単価を更新し、なければ追加する
+func (s *PricingService) Upsert(price models.ModelPrice, actorEmail string) (*models.ModelPrice, error) {
+	price.Model = strings.ToLower(strings.TrimSpace(price.Model))
+	if price.Model == "" || len(price.Model) > 100 {
+		return nil, fmt.Errorf("%w: model is required (max 100 characters)", ErrInvalidPrice)
+	}
+	if price.EffectiveFrom.IsZero() {
+		return nil, fmt.Errorf("%w: effective_from is required", ErrInvalidPrice)
+	}
+	if price.InputPerMTokUSD < 0 || price.CachedInputPerMTokUSD < 0 || price.OutputPerMTokUSD < 0 || price.PerMinuteUSD < 0 {
+		return nil, fmt.Errorf("%w: rates must not be negative", ErrInvalidPrice)
+	}
+	price.ID = 0
+	price.EffectiveFrom = price.EffectiveFrom.UTC()
+	price.Note = strings.TrimSpace(price.Note)
+	price.UpdatedBy = actorEmail
+	if err := s.repo.Upsert(&price); err != nil {
+		return nil, fmt.Errorf("failed to save model price: %w", err)
+	}
+	s.Reload()
+	// 既存行を更新した場合は ID が入らないため読み直す
+	saved, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range saved {
+		if saved[i].Model == price.Model && saved[i].EffectiveFrom.Equal(price.EffectiveFrom) {
+			return &saved[i], nil
+		}
+	}
+	return &price, nil
+}
+
+// Delete は単価表の行を削除し、削除した行を返す
+func (s *PricingService) Delete(id uint) (*models.ModelPrice, error) {
+	price, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if price == nil {
+		return nil, ErrPriceNotFound
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return nil, fmt.Errorf("failed to delete model price: %w", err)
+	}
+	s.Reload()
+	return price, nil
+}
+
+// snapshot は TTL 内ならメモリの単価表を返す。読み込みに失敗したら前回の単価表を使い続ける
+func (s *PricingService) snapshot() []models.ModelPrice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded && s.now().Sub(s.loadedAt) < s.ttl {
+		return s.prices
+	}
+	prices, err := s.repo.List()
+	if err != nil {
+		log.Printf("[Pricing] failed to load model prices: %v", err)
+		return s.prices
+	}
+	s.prices = prices
+	s.loadedAt = s.now()
+	s.loaded = true
+	return s.prices
+}
+
+// matchesModel は model が name そのものか、name に版・日付の接尾辞（"-" / ":" 区切り）が付いたものかを返す
+func matchesModel(model, name string) bool {
+	return model == name || strings.HasPrefix(model, name+"-") || strings.HasPrefix(model, name+":")
+}
+
+func sortPrices(prices []models.ModelPrice) {
+	sort.SliceStable(prices, func(i, j int) bool {
+		if prices[i].Model != prices[j].Model {
+			return prices[i].Model < prices[j].Model
+		}
+		return prices[i].EffectiveFrom.Before(prices[j].EffectiveFrom)
+	})
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+// oauthLoginCodeTTL OAuth コールバックからフロントエンドがセッションを受け取るまでの猶予
+const oauthLoginCodeTTL = 1 * time.Minute
+
+// ErrInvalidLoginCode ログインコードが不正・期限切れ・使用済み
+var ErrInvalidLoginCode = errors.New("invalid or expired login code")
+
+// oauthLoginCodes OAuth ログインの結果を一度だけ受け取れるコードと引き換えに預かる。
+// トークンをリダイレクト先の URL に載せると閲覧履歴・アクセスログ・Referer に残るため、
+// URL にはこのコードだけを載せ、POST /api/auth/oauth/exchange でセッションと交換する。
+// コードはプロセス内に保持する（複数台構成ではコールバックと交換を同じインスタンスに振り分ける）
+type oauthLoginCodes struct {
+	mu      sync.Mutex
+	entries map[string]oauthLoginEntry
+	now     func() time.Time
+}
+
+type oauthLoginEntry struct {
+	resp      *AuthResponse
+	expiresAt time.Time
+}
+
+func newOAuthLoginCodes() *oauthLoginCodes {
+	return &oauthLoginCodes{entries: make(map[string]oauthLoginEntry), now: time.Now}
+}
+
+func (c *oauthLoginCodes) issue(resp *AuthResponse) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := base64.RawURLEncoding.EncodeToString(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[code] = oauthLoginEntry{resp: resp, expiresAt: now.Add(oauthLoginCodeTTL)}
+	return code, nil
+}
+
+func (c *oauthLoginCodes) consume(code string) (*AuthResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[code]
+	if !ok {
+		return nil, false
+	}
+	delete(c.entries, code)
+	if !c.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+// IssueLoginCode OAuth ログインの結果を預かり、リダイレクト先に渡す一度きりのコードを返す
+func (s *OAuthService) IssueLoginCode(resp *AuthResponse) (string, error) {
+	if resp == nil {
+		return "", errors.New("auth response is required")
+	}
+	return s.loginCodes.issue(resp)
+}
+
+// ExchangeLoginCode 一度きりのコードを預かっていた OAuth ログインの結果と交換する
+func (s *OAuthService) ExchangeLoginCode(code string) (*AuthResponse, error) {
+	if code == "" {
+		return nil, ErrInvalidLoginCode
+	}
+	resp, ok := s.loginCodes.consume(code)
+	if !ok {
+		return nil, ErrInvalidLoginCode
+	}
+	return resp, nil
+}
@@ -4,30 +4,72 @@ import (
 	"Backend/domain/entity"
 	"Backend/domain/repository"
 	"Backend/internal/config"
+	"Backend/internal/models"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// 外部アカウント連携のエラー
+var (
+	// ErrOAuthAccountExists 同じメールアドレスのアカウントがあるが自動では連携できない（ログイン後に連携操作が必要）
+	ErrOAuthAccountExists = errors.New("account_exists_link_required")
+	// ErrIdentityLinkedToOtherUser 外部アカウントが別のユーザーに連携済み
+	ErrIdentityLinkedToOtherUser = errors.New("identity_linked_to_other_account")
+	// ErrProviderAlreadyLinked 同じプロバイダの別アカウントを連携済み
+	ErrProviderAlreadyLinked = errors.New("provider_already_linked")
+	// ErrIdentityEmailInUse 外部アカウントのメールアドレスが別のユーザーで登録済み
+	ErrIdentityEmailInUse = errors.New("email_belongs_to_other_account")
+	// ErrLastLoginMethod 連携を解除するとログイン手段がなくなる
+	ErrLastLoginMethod = errors.New("cannot_remove_last_login_method")
+	// ErrIdentityNotFound 指定したプロバイダは連携されていない
+	ErrIdentityNotFound = errors.New("identity not found")
 )
 
 type OAuthService struct {
 	userRepo      repository.UserRepository
+	identities    repository.UserIdentityRepository
 	oauthConfig   *config.OAuthConfig
 	githubService *GitHubService
+	tokens        *TokenService
+	audit         *AuditLogService
+	db            *gorm.DB
+	loginCodes    *oauthLoginCodes
 }
 
-func NewOAuthService(userRepo repository.UserRepository, oauthConfig *config.OAuthConfig, githubService *GitHubService) *OAuthService {
+func NewOAuthService(userRepo repository.UserRepository, identities repository.UserIdentityRepository, oauthConfig *config.OAuthConfig, githubService *GitHubService) *OAuthService {
 	return &OAuthService{
 		userRepo:      userRepo,
+		identities:    identities,
 		oauthConfig:   oauthConfig,
 		githubService: githubService,
+		loginCodes:    newOAuthLoginCodes(),
 	}
 }
 
+// SetTokenService はコールバック成功時に発行するセッショントークンの署名サービスを設定する
+func (s *OAuthService) SetTokenService(tokens *TokenService) {
+	s.tokens = tokens
+}
+
+// SetDB はゲストのデータを既存アカウントへ統合する際に使用する DB を設定する
+func (s *OAuthService) SetDB(db *gorm.DB) {
+	s.db = db
+}
+
+// SetAuditLogService は外部アカウントの連携・解除を記録する監査ログを設定する
+func (s *OAuthService) SetAuditLogService(audit *AuditLogService) {
+	s.audit = audit
+}
+
 // GoogleUserInfo Google APIから取得するユーザー情報
 type GoogleUserInfo struct {
 	ID            string `json:"id"`
@@ -54,6 +96,24 @@ type GitHubEmail struct {
 	Visibility string `json:"visibility"`
 }
 
+// OAuthProfile プロバイダから取得した外部アカウントの情報
+type OAuthProfile struct {
+	Provider      string // google / github
+	Subject       string // プロバイダ側のユーザーID
+	Email         string
+	EmailVerified bool // プロバイダがメールアドレスの所有を確認済みか
+	Name          string
+	Username      string // GitHub のログイン名
+	AvatarURL     string
+	AccessToken   string // GitHub のみ保存してスキル分析に使う
+}
+
+// LinkedAccounts ログイン手段の一覧
+type LinkedAccounts struct {
+	HasPassword bool                  `json:"has_password"`
+	Identities  []models.UserIdentity `json:"identities"`
+}
+
 // GetGoogleAuthURL Google OAuth認証URLを取得
 func (s *OAuthService) GetGoogleAuthURL(state string) string {
 	return s.oauthConfig.Google.AuthCodeURL(state, oauth2.AccessTypeOffline)
@@ -66,96 +126,583 @@ func (s *OAuthService) GetGitHubAuthURL(state string) string {
 
 // HandleGoogleCallback Google OAuth認証後のコールバック処理
 func (s *OAuthService) HandleGoogleCallback(ctx context.Context, code string) (*AuthResponse, error) {
-	token, err := s.oauthConfig.Google.Exchange(ctx, code)
+	profile, err := s.fetchGoogleProfile(ctx, code)
 	if err != nil {
-		return nil, fmt.Errorf("failed to exchange token: %w", err)
+		return nil, err
+	}
+	if !profile.EmailVerified {
+		return nil, errors.New("email not verified")
 	}
+	return s.LoginWithIdentity(profile)
+}
 
-	// ユーザー情報取得
-	client := s.oauthConfig.Google.Client(ctx, token)
-	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
+// HandleGitHubCallback GitHub OAuth認証後のコールバック処理
+func (s *OAuthService) HandleGitHubCallback(ctx context.Context, code string) (*AuthResponse, error) {
+	profile, err := s.fetchGitHubProfile(ctx, code)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return s.LoginWithIdentity(profile)
+}
 
-	body, err := io.ReadAll(resp.Body)
+// LoginWithIdentity 外部アカウントでログインする。
+// 連携済みならそのユーザー、未連携で同じメールアドレスのユーザーがいれば安全な場合のみ自動連携し、いなければ新規作成する
+func (s *OAuthService) LoginWithIdentity(profile *OAuthProfile) (*AuthResponse, error) {
+	user, err := s.resolveIdentityUser(profile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
+	s.storeProviderToken(user.ID, profile)
+	return s.sessionResponse(user, profile.Provider)
+}
 
-	var userInfo GoogleUserInfo
-	if err := json.Unmarshal(body, &userInfo); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal user info: %w", err)
+// sessionResponse 外部アカウントでログインしたユーザーのセッションを発行する。
+// 2段階認証を有効にしているユーザーにはセッションを発行せず、認証コードの入力を求める
+func (s *OAuthService) sessionResponse(user *entity.User, provider string) (*AuthResponse, error) {
+	if user.HasTOTP() {
+		return mfaChallengeResponse(s.tokens, user)
+	}
+	authResp := &AuthResponse{
+		UserID:                   user.ID,
+		Email:                    user.Email,
+		Name:                     user.Name,
+		IsGuest:                  user.IsGuest,
+		TargetLevel:              user.TargetLevel,
+		SchoolName:               user.SchoolName,
+		IsAdmin:                  user.HasAdminRole(),
+		Role:                     user.EffectiveRole(),
+		CertificationsAcquired:   user.CertificationsAcquired,
+		CertificationsInProgress: user.CertificationsInProgress,
+		AvatarURL:                user.AvatarURL,
+		OAuthProvider:            provider,
 	}
+	if err := attachTokens(s.tokens, authResp, false); err != nil {
+		return nil, err
+	}
+	return authResp, nil
+}
 
-	if !userInfo.VerifiedEmail {
-		return nil, errors.New("email not verified")
+// resolveIdentityUser 外部アカウントに対応するユーザーを返す（必要に応じて連携・新規作成する）
+func (s *OAuthService) resolveIdentityUser(profile *OAuthProfile) (*entity.User, error) {
+	user, err := s.findIdentityOwner(profile)
+	if err != nil || user != nil {
+		return user, err
 	}
 
-	// 既存ユーザーチェック（OAuth）
-	user, err := s.userRepo.GetUserByOAuth("google", userInfo.ID)
+	// メールアドレスで既存ユーザーチェック
+	existingUser, err := s.userRepo.GetUserByEmail(profile.Email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by oauth: %w", err)
+		return nil, fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if existingUser != nil {
+		if err := s.checkAutoLink(existingUser, profile); err != nil {
+			return nil, err
+		}
+		if _, err := s.createIdentity(nil, existingUser.ID, profile); err != nil {
+			return nil, err
+		}
+		if existingUser.AvatarURL == "" {
+			existingUser.AvatarURL = profile.AvatarURL
+		}
+		if existingUser.Name == "" {
+			existingUser.Name = profile.Name
+		}
+		// プロバイダがメールアドレスの所有を確認済み
+		if existingUser.EmailVerifiedAt == nil {
+			now := time.Now()
+			existingUser.EmailVerifiedAt = &now
+		}
+		if err := s.userRepo.UpdateUser(existingUser); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+		promoteAdminIfMatched(existingUser, s.userRepo)
+		s.audit.Record(existingUser.Email, "auth.identity_linked", "user", existingUser.ID, map[string]interface{}{
+			"provider": profile.Provider,
+			"via":      "email_match",
+		})
+		return existingUser, nil
 	}
 
-	// 既存ユーザーがいない場合は新規作成
-	if user == nil {
-		// メールアドレスで既存ユーザーチェック
-		existingUser, err := s.userRepo.GetUserByEmail(userInfo.Email)
+	// 新規ユーザー作成
+	user = &entity.User{
+		Email:         profile.Email,
+		Name:          profile.Name,
+		OAuthProvider: profile.Provider,
+		OAuthID:       profile.Subject,
+		AvatarURL:     profile.AvatarURL,
+		IsGuest:       false,
+		TargetLevel:   "未設定",
+		SchoolName:    "学校法人岩崎学園情報科学専門学校",
+		IsAdmin:       isAdminIdentity(profile.Email, profile.Name),
+	}
+	if profile.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.userRepo.CreateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	if _, err := s.createIdentity(nil, user.ID, profile); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkAutoLink 同じメールアドレスの既存ユーザーに外部アカウントを自動連携してよいか判定する。
+// アカウント乗っ取りを防ぐため、次の場合はログイン後の連携操作を求める:
+//   - プロバイダがメールアドレスを確認していない
+//   - 管理者・教員、または 2段階認証を有効にしたユーザー
+//   - パスワード登録済みでメール未認証のユーザー（第三者が先に登録した可能性がある）
+//   - 同じプロバイダの別アカウントを連携済み
+func (s *OAuthService) checkAutoLink(user *entity.User, profile *OAuthProfile) error {
+	if !profile.EmailVerified || user.IsGuest || user.RequiresMFA() || user.HasTOTP() {
+		return ErrOAuthAccountExists
+	}
+	if user.Password != "" && !user.IsEmailVerified() {
+		return ErrOAuthAccountExists
+	}
+	linked, err := s.userIdentities(user)
+	if err != nil {
+		return err
+	}
+	for _, identity := range linked {
+		if identity.Provider == profile.Provider {
+			return ErrOAuthAccountExists
+		}
+	}
+	return nil
+}
+
+// BeginLink ログイン中のユーザーが外部アカウントを連携するための認証 URL と state を返す（nonce は連携を始めたブラウザの Cookie に置く値）
+func (s *OAuthService) BeginLink(userID uint, provider, nonce string) (string, string, error) {
+	if s.tokens == nil {
+		return "", "", errors.New("token service not configured")
+	}
+	state, err := s.tokens.IssueOAuthLinkState(userID, provider, nonce)
+	if err != nil {
+		return "", "", err
+	}
+	switch provider {
+	case "google":
+		return s.GetGoogleAuthURL(state), state, nil
+	case "github":
+		return s.GetGitHubAuthURL(state), state, nil
+	}
+	return "", "", errors.New("unsupported provider")
+}
+
+// LinkStateUserID コールバックの state が連携用で、nonce（ブラウザの Cookie）が連携を始めたときと一致すれば連携先のユーザーIDを返す
+func (s *OAuthService) LinkStateUserID(state, provider, nonce string) (uint, bool) {
+	if s.tokens == nil || state == "" {
+		return 0, false
+	}
+	claims, err := s.tokens.VerifyOAuthLinkState(state, provider, nonce)
+	if err != nil {
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
+// HandleLinkCallback 連携用の OAuth コールバックで取得した外部アカウントをユーザーに連携する。
+// ゲストユーザーの場合は登録ユーザーへのアップグレードとなり、切り替え後のセッションを返す（通常の連携では nil）
+func (s *OAuthService) HandleLinkCallback(ctx context.Context, provider string, userID uint, code string) (*AuthResponse, error) {
+	var profile *OAuthProfile
+	var err error
+	switch provider {
+	case "google":
+		profile, err = s.fetchGoogleProfile(ctx, code)
+	case "github":
+		profile, err = s.fetchGitHubProfile(ctx, code)
+	default:
+		return nil, errors.New("unsupported provider")
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user != nil && user.IsGuest {
+		return s.UpgradeGuestWithIdentity(userID, profile)
+	}
+	if _, err := s.LinkIdentity(userID, profile); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// UpgradeGuestWithIdentity ゲストユーザーを外部アカウントで登録ユーザーにする。
+//   - 外部アカウントが既存ユーザーに連携済み、または同じメールアドレスの既存ユーザーに安全に自動連携できる場合は、
+//     ゲストのデータをそのユーザーに統合してゲストを削除する
+//   - それ以外はゲストユーザー自体に外部アカウントを連携して登録ユーザーにする（ユーザーIDは変わらない）
+func (s *OAuthService) UpgradeGuestWithIdentity(guestID uint, profile *OAuthProfile) (*AuthResponse, error) {
+	guest, err := s.userRepo.GetUserByID(guestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if guest == nil {
+		return nil, errors.New("user not found")
+	}
+	if !guest.IsGuest {
+		return nil, errors.New("user is not a guest")
+	}
+
+	target, err := s.findIdentityOwner(profile)
+	if err != nil {
+		return nil, err
+	}
+	// 既存ユーザーへ自動連携する場合は、連携の作成とゲストの統合を同じトランザクションで行う
+	linkTarget := false
+	if target == nil {
+		if !profile.EmailVerified {
+			return nil, errors.New("email not verified")
+		}
+		existing, err := s.userRepo.GetUserByEmail(profile.Email)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check existing user: %w", err)
 		}
-
-		if existingUser != nil {
-			// 既存ユーザーにOAuth情報を紐付け
-			existingUser.OAuthProvider = "google"
-			existingUser.OAuthID = userInfo.ID
-			existingUser.AvatarURL = userInfo.Picture
-			if existingUser.Name == "" {
-				existingUser.Name = userInfo.Name
+		if existing != nil {
+			if err := s.checkAutoLink(existing, profile); err != nil {
+				return nil, err
 			}
-			if err := s.userRepo.UpdateUser(existingUser); err != nil {
-				return nil, fmt.Errorf("failed to update user: %w", err)
-			}
-			promoteAdminIfMatched(existingUser, s.userRepo)
-			user = existingUser
-		} else {
-			// 新規ユーザー作成
-			user = &entity.User{
-				Email:         userInfo.Email,
-				Name:          userInfo.Name,
-				OAuthProvider: "google",
-				OAuthID:       userInfo.ID,
-				AvatarURL:     userInfo.Picture,
-				IsGuest:       false,
-				TargetLevel:   "未設定",
-				SchoolName:    "学校法人岩崎学園情報科学専門学校",
-				IsAdmin:       isAdminIdentity(userInfo.Email, userInfo.Name),
+			target, linkTarget = existing, true
+		}
+	}
+
+	if target != nil {
+		if s.db == nil {
+			return nil, errors.New("database not configured")
+		}
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if linkTarget {
+				if _, err := s.createIdentity(tx, target.ID, profile); err != nil {
+					return err
+				}
 			}
-			if err := s.userRepo.CreateUser(user); err != nil {
-				return nil, fmt.Errorf("failed to create user: %w", err)
+			if err := mergeGuestTx(tx, guest.ID, target.ID); err != nil {
+				return fmt.Errorf("failed to merge guest data: %w", err)
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
+		s.audit.Record(target.Email, "auth.guest_merged", "user", target.ID, map[string]interface{}{
+			"guest_user_id": guest.ID,
+			"provider":      profile.Provider,
+		})
+		s.storeProviderToken(target.ID, profile)
+		return s.sessionResponse(target, profile.Provider)
 	}
 
-	return &AuthResponse{
-		UserID:                   user.ID,
-		Email:                    user.Email,
-		Name:                     user.Name,
-		IsGuest:                  user.IsGuest,
-		TargetLevel:              user.TargetLevel,
-		SchoolName:               user.SchoolName,
-		IsAdmin:                  user.IsAdmin,
-		CertificationsAcquired:   user.CertificationsAcquired,
-		CertificationsInProgress: user.CertificationsInProgress,
-		AvatarURL:                user.AvatarURL,
-		OAuthProvider:            "google",
+	guest.Email = profile.Email
+	guest.IsGuest = false
+	if profile.Name != "" {
+		guest.Name = profile.Name
+	}
+	guest.AvatarURL = profile.AvatarURL
+	guest.OAuthProvider = profile.Provider
+	guest.OAuthID = profile.Subject
+	guest.IsAdmin = isAdminIdentity(guest.Email, guest.Name)
+	now := time.Now()
+	guest.EmailVerifiedAt = &now
+	if err := s.userRepo.UpdateUser(guest); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	if _, err := s.createIdentity(nil, guest.ID, profile); err != nil {
+		return nil, err
+	}
+	s.audit.Record(guest.Email, "auth.guest_upgraded", "user", guest.ID, map[string]interface{}{
+		"method": profile.Provider,
+	})
+	s.storeProviderToken(guest.ID, profile)
+	return s.sessionResponse(guest, profile.Provider)
+}
+
+// findIdentityOwner 外部アカウントを連携済みのユーザーを返す（連携テーブル導入前の users.oauth_provider / oauth_id も含む）
+func (s *OAuthService) findIdentityOwner(profile *OAuthProfile) (*entity.User, error) {
+	identity, err := s.identities.FindByProvider(profile.Provider, profile.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+	if identity != nil {
+		user, err := s.userRepo.GetUserByID(identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return nil, errors.New("user not found")
+		}
+		s.touchIdentity(identity, profile)
+		return user, nil
+	}
+	user, err := s.userRepo.GetUserByOAuth(profile.Provider, profile.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by oauth: %w", err)
+	}
+	if user != nil {
+		if _, err := s.userIdentities(user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// LinkIdentity ログイン中のユーザーに外部アカウントを連携する。
+// 別ユーザーに連携済みの外部アカウントや、別ユーザーのメールアドレスを持つ外部アカウントは連携しない（アカウントの統合は行わない）
+func (s *OAuthService) LinkIdentity(userID uint, profile *OAuthProfile) (*models.UserIdentity, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.IsGuest {
+		return nil, errors.New("guest users cannot link accounts")
+	}
+
+	existing, err := s.identities.FindByProvider(profile.Provider, profile.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+	if existing != nil && existing.UserID != user.ID {
+		return nil, ErrIdentityLinkedToOtherUser
+	}
+	legacy, err := s.userRepo.GetUserByOAuth(profile.Provider, profile.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by oauth: %w", err)
+	}
+	if legacy != nil && legacy.ID != user.ID {
+		return nil, ErrIdentityLinkedToOtherUser
+	}
+
+	linked, err := s.userIdentities(user)
+	if err != nil {
+		return nil, err
+	}
+	for i := range linked {
+		if linked[i].Provider != profile.Provider {
+			continue
+		}
+		if linked[i].ProviderUserID != profile.Subject {
+			return nil, ErrProviderAlreadyLinked
+		}
+		// 連携済みの同じアカウント（再連携はトークンの更新のみ）
+		s.touchIdentity(&linked[i], profile)
+		s.storeProviderToken(user.ID, profile)
+		return &linked[i], nil
+	}
+
+	if profile.Email != "" && !strings.EqualFold(profile.Email, user.Email) {
+		other, err := s.userRepo.GetUserByEmail(profile.Email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing user: %w", err)
+		}
+		if other != nil && other.ID != user.ID {
+			return nil, ErrIdentityEmailInUse
+		}
+	}
+
+	identity, err := s.createIdentity(nil, user.ID, profile)
+	if err != nil {
+		return nil, err
+	}
+	if user.AvatarURL == "" && profile.AvatarURL != "" {
+		user.AvatarURL = profile.AvatarURL
+		if err := s.userRepo.UpdateUser(user); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+	s.audit.Record(user.Email, "auth.identity_linked", "user", user.ID, map[string]interface{}{
+		"provider": profile.Provider,
+		"via":      "session",
+	})
+	s.storeProviderToken(user.ID, profile)
+	return identity, nil
+}
+
+// UnlinkIdentity 外部アカウントの連携を解除する。パスワード未設定で最後の連携は解除できない
+func (s *OAuthService) UnlinkIdentity(userID uint, provider string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	linked, err := s.userIdentities(user)
+	if err != nil {
+		return err
+	}
+	var target *models.UserIdentity
+	for i := range linked {
+		if linked[i].Provider == provider {
+			target = &linked[i]
+		}
+	}
+	if target == nil {
+		return ErrIdentityNotFound
+	}
+	if user.Password == "" && len(linked) <= 1 {
+		return ErrLastLoginMethod
+	}
+
+	if err := s.identities.Delete(target.ID); err != nil {
+		return fmt.Errorf("failed to delete identity: %w", err)
+	}
+	if user.OAuthProvider == provider {
+		user.OAuthProvider = ""
+		user.OAuthID = ""
+		if err := s.userRepo.UpdateUser(user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+	if provider == "github" && s.githubService != nil {
+		if err := s.githubService.DisconnectAccount(user.ID); err != nil {
+			fmt.Printf("[OAuthService] failed to clear github access token for user %d: %v\n", user.ID, err)
+		}
+	}
+	s.audit.Record(user.Email, "auth.identity_unlinked", "user", user.ID, map[string]interface{}{
+		"provider": provider,
+	})
+	return nil
+}
+
+// ListLinkedAccounts ユーザーのログイン手段（パスワードの有無と連携済みの外部アカウント）を返す
+func (s *OAuthService) ListLinkedAccounts(userID uint) (*LinkedAccounts, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	linked, err := s.userIdentities(user)
+	if err != nil {
+		return nil, err
+	}
+	return &LinkedAccounts{HasPassword: user.Password != "", Identities: linked}, nil
+}
+
+// userIdentities ユーザーの連携一覧を返す。
+// users.oauth_provider / oauth_id にだけ残っている連携テーブル導入前の情報はここで移行する
+func (s *OAuthService) userIdentities(user *entity.User) ([]models.UserIdentity, error) {
+	linked, err := s.identities.ListByUser(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	if linked == nil {
+		linked = []models.UserIdentity{}
+	}
+	if !user.HasOAuth() {
+		return linked, nil
+	}
+	for _, identity := range linked {
+		if identity.Provider == user.OAuthProvider {
+			return linked, nil
+		}
+	}
+	identity, err := s.createIdentity(nil, user.ID, &OAuthProfile{
+		Provider: user.OAuthProvider,
+		Subject:  user.OAuthID,
+		Email:    user.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(linked, *identity), nil
+}
+
+// createIdentity 外部アカウントの連携を作成する。tx が nil でなければそのトランザクション内で作成する
+func (s *OAuthService) createIdentity(tx *gorm.DB, userID uint, profile *OAuthProfile) (*models.UserIdentity, error) {
+	now := time.Now()
+	identity := &models.UserIdentity{
+		UserID:         userID,
+		Provider:       profile.Provider,
+		ProviderUserID: profile.Subject,
+		Email:          profile.Email,
+		Username:       profile.Username,
+		LastUsedAt:     &now,
+	}
+	var err error
+	if tx != nil {
+		err = tx.Create(identity).Error
+	} else {
+		err = s.identities.Create(identity)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity: %w", err)
+	}
+	return identity, nil
+}
+
+// touchIdentity 最終利用日時とプロバイダ側の表示情報を更新する（失敗してもログインは継続する）
+func (s *OAuthService) touchIdentity(identity *models.UserIdentity, profile *OAuthProfile) {
+	now := time.Now()
+	identity.LastUsedAt = &now
+	if profile.Email != "" {
+		identity.Email = profile.Email
+	}
+	if profile.Username != "" {
+		identity.Username = profile.Username
+	}
+	if err := s.identities.Update(identity); err != nil {
+		fmt.Printf("[OAuthService] failed to update identity %d: %v\n", identity.ID, err)
+	}
+}
+
+// storeProviderToken GitHub のアクセストークンを保存して非同期でデータ同期する
+func (s *OAuthService) storeProviderToken(userID uint, profile *OAuthProfile) {
+	if profile.Provider != "github" || profile.AccessToken == "" || s.githubService == nil {
+		return
+	}
+	if err := s.githubService.StoreAccessToken(userID, profile.Username, profile.AccessToken); err != nil {
+		// トークン保存失敗はログのみ（ログイン自体は成功扱い）
+		fmt.Printf("[OAuthService] failed to store github access token for user %d: %v\n", userID, err)
+		return
+	}
+	s.githubService.TriggerAsyncSync(userID, false)
+}
+
+// fetchGoogleProfile 認可コードを交換して Google のユーザー情報を取得する
+func (s *OAuthService) fetchGoogleProfile(ctx context.Context, code string) (*OAuthProfile, error) {
+	token, err := s.oauthConfig.Google.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange token: %w", err)
+	}
+
+	// ユーザー情報取得
+	client := s.oauthConfig.Google.Client(ctx, token)
+	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var userInfo GoogleUserInfo
+	if err := json.Unmarshal(body, &userInfo); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user info: %w", err)
+	}
+
+	return &OAuthProfile{
+		Provider:      "google",
+		Subject:       userInfo.ID,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.VerifiedEmail,
+		Name:          userInfo.Name,
+		AvatarURL:     userInfo.Picture,
 	}, nil
 }
 
-// HandleGitHubCallback GitHub OAuth認証後のコールバック処理
-func (s *OAuthService) HandleGitHubCallback(ctx context.Context, code string) (*AuthResponse, error) {
+// fetchGitHubProfile 認可コードを交換して GitHub のユーザー情報を取得する
+func (s *OAuthService) fetchGitHubProfile(ctx context.Context, code string) (*OAuthProfile, error) {
 	token, err := s.oauthConfig.GitHub.Exchange(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange token: %w", err)
@@ -179,97 +726,33 @@ func (s *OAuthService) HandleGitHubCallback(ctx context.Context, code string) (*
 		return nil, fmt.Errorf("failed to unmarshal user info: %w", err)
 	}
 
-	// メールアドレスが公開されていない場合は別途取得
+	// 公開メールアドレスは未確認の場合があるため、確認済みのプライマリメールアドレスを優先する
 	email := userInfo.Email
-	if email == "" {
-		email, err = s.getGitHubPrimaryEmail(ctx, client)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get email: %w", err)
-		}
+	verified := false
+	if primary, err := s.getGitHubPrimaryEmail(ctx, client); err == nil {
+		email = primary
+		verified = true
+	} else if email == "" {
+		return nil, fmt.Errorf("failed to get email: %w", err)
 	}
 
 	if email == "" {
 		return nil, errors.New("email not found")
 	}
 
-	// 既存ユーザーチェック（OAuth）
-	oauthID := fmt.Sprintf("%d", userInfo.ID)
-	user, err := s.userRepo.GetUserByOAuth("github", oauthID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user by oauth: %w", err)
+	name := userInfo.Name
+	if name == "" {
+		name = userInfo.Login
 	}
-
-	// 既存ユーザーがいない場合は新規作成
-	if user == nil {
-		// メールアドレスで既存ユーザーチェック
-		existingUser, err := s.userRepo.GetUserByEmail(email)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check existing user: %w", err)
-		}
-
-		if existingUser != nil {
-			// 既存ユーザーにOAuth情報を紐付け
-			existingUser.OAuthProvider = "github"
-			existingUser.OAuthID = oauthID
-			existingUser.AvatarURL = userInfo.AvatarURL
-			if existingUser.Name == "" {
-				name := userInfo.Name
-				if name == "" {
-					name = userInfo.Login
-				}
-				existingUser.Name = name
-			}
-			if err := s.userRepo.UpdateUser(existingUser); err != nil {
-				return nil, fmt.Errorf("failed to update user: %w", err)
-			}
-			promoteAdminIfMatched(existingUser, s.userRepo)
-			user = existingUser
-		} else {
-			// 新規ユーザー作成
-			name := userInfo.Name
-			if name == "" {
-				name = userInfo.Login
-			}
-			user = &entity.User{
-				Email:         email,
-				Name:          name,
-				OAuthProvider: "github",
-				OAuthID:       oauthID,
-				AvatarURL:     userInfo.AvatarURL,
-				IsGuest:       false,
-				TargetLevel:   "未設定",
-				SchoolName:    "学校法人岩崎学園情報科学専門学校",
-				IsAdmin:       isAdminIdentity(email, name),
-			}
-			if err := s.userRepo.CreateUser(user); err != nil {
-				return nil, fmt.Errorf("failed to create user: %w", err)
-			}
-		}
-	}
-
-	// アクセストークン保存 & 非同期データ同期
-	if s.githubService != nil {
-		accessToken := token.AccessToken
-		if err := s.githubService.StoreAccessToken(user.ID, userInfo.Login, accessToken); err != nil {
-			// トークン保存失敗はログのみ（ログイン自体は成功扱い）
-			fmt.Printf("[OAuthService] failed to store github access token for user %d: %v\n", user.ID, err)
-		} else {
-			s.githubService.TriggerAsyncSync(user.ID, false)
-		}
-	}
-
-	return &AuthResponse{
-		UserID:                   user.ID,
-		Email:                    user.Email,
-		Name:                     user.Name,
-		IsGuest:                  user.IsGuest,
-		TargetLevel:              user.TargetLevel,
-		SchoolName:               user.SchoolName,
-		IsAdmin:                  user.IsAdmin,
-		CertificationsAcquired:   user.CertificationsAcquired,
-		CertificationsInProgress: user.CertificationsInProgress,
-		AvatarURL:                user.AvatarURL,
-		OAuthProvider:            "github",
+	return &OAuthProfile{
+		Provider:      "github",
+		Subject:       fmt.Sprintf("%d", userInfo.ID),
+		Email:         email,
+		EmailVerified: verified,
+		Name:          name,
+		Username:      userInfo.Login,
+		AvatarURL:     userInfo.AvatarURL,
+		AccessToken:   token.AccessToken,
 	}, nil
 }
 
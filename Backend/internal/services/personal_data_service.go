@@ -0,0 +1,668 @@
+package services
+
+import (
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 個人データジョブの種別・状態
+const (
+	PersonalDataJobExport  = "export"
+	PersonalDataJobErasure = "erasure"
+
+	PersonalDataJobPending   = "pending"
+	PersonalDataJobRunning   = "running"
+	PersonalDataJobCompleted = "completed"
+	PersonalDataJobFailed    = "failed"
+)
+
+var (
+	// ErrPersonalDataJobNotFound ジョブが存在しない、または呼び出し元のジョブではない
+	ErrPersonalDataJobNotFound = errors.New("job not found")
+	// ErrExportNotReady エクスポートが完了していない、または有効期限切れ
+	ErrExportNotReady = errors.New("export not ready")
+)
+
+// userSecretColumns users テーブルのうちエクスポートに含めない列
+var userSecretColumns = []string{
+	"password",
+	"email_verification_token",
+	"password_reset_token",
+	"password_reset_expires_at",
+	"unlock_token",
+	"totp_secret",
+	"totp_last_counter",
+	"totp_recovery_codes",
+}
+
+// PersonalDataService 個人データのエクスポート（ZIP）と完全削除（個人情報保護法第28条・第35条対応）。
+// 対象テーブルは models.UserDataTables の一覧に従う
+type PersonalDataService struct {
+	db          *gorm.DB
+	jobs        repository.PersonalDataJobRepository
+	exportDir   string
+	store       ObjectStore
+	audit       *AuditLogService
+	exportTTL   time.Duration
+	fileRetries int
+	retryDelay  time.Duration
+}
+
+func NewPersonalDataService(db *gorm.DB, jobs repository.PersonalDataJobRepository, exportDir string) *PersonalDataService {
+	if exportDir == "" {
+		exportDir = "storage/exports"
+	}
+	return &PersonalDataService{
+		db:          db,
+		jobs:        jobs,
+		exportDir:   exportDir,
+		exportTTL:   7 * 24 * time.Hour,
+		fileRetries: 3,
+		retryDelay:  5 * time.Second,
+	}
+}
+
+// SetObjectStore は s3:// に保存されたファイルの読み出し・削除に使うストレージを設定する
+func (s *PersonalDataService) SetObjectStore(store ObjectStore) {
+	s.store = store
+}
+
+// SetAuditLogService はエクスポート・削除の依頼を記録する監査ログを設定する
+func (s *PersonalDataService) SetAuditLogService(audit *AuditLogService) {
+	s.audit = audit
+}
+
+// SetFileRetry はファイル削除の試行回数と再試行までの待ち時間を差し替える
+func (s *PersonalDataService) SetFileRetry(attempts int, delay time.Duration) {
+	if attempts < 1 {
+		attempts = 1
+	}
+	s.fileRetries = attempts
+	s.retryDelay = delay
+}
+
+// RequestExport 個人データのエクスポートを開始する。実行中のエクスポートがあればそれを返す
+func (s *PersonalDataService) RequestExport(userID uint, actorEmail string) (*models.PersonalDataJob, error) {
+	if s.db == nil {
+		return nil, errors.New("database not configured")
+	}
+	existing, err := s.jobs.ListByUser(userID, PersonalDataJobExport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range existing {
+		if isUnfinishedJob(&existing[i]) {
+			return &existing[i], nil
+		}
+	}
+
+	job, err := s.createJob(userID, PersonalDataJobExport)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(actorEmail, "privacy.export_requested", "user", userID, map[string]interface{}{
+		"job_id": job.ID,
+	})
+	snapshot := *job
+	go s.runExport(job)
+	return &snapshot, nil
+}
+
+// RequestErasure アカウントと全データの完全削除を開始する。
+// DB 上のデータは呼び出し中に削除され、保存済みファイルの削除はジョブとして続行する。
+// 削除後はログインできないため、進捗の確認には返却するジョブの状況確認トークンを使う
+func (s *PersonalDataService) RequestErasure(userID uint) (*models.PersonalDataJob, string, error) {
+	if s.db == nil {
+		return nil, "", errors.New("database not configured")
+	}
+	job, err := s.createJob(userID, PersonalDataJobErasure)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.eraseUserData(job); err != nil {
+		s.fail(job, err)
+		return nil, "", fmt.Errorf("failed to erase user data: %w", err)
+	}
+	s.audit.Record(erasedActor(userID), "privacy.erasure_requested", "user", userID, map[string]interface{}{
+		"job_id":      job.ID,
+		"files_total": job.FilesTotal,
+	})
+	snapshot := *job
+	go s.deletePendingFiles(job)
+	return &snapshot, snapshot.StatusToken, nil
+}
+
+// GetJob ユーザー自身のジョブを取得する
+func (s *PersonalDataService) GetJob(userID, jobID uint) (*models.PersonalDataJob, error) {
+	job, err := s.jobs.FindByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.UserID != userID {
+		return nil, ErrPersonalDataJobNotFound
+	}
+	return job, nil
+}
+
+// JobByStatusToken 状況確認トークンでジョブを取得する（削除済みユーザー向け）
+func (s *PersonalDataService) JobByStatusToken(token string) (*models.PersonalDataJob, error) {
+	if token == "" {
+		return nil, ErrPersonalDataJobNotFound
+	}
+	job, err := s.jobs.FindByStatusToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrPersonalDataJobNotFound
+	}
+	return job, nil
+}
+
+// OpenExport 完了したエクスポートの ZIP を開く（呼び出し側で Close する）
+func (s *PersonalDataService) OpenExport(userID, jobID uint) (*os.File, error) {
+	job, err := s.GetJob(userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Kind != PersonalDataJobExport {
+		return nil, ErrPersonalDataJobNotFound
+	}
+	if job.Status != PersonalDataJobCompleted || job.ArtifactPath == "" ||
+		(job.ExpiresAt != nil && time.Now().After(*job.ExpiresAt)) {
+		return nil, ErrExportNotReady
+	}
+	f, err := os.Open(job.ArtifactPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrExportNotReady
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// ResumePendingJobs サーバー停止で中断したジョブを再開する（起動時に呼び出す）
+func (s *PersonalDataService) ResumePendingJobs() {
+	if s.db == nil {
+		return
+	}
+	jobs, err := s.jobs.ListUnfinished()
+	if err != nil {
+		log.Printf("[PersonalData] failed to list unfinished jobs: %v", err)
+		return
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		switch job.Kind {
+		case PersonalDataJobExport:
+			go s.runExport(job)
+		case PersonalDataJobErasure:
+			var count int64
+			if err := s.db.Model(&models.User{}).Where("id = ?", job.UserID).Count(&count).Error; err != nil {
+				log.Printf("[PersonalData] failed to resume erasure job %d: %v", job.ID, err)
+				continue
+			}
+			if count > 0 {
+				// DB の削除前に停止した
+				if err := s.eraseUserData(job); err != nil {
+					s.fail(job, err)
+					continue
+				}
+			}
+			go s.deletePendingFiles(job)
+		}
+	}
+}
+
+func (s *PersonalDataService) createJob(userID uint, kind string) (*models.PersonalDataJob, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	job := &models.PersonalDataJob{
+		UserID:      userID,
+		Kind:        kind,
+		Status:      PersonalDataJobPending,
+		StatusToken: token,
+	}
+	if err := s.jobs.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+	return job, nil
+}
+
+// ─── 収集 ────────────────────────────────────────────────────────────────────
+
+// personalData ユーザーに紐付くデータ一式
+type personalData struct {
+	User     map[string]interface{}
+	Tables   map[string][]map[string]interface{}
+	Insights []map[string]interface{}
+	Files    []storedFile
+	ids      map[string][]interface{} // テーブル名 → 行の id（子テーブルの検索用）
+}
+
+// storedFile ユーザーデータが参照する保存済みファイル
+type storedFile struct {
+	Table string
+	RowID interface{}
+	URI   string // s3:// URI またはローカルパス
+}
+
+// loadPersonalData models.UserDataTables の順にユーザーのデータを読み込む（ユーザーが存在しない場合は nil）
+func (s *PersonalDataService) loadPersonalData(db *gorm.DB, userID uint) (*personalData, error) {
+	var users []map[string]interface{}
+	if err := db.Model(&models.User{}).Where("id = ?", userID).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	data := &personalData{
+		User:   normalizeRow(users[0], userSecretColumns),
+		Tables: make(map[string][]map[string]interface{}),
+		ids:    make(map[string][]interface{}),
+	}
+	email := fmt.Sprint(data.User["email"])
+
+	for _, t := range models.UserDataTables() {
+		q := userDataScope(db, t, userID, email, data.ids)
+		if q == nil {
+			data.Tables[t.Name] = []map[string]interface{}{}
+			continue
+		}
+		var rows []map[string]interface{}
+		if err := q.Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", t.Name, err)
+		}
+		out := make([]map[string]interface{}, 0, len(rows))
+		for _, row := range rows {
+			row = normalizeRow(row, nil)
+			if id, ok := row["id"]; ok {
+				data.ids[t.Name] = append(data.ids[t.Name], id)
+			}
+			data.Files = append(data.Files, s.rowFiles(t, row)...)
+			out = append(out, normalizeRow(row, t.Redact))
+		}
+		data.Tables[t.Name] = out
+	}
+
+	// 集合知ログはユーザーIDを匿名化したハッシュで紐付いている
+	var insights []map[string]interface{}
+	if err := db.Model(&models.CollectiveInsightLog{}).
+		Where("anonymous_user_id = ?", anonymizeUserID(userID)).
+		Find(&insights).Error; err != nil {
+		return nil, fmt.Errorf("failed to load collective insight logs: %w", err)
+	}
+	for i := range insights {
+		insights[i] = normalizeRow(insights[i], nil)
+	}
+	data.Insights = insights
+	return data, nil
+}
+
+// userDataScope テーブル t のうちユーザーに紐付く行の条件（対象がない場合は nil）
+func userDataScope(db *gorm.DB, t models.UserDataTable, userID uint, email string, ids map[string][]interface{}) *gorm.DB {
+	q := db.Unscoped().Model(t.Model)
+	switch {
+	case t.Parent != "":
+		parentIDs := ids[t.Parent]
+		if len(parentIDs) == 0 {
+			return nil
+		}
+		return q.Where(t.Column+" IN ?", parentIDs)
+	case t.Column != "" && t.EmailColumn != "" && email != "":
+		return q.Where(t.Column+" = ? OR "+t.EmailColumn+" = ?", userID, email)
+	case t.Column != "":
+		return q.Where(t.Column+" = ?", userID)
+	case t.EmailColumn != "" && email != "":
+		return q.Where(t.EmailColumn+" = ?", email)
+	}
+	return nil
+}
+
+// rowFiles 行が参照する保存済みファイル
+func (s *PersonalDataService) rowFiles(t models.UserDataTable, row map[string]interface{}) []storedFile {
+	var files []storedFile
+	for _, col := range t.Files {
+		if uri, ok := row[col].(string); ok && uri != "" {
+			files = append(files, storedFile{Table: t.Name, RowID: row["id"], URI: uri})
+		}
+	}
+	for _, col := range t.ObjectKeys {
+		// ストレージ未設定の環境ではオブジェクトはアップロードされていない
+		if key, ok := row[col].(string); ok && key != "" && s.store != nil {
+			files = append(files, storedFile{Table: t.Name, RowID: row["id"], URI: s.store.URI(key)})
+		}
+	}
+	return files
+}
+
+// normalizeRow JSON に出力できる形に変換し、redact の列を取り除く
+func normalizeRow(row map[string]interface{}, redact []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		out[k] = v
+	}
+	for _, col := range redact {
+		delete(out, col)
+	}
+	return out
+}
+
+// ─── エクスポート ────────────────────────────────────────────────────────────
+
+func (s *PersonalDataService) runExport(job *models.PersonalDataJob) {
+	s.start(job)
+	artifact, err := s.writeExport(job)
+	if err != nil {
+		log.Printf("[PersonalData] export job %d failed: %v", job.ID, err)
+		s.fail(job, err)
+		return
+	}
+	expiresAt := time.Now().Add(s.exportTTL)
+	job.ArtifactPath = artifact
+	job.ExpiresAt = &expiresAt
+	s.finish(job, PersonalDataJobCompleted, "")
+}
+
+// writeExport エンティティごとの JSON と保存済みファイルを ZIP にまとめ、保存先のパスを返す
+func (s *PersonalDataService) writeExport(job *models.PersonalDataJob) (string, error) {
+	data, err := s.loadPersonalData(s.db, job.UserID)
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		return "", errors.New("user not found")
+	}
+	if err := os.MkdirAll(s.exportDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create export dir: %w", err)
+	}
+	artifact := filepath.Join(s.exportDir, fmt.Sprintf("personal-data-%d-%d.zip", job.UserID, job.ID))
+	f, err := os.Create(artifact)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	counts := map[string]int{}
+	if err := writeZipJSON(zw, "user.json", data.User); err != nil {
+		return "", err
+	}
+	for _, t := range models.UserDataTables() {
+		rows := data.Tables[t.Name]
+		counts[t.Name] = len(rows)
+		if err := writeZipJSON(zw, t.Name+".json", rows); err != nil {
+			return "", err
+		}
+	}
+	counts["collective_insight_logs"] = len(data.Insights)
+	if err := writeZipJSON(zw, "collective_insight_logs.json", data.Insights); err != nil {
+		return "", err
+	}
+
+	job.FilesTotal = len(data.Files)
+	s.update(job)
+	var included, missing []string
+	for _, file := range data.Files {
+		name := fmt.Sprintf("files/%s/%v/%s", file.Table, file.RowID, path.Base(file.URI))
+		if err := s.copyFileToZip(zw, name, file.URI); err != nil {
+			log.Printf("[PersonalData] export job %d: failed to include %s: %v", job.ID, file.URI, err)
+			job.FilesFailed++
+			missing = append(missing, name)
+		} else {
+			job.FilesDone++
+			included = append(included, name)
+		}
+		s.update(job)
+	}
+
+	manifest := map[string]interface{}{
+		"user_id":       job.UserID,
+		"generated_at":  time.Now(),
+		"tables":        counts,
+		"files":         included,
+		"missing_files": missing,
+	}
+	if err := writeZipJSON(zw, "manifest.json", manifest); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to finalize export: %w", err)
+	}
+	return artifact, nil
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *PersonalDataService) copyFileToZip(zw *zip.Writer, name, uri string) error {
+	src, err := s.openFile(uri)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return copyToWriter(src, w)
+}
+
+func (s *PersonalDataService) openFile(uri string) (io.ReadCloser, error) {
+	if isS3URI(uri) {
+		if s.store == nil {
+			return nil, errors.New("object store not configured")
+		}
+		return s.store.Open(context.Background(), uri)
+	}
+	return os.Open(uri)
+}
+
+// ─── 削除 ────────────────────────────────────────────────────────────────────
+
+// eraseUserData ユーザーの DB 上のデータを1トランザクションで削除し、削除すべきファイルをジョブに記録する
+func (s *PersonalDataService) eraseUserData(job *models.PersonalDataJob) error {
+	userID := job.UserID
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		data, err := s.loadPersonalData(tx, userID)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			return errors.New("user not found")
+		}
+		email := fmt.Sprint(data.User["email"])
+
+		// 子テーブルから順に削除する
+		tables := models.UserDataTables()
+		for i := len(tables) - 1; i >= 0; i-- {
+			t := tables[i]
+			q := userDataScope(tx, t, userID, email, data.ids)
+			if q == nil {
+				continue
+			}
+			if err := q.Delete(t.Model).Error; err != nil {
+				return fmt.Errorf("failed to delete %s: %w", t.Name, err)
+			}
+		}
+		if err := tx.Where("anonymous_user_id = ?", anonymizeUserID(userID)).
+			Delete(&models.CollectiveInsightLog{}).Error; err != nil {
+			return fmt.Errorf("failed to delete collective insight logs: %w", err)
+		}
+
+		// 作成済みのエクスポート ZIP も個人データを含む
+		files := make([]string, 0, len(data.Files))
+		for _, f := range data.Files {
+			files = append(files, f.URI)
+		}
+		var exports []models.PersonalDataJob
+		if err := tx.Where("user_id = ? AND kind = ?", userID, PersonalDataJobExport).Find(&exports).Error; err != nil {
+			return fmt.Errorf("failed to list exports: %w", err)
+		}
+		for _, e := range exports {
+			if e.ArtifactPath != "" {
+				files = append(files, e.ArtifactPath)
+			}
+		}
+		if err := tx.Where("user_id = ? AND kind = ?", userID, PersonalDataJobExport).
+			Delete(&models.PersonalDataJob{}).Error; err != nil {
+			return fmt.Errorf("failed to delete export jobs: %w", err)
+		}
+
+		// 監査ログは操作の記録として残し、操作者のメールアドレスのみ仮名化する
+		if err := tx.Model(&models.AuditLog{}).Where("actor_email = ?", email).
+			Update("actor_email", erasedActor(userID)).Error; err != nil {
+			return fmt.Errorf("failed to pseudonymize audit logs: %w", err)
+		}
+		if err := tx.Delete(&models.User{}, userID).Error; err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		// ファイル一覧はデータ削除と同じトランザクションで記録し、再起動後も削除を続行できるようにする
+		pending, _ := json.Marshal(files)
+		now := time.Now()
+		job.Status = PersonalDataJobRunning
+		job.StartedAt = &now
+		job.FilesTotal = len(files)
+		job.PendingFiles = string(pending)
+		return tx.Save(job).Error
+	})
+}
+
+// deletePendingFiles ジョブに記録したファイルを削除する（失敗したファイルは再試行後も残っていれば失敗として記録）
+func (s *PersonalDataService) deletePendingFiles(job *models.PersonalDataJob) {
+	var pending []string
+	if job.PendingFiles != "" {
+		if err := json.Unmarshal([]byte(job.PendingFiles), &pending); err != nil {
+			s.fail(job, fmt.Errorf("invalid pending files: %w", err))
+			return
+		}
+	}
+	var failed []string
+	for i, uri := range pending {
+		if err := s.deleteFileWithRetry(uri); err != nil {
+			log.Printf("[PersonalData] erasure job %d: failed to delete %s: %v", job.ID, uri, err)
+			failed = append(failed, uri)
+			job.FilesFailed++
+		} else {
+			job.FilesDone++
+		}
+		remaining, _ := json.Marshal(append(append([]string{}, failed...), pending[i+1:]...))
+		job.PendingFiles = string(remaining)
+		s.update(job)
+	}
+
+	if len(failed) > 0 {
+		s.finish(job, PersonalDataJobFailed, fmt.Sprintf("%d files could not be deleted", len(failed)))
+		return
+	}
+	job.PendingFiles = ""
+	s.finish(job, PersonalDataJobCompleted, "")
+	s.audit.Record(erasedActor(job.UserID), "privacy.erasure_completed", "user", job.UserID, map[string]interface{}{
+		"job_id":        job.ID,
+		"files_deleted": job.FilesDone,
+	})
+}
+
+func (s *PersonalDataService) deleteFileWithRetry(uri string) error {
+	var err error
+	for attempt := 0; attempt < s.fileRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(s.retryDelay)
+		}
+		if err = s.deleteFile(uri); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (s *PersonalDataService) deleteFile(uri string) error {
+	if isS3URI(uri) {
+		if s.store == nil {
+			return errors.New("object store not configured")
+		}
+		return s.store.Delete(context.Background(), uri)
+	}
+	if err := os.Remove(uri); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ─── ジョブ状態 ──────────────────────────────────────────────────────────────
+
+func (s *PersonalDataService) start(job *models.PersonalDataJob) {
+	now := time.Now()
+	job.Status = PersonalDataJobRunning
+	job.StartedAt = &now
+	job.FilesTotal, job.FilesDone, job.FilesFailed = 0, 0, 0
+	job.Error = ""
+	s.update(job)
+}
+
+func (s *PersonalDataService) finish(job *models.PersonalDataJob, status, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Error = message
+	job.CompletedAt = &now
+	s.update(job)
+}
+
+func (s *PersonalDataService) fail(job *models.PersonalDataJob, err error) {
+	s.finish(job, PersonalDataJobFailed, err.Error())
+}
+
+func (s *PersonalDataService) update(job *models.PersonalDataJob) {
+	if err := s.jobs.Update(job); err != nil {
+		log.Printf("[PersonalData] failed to update job %d: %v", job.ID, err)
+	}
+}
+
+func isUnfinishedJob(job *models.PersonalDataJob) bool {
+	return job.Status == PersonalDataJobPending || job.Status == PersonalDataJobRunning
+}
+
+// erasedActor 削除済みユーザーを監査ログ上で表す仮名
+func erasedActor(userID uint) string {
+	return fmt.Sprintf("deleted-user-%d", userID)
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"Backend/domain/ai"
+	"Backend/domain/repository"
+	"context"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 置き換える個人情報の種類
+const (
+	PIIKindEmail   = "email"   // メールアドレス
+	PIIKindPhone   = "phone"   // 電話番号（市外局番・携帯・+81）
+	PIIKindPostal  = "postal"  // 郵便番号
+	PIIKindAddress = "address" // 都道府県から番地までの住所
+	PIIKindSchool  = "school"  // 大学・高校などの学校名
+	PIIKindName    = "name"    // 「氏名:」欄の値と、よくある姓に敬称・名が続く人名
+	PIIKindProfile = "profile" // 呼び出し元ユーザーの登録情報（氏名・メールアドレス・学校名）
+)
+
+// allPIIKinds すべての種類
+var allPIIKinds = []string{PIIKindProfile, PIIKindEmail, PIIKindPhone, PIIKindPostal, PIIKindAddress, PIIKindSchool, PIIKindName}
+
+// PIIPolicy 機能ごとに置き換える個人情報の種類（空なら置き換えない）
+type PIIPolicy struct {
+	Kinds []string
+}
+
+// DefaultPIIPolicies 機能ごとの既定の方針。公開情報だけを扱うクロールは置き換えない
+func DefaultPIIPolicies() map[string]PIIPolicy {
+	all := PIIPolicy{Kinds: allPIIKinds}
+	return map[string]PIIPolicy{
+		ai.FeatureChat:            all,
+		ai.FeatureInterview:       all,
+		ai.FeatureInterviewReport: all,
+		ai.FeatureResumeReview:    all,
+		ai.FeatureESRewrite:       all,
+		FeatureESReview:           all,
+		ai.FeatureGitHubSummary:   {Kinds: []string{PIIKindProfile, PIIKindEmail, PIIKindPhone}},
+		ai.FeatureSemanticSearch:  {Kinds: []string{PIIKindProfile, PIIKindEmail, PIIKindPhone}},
+	}
+}
+
+// PIIRedactionDisabledFeaturesFromEnv は PII_REDACTION_DISABLED_FEATURES（カンマ区切りの機能名）を返す
+func PIIRedactionDisabledFeaturesFromEnv() []string {
+	var features []string
+	for _, f := range strings.Split(os.Getenv("PII_REDACTION_DISABLED_FEATURES"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+const (
+	piiDigit  = `[0-9０-９]`
+	piiHyphen = `[-‐－−ー―]`
+)
+
+var piiPrefectures = `北海道|東京都|京都府|大阪府|青森県|岩手県|宮城県|秋田県|山形県|福島県|茨城県|栃木県|群馬県|埼玉県|千葉県|神奈川県|新潟県|富山県|石川県|福井県|山梨県|長野県|岐阜県|静岡県|愛知県|三重県|滋賀県|兵庫県|奈良県|和歌山県|鳥取県|島根県|岡山県|広島県|山口県|徳島県|香川県|愛媛県|高知県|福岡県|佐賀県|長崎県|熊本県|大分県|宮崎県|鹿児島県|沖縄県`
+
+// piiSurnames 人名の判定に使うよくある姓（敬称か名が続く場合だけ人名とみなす）
+var piiSurnames = `佐々木|長谷川|五十嵐|久保田|大久保|佐藤|鈴木|高橋|田中|伊藤|渡辺|渡部|山本|中村|小林|加藤|吉田|山田|山口|松本|井上|木村|斎藤|斉藤|清水|山崎|池田|橋本|阿部|石川|山下|中島|石井|小川|前田|岡田|藤田|後藤|近藤|村上|遠藤|青木|坂本|福田|太田|西村|藤井|金子|岡本|藤原|中野|三浦|原田|中川|松田|竹内|小野|田村|中山|和田|石田|森田|上田|内田|柴田|酒井|宮崎|横山|高木|安藤|宮本|大野|小島|工藤|谷口|今井|高田|丸山|増田|杉山|村田|大塚|小山|平野|河野|上野|野口|武田|松井|千葉|岩崎|菅原|木下|久保|佐野|野村|松尾|市川|菊地|菊池|杉本|古川|大西|島田|水野|桜井|高野|吉川|山内|西田|飯田|西川|小松|北村|安田|川口|平田|中田|服部|岩田|土屋|川崎|本田|樋口|秋山|田口|永井|山中|中西|吉村|川上|石原|大橋|松岡|馬場|浅野|荒木|野田|小池|林|森|原|関|辻`
+
+type piiRule struct {
+	kind  string
+	tag   string
+	re    *regexp.Regexp
+	group int  // 置き換える部分式（0 なら一致全体）
+	digit bool // 前後に数字が続く一致は除く（長い番号の一部）
+	valid func(match string) bool
+}
+
+// piiRules 置き換えの順に並べる（先に置き換えた記号には後のルールが一致しない）
+var piiRules = []piiRule{
+	{kind: PIIKindEmail, tag: "EMAIL", re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)},
+	{kind: PIIKindPhone, tag: "PHONE", digit: true, valid: validPhoneNumber, re: regexp.MustCompile(
+		`(\+81[ \-]?|[0０])` + piiDigit + `{1,4}(` + piiHyphen + `|[ 　(（)）]{1,2})?` + piiDigit + `{1,4}(` + piiHyphen + `|[ 　)）])?` + piiDigit + `{3,4}`)},
+	{kind: PIIKindPostal, tag: "POSTAL", digit: true, re: regexp.MustCompile(`(〒[ 　]?)?` + piiDigit + `{3}` + piiHyphen + piiDigit + `{4}`)},
+	{kind: PIIKindAddress, tag: "ADDRESS", re: regexp.MustCompile(
+		`(` + piiPrefectures + `)[^\s、。,，()（）「」]{1,30}?` + piiDigit + `+(丁目|番地|番|号|` + piiHyphen + `)(` + piiDigit + `+(番地|番|号|` + piiHyphen + `)?){0,3}`)},
+	{kind: PIIKindSchool, tag: "SCHOOL", re: regexp.MustCompile(`[一-龥々ァ-ヶーA-Za-zＡ-Ｚａ-ｚ]{2,20}(大学院|短期大学|大学|高等専門学校|高専|高等学校|高校|専門学校|中学校)`)},
+	{kind: PIIKindName, tag: "NAME", group: 1, re: regexp.MustCompile(`(?:氏名|名前|ふりがな|フリガナ)(?:[ \t　]*[:：][ \t　]*|[ \t　]+)([一-龥々ぁ-んァ-ヶー]{1,8}(?:[ 　][一-龥々ぁ-んァ-ヶー]{1,8})?)`)},
+	{kind: PIIKindName, tag: "NAME", group: 1, re: regexp.MustCompile(`((?:` + piiSurnames + `)(?:[ 　]?[一-龥々]{1,3})?)(?:さん|様|さま|くん|君|氏|先生|ちゃん)`)},
+	{kind: PIIKindName, tag: "NAME", group: 1, re: regexp.MustCompile(`((?:` + piiSurnames + `)[ 　][一-龥々]{1,3})(?:[^一-龥々]|$)`)},
+}
+
+// validPhoneNumber は数字の桁数が電話番号として妥当か（国内 10〜11 桁、+81 付きは 11〜12 桁）を返す
+func validPhoneNumber(match string) bool {
+	digits := 0
+	for _, r := range match {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if strings.HasPrefix(match, "+81") {
+		return digits >= 11 && digits <= 12
+	}
+	return digits >= 10 && digits <= 11
+}
+
+// piiProfileTTL 呼び出し元ユーザーの登録情報を保持する時間
+const piiProfileTTL = 5 * time.Minute
+
+type piiProfile struct {
+	values   []piiValue
+	loadedAt time.Time
+}
+
+type piiValue struct {
+	tag   string
+	value string
+}
+
+// PIIRedactor LLM・RAG サービスに送るテキストの個人情報を記号（例: [PHONE_1]）に置き換える
+// 置き換える種類は ai.CallContext の機能ごとの方針で決め、呼び出し元ユーザーの登録情報は users から読む
+// nil の PIIRedactor は何も置き換えない
+type PIIRedactor struct {
+	users    repository.UserRepository
+	policies map[string]PIIPolicy
+	now      func() time.Time
+
+	mu       sync.Mutex
+	profiles map[uint]piiProfile
+}
+
+func NewPIIRedactor(users repository.UserRepository) *PIIRedactor {
+	return &PIIRedactor{users: users, policies: DefaultPIIPolicies(), now: time.Now, profiles: map[uint]piiProfile{}}
+}
+
+// SetPolicy は機能の方針を上書きする（起動時に呼ぶ）
+func (p *PIIRedactor) SetPolicy(feature string, policy PIIPolicy) {
+	p.policies[feature] = policy
+}
+
+// Redact は ctx の機能の方針で texts の個人情報を置き換える。
+// 同じ値はすべてのテキストで同じ記号になり、返した Redaction で応答の記号を元に戻せる（置き換えなければ nil）
+func (p *PIIRedactor) Redact(ctx context.Context, texts ...string) ([]string, *ai.Redaction) {
+	if p == nil {
+		return texts, nil
+	}
+	cc := ai.CallContextFrom(ctx)
+	policy, ok := p.policies[cc.Feature]
+	if !ok || len(policy.Kinds) == 0 {
+		return texts, nil
+	}
+	redaction := ai.NewRedaction()
+	out := make([]string, len(texts))
+	var profile []piiValue
+	if containsPIIKind(policy.Kinds, PIIKindProfile) {
+		profile = p.profile(cc.UserID)
+	}
+	for i, text := range texts {
+		out[i] = redactText(text, policy.Kinds, profile, redaction)
+	}
+	if redaction.Len() == 0 {
+		return texts, nil
+	}
+	return out, redaction
+}
+
+// redactText は text の kinds の個人情報を redaction の記号に置き換える
+func redactText(text string, kinds []string, profile []piiValue, redaction *ai.Redaction) string {
+	for _, v := range profile {
+		if strings.Contains(text, v.value) {
+			text = strings.ReplaceAll(text, v.value, redaction.Placeholder(v.tag, v.value))
+		}
+	}
+	for _, rule := range piiRules {
+		if containsPIIKind(kinds, rule.kind) {
+			text = rule.apply(text, redaction)
+		}
+	}
+	return text
+}
+
+func (r piiRule) apply(text string, redaction *ai.Redaction) string {
+	matches := r.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*r.group], m[2*r.group+1]
+		if start < 0 || start < last {
+			continue
+		}
+		value := text[start:end]
+		if r.digit && (digitBefore(text, start) || digitAfter(text, end)) {
+			continue
+		}
+		if r.valid != nil && !r.valid(value) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(redaction.Placeholder(r.tag, value))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func digitBefore(text string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return i > 0 && unicode.IsDigit(r)
+}
+
+func digitAfter(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return i < len(text) && unicode.IsDigit(r)
+}
+
+// profile はユーザーの登録情報のうち置き換える値を長い順に返す（TTL の間はメモリに保持する）
+func (p *PIIRedactor) profile(userID uint) []piiValue {
+	if userID == 0 || p.users == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cached, ok := p.profiles[userID]; ok && p.now().Sub(cached.loadedAt) < piiProfileTTL {
+		return cached.values
+	}
+	user, err := p.users.GetUserByID(userID)
+	if err != nil || user == nil {
+		if err != nil {
+			log.Printf("[PII] failed to load user %d: %v", userID, err)
+		}
+		return nil
+	}
+	var values []piiValue
+	add := func(tag, value string) {
+		value = strings.TrimSpace(value)
+		if utf8.RuneCountInString(value) >= 2 {
+			values = append(values, piiValue{tag: tag, value: value})
+		}
+	}
+	add("NAME", user.Name)
+	if parts := strings.Fields(user.Name); len(parts) > 1 {
+		for _, part := range parts {
+			add("NAME", part)
+		}
+	}
+	add("EMAIL", user.Email)
+	add("SCHOOL", user.SchoolName)
+	// 氏名の一部より氏名全体を先に置き換える
+	sort.SliceStable(values, func(i, j int) bool { return len(values[i].value) > len(values[j].value) })
+	p.profiles[userID] = piiProfile{values: values, loadedAt: p.now()}
+	return values
+}
+
+func containsPIIKind(kinds []string, kind string) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
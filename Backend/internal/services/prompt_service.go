@@ -0,0 +1,236 @@
+package services
+
+import (
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/services/prompts"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PromptService プロンプトの版の登録・公開・ロールバック（管理画面用）
+type PromptService struct {
+	repo     repository.PromptVersionRepository
+	registry *prompts.Registry
+	now      func() time.Time
+}
+
+func NewPromptService(repo repository.PromptVersionRepository, registry *prompts.Registry) *PromptService {
+	return &PromptService{repo: repo, registry: registry, now: time.Now}
+}
+
+// PromptSummary プロンプトごとの公開中の版
+type PromptSummary struct {
+	Name           string     `json:"name"`
+	ActiveVersion  string     `json:"active_version"`
+	BuiltinVersion string     `json:"builtin_version"`
+	Variables      []string   `json:"variables"`
+	PublishedAt    *time.Time `json:"published_at,omitempty"`
+}
+
+// PromptVersionView プロンプトの版（ファイルの既定版を含む）
+type PromptVersionView struct {
+	Version     string     `json:"version"`
+	Body        string     `json:"body"`
+	Description string     `json:"description"`
+	IsActive    bool       `json:"is_active"`
+	Builtin     bool       `json:"builtin"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// List 全プロンプトの公開中の版
+func (s *PromptService) List() ([]PromptSummary, error) {
+	active, err := s.repo.ListActive()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.PromptVersion, len(active))
+	for _, v := range active {
+		byName[v.Name] = v
+	}
+	names := prompts.Names()
+	summaries := make([]PromptSummary, 0, len(names))
+	for _, name := range names {
+		_, builtinVersion, variables, _ := prompts.Builtin(name)
+		summary := PromptSummary{Name: name, ActiveVersion: builtinVersion, BuiltinVersion: builtinVersion, Variables: variables}
+		if v, ok := byName[name]; ok {
+			summary.ActiveVersion = prompts.VersionLabel(v.Version)
+			summary.PublishedAt = v.PublishedAt
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// Versions プロンプトの版を新しい順に返す（最後がファイルの既定版）
+func (s *PromptService) Versions(name string) ([]PromptVersionView, error) {
+	body, builtinVersion, _, ok := prompts.Builtin(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", prompts.ErrUnknownPrompt, name)
+	}
+	versions, err := s.repo.ListByName(name)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]PromptVersionView, 0, len(versions)+1)
+	builtinActive := true
+	for _, v := range versions {
+		createdAt := v.CreatedAt
+		views = append(views, PromptVersionView{
+			Version:     prompts.VersionLabel(v.Version),
+			Body:        v.Body,
+			Description: v.Description,
+			IsActive:    v.IsActive,
+			PublishedAt: v.PublishedAt,
+			CreatedBy:   v.CreatedBy,
+			CreatedAt:   &createdAt,
+		})
+		if v.IsActive {
+			builtinActive = false
+		}
+	}
+	views = append(views, PromptVersionView{
+		Version:     builtinVersion,
+		Body:        body,
+		Description: "ファイルの既定版",
+		IsActive:    builtinActive,
+		Builtin:     true,
+	})
+	return views, nil
+}
+
+// CreateVersion 新しい版を登録する（publish なら続けて公開する）
+func (s *PromptService) CreateVersion(name, body, description string, publish bool, actorEmail string) (*PromptVersionView, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("%w: body is required", prompts.ErrInvalidTemplate)
+	}
+	if err := prompts.Validate(name, body); err != nil {
+		return nil, err
+	}
+	v := &models.PromptVersion{
+		Name:        name,
+		Body:        body,
+		Description: strings.TrimSpace(description),
+		CreatedBy:   actorEmail,
+	}
+	if err := s.repo.CreateNextVersion(v); err != nil {
+		return nil, fmt.Errorf("failed to save prompt version: %w", err)
+	}
+	if publish {
+		summary, err := s.publish(name, v.Version, actorEmail)
+		if err != nil {
+			return nil, err
+		}
+		v.IsActive = true
+		v.PublishedAt = summary.PublishedAt
+	}
+	return &PromptVersionView{
+		Version:     prompts.VersionLabel(v.Version),
+		Body:        v.Body,
+		Description: v.Description,
+		IsActive:    v.IsActive,
+		PublishedAt: v.PublishedAt,
+		CreatedBy:   v.CreatedBy,
+		CreatedAt:   &v.CreatedAt,
+	}, nil
+}
+
+// Publish 指定した版を公開する（"builtin" ならファイルの既定版に戻す）
+func (s *PromptService) Publish(name, version, actorEmail string) (*PromptSummary, error) {
+	if _, _, _, ok := prompts.Builtin(name); !ok {
+		return nil, fmt.Errorf("%w: %s", prompts.ErrUnknownPrompt, name)
+	}
+	n, err := prompts.ParseVersionLabel(name, version)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		v, err := s.repo.FindVersion(name, n)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, fmt.Errorf("%w: %s@%s", prompts.ErrVersionNotFound, name, version)
+		}
+	}
+	return s.publish(name, n, actorEmail)
+}
+
+// publish 版を公開して公開履歴に追記する
+func (s *PromptService) publish(name string, version int, actorEmail string) (*PromptSummary, error) {
+	summary, err := s.activate(name, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RecordPublication(&models.PromptPublication{
+		Name:        name,
+		Version:     version,
+		PublishedBy: actorEmail,
+		PublishedAt: s.now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record prompt publication: %w", err)
+	}
+	return summary, nil
+}
+
+// Rollback 最新の公開を取り消し、その前に公開していた版に戻す（なければファイルの既定版）。
+// 公開履歴を遡るため、繰り返すとさらに前の版に戻る
+func (s *PromptService) Rollback(name string) (*PromptSummary, error) {
+	if _, _, _, ok := prompts.Builtin(name); !ok {
+		return nil, fmt.Errorf("%w: %s", prompts.ErrUnknownPrompt, name)
+	}
+	pubs, err := s.repo.ListPublications(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(pubs) == 0 || (len(pubs) == 1 && pubs[0].Version == 0) {
+		// 公開履歴がない（履歴の記録前に公開した）版が公開中ならファイルの既定版に戻す
+		active, err := s.repo.ListByName(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range active {
+			if v.IsActive {
+				return s.revertTo(name, 0, pubs)
+			}
+		}
+		return nil, fmt.Errorf("%w: %s has no previously published version", prompts.ErrVersionNotFound, name)
+	}
+	target := 0
+	if len(pubs) > 1 {
+		target = pubs[1].Version
+	}
+	return s.revertTo(name, target, pubs[:1])
+}
+
+// revertTo 版 version を公開し、取り消す公開履歴 reverted を記録する（公開履歴には追記しない）
+func (s *PromptService) revertTo(name string, version int, reverted []models.PromptPublication) (*PromptSummary, error) {
+	summary, err := s.activate(name, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range reverted {
+		if err := s.repo.RevertPublication(p.ID, s.now()); err != nil {
+			return nil, fmt.Errorf("failed to record prompt rollback: %w", err)
+		}
+	}
+	return summary, nil
+}
+
+func (s *PromptService) activate(name string, version int) (*PromptSummary, error) {
+	now := s.now()
+	if err := s.repo.Activate(name, version, now); err != nil {
+		return nil, fmt.Errorf("failed to publish prompt version: %w", err)
+	}
+	s.registry.Invalidate()
+	_, builtinVersion, variables, _ := prompts.Builtin(name)
+	summary := &PromptSummary{Name: name, ActiveVersion: builtinVersion, BuiltinVersion: builtinVersion, Variables: variables}
+	if version > 0 {
+		summary.ActiveVersion = prompts.VersionLabel(version)
+		summary.PublishedAt = &now
+	}
+	return summary, nil
+}
@@ -0,0 +1,416 @@
+// Package prompts はAIプロンプト文字列を集約管理するパッケージです。
+// プロンプトの変更は必ずこのパッケージ内で行い、サービス層での直接定義を避けてください。
+// プロンプトは templates/ にテンプレートとして置き、Registry で管理画面から登録・公開した版に差し替えられます。
+package prompts
+
+import (
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// ──────────────────────────────────────────────
+// プロンプトレジストリ
+// ──────────────────────────────────────────────
+
+// レジストリで管理するプロンプト名（templates/<名前>.tmpl が既定版）
+const (
+	InterviewReport       = "interview_report"
+	ESRewrite             = "es_rewrite"
+	ResumeReview          = "resume_review"
+	ResumeReviewRetry     = "resume_review_retry"
+	CrawlJobListings      = "crawl_job_listings"
+	CrawlJobSiteCompany   = "crawl_job_site_company"
+	CrawlPopularCompanies = "crawl_popular_companies"
+	AnswerValidation      = "answer_validation"
+	InputGuard            = "input_guard"
+
+	// チャットの質問生成・採点
+	QuestionStrategic           = "question_strategic"
+	QuestionLowConfidence       = "question_low_confidence"
+	QuestionUnevaluatedCategory = "question_unevaluated_category"
+	QuestionDeepening           = "question_deepening"
+	QuestionSimplify            = "question_simplify"
+	QuestionAddChoices          = "question_add_choices"
+	QuestionRegenerate          = "question_regenerate"
+	JobFitScoring               = "job_fit_scoring"
+)
+
+// BuiltinVersion はファイルの既定版を指す版名（固定・公開の指定に使う）。
+const BuiltinVersion = "builtin"
+
+var (
+	// ErrUnknownPrompt はレジストリにないプロンプト名が指定されたことを表す。
+	ErrUnknownPrompt = errors.New("unknown prompt")
+	// ErrInvalidTemplate はテンプレートの構文や変数が不正なことを表す。
+	ErrInvalidTemplate = errors.New("invalid prompt template")
+	// ErrVersionNotFound は指定した版が存在しないことを表す。
+	ErrVersionNotFound = errors.New("prompt version not found")
+)
+
+//go:embed templates/*.tmpl templates/shared/*.tmpl
+var templateFS embed.FS
+
+// Vars はテンプレート変数。
+type Vars map[string]interface{}
+
+// Rendered は変数を埋め込んだプロンプト。
+type Rendered struct {
+	Name    string
+	Version string
+	System  string
+	User    string
+}
+
+// Ref は出力に記録するプロンプトの版（例: interview_report@v3）。
+func (r *Rendered) Ref() string {
+	return r.Name + "@" + r.Version
+}
+
+// builtin はファイルの既定版。版名は本文のハッシュから決める（例: builtin-1a2b3c4d）。
+type builtin struct {
+	body      string
+	version   string
+	variables []string
+}
+
+var builtins = loadBuiltins()
+
+// shared は templates/shared/ の共通部品（{{define}} のみ）。すべてのプロンプトから {{template "名前"}} で参照できる
+var shared = loadShared()
+
+func loadShared() []string {
+	entries, err := templateFS.ReadDir("templates/shared")
+	if err != nil {
+		panic(err)
+	}
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		data, err := templateFS.ReadFile(path.Join("templates/shared", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, string(data))
+	}
+	return out
+}
+
+func loadBuiltins() map[string]builtin {
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+	out := make(map[string]builtin, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		data, err := templateFS.ReadFile(path.Join("templates", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		body := string(data)
+		tmpl, err := parseTemplate(e.Name(), body)
+		if err != nil {
+			panic(fmt.Sprintf("prompts: %s: %v", e.Name(), err))
+		}
+		sum := sha256.Sum256(data)
+		out[strings.TrimSuffix(e.Name(), ".tmpl")] = builtin{
+			body:      body,
+			version:   BuiltinVersion + "-" + hex.EncodeToString(sum[:4]),
+			variables: templateVariables(tmpl),
+		}
+	}
+	return out
+}
+
+// Names はレジストリで管理するプロンプト名を返す。
+func Names() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Builtin はファイルの既定版の本文・版名・変数を返す。
+func Builtin(name string) (body, version string, variables []string, ok bool) {
+	b, ok := builtins[name]
+	return b.body, b.version, b.variables, ok
+}
+
+// VersionLabel は DB の版の版名（例: v3）。
+func VersionLabel(version int) string {
+	return "v" + strconv.Itoa(version)
+}
+
+// ParseVersionLabel は版名を DB の版番号に変換する（既定版は 0）。
+func ParseVersionLabel(name, label string) (int, error) {
+	if label == BuiltinVersion || (builtins[name].version != "" && label == builtins[name].version) {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(label, "v"))
+	if err != nil || n <= 0 || !strings.HasPrefix(label, "v") {
+		return 0, fmt.Errorf("%w: %s@%s", ErrVersionNotFound, name, label)
+	}
+	return n, nil
+}
+
+type pinsKey struct{}
+
+// WithPinnedVersions はプロンプト名→版名の固定を ctx に載せる（A/B テストのバリアントなど）。
+// 固定した版が見つからない場合は公開中の版を使う。
+func WithPinnedVersions(ctx context.Context, pins map[string]string) context.Context {
+	if len(pins) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, pinsKey{}, pins)
+}
+
+func pinnedVersion(ctx context.Context, name string) string {
+	if ctx == nil {
+		return ""
+	}
+	pins, _ := ctx.Value(pinsKey{}).(map[string]string)
+	return pins[name]
+}
+
+// Registry はファイルの既定版と DB に登録した版からプロンプトを組み立てる。
+// 公開中の版は activeTTL ごとに DB から読み直すため、他のインスタンスでの公開も遅れて反映される。
+// nil の Registry はファイルの既定版だけを使う。
+type Registry struct {
+	repo      repository.PromptVersionRepository
+	activeTTL time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	active   map[string]*models.PromptVersion
+	loadedAt time.Time
+	pinned   map[string]*models.PromptVersion // 版は登録後に変わらないので期限なしで保持する
+}
+
+func NewRegistry(repo repository.PromptVersionRepository) *Registry {
+	return &Registry{repo: repo, activeTTL: 30 * time.Second, now: time.Now, pinned: make(map[string]*models.PromptVersion)}
+}
+
+// SetNow は現在時刻の取得関数を差し替える（テスト用）。
+func (r *Registry) SetNow(now func() time.Time) {
+	r.now = now
+}
+
+// Invalidate は公開中の版の読み込み結果を破棄する（公開・ロールバック後に呼ぶ）。
+func (r *Registry) Invalidate() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.active = nil
+	r.mu.Unlock()
+}
+
+// Render は name のプロンプトに vars を埋め込む。ctx に固定の版があればそれを、なければ公開中の版を使い、
+// DB の版が読めない・描画できない場合はファイルの既定版に戻す。
+func (r *Registry) Render(ctx context.Context, name string, vars Vars) (*Rendered, error) {
+	b, ok := builtins[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPrompt, name)
+	}
+	if v := r.resolve(ctx, name); v != nil {
+		rendered, err := render(name, VersionLabel(v.Version), v.Body, vars)
+		if err == nil {
+			return rendered, nil
+		}
+		log.Printf("[Prompts] %s@%s: %v; falling back to %s", name, VersionLabel(v.Version), err, b.version)
+	}
+	return render(name, b.version, b.body, vars)
+}
+
+// Validate は name のプロンプトとして body を登録できるか検証する。
+// 既定版にない変数を参照していればエラーにする。
+func Validate(name, body string) error {
+	b, ok := builtins[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownPrompt, name)
+	}
+	sample := Vars{}
+	for _, v := range b.variables {
+		sample[v] = ""
+	}
+	if _, err := render(name, "", body, sample); err != nil {
+		if errors.Is(err, ErrInvalidTemplate) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+// resolve は使用する DB の版を返す（nil ならファイルの既定版）。
+func (r *Registry) resolve(ctx context.Context, name string) *models.PromptVersion {
+	if r == nil || r.repo == nil {
+		return nil
+	}
+	if label := pinnedVersion(ctx, name); label != "" {
+		version, err := ParseVersionLabel(name, label)
+		if err == nil && version == 0 {
+			return nil
+		}
+		if err == nil {
+			if v := r.pinnedVersion(name, version); v != nil {
+				return v
+			}
+		}
+		log.Printf("[Prompts] pinned version %s@%s not found; using the published version", name, label)
+	}
+	return r.activeVersion(name)
+}
+
+func (r *Registry) activeVersion(name string) *models.PromptVersion {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if r.active == nil || now.Sub(r.loadedAt) >= r.activeTTL {
+		versions, err := r.repo.ListActive()
+		if err != nil {
+			log.Printf("[Prompts] failed to load published versions: %v", err)
+			if r.active == nil {
+				return nil
+			}
+		} else {
+			r.active = make(map[string]*models.PromptVersion, len(versions))
+			for i := range versions {
+				r.active[versions[i].Name] = &versions[i]
+			}
+			r.loadedAt = now
+		}
+	}
+	return r.active[name]
+}
+
+func (r *Registry) pinnedVersion(name string, version int) *models.PromptVersion {
+	key := name + "@" + VersionLabel(version)
+	r.mu.Lock()
+	v, ok := r.pinned[key]
+	r.mu.Unlock()
+	if ok {
+		return v
+	}
+	v, err := r.repo.FindVersion(name, version)
+	if err != nil || v == nil {
+		return nil
+	}
+	r.mu.Lock()
+	r.pinned[key] = v
+	r.mu.Unlock()
+	return v
+}
+
+func render(name, version, body string, vars Vars) (*Rendered, error) {
+	tmpl, err := parseTemplate(name, body)
+	if err != nil {
+		return nil, err
+	}
+	out := &Rendered{Name: name, Version: version}
+	if t := tmpl.Lookup("system"); t != nil {
+		if out.System, err = execute(t, vars); err != nil {
+			return nil, err
+		}
+	}
+	t := tmpl.Lookup("user")
+	if t == nil {
+		return nil, fmt.Errorf(`%w: {{define "user"}} is missing`, ErrInvalidTemplate)
+	}
+	if out.User, err = execute(t, vars); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func parseTemplate(name, body string) (*template.Template, error) {
+	tmpl := template.New(name).Option("missingkey=error")
+	for _, part := range shared {
+		if _, err := tmpl.Parse(part); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl.Parse(body)
+}
+
+// templateVariables はテンプレートが参照する変数（.Name）を返す。
+func templateVariables(tmpl *template.Template) []string {
+	seen := map[string]bool{}
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				for _, arg := range cmd.Args {
+					walk(arg)
+				}
+			}
+		case *parse.FieldNode:
+			seen[n.Ident[0]] = true
+		}
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			walk(t.Tree.Root)
+		}
+	}
+	vars := make([]string, 0, len(seen))
+	for v := range seen {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+func execute(t *template.Template, vars Vars) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -1,9 +1,9 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/repository"
 	"Backend/internal/models"
-	"Backend/internal/openai"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -11,12 +11,12 @@ import (
 )
 
 type QuestionGeneratorService struct {
-	aiClient           *openai.Client
+	aiClient           ai.LLMClient
 	questionWeightRepo repository.QuestionWeightRepository
 }
 
 func NewQuestionGeneratorService(
-	aiClient *openai.Client,
+	aiClient ai.LLMClient,
 	questionWeightRepo repository.QuestionWeightRepository,
 ) *QuestionGeneratorService {
 	return &QuestionGeneratorService{
@@ -62,7 +62,7 @@ func (s *QuestionGeneratorService) GenerateAndSaveQuestions(ctx context.Context,
   ...
 ]`, req.Count, req.Category, req.Category)
 
-	response, err := s.aiClient.Responses(ctx, prompt)
+	response, err := s.aiClient.GenerateText(ctx, "", prompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate questions: %w", err)
 	}
@@ -0,0 +1,373 @@
+package services
+
+import (
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/repositories"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrWeightRuleNotFound 指定した加点ルールがない
+	ErrWeightRuleNotFound = errors.New("weight rule not found")
+	// ErrQuestionTemplateNotFound 指定した AI 質問テンプレートがない
+	ErrQuestionTemplateNotFound = errors.New("question template not found")
+	// ErrQuestionTemplateInUse テンプレートから作った質問が残っていて削除できない（無効にする）
+	ErrQuestionTemplateInUse = errors.New("question template is used by generated questions")
+	// ErrEmptyTranscript ドライランする会話がない
+	ErrEmptyTranscript = errors.New("transcript has no answers to evaluate")
+)
+
+// QuestionRuleService 回答の加点ルール（WeightRule）と AI 質問テンプレート（AIQuestionTemplate）の管理と評価
+type QuestionRuleService struct {
+	repo            *repositories.QuestionRuleRepository
+	chatMessageRepo repository.ChatMessageRepository
+	evaluator       *AnswerEvaluator
+}
+
+func NewQuestionRuleService(repo *repositories.QuestionRuleRepository, chatMessageRepo repository.ChatMessageRepository) *QuestionRuleService {
+	return &QuestionRuleService{repo: repo, chatMessageRepo: chatMessageRepo, evaluator: NewAnswerEvaluator()}
+}
+
+// ── 加点ルール ───────────────────────────────────────────────────────────────
+
+// ActiveWeightRules は有効な加点ルールを評価するエンジンを返す（ルールがなければ nil）
+func (s *QuestionRuleService) ActiveWeightRules() *WeightRuleEngine {
+	if s == nil {
+		return nil
+	}
+	rules, err := s.repo.ListWeightRules(true)
+	if err != nil {
+		log.Printf("[WeightRule] failed to load rules: %v", err)
+		return nil
+	}
+	engine := NewWeightRuleEngine(rules)
+	if engine.Len() == 0 {
+		return nil
+	}
+	return engine
+}
+
+// ListWeightRules はすべての加点ルールと、条件を読めず評価から外している有効なルールを返す
+func (s *QuestionRuleService) ListWeightRules() ([]models.WeightRule, []InvalidWeightRule, error) {
+	rules, err := s.repo.ListWeightRules(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rules, NewWeightRuleEngine(rules).InvalidRules(), nil
+}
+
+func (s *QuestionRuleService) GetWeightRule(id uint) (*models.WeightRule, error) {
+	rule, err := s.repo.FindWeightRule(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %d", ErrWeightRuleNotFound, id)
+	}
+	return rule, err
+}
+
+// CreateWeightRule はルールを確かめて登録する（条件の JSON は整形して保存する）
+func (s *QuestionRuleService) CreateWeightRule(rule models.WeightRule) (*models.WeightRule, error) {
+	if err := normalizeWeightRule(&rule); err != nil {
+		return nil, err
+	}
+	rule.ID = 0
+	if err := s.repo.CreateWeightRule(&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateWeightRule はルールの内容を置き換える
+func (s *QuestionRuleService) UpdateWeightRule(id uint, rule models.WeightRule) (*models.WeightRule, error) {
+	current, err := s.GetWeightRule(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := normalizeWeightRule(&rule); err != nil {
+		return nil, err
+	}
+	rule.ID = current.ID
+	rule.CreatedAt = current.CreatedAt
+	if err := s.repo.SaveWeightRule(&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// DeleteWeightRule はルールを削除する
+func (s *QuestionRuleService) DeleteWeightRule(id uint) error {
+	err := s.repo.DeleteWeightRule(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %d", ErrWeightRuleNotFound, id)
+	}
+	return err
+}
+
+func normalizeWeightRule(rule *models.WeightRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if err := ValidateWeightRule(*rule); err != nil {
+		return err
+	}
+	condition, _ := ParseWeightRuleCondition(rule.Condition)
+	raw, _ := json.Marshal(condition)
+	rule.Condition = string(raw)
+	return nil
+}
+
+// ── AI 質問テンプレート ───────────────────────────────────────────────────────
+
+// QuestionTemplate はカテゴリの質問生成に使う有効なテンプレート（基本重みが最も高いもの）を返す（なければ nil）
+func (s *QuestionRuleService) QuestionTemplate(category string) *models.AIQuestionTemplate {
+	if s == nil || category == "" {
+		return nil
+	}
+	templates, err := s.repo.ListQuestionTemplates(category, true)
+	if err != nil {
+		log.Printf("[QuestionTemplate] failed to load templates for %s: %v", category, err)
+		return nil
+	}
+	for i := range templates {
+		if ValidateQuestionTemplate(templates[i]) == nil {
+			return &templates[i]
+		}
+	}
+	return nil
+}
+
+// ListQuestionTemplates はテンプレートを返す（category が空なら全カテゴリ）
+func (s *QuestionRuleService) ListQuestionTemplates(category string) ([]models.AIQuestionTemplate, error) {
+	return s.repo.ListQuestionTemplates(category, false)
+}
+
+func (s *QuestionRuleService) GetQuestionTemplate(id uint) (*models.AIQuestionTemplate, error) {
+	template, err := s.repo.FindQuestionTemplate(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %d", ErrQuestionTemplateNotFound, id)
+	}
+	return template, err
+}
+
+// CreateQuestionTemplate はテンプレートを確かめて登録する
+func (s *QuestionRuleService) CreateQuestionTemplate(template models.AIQuestionTemplate) (*models.AIQuestionTemplate, error) {
+	if err := normalizeQuestionTemplate(&template); err != nil {
+		return nil, err
+	}
+	template.ID = 0
+	if err := s.repo.CreateQuestionTemplate(&template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// UpdateQuestionTemplate はテンプレートの内容を置き換える
+func (s *QuestionRuleService) UpdateQuestionTemplate(id uint, template models.AIQuestionTemplate) (*models.AIQuestionTemplate, error) {
+	current, err := s.GetQuestionTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := normalizeQuestionTemplate(&template); err != nil {
+		return nil, err
+	}
+	template.ID = current.ID
+	template.CreatedAt = current.CreatedAt
+	if err := s.repo.SaveQuestionTemplate(&template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// DeleteQuestionTemplate はテンプレートを削除する（作った質問が残っていれば削除せず ErrQuestionTemplateInUse）
+func (s *QuestionRuleService) DeleteQuestionTemplate(id uint) error {
+	if _, err := s.GetQuestionTemplate(id); err != nil {
+		return err
+	}
+	count, err := s.repo.CountTemplateQuestions(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: %d questions", ErrQuestionTemplateInUse, count)
+	}
+	err = s.repo.DeleteQuestionTemplate(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %d", ErrQuestionTemplateNotFound, id)
+	}
+	return err
+}
+
+func normalizeQuestionTemplate(template *models.AIQuestionTemplate) error {
+	template.Category = strings.TrimSpace(template.Category)
+	if err := ValidateQuestionTemplate(*template); err != nil {
+		return err
+	}
+	if template.BaseWeight == 0 {
+		template.BaseWeight = defaultTemplateBaseWeight
+	}
+	template.ContextKeys = normalizeTemplateContextKeys(template.ContextKeys)
+	return nil
+}
+
+// ── ドライラン ───────────────────────────────────────────────────────────────
+
+// WeightRuleDryRunRequest 会話に加点ルールを当てはめて、どのルールが発火するかを確かめる
+type WeightRuleDryRunRequest struct {
+	SessionID     string              `json:"session_id"`      // 指定すればセッションの会話を使う
+	Messages      []DryRunMessage     `json:"messages"`        // session_id がなければこの会話を使う
+	Phase         string              `json:"phase"`           // 回答の時点のフェーズ（メッセージごとに上書きできる）
+	TargetLevel   string              `json:"target_level"`    // 新卒 / 中途
+	IndustryID    uint                `json:"industry_id"`     // セッションの業界
+	JobCategoryID uint                `json:"job_category_id"` // セッションの職種
+	Rules         []models.WeightRule `json:"rules"`           // 指定すれば保存済みの有効なルールの代わりにこのルールをすべて評価する（保存前の確認用）
+}
+
+// DryRunMessage ドライランする会話の 1 件
+type DryRunMessage struct {
+	Role     string `json:"role"` // "assistant"（質問） / "user"（回答）
+	Content  string `json:"content"`
+	Phase    string `json:"phase,omitempty"`    // この回答の時点のフェーズ
+	Category string `json:"category,omitempty"` // 採点するカテゴリ（空なら質問文から推測）
+}
+
+// WeightRuleDryRunTurn 回答 1 件の評価
+type WeightRuleDryRunTurn struct {
+	Index      int               `json:"index"` // 会話の中の回答の位置
+	Question   string            `json:"question"`
+	Answer     string            `json:"answer"`
+	Category   string            `json:"category"`
+	Phase      string            `json:"phase,omitempty"`
+	BaseScore  int               `json:"base_score"` // 加点前のスコア
+	Boost      int               `json:"boost"`      // 加点の合計（0〜100 に収める前）
+	Score      int               `json:"score"`      // 加点後のスコア
+	Skipped    string            `json:"skipped,omitempty"`
+	FiredRules []FiredWeightRule `json:"fired_rules"`
+}
+
+// WeightRuleHit ルールごとの発火回数
+type WeightRuleHit struct {
+	RuleID uint   `json:"rule_id"`
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+}
+
+// WeightRuleDryRun ドライランの結果
+type WeightRuleDryRun struct {
+	Turns        []WeightRuleDryRunTurn `json:"turns"`
+	RuleHits     []WeightRuleHit        `json:"rule_hits"`
+	InvalidRules []InvalidWeightRule    `json:"invalid_rules,omitempty"`
+}
+
+// DryRunWeightRules は会話の回答ごとにチャットと同じ採点をし、発火するルールと加点後のスコアを返す（スコアは保存しない）
+func (s *QuestionRuleService) DryRunWeightRules(req WeightRuleDryRunRequest) (*WeightRuleDryRun, error) {
+	messages := req.Messages
+	if req.SessionID != "" {
+		history, err := s.chatMessageRepo.FindBySessionID(req.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("会話の取得エラー: %w", err)
+		}
+		messages = make([]DryRunMessage, 0, len(history))
+		for _, msg := range history {
+			messages = append(messages, DryRunMessage{Role: msg.Role, Content: msg.Content})
+		}
+	}
+
+	var engine *WeightRuleEngine
+	if req.Rules != nil {
+		for i := range req.Rules {
+			req.Rules[i].IsActive = true
+		}
+		engine = NewWeightRuleEngine(req.Rules)
+	} else {
+		rules, err := s.repo.ListWeightRules(true)
+		if err != nil {
+			return nil, err
+		}
+		engine = NewWeightRuleEngine(rules)
+	}
+
+	return dryRunWeightRules(engine, s.evaluator, messages, req)
+}
+
+func dryRunWeightRules(engine *WeightRuleEngine, evaluator *AnswerEvaluator, messages []DryRunMessage, req WeightRuleDryRunRequest) (*WeightRuleDryRun, error) {
+	result := &WeightRuleDryRun{Turns: []WeightRuleDryRunTurn{}, RuleHits: []WeightRuleHit{}, InvalidRules: engine.InvalidRules()}
+	// 保存前のルールは ID が 0 のことがあるので名前と合わせて数える
+	type ruleKey struct {
+		id   uint
+		name string
+	}
+	hits := map[ruleKey]int{}
+	var order []FiredWeightRule
+
+	question := ""
+	for i, msg := range messages {
+		if msg.Role == "assistant" {
+			question = msg.Content
+			continue
+		}
+		if msg.Role != "user" || question == "" {
+			continue
+		}
+
+		turn := WeightRuleDryRunTurn{Index: i, Question: question, Answer: msg.Content, Category: msg.Category, Phase: msg.Phase, FiredRules: []FiredWeightRule{}}
+		if turn.Category == "" {
+			turn.Category = inferQuestionCategory(question)
+		}
+		if turn.Phase == "" {
+			turn.Phase = req.Phase
+		}
+
+		trimmed := strings.TrimSpace(msg.Content)
+		isChoice := len(trimmed) <= 3 && isChoiceAnswerText(trimmed)
+		if !isChoice {
+			isChoice = !isTextBasedQuestion(question)
+		}
+		scored := evaluator.EvaluateHumanScoring(question, msg.Content, isChoice, req.JobCategoryID != 0, nil)
+		switch {
+		case scored.Action != PrecheckScore:
+			turn.Skipped = scored.Reason
+		case scored.Score <= 0:
+			turn.Skipped = "no score"
+		}
+		if turn.Skipped != "" {
+			result.Turns = append(result.Turns, turn)
+			continue
+		}
+
+		turn.BaseScore = scored.Score
+		score, fired := engine.Apply(WeightRuleFacts{
+			Answer:        msg.Content,
+			Category:      turn.Category,
+			Phase:         turn.Phase,
+			TargetLevel:   req.TargetLevel,
+			IndustryID:    req.IndustryID,
+			JobCategoryID: req.JobCategoryID,
+			AnswerScore:   scored.Score,
+		})
+		turn.Score = score
+		for _, f := range fired {
+			turn.Boost += f.WeightBoost
+			key := ruleKey{f.RuleID, f.Name}
+			if hits[key] == 0 {
+				order = append(order, f)
+			}
+			hits[key]++
+		}
+		if fired != nil {
+			turn.FiredRules = fired
+		}
+		result.Turns = append(result.Turns, turn)
+	}
+
+	if len(result.Turns) == 0 {
+		return nil, ErrEmptyTranscript
+	}
+	for _, f := range order {
+		result.RuleHits = append(result.RuleHits, WeightRuleHit{RuleID: f.RuleID, Name: f.Name, Count: hits[ruleKey{f.RuleID, f.Name}]})
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"Backend/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidQuestionTemplate AI 質問テンプレートの内容が不正
+var ErrInvalidQuestionTemplate = errors.New("invalid question template")
+
+// defaultTemplateBaseWeight テンプレートの基本重みの既定値（AIGeneratedQuestion.Weight に使う）
+const defaultTemplateBaseWeight = 5
+
+// QuestionTemplateContextKeys テンプレートの ContextKeys で指定できる文脈の名前。
+// プロンプト中の {{名前}} は値に置き換え、それ以外は参考情報としてプロンプトに添える。
+// ここにない名前は質問に織り込みたいキーワードとして扱う
+var QuestionTemplateContextKeys = []string{
+	"answer_history",   // 直近の回答
+	"scores",           // 現在の評価状況
+	"phase",            // 分析フェーズ名
+	"target_level",     // 新卒 / 中途
+	"job_category",     // 志望職種名
+	"job_category_ids", // 志望職種 ID
+	"industry_ids",     // 業界 ID
+}
+
+// ParseTemplateContextKeys は ContextKeys の JSON 配列を読む（空なら nil）
+func ParseTemplateContextKeys(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var keys []string
+	if err := json.Unmarshal([]byte(raw), &keys); err != nil {
+		return nil, fmt.Errorf("%w: context_keys must be a JSON array of strings", ErrInvalidQuestionTemplate)
+	}
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%w: context_keys must not contain empty keys", ErrInvalidQuestionTemplate)
+		}
+	}
+	return keys, nil
+}
+
+// ValidateQuestionTemplate はテンプレートのカテゴリ・指示・基本重み・文脈の名前を確かめる
+func ValidateQuestionTemplate(template models.AIQuestionTemplate) error {
+	category := strings.TrimSpace(template.Category)
+	if category == "" || len([]rune(category)) > 50 {
+		return fmt.Errorf("%w: category is required (up to 50 characters)", ErrInvalidQuestionTemplate)
+	}
+	if strings.TrimSpace(template.Prompt) == "" {
+		return fmt.Errorf("%w: prompt is required", ErrInvalidQuestionTemplate)
+	}
+	if template.BaseWeight < 0 || template.BaseWeight > 10 {
+		return fmt.Errorf("%w: base_weight must be between 0 and 10", ErrInvalidQuestionTemplate)
+	}
+	_, err := ParseTemplateContextKeys(template.ContextKeys)
+	return err
+}
+
+// RenderQuestionTemplate はテンプレートの指示に文脈の値を当てはめ、質問生成のプロンプトに添える節を作る
+func RenderQuestionTemplate(template models.AIQuestionTemplate, values map[string]string) string {
+	keys, err := ParseTemplateContextKeys(template.ContextKeys)
+	if err != nil {
+		keys = nil
+	}
+
+	prompt := strings.TrimSpace(template.Prompt)
+	var info, keywords []string
+	for _, key := range keys {
+		if !containsString(QuestionTemplateContextKeys, key) {
+			keywords = append(keywords, key)
+			continue
+		}
+		placeholder := "{{" + key + "}}"
+		if strings.Contains(prompt, placeholder) {
+			prompt = strings.ReplaceAll(prompt, placeholder, values[key])
+			continue
+		}
+		if value := strings.TrimSpace(values[key]); value != "" {
+			info = append(info, fmt.Sprintf("- %s: %s", key, value))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 質問テンプレート（%s）\n%s\n", template.Category, prompt)
+	if len(info) > 0 {
+		fmt.Fprintf(&b, "\n### 参考情報\n%s\n", strings.Join(info, "\n"))
+	}
+	if len(keywords) > 0 {
+		fmt.Fprintf(&b, "\n### 質問に織り込みたいキーワード\n%s\n", strings.Join(keywords, "、"))
+	}
+	return b.String()
+}
+
+// normalizeTemplateContextKeys は ContextKeys を整形した JSON 配列にする（空なら "[]"）
+func normalizeTemplateContextKeys(raw string) string {
+	keys, _ := ParseTemplateContextKeys(raw)
+	if keys == nil {
+		keys = []string{}
+	}
+	out, _ := json.Marshal(keys)
+	return string(out)
+}
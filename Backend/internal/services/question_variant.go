@@ -0,0 +1,244 @@
+package services
+
+import (
+	"Backend/domain/entity"
+	"Backend/internal/models"
+	"Backend/internal/services/prompts"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+)
+
+// ErrInvalidVariantConfig バリアントの設定が不正
+var ErrInvalidVariantConfig = errors.New("invalid variant config")
+
+// defaultRecentAnswerWeight カテゴリスコアの移動平均で直近の回答に掛ける重み
+const defaultRecentAnswerWeight = 0.3
+
+// VariantConfig バリアントで変える質問フローの設定（QuestionVariant.ConfigJSON）。未設定の項目は既定の挙動
+type VariantConfig struct {
+	QuestionPool   *VariantQuestionPool          `json:"question_pool,omitempty"`
+	PhaseQuestions map[string]VariantPhaseLimits `json:"phase_questions,omitempty"` // フェーズ名→質問数
+	Scoring        *VariantScoring               `json:"scoring,omitempty"`
+}
+
+// VariantQuestionPool 事前定義の質問の使い方
+type VariantQuestionPool struct {
+	DisablePredefined  bool   `json:"disable_predefined,omitempty"`   // 事前定義の質問を使わず、すべて AI が質問を作る
+	QuestionIDs        []uint `json:"question_ids,omitempty"`         // 事前定義の質問をこの ID に限る
+	ExcludeQuestionIDs []uint `json:"exclude_question_ids,omitempty"` // この ID の事前定義の質問は使わない
+}
+
+// VariantPhaseLimits フェーズの最小・最大の質問数（0 ならフェーズの設定のまま）
+type VariantPhaseLimits struct {
+	MinQuestions int `json:"min_questions,omitempty"`
+	MaxQuestions int `json:"max_questions,omitempty"`
+}
+
+// VariantScoring 回答のスコアリングの変更
+type VariantScoring struct {
+	// CategoryMultipliers カテゴリ→回答 1 件のスコアに掛ける倍率（"*" は他のすべてのカテゴリ）
+	CategoryMultipliers map[string]float64 `json:"category_multipliers,omitempty"`
+	// RecentAnswerWeight カテゴリスコアの移動平均で直近の回答に掛ける重み（0 より大きく 1 以下。0 なら 0.3）
+	RecentAnswerWeight float64 `json:"recent_answer_weight,omitempty"`
+}
+
+// Validate は設定の値の範囲を確かめる
+func (c *VariantConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for name, limits := range c.PhaseQuestions {
+		if limits.MinQuestions < 0 || limits.MaxQuestions < 0 {
+			return fmt.Errorf("%w: phase %s has a negative question count", ErrInvalidVariantConfig, name)
+		}
+		if limits.MaxQuestions > 0 && limits.MinQuestions > limits.MaxQuestions {
+			return fmt.Errorf("%w: phase %s has min_questions greater than max_questions", ErrInvalidVariantConfig, name)
+		}
+	}
+	if c.Scoring != nil {
+		for category, multiplier := range c.Scoring.CategoryMultipliers {
+			if multiplier < 0 || math.IsNaN(multiplier) || math.IsInf(multiplier, 0) {
+				return fmt.Errorf("%w: multiplier for %s must be zero or positive", ErrInvalidVariantConfig, category)
+			}
+		}
+		if w := c.Scoring.RecentAnswerWeight; w < 0 || w > 1 {
+			return fmt.Errorf("%w: recent_answer_weight must be between 0 and 1", ErrInvalidVariantConfig)
+		}
+	}
+	return nil
+}
+
+// SessionVariants セッションに割り当てたバリアントの設定をまとめたもの。
+// 複数の実験に割り当てられたときは割り当て順に重ね、同じ項目は後の割り当てを優先する。
+// nil は割り当てなし（既定の挙動）として扱える
+type SessionVariants struct {
+	Assignments []models.VariantAssignment
+
+	config         VariantConfig
+	promptVersions map[string]string
+}
+
+// NewSessionVariants は割り当て（Variant を読み込み済み）から設定をまとめる。割り当てがなければ nil
+func NewSessionVariants(assignments []models.VariantAssignment) *SessionVariants {
+	if len(assignments) == 0 {
+		return nil
+	}
+	sv := &SessionVariants{Assignments: assignments}
+	for _, a := range assignments {
+		if a.Variant == nil {
+			continue
+		}
+		if a.Variant.PromptVersionsJSON != "" {
+			var pins map[string]string
+			if err := json.Unmarshal([]byte(a.Variant.PromptVersionsJSON), &pins); err != nil {
+				log.Printf("[Experiment] variant %d: invalid prompt versions: %v", a.VariantID, err)
+			}
+			for name, version := range pins {
+				if sv.promptVersions == nil {
+					sv.promptVersions = map[string]string{}
+				}
+				sv.promptVersions[name] = version
+			}
+		}
+		if a.Variant.ConfigJSON != "" {
+			var cfg VariantConfig
+			if err := json.Unmarshal([]byte(a.Variant.ConfigJSON), &cfg); err != nil {
+				log.Printf("[Experiment] variant %d: invalid config: %v", a.VariantID, err)
+				continue
+			}
+			sv.merge(&cfg)
+		}
+	}
+	return sv
+}
+
+func (sv *SessionVariants) merge(cfg *VariantConfig) {
+	if cfg.QuestionPool != nil {
+		sv.config.QuestionPool = cfg.QuestionPool
+	}
+	for name, limits := range cfg.PhaseQuestions {
+		if sv.config.PhaseQuestions == nil {
+			sv.config.PhaseQuestions = map[string]VariantPhaseLimits{}
+		}
+		sv.config.PhaseQuestions[name] = limits
+	}
+	if cfg.Scoring != nil {
+		if sv.config.Scoring == nil {
+			sv.config.Scoring = &VariantScoring{}
+		}
+		for category, multiplier := range cfg.Scoring.CategoryMultipliers {
+			if sv.config.Scoring.CategoryMultipliers == nil {
+				sv.config.Scoring.CategoryMultipliers = map[string]float64{}
+			}
+			sv.config.Scoring.CategoryMultipliers[category] = multiplier
+		}
+		if cfg.Scoring.RecentAnswerWeight > 0 {
+			sv.config.Scoring.RecentAnswerWeight = cfg.Scoring.RecentAnswerWeight
+		}
+	}
+}
+
+// PromptContext はバリアントが固定したプロンプトの版を ctx に載せる
+func (sv *SessionVariants) PromptContext(ctx context.Context) context.Context {
+	if sv == nil || len(sv.promptVersions) == 0 {
+		return ctx
+	}
+	return prompts.WithPinnedVersions(ctx, sv.promptVersions)
+}
+
+// AllowsPredefinedQuestion は事前定義の質問 id を出してよいかを返す
+func (sv *SessionVariants) AllowsPredefinedQuestion(id uint) bool {
+	if sv == nil || sv.config.QuestionPool == nil {
+		return true
+	}
+	pool := sv.config.QuestionPool
+	if pool.DisablePredefined {
+		return false
+	}
+	for _, excluded := range pool.ExcludeQuestionIDs {
+		if excluded == id {
+			return false
+		}
+	}
+	if len(pool.QuestionIDs) == 0 {
+		return true
+	}
+	for _, included := range pool.QuestionIDs {
+		if included == id {
+			return true
+		}
+	}
+	return false
+}
+
+// PredefinedQuestionsDisabled は事前定義の質問をまったく使わないかを返す
+func (sv *SessionVariants) PredefinedQuestionsDisabled() bool {
+	return sv != nil && sv.config.QuestionPool != nil && sv.config.QuestionPool.DisablePredefined
+}
+
+// ApplyPhase はバリアントの質問数をフェーズに当てはめたコピーを返す
+func (sv *SessionVariants) ApplyPhase(phase entity.AnalysisPhase) entity.AnalysisPhase {
+	if sv == nil {
+		return phase
+	}
+	limits, ok := sv.config.PhaseQuestions[phase.PhaseName]
+	if !ok {
+		return phase
+	}
+	if limits.MinQuestions > 0 {
+		phase.MinQuestions = limits.MinQuestions
+	}
+	if limits.MaxQuestions > 0 {
+		phase.MaxQuestions = limits.MaxQuestions
+	}
+	if phase.MaxQuestions > 0 && phase.MinQuestions > phase.MaxQuestions {
+		phase.MinQuestions = phase.MaxQuestions
+	}
+	return phase
+}
+
+// ApplyPhases は ApplyPhase をすべてのフェーズに当てはめる
+func (sv *SessionVariants) ApplyPhases(phases []entity.AnalysisPhase) []entity.AnalysisPhase {
+	if sv == nil || len(sv.config.PhaseQuestions) == 0 {
+		return phases
+	}
+	out := make([]entity.AnalysisPhase, len(phases))
+	for i := range phases {
+		out[i] = sv.ApplyPhase(phases[i])
+	}
+	return out
+}
+
+// ScaleScore は回答 1 件のスコアにカテゴリの倍率を掛ける（0〜100 に収める）
+func (sv *SessionVariants) ScaleScore(category string, score int) int {
+	if sv == nil || sv.config.Scoring == nil {
+		return score
+	}
+	multiplier, ok := sv.config.Scoring.CategoryMultipliers[category]
+	if !ok {
+		multiplier, ok = sv.config.Scoring.CategoryMultipliers["*"]
+	}
+	if !ok {
+		return score
+	}
+	scaled := int(math.Round(float64(score) * multiplier))
+	if scaled > 100 {
+		return 100
+	}
+	if scaled < 0 {
+		return 0
+	}
+	return scaled
+}
+
+// RecentAnswerWeight はカテゴリスコアの移動平均で直近の回答に掛ける重みを返す
+func (sv *SessionVariants) RecentAnswerWeight() float64 {
+	if sv == nil || sv.config.Scoring == nil || sv.config.Scoring.RecentAnswerWeight <= 0 {
+		return defaultRecentAnswerWeight
+	}
+	return sv.config.Scoring.RecentAnswerWeight
+}
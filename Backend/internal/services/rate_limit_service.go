@@ -0,0 +1,111 @@
+package services
+
+import (
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/ratelimit"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRateLimit 制限値が不正
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// RateLimitService レート制限値の参照・変更（DB に保存し、稼働中の Limiter に即時反映する）
+type RateLimitService struct {
+	repo    repository.RateLimitSettingRepository
+	limiter *ratelimit.Limiter
+}
+
+func NewRateLimitService(repo repository.RateLimitSettingRepository, limiter *ratelimit.Limiter) *RateLimitService {
+	return &RateLimitService{repo: repo, limiter: limiter}
+}
+
+// RateLimitGroup グループ名付きの制限値
+type RateLimitGroup struct {
+	Group string `json:"group"`
+	ratelimit.Limit
+}
+
+// LoadOverrides 保存済みの設定を Limiter に反映する（起動時に呼ぶ）
+func (s *RateLimitService) LoadOverrides() error {
+	settings, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	known := s.limiter.Limits()
+	for _, st := range settings {
+		if _, ok := known[st.GroupName]; !ok {
+			continue
+		}
+		s.limiter.SetLimit(st.GroupName, settingToLimit(st))
+	}
+	return nil
+}
+
+// List 全グループの現在値
+func (s *RateLimitService) List() []RateLimitGroup {
+	limits := s.limiter.Limits()
+	groups := make([]RateLimitGroup, 0, len(limits))
+	for _, g := range s.limiter.Groups() {
+		groups = append(groups, RateLimitGroup{Group: g, Limit: limits[g]})
+	}
+	return groups
+}
+
+// RateLimitUpdate 制限値の変更内容。Enabled を省略した場合は現在の有効・無効を保つ
+type RateLimitUpdate struct {
+	Enabled       *bool   `json:"enabled"`
+	UserPerMinute float64 `json:"user_per_minute"`
+	UserBurst     int     `json:"user_burst"`
+	IPPerMinute   float64 `json:"ip_per_minute"`
+	IPBurst       int     `json:"ip_burst"`
+}
+
+// Update グループの制限値を変更する
+func (s *RateLimitService) Update(group string, update RateLimitUpdate, actorEmail string) (*RateLimitGroup, error) {
+	current, ok := s.limiter.Limits()[group]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	limit := ratelimit.Limit{
+		Enabled:       current.Enabled,
+		UserPerMinute: update.UserPerMinute,
+		UserBurst:     update.UserBurst,
+		IPPerMinute:   update.IPPerMinute,
+		IPBurst:       update.IPBurst,
+	}
+	if update.Enabled != nil {
+		limit.Enabled = *update.Enabled
+	}
+	if limit.UserPerMinute < 0 || limit.IPPerMinute < 0 || limit.UserBurst < 0 || limit.IPBurst < 0 {
+		return nil, fmt.Errorf("%w: limits must not be negative", ErrInvalidRateLimit)
+	}
+	if (limit.UserPerMinute > 0 && limit.UserBurst < 1) || (limit.IPPerMinute > 0 && limit.IPBurst < 1) {
+		return nil, fmt.Errorf("%w: burst must be at least 1 when a rate is set", ErrInvalidRateLimit)
+	}
+	setting := &models.RateLimitSetting{
+		GroupName:     group,
+		Enabled:       limit.Enabled,
+		UserPerMinute: limit.UserPerMinute,
+		UserBurst:     limit.UserBurst,
+		IPPerMinute:   limit.IPPerMinute,
+		IPBurst:       limit.IPBurst,
+		UpdatedBy:     actorEmail,
+	}
+	if err := s.repo.Upsert(setting); err != nil {
+		return nil, fmt.Errorf("failed to save rate limit: %w", err)
+	}
+	s.limiter.SetLimit(group, limit)
+	return &RateLimitGroup{Group: group, Limit: limit}, nil
+}
+
+func settingToLimit(st models.RateLimitSetting) ratelimit.Limit {
+	return ratelimit.Limit{
+		Enabled:       st.Enabled,
+		UserPerMinute: st.UserPerMinute,
+		UserBurst:     st.UserBurst,
+		IPPerMinute:   st.IPPerMinute,
+		IPBurst:       st.IPBurst,
+	}
+}
@@ -47,6 +47,7 @@ type RealtimeUsageService struct {
 	maxConcurrent           int64
 	ratePerMinuteUSD        float64
 	sessionDurationMinutes  int
+	pricing                 *PricingService
 
 	mu               sync.Mutex
 	lastAlertMonthID string
@@ -73,6 +74,20 @@ func NewRealtimeUsageService(repo *repositories.RealtimeUsageRepository, emailSe
 	}
 }
 
+// SetPricing はモデル単価表の 1 分あたりの単価を使うようにする（オプション）。
+// 単価表に RealtimeModel() の単価がない期間は INTERVIEW_COST_PER_MIN_USD を使う
+func (s *RealtimeUsageService) SetPricing(pricing *PricingService) {
+	s.pricing = pricing
+}
+
+// RatePerMinute は at の時点のリアルタイム音声の 1 分あたりの単価（USD）を返す
+func (s *RealtimeUsageService) RatePerMinute(at time.Time) float64 {
+	if rate, ok := s.pricing.PerMinuteRate(RealtimeModel(), at); ok {
+		return rate
+	}
+	return s.ratePerMinuteUSD
+}
+
 // SessionDurationMinutes はユーザー向けのセッション時間（分）を返す。
 // コスト上限は内部管理のみとし、UXには時間として公開する。
 func (s *RealtimeUsageService) SessionDurationMinutes() int {
@@ -94,6 +109,7 @@ func (s *RealtimeUsageService) EnsureSessionStarted(userID, sessionID uint) erro
 	entry := &models.RealtimeUsageLog{
 		UserID:             userID,
 		InterviewSessionID: sessionID,
+		Model:              RealtimeModel(),
 		Status:             "active",
 		StartedAt:          time.Now().UTC(),
 	}
@@ -116,7 +132,13 @@ func (s *RealtimeUsageService) CloseSession(sessionID uint, endedAt time.Time) (
 	if dur < 0 {
 		dur = 0
 	}
-	cost := (float64(dur) / 60.0) * s.ratePerMinuteUSD
+	rate := s.RatePerMinute(entry.StartedAt)
+	if entry.Model != "" {
+		if r, ok := s.pricing.PerMinuteRate(entry.Model, entry.StartedAt); ok {
+			rate = r
+		}
+	}
+	cost := (float64(dur) / 60.0) * rate
 	entry.EndedAt = &endedAt
 	entry.DurationSeconds = int(dur)
 	entry.CostUSD = cost
@@ -129,7 +151,7 @@ func (s *RealtimeUsageService) CloseSession(sessionID uint, endedAt time.Time) (
 }
 
 func (s *RealtimeUsageService) CurrentMonthTotalCost() (float64, error) {
-	return s.repo.CurrentMonthTotalCostEstimated(s.ratePerMinuteUSD)
+	return s.repo.CurrentMonthTotalCostEstimated(s.RatePerMinute(time.Now()))
 }
 
 func (s *RealtimeUsageService) CurrentActiveCount() (int64, error) {
@@ -137,7 +159,7 @@ func (s *RealtimeUsageService) CurrentActiveCount() (int64, error) {
 }
 
 func (s *RealtimeUsageService) GetDailyUsage(nDays int) ([]RealtimeDailySummary, error) {
-	rows, err := s.repo.DailyUsage(nDays, s.ratePerMinuteUSD)
+	rows, err := s.repo.DailyUsage(nDays, s.RatePerMinute(time.Now()))
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +177,7 @@ func (s *RealtimeUsageService) GetDailyUsage(nDays int) ([]RealtimeDailySummary,
 }
 
 func (s *RealtimeUsageService) GetMonthlyUsage(nMonths int) ([]RealtimeMonthlySummary, error) {
-	rows, err := s.repo.MonthlyUsage(nMonths, s.ratePerMinuteUSD)
+	rows, err := s.repo.MonthlyUsage(nMonths, s.RatePerMinute(time.Now()))
 	if err != nil {
 		return nil, err
 	}
@@ -177,7 +199,7 @@ func (s *RealtimeUsageService) GetUserBreakdown(days int, limit int) ([]Realtime
 		days = 30
 	}
 	since := time.Now().UTC().AddDate(0, 0, -days)
-	rows, err := s.repo.UserBreakdown(since, s.ratePerMinuteUSD, limit)
+	rows, err := s.repo.UserBreakdown(since, s.RatePerMinute(time.Now()), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -1,9 +1,10 @@
 package services
 
 import (
+	"Backend/domain/ai"
 	"Backend/domain/repository"
 	"Backend/internal/models"
-	"Backend/internal/openai"
+	"Backend/internal/services/prompts"
 	"bufio"
 	"bytes"
 	"context"
@@ -29,12 +30,15 @@ type ValidationError struct {
 func (e *ValidationError) Error() string { return e.Message }
 
 type ResumeService struct {
-	repo         repository.ResumeRepository
-	storageDir   string
-	aiClient     *openai.Client
-	s3           *s3Storage
-	s3Err        error
-	crossFeature *CrossFeatureIntegrationService
+	repo           repository.ResumeRepository
+	storageDir     string
+	aiClient       ai.LLMClient
+	s3             *s3Storage
+	s3Err          error
+	crossFeature   *CrossFeatureIntegrationService
+	promptRegistry *prompts.Registry
+	inputGuard     *InputGuard
+	piiRedactor    *PIIRedactor
 }
 
 // SetCrossFeatureService 機能間連携サービスを注入する（オプション）
@@ -42,7 +46,22 @@ func (s *ResumeService) SetCrossFeatureService(cf *CrossFeatureIntegrationServic
 	s.crossFeature = cf
 }
 
-func NewResumeService(repo repository.ResumeRepository, storageDir string, aiClient *openai.Client) *ResumeService {
+// SetInputGuard 履歴書のテキストを LLM に渡す前の入力ガードを注入する（未設定なら判定しない）
+func (s *ResumeService) SetInputGuard(guard *InputGuard) {
+	s.inputGuard = guard
+}
+
+// SetPIIRedactor RAG サービスに送る履歴書のテキストの個人情報の置き換えを注入する（未設定なら置き換えない）
+func (s *ResumeService) SetPIIRedactor(redactor *PIIRedactor) {
+	s.piiRedactor = redactor
+}
+
+// SetPromptRegistry プロンプトレジストリを注入する（未設定ならファイルの既定版を使う）
+func (s *ResumeService) SetPromptRegistry(registry *prompts.Registry) {
+	s.promptRegistry = registry
+}
+
+func NewResumeService(repo repository.ResumeRepository, storageDir string, aiClient ai.LLMClient) *ResumeService {
 	if strings.TrimSpace(storageDir) == "" {
 		storageDir = "storage/resumes"
 	}
@@ -126,8 +145,9 @@ func (s *ResumeService) Upload(userID uint, sessionID, sourceType, sourceURL str
 	return &ResumeUploadResult{Document: doc}, nil
 }
 
-func (s *ResumeService) ReviewDocument(documentID uint, companyName string, jobTitle string, candidateType string) (*models.ResumeReview, []models.ResumeReviewItem, error) {
-	doc, err := s.repo.FindDocumentByID(documentID)
+func (s *ResumeService) ReviewDocument(ctx context.Context, userID, documentID uint, companyName string, jobTitle string, candidateType string) (*models.ResumeReview, []models.ResumeReviewItem, error) {
+	ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: userID, Feature: ai.FeatureResumeReview})
+	doc, err := s.findOwnedDocument(userID, documentID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -172,7 +192,7 @@ func (s *ResumeService) ReviewDocument(documentID uint, companyName string, jobT
 		return nil, nil, err
 	}
 
-	review, items, err := s.buildResumeReviewWithAI(blocks, companyName, jobTitle, candidateType)
+	review, items, err := s.ReviewTextBlocks(ctx, blocks, companyName, jobTitle, candidateType)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -216,8 +236,8 @@ type AnnotatedFile struct {
 	CloseFunc   func() error
 }
 
-func (s *ResumeService) OpenAnnotatedFile(documentID uint) (*AnnotatedFile, error) {
-	doc, err := s.repo.FindDocumentByID(documentID)
+func (s *ResumeService) OpenAnnotatedFile(userID, documentID uint) (*AnnotatedFile, error) {
+	doc, err := s.findOwnedDocument(userID, documentID)
 	if err != nil {
 		return nil, err
 	}
@@ -438,6 +458,18 @@ func decodeBBoxInfo(raw string) bboxInfo {
 	return bboxInfo{}
 }
 
+// findOwnedDocument 呼び出し元ユーザーが所有するドキュメントのみを返す
+func (s *ResumeService) findOwnedDocument(userID, documentID uint) (*models.ResumeDocument, error) {
+	doc, err := s.repo.FindDocumentByID(documentID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.UserID != userID {
+		return nil, errors.New("forbidden")
+	}
+	return doc, nil
+}
+
 func (s *ResumeService) ensureS3Available() error {
 	if s.s3Err != nil {
 		return s.s3Err
@@ -614,7 +646,7 @@ func downloadSourceFile(url, storagePath string) (string, string, error) {
 }
 
 type aiReviewResponse struct {
-	Score          int            `json:"score"`
+	Score          int            `json:"score" minimum:"0" maximum:"100"`
 	Summary        string         `json:"summary"`
 	CompanySummary string         `json:"company_summary,omitempty"`
 	Items          []aiReviewItem `json:"items"`
@@ -624,7 +656,7 @@ type aiReviewItem struct {
 	Quote      string `json:"quote"`
 	Message    string `json:"message"`
 	Suggestion string `json:"suggestion"`
-	Severity   string `json:"severity"`
+	Severity   string `json:"severity" enum:"info,warning,critical"`
 	PageHint   int    `json:"page_hint,omitempty"`
 	BlockIndex int    `json:"block_index,omitempty"`
 }
@@ -730,7 +762,8 @@ func (s *ResumeService) fetchRAGReportStream(ctx context.Context, resumeText, co
 
 // ReviewDocumentStream はドキュメントを前処理した後、SSEでRAGレポートをストリーミングし、
 // 最後にスコア・指摘事項を complete イベントとして送信する。
-func (s *ResumeService) ReviewDocumentStream(ctx context.Context, documentID uint, companyName, jobTitle, candidateType string, w http.ResponseWriter) error {
+func (s *ResumeService) ReviewDocumentStream(ctx context.Context, userID, documentID uint, companyName, jobTitle, candidateType string, w http.ResponseWriter) error {
+	ctx = ai.WithCallContext(ctx, ai.CallContext{UserID: userID, Feature: ai.FeatureResumeReview})
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		return fmt.Errorf("streaming not supported")
@@ -742,7 +775,7 @@ func (s *ResumeService) ReviewDocumentStream(ctx context.Context, documentID uin
 		flusher.Flush()
 	}
 
-	doc, err := s.repo.FindDocumentByID(documentID)
+	doc, err := s.findOwnedDocument(userID, documentID)
 	if err != nil {
 		sendEvent(map[string]interface{}{"type": "error", "message": err.Error()})
 		return err
@@ -791,15 +824,22 @@ func (s *ResumeService) ReviewDocumentStream(ctx context.Context, documentID uin
 		return errors.New(msg)
 	}
 	_ = s.repo.ReplaceTextBlocks(doc.ID, blocks)
+	blocks, err = s.guardTextBlocks(ctx, blocks)
+	if err != nil {
+		sendEvent(map[string]interface{}{"type": "error", "message": InputRejectedMessage})
+		return err
+	}
 
 	text := buildResumeText(blocks, 30000)
 
 	// RAGレポートをストリーミングしつつ全文を収集する
 	var ragReport string
 	if strings.TrimSpace(companyName) != "" {
-		ragBody, ragErr := s.fetchRAGReportStream(ctx, text, companyName, jobTitle)
+		// RAG サービスは LLM に履歴書を渡すため、個人情報を置き換えて送りレポートで元に戻す
+		ragText, redaction := s.piiRedactor.Redact(ctx, text)
+		ragBody, ragErr := s.fetchRAGReportStream(ctx, ragText[0], companyName, jobTitle)
 		if ragErr == nil {
-			ragReport, _ = relaySSEChunks(ragBody, w, flusher)
+			ragReport, _ = relaySSEChunks(ragBody, w, flusher, redaction)
 			ragBody.Close()
 		} else {
 			log.Printf("resume_review_stream: rag stream failed: %v", ragErr)
@@ -807,12 +847,14 @@ func (s *ResumeService) ReviewDocumentStream(ctx context.Context, documentID uin
 	}
 
 	// スコア・指摘事項を生成
-	review, items, err := s.buildReviewScoreItems(blocks, companyName, jobTitle, candidateType, ragReport)
+	review, items, err := s.buildReviewScoreItems(ctx, blocks, companyName, jobTitle, candidateType, ragReport)
 	if err != nil {
 		log.Printf("resume_review_stream: build score failed: %v", err)
 		var ve *ValidationError
 		if errors.As(err, &ve) {
 			sendEvent(map[string]interface{}{"type": "error", "message": ve.Message})
+		} else if errors.Is(err, ai.ErrBudgetExceeded) {
+			sendEvent(map[string]interface{}{"type": "error", "message": LLMBudgetExceededMessage})
 		} else {
 			sendEvent(map[string]interface{}{"type": "error", "message": err.Error()})
 		}
@@ -829,8 +871,15 @@ func (s *ResumeService) ReviewDocumentStream(ctx context.Context, documentID uin
 
 // relaySSEChunks はFastAPIからのSSEストリームを読み取り、chunk イベントをそのまま
 // クライアントに転送しつつ、全テキストを返す。
-func relaySSEChunks(body io.Reader, w io.Writer, flusher http.Flusher) (string, error) {
+// redaction があれば記号を元の値に戻した chunk を送る（chunk の境目で分かれた記号は次の chunk とまとめて送る）。
+func relaySSEChunks(body io.Reader, w io.Writer, flusher http.Flusher, redaction *ai.Redaction) (string, error) {
 	var accum strings.Builder
+	restorer := ai.NewStreamRestorer(redaction, func(text string) {
+		accum.WriteString(text)
+		data, _ := json.Marshal(map[string]string{"type": "chunk", "text": text})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	})
 	scanner := bufio.NewScanner(body)
 	scanner.Buffer(make([]byte, 64*1024), 64*1024)
 
@@ -852,19 +901,57 @@ func relaySSEChunks(body io.Reader, w io.Writer, flusher http.Flusher) (string,
 
 		switch event.Type {
 		case "chunk":
+			if redaction.Len() > 0 {
+				restorer.Write(event.Text)
+				continue
+			}
 			accum.WriteString(event.Text)
 			fmt.Fprintf(w, "data: %s\n\n", payload)
 			flusher.Flush()
 		case "done":
+			restorer.Flush()
 			return accum.String(), nil
 		case "error":
+			restorer.Flush()
 			return accum.String(), fmt.Errorf("rag stream error: %s", event.Message)
 		}
 	}
+	restorer.Flush()
 	return accum.String(), scanner.Err()
 }
 
-func (s *ResumeService) buildResumeReviewWithAI(blocks []models.ResumeTextBlock, companyName string, jobTitle string, candidateType string) (*models.ResumeReview, []models.ResumeReviewItem, error) {
+// guardTextBlocks は抽出したテキスト全体を入力ガードで判定し、AI に渡すブロックを返す。
+// 保存済みのブロック（注釈の位置に使う）は変えず、除去の方針ならブロックごとに該当箇所を取り除いた複製を返す
+func (s *ResumeService) guardTextBlocks(ctx context.Context, blocks []models.ResumeTextBlock) ([]models.ResumeTextBlock, error) {
+	texts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		texts = append(texts, b.Text)
+	}
+	guard := s.inputGuard.Check(ctx, ai.FeatureResumeReview, strings.Join(texts, "\n"))
+	if guard.Blocked() {
+		return nil, &ValidationError{Message: InputRejectedMessage}
+	}
+	if guard.Action != GuardSanitize {
+		return blocks, nil
+	}
+	guarded := make([]models.ResumeTextBlock, len(blocks))
+	for i, b := range blocks {
+		b.Text = s.inputGuard.Sanitize(ai.FeatureResumeReview, b.Text)
+		guarded[i] = b
+	}
+	return guarded, nil
+}
+
+// ReviewTextBlocks 抽出済みのテキストブロックを入力ガードにかけ、AI でスコアと指摘事項を生成する（保存はしない）
+func (s *ResumeService) ReviewTextBlocks(ctx context.Context, blocks []models.ResumeTextBlock, companyName, jobTitle, candidateType string) (*models.ResumeReview, []models.ResumeReviewItem, error) {
+	blocks, err := s.guardTextBlocks(ctx, blocks)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.buildResumeReviewWithAI(ctx, blocks, companyName, jobTitle, candidateType)
+}
+
+func (s *ResumeService) buildResumeReviewWithAI(ctx context.Context, blocks []models.ResumeTextBlock, companyName string, jobTitle string, candidateType string) (*models.ResumeReview, []models.ResumeReviewItem, error) {
 	text := buildResumeText(blocks, 30000)
 	if strings.TrimSpace(text) == "" {
 		return nil, nil, &ValidationError{Message: "履歴書からテキストを抽出できませんでした。PDF の画質や形式を確認してください"}
@@ -875,18 +962,19 @@ func (s *ResumeService) buildResumeReviewWithAI(blocks []models.ResumeTextBlock,
 
 	var companyInfo string
 	if strings.TrimSpace(companyName) != "" {
-		if ragReport, err := s.fetchRAGReport(text, companyName, jobTitle); err == nil {
-			companyInfo = ragReport
+		ragText, redaction := s.piiRedactor.Redact(ctx, text)
+		if ragReport, err := s.fetchRAGReport(ragText[0], companyName, jobTitle); err == nil {
+			companyInfo = redaction.Restore(ragReport)
 		} else {
 			log.Printf("resume_review: rag report failed: %v", err)
 		}
 	}
-	return s.buildReviewScoreItems(blocks, companyName, jobTitle, candidateType, companyInfo)
+	return s.buildReviewScoreItems(ctx, blocks, companyName, jobTitle, candidateType, companyInfo)
 }
 
 // buildReviewScoreItems はcompanyInfoを受け取りOpenAIでスコア・指摘事項を生成する。
 // fetchRAGReportStream など外部から取得したRAGレポートを直接渡す場合に使用する。
-func (s *ResumeService) buildReviewScoreItems(blocks []models.ResumeTextBlock, companyName, jobTitle, candidateType, companyInfo string) (*models.ResumeReview, []models.ResumeReviewItem, error) {
+func (s *ResumeService) buildReviewScoreItems(ctx context.Context, blocks []models.ResumeTextBlock, companyName, jobTitle, candidateType, companyInfo string) (*models.ResumeReview, []models.ResumeReviewItem, error) {
 	if s.aiClient == nil {
 		return nil, nil, fmt.Errorf("AIクライアントが初期化されていません")
 	}
@@ -897,7 +985,7 @@ func (s *ResumeService) buildReviewScoreItems(blocks []models.ResumeTextBlock, c
 不確かな情報は断定せず、一般的に言える範囲で述べてください。
 出力は次のJSONのみ:
 {"summary":"200〜300字の企業概要","evaluation_axes":["評価軸1","評価軸2"],"keywords":["キーワード1","キーワード2"]}`, companyName)
-		info, err := s.aiClient.Responses(context.Background(), companyPrompt)
+		info, err := s.aiClient.GenerateText(ctx, "", companyPrompt)
 		if err == nil {
 			companyInfo = info
 		} else {
@@ -905,47 +993,34 @@ func (s *ResumeService) buildReviewScoreItems(blocks []models.ResumeTextBlock, c
 		}
 	}
 
-	prompt := fmt.Sprintf(`以下は履歴書/エントリーシートのOCRテキストです。
-この内容をレビューし、改善すべき点を最大8件までJSONで返してください。
-必ず本文中に存在する短い引用(quote)を入れてください。quoteは後で位置合わせに使います。
-「記載されていません」「未記入」などの欠落指摘は禁止です。本文の内容に基づいた具体的な改善点のみを書いてください。
-page_hintは本文の行頭にある [P#B#] の P# を使ってください。
-block_indexは本文の行頭にある [P#B#] の B# を使ってください。
-各itemsは必ず本文の1ブロックに対応させ、総合的なまとめや全体評価だけの項目は禁止です。
-messageとsuggestionは該当ブロックの内容を引用・要約して具体的に指摘してください。
-suggestionは「どう直すか」が分かるように書いてください（数値・役割・成果・再現性など具体語を含める）。
-
-応募企業名: %s
-応募職種: %s
-企業情報(参考): %s
-候補者区分: %s
-企業名が空欄の場合は一般的な観点でレビューしてください。
-学歴/職歴は明らかな矛盾・不足がある場合のみ指摘し、それ以外は指摘から除外してください。
-企業に合わせた観点（求める人物像・事業領域・評価軸）に照らし、応募書類の内容がどう評価されるかを具体的に指摘してください。
-一般論ではなく、この応募企業に合わせた改善提案を優先してください。
-
-出力は次のJSONのみ:
-{"score":0-100,"summary":"短い要約","items":[{"quote":"本文中の一文","message":"指摘","suggestion":"改善案","severity":"info|warning|critical","page_hint":1,"block_index":1}]}
-
-OCRテキスト:
-%s`, companyName, jobTitle, companyInfo, candidateType, text)
+	prompt, err := s.promptRegistry.Render(ctx, prompts.ResumeReview, prompts.Vars{
+		"CompanyName":   companyName,
+		"JobTitle":      jobTitle,
+		"CompanyInfo":   companyInfo,
+		"CandidateType": candidateType,
+		"Text":          text,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
 
 	modelOverride := strings.TrimSpace(os.Getenv("OPENAI_REVIEW_MODEL"))
 	if modelOverride == "" {
 		modelOverride = "gpt-4o-mini"
 	}
-	raw, err := s.aiClient.ResponsesWithMaxTokens(context.Background(), "あなたは日本語の履歴書・エントリーシートを添削する専門家です。必ず具体的な書き換え案をJSON形式で提示します。", prompt, 0.2, 2000, modelOverride)
+	response, err := ai.GenerateStructured[aiReviewResponse](ctx, s.aiClient, prompt.System, prompt.User, ai.WithTemperature(0.2), ai.WithMaxTokens(2000), ai.WithModel(modelOverride))
+	if errors.Is(err, ai.ErrInvalidStructuredOutput) {
+		log.Printf("resume_review: decode failed: %v", err)
+		return nil, nil, fmt.Errorf("AIレビュー結果の解析に失敗しました。再度お試しください")
+	}
+	if errors.Is(err, ai.ErrBudgetExceeded) {
+		return nil, nil, err
+	}
 	if err != nil {
 		log.Printf("resume_review: openai review failed: %v", err)
 		return nil, nil, fmt.Errorf("AIレビューの生成に失敗しました。しばらく待ってから再度お試しください")
 	}
 
-	response := aiReviewResponse{}
-	if err := decodeJSON(raw, &response); err != nil {
-		log.Printf("resume_review: decode failed: %v", err)
-		return nil, nil, fmt.Errorf("AIレビュー結果の解析に失敗しました。再度お試しください")
-	}
-
 	if response.Score <= 0 {
 		response.Score = 70
 	}
@@ -958,30 +1033,22 @@ OCRテキスト:
 	if len(items) < 3 {
 		blocksForRetry := selectReviewBlocks(blocks, 40)
 		blockList := buildBlockList(blocksForRetry)
-		retryPrompt := fmt.Sprintf(`以下のブロック一覧から、各ブロックに必ず紐づく指摘を最大8件返してください。
-各itemsは必ず block_index と page_hint を含め、quote は block_text の一部をそのまま抜粋してください。
-総合的なまとめや全体評価は不可です。必ずブロック単位で具体的に指摘してください。
- suggestionは具体的な書き換え案にしてください（数値・役割・成果・再現性を含める）。
-
-応募企業名: %s
-応募職種: %s
-企業情報(参考): %s
-候補者区分: %s
-学歴/職歴は明らかな矛盾・不足がある場合のみ指摘し、それ以外は指摘から除外してください。
-
-ブロック一覧:
-%s
-
-出力は次のJSONのみ:
-{"score":0-100,"summary":"短い要約","items":[{"quote":"本文中の一文","message":"指摘","suggestion":"改善案","severity":"info|warning|critical","page_hint":1,"block_index":1}]}`,
-			companyName, jobTitle, companyInfo, candidateType, blockList)
-		rawRetry, err := s.aiClient.ResponsesWithMaxTokens(context.Background(), "あなたは日本語の履歴書・エントリーシートを添削する専門家です。JSON形式で出力してください。", retryPrompt, 0.2, 2000, modelOverride)
+		retryPrompt, err := s.promptRegistry.Render(ctx, prompts.ResumeReviewRetry, prompts.Vars{
+			"CompanyName":   companyName,
+			"JobTitle":      jobTitle,
+			"CompanyInfo":   companyInfo,
+			"CandidateType": candidateType,
+			"BlockList":     blockList,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		responseRetry, err := ai.GenerateStructured[aiReviewResponse](ctx, s.aiClient, retryPrompt.System, retryPrompt.User, ai.WithTemperature(0.2), ai.WithMaxTokens(2000), ai.WithModel(modelOverride))
 		if err == nil {
-			responseRetry := aiReviewResponse{}
-			if decodeJSON(rawRetry, &responseRetry) == nil {
-				items = mapReviewItems(blocks, responseRetry.Items)
-				log.Printf("resume_review: retry items mapped=%d raw=%d", len(items), len(responseRetry.Items))
-			}
+			items = mapReviewItems(blocks, responseRetry.Items)
+			log.Printf("resume_review: retry items mapped=%d raw=%d", len(items), len(responseRetry.Items))
+		} else {
+			log.Printf("resume_review: retry failed: %v", err)
 		}
 	}
 	if len(items) == 0 {
@@ -990,8 +1057,9 @@ OCRテキスト:
 	}
 
 	return &models.ResumeReview{
-		Score:   response.Score,
-		Summary: response.Summary,
+		Score:         response.Score,
+		Summary:       response.Summary,
+		PromptVersion: prompt.Ref(),
 	}, items, nil
 }
 
@@ -1044,22 +1112,6 @@ func buildResumeText(blocks []models.ResumeTextBlock, maxLen int) string {
 	return b.String()
 }
 
-func decodeJSON(raw string, out interface{}) error {
-	raw = strings.TrimSpace(raw)
-	if raw == "" {
-		return errors.New("empty response")
-	}
-	if err := json.Unmarshal([]byte(raw), out); err == nil {
-		return nil
-	}
-	start := strings.Index(raw, "{")
-	end := strings.LastIndex(raw, "}")
-	if start >= 0 && end > start {
-		return json.Unmarshal([]byte(raw[start:end+1]), out)
-	}
-	return errors.New("invalid JSON response")
-}
-
 func mapReviewItems(blocks []models.ResumeTextBlock, aiItems []aiReviewItem) []models.ResumeReviewItem {
 	if len(aiItems) == 0 {
 		return nil
@@ -100,6 +100,48 @@ func (s *s3Storage) getObject(ctx context.Context, key string) (*s3.GetObjectOut
 	})
 }
 
+// ObjectStore 保存済みオブジェクト（s3:// URI）の読み出し・削除
+type ObjectStore interface {
+	Open(ctx context.Context, uri string) (io.ReadCloser, error)
+	Delete(ctx context.Context, uri string) error
+	// URI バケット内のオブジェクトキーを s3:// URI に変換する
+	URI(key string) string
+}
+
+// NewS3ObjectStoreFromEnv AWS_S3_BUCKET のバケットを操作する ObjectStore を返す（未設定の場合は nil）
+func NewS3ObjectStoreFromEnv(ctx context.Context) (ObjectStore, error) {
+	storage, err := newS3StorageFromEnv(ctx)
+	if err != nil || storage == nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
+func (s *s3Storage) Open(ctx context.Context, uri string) (io.ReadCloser, error) {
+	bucket, key, ok := parseS3URI(uri)
+	if !ok {
+		return nil, fmt.Errorf("invalid s3 uri: %s", uri)
+	}
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, uri string) error {
+	bucket, key, ok := parseS3URI(uri)
+	if !ok {
+		return fmt.Errorf("invalid s3 uri: %s", uri)
+	}
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: &bucket, Key: &key})
+	return err
+}
+
+func (s *s3Storage) URI(key string) string {
+	return fmt.Sprintf("s3://%s/%s", s.bucket, key)
+}
+
 func parseS3URI(uri string) (string, string, bool) {
 	if !strings.HasPrefix(uri, "s3://") {
 		return "", "", false
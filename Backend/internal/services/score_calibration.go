@@ -0,0 +1,164 @@
+package services
+
+import (
+	"Backend/internal/models"
+	"Backend/internal/repositories"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrCalibrationVersionNotFound 指定したキャリブレーションの版がない
+var ErrCalibrationVersionNotFound = errors.New("calibration version not found")
+
+// CalibrationWeights キャリブレーションの 1 つの版のカテゴリ別の重み。nil は重みなし（すべて 1.0）として扱える
+type CalibrationWeights struct {
+	Version int
+	Weights map[string]float64
+}
+
+// NewCalibrationWeights は版の重みの行からまとめる（行がなければ nil）
+func NewCalibrationWeights(rows []models.ScoreCalibrationWeight) *CalibrationWeights {
+	if len(rows) == 0 {
+		return nil
+	}
+	c := &CalibrationWeights{Version: rows[0].Version, Weights: make(map[string]float64, len(rows))}
+	for _, row := range rows {
+		c.Weights[row.Category] = row.Weight
+	}
+	return c
+}
+
+// Weight はカテゴリの重みを返す（版にないカテゴリは 1.0）
+func (c *CalibrationWeights) Weight(category string) float64 {
+	if c == nil {
+		return 1
+	}
+	w, ok := c.Weights[category]
+	if !ok {
+		return 1
+	}
+	if w < 0 {
+		return 0
+	}
+	return w
+}
+
+// VersionNumber は版の番号を返す（重みなしは 0）
+func (c *CalibrationWeights) VersionNumber() int {
+	if c == nil {
+		return 0
+	}
+	return c.Version
+}
+
+// CalibrationSource マッチング・分析スコアで使うキャリブレーションの重みの取得元
+type CalibrationSource interface {
+	// ActiveCalibration は有効な版の重みを返す（なければ nil）
+	ActiveCalibration() *CalibrationWeights
+	// ShadowCalibration はシャドーモードで並べて計算する版の重みを返す（なければ nil）
+	ShadowCalibration() *CalibrationWeights
+}
+
+// CalibrationStatus 有効な版とシャドーモードの版
+type CalibrationStatus struct {
+	ActiveVersion int                             `json:"active_version"`
+	ShadowVersion int                             `json:"shadow_version"`
+	Weights       []models.ScoreCalibrationWeight `json:"weights"`
+	ShadowWeights []models.ScoreCalibrationWeight `json:"shadow_weights,omitempty"`
+}
+
+// ActiveCalibration は有効な版の重みを返す（なければ nil）
+func (s *ScoreValidationService) ActiveCalibration() *CalibrationWeights {
+	if s == nil {
+		return nil
+	}
+	rows, err := s.repo.GetLatestCalibrationWeights()
+	if err != nil {
+		return nil
+	}
+	return NewCalibrationWeights(rows)
+}
+
+// ShadowCalibration はシャドーモードで並べて計算する版の重みを返す（なければ nil）
+func (s *ScoreValidationService) ShadowCalibration() *CalibrationWeights {
+	if s == nil {
+		return nil
+	}
+	setting, err := s.repo.GetCalibrationSetting()
+	if err != nil || setting.ShadowVersion == 0 {
+		return nil
+	}
+	rows, err := s.repo.GetCalibrationWeights(setting.ShadowVersion)
+	if err != nil {
+		return nil
+	}
+	return NewCalibrationWeights(rows)
+}
+
+// GetCalibrationStatus は有効な版とシャドーモードの版の重みを返す
+func (s *ScoreValidationService) GetCalibrationStatus() (*CalibrationStatus, error) {
+	weights, err := s.repo.GetLatestCalibrationWeights()
+	if err != nil {
+		return nil, err
+	}
+	setting, err := s.repo.GetCalibrationSetting()
+	if err != nil {
+		return nil, err
+	}
+	status := &CalibrationStatus{Weights: weights, ShadowVersion: setting.ShadowVersion}
+	if len(weights) > 0 {
+		status.ActiveVersion = weights[0].Version
+	}
+	if setting.ShadowVersion != 0 {
+		if status.ShadowWeights, err = s.repo.GetCalibrationWeights(setting.ShadowVersion); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// ActivateCalibration は version の重みをマッチング・分析スコアに使う（0 なら重みなしに戻す）。
+// シャドーモードで比較していた版を有効にしたらシャドー計算は止める
+func (s *ScoreValidationService) ActivateCalibration(version int, actor string) error {
+	err := s.repo.ActivateCalibrationVersion(version)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %d", ErrCalibrationVersionNotFound, version)
+	}
+	if err != nil {
+		return err
+	}
+	setting, err := s.repo.GetCalibrationSetting()
+	if err != nil || version == 0 || setting.ShadowVersion != version {
+		return err
+	}
+	setting.ShadowVersion = 0
+	setting.UpdatedBy = actor
+	return s.repo.SaveCalibrationSetting(setting)
+}
+
+// SetShadowCalibration は version の重みでのマッチ度を有効な版と並べて記録させる（0 ならシャドー計算をやめる）
+func (s *ScoreValidationService) SetShadowCalibration(version int, actor string) error {
+	if version != 0 {
+		rows, err := s.repo.GetCalibrationWeights(version)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return fmt.Errorf("%w: %d", ErrCalibrationVersionNotFound, version)
+		}
+	}
+	setting, err := s.repo.GetCalibrationSetting()
+	if err != nil {
+		return err
+	}
+	setting.ShadowVersion = version
+	setting.UpdatedBy = actor
+	return s.repo.SaveCalibrationSetting(setting)
+}
+
+// GetCalibrationShadowReport はシャドー計算したマッチ度と有効な版のマッチ度を比べる
+func (s *ScoreValidationService) GetCalibrationShadowReport() ([]repositories.CalibrationShadowRow, error) {
+	return s.repo.GetCalibrationShadowReport()
+}
@@ -3,6 +3,10 @@ package services
 import (
 	"Backend/internal/models"
 	"Backend/internal/repositories"
+	"Backend/internal/services/prompts"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -128,7 +132,9 @@ func (s *ScoreValidationService) GetPhasePrecisionReport() (*PhasePrecisionRepor
 // ── A/Bテスト バリアント管理 ─────────────────────────────────────────────────
 
 // CreateVariant 新しい質問バリアントを登録する
-func (s *ScoreValidationService) CreateVariant(experimentName, variantName, description string, trafficRatio float64) (*models.QuestionVariant, error) {
+// promptVersions はバリアントで固定するプロンプト名→版名（例: {"answer_validation": "v3"}）、
+// config は質問プール・フェーズの質問数・スコアリングの変更（nil なら既定の挙動）
+func (s *ScoreValidationService) CreateVariant(experimentName, variantName, description string, trafficRatio float64, promptVersions map[string]string, config *VariantConfig) (*models.QuestionVariant, error) {
 	v := &models.QuestionVariant{
 		ExperimentName: experimentName,
 		VariantName:    variantName,
@@ -136,16 +142,35 @@ func (s *ScoreValidationService) CreateVariant(experimentName, variantName, desc
 		IsActive:       true,
 		TrafficRatio:   trafficRatio,
 	}
+	if len(promptVersions) > 0 {
+		for name, version := range promptVersions {
+			if _, _, _, ok := prompts.Builtin(name); !ok {
+				return nil, fmt.Errorf("%w: %s", prompts.ErrUnknownPrompt, name)
+			}
+			if _, err := prompts.ParseVersionLabel(name, version); err != nil {
+				return nil, err
+			}
+		}
+		pins, _ := json.Marshal(promptVersions)
+		v.PromptVersionsJSON = string(pins)
+	}
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return nil, err
+		}
+		raw, _ := json.Marshal(config)
+		v.ConfigJSON = string(raw)
+	}
 	if err := s.repo.CreateVariant(v); err != nil {
 		return nil, err
 	}
 	return v, nil
 }
 
-// AssignVariant セッションにバリアントをランダム割り当てする
+// AssignVariant セッションにバリアントをランダム割り当てする（実験ごとに 1 つ）
 func (s *ScoreValidationService) AssignVariant(userID uint, sessionID, experimentName string) (*models.VariantAssignment, error) {
 	// 既存割り当て確認
-	existing, err := s.repo.FindAssignment(userID, sessionID)
+	existing, err := s.repo.FindExperimentAssignment(userID, sessionID, experimentName)
 	if err == nil {
 		return existing, nil
 	}
@@ -155,8 +180,12 @@ func (s *ScoreValidationService) AssignVariant(userID uint, sessionID, experimen
 		return nil, fmt.Errorf("アクティブなバリアントが見つかりません: %s", experimentName)
 	}
 
-	// traffic_ratio に基づく重み付きランダム選択
-	r := rand.Float64()
+	// traffic_ratio に基づく重み付きランダム選択（合計が 1 でなくても比率として扱う）
+	total := 0.0
+	for _, v := range variants {
+		total += v.TrafficRatio
+	}
+	r := rand.Float64() * total
 	cumulative := 0.0
 	selected := variants[0]
 	for _, v := range variants {
@@ -174,12 +203,57 @@ func (s *ScoreValidationService) AssignVariant(userID uint, sessionID, experimen
 		ExperimentName:  experimentName,
 		AssignedVariant: selected.VariantName,
 	}
-	if err := s.repo.AssignVariant(assignment); err != nil {
+	created, err := s.repo.AssignVariant(assignment)
+	if err != nil {
 		return nil, err
 	}
+	if !created {
+		// 同じセッションの並行したリクエストが先に割り当てた場合はその割り当てに揃える
+		return s.repo.FindExperimentAssignment(userID, sessionID, experimentName)
+	}
+	assignment.Variant = &selected
 	return assignment, nil
 }
 
+// AssignActiveExperiments 実施中のすべての実験でセッションにバリアントを割り当てる（割り当て済みの実験はそのまま）
+func (s *ScoreValidationService) AssignActiveExperiments(userID uint, sessionID string) ([]models.VariantAssignment, error) {
+	if s == nil {
+		return nil, nil
+	}
+	experiments, err := s.repo.ListActiveExperiments()
+	if err != nil {
+		return nil, fmt.Errorf("実験一覧の取得エラー: %w", err)
+	}
+	assignments := make([]models.VariantAssignment, 0, len(experiments))
+	var errs []error
+	for _, experiment := range experiments {
+		assignment, err := s.AssignVariant(userID, sessionID, experiment)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		assignments = append(assignments, *assignment)
+	}
+	return assignments, errors.Join(errs...)
+}
+
+// SessionVariants セッションに割り当てたバリアントの設定を返す（割り当てがなければ nil）
+func (s *ScoreValidationService) SessionVariants(userID uint, sessionID string) *SessionVariants {
+	if s == nil {
+		return nil
+	}
+	assignments, err := s.repo.ListAssignments(userID, sessionID)
+	if err != nil {
+		return nil
+	}
+	return NewSessionVariants(assignments)
+}
+
+// PromptContext セッションに割り当てたバリアントがプロンプトの版を固定していれば ctx に載せる
+func (s *ScoreValidationService) PromptContext(ctx context.Context, userID uint, sessionID string) context.Context {
+	return s.SessionVariants(userID, sessionID).PromptContext(ctx)
+}
+
 // GetVariantResults 実験バリアント別の通過率レポートを返す
 func (s *ScoreValidationService) GetVariantResults(experimentName string) ([]repositories.VariantResultRow, error) {
 	return s.repo.GetVariantResults(experimentName)
@@ -199,7 +273,8 @@ type CalibrationResult struct {
 	Message  string                        `json:"message"`
 }
 
-// RunCalibration 実績データからスコア重みを再計算して保存する
+// RunCalibration 実績データからスコア重みを再計算して新しい版として保存する
+// 保存した版はすぐには使わない。SetShadowCalibration で重みなし・現行の版と並べて比べてから ActivateCalibration で有効にする
 func (s *ScoreValidationService) RunCalibration() (*CalibrationResult, error) {
 	stats, err := s.repo.GetCategoryPassStats()
 	if err != nil {
@@ -240,7 +315,7 @@ func (s *ScoreValidationService) RunCalibration() (*CalibrationResult, error) {
 			SampleCount: stat.SampleN,
 			PassRate:    math.Round(stat.PassRate*10) / 10,
 			Correlation: math.Round(correlation*100) / 100,
-			IsActive:    true,
+			IsActive:    false,
 		})
 	}
 
@@ -251,7 +326,7 @@ func (s *ScoreValidationService) RunCalibration() (*CalibrationResult, error) {
 	return &CalibrationResult{
 		Version:  nextVersion,
 		Weights:  weights,
-		Message:  fmt.Sprintf("キャリブレーション完了: %d カテゴリ、サンプル合計 %d 件", len(weights), totalSampleCount(stats)),
+		Message:  fmt.Sprintf("キャリブレーション完了: %d カテゴリ、サンプル合計 %d 件（版 %d は未適用。シャドーモードで比較してから有効化してください）", len(weights), totalSampleCount(stats), nextVersion),
 	}, nil
 }
 
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"Backend/domain/ai"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSemanticSearchUnavailable 埋め込みを作れないため意味検索ができない（LLM 未設定・障害）
+	ErrSemanticSearchUnavailable = errors.New("semantic search unavailable")
+	// ErrSemanticReindexRunning 埋め込みの再作成が実行中
+	ErrSemanticReindexRunning = errors.New("semantic reindex already running")
+)
+
+const (
+	// semanticQueryMaxRunes 検索文の上限（超えた分は切り詰める）
+	semanticQueryMaxRunes = 500
+	// semanticQueryCacheTTL 同じ検索文の埋め込みはキャッシュで省く
+	semanticQueryCacheTTL = 24 * time.Hour
+	// semanticReindexBatchSize 再作成で 1 回に読み込む企業の数
+	semanticReindexBatchSize = 100
+)
+
+// SemanticJobPositionMatch 検索文に近かった募集職種
+type SemanticJobPositionMatch struct {
+	ID    uint    `json:"id"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+// SemanticCompanyMatch 意味検索の結果。Score は企業か募集職種のうち検索文に近い方のコサイン類似度
+type SemanticCompanyMatch struct {
+	Company      models.Company             `json:"company"`
+	Score        float64                    `json:"score"`
+	JobPositions []SemanticJobPositionMatch `json:"job_positions,omitempty"`
+}
+
+// SemanticIndexStats 埋め込みの再作成の結果
+type SemanticIndexStats struct {
+	Companies    int `json:"companies"`
+	JobPositions int `json:"job_positions"`
+	Embedded     int `json:"embedded"` // 新たに埋め込みを作った件数（テキストが変わらないものは作らない）
+	Failed       int `json:"failed"`
+}
+
+// SemanticIndexStatus インデックスの件数と直近の再作成の結果
+type SemanticIndexStatus struct {
+	Running             bool                `json:"running"`
+	IndexedCompanies    int                 `json:"indexed_companies"`
+	IndexedJobPositions int                 `json:"indexed_job_positions"`
+	LastRun             *SemanticIndexStats `json:"last_run,omitempty"`
+	LastRunAt           *time.Time          `json:"last_run_at,omitempty"`
+}
+
+// SemanticSearchService 企業・募集職種の埋め込みを作り、学生が書いた希望の文章に近い企業を探す
+type SemanticSearchService struct {
+	llm       ai.LLMClient
+	store     *EmbeddingStore
+	companies repository.CompanyRepository
+
+	mu        sync.Mutex
+	running   bool
+	lastRun   *SemanticIndexStats
+	lastRunAt *time.Time
+}
+
+func NewSemanticSearchService(llm ai.LLMClient, store *EmbeddingStore, companies repository.CompanyRepository) *SemanticSearchService {
+	return &SemanticSearchService{llm: llm, store: store, companies: companies}
+}
+
+// SearchCompanies は query に近い企業を最大 limit 件返す。募集職種が近い企業も含める
+func (s *SemanticSearchService) SearchCompanies(ctx context.Context, query string, limit int) ([]SemanticCompanyMatch, error) {
+	query = trimToMaxChars(strings.TrimSpace(query), semanticQueryMaxRunes)
+	if query == "" || limit <= 0 {
+		return []SemanticCompanyMatch{}, nil
+	}
+	if s.store.Len(EmbeddingKindCompany) == 0 && s.store.Len(EmbeddingKindJobPosition) == 0 {
+		return []SemanticCompanyMatch{}, nil
+	}
+	if s.llm == nil {
+		return nil, ErrSemanticSearchUnavailable
+	}
+
+	ctx = ai.WithCache(ai.WithFeature(ctx, ai.FeatureSemanticSearch), "semantic_search_query", semanticQueryCacheTTL)
+	vec, err := s.llm.GenerateEmbedding(ctx, query)
+	if err != nil {
+		if errors.Is(err, ai.ErrBudgetExceeded) {
+			return nil, err
+		}
+		log.Printf("[SemanticSearch] query embedding failed: %v", err)
+		return nil, ErrSemanticSearchUnavailable
+	}
+
+	scores := map[uint]float64{}
+	for _, hit := range s.store.Search(EmbeddingKindCompany, vec, limit*3) {
+		scores[hit.ID] = hit.Score
+	}
+	positions := map[uint][]SemanticJobPositionMatch{}
+	for _, hit := range s.store.Search(EmbeddingKindJobPosition, vec, limit*5) {
+		position, err := s.companies.FindJobPositionByID(hit.ID)
+		if err != nil || !position.IsActive || position.DataStatus != "published" {
+			continue
+		}
+		positions[position.CompanyID] = append(positions[position.CompanyID], SemanticJobPositionMatch{ID: position.ID, Title: position.Title, Score: hit.Score})
+		if hit.Score > scores[position.CompanyID] {
+			scores[position.CompanyID] = hit.Score
+		}
+	}
+
+	ranked := make([]uint, 0, len(scores))
+	for id := range scores {
+		ranked = append(ranked, id)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i]] != scores[ranked[j]] {
+			return scores[ranked[i]] > scores[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	results := make([]SemanticCompanyMatch, 0, limit)
+	for _, id := range ranked {
+		company, err := s.companies.FindByID(id)
+		if err != nil || !company.IsActive || company.DeletedAt != nil {
+			continue
+		}
+		results = append(results, SemanticCompanyMatch{Company: *company, Score: scores[id], JobPositions: positions[id]})
+		if len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// StartReindex は埋め込みの再作成をバックグラウンドで始める（実行中なら ErrSemanticReindexRunning）
+func (s *SemanticSearchService) StartReindex() error {
+	if s.llm == nil {
+		return ErrSemanticSearchUnavailable
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return ErrSemanticReindexRunning
+	}
+	s.running = true
+	go func() {
+		stats, err := s.Reindex(context.Background())
+		if err != nil {
+			log.Printf("[SemanticSearch] reindex failed: %v", err)
+		}
+		now := time.Now()
+		s.mu.Lock()
+		s.running, s.lastRun, s.lastRunAt = false, &stats, &now
+		s.mu.Unlock()
+	}()
+	return nil
+}
+
+// Status はインデックスの件数と直近の再作成の結果を返す
+func (s *SemanticSearchService) Status() SemanticIndexStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SemanticIndexStatus{
+		Running:             s.running,
+		IndexedCompanies:    s.store.Len(EmbeddingKindCompany),
+		IndexedJobPositions: s.store.Len(EmbeddingKindJobPosition),
+		LastRun:             s.lastRun,
+		LastRunAt:           s.lastRunAt,
+	}
+}
+
+// Reindex はアクティブな企業と公開中の募集職種の埋め込みを作る（テキストが変わらないものは作り直さない）
+func (s *SemanticSearchService) Reindex(ctx context.Context) (SemanticIndexStats, error) {
+	var stats SemanticIndexStats
+	if s.llm == nil {
+		return stats, ErrSemanticSearchUnavailable
+	}
+	ctx = ai.WithFeature(ctx, ai.FeatureSemanticSearch)
+	for offset := 0; ; offset += semanticReindexBatchSize {
+		companies, err := s.companies.FindAllActive(semanticReindexBatchSize, offset)
+		if err != nil {
+			return stats, fmt.Errorf("load companies: %w", err)
+		}
+		for i := range companies {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+			s.indexCompany(ctx, &companies[i], &stats)
+		}
+		if len(companies) < semanticReindexBatchSize {
+			break
+		}
+	}
+	log.Printf("[SemanticSearch] reindexed companies=%d job_positions=%d embedded=%d failed=%d",
+		stats.Companies, stats.JobPositions, stats.Embedded, stats.Failed)
+	return stats, nil
+}
+
+func (s *SemanticSearchService) indexCompany(ctx context.Context, company *models.Company, stats *SemanticIndexStats) {
+	stats.Companies++
+	s.embed(ctx, EmbeddingKindCompany, company.ID, buildCompanyEmbeddingText(company), stats)
+
+	positions, err := s.companies.FindJobPositionsByCompany(company.ID)
+	if err != nil {
+		log.Printf("[SemanticSearch] load job positions for company %d: %v", company.ID, err)
+		stats.Failed++
+		return
+	}
+	for i := range positions {
+		stats.JobPositions++
+		s.embed(ctx, EmbeddingKindJobPosition, positions[i].ID, buildJobPositionEmbeddingText(company, &positions[i]), stats)
+	}
+}
+
+func (s *SemanticSearchService) embed(ctx context.Context, kind string, id uint, text string, stats *SemanticIndexStats) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if stored, ok, err := s.store.SourceText(kind, id); err == nil && ok && stored == text {
+		return
+	}
+	ctxReq, cancel := context.WithTimeout(ctx, 45*time.Second)
+	defer cancel()
+	vec, err := s.llm.GenerateEmbedding(ctxReq, text)
+	if err == nil {
+		err = s.store.Put(kind, id, text, vec)
+	}
+	if err != nil {
+		log.Printf("[SemanticSearch] embed %s %d: %v", kind, id, err)
+		stats.Failed++
+		return
+	}
+	stats.Embedded++
+}
+
+func buildCompanyEmbeddingText(company *models.Company) string {
+	var b strings.Builder
+	b.WriteString("Company\n")
+	writeEmbeddingField(&b, "Name", company.Name)
+	writeEmbeddingField(&b, "Industry", company.Industry)
+	writeEmbeddingField(&b, "Description", company.Description)
+	writeEmbeddingField(&b, "Main business", company.MainBusiness)
+	writeEmbeddingField(&b, "Culture", company.Culture)
+	writeEmbeddingField(&b, "Work style", company.WorkStyle)
+	writeEmbeddingField(&b, "Welfare", company.WelfareDetails)
+	writeEmbeddingField(&b, "Tech stack", company.TechStack)
+	writeEmbeddingField(&b, "Infrastructure", company.InfraStack)
+	writeEmbeddingField(&b, "Development style", company.DevelopmentStyle)
+	writeEmbeddingField(&b, "Location", company.Location)
+	return trimToMaxChars(strings.TrimSpace(b.String()), maxEmbeddingChars)
+}
+
+func buildJobPositionEmbeddingText(company *models.Company, position *models.CompanyJobPosition) string {
+	var b strings.Builder
+	b.WriteString("Job position\n")
+	writeEmbeddingField(&b, "Title", position.Title)
+	writeEmbeddingField(&b, "Company", company.Name)
+	writeEmbeddingField(&b, "Job category", position.JobCategory.Name)
+	writeEmbeddingField(&b, "Description", position.Description)
+	writeEmbeddingField(&b, "Employment type", position.EmploymentType)
+	writeEmbeddingField(&b, "Work location", position.WorkLocation)
+	if position.RemoteOption {
+		writeEmbeddingField(&b, "Remote", "available")
+	}
+	writeEmbeddingField(&b, "Required skills", position.RequiredSkills)
+	writeEmbeddingField(&b, "Preferred skills", position.PreferredSkills)
+	return trimToMaxChars(strings.TrimSpace(b.String()), maxEmbeddingChars)
+}
+
+func writeEmbeddingField(b *strings.Builder, label, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		b.WriteString(label)
+		b.WriteString(": ")
+		b.WriteString(value)
+		b.WriteString("\n")
+	}
+}
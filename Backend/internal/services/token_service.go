@@ -0,0 +1,244 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// TokenTypeAccess は API 呼び出しに使う短命トークン
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh はアクセストークン再発行専用の長命トークン
+	TokenTypeRefresh = "refresh"
+	// TokenTypeMFAChallenge はパスワード認証後、2段階認証コードの入力までを繋ぐ短命トークン
+	TokenTypeMFAChallenge = "mfa_challenge"
+	// TokenTypeOAuthLink はログイン中のユーザーが外部アカウントを連携する際の OAuth state
+	TokenTypeOAuthLink = "oauth_link"
+
+	defaultAccessTokenTTL  = 1 * time.Hour
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	mfaChallengeTTL        = 5 * time.Minute
+	oauthLinkStateTTL      = 10 * time.Minute
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// TokenClaims 署名付きトークンのペイロード
+type TokenClaims struct {
+	UserID    uint   `json:"uid"`
+	Type      string `json:"typ"`
+	MFA       bool   `json:"mfa,omitempty"` // 2段階認証を経たセッションか
+	Provider  string `json:"prv,omitempty"` // 連携先の OAuth プロバイダ（oauth_link のみ）
+	NonceHash string `json:"nch,omitempty"` // 連携を始めたブラウザの Cookie に置いた nonce のハッシュ（oauth_link のみ）
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenPair ログイン時に発行するアクセス・リフレッシュトークンの組
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// TokenService HMAC-SHA256 (JWT HS256 形式) でセッショントークンを発行・検証する
+type TokenService struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+func NewTokenService(secret []byte, accessTTL, refreshTTL time.Duration) *TokenService {
+	if accessTTL <= 0 {
+		accessTTL = defaultAccessTokenTTL
+	}
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+	return &TokenService{
+		secret:     secret,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+// NewTokenServiceFromEnv AUTH_TOKEN_SECRET から署名鍵を読み込む。
+// 本番環境で未設定の場合はエラー、それ以外は起動ごとのランダム鍵で代替する（再起動で全トークン失効）。
+func NewTokenServiceFromEnv() (*TokenService, error) {
+	secret := os.Getenv("AUTH_TOKEN_SECRET")
+	accessTTL := time.Duration(getIntEnv("AUTH_ACCESS_TOKEN_TTL_MINUTES", 0)) * time.Minute
+	refreshTTL := time.Duration(getIntEnv("AUTH_REFRESH_TOKEN_TTL_HOURS", 0)) * time.Hour
+	if secret == "" {
+		if os.Getenv("APP_ENV") == "production" {
+			return nil, errors.New("AUTH_TOKEN_SECRET is required in production")
+		}
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate token secret: %w", err)
+		}
+		log.Println("WARNING: AUTH_TOKEN_SECRET が設定されていません。起動ごとのランダム鍵を使用します（再起動で全セッションが失効します）。")
+		return NewTokenService(b, accessTTL, refreshTTL), nil
+	}
+	if len(secret) < 32 {
+		return nil, errors.New("AUTH_TOKEN_SECRET must be at least 32 bytes")
+	}
+	return NewTokenService([]byte(secret), accessTTL, refreshTTL), nil
+}
+
+// IssuePair ユーザーのアクセス・リフレッシュトークンを発行する
+func (s *TokenService) IssuePair(userID uint) (*TokenPair, error) {
+	return s.issuePair(userID, false)
+}
+
+// IssueMFAPair 2段階認証を経たセッションとしてトークンを発行する
+func (s *TokenService) IssueMFAPair(userID uint) (*TokenPair, error) {
+	return s.issuePair(userID, true)
+}
+
+func (s *TokenService) issuePair(userID uint, mfa bool) (*TokenPair, error) {
+	if userID == 0 {
+		return nil, errors.New("user_id is required")
+	}
+	now := s.now()
+	access, err := s.sign(TokenClaims{UserID: userID, Type: TokenTypeAccess, MFA: mfa, IssuedAt: now.Unix(), ExpiresAt: now.Add(s.accessTTL).Unix()})
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := s.sign(TokenClaims{UserID: userID, Type: TokenTypeRefresh, MFA: mfa, IssuedAt: now.Unix(), ExpiresAt: now.Add(s.refreshTTL).Unix()})
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{AccessToken: access, RefreshToken: refresh, ExpiresAt: now.Add(s.accessTTL)}, nil
+}
+
+// IssueMFAChallenge 2段階認証コードの入力待ちを表す短命トークンを発行する
+func (s *TokenService) IssueMFAChallenge(userID uint) (string, error) {
+	if userID == 0 {
+		return "", errors.New("user_id is required")
+	}
+	now := s.now()
+	return s.sign(TokenClaims{UserID: userID, Type: TokenTypeMFAChallenge, IssuedAt: now.Unix(), ExpiresAt: now.Add(mfaChallengeTTL).Unix()})
+}
+
+// VerifyMFAChallenge 2段階認証の入力待ちトークンを検証してクレームを返す
+func (s *TokenService) VerifyMFAChallenge(token string) (*TokenClaims, error) {
+	return s.verify(token, TokenTypeMFAChallenge)
+}
+
+// IssueOAuthLinkState 外部アカウント連携の OAuth state を発行する。
+// 連携先ユーザー・プロバイダと、連携を始めたブラウザの Cookie に置く nonce のハッシュを署名で固定する
+func (s *TokenService) IssueOAuthLinkState(userID uint, provider, nonce string) (string, error) {
+	if userID == 0 {
+		return "", errors.New("user_id is required")
+	}
+	if nonce == "" {
+		return "", errors.New("nonce is required")
+	}
+	now := s.now()
+	return s.sign(TokenClaims{UserID: userID, Type: TokenTypeOAuthLink, Provider: provider, NonceHash: hashOAuthNonce(nonce), IssuedAt: now.Unix(), ExpiresAt: now.Add(oauthLinkStateTTL).Unix()})
+}
+
+// VerifyOAuthLinkState 外部アカウント連携の OAuth state を検証してクレームを返す。
+// nonce はコールバックを受けたブラウザの Cookie の値で、連携を始めたブラウザと違えば ErrInvalidToken を返す
+func (s *TokenService) VerifyOAuthLinkState(state, provider, nonce string) (*TokenClaims, error) {
+	claims, err := s.verify(state, TokenTypeOAuthLink)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Provider != provider {
+		return nil, ErrInvalidToken
+	}
+	if nonce == "" || !hmac.Equal([]byte(claims.NonceHash), []byte(hashOAuthNonce(nonce))) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func hashOAuthNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifyAccessToken アクセストークンを検証してクレームを返す
+func (s *TokenService) VerifyAccessToken(token string) (*TokenClaims, error) {
+	return s.verify(token, TokenTypeAccess)
+}
+
+// VerifyRefreshToken リフレッシュトークンを検証してクレームを返す
+func (s *TokenService) VerifyRefreshToken(token string) (*TokenClaims, error) {
+	return s.verify(token, TokenTypeRefresh)
+}
+
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+func (s *TokenService) sign(claims TokenClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+	signingInput := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(s.mac(signingInput)), nil
+}
+
+func (s *TokenService) verify(token, expectedType string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return nil, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, s.mac(parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Type != expectedType || claims.UserID == 0 {
+		return nil, ErrInvalidToken
+	}
+	if s.now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+func (s *TokenService) mac(input string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(input))
+	return h.Sum(nil)
+}
+
+// attachTokens 認証レスポンスにトークンを付与する（TokenService 未設定時は何もしない）。
+// mfa は 2段階認証を経たセッションかどうか
+func attachTokens(tokens *TokenService, resp *AuthResponse, mfa bool) error {
+	if tokens == nil || resp == nil {
+		return nil
+	}
+	pair, err := tokens.issuePair(resp.UserID, mfa)
+	if err != nil {
+		return fmt.Errorf("failed to issue tokens: %w", err)
+	}
+	resp.Token = pair.AccessToken
+	resp.RefreshToken = pair.RefreshToken
+	expiresAt := pair.ExpiresAt
+	resp.TokenExpiresAt = &expiresAt
+	return nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"Backend/internal/models"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidWeightRule 加点ルールの条件・加点が不正
+var ErrInvalidWeightRule = errors.New("invalid weight rule")
+
+// maxWeightBoost 1 つのルールで加減できる点数の上限
+const maxWeightBoost = 100
+
+// WeightRuleCondition 加点ルールの条件（WeightRule.Condition の JSON）。設定した条件をすべて満たす回答でルールが発火する
+type WeightRuleCondition struct {
+	Keywords       []string `json:"keywords,omitempty"`         // 回答にいずれかを含む（大文字・小文字は区別しない）
+	Categories     []string `json:"categories,omitempty"`       // 採点するカテゴリがいずれか
+	Phase          string   `json:"phase,omitempty"`            // 分析フェーズ名（例: "aptitude_analysis"）
+	Phases         []string `json:"phases,omitempty"`           // 分析フェーズ名のいずれか
+	TargetLevel    string   `json:"target_level,omitempty"`     // 利用者の対象（"新卒" / "中途"）
+	IndustryIDs    []uint   `json:"industry_ids,omitempty"`     // セッションの業界がいずれか
+	JobCategoryIDs []uint   `json:"job_category_ids,omitempty"` // セッションの職種がいずれか
+	MinAnswerScore *int     `json:"min_answer_score,omitempty"` // 加点前の回答のスコアがこれ以上
+	MaxAnswerScore *int     `json:"max_answer_score,omitempty"` // 加点前の回答のスコアがこれ以下
+}
+
+// ParseWeightRuleCondition は条件の JSON を読み、値を確かめる（知らない項目は誤記として受け付けない）
+func ParseWeightRuleCondition(raw string) (*WeightRuleCondition, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	dec.DisallowUnknownFields()
+	var c WeightRuleCondition
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%w: condition: %v", ErrInvalidWeightRule, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c *WeightRuleCondition) validate() error {
+	if len(c.Keywords) == 0 && len(c.Categories) == 0 && c.Phase == "" && len(c.Phases) == 0 &&
+		c.TargetLevel == "" && len(c.IndustryIDs) == 0 && len(c.JobCategoryIDs) == 0 &&
+		c.MinAnswerScore == nil && c.MaxAnswerScore == nil {
+		return fmt.Errorf("%w: condition has no criteria", ErrInvalidWeightRule)
+	}
+	for _, keyword := range c.Keywords {
+		if strings.TrimSpace(keyword) == "" {
+			return fmt.Errorf("%w: keywords must not be empty", ErrInvalidWeightRule)
+		}
+	}
+	for _, score := range []*int{c.MinAnswerScore, c.MaxAnswerScore} {
+		if score != nil && (*score < 0 || *score > 100) {
+			return fmt.Errorf("%w: answer score bounds must be between 0 and 100", ErrInvalidWeightRule)
+		}
+	}
+	if c.MinAnswerScore != nil && c.MaxAnswerScore != nil && *c.MinAnswerScore > *c.MaxAnswerScore {
+		return fmt.Errorf("%w: min_answer_score is greater than max_answer_score", ErrInvalidWeightRule)
+	}
+	return nil
+}
+
+// ValidateWeightRule はルールの名前・加点・条件を確かめる
+func ValidateWeightRule(rule models.WeightRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidWeightRule)
+	}
+	if rule.WeightBoost < -maxWeightBoost || rule.WeightBoost > maxWeightBoost {
+		return fmt.Errorf("%w: weight_boost must be between -%d and %d", ErrInvalidWeightRule, maxWeightBoost, maxWeightBoost)
+	}
+	_, err := ParseWeightRuleCondition(rule.Condition)
+	return err
+}
+
+// WeightRuleFacts ルールの判定に使う回答・利用者・フェーズの情報
+type WeightRuleFacts struct {
+	Answer        string
+	Category      string // 採点するカテゴリ
+	Phase         string
+	TargetLevel   string
+	IndustryID    uint
+	JobCategoryID uint
+	AnswerScore   int // 加点前の回答のスコア（0〜100）
+}
+
+// FiredWeightRule 回答で発火したルール
+type FiredWeightRule struct {
+	RuleID          uint     `json:"rule_id"`
+	Name            string   `json:"name"`
+	Priority        int      `json:"priority"`
+	WeightBoost     int      `json:"weight_boost"`
+	MatchedKeywords []string `json:"matched_keywords,omitempty"`
+}
+
+// InvalidWeightRule 条件を読めず評価から外したルール
+type InvalidWeightRule struct {
+	RuleID uint   `json:"rule_id"`
+	Name   string `json:"name"`
+	Error  string `json:"error"`
+}
+
+type compiledWeightRule struct {
+	rule      models.WeightRule
+	condition *WeightRuleCondition
+}
+
+// WeightRuleEngine 有効な加点ルールを優先度の高い順に評価する。nil はルールなしとして扱える
+type WeightRuleEngine struct {
+	rules   []compiledWeightRule
+	invalid []InvalidWeightRule
+}
+
+// NewWeightRuleEngine は有効なルールの条件を読んで評価できる形にする（条件を読めないルールは外して記録する）
+func NewWeightRuleEngine(rules []models.WeightRule) *WeightRuleEngine {
+	e := &WeightRuleEngine{}
+	for _, rule := range rules {
+		if !rule.IsActive {
+			continue
+		}
+		if err := ValidateWeightRule(rule); err != nil {
+			log.Printf("[WeightRule] rule %d (%s) skipped: %v", rule.ID, rule.Name, err)
+			e.invalid = append(e.invalid, InvalidWeightRule{RuleID: rule.ID, Name: rule.Name, Error: err.Error()})
+			continue
+		}
+		condition, _ := ParseWeightRuleCondition(rule.Condition)
+		e.rules = append(e.rules, compiledWeightRule{rule: rule, condition: condition})
+	}
+	sort.SliceStable(e.rules, func(i, j int) bool {
+		if e.rules[i].rule.Priority != e.rules[j].rule.Priority {
+			return e.rules[i].rule.Priority > e.rules[j].rule.Priority
+		}
+		return e.rules[i].rule.ID < e.rules[j].rule.ID
+	})
+	return e
+}
+
+// Len は評価するルールの数を返す
+func (e *WeightRuleEngine) Len() int {
+	if e == nil {
+		return 0
+	}
+	return len(e.rules)
+}
+
+// InvalidRules は条件を読めず評価から外したルールを返す
+func (e *WeightRuleEngine) InvalidRules() []InvalidWeightRule {
+	if e == nil {
+		return nil
+	}
+	return e.invalid
+}
+
+// Evaluate は回答で発火するルールを優先度の高い順に返す
+func (e *WeightRuleEngine) Evaluate(facts WeightRuleFacts) []FiredWeightRule {
+	if e == nil {
+		return nil
+	}
+	var fired []FiredWeightRule
+	for _, r := range e.rules {
+		matched, keywords := r.condition.match(facts)
+		if !matched {
+			continue
+		}
+		fired = append(fired, FiredWeightRule{
+			RuleID:          r.rule.ID,
+			Name:            r.rule.Name,
+			Priority:        r.rule.Priority,
+			WeightBoost:     r.rule.WeightBoost,
+			MatchedKeywords: keywords,
+		})
+	}
+	return fired
+}
+
+// Apply は発火したルールの加点を回答のスコアに足す（0〜100 に収める）
+func (e *WeightRuleEngine) Apply(facts WeightRuleFacts) (int, []FiredWeightRule) {
+	fired := e.Evaluate(facts)
+	score := facts.AnswerScore
+	for _, f := range fired {
+		score += f.WeightBoost
+	}
+	if score > 100 {
+		score = 100
+	}
+	if score < 0 {
+		score = 0
+	}
+	return score, fired
+}
+
+func (c *WeightRuleCondition) match(facts WeightRuleFacts) (bool, []string) {
+	if len(c.Categories) > 0 && !containsString(c.Categories, facts.Category) {
+		return false, nil
+	}
+	if c.Phase != "" && c.Phase != facts.Phase {
+		return false, nil
+	}
+	if len(c.Phases) > 0 && !containsString(c.Phases, facts.Phase) {
+		return false, nil
+	}
+	if c.TargetLevel != "" && c.TargetLevel != facts.TargetLevel {
+		return false, nil
+	}
+	if len(c.IndustryIDs) > 0 && !containsUint(c.IndustryIDs, facts.IndustryID) {
+		return false, nil
+	}
+	if len(c.JobCategoryIDs) > 0 && !containsUint(c.JobCategoryIDs, facts.JobCategoryID) {
+		return false, nil
+	}
+	if c.MinAnswerScore != nil && facts.AnswerScore < *c.MinAnswerScore {
+		return false, nil
+	}
+	if c.MaxAnswerScore != nil && facts.AnswerScore > *c.MaxAnswerScore {
+		return false, nil
+	}
+	if len(c.Keywords) == 0 {
+		return true, nil
+	}
+	answer := strings.ToLower(facts.Answer)
+	var matched []string
+	for _, keyword := range c.Keywords {
+		if strings.Contains(answer, strings.ToLower(keyword)) {
+			matched = append(matched, keyword)
+		}
+	}
+	return len(matched) > 0, matched
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsUint(values []uint, v uint) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
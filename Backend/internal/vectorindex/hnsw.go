@@ -0,0 +1,360 @@
+// Package vectorindex は埋め込みベクトルのメモリ上の近似最近傍（ANN）インデックスを提供する。
+// 類似度はコサイン類似度で、登録時に正規化したベクトルの内積で計算する。
+package vectorindex
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// ErrDimensionMismatch 登録済みのベクトルと次元数が異なる
+var ErrDimensionMismatch = errors.New("vector dimension mismatch")
+
+// Result 検索結果（Score はコサイン類似度）
+type Result struct {
+	ID    uint
+	Score float64
+}
+
+// Config HNSW の構築・検索のパラメータ（0 なら既定値）
+type Config struct {
+	M              int   // 各層で張るリンクの数（第 0 層はその 2 倍）
+	EfConstruction int   // 登録時に探索する候補の数
+	EfSearch       int   // 検索時に探索する候補の数（k より小さければ k）
+	Seed           int64 // 層の割り当ての乱数の種
+}
+
+func (c Config) withDefaults() Config {
+	if c.M <= 0 {
+		c.M = 16
+	}
+	if c.EfConstruction <= 0 {
+		c.EfConstruction = 200
+	}
+	if c.EfSearch <= 0 {
+		c.EfSearch = 64
+	}
+	if c.Seed == 0 {
+		c.Seed = 1
+	}
+	return c
+}
+
+type node struct {
+	id      uint
+	vec     []float32
+	links   [][]int
+	deleted bool
+}
+
+// HNSW は Hierarchical Navigable Small World グラフによる近似最近傍インデックス。
+// 同じ ID を登録し直すと古いベクトルを置き換える。削除したノードは検索結果から除き、
+// 半分を超えたらグラフを作り直す。並行に使ってよい。
+type HNSW struct {
+	cfg       Config
+	levelMult float64
+
+	mu       sync.RWMutex
+	rng      *rand.Rand
+	nodes    []*node
+	byID     map[uint]int
+	entry    int
+	maxLevel int
+	dim      int
+	deleted  int
+}
+
+func NewHNSW(cfg Config) *HNSW {
+	cfg = cfg.withDefaults()
+	return &HNSW{
+		cfg:       cfg,
+		levelMult: 1 / math.Log(float64(cfg.M)),
+		rng:       rand.New(rand.NewSource(cfg.Seed)),
+		byID:      map[uint]int{},
+		entry:     -1,
+	}
+}
+
+// Len は登録されているベクトルの数を返す
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.byID)
+}
+
+// Add は id のベクトルを登録する（登録済みなら置き換える）
+func (h *HNSW) Add(id uint, vec []float32) error {
+	if len(vec) == 0 {
+		return fmt.Errorf("vectorindex: empty vector for id %d", id)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.dim != 0 && len(vec) != h.dim {
+		return fmt.Errorf("%w: got %d, want %d", ErrDimensionMismatch, len(vec), h.dim)
+	}
+	h.dim = len(vec)
+	if old, ok := h.byID[id]; ok {
+		h.markDeleted(old)
+	}
+	h.insert(id, normalize(vec))
+	h.compactIfNeeded()
+	return nil
+}
+
+// Remove は id のベクトルを取り除く（なければ何もしない）
+func (h *HNSW) Remove(id uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i, ok := h.byID[id]; ok {
+		h.markDeleted(i)
+		h.compactIfNeeded()
+	}
+}
+
+// Search は query に近い順に最大 k 件を返す
+func (h *HNSW) Search(query []float32, k int) []Result {
+	if k <= 0 {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.entry < 0 || len(query) != h.dim {
+		return nil
+	}
+	q := normalize(query)
+	ep := h.entry
+	for level := h.maxLevel; level > 0; level-- {
+		ep = h.greedy(q, ep, level)
+	}
+	ef := h.cfg.EfSearch
+	if ef < k {
+		ef = k
+	}
+	// 削除済みのノードも経路には使うため、その分だけ多めに探す
+	candidates := h.searchLayer(q, ep, ef+h.deleted, 0)
+	results := make([]Result, 0, k)
+	for _, c := range candidates {
+		n := h.nodes[c.index]
+		if n.deleted {
+			continue
+		}
+		results = append(results, Result{ID: n.id, Score: 1 - c.dist})
+		if len(results) == k {
+			break
+		}
+	}
+	return results
+}
+
+func (h *HNSW) insert(id uint, vec []float32) {
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+	n := &node{id: id, vec: vec, links: make([][]int, level+1)}
+	index := len(h.nodes)
+	h.nodes = append(h.nodes, n)
+	h.byID[id] = index
+	if h.entry < 0 {
+		h.entry, h.maxLevel = index, level
+		return
+	}
+
+	ep := h.entry
+	for l := h.maxLevel; l > level; l-- {
+		ep = h.greedy(vec, ep, l)
+	}
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(vec, ep, h.cfg.EfConstruction, l)
+		limit := h.maxLinks(l)
+		for _, c := range candidates {
+			if len(n.links[l]) == limit {
+				break
+			}
+			n.links[l] = append(n.links[l], c.index)
+		}
+		for _, neighbor := range n.links[l] {
+			h.link(neighbor, index, l)
+		}
+		ep = candidates[0].index
+	}
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = index, level
+	}
+}
+
+// link は from から to へのリンクを張り、上限を超えたら近い順に残す
+func (h *HNSW) link(from, to, level int) {
+	n := h.nodes[from]
+	n.links[level] = append(n.links[level], to)
+	limit := h.maxLinks(level)
+	if len(n.links[level]) <= limit {
+		return
+	}
+	sort.Slice(n.links[level], func(i, j int) bool {
+		return h.distance(n.vec, n.links[level][i]) < h.distance(n.vec, n.links[level][j])
+	})
+	n.links[level] = n.links[level][:limit]
+}
+
+func (h *HNSW) maxLinks(level int) int {
+	if level == 0 {
+		return h.cfg.M * 2
+	}
+	return h.cfg.M
+}
+
+// greedy は level の層で q に近い隣へ移れなくなるまで進み、着いたノードを返す
+func (h *HNSW) greedy(q []float32, ep, level int) int {
+	best := h.distance(q, ep)
+	for changed := true; changed; {
+		changed = false
+		for _, next := range h.nodes[ep].links[level] {
+			if d := h.distance(q, next); d < best {
+				best, ep, changed = d, next, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer は level の層で q に近いノードを最大 ef 件、近い順に返す
+func (h *HNSW) searchLayer(q []float32, ep, ef, level int) []candidate {
+	visited := make(map[int]struct{}, ef*4)
+	visited[ep] = struct{}{}
+	start := candidate{index: ep, dist: h.distance(q, ep)}
+	frontier := &minHeap{start}
+	found := &maxHeap{start}
+	for frontier.Len() > 0 {
+		c := heap.Pop(frontier).(candidate)
+		if found.Len() >= ef && c.dist > (*found)[0].dist {
+			break
+		}
+		for _, next := range h.nodes[c.index].links[level] {
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			visited[next] = struct{}{}
+			d := h.distance(q, next)
+			if found.Len() < ef || d < (*found)[0].dist {
+				heap.Push(frontier, candidate{index: next, dist: d})
+				heap.Push(found, candidate{index: next, dist: d})
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+	out := make([]candidate, found.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(found).(candidate)
+	}
+	return out
+}
+
+func (h *HNSW) distance(q []float32, index int) float64 {
+	return 1 - dot(q, h.nodes[index].vec)
+}
+
+func (h *HNSW) markDeleted(index int) {
+	n := h.nodes[index]
+	n.deleted = true
+	delete(h.byID, n.id)
+	h.deleted++
+}
+
+// compactIfNeeded は削除済みのノードが半分を超えたら残りのノードでグラフを作り直す
+func (h *HNSW) compactIfNeeded() {
+	if h.deleted == 0 || h.deleted*2 < len(h.nodes) {
+		return
+	}
+	live := make([]*node, 0, len(h.byID))
+	for _, n := range h.nodes {
+		if !n.deleted {
+			live = append(live, n)
+		}
+	}
+	h.nodes, h.byID, h.entry, h.maxLevel, h.deleted = nil, map[uint]int{}, -1, 0, 0
+	for _, n := range live {
+		h.insert(n.id, n.vec)
+	}
+}
+
+type candidate struct {
+	index int
+	dist  float64
+}
+
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].dist > h[j].dist }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func normalize(vec []float32) []float32 {
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	out := make([]float32, len(vec))
+	if norm == 0 {
+		return out
+	}
+	scale := 1 / math.Sqrt(norm)
+	for i, v := range vec {
+		out[i] = float32(float64(v) * scale)
+	}
+	return out
+}
+
+// EncodeVector はベクトルを DB に保存するバイト列（float32 のリトルエンディアン）にする
+func EncodeVector(vec []float32) []byte {
+	out := make([]byte, len(vec)*4)
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(out[i*4:], math.Float32bits(v))
+	}
+	return out
+}
+
+// DecodeVector は EncodeVector のバイト列をベクトルに戻す
+func DecodeVector(raw []byte) ([]float32, error) {
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("vectorindex: invalid vector length %d", len(raw))
+	}
+	out := make([]float32, len(raw)/4)
+	for i := range out {
+		out[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))
+	}
+	return out, nil
+}
@@ -0,0 +1,94 @@
+package controllers_test
+
+// OAuth の state とブラウザの Cookie の結び付け（OAuthController）のテスト
+//
+// 実行: cd Backend && go test ./test/controllers/... -run OAuthState -v
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"Backend/internal/config"
+	"Backend/internal/controllers"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func newOAuthStateController(t *testing.T) (*controllers.OAuthController, *services.TokenService) {
+	t.Helper()
+	t.Setenv("APP_URL", "http://app.test")
+	tokens := services.NewTokenService([]byte("0123456789abcdef0123456789abcdef"), time.Hour, 24*time.Hour)
+	oauthService := services.NewOAuthService(nil, nil, &config.OAuthConfig{
+		Google: &oauth2.Config{ClientID: "client", Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example/auth"}},
+		GitHub: &oauth2.Config{ClientID: "client", Endpoint: oauth2.Endpoint{AuthURL: "https://github.example/auth"}},
+	}, nil)
+	oauthService.SetTokenService(tokens)
+	return controllers.NewOAuthController(oauthService), tokens
+}
+
+func callback(handler http.HandlerFunc, path, state string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path+"?code=abc&state="+url.QueryEscape(state), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func assertStateRejected(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	require.Equal(t, http.StatusTemporaryRedirect, rec.Code)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "app.test", location.Host)
+	assert.Equal(t, "invalid oauth state", location.Query().Get("error"))
+}
+
+func TestOAuthState_LoginSetsHttpOnlyNonceCookie(t *testing.T) {
+	ctrl, _ := newOAuthStateController(t)
+	rec := httptest.NewRecorder()
+	ctrl.GoogleLogin(rec, httptest.NewRequest(http.MethodGet, "/api/auth/google", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "oauth_state", cookies[0].Name)
+	assert.Equal(t, body["state"], cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+}
+
+func TestOAuthState_LoginCallbackRequiresMatchingCookie(t *testing.T) {
+	ctrl, _ := newOAuthStateController(t)
+
+	// Cookie が無い（別のブラウザで開始された）ログインは受け付けない
+	assertStateRejected(t, callback(ctrl.GoogleCallback, "/api/auth/google/callback", "attacker-state", nil))
+	assertStateRejected(t, callback(ctrl.GitHubCallback, "/api/auth/github/callback", "attacker-state",
+		&http.Cookie{Name: "oauth_state", Value: "victim-nonce"}))
+}
+
+func TestOAuthState_LinkStateFromAnotherBrowserIsRejected(t *testing.T) {
+	ctrl, tokens := newOAuthStateController(t)
+	state, err := tokens.IssueOAuthLinkState(42, "github", "attacker-nonce")
+	require.NoError(t, err)
+
+	// 攻撃者が開始した連携の state を被害者のブラウザで踏ませても、Cookie の nonce が違うので連携しない
+	rec := callback(ctrl.GitHubCallback, "/api/auth/github/callback", state,
+		&http.Cookie{Name: "oauth_state", Value: "victim-nonce"})
+	assertStateRejected(t, rec)
+
+	// コールバックのたびに Cookie は消す
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+}
@@ -0,0 +1,121 @@
+package middleware_test
+
+// ロールベース認可（RequirePermission）のテスト
+//
+// 実行: cd Backend && go test ./test/middleware/... -v
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"Backend/domain/entity"
+	"Backend/domain/repository"
+	"Backend/internal/middleware"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubUserRepo struct {
+	repository.UserRepository
+	users map[uint]*entity.User
+}
+
+func (r *stubUserRepo) GetUserByID(id uint) (*entity.User, error) {
+	return r.users[id], nil
+}
+
+func newTestAuthenticator(users ...*entity.User) (*middleware.Authenticator, *services.TokenService) {
+	tokens := services.NewTokenService([]byte("0123456789abcdef0123456789abcdef"), time.Hour, time.Hour)
+	repo := &stubUserRepo{users: map[uint]*entity.User{}}
+	for _, u := range users {
+		repo.users[u.ID] = u
+	}
+	return middleware.NewAuthenticator(tokens, repo), tokens
+}
+
+func callWithToken(t *testing.T, h http.HandlerFunc, tokens *services.TokenService, userID uint) int {
+	t.Helper()
+	return call(t, h, tokens, userID, false).Code
+}
+
+// callWithMFAToken 2段階認証を経たセッションのトークンで呼び出す
+func callWithMFAToken(t *testing.T, h http.HandlerFunc, tokens *services.TokenService, userID uint) int {
+	t.Helper()
+	return call(t, h, tokens, userID, true).Code
+}
+
+func call(t *testing.T, h http.HandlerFunc, tokens *services.TokenService, userID uint, mfa bool) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+	if userID != 0 {
+		issue := tokens.IssuePair
+		if mfa {
+			issue = tokens.IssueMFAPair
+		}
+		pair, err := issue(userID)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	}
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec
+}
+
+func TestRequirePermission(t *testing.T) {
+	admin := &entity.User{ID: 1, Email: "admin@example.com", Role: entity.RoleAdmin}
+	legacyAdmin := &entity.User{ID: 2, Email: "legacy@example.com", IsAdmin: true}
+	teacher := &entity.User{ID: 3, Email: "teacher@example.com", Role: entity.RoleTeacher}
+	student := &entity.User{ID: 4, Email: "student@example.com", Role: entity.RoleStudent}
+	authn, tokens := newTestAuthenticator(admin, legacyAdmin, teacher, student)
+
+	var actor string
+	h := authn.RequirePermission(middleware.PermUsersManage, func(w http.ResponseWriter, r *http.Request) {
+		u, _ := middleware.UserFromContext(r.Context())
+		actor = u.Email
+		w.WriteHeader(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, callWithToken(t, h, tokens, 0))
+	assert.Equal(t, http.StatusForbidden, callWithToken(t, h, tokens, student.ID))
+	assert.Equal(t, http.StatusForbidden, callWithMFAToken(t, h, tokens, teacher.ID))
+	assert.Equal(t, http.StatusOK, callWithMFAToken(t, h, tokens, legacyAdmin.ID))
+	assert.Equal(t, http.StatusOK, callWithMFAToken(t, h, tokens, admin.ID))
+	assert.Equal(t, "admin@example.com", actor)
+}
+
+func TestRequirePermission_RequiresMFAForPrivilegedRoles(t *testing.T) {
+	now := time.Now()
+	admin := &entity.User{ID: 1, Email: "admin@example.com", Role: entity.RoleAdmin}
+	enrolledTeacher := &entity.User{ID: 2, Email: "teacher@example.com", Role: entity.RoleTeacher, TOTPEnabledAt: &now}
+	authn, tokens := newTestAuthenticator(admin, enrolledTeacher)
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	rec := call(t, authn.RequirePermission(middleware.PermUsersManage, ok), tokens, admin.ID, false)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "mfa_enrollment_required")
+
+	rec = call(t, authn.RequirePermission(middleware.PermStudentsView, ok), tokens, enrolledTeacher.ID, false)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"mfa_required"`)
+
+	assert.Equal(t, http.StatusOK, callWithMFAToken(t, authn.RequirePermission(middleware.PermStudentsView, ok), tokens, enrolledTeacher.ID))
+	assert.Equal(t, http.StatusOK, callWithToken(t, authn.Require(ok), tokens, admin.ID), "権限を要求しないルートは 2段階認証なしで使える")
+}
+
+func TestRequirePermission_IgnoresAdminEmailHeader(t *testing.T) {
+	admin := &entity.User{ID: 1, Email: "admin@example.com", Role: entity.RoleAdmin}
+	authn, _ := newTestAuthenticator(admin)
+	h := authn.RequirePermission(middleware.PermUsersManage, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+	req.Header.Set("X-Admin-Email", "admin@example.com")
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,98 @@
+package ratelimit_test
+
+// トークンバケット型レート制限のテスト
+//
+// 実行: cd Backend && go test ./test/ratelimit/... -v
+
+import (
+	"testing"
+	"time"
+
+	"Backend/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newLimiter(now *time.Time) *ratelimit.Limiter {
+	l := ratelimit.NewLimiter()
+	l.SetNow(func() time.Time { return *now })
+	l.SetLimit("test", ratelimit.Limit{Enabled: true, UserPerMinute: 6, UserBurst: 2, IPPerMinute: 60, IPBurst: 10})
+	return l
+}
+
+func TestLimiter_BurstThenReject(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(&now)
+
+	ok, _ := l.Allow("test", "1", "10.0.0.1")
+	assert.True(t, ok)
+	ok, _ = l.Allow("test", "1", "10.0.0.1")
+	assert.True(t, ok)
+
+	ok, wait := l.Allow("test", "1", "10.0.0.1")
+	assert.False(t, ok)
+	// 6回/分 = 10秒で1トークン回復
+	assert.Equal(t, 10*time.Second, wait)
+
+	// 別ユーザーは独立したバケット
+	ok, _ = l.Allow("test", "2", "10.0.0.1")
+	assert.True(t, ok)
+}
+
+func TestLimiter_Refill(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(&now)
+
+	l.Allow("test", "1", "10.0.0.1")
+	l.Allow("test", "1", "10.0.0.1")
+	ok, _ := l.Allow("test", "1", "10.0.0.1")
+	assert.False(t, ok)
+
+	now = now.Add(10 * time.Second)
+	ok, _ = l.Allow("test", "1", "10.0.0.1")
+	assert.True(t, ok)
+}
+
+func TestLimiter_IPLimitAppliesAcrossUsers(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := ratelimit.NewLimiter()
+	l.SetNow(func() time.Time { return now })
+	l.SetLimit("test", ratelimit.Limit{Enabled: true, UserPerMinute: 60, UserBurst: 10, IPPerMinute: 60, IPBurst: 2})
+
+	ok, _ := l.Allow("test", "1", "10.0.0.1")
+	assert.True(t, ok)
+	ok, _ = l.Allow("test", "2", "10.0.0.1")
+	assert.True(t, ok)
+	ok, wait := l.Allow("test", "3", "10.0.0.1")
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, wait)
+
+	// 未認証（ユーザーキーなし）は IP のみで判定する
+	ok, _ = l.Allow("test", "", "10.0.0.2")
+	assert.True(t, ok)
+}
+
+func TestLimiter_DisabledAndUpdatedLimits(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(&now)
+
+	l.Allow("test", "1", "10.0.0.1")
+	l.Allow("test", "1", "10.0.0.1")
+
+	// 無効化すると常に許可
+	l.SetLimit("test", ratelimit.Limit{Enabled: false, UserPerMinute: 6, UserBurst: 2, IPPerMinute: 60, IPBurst: 10})
+	ok, _ := l.Allow("test", "1", "10.0.0.1")
+	assert.True(t, ok)
+
+	// 容量を下げると既存バケットも切り詰められる
+	l.SetLimit("test", ratelimit.Limit{Enabled: true, UserPerMinute: 60, UserBurst: 1, IPPerMinute: 60, IPBurst: 1})
+	now = now.Add(time.Minute)
+	ok, _ = l.Allow("test", "1", "10.0.0.1")
+	assert.True(t, ok)
+	ok, _ = l.Allow("test", "1", "10.0.0.1")
+	assert.False(t, ok)
+
+	// 未登録グループは制限しない
+	ok, _ = l.Allow("unknown", "1", "10.0.0.1")
+	assert.True(t, ok)
+}
@@ -0,0 +1,99 @@
+package secrets_test
+
+// エンベロープ暗号化と鍵ローテーションのテスト
+//
+// 実行: cd Backend && go test ./test/secrets/... -v
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Backend/internal/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProvider(t *testing.T) *secrets.LocalFileKeyProvider {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, secrets.GenerateKeyFile(path))
+	provider, err := secrets.NewLocalFileKeyProvider(path)
+	require.NoError(t, err)
+	return provider
+}
+
+func TestEnvelope_RoundTrip(t *testing.T) {
+	env := secrets.NewEnvelope(newProvider(t))
+
+	encrypted, err := env.Encrypt("gho_secret_token")
+	require.NoError(t, err)
+	assert.True(t, secrets.IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "gho_secret_token")
+
+	plain, err := env.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "gho_secret_token", plain)
+}
+
+func TestEnvelope_LegacyPlaintextPassesThrough(t *testing.T) {
+	env := secrets.NewEnvelope(newProvider(t))
+
+	plain, err := env.Decrypt("token123")
+	require.NoError(t, err)
+	assert.Equal(t, "token123", plain)
+	assert.True(t, env.NeedsReencrypt("token123"))
+}
+
+func TestEnvelope_Rotation(t *testing.T) {
+	provider := newProvider(t)
+	env := secrets.NewEnvelope(provider)
+
+	old, err := env.Encrypt("gho_secret_token")
+	require.NoError(t, err)
+	assert.False(t, env.NeedsReencrypt(old))
+
+	_, err = provider.Rotate()
+	require.NoError(t, err)
+
+	// 旧鍵の暗号文も復号でき、再暗号化対象として検出される
+	assert.True(t, env.NeedsReencrypt(old))
+	plain, err := env.Decrypt(old)
+	require.NoError(t, err)
+	assert.Equal(t, "gho_secret_token", plain)
+}
+
+func TestEnvelope_RunningProviderPicksUpRotatedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, secrets.GenerateKeyFile(path))
+	server, err := secrets.NewLocalFileKeyProvider(path)
+	require.NoError(t, err)
+	rotator, err := secrets.NewLocalFileKeyProvider(path)
+	require.NoError(t, err)
+
+	// 再暗号化コマンドが鍵を追加して再暗号化した行を、稼働中のサーバーが再起動せずに復号できる
+	_, err = rotator.Rotate()
+	require.NoError(t, err)
+	encrypted, err := secrets.NewEnvelope(rotator).Encrypt("gho_secret_token")
+	require.NoError(t, err)
+
+	plain, err := secrets.NewEnvelope(server).Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "gho_secret_token", plain)
+}
+
+func TestEnvelope_TamperedCiphertext(t *testing.T) {
+	env := secrets.NewEnvelope(newProvider(t))
+	encrypted, err := env.Encrypt("gho_secret_token")
+	require.NoError(t, err)
+
+	last := encrypted[len(encrypted)-1]
+	replacement := "A"
+	if last == 'A' {
+		replacement = "B"
+	}
+	tampered := strings.TrimSuffix(encrypted, string(last)) + replacement
+	_, err = env.Decrypt(tampered)
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package services_test
+
+// 2段階認証（TOTP）のユニットテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run TOTP -v
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"Backend/domain/entity"
+	"Backend/internal/services"
+	"Backend/internal/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTP_EnrollmentAndLogin(t *testing.T) {
+	svc, userRepo, _ := newLoginTestService(t)
+	userRepo.user.Role = entity.RoleTeacher
+	tokens := services.NewTokenService([]byte("0123456789abcdef0123456789abcdef"), time.Hour, time.Hour)
+	svc.SetTokenService(tokens)
+	login := services.LoginRequest{Email: "student@example.com", Password: "correct-password", IP: "203.0.113.1"}
+
+	// 未設定の教員はトークンを受け取るが、2段階認証済みのセッションにはならない
+	resp, err := svc.Login(login)
+	require.NoError(t, err)
+	assert.True(t, resp.MFAEnrollmentRequired)
+	claims, err := tokens.VerifyAccessToken(resp.Token)
+	require.NoError(t, err)
+	assert.False(t, claims.MFA)
+
+	enrollment, err := svc.BeginTOTPEnrollment(1)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/")
+
+	code, err := totp.Code(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	activated, err := svc.ActivateTOTP(1, code, "203.0.113.1")
+	require.NoError(t, err)
+	require.Len(t, activated.RecoveryCodes, 10)
+	claims, err = tokens.VerifyAccessToken(activated.Token)
+	require.NoError(t, err)
+	assert.True(t, claims.MFA)
+
+	// 有効化後はパスワードだけではトークンを発行しない
+	resp, err = svc.Login(login)
+	require.NoError(t, err)
+	assert.True(t, resp.MFARequired)
+	assert.Empty(t, resp.Token)
+	require.NotEmpty(t, resp.MFAToken)
+
+	_, err = svc.CompleteMFALogin(resp.MFAToken, code, "203.0.113.1")
+	assert.True(t, errors.Is(err, services.ErrInvalidMFACode), "受理済みのコードは再利用できない")
+
+	done, err := svc.CompleteMFALogin(resp.MFAToken, activated.RecoveryCodes[0], "203.0.113.1")
+	require.NoError(t, err)
+	claims, err = tokens.VerifyAccessToken(done.Token)
+	require.NoError(t, err)
+	assert.True(t, claims.MFA)
+
+	_, err = svc.CompleteMFALogin(resp.MFAToken, activated.RecoveryCodes[0], "203.0.113.1")
+	assert.True(t, errors.Is(err, services.ErrInvalidMFACode), "リカバリーコードは一度しか使えない")
+
+	status, err := svc.GetTOTPStatus(1)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, 9, status.RecoveryCodesRemaining)
+
+	assert.Error(t, svc.DisableTOTP(1, activated.RecoveryCodes[1], "203.0.113.1"), "教員は 2段階認証を無効化できない")
+}
+
+func TestTOTP_RefreshKeepsMFA(t *testing.T) {
+	svc, userRepo, _ := newLoginTestService(t)
+	tokens := services.NewTokenService([]byte("0123456789abcdef0123456789abcdef"), time.Hour, time.Hour)
+	svc.SetTokenService(tokens)
+	enabledAt := time.Now().Add(-time.Hour)
+	userRepo.user.TOTPEnabledAt = &enabledAt
+
+	pair, err := tokens.IssueMFAPair(1)
+	require.NoError(t, err)
+	resp, err := svc.RefreshSession(pair.RefreshToken)
+	require.NoError(t, err)
+	claims, err := tokens.VerifyAccessToken(resp.Token)
+	require.NoError(t, err)
+	assert.True(t, claims.MFA)
+
+	// 発行後に 2段階認証を設定し直した場合は引き継がない
+	reenabledAt := time.Now().Add(time.Hour)
+	userRepo.user.TOTPEnabledAt = &reenabledAt
+	resp, err = svc.RefreshSession(pair.RefreshToken)
+	require.NoError(t, err)
+	claims, err = tokens.VerifyAccessToken(resp.Token)
+	require.NoError(t, err)
+	assert.False(t, claims.MFA)
+
+	// 2段階認証を無効化した場合も引き継がない
+	userRepo.user.TOTPEnabledAt = nil
+	resp, err = svc.RefreshSession(pair.RefreshToken)
+	require.NoError(t, err)
+	claims, err = tokens.VerifyAccessToken(resp.Token)
+	require.NoError(t, err)
+	assert.False(t, claims.MFA)
+}
@@ -0,0 +1,163 @@
+package services_test
+
+// 教員・クラス管理（CohortService）のユニットテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Cohort -v
+
+import (
+	"testing"
+	"time"
+
+	"Backend/domain/entity"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCohortRepo は所属と招待をメモリ上に保持する CohortRepository モック。
+type mockCohortRepo struct {
+	repository.CohortRepository
+	cohorts     map[uint]*models.Cohort
+	members     []models.CohortMember
+	invitations map[string]*models.CohortInvitation
+}
+
+func newMockCohortRepo() *mockCohortRepo {
+	return &mockCohortRepo{
+		cohorts:     map[uint]*models.Cohort{1: {ID: 1, SchoolName: "テスト大学", Name: "ゼミA"}},
+		invitations: map[string]*models.CohortInvitation{},
+	}
+}
+
+func (m *mockCohortRepo) FindByID(id uint) (*models.Cohort, error) { return m.cohorts[id], nil }
+func (m *mockCohortRepo) AddMember(member *models.CohortMember) error {
+	m.members = append(m.members, *member)
+	return nil
+}
+func (m *mockCohortRepo) FindMember(cohortID, userID uint) (*models.CohortMember, error) {
+	for i := range m.members {
+		if m.members[i].CohortID == cohortID && m.members[i].UserID == userID {
+			return &m.members[i], nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockCohortRepo) SharesCohort(teacherID, studentID uint) (bool, error) {
+	for _, t := range m.members {
+		if t.UserID != teacherID || t.Role != models.CohortRoleTeacher {
+			continue
+		}
+		for _, s := range m.members {
+			if s.CohortID == t.CohortID && s.UserID == studentID && s.Role == models.CohortRoleStudent && s.ApprovedAt != nil {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+func (m *mockCohortRepo) ApproveMember(cohortID, userID uint) error {
+	if member, _ := m.FindMember(cohortID, userID); member != nil {
+		now := time.Now()
+		member.ApprovedAt = &now
+	}
+	return nil
+}
+func (m *mockCohortRepo) FindInvitationByToken(token string) (*models.CohortInvitation, error) {
+	return m.invitations[token], nil
+}
+func (m *mockCohortRepo) UpdateInvitation(inv *models.CohortInvitation) error { return nil }
+
+// mockCohortUserRepo は UpdateUser の呼び出しを記録する UserRepository モック。
+type mockCohortUserRepo struct {
+	repository.UserRepository
+	updated *entity.User
+}
+
+func (m *mockCohortUserRepo) UpdateUser(u *entity.User) error {
+	m.updated = u
+	return nil
+}
+
+func TestCohortService_CanViewStudent(t *testing.T) {
+	repo := newMockCohortRepo()
+	repo.members = []models.CohortMember{
+		{CohortID: 1, UserID: 10, Role: models.CohortRoleTeacher},
+		{CohortID: 1, UserID: 20, Role: models.CohortRoleStudent},
+	}
+	svc := services.NewCohortService(repo, &mockCohortUserRepo{}, nil, nil, nil, nil, nil)
+
+	teacherBeforeApproval := &entity.User{ID: 10, Role: entity.RoleTeacher}
+	ok, err := svc.CanViewStudent(teacherBeforeApproval, 20)
+	require.NoError(t, err)
+	assert.False(t, ok, "学生本人が承認するまで担当教員も閲覧できない")
+	require.NoError(t, svc.ApproveMembership(&entity.User{ID: 20, Role: entity.RoleStudent}, 1))
+
+	teacher := &entity.User{ID: 10, Role: entity.RoleTeacher}
+	otherTeacher := &entity.User{ID: 11, Role: entity.RoleTeacher}
+	student := &entity.User{ID: 21, Role: entity.RoleStudent}
+	admin := &entity.User{ID: 1, IsAdmin: true}
+
+	ok, err = svc.CanViewStudent(teacher, 20)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, _ = svc.CanViewStudent(otherTeacher, 20)
+	assert.False(t, ok, "担当外の教員は閲覧できない")
+
+	ok, _ = svc.CanViewStudent(student, 20)
+	assert.False(t, ok, "学生は他の学生を閲覧できない")
+
+	ok, _ = svc.CanViewStudent(admin, 20)
+	assert.True(t, ok)
+}
+
+func TestCohortService_AcceptInvitation(t *testing.T) {
+	repo := newMockCohortRepo()
+	repo.invitations["valid"] = &models.CohortInvitation{CohortID: 1, Email: "sensei@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+	repo.invitations["expired"] = &models.CohortInvitation{CohortID: 1, Email: "sensei@example.com", ExpiresAt: time.Now().Add(-time.Hour)}
+	userRepo := &mockCohortUserRepo{}
+	svc := services.NewCohortService(repo, userRepo, nil, nil, nil, nil, nil)
+
+	t.Run("メールアドレスが一致しない招待は承認できない", func(t *testing.T) {
+		_, err := svc.AcceptInvitation(&entity.User{ID: 5, Email: "other@example.com"}, "valid")
+		require.Error(t, err)
+		assert.Equal(t, "forbidden", err.Error())
+	})
+
+	t.Run("期限切れの招待は承認できない", func(t *testing.T) {
+		_, err := svc.AcceptInvitation(&entity.User{ID: 5, Email: "sensei@example.com"}, "expired")
+		assert.Error(t, err)
+	})
+
+	t.Run("学生ロールのユーザーは承認できずロールも変わらない", func(t *testing.T) {
+		user := &entity.User{ID: 5, Email: "sensei@example.com", Role: entity.RoleStudent, SchoolName: "テスト大学"}
+		_, err := svc.AcceptInvitation(user, "valid")
+		require.Error(t, err)
+		assert.Nil(t, userRepo.updated, "招待の承認でロールを変更しない")
+		assert.Equal(t, entity.RoleStudent, user.Role)
+		member, _ := repo.FindMember(1, 5)
+		assert.Nil(t, member)
+	})
+
+	t.Run("別の学校の教員は承認できない", func(t *testing.T) {
+		_, err := svc.AcceptInvitation(&entity.User{ID: 5, Email: "sensei@example.com", Role: entity.RoleTeacher, SchoolName: "別の大学"}, "valid")
+		assert.Error(t, err)
+	})
+
+	t.Run("同じ学校の教員が承認するとクラスに所属する", func(t *testing.T) {
+		user := &entity.User{ID: 5, Email: "Sensei@example.com", Role: entity.RoleTeacher, SchoolName: "テスト大学"}
+		cohort, err := svc.AcceptInvitation(user, "valid")
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), cohort.ID)
+		assert.Nil(t, userRepo.updated)
+		member, _ := repo.FindMember(1, 5)
+		require.NotNil(t, member)
+		assert.Equal(t, models.CohortRoleTeacher, member.Role)
+
+		_, err = svc.AcceptInvitation(user, "valid")
+		assert.Error(t, err, "使用済みの招待は再利用できない")
+	})
+}
@@ -0,0 +1,172 @@
+package services_test
+
+// ゲストユーザーのアップグレード・統合のユニットテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Guest -v
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"Backend/domain/entity"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newGuest() *entity.User {
+	return &entity.User{ID: 1, Email: "guest_abc@temp.local", Name: "Guest_abc", IsGuest: true, TargetLevel: "未設定"}
+}
+
+func TestUpgradeGuest_KeepsUserID(t *testing.T) {
+	users := newMockIdentityUserRepo(newGuest(), &entity.User{ID: 2, Email: "taken@example.com"})
+	auth := services.NewAuthService(users, nil, services.NewEmailService())
+
+	_, err := auth.UpgradeGuest(1, services.RegisterRequest{Email: "taken@example.com", Password: "password123"})
+	assert.EqualError(t, err, "email already exists")
+
+	resp, err := auth.UpgradeGuest(1, services.RegisterRequest{Email: "student@example.com", Password: "password123", Name: "学生"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), resp.UserID, "ユーザーIDが変わらないのでデータはそのまま残る")
+	assert.False(t, resp.IsGuest)
+
+	saved := users.users[1]
+	assert.Equal(t, "student@example.com", saved.Email)
+	assert.Equal(t, "学生", saved.Name)
+	assert.False(t, saved.IsGuest)
+	assert.NotEmpty(t, saved.Password)
+	assert.NotEmpty(t, saved.EmailVerificationToken)
+
+	_, err = auth.UpgradeGuest(1, services.RegisterRequest{Email: "again@example.com", Password: "password123"})
+	assert.EqualError(t, err, "user is not a guest")
+}
+
+func TestUpgradeGuestWithIdentity_ConvertsGuestInPlace(t *testing.T) {
+	users := newMockIdentityUserRepo(newGuest())
+	identities := &mockIdentityRepo{}
+	svc := services.NewOAuthService(users, identities, nil, nil)
+
+	resp, err := svc.UpgradeGuestWithIdentity(1, googleProfile("g-1", "student@example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), resp.UserID)
+	assert.False(t, users.users[1].IsGuest)
+	assert.Equal(t, "student@example.com", users.users[1].Email)
+	require.Len(t, identities.identities, 1)
+	assert.Equal(t, uint(1), identities.identities[0].UserID)
+}
+
+func TestUpgradeGuestWithIdentity_RefusesUnsafeEmailMatch(t *testing.T) {
+	users := newMockIdentityUserRepo(newGuest(), &entity.User{ID: 2, Email: "student@example.com", Password: "hash"})
+	svc := services.NewOAuthService(users, &mockIdentityRepo{}, nil, nil)
+
+	_, err := svc.UpgradeGuestWithIdentity(1, googleProfile("g-1", "student@example.com"))
+	assert.ErrorIs(t, err, services.ErrOAuthAccountExists, "メール未認証のアカウントにはゲストを統合しない")
+	assert.True(t, users.users[1].IsGuest)
+}
+
+// newMergeTestDB 実行された SQL を記録する sqlmock の GORM DB を返す
+func newMergeTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, *[]string) {
+	t.Helper()
+	var executed []string
+	matcher := sqlmock.QueryMatcherFunc(func(expected, actual string) error {
+		executed = append(executed, actual)
+		return nil
+	})
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	require.NoError(t, err)
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db, mock, &executed
+}
+
+func TestMergeGuest_MovesEveryUserOwnedTableInOneTransaction(t *testing.T) {
+	db, mock, executed := newMergeTestDB(t)
+	users := newMockIdentityUserRepo(newGuest(), &entity.User{ID: 2, Email: "student@example.com", Password: "hash"})
+	tokens := services.NewTokenService([]byte(strings.Repeat("k", 32)), 0, 0)
+	auth := services.NewAuthService(users, nil, services.NewEmailService())
+	auth.SetTokenService(tokens)
+	auth.SetDB(db)
+
+	guestTokens, err := tokens.IssuePair(1)
+	require.NoError(t, err)
+
+	result := sqlmock.NewResult(0, 1)
+	mock.ExpectBegin()
+	for _, table := range models.UserDataTables() {
+		if !table.OwnedByUserID() {
+			continue
+		}
+		switch table.Conflict {
+		case "":
+		case table.Column:
+			mock.ExpectQuery("count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		default:
+			mock.ExpectQuery("pluck").WillReturnRows(sqlmock.NewRows([]string{table.Conflict}).AddRow("1"))
+			mock.ExpectExec("delete").WillReturnResult(result)
+		}
+		mock.ExpectExec("update").WillReturnResult(result)
+	}
+	mock.ExpectExec("collective").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(result)
+	mock.ExpectExec("delete user").WithArgs(driver.Value(uint64(1))).WillReturnResult(result)
+	mock.ExpectCommit()
+
+	guestID, err := auth.MergeGuest(2, guestTokens.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), guestID)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	all := strings.Join(*executed, "\n")
+	for _, table := range []string{"chat_messages", "user_weight_scores", "user_company_matches", "interview_sessions", "resume_documents", "user_identities"} {
+		assert.Contains(t, all, "UPDATE `"+table+"` SET `user_id`=?", table)
+	}
+	assert.Contains(t, all, "DELETE FROM `users`")
+
+	_, err = auth.MergeGuest(2, "not-a-token")
+	assert.ErrorIs(t, err, services.ErrInvalidGuestToken)
+}
+
+func TestUpgradeGuestWithIdentity_LinksAndMergesInOneTransaction(t *testing.T) {
+	db, mock, executed := newMergeTestDB(t)
+	users := newMockIdentityUserRepo(newGuest(), &entity.User{ID: 2, Email: "student@example.com", OAuthProvider: "github"})
+	identities := &mockIdentityRepo{}
+	svc := services.NewOAuthService(users, identities, nil, nil)
+	svc.SetDB(db)
+
+	// 連携の作成後にゲストの統合が失敗したら、連携もロールバックされる
+	mock.ExpectBegin()
+	mock.ExpectExec("insert identity").WillReturnResult(sqlmock.NewResult(5, 1))
+	for _, table := range models.UserDataTables() {
+		if !table.OwnedByUserID() {
+			continue
+		}
+		switch table.Conflict {
+		case "":
+			mock.ExpectExec("update").WillReturnError(errors.New("merge failed"))
+		case table.Column:
+			mock.ExpectQuery("count").WillReturnError(errors.New("merge failed"))
+		default:
+			mock.ExpectQuery("pluck").WillReturnError(errors.New("merge failed"))
+		}
+		break
+	}
+	mock.ExpectRollback()
+
+	_, err := svc.UpgradeGuestWithIdentity(1, googleProfile("g-1", "student@example.com"))
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NotEmpty(t, *executed)
+	assert.Contains(t, (*executed)[0], "INSERT INTO `user_identities`")
+	assert.Empty(t, identities.identities, "連携はトランザクションの外で作成しない")
+	assert.True(t, users.users[1].IsGuest)
+}
@@ -9,7 +9,9 @@ import (
 	"testing"
 	"time"
 
+	domainai "Backend/domain/ai"
 	"Backend/domain/entity"
+	internalai "Backend/internal/ai"
 	"Backend/internal/models"
 	openaiPkg "Backend/internal/openai"
 	"Backend/internal/services"
@@ -80,6 +82,11 @@ func (m *mockUserRepo2) GetUserByPasswordResetToken(token string) (*entity.User,
 func (m *mockUserRepo2) GetUserByOAuth(provider, oauthID string) (*entity.User, error) {
 	return nil, nil
 }
+func (m *mockUserRepo2) GetUserByUnlockToken(token string) (*entity.User, error) {
+	return nil, nil
+}
+func (m *mockUserRepo2) IncrementFailedLogin(userID uint, at time.Time) (int, error) { return 0, nil }
+func (m *mockUserRepo2) UpdateLockout(u *entity.User) error                          { return nil }
 
 // ─── ヘルパー ─────────────────────────────────────────────────────────────────
 
@@ -88,13 +95,13 @@ func newTestInterviewService(
 	sessionRepo *mockInterviewSessionRepo,
 	utterRepo *mockInterviewUtterRepo,
 	userRepo *mockUserRepo2,
-	aiClient *openaiPkg.Client,
+	aiClient domainai.LLMClient,
 ) *services.InterviewService {
 	return services.NewInterviewService(sessionRepo, utterRepo, nil, userRepo, nil, aiClient, nil)
 }
 
 // newOpenAITestServer はOpenAI Chat Completions レスポンスを返すテストHTTPサーバーを起動する。
-func newOpenAITestServer(t *testing.T, responseBody string) (*httptest.Server, *openaiPkg.Client) {
+func newOpenAITestServer(t *testing.T, responseBody string) (*httptest.Server, domainai.LLMClient) {
 	t.Helper()
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -114,7 +121,7 @@ func newOpenAITestServer(t *testing.T, responseBody string) (*httptest.Server, *
 		json.NewEncoder(w).Encode(resp)
 	}))
 	t.Cleanup(srv.Close)
-	return srv, openaiPkg.NewWithBaseURL(srv.URL, "gpt-4o")
+	return srv, internalai.NewOpenAIAdapter(openaiPkg.NewWithBaseURL(srv.URL, "gpt-4o"))
 }
 
 // ─── BuildTranscript ─────────────────────────────────────────────────────────
@@ -160,25 +167,25 @@ func TestBuildTranscript_TrimsWhitespace(t *testing.T) {
 
 func TestExtractJSONObject_Clean(t *testing.T) {
 	input := `{"suggestions": [{"original": "頑張りました", "suggestions": ["尽力しました"]}]}`
-	result := services.ExtractJSONObject(input)
+	result := domainai.ExtractJSONObject(input)
 	assert.Equal(t, input, result)
 }
 
 func TestExtractJSONObject_WithMarkdownFence(t *testing.T) {
 	input := "```json\n{\"key\": \"value\"}\n```"
-	result := services.ExtractJSONObject(input)
+	result := domainai.ExtractJSONObject(input)
 	assert.Equal(t, `{"key": "value"}`, result)
 }
 
 func TestExtractJSONObject_WithLeadingText(t *testing.T) {
 	// ExtractJSONObject は先頭のテキストと末尾のテキストを両方除去する
 	input := `以下がJSONです: {"key": "value"} 終わり`
-	result := services.ExtractJSONObject(input)
+	result := domainai.ExtractJSONObject(input)
 	assert.Equal(t, `{"key": "value"}`, result)
 }
 
 func TestExtractJSONObject_Empty(t *testing.T) {
-	result := services.ExtractJSONObject("")
+	result := domainai.ExtractJSONObject("")
 	assert.Equal(t, "", result)
 }
 
@@ -6,10 +6,14 @@ package services_test
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	domainai "Backend/domain/ai"
 	internalai "Backend/internal/ai"
+	"Backend/internal/config"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,6 +21,9 @@ import (
 
 // コンパイル時に FallbackAdapter が LLMClient インターフェースを満たすことを検証
 var _ domainai.LLMClient = (*internalai.FallbackAdapter)(nil)
+var _ domainai.LLMClient = (*internalai.UnavailableAdapter)(nil)
+var _ domainai.LLMClient = (*internalai.OpenAIAdapter)(nil)
+var _ domainai.LLMClient = (*internalai.CompatibleAdapter)(nil)
 
 func TestFallbackAdapter_GenerateText(t *testing.T) {
 	adapter := internalai.NewFallbackAdapter()
@@ -46,6 +53,17 @@ func TestFallbackAdapter_TranscribeAudio_ReturnsError(t *testing.T) {
 	assert.Empty(t, text)
 }
 
+func TestUnavailableAdapter_ReturnsProviderUnavailable(t *testing.T) {
+	adapter := internalai.NewUnavailableAdapter()
+	text, err := adapter.GenerateText(context.Background(), "system", "user")
+	assert.ErrorIs(t, err, domainai.ErrProviderUnavailable, "固定メッセージを正常な応答として返さない")
+	assert.Empty(t, text)
+	_, err = adapter.GenerateJSON(context.Background(), "system", "user")
+	assert.ErrorIs(t, err, domainai.ErrProviderUnavailable)
+	_, err = adapter.GenerateEmbedding(context.Background(), "test")
+	assert.ErrorIs(t, err, domainai.ErrProviderUnavailable)
+}
+
 // LLMClient を依存注入で受け取る関数のテスト
 // サービス層がインターフェース経由で AI を呼び出せることを確認
 func TestLLMClientDependencyInjection(t *testing.T) {
@@ -61,3 +79,130 @@ func TestLLMClientDependencyInjection(t *testing.T) {
 	_, embErr := client.GenerateEmbedding(context.Background(), "test")
 	assert.Error(t, embErr, "フォールバック実装ではエンべディングは利用不可")
 }
+
+// newCompatibleTestServer は /chat/completions へのリクエストを記録し、固定の応答を返すテストサーバーを起動する。
+func newCompatibleTestServer(t *testing.T, content string, requests *[]map[string]interface{}) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		*requests = append(*requests, body)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion",
+			"model":   body["model"],
+			"choices": []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": content}, "finish_reason": "stop"}},
+			"usage":   map[string]int{"prompt_tokens": 12, "completion_tokens": 3, "total_tokens": 15},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLLMRegistry_OpenAICompatibleProvider(t *testing.T) {
+	var requests []map[string]interface{}
+	srv := newCompatibleTestServer(t, `{"ok":true}`, &requests)
+
+	var usageModel string
+	var usageTokens int
+	var usageCaller domainai.CallContext
+	client, err := internalai.NewRegistry().New(config.LLMConfig{
+		Provider: internalai.ProviderOpenAICompatible,
+		BaseURL:  srv.URL + "/v1",
+		Model:    "llama3",
+	}, func(ctx context.Context, model string, usage domainai.TokenUsage) {
+		usageModel = model
+		usageTokens = usage.PromptTokens + usage.CompletionTokens
+		usageCaller = domainai.CallContextFrom(ctx)
+	})
+	require.NoError(t, err)
+
+	ctx := domainai.WithCallContext(context.Background(), domainai.CallContext{UserID: 7, Feature: domainai.FeatureChat, RequestID: "req-1"})
+	out, err := client.GenerateJSON(ctx, "sys", "user", domainai.WithMaxTokens(50))
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, out)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "llama3", requests[0]["model"])
+	assert.EqualValues(t, 50, requests[0]["max_tokens"])
+	assert.Equal(t, map[string]interface{}{"type": "json_object"}, requests[0]["response_format"])
+	assert.Len(t, requests[0]["messages"], 2)
+	assert.Equal(t, "llama3", usageModel)
+	assert.Equal(t, 15, usageTokens)
+	assert.Equal(t, domainai.CallContext{UserID: 7, Feature: domainai.FeatureChat, RequestID: "req-1"}, usageCaller, "使用量フックに呼び出し元が渡る")
+
+	_, err = client.Chat(context.Background(), []domainai.Message{
+		{Role: domainai.RoleSystem, Content: "sys"},
+		{Role: domainai.RoleUser, Content: "hi"},
+		{Role: domainai.RoleAssistant, Content: "hello"},
+		{Role: domainai.RoleUser, Content: "again"},
+	}, domainai.WithModel("qwen2"))
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "qwen2", requests[1]["model"])
+	assert.Len(t, requests[1]["messages"], 4)
+	assert.Nil(t, requests[1]["response_format"])
+
+	_, err = client.WebSearch(context.Background(), "query")
+	assert.ErrorIs(t, err, domainai.ErrUnsupported)
+}
+
+func TestLLMRegistry_UnknownProvider(t *testing.T) {
+	_, err := internalai.NewRegistry().New(config.LLMConfig{Provider: "no-such-provider"}, nil)
+	assert.Error(t, err)
+}
+
+func TestLLMRegistry_CompatibleProviderRequiresBaseURL(t *testing.T) {
+	_, err := internalai.NewRegistry().New(config.LLMConfig{Provider: internalai.ProviderOpenAICompatible, Model: "llama3"}, nil)
+	assert.Error(t, err)
+}
+
+func TestLLMRegistry_FallbackProvider(t *testing.T) {
+	client, err := internalai.NewRegistry().New(config.LLMConfig{Provider: internalai.ProviderFallback}, nil)
+	require.NoError(t, err)
+	text, err := client.GenerateText(context.Background(), "", "user")
+	require.NoError(t, err)
+	assert.NotEmpty(t, text)
+}
+
+func TestLLMRegistry_CompatibleProviderStreamsText(t *testing.T) {
+	var streamed bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		streamed, _ = body["stream"].(bool)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"c1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","content":"次の"}}]}`,
+			`{"id":"c1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"質問です"}}]}`,
+			`{"id":"c1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":4,"total_tokens":14}}`,
+		} {
+			_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	var usageTokens int
+	client, err := internalai.NewRegistry().New(config.LLMConfig{
+		Provider: internalai.ProviderOpenAICompatible,
+		BaseURL:  srv.URL + "/v1",
+		Model:    "llama3",
+	}, func(ctx context.Context, model string, usage domainai.TokenUsage) {
+		usageTokens = usage.PromptTokens + usage.CompletionTokens
+	})
+	require.NoError(t, err)
+
+	var deltas []string
+	ctx := domainai.WithTextStream(context.Background(), func(delta string) { deltas = append(deltas, delta) })
+	out, err := client.GenerateText(ctx, "sys", "user")
+	require.NoError(t, err)
+	assert.True(t, streamed, "ストリーミングで要求する")
+	assert.Equal(t, "次の質問です", out, "差分を連結した全文を返す")
+	assert.Equal(t, []string{"次の", "質問です"}, deltas)
+	assert.Equal(t, 14, usageTokens, "最後のチャンクの使用量を記録する")
+}
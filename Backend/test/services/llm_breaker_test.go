@@ -0,0 +1,166 @@
+package services_test
+
+// LLM サーキットブレーカーのテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run LLMBreaker -v
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domainai "Backend/domain/ai"
+	internalai "Backend/internal/ai"
+	"Backend/internal/controllers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ domainai.LLMClient = (*internalai.CircuitBreakerAdapter)(nil)
+var _ controllers.LLMBreakerStatusProvider = (*internalai.CircuitBreakerAdapter)(nil)
+
+// flakyLLM は fail が true の間、テキスト生成と埋め込みでエラーを返すスタブ。
+type flakyLLM struct {
+	domainai.LLMClient
+	fail  bool
+	calls int
+}
+
+func newFlakyLLM() *flakyLLM {
+	return &flakyLLM{LLMClient: internalai.NewFallbackAdapter()}
+}
+
+func (f *flakyLLM) GenerateText(ctx context.Context, _, _ string, _ ...domainai.CallOption) (string, error) {
+	f.calls++
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if f.fail {
+		return "", errors.New("upstream 503")
+	}
+	return "primary", nil
+}
+
+func (f *flakyLLM) GenerateEmbedding(_ context.Context, _ string) ([]float32, error) {
+	f.calls++
+	if f.fail {
+		return nil, errors.New("upstream 503")
+	}
+	return []float32{1}, nil
+}
+
+func breakerState(t *testing.T, b *internalai.CircuitBreakerAdapter, capability string) domainai.BreakerStatus {
+	t.Helper()
+	for _, s := range b.BreakerStatuses() {
+		if s.Capability == capability {
+			return s
+		}
+	}
+	t.Fatalf("capability %s not found", capability)
+	return domainai.BreakerStatus{}
+}
+
+func TestLLMBreaker_OpensAfterThresholdAndFailsOver(t *testing.T) {
+	primary := newFlakyLLM()
+	primary.fail = true
+	b := internalai.NewCircuitBreakerAdapter(primary, nil, internalai.BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Minute})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.SetNow(func() time.Time { return now })
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := b.GenerateText(ctx, "s", "u")
+		assert.Error(t, err, "closed の間は失敗をそのまま返す")
+	}
+	text := breakerState(t, b, domainai.CapabilityText)
+	assert.Equal(t, domainai.BreakerOpen, text.State)
+	assert.EqualValues(t, 1, text.Trips)
+	assert.Equal(t, "upstream 503", text.LastError)
+
+	// open の間はプライマリを呼ばない。セカンダリ未設定なら固定メッセージではなくエラーを返す
+	out, err := b.GenerateText(ctx, "s", "u")
+	assert.ErrorIs(t, err, domainai.ErrProviderUnavailable)
+	assert.Empty(t, out)
+	_, err = b.GenerateJSON(ctx, "s", "u")
+	assert.ErrorIs(t, err, domainai.ErrProviderUnavailable)
+	assert.Equal(t, 3, primary.calls)
+
+	// 機能ごとに独立している
+	assert.Equal(t, domainai.BreakerClosed, breakerState(t, b, domainai.CapabilityEmbedding).State)
+}
+
+func TestLLMBreaker_HalfOpenProbe(t *testing.T) {
+	primary := newFlakyLLM()
+	primary.fail = true
+	secondary := newCountingLLM(`{}`)
+	b := internalai.NewCircuitBreakerAdapter(primary, secondary, internalai.BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.SetNow(func() time.Time { return now })
+	ctx := context.Background()
+
+	_, err := b.GenerateEmbedding(ctx, "x")
+	require.Error(t, err)
+	assert.Equal(t, domainai.BreakerOpen, breakerState(t, b, domainai.CapabilityEmbedding).State)
+
+	// 時間経過で half-open。試行が失敗すると再び open
+	now = now.Add(time.Minute)
+	assert.Equal(t, domainai.BreakerHalfOpen, breakerState(t, b, domainai.CapabilityEmbedding).State)
+	_, err = b.GenerateEmbedding(ctx, "x")
+	require.Error(t, err)
+	status := breakerState(t, b, domainai.CapabilityEmbedding)
+	assert.Equal(t, domainai.BreakerOpen, status.State)
+	assert.EqualValues(t, 2, status.Trips)
+
+	// open の間はセカンダリ
+	vec, err := b.GenerateEmbedding(ctx, "x")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.1, 0.2, 0.3}, vec)
+	assert.Equal(t, 1, secondary.calls)
+
+	// 復旧後の試行が成功すると closed
+	primary.fail = false
+	now = now.Add(time.Minute)
+	vec, err = b.GenerateEmbedding(ctx, "x")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1}, vec)
+	status = breakerState(t, b, domainai.CapabilityEmbedding)
+	assert.Equal(t, domainai.BreakerClosed, status.State)
+	assert.Zero(t, status.ConsecutiveFailures)
+}
+
+func TestLLMBreaker_CallerCancellationIsNotAFailure(t *testing.T) {
+	primary := newFlakyLLM()
+	b := internalai.NewCircuitBreakerAdapter(primary, nil, internalai.BreakerConfig{FailureThreshold: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := b.GenerateText(ctx, "s", "u")
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, domainai.BreakerClosed, breakerState(t, b, domainai.CapabilityText).State)
+}
+
+func TestHealthController_ReportsDegradedLLM(t *testing.T) {
+	primary := newFlakyLLM()
+	primary.fail = true
+	b := internalai.NewCircuitBreakerAdapter(primary, nil, internalai.BreakerConfig{FailureThreshold: 1})
+	ctrl := controllers.NewHealthController(b)
+
+	rec := httptest.NewRecorder()
+	ctrl.Health(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "ok", body["status"])
+
+	_, _ = b.GenerateText(context.Background(), "s", "u")
+	rec = httptest.NewRecorder()
+	ctrl.Health(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "LLM 障害でもヘルスチェック自体は落とさない")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "degraded", body["status"])
+	assert.Len(t, body["llm"], 4)
+}
@@ -0,0 +1,169 @@
+package services_test
+
+// 利用者ごとの LLM 予算のテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run LLMBudget -v
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainai "Backend/domain/ai"
+	"Backend/domain/repository"
+	internalai "Backend/internal/ai"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ repository.LLMUserBudgetRepository = (*memoryBudgetRepo)(nil)
+var _ repository.LLMSpendRepository = (*fakeSpend)(nil)
+var _ internalai.BudgetChecker = (*services.LLMBudgetService)(nil)
+
+type memoryBudgetRepo struct {
+	budgets map[uint]models.LLMUserBudget
+}
+
+func (m *memoryBudgetRepo) FindByUser(userID uint) (*models.LLMUserBudget, error) {
+	b, ok := m.budgets[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &b, nil
+}
+
+func (m *memoryBudgetRepo) List() ([]models.LLMUserBudget, error) {
+	out := make([]models.LLMUserBudget, 0, len(m.budgets))
+	for _, b := range m.budgets {
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func (m *memoryBudgetRepo) Upsert(budget *models.LLMUserBudget) error {
+	m.budgets[budget.UserID] = *budget
+	return nil
+}
+
+func (m *memoryBudgetRepo) Delete(userID uint) error {
+	delete(m.budgets, userID)
+	return nil
+}
+
+// fakeSpend は利用者ごとの当日・当月の利用額を返す（since が月初なら月額）。
+type fakeSpend struct {
+	daily, monthly map[uint]float64
+	calls          int
+}
+
+func (f *fakeSpend) UserCostSince(userID uint, since time.Time) (float64, error) {
+	f.calls++
+	if since.Day() == 1 && since.Hour() == 0 && f.monthly[userID] > 0 {
+		return f.monthly[userID], nil
+	}
+	return f.daily[userID], nil
+}
+
+// modelRecorder は GenerateText で受け取ったモデル指定を記録するスタブ。
+type modelRecorder struct {
+	domainai.LLMClient
+	models []string
+}
+
+func (m *modelRecorder) GenerateText(_ context.Context, _, _ string, opts ...domainai.CallOption) (string, error) {
+	m.models = append(m.models, domainai.ApplyCallOptions(opts...).Model)
+	return "ok", nil
+}
+
+func newBudgetFixture(cfg services.LLMBudgetConfig) (*services.LLMBudgetService, *fakeSpend, *modelRecorder, domainai.LLMClient) {
+	spend := &fakeSpend{daily: map[uint]float64{}, monthly: map[uint]float64{}}
+	svc := services.NewLLMBudgetService(&memoryBudgetRepo{budgets: map[uint]models.LLMUserBudget{}}, spend, cfg)
+	recorder := &modelRecorder{LLMClient: internalai.NewFallbackAdapter()}
+	return svc, spend, recorder, internalai.NewBudgetAdapter(recorder, svc)
+}
+
+func userCtx(userID uint) context.Context {
+	return domainai.WithCallContext(context.Background(), domainai.CallContext{UserID: userID, Feature: domainai.FeatureChat})
+}
+
+func TestLLMBudget_RefusesOverLimit(t *testing.T) {
+	svc, spend, recorder, client := newBudgetFixture(services.LLMBudgetConfig{DailyLimitUSD: 1, MonthlyLimitUSD: 10})
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	svc.SetNow(func() time.Time { return now })
+
+	spend.daily[1] = 0.5
+	_, err := client.GenerateText(userCtx(1), "", "q", domainai.WithModel("gpt-4o"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o"}, recorder.models)
+
+	// 利用額は TTL の間キャッシュし、経過後に読み直す
+	spend.daily[1] = 1.2
+	_, err = client.GenerateText(userCtx(1), "", "q")
+	require.NoError(t, err)
+	now = now.Add(time.Minute)
+	_, err = client.GenerateText(userCtx(1), "", "q")
+	assert.ErrorIs(t, err, domainai.ErrBudgetExceeded)
+	_, err = client.GenerateEmbedding(userCtx(1), "q")
+	assert.ErrorIs(t, err, domainai.ErrBudgetExceeded)
+	assert.Len(t, recorder.models, 2)
+
+	// 月額の上限でも止める
+	spend.monthly[2] = 10
+	_, err = client.GenerateText(userCtx(2), "", "q")
+	assert.ErrorIs(t, err, domainai.ErrBudgetExceeded)
+
+	// 利用者に紐づかない呼び出しは止めない
+	_, err = client.GenerateText(domainai.WithFeature(context.Background(), domainai.FeatureCrawl), "", "q")
+	require.NoError(t, err)
+}
+
+func TestLLMBudget_OverrideDowngradesModel(t *testing.T) {
+	svc, spend, recorder, client := newBudgetFixture(services.LLMBudgetConfig{DailyLimitUSD: 1, DowngradeModel: "gpt-4o-mini"})
+	spend.daily[3] = 2
+
+	_, err := client.GenerateText(userCtx(3), "", "q")
+	require.ErrorIs(t, err, domainai.ErrBudgetExceeded)
+
+	// 上書き設定で上限を引き上げ、超過時は格下げにする
+	_, err = svc.SetOverride(3, 5, 0, services.BudgetActionDowngrade, "admin@example.com")
+	require.NoError(t, err)
+	_, err = client.GenerateText(userCtx(3), "", "q", domainai.WithModel("gpt-4o"))
+	require.NoError(t, err)
+	spend.daily[3] = 6
+	status, err := svc.Status(3)
+	require.NoError(t, err)
+	assert.True(t, status.Override)
+	assert.True(t, status.Exceeded)
+	_, err = client.GenerateText(userCtx(3), "", "q", domainai.WithModel("gpt-4o"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, recorder.models)
+
+	// 上書きを削除すると既定の予算（拒否）に戻る
+	require.NoError(t, svc.DeleteOverride(3))
+	_, err = client.GenerateText(userCtx(3), "", "q")
+	assert.ErrorIs(t, err, domainai.ErrBudgetExceeded)
+}
+
+func TestLLMBudget_UnlimitedSkipsSpendQuery(t *testing.T) {
+	svc, spend, _, client := newBudgetFixture(services.LLMBudgetConfig{})
+	_, err := client.GenerateText(userCtx(4), "", "q")
+	require.NoError(t, err)
+	assert.Zero(t, spend.calls, "上限がなければ利用額を集計しない")
+
+	_, err = svc.SetOverride(4, -1, 0, "", "a")
+	assert.ErrorIs(t, err, services.ErrInvalidBudget)
+	_, err = svc.SetOverride(4, 1, 0, "block", "a")
+	assert.ErrorIs(t, err, services.ErrInvalidBudget)
+	_, err = svc.SetOverride(4, 1, 0, services.BudgetActionDowngrade, "a")
+	assert.ErrorIs(t, err, services.ErrInvalidBudget, "格下げ先のモデルが未設定なら格下げにできない")
+}
+
+func TestCallContext_Merges(t *testing.T) {
+	ctx := domainai.WithCallContext(context.Background(), domainai.CallContext{RequestID: "req-1"})
+	ctx = domainai.WithCallContext(ctx, domainai.CallContext{UserID: 9})
+	ctx = domainai.WithFeature(ctx, domainai.FeatureResumeReview)
+	assert.Equal(t, domainai.CallContext{UserID: 9, Feature: domainai.FeatureResumeReview, RequestID: "req-1"}, domainai.CallContextFrom(ctx))
+}
@@ -0,0 +1,134 @@
+package services_test
+
+// LLM 応答キャッシュのテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run LLMCache -v
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domainai "Backend/domain/ai"
+	internalai "Backend/internal/ai"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ internalai.CacheStore = (*internalai.MemoryCacheStore)(nil)
+var _ internalai.CacheStore = (*internalai.DBCacheStore)(nil)
+var _ services.LLMResponseCache = (*internalai.CachingAdapter)(nil)
+
+func TestLLMCache_OnlyMarkedCallsAreCached(t *testing.T) {
+	inner := newCountingLLM(`{"a":1}`)
+	cache := internalai.NewCachingAdapter(inner, internalai.NewMemoryCacheStore(10))
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := cache.GenerateJSON(ctx, "s", "u")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, inner.calls, "WithCache なしの呼び出しはキャッシュしない")
+
+	cached := domainai.WithCache(ctx, "repo_summary", time.Hour)
+	for i := 0; i < 3; i++ {
+		out, err := cache.GenerateJSON(cached, "s", "u", domainai.WithTemperature(0.5))
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, out)
+	}
+	assert.Equal(t, 3, inner.calls)
+
+	// 生成パラメータが違えば別キー
+	_, err := cache.GenerateJSON(cached, "s", "u", domainai.WithTemperature(0.9))
+	require.NoError(t, err)
+	assert.Equal(t, 4, inner.calls)
+
+	stats := cache.CacheStats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "repo_summary", stats[0].Scope)
+	assert.EqualValues(t, 2, stats[0].Hits)
+	assert.EqualValues(t, 2, stats[0].Misses)
+	assert.InDelta(t, 0.5, stats[0].HitRate, 1e-9)
+}
+
+func TestLLMCache_EmbeddingsAndPurgeByScope(t *testing.T) {
+	inner := newCountingLLM(`{}`)
+	cache := internalai.NewCachingAdapter(inner, internalai.NewMemoryCacheStore(10))
+
+	embedCtx := domainai.WithCache(context.Background(), "job_category_embedding", time.Hour)
+	vec, err := cache.GenerateEmbedding(embedCtx, "バックエンドエンジニア")
+	require.NoError(t, err)
+	vec2, err := cache.GenerateEmbedding(embedCtx, "バックエンドエンジニア")
+	require.NoError(t, err)
+	assert.Equal(t, vec, vec2)
+	assert.Equal(t, 1, inner.calls)
+
+	jsonCtx := domainai.WithCache(context.Background(), "repo_summary", time.Hour)
+	_, err = cache.GenerateJSON(jsonCtx, "s", "u")
+	require.NoError(t, err)
+
+	n, err := cache.PurgeCache(context.Background(), "job_category_embedding")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+
+	_, err = cache.GenerateEmbedding(embedCtx, "バックエンドエンジニア")
+	require.NoError(t, err)
+	_, err = cache.GenerateJSON(jsonCtx, "s", "u")
+	require.NoError(t, err)
+	assert.Equal(t, 3, inner.calls, "削除した scope だけ再計算されること")
+}
+
+func TestLLMCache_ErrorsAreNotCached(t *testing.T) {
+	inner := internalai.NewFallbackAdapter() // WebSearch はエラーを返す
+	store := internalai.NewMemoryCacheStore(10)
+	cache := internalai.NewCachingAdapter(inner, store)
+
+	ctx := domainai.WithCache(context.Background(), "company_reading", time.Hour)
+	_, err := cache.WebSearch(ctx, "株式会社テスト")
+	assert.Error(t, err)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemoryCacheStore_LRUAndExpiry(t *testing.T) {
+	store := internalai.NewMemoryCacheStore(2)
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "a", "s", []byte("1"), time.Hour))
+	require.NoError(t, store.Set(ctx, "b", "s", []byte("2"), time.Hour))
+	_, ok, _ := store.Get(ctx, "a") // a を最近使ったものにする
+	require.True(t, ok)
+	require.NoError(t, store.Set(ctx, "c", "s", []byte("3"), time.Hour))
+
+	_, ok, _ = store.Get(ctx, "b")
+	assert.False(t, ok, "最も古く使われた b が追い出されること")
+	_, ok, _ = store.Get(ctx, "a")
+	assert.True(t, ok)
+
+	require.NoError(t, store.Set(ctx, "d", "s", []byte("4"), time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, ok, _ = store.Get(ctx, "d")
+	assert.False(t, ok, "期限切れは返さないこと")
+}
+
+func TestAPICostService_ResponseCacheStats(t *testing.T) {
+	svc := services.NewAPICostService(nil, nil)
+	assert.Empty(t, svc.GetCacheStats())
+	_, err := svc.PurgeResponseCache(context.Background(), "")
+	assert.True(t, errors.Is(err, services.ErrResponseCacheDisabled))
+
+	cache := internalai.NewCachingAdapter(newCountingLLM(`{}`), internalai.NewMemoryCacheStore(10))
+	svc.SetResponseCache(cache)
+	ctx := domainai.WithCache(context.Background(), "company_profile", time.Hour)
+	_, _ = cache.GenerateJSON(ctx, "s", "u")
+	_, _ = cache.GenerateJSON(ctx, "s", "u")
+
+	stats := svc.GetCacheStats()
+	require.Len(t, stats, 1)
+	assert.EqualValues(t, 1, stats[0].Hits)
+	n, err := svc.PurgeResponseCache(context.Background(), "")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+}
@@ -0,0 +1,324 @@
+package services_test
+
+// 記録済みカセットで LLM を使うサービスをネットワークなしにエンドツーエンドで実行するテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Cassette -v
+//
+// どのテストも strict モードで再生するので、プロンプトを変えるとフィクスチャにない呼び出しとして失敗する。
+// その場合は testdata/llm_cassettes のフィクスチャを記録し直してプロンプトの変更と一緒にコミットする。
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domainai "Backend/domain/ai"
+	"Backend/domain/entity"
+	"Backend/domain/repository"
+	internalai "Backend/internal/ai"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cassetteDir = "testdata/llm_cassettes"
+
+// newStrictCassette は testdata/llm_cassettes を strict モードで再生するクライアントを返す
+func newStrictCassette(t *testing.T) domainai.LLMClient {
+	t.Helper()
+	player, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: cassetteDir, Mode: internalai.CassetteStrict})
+	require.NoError(t, err)
+	return player
+}
+
+// ─── チャット ─────────────────────────────────────────────────────────────────
+
+// memoryChatMessageRepo はメッセージをメモリ上に保持する ChatMessageRepository モック。
+type memoryChatMessageRepo struct {
+	repository.ChatMessageRepository
+	messages []models.ChatMessage
+}
+
+func (m *memoryChatMessageRepo) Create(msg *models.ChatMessage) error {
+	m.messages = append(m.messages, *msg)
+	return nil
+}
+
+func (m *memoryChatMessageRepo) FindRecentBySessionID(sessionID string, limit int) ([]models.ChatMessage, error) {
+	var found []models.ChatMessage
+	for _, msg := range m.messages {
+		if msg.SessionID == sessionID {
+			found = append(found, msg)
+		}
+	}
+	if len(found) > limit {
+		found = found[len(found)-limit:]
+	}
+	return found, nil
+}
+
+// memoryScoreRepo はスコアをメモリ上に保持する UserWeightScoreRepository モック。
+type memoryScoreRepo struct {
+	repository.UserWeightScoreRepository
+	mu     sync.Mutex
+	scores map[string]int
+}
+
+func (m *memoryScoreRepo) UpdateScore(_ uint, _, category string, scoreIncrement int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scores[category] += scoreIncrement
+	return nil
+}
+
+func (m *memoryScoreRepo) FindByUserAndSession(userID uint, sessionID string) ([]entity.UserWeightScore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var scores []entity.UserWeightScore
+	for category, score := range m.scores {
+		scores = append(scores, entity.UserWeightScore{UserID: userID, SessionID: sessionID, WeightCategory: category, Score: score})
+	}
+	return scores, nil
+}
+
+func (m *memoryScoreRepo) FindByUserSessionAndCategory(userID uint, sessionID, category string) (*entity.UserWeightScore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	score, ok := m.scores[category]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &entity.UserWeightScore{UserID: userID, SessionID: sessionID, WeightCategory: category, Score: score}, nil
+}
+
+// singlePhaseRepo はフェーズを 1 つだけ持つ AnalysisPhaseRepository モック。
+type singlePhaseRepo struct {
+	repository.AnalysisPhaseRepository
+}
+
+func (singlePhaseRepo) FindAll() ([]entity.AnalysisPhase, error) {
+	return []entity.AnalysisPhase{{ID: 1, PhaseName: "values_analysis", DisplayName: "価値観", MinQuestions: 3, MaxQuestions: 5}}, nil
+}
+func (singlePhaseRepo) FindByName(string) (*entity.AnalysisPhase, error) { return nil, nil }
+
+// memoryProgressRepo はフェーズ進捗をメモリ上に保持する UserAnalysisProgressRepository モック。
+type memoryProgressRepo struct {
+	repository.UserAnalysisProgressRepository
+	progress map[uint]*entity.UserAnalysisProgress
+}
+
+func (m *memoryProgressRepo) FindByUserAndSession(uint, string) ([]entity.UserAnalysisProgress, error) {
+	var list []entity.UserAnalysisProgress
+	for _, p := range m.progress {
+		list = append(list, *p)
+	}
+	return list, nil
+}
+
+func (m *memoryProgressRepo) FindOrCreate(userID uint, sessionID string, phaseID uint) (*entity.UserAnalysisProgress, error) {
+	if p, ok := m.progress[phaseID]; ok {
+		copied := *p
+		return &copied, nil
+	}
+	m.progress[phaseID] = &entity.UserAnalysisProgress{UserID: userID, SessionID: sessionID, PhaseID: phaseID}
+	copied := *m.progress[phaseID]
+	return &copied, nil
+}
+
+func (m *memoryProgressRepo) Update(progress *entity.UserAnalysisProgress) error {
+	copied := *progress
+	m.progress[progress.PhaseID] = &copied
+	return nil
+}
+
+// memorySessionValidationRepo は無効回答の回数を保持する SessionValidationRepository モック。
+type memorySessionValidationRepo struct {
+	repository.SessionValidationRepository
+	validation models.SessionValidation
+}
+
+func (m *memorySessionValidationRepo) IsTerminated(string) (bool, error) {
+	return m.validation.IsTerminated, nil
+}
+func (m *memorySessionValidationRepo) GetOrCreate(string) (*models.SessionValidation, error) {
+	copied := m.validation
+	return &copied, nil
+}
+func (m *memorySessionValidationRepo) IncrementInvalidCount(string) (*models.SessionValidation, error) {
+	m.validation.InvalidAnswerCount++
+	return m.GetOrCreate("")
+}
+func (m *memorySessionValidationRepo) ResetInvalidCount(string) error {
+	m.validation.InvalidAnswerCount = 0
+	return nil
+}
+func (m *memorySessionValidationRepo) TerminateSession(string) error {
+	m.validation.IsTerminated = true
+	return nil
+}
+
+// emptyAIQuestionRepo は生成済みの質問を持たない AIGeneratedQuestionRepository モック。
+type emptyAIQuestionRepo struct {
+	repository.AIGeneratedQuestionRepository
+}
+
+func (emptyAIQuestionRepo) FindByUserAndSession(uint, string) ([]models.AIGeneratedQuestion, error) {
+	return nil, nil
+}
+func (emptyAIQuestionRepo) Create(*models.AIGeneratedQuestion) error { return nil }
+
+// emptyPredefinedQuestionRepo は事前定義の質問を持たない PredefinedQuestionRepository モック（AI 生成にフォールバックさせる）。
+type emptyPredefinedQuestionRepo struct {
+	repository.PredefinedQuestionRepository
+}
+
+func (emptyPredefinedQuestionRepo) FindActiveQuestions(string, *uint, *uint, string) ([]*models.PredefinedQuestion, error) {
+	return nil, nil
+}
+
+// unknownJobCategoryRepo は職種を持たない JobCategoryRepository モック。
+type unknownJobCategoryRepo struct {
+	repository.JobCategoryRepository
+}
+
+func (unknownJobCategoryRepo) FindByID(uint) (*models.JobCategory, error) { return nil, nil }
+
+// chatUserRepo は中途の利用者を 1 人返す UserRepository モック。
+type chatUserRepo struct {
+	repository.UserRepository
+}
+
+func (chatUserRepo) GetUserByID(id uint) (*entity.User, error) {
+	return &entity.User{ID: id, TargetLevel: "中途"}, nil
+}
+
+// newCassetteChatService は LLM 以外をメモリ上のモックにした ChatService を返す
+func newCassetteChatService(llm domainai.LLMClient, messages *memoryChatMessageRepo, scores *memoryScoreRepo) *services.ChatService {
+	return services.NewChatService(
+		llm,
+		nil,
+		messages,
+		scores,
+		emptyAIQuestionRepo{},
+		emptyPredefinedQuestionRepo{},
+		unknownJobCategoryRepo{},
+		chatUserRepo{},
+		nil,
+		nil,
+		singlePhaseRepo{},
+		&memoryProgressRepo{progress: map[uint]*entity.UserAnalysisProgress{}},
+		&memorySessionValidationRepo{},
+		nil,
+	)
+}
+
+// cassetteChatHistory は直前に AI が 1 問だけ質問した状態のセッション
+func cassetteChatHistory() *memoryChatMessageRepo {
+	return &memoryChatMessageRepo{messages: []models.ChatMessage{{
+		SessionID: "cassette-chat",
+		UserID:    7,
+		Role:      "assistant",
+		Content:   "これまでに最も力を入れて取り組んだことについて、具体的に教えてください。",
+	}}}
+}
+
+const (
+	cassetteChatAnswer   = "大学のゼミで共同研究のリーダーを務め、メンバーの意見を整理して週次で進捗を共有し、論文を期限内に仕上げました。"
+	cassetteChatQuestion = "意見の異なるメンバーと協力して成果を出した経験について、あなたがどのように調整したかを具体的に教えてください。"
+)
+
+func TestCassette_ProcessChatFromFixture(t *testing.T) {
+	messages := cassetteChatHistory()
+	scores := &memoryScoreRepo{scores: map[string]int{}}
+	svc := newCassetteChatService(newStrictCassette(t), messages, scores)
+
+	resp, err := svc.ProcessChat(context.Background(), services.ChatRequest{
+		UserID:        7,
+		SessionID:     "cassette-chat",
+		Message:       cassetteChatAnswer,
+		JobCategoryID: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, cassetteChatQuestion, resp.Response)
+
+	require.Len(t, messages.messages, 3, "回答と次の質問が保存されること")
+	assert.Equal(t, "user", messages.messages[1].Role)
+	assert.Equal(t, cassetteChatAnswer, messages.messages[1].Content)
+	assert.Equal(t, cassetteChatQuestion, messages.messages[2].Content)
+	assert.Equal(t, map[string]int{"技術志向": 35}, scores.scores)
+}
+
+// ─── 面接レポート ─────────────────────────────────────────────────────────────
+
+// memoryInterviewReportRepo は保存されたレポートを通知する InterviewReportRepository モック。
+type memoryInterviewReportRepo struct {
+	saved chan *models.InterviewReport
+}
+
+func (m *memoryInterviewReportRepo) FindBySessionID(uint) (*models.InterviewReport, error) {
+	return nil, errors.New("record not found")
+}
+
+func (m *memoryInterviewReportRepo) Upsert(report *models.InterviewReport) error {
+	m.saved <- report
+	return nil
+}
+
+func TestCassette_InterviewReportFromFixture(t *testing.T) {
+	t.Setenv("INTERVIEW_REPORT_MODEL", "")
+
+	sessionRepo := &mockInterviewSessionRepo{session: &models.InterviewSession{ID: 3, UserID: 1, Language: "ja"}}
+	utterRepo := &mockInterviewUtterRepo{utterances: []models.InterviewUtterance{
+		{Role: "ai", Text: "学生時代に力を入れたことを教えてください。"},
+		{Role: "user", Text: "飲食店のアルバイトで新人教育の仕組みを作り、3か月で離職者をゼロにしました。"},
+		{Role: "ai", Text: "その仕組みを作ろうと思ったきっかけは何ですか？"},
+		{Role: "user", Text: "新人が1か月以内に辞めてしまうことが続き、店長に提案して手順書と面談の場を用意しました。"},
+	}}
+	reportRepo := &memoryInterviewReportRepo{saved: make(chan *models.InterviewReport, 1)}
+	svc := services.NewInterviewService(sessionRepo, utterRepo, reportRepo, &mockUserRepo2{}, nil, newStrictCassette(t), nil)
+	svc.StartWorker()
+
+	_, err := svc.FinishSession(1, 3)
+	require.NoError(t, err)
+
+	var report *models.InterviewReport
+	select {
+	case report = <-reportRepo.saved:
+	case <-time.After(5 * time.Second):
+		t.Fatal("レポートが保存されなかった（フィクスチャにないプロンプトで生成に失敗した可能性がある）")
+	}
+	assert.Equal(t, uint(3), report.SessionID)
+	assert.Contains(t, report.SummaryText, "仕組みを作って成果につなげた")
+	assert.JSONEq(t, `{"logic":4,"specificity":4,"ownership":5,"communication":3,"enthusiasm":4}`, report.ScoresJSON)
+	assert.True(t, strings.HasPrefix(report.PromptVersion, "interview_report@"), report.PromptVersion)
+}
+
+// ─── 履歴書レビュー ───────────────────────────────────────────────────────────
+
+var cassetteResumeBlocks = []models.ResumeTextBlock{
+	{PageNumber: 1, BlockIndex: 1, Text: "自己PR: 私の強みは粘り強さです。", BBox: "[20,20,260,40]"},
+	{PageNumber: 1, BlockIndex: 2, Text: "学生時代に力を入れたこと: テニスサークルの副代表としてイベントを運営しました。", BBox: "[20,50,260,70]"},
+	{PageNumber: 1, BlockIndex: 3, Text: "志望動機: 貴社の理念に共感したため志望しました。", BBox: "[20,80,260,100]"},
+}
+
+func TestCassette_ResumeReviewFromFixture(t *testing.T) {
+	t.Setenv("RAG_REVIEW_URL", "")
+	t.Setenv("OPENAI_REVIEW_MODEL", "")
+
+	svc := services.NewResumeService(nil, t.TempDir(), newStrictCassette(t))
+
+	review, items, err := svc.ReviewTextBlocks(context.Background(), cassetteResumeBlocks, "サンプル商事", "営業職", "新卒")
+	require.NoError(t, err)
+	assert.Equal(t, 62, review.Score)
+	assert.True(t, strings.HasPrefix(review.PromptVersion, "resume_review@"), review.PromptVersion)
+	require.Len(t, items, 3, "指摘がすべて履歴書のブロックに紐づくこと")
+	for i, item := range items {
+		assert.Equal(t, cassetteResumeBlocks[i].BBox, item.BBox)
+	}
+	assert.Equal(t, []string{"warning", "info", "critical"}, []string{items[0].Severity, items[1].Severity, items[2].Severity})
+}
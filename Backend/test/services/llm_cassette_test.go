@@ -0,0 +1,185 @@
+package services_test
+
+// LLM カセット（記録・再生）アダプターのテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Cassette -v
+//
+// testdata/llm_cassettes のフィクスチャを更新する場合は、実プロバイダの設定をしたうえで
+// LLM_CASSETTE_DIR=test/services/testdata/llm_cassettes LLM_CASSETTE_MODE=record でサーバーを動かして記録する。
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	domainai "Backend/domain/ai"
+	internalai "Backend/internal/ai"
+	"Backend/internal/config"
+	"Backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ domainai.LLMClient = (*internalai.CassetteAdapter)(nil)
+
+// countingLLM は呼び出し回数を数えるスタブ。応答は FallbackAdapter に委譲し、JSON と埋め込みだけ固定値を返す。
+type countingLLM struct {
+	domainai.LLMClient
+	calls int
+	json  string
+}
+
+func newCountingLLM(json string) *countingLLM {
+	return &countingLLM{LLMClient: internalai.NewFallbackAdapter(), json: json}
+}
+
+func (c *countingLLM) GenerateJSON(_ context.Context, _, _ string, _ ...domainai.CallOption) (string, error) {
+	c.calls++
+	return c.json, nil
+}
+
+func (c *countingLLM) Chat(ctx context.Context, messages []domainai.Message, opts ...domainai.CallOption) (string, error) {
+	c.calls++
+	return "次の質問です。" + messages[len(messages)-1].Content, nil
+}
+
+func (c *countingLLM) GenerateEmbedding(_ context.Context, _ string) ([]float32, error) {
+	c.calls++
+	return []float32{0.1, 0.2, 0.3}, nil
+}
+
+func TestCassette_RecordThenStrictReplay(t *testing.T) {
+	dir := t.TempDir()
+	inner := newCountingLLM(`{"ok":true}`)
+	recorder, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: dir, Mode: internalai.CassetteRecord, Inner: inner})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = recorder.GenerateJSON(ctx, "system", "user prompt", domainai.WithModel("m1"))
+	require.NoError(t, err)
+	_, err = recorder.Chat(ctx, []domainai.Message{{Role: domainai.RoleUser, Content: "こんにちは"}})
+	require.NoError(t, err)
+	_, err = recorder.GenerateEmbedding(ctx, "embed me")
+	require.NoError(t, err)
+	assert.Equal(t, 3, inner.calls)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.Len(t, files, 3, "1 呼び出し 1 ファイルで記録されること")
+
+	player, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: dir, Mode: internalai.CassetteStrict})
+	require.NoError(t, err)
+	assert.Equal(t, 3, player.Len())
+
+	// 空白・改行の揺れは同じプロンプトとして扱う
+	out, err := player.GenerateJSON(ctx, "  system\n", "user\n  prompt ", domainai.WithModel("m1"), domainai.WithTemperature(0.9))
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, out)
+
+	reply, err := player.Chat(ctx, []domainai.Message{{Role: domainai.RoleUser, Content: "こんにちは"}})
+	require.NoError(t, err)
+	assert.Equal(t, "次の質問です。こんにちは", reply)
+
+	vec, err := player.GenerateEmbedding(ctx, "embed me")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.1, 0.2, 0.3}, vec)
+}
+
+func TestCassette_StrictModeFailsOnUnknownPrompt(t *testing.T) {
+	player, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: t.TempDir(), Mode: internalai.CassetteStrict})
+	require.NoError(t, err)
+
+	_, err = player.GenerateJSON(context.Background(), "system", "unknown prompt")
+	assert.ErrorIs(t, err, internalai.ErrCassetteMiss)
+	assert.Contains(t, err.Error(), "unknown prompt")
+
+	// モデルが違えば別のプロンプトとして扱う
+	dir := t.TempDir()
+	recorder, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: dir, Mode: internalai.CassetteRecord, Inner: newCountingLLM(`{}`)})
+	require.NoError(t, err)
+	_, err = recorder.GenerateJSON(context.Background(), "s", "u", domainai.WithModel("m1"))
+	require.NoError(t, err)
+	player, err = internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: dir, Mode: internalai.CassetteStrict})
+	require.NoError(t, err)
+	_, err = player.GenerateJSON(context.Background(), "s", "u", domainai.WithModel("m2"))
+	assert.ErrorIs(t, err, internalai.ErrCassetteMiss)
+}
+
+func TestCassette_ReplayModeRecordsMissesOnce(t *testing.T) {
+	inner := newCountingLLM(`{"n":1}`)
+	adapter, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: t.TempDir(), Mode: internalai.CassetteReplay, Inner: inner})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		out, err := adapter.GenerateJSON(context.Background(), "s", "u")
+		require.NoError(t, err)
+		assert.Equal(t, `{"n":1}`, out)
+	}
+	assert.Equal(t, 1, inner.calls, "2 回目以降は記録から再生されること")
+}
+
+func TestCassette_NormalizeHook(t *testing.T) {
+	dir := t.TempDir()
+	datePattern := regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+	stripDate := func(s string) string { return datePattern.ReplaceAllString(s, "<date>") }
+	recorder, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: dir, Mode: internalai.CassetteRecord, Inner: newCountingLLM(`{}`), Normalize: stripDate})
+	require.NoError(t, err)
+	_, err = recorder.GenerateJSON(context.Background(), "", "today is 2026-01-01")
+	require.NoError(t, err)
+
+	// 日付が変わっても同じプロンプトとして再生される
+	player, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: dir, Mode: internalai.CassetteStrict, Normalize: stripDate})
+	require.NoError(t, err)
+	_, err = player.GenerateJSON(context.Background(), "", "today is 2026-02-02")
+	assert.NoError(t, err)
+}
+
+func TestCassette_ConfigValidation(t *testing.T) {
+	_, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Mode: internalai.CassetteStrict})
+	assert.Error(t, err, "ディレクトリ未指定はエラー")
+	_, err = internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: t.TempDir(), Mode: internalai.CassetteRecord})
+	assert.Error(t, err, "record モードは実プロバイダが必須")
+	_, err = internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: t.TempDir(), Mode: "rewind"})
+	assert.Error(t, err)
+
+	// 壊れたフィクスチャは読み込み時にエラー
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644))
+	_, err = internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: dir, Mode: internalai.CassetteStrict})
+	assert.Error(t, err)
+}
+
+func TestCassette_RegistryStrictModeNeedsNoProvider(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	client, err := internalai.NewRegistry().New(config.LLMConfig{
+		Provider:     internalai.ProviderOpenAI,
+		CassetteDir:  t.TempDir(),
+		CassetteMode: internalai.CassetteStrict,
+	}, nil)
+	require.NoError(t, err, "strict モードでは API キーなしで生成できること")
+	_, err = client.GenerateText(context.Background(), "", "hello")
+	assert.ErrorIs(t, err, internalai.ErrCassetteMiss)
+}
+
+// 記録済みフィクスチャでサービスをネットワークなしにエンドツーエンドで実行する
+func TestCassette_PhraseSuggestionsFromFixture(t *testing.T) {
+	player, err := internalai.NewCassetteAdapter(internalai.CassetteConfig{Dir: "testdata/llm_cassettes", Mode: internalai.CassetteStrict})
+	require.NoError(t, err)
+
+	sessionRepo := &mockInterviewSessionRepo{session: &models.InterviewSession{ID: 1, UserID: 1}}
+	utterRepo := &mockInterviewUtterRepo{utterances: []models.InterviewUtterance{
+		{Role: "ai", Text: "学生時代に力を入れたことを教えてください。"},
+		{Role: "user", Text: "サークルでいろいろ頑張りました。"},
+		{Role: "ai", Text: "具体的には？"},
+		{Role: "user", Text: "イベントの運営をなんとかやりました。"},
+	}}
+	svc := newTestInterviewService(sessionRepo, utterRepo, &mockUserRepo2{}, player)
+
+	result, err := svc.GetPhraseSuggestions(context.Background(), 1, 1)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "サークルでいろいろ頑張りました", result[0].Original)
+	assert.NotEmpty(t, result[1].Suggestions)
+}
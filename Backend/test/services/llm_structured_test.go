@@ -0,0 +1,157 @@
+package services_test
+
+// スキーマ検証付き構造化出力のテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Structured -v
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	domainai "Backend/domain/ai"
+	internalai "Backend/internal/ai"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type structuredReview struct {
+	Score    int      `json:"score" minimum:"0" maximum:"100"`
+	Severity string   `json:"severity" enum:"info,warning,critical"`
+	Tags     []string `json:"tags" minItems:"1"`
+	Note     *string  `json:"note,omitempty" description:"補足"`
+}
+
+// scriptedLLM は GenerateJSON で responses を順に返し、受け取ったプロンプトと応答形式を記録するスタブ。
+type scriptedLLM struct {
+	domainai.LLMClient
+	responses []string
+	prompts   []string
+	schemas   []*domainai.ResponseSchema
+}
+
+func newScriptedLLM(responses ...string) *scriptedLLM {
+	return &scriptedLLM{LLMClient: internalai.NewFallbackAdapter(), responses: responses}
+}
+
+func (s *scriptedLLM) GenerateJSON(_ context.Context, _, userPrompt string, opts ...domainai.CallOption) (string, error) {
+	s.prompts = append(s.prompts, userPrompt)
+	s.schemas = append(s.schemas, domainai.ApplyCallOptions(opts...).ResponseSchema)
+	out := s.responses[0]
+	if len(s.responses) > 1 {
+		s.responses = s.responses[1:]
+	}
+	return out, nil
+}
+
+func TestStructured_SchemaFor(t *testing.T) {
+	schema, strict, err := domainai.SchemaFor(reflect.TypeOf(structuredReview{}))
+	require.NoError(t, err)
+	assert.True(t, strict)
+
+	raw, err := json.Marshal(schema)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &doc))
+	assert.Equal(t, "object", doc["type"])
+	assert.Equal(t, false, doc["additionalProperties"])
+	assert.ElementsMatch(t, []interface{}{"score", "severity", "tags", "note"}, doc["required"])
+
+	props := doc["properties"].(map[string]interface{})
+	assert.Equal(t, "integer", props["score"].(map[string]interface{})["type"])
+	assert.EqualValues(t, 100, props["score"].(map[string]interface{})["maximum"])
+	assert.Equal(t, []interface{}{"info", "warning", "critical"}, props["severity"].(map[string]interface{})["enum"])
+	note := props["note"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"string", "null"}, note["type"], "omitempty は null 許容")
+	assert.Equal(t, "補足", note["description"])
+
+	// map を含む型は strict にできない
+	_, strict, err = domainai.SchemaFor(reflect.TypeOf(struct {
+		Scores map[string]int `json:"scores"`
+	}{}))
+	require.NoError(t, err)
+	assert.False(t, strict)
+}
+
+func TestStructured_Validate(t *testing.T) {
+	schema, _, err := domainai.SchemaFor(reflect.TypeOf(structuredReview{}))
+	require.NoError(t, err)
+
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"score":120,"severity":"fatal","tags":[]}`), &v))
+	assert.ElementsMatch(t, []string{
+		"$.score: 120 is greater than maximum 100",
+		`$.severity: "fatal" is not one of info, warning, critical`,
+		"$.tags: expected at least 1 items, got 0",
+	}, schema.Validate(v))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"score":"80","tags":["a",1]}`), &v))
+	assert.ElementsMatch(t, []string{
+		"$.score: expected integer, got string",
+		"$.severity: required property is missing",
+		"$.tags[1]: expected string, got number",
+	}, schema.Validate(v))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"score":80,"severity":"info","tags":["a"],"note":null}`), &v))
+	assert.Empty(t, schema.Validate(v))
+}
+
+func TestStructured_RepairsInvalidOutput(t *testing.T) {
+	llm := newScriptedLLM(
+		"```json\n{\"score\": 120, \"severity\": \"info\", \"tags\": [\"a\"]}\n```",
+		`{"score": 80, "severity": "warning", "tags": ["a", "b"]}`,
+	)
+
+	out, err := domainai.GenerateStructured[structuredReview](context.Background(), llm, "sys", "レビューしてください")
+	require.NoError(t, err)
+	assert.Equal(t, 80, out.Score)
+	assert.Equal(t, "warning", out.Severity)
+	assert.Nil(t, out.Note)
+
+	require.Len(t, llm.prompts, 2)
+	assert.Equal(t, "レビューしてください", llm.prompts[0])
+	assert.Contains(t, llm.prompts[1], "レビューしてください")
+	assert.Contains(t, llm.prompts[1], "$.score: 120 is greater than maximum 100", "再生成の依頼に検証エラーを添える")
+	require.NotNil(t, llm.schemas[0])
+	assert.Equal(t, "structuredReview", llm.schemas[0].Name)
+	assert.True(t, llm.schemas[0].Strict)
+}
+
+func TestStructured_FailsAfterRetries(t *testing.T) {
+	llm := newScriptedLLM(`not json`)
+
+	_, err := domainai.GenerateStructured[structuredReview](context.Background(), llm, "sys", "u", domainai.WithRepairRetries(1))
+	require.ErrorIs(t, err, domainai.ErrInvalidStructuredOutput)
+	assert.Len(t, llm.prompts, 2)
+}
+
+func TestStructured_CompatibleAdapterSendsJSONSchema(t *testing.T) {
+	var format map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]interface{}
+		_ = json.Unmarshal(body, &req)
+		format, _ = req["response_format"].(map[string]interface{})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"score\":90,\"severity\":\"info\",\"tags\":[\"go\"]}"}}]}`))
+	}))
+	defer srv.Close()
+
+	client, err := internalai.NewCompatibleAdapter(internalai.CompatibleConfig{BaseURL: srv.URL + "/v1", Model: "local"})
+	require.NoError(t, err)
+	out, err := domainai.GenerateStructured[structuredReview](context.Background(), client, "sys", "u")
+	require.NoError(t, err)
+	assert.Equal(t, 90, out.Score)
+
+	require.NotNil(t, format)
+	assert.Equal(t, "json_schema", format["type"])
+	jsonSchema := format["json_schema"].(map[string]interface{})
+	assert.Equal(t, "structuredReview", jsonSchema["name"])
+	assert.Equal(t, true, jsonSchema["strict"])
+	assert.Equal(t, "object", jsonSchema["schema"].(map[string]interface{})["type"])
+}
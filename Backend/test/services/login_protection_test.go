@@ -0,0 +1,227 @@
+package services_test
+
+// ログイン試行制限（AuthService.Login）のユニットテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Login -v
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"Backend/domain/entity"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mockLoginUserRepo は 1 ユーザーだけを保持する UserRepository モック。
+type mockLoginUserRepo struct {
+	repository.UserRepository
+	user *entity.User
+}
+
+func (m *mockLoginUserRepo) GetUserByEmail(email string) (*entity.User, error) {
+	if m.user != nil && m.user.Email == email {
+		u := *m.user
+		return &u, nil
+	}
+	return nil, nil
+}
+func (m *mockLoginUserRepo) GetUserByID(id uint) (*entity.User, error) {
+	if m.user != nil && m.user.ID == id {
+		u := *m.user
+		return &u, nil
+	}
+	return nil, nil
+}
+func (m *mockLoginUserRepo) GetUserByUnlockToken(token string) (*entity.User, error) {
+	if m.user != nil && token != "" && m.user.UnlockToken == token {
+		u := *m.user
+		return &u, nil
+	}
+	return nil, nil
+}
+func (m *mockLoginUserRepo) UpdateUser(u *entity.User) error {
+	saved := *u
+	m.user = &saved
+	return nil
+}
+func (m *mockLoginUserRepo) IncrementFailedLogin(userID uint, at time.Time) (int, error) {
+	m.user.FailedLoginCount++
+	m.user.LastFailedLoginAt = &at
+	return m.user.FailedLoginCount, nil
+}
+func (m *mockLoginUserRepo) UpdateLockout(u *entity.User) error {
+	m.user.FailedLoginCount = u.FailedLoginCount
+	m.user.LastFailedLoginAt = u.LastFailedLoginAt
+	m.user.LockedUntil = u.LockedUntil
+	m.user.UnlockToken = u.UnlockToken
+	m.user.SessionsRevokedAt = u.SessionsRevokedAt
+	return nil
+}
+
+// mockLoginAttemptRepo は試行履歴をメモリ上に保持する LoginAttemptRepository モック。
+type mockLoginAttemptRepo struct {
+	attempts []models.LoginAttempt
+}
+
+func (m *mockLoginAttemptRepo) Create(a *models.LoginAttempt) error {
+	a.CreatedAt = time.Now()
+	m.attempts = append(m.attempts, *a)
+	return nil
+}
+func (m *mockLoginAttemptRepo) CountFailuresByIP(ip string, since time.Time) (int64, error) {
+	var n int64
+	for _, a := range m.attempts {
+		if a.IPAddress == ip && !a.Success && !a.CreatedAt.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+func (m *mockLoginAttemptRepo) ListByUser(userID uint, limit int) ([]models.LoginAttempt, error) {
+	return nil, nil
+}
+
+func newLoginTestService(t *testing.T) (*services.AuthService, *mockLoginUserRepo, *mockLoginAttemptRepo) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	verified := time.Now()
+	userRepo := &mockLoginUserRepo{user: &entity.User{
+		ID:              1,
+		Email:           "student@example.com",
+		Password:        string(hash),
+		EmailVerifiedAt: &verified,
+	}}
+	attempts := &mockLoginAttemptRepo{}
+	svc := services.NewAuthService(userRepo, nil, services.NewEmailService())
+	svc.SetLoginAttemptRepository(attempts)
+	svc.SetLoginPolicy(services.LoginPolicy{
+		FreeFailures:  100, // 段階的な待機はテストごとに個別に確認する
+		MaxDelay:      time.Minute,
+		MaxFailures:   3,
+		LockDuration:  30 * time.Minute,
+		IPMaxFailures: 100,
+		IPWindow:      15 * time.Minute,
+	})
+	return svc, userRepo, attempts
+}
+
+func loginBlockedCode(err error) string {
+	var blocked *services.LoginBlockedError
+	if errors.As(err, &blocked) {
+		return blocked.Code
+	}
+	return ""
+}
+
+func TestLogin_LocksAccountAfterMaxFailures(t *testing.T) {
+	svc, userRepo, _ := newLoginTestService(t)
+	bad := services.LoginRequest{Email: "student@example.com", Password: "wrong", IP: "203.0.113.1"}
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.Login(bad)
+		require.Error(t, err)
+		assert.Equal(t, "invalid email or password", err.Error())
+	}
+	_, err := svc.Login(bad)
+	assert.Equal(t, services.LoginBlockedAccountLocked, loginBlockedCode(err))
+	require.NotNil(t, userRepo.user.LockedUntil)
+	assert.NotEmpty(t, userRepo.user.UnlockToken)
+
+	_, err = svc.Login(services.LoginRequest{Email: "student@example.com", Password: "correct-password", IP: "203.0.113.1"})
+	assert.Equal(t, services.LoginBlockedAccountLocked, loginBlockedCode(err), "ロック中は正しいパスワードでもログインできない")
+
+	require.NoError(t, svc.UnlockAccountByToken(userRepo.user.UnlockToken))
+	assert.Nil(t, userRepo.user.LockedUntil)
+	assert.Equal(t, 0, userRepo.user.FailedLoginCount)
+
+	resp, err := svc.Login(services.LoginRequest{Email: "student@example.com", Password: "correct-password", IP: "203.0.113.1"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), resp.UserID)
+}
+
+func TestLogin_ProgressiveDelay(t *testing.T) {
+	svc, userRepo, _ := newLoginTestService(t)
+	svc.SetLoginPolicy(services.LoginPolicy{
+		FreeFailures:  1,
+		MaxDelay:      time.Minute,
+		MaxFailures:   10,
+		LockDuration:  30 * time.Minute,
+		IPMaxFailures: 100,
+		IPWindow:      15 * time.Minute,
+	})
+	bad := services.LoginRequest{Email: "student@example.com", Password: "wrong", IP: "203.0.113.1"}
+
+	_, err := svc.Login(bad)
+	assert.Equal(t, "invalid email or password", err.Error())
+	_, err = svc.Login(bad)
+	assert.Equal(t, "invalid email or password", err.Error(), "許容回数内は待機なしで再試行できる")
+
+	_, err = svc.Login(bad)
+	var blocked *services.LoginBlockedError
+	require.True(t, errors.As(err, &blocked), "許容回数を超えると待機が必要になる")
+	assert.Equal(t, services.LoginBlockedTooManyAttempts, blocked.Code)
+	assert.Greater(t, blocked.RetryAfter, time.Duration(0))
+	assert.Equal(t, 2, userRepo.user.FailedLoginCount, "待機中の試行は失敗回数に数えない")
+}
+
+func TestLogin_SuccessResetsFailures(t *testing.T) {
+	svc, userRepo, attempts := newLoginTestService(t)
+
+	_, err := svc.Login(services.LoginRequest{Email: "student@example.com", Password: "wrong", IP: "203.0.113.1"})
+	require.Error(t, err)
+	assert.Equal(t, 1, userRepo.user.FailedLoginCount)
+
+	_, err = svc.Login(services.LoginRequest{Email: "student@example.com", Password: "correct-password", IP: "203.0.113.1"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, userRepo.user.FailedLoginCount)
+	assert.Nil(t, userRepo.user.LastFailedLoginAt)
+	require.Len(t, attempts.attempts, 2)
+	assert.True(t, attempts.attempts[1].Success)
+}
+
+func TestLogin_BlocksIPAfterTooManyFailures(t *testing.T) {
+	svc, _, attempts := newLoginTestService(t)
+	for i := 0; i < 100; i++ {
+		attempts.Create(&models.LoginAttempt{Email: "someone@example.com", IPAddress: "198.51.100.7", Reason: "unknown_email"})
+	}
+
+	_, err := svc.Login(services.LoginRequest{Email: "student@example.com", Password: "correct-password", IP: "198.51.100.7"})
+	assert.Equal(t, services.LoginBlockedTooManyAttempts, loginBlockedCode(err))
+
+	_, err = svc.Login(services.LoginRequest{Email: "student@example.com", Password: "correct-password", IP: "203.0.113.1"})
+	assert.NoError(t, err, "別の IP からは影響を受けない")
+}
+
+func TestRefreshSession_RejectedWhileLockedAndAfterAdminUnlock(t *testing.T) {
+	svc, userRepo, _ := newLoginTestService(t)
+	svc.SetTokenService(services.NewTokenService(testTokenSecret, time.Hour, 24*time.Hour))
+
+	resp, err := svc.Login(services.LoginRequest{Email: "student@example.com", Password: "correct-password", IP: "203.0.113.1"})
+	require.NoError(t, err)
+	bad := services.LoginRequest{Email: "student@example.com", Password: "wrong", IP: "203.0.113.1"}
+	for i := 0; i < 3; i++ {
+		svc.Login(bad)
+	}
+	require.NotNil(t, userRepo.user.LockedUntil)
+
+	_, err = svc.RefreshSession(resp.RefreshToken)
+	assert.Equal(t, services.LoginBlockedAccountLocked, loginBlockedCode(err), "ロック中はトークンを再発行しない")
+
+	_, err = svc.UnlockAccount(1, "admin@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, userRepo.user.SessionsRevokedAt)
+	// 無効化と同じ秒に発行したトークンは有効とみなすため、解除がトークン発行の後の秒に行われたことにする
+	revokedAt := userRepo.user.SessionsRevokedAt.Add(2 * time.Second)
+	userRepo.user.SessionsRevokedAt = &revokedAt
+
+	_, err = svc.RefreshSession(resp.RefreshToken)
+	assert.ErrorIs(t, err, services.ErrInvalidToken, "管理者がロックを解除する前のセッションは再発行しない")
+}
@@ -0,0 +1,265 @@
+package services_test
+
+// モデル単価表と料金の再計算のテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Pricing -v
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	domainai "Backend/domain/ai"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ repository.ModelPriceRepository = (*memoryPriceRepo)(nil)
+var _ repository.CallCostRepricingRepository = (*memoryCallLogs)(nil)
+var _ repository.RealtimeCostRepricingRepository = (*memoryRealtimeLogs)(nil)
+
+// memoryPriceRepo は repository.ModelPriceRepository のインメモリ実装。
+type memoryPriceRepo struct {
+	prices []models.ModelPrice
+	lists  int
+}
+
+func (m *memoryPriceRepo) List() ([]models.ModelPrice, error) {
+	m.lists++
+	return append([]models.ModelPrice{}, m.prices...), nil
+}
+
+func (m *memoryPriceRepo) FindByID(id uint) (*models.ModelPrice, error) {
+	for _, p := range m.prices {
+		if p.ID == id {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryPriceRepo) Upsert(price *models.ModelPrice) error {
+	for i, p := range m.prices {
+		if p.Model == price.Model && p.EffectiveFrom.Equal(price.EffectiveFrom) {
+			price.ID = p.ID
+			m.prices[i] = *price
+			return nil
+		}
+	}
+	price.ID = uint(len(m.prices) + 1)
+	m.prices = append(m.prices, *price)
+	return nil
+}
+
+func (m *memoryPriceRepo) Delete(id uint) error {
+	for i, p := range m.prices {
+		if p.ID == id {
+			m.prices = append(m.prices[:i], m.prices[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type memoryCallLogs struct {
+	logs []models.APICallLog
+}
+
+func (m *memoryCallLogs) ListForRepricing(from, to time.Time, model string, afterID uint, limit int) ([]models.APICallLog, error) {
+	var out []models.APICallLog
+	for _, l := range m.logs {
+		if l.ID <= afterID || l.CalledAt.Before(from) || !l.CalledAt.Before(to) {
+			continue
+		}
+		if model != "" && (len(l.Model) < len(model) || l.Model[:len(model)] != model) {
+			continue
+		}
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *memoryCallLogs) UpdateCost(id uint, costUSD float64, modelPriceID *uint) error {
+	for i := range m.logs {
+		if m.logs[i].ID == id {
+			m.logs[i].CostUSD = costUSD
+			m.logs[i].ModelPriceID = modelPriceID
+		}
+	}
+	return nil
+}
+
+type memoryRealtimeLogs struct {
+	logs []models.RealtimeUsageLog
+}
+
+func (m *memoryRealtimeLogs) ListFinishedForRepricing(from, to time.Time, afterID uint, limit int) ([]models.RealtimeUsageLog, error) {
+	var out []models.RealtimeUsageLog
+	for _, l := range m.logs {
+		if l.ID > afterID && !l.StartedAt.Before(from) && l.StartedAt.Before(to) && l.EndedAt != nil {
+			out = append(out, l)
+		}
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *memoryRealtimeLogs) UpdateCost(id uint, costUSD float64) error {
+	for i := range m.logs {
+		if m.logs[i].ID == id {
+			m.logs[i].CostUSD = costUSD
+		}
+	}
+	return nil
+}
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestPricing_LookupByEffectiveDateAndLongestPrefix(t *testing.T) {
+	repo := &memoryPriceRepo{}
+	pricing := services.NewPricingService(repo)
+	seeded, err := pricing.SeedDefaults()
+	require.NoError(t, err)
+	assert.Greater(t, seeded, 0)
+	again, err := pricing.SeedDefaults()
+	require.NoError(t, err)
+	assert.Zero(t, again, "単価表が空でなければ初期値を登録しない")
+
+	// o3 は 2025-06-10 から値下げ後の単価
+	before := pricing.Lookup("o3", day(2025, 6, 9))
+	after := pricing.Lookup("o3-2025-04-16", day(2025, 6, 10))
+	require.NotNil(t, before)
+	require.NotNil(t, after)
+	assert.Equal(t, 10.0, before.InputPerMTokUSD)
+	assert.Equal(t, 2.0, after.InputPerMTokUSD)
+
+	// 前方一致はより長いモデル名を優先し、区切りのない前方一致は当てない
+	assert.Equal(t, "gpt-4o-mini", pricing.Lookup("gpt-4o-mini-2024-07-18", day(2025, 1, 1)).Model)
+	assert.Equal(t, "gpt-4o", pricing.Lookup("GPT-4o-2024-08-06", day(2025, 1, 1)).Model)
+	assert.Nil(t, pricing.Lookup("gpt-4oz", day(2025, 1, 1)))
+	assert.Nil(t, pricing.Lookup("claude-3-opus", day(2025, 1, 1)), "未登録のモデルを既定の単価で計上しない")
+	assert.Nil(t, pricing.Lookup("gpt-4o", day(2023, 12, 31)), "適用開始日より前は未登録")
+
+	// 長い名前の行がまだ有効でない期間は、短い名前の単価で代用しない
+	_, err = pricing.Upsert(models.ModelPrice{Model: "gpt-4o-audio", EffectiveFrom: day(2025, 3, 1), InputPerMTokUSD: 40}, "admin@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, pricing.Lookup("gpt-4o-audio-preview", day(2025, 2, 1)))
+	assert.Equal(t, 40.0, pricing.Lookup("gpt-4o-audio-preview", day(2025, 3, 1)).InputPerMTokUSD)
+}
+
+func TestPricing_TokenCostWithCachedInput(t *testing.T) {
+	repo := &memoryPriceRepo{}
+	pricing := services.NewPricingService(repo)
+	_, err := pricing.Upsert(models.ModelPrice{Model: "m1", EffectiveFrom: day(2025, 1, 1), InputPerMTokUSD: 2, CachedInputPerMTokUSD: 0.5, OutputPerMTokUSD: 8}, "a")
+	require.NoError(t, err)
+	_, err = pricing.Upsert(models.ModelPrice{Model: "m2", EffectiveFrom: day(2025, 1, 1), InputPerMTokUSD: 1, OutputPerMTokUSD: 1}, "a")
+	require.NoError(t, err)
+
+	cost, priceID := pricing.TokenCost("m1", day(2025, 2, 1), domainai.TokenUsage{PromptTokens: 1_000_000, CachedPromptTokens: 400_000, CompletionTokens: 500_000})
+	require.NotNil(t, priceID)
+	assert.InDelta(t, 0.6*2+0.4*0.5+0.5*8, cost, 1e-9)
+
+	// キャッシュ済み入力の単価が未設定なら入力と同じ単価
+	cost, _ = pricing.TokenCost("m2", day(2025, 2, 1), domainai.TokenUsage{PromptTokens: 1_000_000, CachedPromptTokens: 1_000_000})
+	assert.InDelta(t, 1.0, cost, 1e-9)
+
+	cost, priceID = pricing.TokenCost("unknown", day(2025, 2, 1), domainai.TokenUsage{PromptTokens: 1000})
+	assert.Zero(t, cost)
+	assert.Nil(t, priceID)
+
+	_, err = pricing.Upsert(models.ModelPrice{Model: " ", EffectiveFrom: day(2025, 1, 1)}, "a")
+	assert.ErrorIs(t, err, services.ErrInvalidPrice)
+	_, err = pricing.Upsert(models.ModelPrice{Model: "m3"}, "a")
+	assert.ErrorIs(t, err, services.ErrInvalidPrice)
+	_, err = pricing.Upsert(models.ModelPrice{Model: "m3", EffectiveFrom: day(2025, 1, 1), OutputPerMTokUSD: -1}, "a")
+	assert.ErrorIs(t, err, services.ErrInvalidPrice)
+	_, err = pricing.Delete(99)
+	assert.ErrorIs(t, err, services.ErrPriceNotFound)
+}
+
+func TestPricing_CacheReloadsAfterTTL(t *testing.T) {
+	repo := &memoryPriceRepo{}
+	pricing := services.NewPricingService(repo)
+	now := day(2025, 5, 1)
+	pricing.SetNow(func() time.Time { return now })
+	_, err := pricing.Upsert(models.ModelPrice{Model: "m1", EffectiveFrom: day(2025, 1, 1), InputPerMTokUSD: 1}, "a")
+	require.NoError(t, err)
+	require.NotNil(t, pricing.Lookup("m1", now))
+
+	// DB を直接書き換えた場合は TTL の経過後に反映される
+	repo.prices = nil
+	assert.NotNil(t, pricing.Lookup("m1", now))
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, pricing.Lookup("m1", now))
+}
+
+func TestCostRepricing_RecalculatesHistory(t *testing.T) {
+	repo := &memoryPriceRepo{}
+	pricing := services.NewPricingService(repo)
+	old, err := pricing.Upsert(models.ModelPrice{Model: "gpt-x", EffectiveFrom: day(2025, 1, 1), InputPerMTokUSD: 1, OutputPerMTokUSD: 1}, "a")
+	require.NoError(t, err)
+	rt, err := pricing.Upsert(models.ModelPrice{Model: "rt-model", EffectiveFrom: day(2025, 1, 1), PerMinuteUSD: 0.3}, "a")
+	require.NoError(t, err)
+	require.NotNil(t, rt)
+
+	oldID := old.ID
+	ended := day(2025, 3, 1).Add(10 * time.Minute)
+	calls := &memoryCallLogs{logs: []models.APICallLog{
+		{ID: 1, Model: "gpt-x", PromptTokens: 1_000_000, CalledAt: day(2025, 2, 1), CostUSD: 1, ModelPriceID: &oldID},
+		{ID: 2, Model: "gpt-x-2025", CompletionTokens: 1_000_000, CalledAt: day(2025, 4, 1), CostUSD: 1, ModelPriceID: &oldID},
+		{ID: 3, Model: "mystery", PromptTokens: 1000, CalledAt: day(2025, 4, 1), CostUSD: 0.0025},
+		{ID: 4, Model: "gpt-x", PromptTokens: 1_000_000, CalledAt: day(2024, 12, 1), CostUSD: 7},
+	}}
+	realtime := &memoryRealtimeLogs{logs: []models.RealtimeUsageLog{
+		{ID: 1, Model: "rt-model", StartedAt: day(2025, 3, 1), EndedAt: &ended, DurationSeconds: 600, CostUSD: 1.8},
+	}}
+	svc := services.NewCostRepricingService(pricing, calls, realtime)
+
+	// 2025-04-01 以降の単価を訂正する
+	_, err = pricing.Upsert(models.ModelPrice{Model: "gpt-x", EffectiveFrom: day(2025, 4, 1), InputPerMTokUSD: 3, OutputPerMTokUSD: 3}, "a")
+	require.NoError(t, err)
+
+	dry, err := svc.Recalculate(context.Background(), services.RepricingOptions{From: day(2025, 1, 1), To: day(2025, 5, 1), DryRun: true, BatchSize: 1})
+	require.NoError(t, err)
+	assert.Equal(t, services.RepricingCount{Scanned: 3, Changed: 1, Unpriced: 1, DeltaUSD: 2}, roundCount(dry.Calls))
+	assert.Equal(t, 1, dry.Realtime.Changed)
+	assert.Equal(t, 1.0, calls.logs[1].CostUSD, "dry-run では更新しない")
+
+	result, err := svc.Recalculate(context.Background(), services.RepricingOptions{From: day(2025, 1, 1), To: day(2025, 5, 1)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Calls.Changed)
+	assert.Equal(t, 1.0, calls.logs[0].CostUSD, "訂正前の期間の料金は変わらない")
+	assert.Equal(t, 3.0, calls.logs[1].CostUSD)
+	assert.NotEqual(t, oldID, *calls.logs[1].ModelPriceID)
+	assert.Equal(t, 0.0025, calls.logs[2].CostUSD, "単価未登録のモデルは記録済みの料金を 0 で上書きしない")
+	assert.Equal(t, 7.0, calls.logs[3].CostUSD, "期間外の行は変えない")
+	assert.InDelta(t, 3.0, realtime.logs[0].CostUSD, 1e-9)
+
+	// モデルで絞り込むと他のモデルは対象外
+	filtered, err := svc.Recalculate(context.Background(), services.RepricingOptions{From: day(2025, 1, 1), To: day(2025, 5, 1), Model: "rt-"})
+	require.NoError(t, err)
+	assert.Zero(t, filtered.Calls.Scanned)
+	assert.Equal(t, 1, filtered.Realtime.Scanned)
+	assert.Zero(t, filtered.Realtime.Changed, "再計算済みなら変更なし")
+
+	_, err = svc.Recalculate(context.Background(), services.RepricingOptions{From: day(2025, 5, 1), To: day(2025, 1, 1)})
+	assert.ErrorIs(t, err, services.ErrInvalidRepricing)
+}
+
+func roundCount(c services.RepricingCount) services.RepricingCount {
+	c.DeltaUSD = float64(int64(c.DeltaUSD*1e6+0.5)) / 1e6
+	return c
+}
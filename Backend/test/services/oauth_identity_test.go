@@ -0,0 +1,296 @@
+package services_test
+
+// 外部アカウント連携（OAuthService）のユニットテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Identity -v
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Backend/domain/entity"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIdentityUserRepo は複数ユーザーを保持する UserRepository モック。
+type mockIdentityUserRepo struct {
+	repository.UserRepository
+	users  map[uint]*entity.User
+	nextID uint
+}
+
+func newMockIdentityUserRepo(users ...*entity.User) *mockIdentityUserRepo {
+	m := &mockIdentityUserRepo{users: map[uint]*entity.User{}, nextID: 100}
+	for _, u := range users {
+		m.users[u.ID] = u
+	}
+	return m
+}
+
+func (m *mockIdentityUserRepo) CreateUser(u *entity.User) error {
+	m.nextID++
+	u.ID = m.nextID
+	saved := *u
+	m.users[u.ID] = &saved
+	return nil
+}
+func (m *mockIdentityUserRepo) GetUserByID(id uint) (*entity.User, error) {
+	if u, ok := m.users[id]; ok {
+		c := *u
+		return &c, nil
+	}
+	return nil, nil
+}
+func (m *mockIdentityUserRepo) GetUserByEmail(email string) (*entity.User, error) {
+	for _, u := range m.users {
+		if strings.EqualFold(u.Email, email) {
+			c := *u
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockIdentityUserRepo) GetUserByOAuth(provider, oauthID string) (*entity.User, error) {
+	for _, u := range m.users {
+		if u.OAuthProvider == provider && u.OAuthID == oauthID {
+			c := *u
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockIdentityUserRepo) UpdateUser(u *entity.User) error {
+	saved := *u
+	m.users[u.ID] = &saved
+	return nil
+}
+
+// mockIdentityRepo は連携をメモリ上に保持する UserIdentityRepository モック。
+type mockIdentityRepo struct {
+	identities []models.UserIdentity
+	nextID     uint
+}
+
+func (m *mockIdentityRepo) Create(identity *models.UserIdentity) error {
+	m.nextID++
+	identity.ID = m.nextID
+	identity.CreatedAt = time.Now()
+	m.identities = append(m.identities, *identity)
+	return nil
+}
+func (m *mockIdentityRepo) Update(identity *models.UserIdentity) error {
+	for i := range m.identities {
+		if m.identities[i].ID == identity.ID {
+			m.identities[i] = *identity
+		}
+	}
+	return nil
+}
+func (m *mockIdentityRepo) FindByProvider(provider, providerUserID string) (*models.UserIdentity, error) {
+	for _, identity := range m.identities {
+		if identity.Provider == provider && identity.ProviderUserID == providerUserID {
+			c := identity
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockIdentityRepo) ListByUser(userID uint) ([]models.UserIdentity, error) {
+	var out []models.UserIdentity
+	for _, identity := range m.identities {
+		if identity.UserID == userID {
+			out = append(out, identity)
+		}
+	}
+	return out, nil
+}
+func (m *mockIdentityRepo) Delete(id uint) error {
+	for i := range m.identities {
+		if m.identities[i].ID == id {
+			m.identities = append(m.identities[:i], m.identities[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func googleProfile(subject, email string) *services.OAuthProfile {
+	return &services.OAuthProfile{Provider: "google", Subject: subject, Email: email, EmailVerified: true, Name: "Google User"}
+}
+
+func githubProfile(subject, email string) *services.OAuthProfile {
+	return &services.OAuthProfile{Provider: "github", Subject: subject, Email: email, EmailVerified: true, Username: "octocat"}
+}
+
+func TestLoginWithIdentity_CreatesUserAndReusesIdentity(t *testing.T) {
+	users := newMockIdentityUserRepo()
+	identities := &mockIdentityRepo{}
+	svc := services.NewOAuthService(users, identities, nil, nil)
+
+	first, err := svc.LoginWithIdentity(googleProfile("g-1", "student@example.com"))
+	require.NoError(t, err)
+	require.Len(t, identities.identities, 1)
+	assert.Equal(t, first.UserID, identities.identities[0].UserID)
+	assert.NotNil(t, users.users[first.UserID].EmailVerifiedAt, "プロバイダ確認済みのメールアドレスは認証済みとして扱う")
+
+	second, err := svc.LoginWithIdentity(googleProfile("g-1", "student@example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, first.UserID, second.UserID)
+	assert.Len(t, users.users, 1)
+}
+
+func TestLoginWithIdentity_EmailCollisionRules(t *testing.T) {
+	verified := time.Now()
+	cases := []struct {
+		name    string
+		user    *entity.User
+		profile *services.OAuthProfile
+		linked  bool
+	}{
+		{
+			name:    "メール認証済みのパスワードユーザーには自動連携する",
+			user:    &entity.User{ID: 1, Email: "student@example.com", Password: "hash", EmailVerifiedAt: &verified},
+			profile: googleProfile("g-1", "student@example.com"),
+			linked:  true,
+		},
+		{
+			name:    "メール未認証のパスワードユーザーには自動連携しない",
+			user:    &entity.User{ID: 1, Email: "student@example.com", Password: "hash"},
+			profile: googleProfile("g-1", "student@example.com"),
+		},
+		{
+			name:    "プロバイダが未確認のメールアドレスでは自動連携しない",
+			user:    &entity.User{ID: 1, Email: "student@example.com", Password: "hash", EmailVerifiedAt: &verified},
+			profile: &services.OAuthProfile{Provider: "github", Subject: "1", Email: "student@example.com"},
+		},
+		{
+			name:    "教員アカウントには自動連携しない",
+			user:    &entity.User{ID: 1, Email: "teacher@example.com", Password: "hash", Role: entity.RoleTeacher, EmailVerifiedAt: &verified},
+			profile: googleProfile("g-1", "teacher@example.com"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := newMockIdentityUserRepo(tc.user)
+			identities := &mockIdentityRepo{}
+			svc := services.NewOAuthService(users, identities, nil, nil)
+
+			resp, err := svc.LoginWithIdentity(tc.profile)
+			if tc.linked {
+				require.NoError(t, err)
+				assert.Equal(t, tc.user.ID, resp.UserID)
+				require.Len(t, identities.identities, 1)
+				return
+			}
+			assert.ErrorIs(t, err, services.ErrOAuthAccountExists)
+			assert.Empty(t, identities.identities)
+			assert.Len(t, users.users, 1, "別アカウントも作成しない")
+		})
+	}
+}
+
+func TestLinkIdentity_AddsSecondProviderToGoogleAccount(t *testing.T) {
+	users := newMockIdentityUserRepo(
+		&entity.User{ID: 1, Email: "student@example.com", OAuthProvider: "google", OAuthID: "g-1"},
+		&entity.User{ID: 2, Email: "other@example.com", Password: "hash"},
+	)
+	identities := &mockIdentityRepo{}
+	svc := services.NewOAuthService(users, identities, nil, nil)
+
+	identity, err := svc.LinkIdentity(1, githubProfile("gh-1", "student@users.noreply.github.com"))
+	require.NoError(t, err)
+	assert.Equal(t, "github", identity.Provider)
+
+	accounts, err := svc.ListLinkedAccounts(1)
+	require.NoError(t, err)
+	assert.False(t, accounts.HasPassword)
+	require.Len(t, accounts.Identities, 2, "users.oauth_provider の連携も一覧に移行される")
+
+	resp, err := svc.LoginWithIdentity(githubProfile("gh-1", "student@users.noreply.github.com"))
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), resp.UserID, "連携した GitHub でも同じアカウントにログインできる")
+
+	_, err = svc.LinkIdentity(2, githubProfile("gh-1", "student@users.noreply.github.com"))
+	assert.ErrorIs(t, err, services.ErrIdentityLinkedToOtherUser)
+
+	_, err = svc.LinkIdentity(1, githubProfile("gh-2", "student@users.noreply.github.com"))
+	assert.ErrorIs(t, err, services.ErrProviderAlreadyLinked)
+
+	_, err = svc.LinkIdentity(2, googleProfile("g-2", "student@example.com"))
+	assert.ErrorIs(t, err, services.ErrIdentityEmailInUse, "別ユーザーのメールアドレスを持つ外部アカウントは統合しない")
+}
+
+func TestUnlinkIdentity_KeepsLastLoginMethod(t *testing.T) {
+	users := newMockIdentityUserRepo(&entity.User{ID: 1, Email: "student@example.com", OAuthProvider: "google", OAuthID: "g-1"})
+	identities := &mockIdentityRepo{}
+	svc := services.NewOAuthService(users, identities, nil, nil)
+
+	_, err := svc.LinkIdentity(1, githubProfile("gh-1", "student@example.com"))
+	require.NoError(t, err)
+
+	require.NoError(t, svc.UnlinkIdentity(1, "google"))
+	assert.Empty(t, users.users[1].OAuthProvider, "移行前の OAuth 情報も解除する")
+	assert.Len(t, identities.identities, 1)
+
+	assert.ErrorIs(t, svc.UnlinkIdentity(1, "github"), services.ErrLastLoginMethod)
+	assert.ErrorIs(t, svc.UnlinkIdentity(1, "google"), services.ErrIdentityNotFound)
+
+	users.users[1].Password = "hash"
+	assert.NoError(t, svc.UnlinkIdentity(1, "github"), "パスワードがあれば最後の連携も解除できる")
+}
+
+func TestSetPassword_AddsPasswordLoginToOAuthAccount(t *testing.T) {
+	users := newMockIdentityUserRepo(&entity.User{ID: 1, Email: "student@example.com", OAuthProvider: "google", OAuthID: "g-1"})
+	auth := services.NewAuthService(users, nil, services.NewEmailService())
+
+	assert.EqualError(t, auth.SetPassword(1, "", "short"), "password must be at least 8 characters")
+	require.NoError(t, auth.SetPassword(1, "", "new-password"))
+	assert.NotEmpty(t, users.users[1].Password)
+	assert.NotNil(t, users.users[1].EmailVerifiedAt)
+
+	assert.EqualError(t, auth.SetPassword(1, "wrong", "another-password"), "current password is incorrect")
+	require.NoError(t, auth.SetPassword(1, "new-password", "another-password"))
+
+	resp, err := auth.Login(services.LoginRequest{Email: "student@example.com", Password: "another-password"})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), resp.UserID)
+}
+
+func TestOAuthLoginCode_IsSingleUse(t *testing.T) {
+	svc := services.NewOAuthService(newMockIdentityUserRepo(), &mockIdentityRepo{}, nil, nil)
+	resp, err := svc.LoginWithIdentity(googleProfile("g-1", "student@example.com"))
+	require.NoError(t, err)
+
+	code, err := svc.IssueLoginCode(resp)
+	require.NoError(t, err)
+	assert.NotContains(t, code, resp.Email, "コードにユーザー情報を含めない")
+
+	got, err := svc.ExchangeLoginCode(code)
+	require.NoError(t, err)
+	assert.Equal(t, resp.UserID, got.UserID)
+
+	_, err = svc.ExchangeLoginCode(code)
+	assert.ErrorIs(t, err, services.ErrInvalidLoginCode, "2回目の交換はできない")
+	_, err = svc.ExchangeLoginCode("unknown")
+	assert.ErrorIs(t, err, services.ErrInvalidLoginCode)
+}
+
+func TestLoginWithIdentity_RequiresMFAWhenTOTPEnabled(t *testing.T) {
+	enabledAt := time.Now()
+	users := newMockIdentityUserRepo(&entity.User{ID: 1, Email: "teacher@example.com", OAuthProvider: "google", OAuthID: "g-1", TOTPEnabledAt: &enabledAt})
+	svc := services.NewOAuthService(users, &mockIdentityRepo{}, nil, nil)
+	svc.SetTokenService(services.NewTokenService(testTokenSecret, time.Hour, 24*time.Hour))
+
+	resp, err := svc.LoginWithIdentity(googleProfile("g-1", "teacher@example.com"))
+	require.NoError(t, err)
+	assert.True(t, resp.MFARequired, "2段階認証を有効にしたユーザーは外部アカウントでも認証コードを求める")
+	assert.NotEmpty(t, resp.MFAToken)
+	assert.Empty(t, resp.Token, "認証コードの検証前にセッションを発行しない")
+	assert.Empty(t, resp.RefreshToken)
+}
@@ -0,0 +1,332 @@
+package services_test
+
+// 個人データのエクスポート・完全削除のユニットテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run PersonalData -v
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPersonalDataJobRepo ジョブをメモリ上に保持する（ジョブはバックグラウンドで更新される）
+type mockPersonalDataJobRepo struct {
+	mu     sync.Mutex
+	jobs   map[uint]models.PersonalDataJob
+	nextID uint
+}
+
+func newMockPersonalDataJobRepo() *mockPersonalDataJobRepo {
+	return &mockPersonalDataJobRepo{jobs: map[uint]models.PersonalDataJob{}}
+}
+
+func (m *mockPersonalDataJobRepo) Create(job *models.PersonalDataJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	job.ID = m.nextID
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockPersonalDataJobRepo) Update(job *models.PersonalDataJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockPersonalDataJobRepo) FindByID(id uint) (*models.PersonalDataJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func (m *mockPersonalDataJobRepo) FindByStatusToken(token string) (*models.PersonalDataJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range m.jobs {
+		if job.StatusToken == token {
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockPersonalDataJobRepo) ListByUser(userID uint, kind string) ([]models.PersonalDataJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []models.PersonalDataJob
+	for _, job := range m.jobs {
+		if job.UserID == userID && (kind == "" || job.Kind == kind) {
+			out = append(out, job)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockPersonalDataJobRepo) ListUnfinished() ([]models.PersonalDataJob, error) {
+	return nil, nil
+}
+
+// mockObjectStore S3 の代わりにメモリ上のオブジェクトを扱う
+type mockObjectStore struct {
+	mu       sync.Mutex
+	objects  map[string]string
+	failures map[string]int // 残りの削除失敗回数
+	deleted  []string
+}
+
+func (m *mockObjectStore) Open(_ context.Context, uri string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	body, ok := m.objects[uri]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return io.NopCloser(strings.NewReader(body)), nil
+}
+
+func (m *mockObjectStore) Delete(_ context.Context, uri string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failures[uri] > 0 {
+		m.failures[uri]--
+		return errors.New("temporary failure")
+	}
+	delete(m.objects, uri)
+	m.deleted = append(m.deleted, uri)
+	return nil
+}
+
+func (m *mockObjectStore) URI(key string) string {
+	return "s3://test-bucket/" + key
+}
+
+// personalDataFixture ユーザー1のデータ（履歴書1件・面接1件・録画1件・GitHub連携）
+func personalDataFixture(normalizedPath string) map[string]*sqlmock.Rows {
+	return map[string]*sqlmock.Rows{
+		"users": sqlmock.NewRows([]string{"id", "email", "name", "password", "totp_secret"}).
+			AddRow(1, "student@example.com", "学生", "hash", "secret"),
+		"interview_sessions": sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 1),
+		"interview_videos":   sqlmock.NewRows([]string{"id", "user_id", "drive_file_id"}).AddRow(30, 1, "videos/1.webm"),
+		"resume_documents": sqlmock.NewRows([]string{"id", "user_id", "stored_path", "normalized_path", "annotated_path"}).
+			AddRow(20, 1, "s3://test-bucket/resumes/cv.pdf", normalizedPath, ""),
+		"github_profiles": sqlmock.NewRows([]string{"id", "user_id", "login", "access_token"}).
+			AddRow(5, 1, "octocat", "gho_secret"),
+	}
+}
+
+// expectPersonalDataLoad 一覧の順に読み込むクエリを登録し、読み込まれるテーブルを返す
+func expectPersonalDataLoad(mock sqlmock.Sqlmock, fixture map[string]*sqlmock.Rows) []models.UserDataTable {
+	mock.ExpectQuery("users").WillReturnRows(fixture["users"])
+	var loaded []models.UserDataTable
+	for _, t := range models.UserDataTables() {
+		// 親テーブルに行がなければ子テーブルは読まない
+		if t.Parent != "" && fixture[t.Parent] == nil {
+			continue
+		}
+		rows := fixture[t.Name]
+		if rows == nil {
+			rows = sqlmock.NewRows([]string{"id"})
+		}
+		mock.ExpectQuery(t.Name).WillReturnRows(rows)
+		loaded = append(loaded, t)
+	}
+	mock.ExpectQuery("collective").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	return loaded
+}
+
+func writeTempFile(t *testing.T, name, body string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(p, []byte(body), 0o600))
+	return p
+}
+
+func waitForJob(t *testing.T, repo *mockPersonalDataJobRepo, id uint) models.PersonalDataJob {
+	t.Helper()
+	var job *models.PersonalDataJob
+	require.Eventually(t, func() bool {
+		job, _ = repo.FindByID(id)
+		return job != nil && job.Status != services.PersonalDataJobPending && job.Status != services.PersonalDataJobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return *job
+}
+
+func TestPersonalDataExport_WritesZipWithRedactedJSONAndFiles(t *testing.T) {
+	db, mock, _ := newMergeTestDB(t)
+	normalized := writeTempFile(t, "cv.json", `{"blocks":[]}`)
+	expectPersonalDataLoad(mock, personalDataFixture(normalized))
+
+	jobs := newMockPersonalDataJobRepo()
+	store := &mockObjectStore{objects: map[string]string{
+		"s3://test-bucket/resumes/cv.pdf": "%PDF-1.4",
+		"s3://test-bucket/videos/1.webm":  "webm",
+	}}
+	svc := services.NewPersonalDataService(db, jobs, t.TempDir())
+	svc.SetObjectStore(store)
+
+	job, err := svc.RequestExport(1, "student@example.com")
+	require.NoError(t, err)
+	done := waitForJob(t, jobs, job.ID)
+	require.Equal(t, services.PersonalDataJobCompleted, done.Status, done.Error)
+	assert.Equal(t, 3, done.FilesTotal)
+	assert.Equal(t, 3, done.FilesDone)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = svc.OpenExport(2, job.ID)
+	assert.ErrorIs(t, err, services.ErrPersonalDataJobNotFound, "他人のエクスポートは取得できない")
+
+	f, err := svc.OpenExport(1, job.ID)
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := f.Stat()
+	require.NoError(t, err)
+	zr, err := zip.NewReader(f, info.Size())
+	require.NoError(t, err)
+
+	entries := map[string]string{}
+	for _, zf := range zr.File {
+		rc, err := zf.Open()
+		require.NoError(t, err)
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		entries[zf.Name] = string(body)
+	}
+	for _, table := range models.UserDataTables() {
+		assert.Contains(t, entries, table.Name+".json")
+	}
+	assert.Contains(t, entries, "manifest.json")
+	assert.Contains(t, entries, "collective_insight_logs.json")
+	assert.Equal(t, "%PDF-1.4", entries["files/resume_documents/20/cv.pdf"])
+	assert.Equal(t, `{"blocks":[]}`, entries["files/resume_documents/20/cv.json"])
+	assert.Equal(t, "webm", entries["files/interview_videos/30/1.webm"])
+
+	var user map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(entries["user.json"]), &user))
+	assert.Equal(t, "student@example.com", user["email"])
+	assert.NotContains(t, user, "password")
+	assert.NotContains(t, user, "totp_secret")
+
+	var profiles []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(entries["github_profiles.json"]), &profiles))
+	require.Len(t, profiles, 1)
+	assert.Equal(t, "octocat", profiles[0]["login"])
+	assert.NotContains(t, profiles[0], "access_token")
+}
+
+func TestPersonalDataErasure_DeletesEveryTableAndStoredFiles(t *testing.T) {
+	db, mock, executed := newMergeTestDB(t)
+	normalized := writeTempFile(t, "cv.json", `{"blocks":[]}`)
+	oldExport := writeTempFile(t, "personal-data-1-7.zip", "zip")
+
+	result := sqlmock.NewResult(0, 1)
+	mock.ExpectBegin()
+	loaded := expectPersonalDataLoad(mock, personalDataFixture(normalized))
+	for range loaded {
+		mock.ExpectExec("delete").WillReturnResult(result)
+	}
+	mock.ExpectExec("delete collective").WillReturnResult(result)
+	mock.ExpectQuery("exports").WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "kind", "artifact_path"}).
+		AddRow(7, 1, "export", oldExport))
+	mock.ExpectExec("delete exports").WillReturnResult(result)
+	mock.ExpectExec("audit").WillReturnResult(result)
+	mock.ExpectExec("delete user").WillReturnResult(result)
+	mock.ExpectExec("save job").WillReturnResult(result)
+	mock.ExpectCommit()
+
+	jobs := newMockPersonalDataJobRepo()
+	store := &mockObjectStore{
+		objects: map[string]string{
+			"s3://test-bucket/resumes/cv.pdf": "%PDF-1.4",
+			"s3://test-bucket/videos/1.webm":  "webm",
+		},
+		failures: map[string]int{"s3://test-bucket/videos/1.webm": 1},
+	}
+	svc := services.NewPersonalDataService(db, jobs, t.TempDir())
+	svc.SetObjectStore(store)
+	svc.SetFileRetry(2, 0)
+
+	job, token, err := svc.RequestErasure(1)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	assert.Equal(t, 4, job.FilesTotal)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	all := strings.Join(*executed, "\n")
+	for _, table := range []string{"chat_messages", "resume_documents", "resume_text_blocks", "interview_utterances", "schedule_events", "git_hub_repos", "skill_scores", "user_embeddings", "realtime_usage_logs", "user_identities"} {
+		assert.Contains(t, all, "DELETE FROM `"+table+"`", table)
+	}
+	assert.Contains(t, all, "DELETE FROM `collective_insight_logs`")
+	assert.Contains(t, all, "UPDATE `audit_logs` SET `actor_email`")
+	assert.Contains(t, all, "DELETE FROM `users`")
+	assert.Less(t, strings.Index(all, "DELETE FROM `resume_text_blocks`"), strings.Index(all, "DELETE FROM `resume_documents`"), "子テーブルから削除する")
+
+	done := waitForJob(t, jobs, job.ID)
+	assert.Equal(t, services.PersonalDataJobCompleted, done.Status, done.Error)
+	assert.Equal(t, 4, done.FilesDone)
+	assert.Equal(t, 0, done.FilesFailed)
+	assert.ElementsMatch(t, []string{"s3://test-bucket/resumes/cv.pdf", "s3://test-bucket/videos/1.webm"}, store.deleted, "一時的な失敗は再試行する")
+	assert.NoFileExists(t, normalized)
+	assert.NoFileExists(t, oldExport)
+
+	byToken, err := svc.JobByStatusToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, byToken.ID)
+}
+
+func TestPersonalDataErasure_ReportsFilesThatCouldNotBeDeleted(t *testing.T) {
+	jobs := newMockPersonalDataJobRepo()
+	store := &mockObjectStore{failures: map[string]int{"s3://test-bucket/videos/1.webm": 5}}
+	db, mock, _ := newMergeTestDB(t)
+
+	result := sqlmock.NewResult(0, 1)
+	mock.ExpectBegin()
+	loaded := expectPersonalDataLoad(mock, map[string]*sqlmock.Rows{
+		"users":            sqlmock.NewRows([]string{"id", "email"}).AddRow(1, "student@example.com"),
+		"interview_videos": sqlmock.NewRows([]string{"id", "user_id", "drive_file_id"}).AddRow(30, 1, "videos/1.webm"),
+	})
+	for range loaded {
+		mock.ExpectExec("delete").WillReturnResult(result)
+	}
+	mock.ExpectExec("delete collective").WillReturnResult(result)
+	mock.ExpectQuery("exports").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("delete exports").WillReturnResult(result)
+	mock.ExpectExec("audit").WillReturnResult(result)
+	mock.ExpectExec("delete user").WillReturnResult(result)
+	mock.ExpectExec("save job").WillReturnResult(result)
+	mock.ExpectCommit()
+
+	svc := services.NewPersonalDataService(db, jobs, t.TempDir())
+	svc.SetObjectStore(store)
+	svc.SetFileRetry(2, 0)
+
+	job, _, err := svc.RequestErasure(1)
+	require.NoError(t, err)
+	done := waitForJob(t, jobs, job.ID)
+	assert.Equal(t, services.PersonalDataJobFailed, done.Status)
+	assert.Equal(t, 1, done.FilesFailed)
+	assert.Contains(t, done.PendingFiles, "videos/1.webm", "削除できなかったファイルは再実行のために残す")
+}
@@ -0,0 +1,191 @@
+package services_test
+
+// LLM に送るテキストの個人情報の置き換えのテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run PII -v
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	domainai "Backend/domain/ai"
+	"Backend/domain/entity"
+	internalai "Backend/internal/ai"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoLLM は受け取ったプロンプトを記録し、決めた応答を返す LLM のスタブ（ストリーミングでは deltas を順に送る）。
+type echoLLM struct {
+	domainai.LLMClient
+	prompts  []string
+	messages []domainai.Message
+	reply    string
+	deltas   []string
+}
+
+func (e *echoLLM) GenerateText(ctx context.Context, systemPrompt, userPrompt string, _ ...domainai.CallOption) (string, error) {
+	e.prompts = append(e.prompts, systemPrompt, userPrompt)
+	if onDelta := domainai.TextStreamFrom(ctx); onDelta != nil {
+		for _, d := range e.deltas {
+			onDelta(d)
+		}
+	}
+	return e.reply, nil
+}
+
+func (e *echoLLM) GenerateJSON(_ context.Context, systemPrompt, userPrompt string, _ ...domainai.CallOption) (string, error) {
+	e.prompts = append(e.prompts, systemPrompt, userPrompt)
+	return e.reply, nil
+}
+
+func (e *echoLLM) Chat(_ context.Context, messages []domainai.Message, _ ...domainai.CallOption) (string, error) {
+	e.messages = append(e.messages, messages...)
+	return e.reply, nil
+}
+
+func featureCtx(userID uint, feature string) context.Context {
+	return domainai.WithCallContext(context.Background(), domainai.CallContext{UserID: userID, Feature: feature})
+}
+
+func TestPIIRedactor_DetectsCommonPatterns(t *testing.T) {
+	redactor := services.NewPIIRedactor(nil)
+	cases := []struct {
+		text   string
+		value  string
+		prefix string
+	}{
+		{"連絡先は taro.yamada+job@example.co.jp です", "taro.yamada+job@example.co.jp", "[EMAIL_"},
+		{"電話: 090-1234-5678", "090-1234-5678", "[PHONE_"},
+		{"電話は０３（１２３４）５６７８まで", "０３（１２３４）５６７８", "[PHONE_"},
+		{"TEL +81 80 1234 5678", "+81 80 1234 5678", "[PHONE_"},
+		{"自宅 0312345678", "0312345678", "[PHONE_"},
+		{"〒150-0041 に住んでいます", "〒150-0041", "[POSTAL_"},
+		{"住所は東京都渋谷区神南1-2-3 サンプルビル5F", "東京都渋谷区神南1-2-3", "[ADDRESS_"},
+		{"大阪府大阪市北区梅田３丁目１番１号に通勤", "大阪府大阪市北区梅田３丁目１番１号", "[ADDRESS_"},
+		{"早稲田大学大学院で情報工学を専攻", "早稲田大学大学院", "[SCHOOL_"},
+		{"出身は県立ミライ高等学校です", "県立ミライ高等学校", "[SCHOOL_"},
+		{"氏名：山田 花子\n年齢: 21", "山田 花子", "[NAME_"},
+		{"フリガナ ヤマダ ハナコ", "ヤマダ ハナコ", "[NAME_"},
+		{"ゼミでは田中先生の指導を受けました", "田中", "[NAME_"},
+		{"先輩の佐々木健太さんに相談した", "佐々木健太", "[NAME_"},
+		{"推薦者 高橋 一郎、教授", "高橋 一郎", "[NAME_"},
+	}
+	for _, tc := range cases {
+		out, redaction := redactor.Redact(featureCtx(0, domainai.FeatureResumeReview), tc.text)
+		require.NotNil(t, redaction, "検出すること: %q", tc.text)
+		assert.NotContains(t, out[0], tc.value, "text=%q", tc.text)
+		assert.Contains(t, out[0], tc.prefix, "text=%q", tc.text)
+		assert.Equal(t, tc.text, redaction.Restore(out[0]), "元に戻せること: %q", tc.text)
+	}
+}
+
+func TestPIIRedactor_IgnoresOrdinaryText(t *testing.T) {
+	redactor := services.NewPIIRedactor(nil)
+	texts := []string{
+		"アルバイトで売上を前年比120%に伸ばしました。",
+		"TOEIC は 2023-06-15 に受験し 860 点でした。",
+		"社員番号 12345678901234 の管理システムを作りました。",
+		"お客様の声をもとに 3 つの改善を行いました。",
+		"大学ではデータ分析を学びました。",
+		"東京都内のスタートアップでインターンをしました。",
+	}
+	for _, text := range texts {
+		out, redaction := redactor.Redact(featureCtx(0, domainai.FeatureChat), text)
+		assert.Nil(t, redaction, "誤検出しないこと: %q (%v)", text, redaction.Values())
+		assert.Equal(t, text, out[0])
+	}
+}
+
+func TestPIIRedactor_ProfileValuesAndStablePlaceholders(t *testing.T) {
+	users := &mockLoginUserRepo{user: &entity.User{ID: 7, Name: "Emma Watanabe", Email: "emma@example.com", SchoolName: "ノースリッジ学院"}}
+	redactor := services.NewPIIRedactor(users)
+
+	out, redaction := redactor.Redact(featureCtx(7, domainai.FeatureInterview),
+		"私は Emma Watanabe です。ノースリッジ学院に通っています。",
+		"Emma と呼んでください。連絡は emma@example.com か EMMA@example.com へ")
+	require.NotNil(t, redaction)
+	assert.Equal(t, "私は [NAME_1] です。[SCHOOL_1]に通っています。", out[0], "登録情報の氏名・学校名は形式によらず置き換える")
+	assert.Contains(t, out[1], "[NAME_2] と呼んでください")
+	assert.Contains(t, out[1], "[EMAIL_1] か [EMAIL_2]")
+	assert.Equal(t, "emma@example.com", redaction.Values()["[EMAIL_1]"])
+
+	// 同じ値は複数のテキストをまたいでも同じ記号になる
+	out, _ = redactor.Redact(featureCtx(7, domainai.FeatureInterview), "Watanabe です", "Watanabe と申します")
+	assert.Equal(t, "[NAME_1] です", out[0])
+	assert.Equal(t, "[NAME_1] と申します", out[1])
+
+	// 別のユーザーの登録情報は使わない
+	out, redaction = redactor.Redact(featureCtx(8, domainai.FeatureInterview), "Emma Watanabe です")
+	assert.Nil(t, redaction)
+	assert.Equal(t, "Emma Watanabe です", out[0])
+}
+
+func TestPIIRedactor_FeaturePolicy(t *testing.T) {
+	redactor := services.NewPIIRedactor(nil)
+	text := "090-1234-5678 / 東京大学"
+
+	out, redaction := redactor.Redact(featureCtx(1, domainai.FeatureCrawl), text)
+	assert.Nil(t, redaction, "公開情報を扱うクロールは置き換えない")
+	assert.Equal(t, text, out[0])
+
+	out, _ = redactor.Redact(featureCtx(1, domainai.FeatureGitHubSummary), text)
+	assert.Equal(t, "[PHONE_1] / 東京大学", out[0], "機能ごとに置き換える種類を決める")
+
+	redactor.SetPolicy(domainai.FeatureChat, services.PIIPolicy{})
+	out, redaction = redactor.Redact(featureCtx(1, domainai.FeatureChat), text)
+	assert.Nil(t, redaction, "機能ごとに無効にできる")
+	assert.Equal(t, text, out[0])
+
+	var disabled *services.PIIRedactor
+	out, redaction = disabled.Redact(featureCtx(1, domainai.FeatureResumeReview), text)
+	assert.Nil(t, redaction)
+	assert.Equal(t, text, out[0])
+}
+
+func TestRedactingAdapter_RedactsPromptsAndRestoresOutput(t *testing.T) {
+	inner := &echoLLM{reply: "[NAME_1]さんの強みは粘り強さです。[PHONE_1] には連絡しません。"}
+	client := internalai.NewRedactingAdapter(inner, services.NewPIIRedactor(nil))
+	ctx := featureCtx(1, domainai.FeatureResumeReview)
+
+	out, err := client.GenerateText(ctx, "あなたは採用担当です", "氏名: 山田太郎\n電話: 090-1234-5678")
+	require.NoError(t, err)
+	require.Len(t, inner.prompts, 2)
+	assert.Equal(t, "氏名: [NAME_1]\n電話: [PHONE_1]", inner.prompts[1], "プロバイダーには記号だけを送る")
+	assert.Equal(t, "山田太郎さんの強みは粘り強さです。090-1234-5678 には連絡しません。", out)
+
+	inner.messages = nil
+	inner.reply = "はい、[EMAIL_1] ですね"
+	out, err = client.Chat(ctx, []domainai.Message{{Role: "user", Content: "メールは a.b@example.com です"}})
+	require.NoError(t, err)
+	assert.Equal(t, "メールは [EMAIL_1] です", inner.messages[0].Content)
+	assert.Equal(t, "はい、a.b@example.com ですね", out)
+}
+
+func TestRedactingAdapter_RestoresJSONAndStreams(t *testing.T) {
+	inner := &echoLLM{reply: `{"summary":"[NAME_1]の住所は[ADDRESS_1]"}`}
+	users := &mockLoginUserRepo{user: &entity.User{ID: 1, Name: `Anne "Annie" Lee`}}
+	client := internalai.NewRedactingAdapter(inner, services.NewPIIRedactor(users))
+	ctx := featureCtx(1, domainai.FeatureResumeReview)
+
+	out, err := client.GenerateJSON(ctx, "", "Anne \"Annie\" Lee\n京都府京都市左京区吉田本町1番")
+	require.NoError(t, err)
+	assert.Equal(t, "[NAME_1]\n[ADDRESS_1]", inner.prompts[1])
+	assert.Equal(t, `{"summary":"Anne \"Annie\" Leeの住所は京都府京都市左京区吉田本町1番"}`, out, "元の値は JSON の文字列としてエスケープする")
+
+	// 記号が差分の境目で分かれても元に戻してから送る
+	inner.reply = "[PHONE_1] に電話"
+	inner.deltas = []string{"[PH", "ONE_", "1] に", "電話"}
+	var streamed []string
+	streamCtx := domainai.WithTextStream(ctx, func(delta string) { streamed = append(streamed, delta) })
+	out, err = client.GenerateText(streamCtx, "", "電話 080-9876-5432")
+	require.NoError(t, err)
+	assert.Equal(t, "080-9876-5432 に電話", out)
+	assert.Equal(t, "080-9876-5432 に電話", strings.Join(streamed, ""))
+	for _, d := range streamed {
+		assert.NotContains(t, d, "[PH")
+	}
+}
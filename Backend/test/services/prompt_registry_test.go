@@ -0,0 +1,298 @@
+package services_test
+
+// プロンプトレジストリのテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Prompt -v
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/services"
+	"Backend/internal/services/prompts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ repository.PromptVersionRepository = (*memoryPromptRepo)(nil)
+
+// memoryPromptRepo は repository.PromptVersionRepository のインメモリ実装。
+type memoryPromptRepo struct {
+	versions     []models.PromptVersion
+	publications []models.PromptPublication
+	listActive   int
+}
+
+func (m *memoryPromptRepo) ListActive() ([]models.PromptVersion, error) {
+	m.listActive++
+	var out []models.PromptVersion
+	for _, v := range m.versions {
+		if v.IsActive {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryPromptRepo) ListByName(name string) ([]models.PromptVersion, error) {
+	var out []models.PromptVersion
+	for _, v := range m.versions {
+		if v.Name == name {
+			out = append(out, v)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version > out[j].Version })
+	return out, nil
+}
+
+func (m *memoryPromptRepo) FindVersion(name string, version int) (*models.PromptVersion, error) {
+	for _, v := range m.versions {
+		if v.Name == name && v.Version == version {
+			return &v, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryPromptRepo) CreateNextVersion(v *models.PromptVersion) error {
+	v.Version = 1
+	for _, existing := range m.versions {
+		if existing.Name == v.Name && existing.Version >= v.Version {
+			v.Version = existing.Version + 1
+		}
+	}
+	v.ID = uint(len(m.versions) + 1)
+	m.versions = append(m.versions, *v)
+	return nil
+}
+
+func (m *memoryPromptRepo) Activate(name string, version int, publishedAt time.Time) error {
+	for i := range m.versions {
+		v := &m.versions[i]
+		if v.Name != name {
+			continue
+		}
+		v.IsActive = v.Version == version
+		if v.IsActive {
+			at := publishedAt
+			v.PublishedAt = &at
+		}
+	}
+	return nil
+}
+
+func (m *memoryPromptRepo) RecordPublication(p *models.PromptPublication) error {
+	p.ID = uint(len(m.publications) + 1)
+	m.publications = append(m.publications, *p)
+	return nil
+}
+
+func (m *memoryPromptRepo) ListPublications(name string) ([]models.PromptPublication, error) {
+	var out []models.PromptPublication
+	for i := len(m.publications) - 1; i >= 0; i-- {
+		if p := m.publications[i]; p.Name == name && p.RevertedAt == nil {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryPromptRepo) RevertPublication(id uint, revertedAt time.Time) error {
+	for i := range m.publications {
+		if m.publications[i].ID == id {
+			at := revertedAt
+			m.publications[i].RevertedAt = &at
+		}
+	}
+	return nil
+}
+
+const esRewriteOverride = `{{define "system"}}system v{{end}}{{define "user"}}[{{.QuestionType}}] {{.OriginalText}}{{end}}`
+
+func TestPromptRegistry_BuiltinsRender(t *testing.T) {
+	var registry *prompts.Registry // nil ならファイルの既定版だけを使う
+	for _, name := range prompts.Names() {
+		_, version, variables, ok := prompts.Builtin(name)
+		require.True(t, ok)
+		vars := prompts.Vars{}
+		for _, v := range variables {
+			vars[v] = "x"
+		}
+		rendered, err := registry.Render(context.Background(), name, vars)
+		require.NoError(t, err, name)
+		assert.Equal(t, version, rendered.Version)
+		assert.True(t, strings.HasPrefix(rendered.Version, "builtin-"))
+		assert.NotEmpty(t, rendered.User, name)
+	}
+
+	rendered, err := registry.Render(context.Background(), prompts.ESRewrite, prompts.Vars{"QuestionType": "自己PR", "OriginalText": "頑張りました。", "TechStack": ""})
+	require.NoError(t, err)
+	assert.Contains(t, rendered.User, "【質問種別】自己PR\n【元のES文章】\n頑張りました。\n\n## リライトのルール")
+	assert.NotContains(t, rendered.User, "使用技術スタック", "任意の変数が空なら出力しない")
+
+	rendered, err = registry.Render(context.Background(), prompts.InterviewReport, prompts.Vars{"Language": "xx", "Transcript": "User: hi"})
+	require.NoError(t, err)
+	assert.Contains(t, rendered.System, `language code "xx"`)
+
+	_, err = registry.Render(context.Background(), "missing", nil)
+	assert.ErrorIs(t, err, prompts.ErrUnknownPrompt)
+}
+
+func TestPromptRegistry_QuestionPromptsShareGuidelines(t *testing.T) {
+	var registry *prompts.Registry
+	vars := prompts.Vars{"History": "user: わかりません", "LastQuestion": "得意なことは？", "IndustryID": 1, "JobCategoryID": 2}
+	rendered, err := registry.Render(context.Background(), prompts.QuestionLowConfidence, vars)
+	require.NoError(t, err)
+	assert.Contains(t, rendered.User, "学生が前の質問「得意なことは？」に答えられなかったようです。")
+	assert.Contains(t, rendered.User, "## 【重要】新卒学生向け質問作成ガイドライン")
+
+	// 管理画面から登録する版でも共通のガイドラインを参照できる
+	body := `{{define "user"}}{{.History}}{{template "new_grad_guidelines"}}{{end}}`
+	require.NoError(t, prompts.Validate(prompts.QuestionLowConfidence, body))
+
+	strategic := prompts.Vars{
+		"MidCareer": true, "Phase": "", "ForceTextQuestion": false, "History": "", "Scores": "", "AskedQuestions": "", "AskedCount": 0,
+		"Purpose": "", "Category": "技術志向", "Description": "", "JobCategoryName": "エンジニア", "IndustryID": 1, "JobCategoryID": 2,
+		"PhaseDisplayName": "", "PhaseDescription": "", "PhaseMinQuestions": 0, "PhaseMaxQuestions": 0, "QuestionNumber": 0,
+	}
+	rendered, err = registry.Render(context.Background(), prompts.QuestionStrategic, strategic)
+	require.NoError(t, err)
+	assert.Contains(t, rendered.User, "## 【重要】中途向け質問ガイドライン")
+	assert.Contains(t, rendered.User, "（まだ質問していません）")
+	assert.NotContains(t, rendered.User, "現在の分析フェーズ", "フェーズがなければフェーズの説明を出さない")
+}
+
+func TestPromptService_PublishAndRollback(t *testing.T) {
+	repo := &memoryPromptRepo{}
+	registry := prompts.NewRegistry(repo)
+	svc := services.NewPromptService(repo, registry)
+	ctx := context.Background()
+	vars := prompts.Vars{"QuestionType": "自己PR", "OriginalText": "本文", "TechStack": ""}
+
+	created, err := svc.CreateVersion(prompts.ESRewrite, esRewriteOverride, "短縮版", true, "admin@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", created.Version)
+
+	rendered, err := registry.Render(ctx, prompts.ESRewrite, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "es_rewrite@v1", rendered.Ref())
+	assert.Equal(t, "system v", rendered.System)
+	assert.Equal(t, "[自己PR] 本文", rendered.User)
+
+	// 公開せずに登録した版は使われない
+	_, err = svc.CreateVersion(prompts.ESRewrite, strings.Replace(esRewriteOverride, "system v", "system v2", 1), "", false, "admin@example.com")
+	require.NoError(t, err)
+	rendered, err = registry.Render(ctx, prompts.ESRewrite, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", rendered.Version)
+
+	summary, err := svc.Publish(prompts.ESRewrite, "v2", "admin@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", summary.ActiveVersion)
+	rendered, _ = registry.Render(ctx, prompts.ESRewrite, vars)
+	assert.Equal(t, "system v2", rendered.System)
+
+	// ロールバックは直前に公開していた版、その次はファイルの既定版に戻す
+	summary, err = svc.Rollback(prompts.ESRewrite)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", summary.ActiveVersion)
+	summary, err = svc.Publish(prompts.ESRewrite, prompts.BuiltinVersion, "admin@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, summary.BuiltinVersion, summary.ActiveVersion)
+	rendered, _ = registry.Render(ctx, prompts.ESRewrite, vars)
+	assert.Equal(t, summary.BuiltinVersion, rendered.Version)
+
+	versions, err := svc.Versions(prompts.ESRewrite)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, "v2", versions[0].Version)
+	assert.True(t, versions[2].Builtin)
+	assert.True(t, versions[2].IsActive)
+
+	_, err = svc.Publish(prompts.ESRewrite, "v9", "admin@example.com")
+	assert.ErrorIs(t, err, prompts.ErrVersionNotFound)
+}
+
+func TestPromptService_RollbackWalksPublishHistoryBackwards(t *testing.T) {
+	repo := &memoryPromptRepo{}
+	svc := services.NewPromptService(repo, prompts.NewRegistry(repo))
+	for i := 1; i <= 3; i++ {
+		_, err := svc.CreateVersion(prompts.ESRewrite, strings.Replace(esRewriteOverride, "system v", fmt.Sprintf("system v%d", i), 1), "", true, "admin@example.com")
+		require.NoError(t, err)
+	}
+	_, builtinVersion, _, _ := prompts.Builtin(prompts.ESRewrite)
+
+	// v1 → v2 → v3 と公開した後のロールバックは v2、v1、既定版の順に戻る（v3 には戻らない）
+	for _, want := range []string{"v2", "v1", builtinVersion} {
+		summary, err := svc.Rollback(prompts.ESRewrite)
+		require.NoError(t, err)
+		assert.Equal(t, want, summary.ActiveVersion)
+	}
+	_, err := svc.Rollback(prompts.ESRewrite)
+	assert.ErrorIs(t, err, prompts.ErrVersionNotFound, "既定版より前には戻れない")
+
+	// 既定版を公開した後のロールバックは最新の版ではなく、その直前に公開していた版に戻す
+	_, err = svc.Publish(prompts.ESRewrite, "v1", "admin@example.com")
+	require.NoError(t, err)
+	_, err = svc.Publish(prompts.ESRewrite, prompts.BuiltinVersion, "admin@example.com")
+	require.NoError(t, err)
+	summary, err := svc.Rollback(prompts.ESRewrite)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", summary.ActiveVersion)
+}
+
+func TestPromptService_RejectsInvalidTemplates(t *testing.T) {
+	repo := &memoryPromptRepo{}
+	svc := services.NewPromptService(repo, prompts.NewRegistry(repo))
+
+	_, err := svc.CreateVersion(prompts.ESRewrite, `{{define "user"}}{{.Unknown}}{{end}}`, "", true, "a")
+	assert.ErrorIs(t, err, prompts.ErrInvalidTemplate, "既定版にない変数は使えない")
+	_, err = svc.CreateVersion(prompts.ESRewrite, `{{define "user"}}{{.OriginalText}`, "", true, "a")
+	assert.ErrorIs(t, err, prompts.ErrInvalidTemplate)
+	_, err = svc.CreateVersion(prompts.ESRewrite, `{{define "system"}}only system{{end}}`, "", true, "a")
+	assert.ErrorIs(t, err, prompts.ErrInvalidTemplate)
+	_, err = svc.CreateVersion("missing", esRewriteOverride, "", true, "a")
+	assert.ErrorIs(t, err, prompts.ErrUnknownPrompt)
+	assert.Empty(t, repo.versions)
+}
+
+func TestPromptRegistry_PinnedVersionsAndCache(t *testing.T) {
+	repo := &memoryPromptRepo{}
+	registry := prompts.NewRegistry(repo)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry.SetNow(func() time.Time { return now })
+	svc := services.NewPromptService(repo, registry)
+	vars := prompts.Vars{"QuestionType": "q", "OriginalText": "t", "TechStack": ""}
+
+	_, err := svc.CreateVersion(prompts.ESRewrite, esRewriteOverride, "", false, "a")
+	require.NoError(t, err)
+
+	// 公開中の版は既定版のまま、固定した版を使う
+	pinned := prompts.WithPinnedVersions(context.Background(), map[string]string{prompts.ESRewrite: "v1"})
+	rendered, err := registry.Render(pinned, prompts.ESRewrite, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", rendered.Version)
+
+	// 存在しない版の固定は公開中の版で代替する
+	missing := prompts.WithPinnedVersions(context.Background(), map[string]string{prompts.ESRewrite: "v7"})
+	rendered, err = registry.Render(missing, prompts.ESRewrite, vars)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rendered.Version, "builtin-"))
+
+	// 公開中の版は TTL の間キャッシュし、DB を直接書き換えても TTL 経過後に反映される
+	calls := repo.listActive
+	require.NoError(t, repo.Activate(prompts.ESRewrite, 1, now))
+	rendered, _ = registry.Render(context.Background(), prompts.ESRewrite, vars)
+	assert.True(t, strings.HasPrefix(rendered.Version, "builtin-"))
+	assert.Equal(t, calls, repo.listActive)
+	now = now.Add(time.Minute)
+	rendered, _ = registry.Render(context.Background(), prompts.ESRewrite, vars)
+	assert.Equal(t, "v1", rendered.Version)
+}
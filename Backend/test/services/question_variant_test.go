@@ -0,0 +1,90 @@
+package services_test
+
+// A/B テストのバリアント設定のテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Variant -v
+
+import (
+	"errors"
+	"testing"
+
+	"Backend/domain/entity"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func variantAssignment(id uint, experiment, name, configJSON string) models.VariantAssignment {
+	return models.VariantAssignment{
+		ID:              id,
+		VariantID:       id,
+		ExperimentName:  experiment,
+		AssignedVariant: name,
+		Variant:         &models.QuestionVariant{ID: id, ExperimentName: experiment, VariantName: name, ConfigJSON: configJSON},
+	}
+}
+
+func TestSessionVariants_NoAssignmentKeepsDefaults(t *testing.T) {
+	var variants *services.SessionVariants
+	assert.Nil(t, services.NewSessionVariants(nil))
+
+	assert.True(t, variants.AllowsPredefinedQuestion(1))
+	assert.False(t, variants.PredefinedQuestionsDisabled())
+	assert.Equal(t, 80, variants.ScaleScore("技術志向", 80))
+	assert.Equal(t, 0.3, variants.RecentAnswerWeight())
+	phase := entity.AnalysisPhase{PhaseName: "interest_analysis", MinQuestions: 3, MaxQuestions: 5}
+	assert.Equal(t, phase, variants.ApplyPhase(phase))
+}
+
+func TestSessionVariants_AppliesQuestionPoolPhasesAndScoring(t *testing.T) {
+	variants := services.NewSessionVariants([]models.VariantAssignment{
+		variantAssignment(1, "pool_2026", "curated", `{"question_pool":{"question_ids":[10,11],"exclude_question_ids":[11]}}`),
+		variantAssignment(2, "length_2026", "short", `{"phase_questions":{"interest_analysis":{"max_questions":2}},"scoring":{"category_multipliers":{"技術志向":1.5,"*":0.5},"recent_answer_weight":0.5}}`),
+	})
+	require.NotNil(t, variants)
+	require.Len(t, variants.Assignments, 2)
+
+	assert.True(t, variants.AllowsPredefinedQuestion(10))
+	assert.False(t, variants.AllowsPredefinedQuestion(11), "除外した質問は使わない")
+	assert.False(t, variants.AllowsPredefinedQuestion(12), "プールにない質問は使わない")
+
+	phases := variants.ApplyPhases([]entity.AnalysisPhase{
+		{PhaseName: "job_analysis", MinQuestions: 1, MaxQuestions: 1},
+		{PhaseName: "interest_analysis", MinQuestions: 3, MaxQuestions: 5},
+	})
+	assert.Equal(t, 1, phases[0].MaxQuestions)
+	assert.Equal(t, 2, phases[1].MaxQuestions)
+	assert.Equal(t, 2, phases[1].MinQuestions, "最小は最大を超えない")
+
+	assert.Equal(t, 100, variants.ScaleScore("技術志向", 80), "100 を超えない")
+	assert.Equal(t, 30, variants.ScaleScore("チームワーク", 60), "\"*\" は他のカテゴリに使う")
+	assert.Equal(t, 0.5, variants.RecentAnswerWeight())
+
+	// 後の割り当てが同じ項目を上書きする
+	variants = services.NewSessionVariants([]models.VariantAssignment{
+		variantAssignment(1, "pool_2026", "curated", `{"question_pool":{"question_ids":[10]}}`),
+		variantAssignment(2, "ai_only_2026", "ai", `{"question_pool":{"disable_predefined":true}}`),
+	})
+	assert.True(t, variants.PredefinedQuestionsDisabled())
+	assert.False(t, variants.AllowsPredefinedQuestion(10))
+}
+
+func TestVariantConfig_Validate(t *testing.T) {
+	valid := &services.VariantConfig{
+		PhaseQuestions: map[string]services.VariantPhaseLimits{"interest_analysis": {MinQuestions: 2, MaxQuestions: 4}},
+		Scoring:        &services.VariantScoring{CategoryMultipliers: map[string]float64{"*": 1.2}, RecentAnswerWeight: 0.4},
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := []*services.VariantConfig{
+		{PhaseQuestions: map[string]services.VariantPhaseLimits{"interest_analysis": {MinQuestions: 5, MaxQuestions: 2}}},
+		{PhaseQuestions: map[string]services.VariantPhaseLimits{"interest_analysis": {MaxQuestions: -1}}},
+		{Scoring: &services.VariantScoring{CategoryMultipliers: map[string]float64{"技術志向": -1}}},
+		{Scoring: &services.VariantScoring{RecentAnswerWeight: 1.5}},
+	}
+	for _, cfg := range invalid {
+		assert.True(t, errors.Is(cfg.Validate(), services.ErrInvalidVariantConfig), "%+v", cfg)
+	}
+}
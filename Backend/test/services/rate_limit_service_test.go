@@ -0,0 +1,46 @@
+package services_test
+
+// レート制限値の変更（RateLimitService）のユニットテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run RateLimitService -v
+
+import (
+	"testing"
+
+	"Backend/internal/models"
+	"Backend/internal/ratelimit"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRateLimitSettingRepo は保存された設定を記録する RateLimitSettingRepository モック。
+type mockRateLimitSettingRepo struct {
+	saved []models.RateLimitSetting
+}
+
+func (m *mockRateLimitSettingRepo) List() ([]models.RateLimitSetting, error) { return m.saved, nil }
+func (m *mockRateLimitSettingRepo) Upsert(setting *models.RateLimitSetting) error {
+	m.saved = append(m.saved, *setting)
+	return nil
+}
+
+func TestRateLimitService_UpdateKeepsEnabledWhenOmitted(t *testing.T) {
+	repo := &mockRateLimitSettingRepo{}
+	limiter := ratelimit.NewLimiter()
+	limiter.SetLimit(ratelimit.GroupChat, ratelimit.Limit{Enabled: false, UserPerMinute: 10, UserBurst: 5})
+	svc := services.NewRateLimitService(repo, limiter)
+
+	updated, err := svc.Update(ratelimit.GroupChat, services.RateLimitUpdate{UserPerMinute: 20, UserBurst: 5}, "admin@example.com")
+	require.NoError(t, err)
+	assert.False(t, updated.Enabled, "enabled を省略した変更では無効のまま")
+	assert.False(t, repo.saved[0].Enabled)
+	assert.Equal(t, 20.0, limiter.Limits()[ratelimit.GroupChat].UserPerMinute)
+
+	enabled := true
+	updated, err = svc.Update(ratelimit.GroupChat, services.RateLimitUpdate{Enabled: &enabled, UserPerMinute: 20, UserBurst: 5}, "admin@example.com")
+	require.NoError(t, err)
+	assert.True(t, updated.Enabled)
+	assert.True(t, limiter.Limits()[ratelimit.GroupChat].Enabled)
+}
@@ -0,0 +1,129 @@
+package services_test
+
+// スコアキャリブレーション重みのマッチングへの適用のテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Calibration -v
+
+import (
+	"context"
+	"testing"
+
+	"Backend/domain/entity"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type calibrationWeightScoreRepo struct {
+	repository.UserWeightScoreRepository
+	scores []entity.UserWeightScore
+}
+
+func (r *calibrationWeightScoreRepo) FindByUserAndSession(uint, string) ([]entity.UserWeightScore, error) {
+	return r.scores, nil
+}
+
+type calibrationCompanyRepo struct {
+	repository.CompanyRepository
+	profile *models.CompanyWeightProfile
+}
+
+func (r *calibrationCompanyRepo) FindAllActive(int, int) ([]models.Company, error) {
+	return []models.Company{{ID: 1, Name: "テスト株式会社"}}, nil
+}
+
+func (r *calibrationCompanyRepo) GetWeightProfile(uint, *uint) (*models.CompanyWeightProfile, error) {
+	return r.profile, nil
+}
+
+type calibrationMatchRepo struct {
+	repository.UserCompanyMatchRepository
+	saved []*entity.UserCompanyMatch
+}
+
+func (r *calibrationMatchRepo) CreateOrUpdate(match *entity.UserCompanyMatch) error {
+	r.saved = append(r.saved, match)
+	return nil
+}
+
+type stubCalibrationSource struct {
+	active, shadow *services.CalibrationWeights
+}
+
+func (s stubCalibrationSource) ActiveCalibration() *services.CalibrationWeights { return s.active }
+func (s stubCalibrationSource) ShadowCalibration() *services.CalibrationWeights { return s.shadow }
+
+// calculateWithCalibration は技術志向 100 点一致・チームワーク志向 50 点差のユーザーでマッチングを計算する
+func calculateWithCalibration(t *testing.T, source services.CalibrationSource) *entity.UserCompanyMatch {
+	t.Helper()
+	matches := &calibrationMatchRepo{}
+	svc := services.NewMatchingService(
+		&calibrationWeightScoreRepo{scores: []entity.UserWeightScore{
+			{WeightCategory: "技術志向", Score: 80},
+			{WeightCategory: "チームワーク志向", Score: 20},
+		}},
+		&calibrationCompanyRepo{profile: &models.CompanyWeightProfile{TechnicalOrientation: 80, TeamworkOrientation: 70}},
+		matches,
+	)
+	if source != nil {
+		svc.SetCalibrationSource(source)
+	}
+	require.NoError(t, svc.CalculateMatching(context.Background(), 1, "session-1"))
+	require.Len(t, matches.saved, 1)
+	return matches.saved[0]
+}
+
+func TestCalibrationWeights_NilAndMissingCategory(t *testing.T) {
+	var none *services.CalibrationWeights
+	assert.Nil(t, services.NewCalibrationWeights(nil))
+	assert.Equal(t, 1.0, none.Weight("技術志向"))
+	assert.Equal(t, 0, none.VersionNumber())
+
+	weights := services.NewCalibrationWeights([]models.ScoreCalibrationWeight{
+		{Category: "技術志向", Version: 3, Weight: 1.5},
+		{Category: "安定志向", Version: 3, Weight: -0.2},
+	})
+	require.NotNil(t, weights)
+	assert.Equal(t, 3, weights.VersionNumber())
+	assert.Equal(t, 1.5, weights.Weight("技術志向"))
+	assert.Equal(t, 0.0, weights.Weight("安定志向"), "負の重みは 0 として扱う")
+	assert.Equal(t, 1.0, weights.Weight("成長志向"), "版にないカテゴリは 1.0")
+}
+
+func TestCalculateMatching_AppliesActiveCalibration(t *testing.T) {
+	// キャリブレーションなしは単純平均 (100 + 50) / 2
+	match := calculateWithCalibration(t, nil)
+	assert.InDelta(t, 75.0, match.MatchScore, 0.001)
+	assert.Equal(t, 0, match.CalibrationVersion)
+	assert.Nil(t, match.ShadowMatchScore)
+
+	// 有効な版の重みで加重平均 (100*3 + 50*1) / 4
+	active := services.NewCalibrationWeights([]models.ScoreCalibrationWeight{
+		{Category: "技術志向", Version: 2, Weight: 3},
+	})
+	match = calculateWithCalibration(t, stubCalibrationSource{active: active})
+	assert.InDelta(t, 87.5, match.MatchScore, 0.001)
+	assert.Equal(t, 2, match.CalibrationVersion)
+	assert.InDelta(t, 100.0, match.TechnicalMatch, 0.001, "カテゴリ別のマッチ度は重みで変えない")
+	assert.Nil(t, match.ShadowMatchScore)
+}
+
+func TestCalculateMatching_RecordsShadowCalibration(t *testing.T) {
+	shadow := services.NewCalibrationWeights([]models.ScoreCalibrationWeight{
+		{Category: "チームワーク志向", Version: 4, Weight: 3},
+	})
+	match := calculateWithCalibration(t, stubCalibrationSource{shadow: shadow})
+	assert.InDelta(t, 75.0, match.MatchScore, 0.001, "有効な版がなければ重みなし")
+	assert.Equal(t, 0, match.CalibrationVersion)
+	require.NotNil(t, match.ShadowMatchScore)
+	assert.InDelta(t, 62.5, *match.ShadowMatchScore, 0.001, "(100*1 + 50*3) / 4")
+	assert.Equal(t, 4, match.ShadowCalibrationVersion)
+
+	// 有効な版と同じ版はシャドー計算しない
+	match = calculateWithCalibration(t, stubCalibrationSource{active: shadow, shadow: shadow})
+	assert.Equal(t, 4, match.CalibrationVersion)
+	assert.Nil(t, match.ShadowMatchScore)
+}
@@ -0,0 +1,45 @@
+package services_test
+
+// A/B テストのバリアント割り当て（ScoreValidationService.AssignVariant）のテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run AssignVariant -v
+
+import (
+	"testing"
+
+	"Backend/internal/repositories"
+	"Backend/internal/services"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAssignVariant_ConcurrentInsertReadsExistingAssignment(t *testing.T) {
+	db, mock, executed := newMergeTestDB(t)
+	svc := services.NewScoreValidationService(repositories.NewScoreValidationRepository(db))
+
+	variantColumns := []string{"id", "experiment_name", "variant_name", "is_active", "traffic_ratio"}
+	mock.ExpectQuery("find assignment").WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery("list variants").WillReturnRows(sqlmock.NewRows(variantColumns).
+		AddRow(1, "exp", "control", true, 1.0))
+	// 並行したリクエストが先に割り当てていたので一意インデックスに当たり、挿入されない
+	mock.ExpectBegin()
+	mock.ExpectExec("insert").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery("find assignment").WillReturnRows(sqlmock.NewRows(
+		[]string{"id", "user_id", "session_id", "variant_id", "experiment_name", "assigned_variant"}).
+		AddRow(7, 1, "s-1", 2, "exp", "treatment"))
+	mock.ExpectQuery("preload variant").WillReturnRows(sqlmock.NewRows(variantColumns).
+		AddRow(2, "exp", "treatment", true, 1.0))
+
+	assignment, err := svc.AssignVariant(1, "s-1", "exp")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, uint(7), assignment.ID)
+	assert.Equal(t, "treatment", assignment.AssignedVariant)
+	require.NotNil(t, assignment.Variant)
+	assert.Equal(t, "treatment", assignment.Variant.VariantName)
+	assert.Contains(t, (*executed)[2], "ON DUPLICATE KEY UPDATE")
+}
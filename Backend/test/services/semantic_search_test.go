@@ -0,0 +1,271 @@
+package services_test
+
+// 企業の意味検索（埋め込みのインデックス）のテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run Semantic -v
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	domainai "Backend/domain/ai"
+	"Backend/domain/repository"
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type memoryEntityEmbeddingRepo struct {
+	rows      []models.EntityEmbedding
+	upsertErr error
+}
+
+func (m *memoryEntityEmbeddingRepo) Find(kind string, entityID uint) (*models.EntityEmbedding, error) {
+	for i := range m.rows {
+		if m.rows[i].Kind == kind && m.rows[i].EntityID == entityID {
+			row := m.rows[i]
+			return &row, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memoryEntityEmbeddingRepo) Upsert(embedding *models.EntityEmbedding) error {
+	if m.upsertErr != nil {
+		return m.upsertErr
+	}
+	for i := range m.rows {
+		if m.rows[i].Kind == embedding.Kind && m.rows[i].EntityID == embedding.EntityID {
+			embedding.ID = m.rows[i].ID
+			m.rows[i] = *embedding
+			return nil
+		}
+	}
+	embedding.ID = uint(len(m.rows) + 1)
+	m.rows = append(m.rows, *embedding)
+	return nil
+}
+
+func (m *memoryEntityEmbeddingRepo) ListByKind(kind string, afterID uint, limit int) ([]models.EntityEmbedding, error) {
+	var out []models.EntityEmbedding
+	for _, row := range m.rows {
+		if row.Kind == kind && row.ID > afterID && len(out) < limit {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryEntityEmbeddingRepo) Delete(kind string, entityID uint) error {
+	for i := range m.rows {
+		if m.rows[i].Kind == kind && m.rows[i].EntityID == entityID {
+			m.rows = append(m.rows[:i], m.rows[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// memoryCompanyRepo は意味検索で使う企業・募集職種の読み取りだけを実装する CompanyRepository。
+type memoryCompanyRepo struct {
+	repository.CompanyRepository
+	companies []models.Company
+	positions []models.CompanyJobPosition
+}
+
+func (m *memoryCompanyRepo) FindAllActive(limit, offset int) ([]models.Company, error) {
+	var active []models.Company
+	for _, c := range m.companies {
+		if c.IsActive {
+			active = append(active, c)
+		}
+	}
+	if offset >= len(active) {
+		return nil, nil
+	}
+	return active[offset:min(offset+limit, len(active))], nil
+}
+
+func (m *memoryCompanyRepo) FindByID(id uint) (*models.Company, error) {
+	for _, c := range m.companies {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memoryCompanyRepo) FindJobPositionsByCompany(companyID uint) ([]models.CompanyJobPosition, error) {
+	var out []models.CompanyJobPosition
+	for _, p := range m.positions {
+		if p.CompanyID == companyID && p.IsActive && p.DataStatus == "published" {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryCompanyRepo) FindJobPositionByID(id uint) (*models.CompanyJobPosition, error) {
+	for _, p := range m.positions {
+		if p.ID == id {
+			return &p, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// keywordEmbedder はキーワードの出現回数を次元とする埋め込みを返す LLM のスタブ。
+type keywordEmbedder struct {
+	domainai.LLMClient
+	calls []string
+	fail  bool
+}
+
+var embedderKeywords = []string{"ゲーム", "金融", "機械学習", "医療", "リモート"}
+
+func (k *keywordEmbedder) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	k.calls = append(k.calls, domainai.CallContextFrom(ctx).Feature+":"+text)
+	if k.fail {
+		return nil, errors.New("embedding unavailable")
+	}
+	vec := make([]float32, len(embedderKeywords)+1)
+	for i, kw := range embedderKeywords {
+		vec[i] = float32(strings.Count(text, kw))
+	}
+	vec[len(embedderKeywords)] = 0.1
+	return vec, nil
+}
+
+func newSemanticFixture() (*memoryCompanyRepo, *memoryEntityEmbeddingRepo, *keywordEmbedder) {
+	companies := &memoryCompanyRepo{
+		companies: []models.Company{
+			{ID: 1, Name: "プレイワークス", Industry: "エンタメ", Description: "スマホ向けゲームを開発。ゲームエンジンも自社開発", IsActive: true},
+			{ID: 2, Name: "みらい銀行システムズ", Industry: "金融", Description: "金融機関向けの勘定系システム", IsActive: true},
+			{ID: 3, Name: "メディカルAI", Industry: "ヘルスケア", Description: "医療画像の解析サービス", IsActive: true},
+			{ID: 4, Name: "休眠ゲームス", Description: "ゲーム ゲーム ゲーム", IsActive: false},
+		},
+		positions: []models.CompanyJobPosition{
+			{ID: 10, CompanyID: 2, Title: "機械学習エンジニア", Description: "金融データの機械学習モデルを開発。リモート可", IsActive: true, DataStatus: "published"},
+			{ID: 11, CompanyID: 3, Title: "営業", Description: "医療機関への提案", IsActive: true, DataStatus: "published"},
+			{ID: 12, CompanyID: 1, Title: "下書きの職種", Description: "機械学習 機械学習", IsActive: true, DataStatus: "draft"},
+		},
+	}
+	return companies, &memoryEntityEmbeddingRepo{}, &keywordEmbedder{}
+}
+
+func TestSemanticSearch_ReindexSkipsUnchangedText(t *testing.T) {
+	companies, embeddings, llm := newSemanticFixture()
+	svc := services.NewSemanticSearchService(llm, services.NewEmbeddingStore(embeddings), companies)
+
+	stats, err := svc.Reindex(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, services.SemanticIndexStats{Companies: 3, JobPositions: 2, Embedded: 5}, stats, "アクティブな企業と公開中の職種だけを対象にする")
+	require.Len(t, embeddings.rows, 5)
+	assert.Len(t, embeddings.rows[0].Vector, (len(embedderKeywords)+1)*4, "float32 のバイト列で保存する")
+	for _, call := range llm.calls {
+		assert.True(t, strings.HasPrefix(call, domainai.FeatureSemanticSearch+":"), "コストを意味検索に集計する")
+	}
+
+	stats, err = svc.Reindex(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Embedded, "テキストが変わらなければ作り直さない")
+	assert.Len(t, llm.calls, 5)
+
+	companies.companies[1].Name = "みらいフィナンシャル"
+	stats, err = svc.Reindex(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Embedded, "変わった企業と、企業名を含む職種だけを作り直す")
+	assert.Equal(t, 2, svc.Status().IndexedJobPositions)
+}
+
+func TestSemanticSearch_FindsCompaniesByDescriptionAndPositions(t *testing.T) {
+	companies, embeddings, llm := newSemanticFixture()
+	svc := services.NewSemanticSearchService(llm, services.NewEmbeddingStore(embeddings), companies)
+	_, err := svc.Reindex(context.Background())
+	require.NoError(t, err)
+
+	results, err := svc.SearchCompanies(userCtx(5), "ゲームを作る会社で働きたい", 2)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "プレイワークス", results[0].Company.Name)
+	for _, r := range results {
+		assert.NotEqual(t, uint(4), r.Company.ID, "非アクティブな企業は返さない")
+	}
+
+	results, err = svc.SearchCompanies(userCtx(5), "リモートで機械学習をやりたい", 3)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, uint(2), results[0].Company.ID, "募集職種が近い企業も見つける")
+	require.Len(t, results[0].JobPositions, 1)
+	assert.Equal(t, "機械学習エンジニア", results[0].JobPositions[0].Title)
+	assert.InDelta(t, results[0].JobPositions[0].Score, results[0].Score, 1e-9)
+	for _, r := range results {
+		for _, p := range r.JobPositions {
+			assert.NotEqual(t, uint(12), p.ID, "公開前の職種は返さない")
+		}
+	}
+	scores := make([]float64, len(results))
+	for i, r := range results {
+		scores[i] = r.Score
+	}
+	assert.True(t, sort.SliceIsSorted(scores, func(i, j int) bool { return scores[i] > scores[j] }))
+	assert.Equal(t, domainai.FeatureSemanticSearch+":リモートで機械学習をやりたい", llm.calls[len(llm.calls)-1])
+}
+
+func TestSemanticSearch_LoadRebuildsIndexFromStorage(t *testing.T) {
+	companies, embeddings, llm := newSemanticFixture()
+	_, err := services.NewSemanticSearchService(llm, services.NewEmbeddingStore(embeddings), companies).Reindex(context.Background())
+	require.NoError(t, err)
+
+	// 再起動後は保存済みの埋め込みからインデックスを作り直す
+	store := services.NewEmbeddingStore(embeddings)
+	require.NoError(t, store.Load())
+	assert.Equal(t, 3, store.Len(services.EmbeddingKindCompany))
+	assert.Equal(t, 2, store.Len(services.EmbeddingKindJobPosition))
+
+	svc := services.NewSemanticSearchService(llm, store, companies)
+	results, err := svc.SearchCompanies(context.Background(), "医療", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "メディカルAI", results[0].Company.Name)
+
+	require.NoError(t, store.Delete(services.EmbeddingKindCompany, 3))
+	assert.Equal(t, 2, store.Len(services.EmbeddingKindCompany))
+	assert.Len(t, embeddings.rows, 4)
+}
+
+func TestSemanticSearch_EmptyIndexAndEmbeddingFailure(t *testing.T) {
+	companies, embeddings, llm := newSemanticFixture()
+	svc := services.NewSemanticSearchService(llm, services.NewEmbeddingStore(embeddings), companies)
+
+	results, err := svc.SearchCompanies(context.Background(), "ゲーム", 5)
+	require.NoError(t, err)
+	assert.Empty(t, results, "インデックスが空なら埋め込みを作らずに空を返す")
+	assert.Empty(t, llm.calls)
+
+	_, err = svc.Reindex(context.Background())
+	require.NoError(t, err)
+	llm.fail = true
+	_, err = svc.SearchCompanies(context.Background(), "ゲーム", 5)
+	assert.ErrorIs(t, err, services.ErrSemanticSearchUnavailable)
+}
+
+func TestSemanticSearch_PutIndexesOnlyPersistedEmbeddings(t *testing.T) {
+	embeddings := &memoryEntityEmbeddingRepo{upsertErr: errors.New("db unavailable")}
+	store := services.NewEmbeddingStore(embeddings)
+
+	err := store.Put(services.EmbeddingKindCompany, 1, "医療AI", []float32{1, 0})
+	require.Error(t, err)
+	assert.Equal(t, 0, store.Len(services.EmbeddingKindCompany), "保存できなかった埋め込みは検索に出さない")
+
+	embeddings.upsertErr = nil
+	require.NoError(t, store.Put(services.EmbeddingKindCompany, 1, "医療AI", []float32{1, 0}))
+	assert.Equal(t, 1, store.Len(services.EmbeddingKindCompany))
+	assert.Len(t, embeddings.rows, 1)
+}
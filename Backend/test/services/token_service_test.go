@@ -0,0 +1,94 @@
+package services_test
+
+// セッショントークン（TokenService）の発行・検証テスト
+//
+// 実行: cd Backend && go test ./test/services/... -run TokenService -v
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTokenSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestTokenService_IssueAndVerify(t *testing.T) {
+	svc := services.NewTokenService(testTokenSecret, time.Hour, 24*time.Hour)
+
+	pair, err := svc.IssuePair(42)
+	require.NoError(t, err)
+
+	claims, err := svc.VerifyAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), claims.UserID)
+	assert.Equal(t, services.TokenTypeAccess, claims.Type)
+
+	claims, err = svc.VerifyRefreshToken(pair.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), claims.UserID)
+}
+
+func TestTokenService_RejectsWrongType(t *testing.T) {
+	svc := services.NewTokenService(testTokenSecret, time.Hour, 24*time.Hour)
+	pair, err := svc.IssuePair(1)
+	require.NoError(t, err)
+
+	_, err = svc.VerifyAccessToken(pair.RefreshToken)
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+	_, err = svc.VerifyRefreshToken(pair.AccessToken)
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+}
+
+func TestTokenService_RejectsTamperedToken(t *testing.T) {
+	svc := services.NewTokenService(testTokenSecret, time.Hour, 24*time.Hour)
+	pair, err := svc.IssuePair(1)
+	require.NoError(t, err)
+
+	// 別ユーザーのペイロードを差し込んでも署名が一致しない
+	other, err := svc.IssuePair(2)
+	require.NoError(t, err)
+	a := strings.Split(pair.AccessToken, ".")
+	b := strings.Split(other.AccessToken, ".")
+	forged := a[0] + "." + b[1] + "." + a[2]
+
+	_, err = svc.VerifyAccessToken(forged)
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+	_, err = svc.VerifyAccessToken("not-a-token")
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+}
+
+func TestTokenService_RejectsOtherSecret(t *testing.T) {
+	issuer := services.NewTokenService(testTokenSecret, time.Hour, 24*time.Hour)
+	verifier := services.NewTokenService([]byte("ffffffffffffffffffffffffffffffff"), time.Hour, 24*time.Hour)
+	pair, err := issuer.IssuePair(1)
+	require.NoError(t, err)
+
+	_, err = verifier.VerifyAccessToken(pair.AccessToken)
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+}
+
+func TestTokenService_OAuthLinkStateBoundToBrowserNonce(t *testing.T) {
+	svc := services.NewTokenService(testTokenSecret, time.Hour, 24*time.Hour)
+	_, err := svc.IssueOAuthLinkState(1, "github", "")
+	assert.Error(t, err)
+
+	state, err := svc.IssueOAuthLinkState(1, "github", "browser-nonce")
+	require.NoError(t, err)
+
+	claims, err := svc.VerifyOAuthLinkState(state, "github", "browser-nonce")
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), claims.UserID)
+
+	// state だけを別のブラウザに渡しても Cookie の nonce が無ければ受け付けない
+	_, err = svc.VerifyOAuthLinkState(state, "github", "")
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+	_, err = svc.VerifyOAuthLinkState(state, "github", "attacker-nonce")
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+	_, err = svc.VerifyOAuthLinkState(state, "google", "browser-nonce")
+	assert.ErrorIs(t, err, services.ErrInvalidToken)
+}
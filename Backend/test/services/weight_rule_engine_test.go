@@ -0,0 +1,155 @@
+package services_test
+
+// 回答の加点ルールと AI 質問テンプレートのテスト
+//
+// 実行: cd Backend && go test ./test/services/... -run 'WeightRule|QuestionTemplate' -v
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"Backend/internal/models"
+	"Backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func weightRule(id uint, name, condition string, boost, priority int) models.WeightRule {
+	return models.WeightRule{ID: id, Name: name, Condition: condition, WeightBoost: boost, Priority: priority, IsActive: true}
+}
+
+func TestWeightRuleEngine_EvaluatesConditionsInPriorityOrder(t *testing.T) {
+	inactive := weightRule(4, "無効", `{"keywords": ["チーム"]}`, 50, 100)
+	inactive.IsActive = false
+	engine := services.NewWeightRuleEngine([]models.WeightRule{
+		weightRule(1, "チーム", `{"keywords": ["チーム", "協力"], "categories": ["チームワーク志向"]}`, 5, 1),
+		weightRule(2, "適性フェーズの中途", `{"phases": ["aptitude_analysis"], "target_level": "中途"}`, 3, 10),
+		weightRule(3, "低スコアの底上げ", `{"max_answer_score": 40}`, 10, 5),
+		inactive,
+	})
+	require.Equal(t, 3, engine.Len())
+
+	facts := services.WeightRuleFacts{
+		Answer:      "サークルではチームで協力してイベントを運営しました",
+		Category:    "チームワーク志向",
+		Phase:       "aptitude_analysis",
+		TargetLevel: "中途",
+		AnswerScore: 70,
+	}
+	score, fired := engine.Apply(facts)
+	require.Len(t, fired, 2)
+	assert.Equal(t, "適性フェーズの中途", fired[0].Name, "優先度の高い順")
+	assert.Equal(t, "チーム", fired[1].Name)
+	assert.Equal(t, []string{"チーム", "協力"}, fired[1].MatchedKeywords)
+	assert.Equal(t, 78, score)
+
+	// カテゴリ・対象が違えば発火しない
+	facts.Category = "技術志向"
+	facts.TargetLevel = "新卒"
+	assert.Empty(t, engine.Evaluate(facts))
+
+	// 0〜100 に収める
+	score, _ = services.NewWeightRuleEngine([]models.WeightRule{
+		weightRule(1, "大きな加点", `{"categories": ["技術志向"]}`, 50, 0),
+	}).Apply(services.WeightRuleFacts{Category: "技術志向", AnswerScore: 80})
+	assert.Equal(t, 100, score)
+
+	var none *services.WeightRuleEngine
+	score, fired = none.Apply(services.WeightRuleFacts{AnswerScore: 60})
+	assert.Equal(t, 60, score)
+	assert.Empty(t, fired)
+}
+
+func TestWeightRuleEngine_SkipsInvalidRules(t *testing.T) {
+	engine := services.NewWeightRuleEngine([]models.WeightRule{
+		weightRule(1, "知らない項目", `{"industry_count": 0}`, 3, 20),
+		weightRule(2, "有効", `{"phase": "future_analysis"}`, 2, 10),
+	})
+	assert.Equal(t, 1, engine.Len())
+	require.Len(t, engine.InvalidRules(), 1)
+	assert.Equal(t, uint(1), engine.InvalidRules()[0].RuleID)
+
+	invalid := []models.WeightRule{
+		weightRule(0, "", `{"phase": "future_analysis"}`, 1, 0),
+		weightRule(0, "条件なし", `{}`, 1, 0),
+		weightRule(0, "壊れた JSON", `{"keywords": [`, 1, 0),
+		weightRule(0, "範囲外", `{"min_answer_score": 80, "max_answer_score": 20}`, 1, 0),
+		weightRule(0, "加点が大きすぎる", `{"phase": "future_analysis"}`, 500, 0),
+	}
+	for _, rule := range invalid {
+		assert.True(t, errors.Is(services.ValidateWeightRule(rule), services.ErrInvalidWeightRule), rule.Name)
+	}
+}
+
+func TestQuestionRuleService_DryRunShowsFiredRules(t *testing.T) {
+	svc := services.NewQuestionRuleService(nil, nil)
+	result, err := svc.DryRunWeightRules(services.WeightRuleDryRunRequest{
+		Phase:       "aptitude_analysis",
+		TargetLevel: "新卒",
+		Messages: []services.DryRunMessage{
+			{Role: "user", Content: "よろしくお願いします"},
+			{Role: "assistant", Content: "グループ活動での役割に近いものはどれですか？\nA) まとめ役\nB) 支える役\nC) 一人で進める"},
+			{Role: "user", Content: "B", Category: "チームワーク志向"},
+			{Role: "assistant", Content: "最近取り組んだことを教えてください。"},
+			{Role: "user", Content: "わからない"},
+		},
+		Rules: []models.WeightRule{
+			weightRule(0, "適性フェーズのチームワーク", `{"phase": "aptitude_analysis", "categories": ["チームワーク志向"]}`, 5, 1),
+			weightRule(0, "キーワード", `{"keywords": ["プログラミング"]}`, 5, 0),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Turns, 2, "質問より前の発言は評価しない")
+
+	choice := result.Turns[0]
+	assert.Equal(t, "チームワーク志向", choice.Category)
+	assert.Equal(t, 80, choice.BaseScore)
+	assert.Equal(t, 5, choice.Boost)
+	assert.Equal(t, 85, choice.Score)
+	require.Len(t, choice.FiredRules, 1)
+	assert.Equal(t, "適性フェーズのチームワーク", choice.FiredRules[0].Name)
+
+	assert.NotEmpty(t, result.Turns[1].Skipped, "採点しない回答は加点しない")
+	assert.Empty(t, result.Turns[1].FiredRules)
+
+	require.Len(t, result.RuleHits, 1)
+	assert.Equal(t, 1, result.RuleHits[0].Count)
+
+	_, err = svc.DryRunWeightRules(services.WeightRuleDryRunRequest{
+		Messages: []services.DryRunMessage{{Role: "assistant", Content: "質問です"}},
+		Rules:    []models.WeightRule{},
+	})
+	assert.True(t, errors.Is(err, services.ErrEmptyTranscript))
+}
+
+func TestQuestionTemplate_RenderAndValidate(t *testing.T) {
+	template := models.AIQuestionTemplate{
+		Category:    "技術志向",
+		Prompt:      "{{job_category}}を志望する学生に、技術への興味を聞く質問を作ってください",
+		BaseWeight:  8,
+		ContextKeys: `["job_category", "answer_history", "industry_ids", "開発"]`,
+	}
+	require.NoError(t, services.ValidateQuestionTemplate(template))
+
+	rendered := services.RenderQuestionTemplate(template, map[string]string{
+		"job_category":   "Webエンジニア",
+		"answer_history": "個人でアプリを作っています",
+	})
+	assert.Contains(t, rendered, "Webエンジニアを志望する学生に")
+	assert.NotContains(t, rendered, "{{job_category}}")
+	assert.Contains(t, rendered, "- answer_history: 個人でアプリを作っています")
+	assert.NotContains(t, rendered, "industry_ids", "値のない文脈は添えない")
+	assert.True(t, strings.Contains(rendered, "キーワード") && strings.Contains(rendered, "開発"), "知らない名前はキーワードとして扱う")
+
+	invalid := []models.AIQuestionTemplate{
+		{Category: "", Prompt: "質問を作ってください"},
+		{Category: "技術志向", Prompt: " "},
+		{Category: "技術志向", Prompt: "質問を作ってください", BaseWeight: 20},
+		{Category: "技術志向", Prompt: "質問を作ってください", ContextKeys: `{"key": "value"}`},
+	}
+	for _, tpl := range invalid {
+		assert.True(t, errors.Is(services.ValidateQuestionTemplate(tpl), services.ErrInvalidQuestionTemplate), "%+v", tpl)
+	}
+}
@@ -0,0 +1,62 @@
+package totp_test
+
+// RFC 6238 TOTP のテスト
+//
+// 実行: cd Backend && go test ./test/totp/... -v
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"Backend/internal/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret RFC 6238 付録 B の SHA1 用シークレット "12345678901234567890"
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// 付録 B の 8 桁の値の下 6 桁
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range cases {
+		got, err := totp.Code(rfcSecret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "t=%d", unix)
+	}
+}
+
+func TestValidate_AllowsSkewAndReturnsCounter(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	prev, _ := totp.Code(secret, now.Add(-totp.Period))
+	counter, ok := totp.Validate(secret, prev, now, 1)
+	assert.True(t, ok, "1 ステップ前のコードは許容する")
+	assert.Equal(t, totp.Counter(now)-1, counter)
+
+	old, _ := totp.Code(secret, now.Add(-3*totp.Period))
+	_, ok = totp.Validate(secret, old, now, 1)
+	assert.False(t, ok)
+
+	_, ok = totp.Validate(secret, "12345", now, 1)
+	assert.False(t, ok, "桁数が違うコードは拒否する")
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := totp.ProvisioningURI("SOC AI", "teacher@example.com", "JBSWY3DPEHPK3PXP")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/SOC%20AI:teacher@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=SOC+AI")
+}
@@ -0,0 +1,150 @@
+package vectorindex_test
+
+// HNSW インデックスのテスト
+//
+// 実行: cd Backend && go test ./test/vectorindex/... -v
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"Backend/internal/vectorindex"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomVectors(rng *rand.Rand, n, dim int) [][]float32 {
+	vecs := make([][]float32, n)
+	for i := range vecs {
+		vecs[i] = make([]float32, dim)
+		for j := range vecs[i] {
+			vecs[i][j] = float32(rng.NormFloat64())
+		}
+	}
+	return vecs
+}
+
+func bruteForce(vecs [][]float32, query []float32, k int) []uint {
+	type scored struct {
+		id    uint
+		score float64
+	}
+	all := make([]scored, 0, len(vecs))
+	for i, v := range vecs {
+		var d, nv, nq float64
+		for j := range v {
+			d += float64(v[j]) * float64(query[j])
+			nv += float64(v[j]) * float64(v[j])
+			nq += float64(query[j]) * float64(query[j])
+		}
+		all = append(all, scored{id: uint(i + 1), score: d / (math.Sqrt(nv) * math.Sqrt(nq))})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].score > all[j].score })
+	ids := make([]uint, k)
+	for i := range ids {
+		ids[i] = all[i].id
+	}
+	return ids
+}
+
+func TestHNSW_RecallAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	vecs := randomVectors(rng, 1000, 32)
+	index := vectorindex.NewHNSW(vectorindex.Config{})
+	for i, v := range vecs {
+		require.NoError(t, index.Add(uint(i+1), v))
+	}
+	assert.Equal(t, 1000, index.Len())
+
+	const k = 10
+	hits, total := 0, 0
+	for _, q := range randomVectors(rng, 50, 32) {
+		want := map[uint]bool{}
+		for _, id := range bruteForce(vecs, q, k) {
+			want[id] = true
+		}
+		results := index.Search(q, k)
+		require.Len(t, results, k)
+		for i, r := range results {
+			if want[r.ID] {
+				hits++
+			}
+			if i > 0 {
+				assert.GreaterOrEqual(t, results[i-1].Score, r.Score, "類似度の高い順に返す")
+			}
+		}
+		total += k
+	}
+	recall := float64(hits) / float64(total)
+	assert.GreaterOrEqual(t, recall, 0.95, "recall=%.3f", recall)
+}
+
+func TestHNSW_ReplaceAndRemove(t *testing.T) {
+	index := vectorindex.NewHNSW(vectorindex.Config{})
+	require.NoError(t, index.Add(1, []float32{1, 0, 0}))
+	require.NoError(t, index.Add(2, []float32{0, 1, 0}))
+	require.NoError(t, index.Add(3, []float32{0, 0, 1}))
+
+	results := index.Search([]float32{2, 0.1, 0}, 1)
+	require.Len(t, results, 1)
+	assert.Equal(t, uint(1), results[0].ID)
+	assert.InDelta(t, 0.9988, results[0].Score, 0.001, "長さによらずコサイン類似度を返す")
+
+	// 同じ ID を登録し直すと古いベクトルを置き換える
+	require.NoError(t, index.Add(1, []float32{0, 1, 0.1}))
+	assert.Equal(t, 3, index.Len())
+	results = index.Search([]float32{1, 0, 0}, 3)
+	for _, r := range results {
+		assert.Less(t, r.Score, 0.5, "置き換え前のベクトルでは見つからない")
+	}
+
+	index.Remove(2)
+	index.Remove(99)
+	assert.Equal(t, 2, index.Len())
+	for _, r := range index.Search([]float32{0, 1, 0}, 3) {
+		assert.NotEqual(t, uint(2), r.ID)
+	}
+	assert.Empty(t, index.Search([]float32{0, 1, 0}, 0))
+}
+
+func TestHNSW_CompactsAfterManyRemovals(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	vecs := randomVectors(rng, 200, 8)
+	index := vectorindex.NewHNSW(vectorindex.Config{M: 8})
+	for i, v := range vecs {
+		require.NoError(t, index.Add(uint(i+1), v))
+	}
+	for i := 1; i <= 150; i++ {
+		index.Remove(uint(i))
+	}
+	assert.Equal(t, 50, index.Len())
+	results := index.Search(vecs[180], 5)
+	require.NotEmpty(t, results)
+	assert.Equal(t, uint(181), results[0].ID)
+	for _, r := range results {
+		assert.Greater(t, r.ID, uint(150))
+	}
+}
+
+func TestHNSW_DimensionMismatch(t *testing.T) {
+	index := vectorindex.NewHNSW(vectorindex.Config{})
+	require.NoError(t, index.Add(1, []float32{1, 2, 3}))
+	assert.ErrorIs(t, index.Add(2, []float32{1, 2}), vectorindex.ErrDimensionMismatch)
+	assert.Error(t, index.Add(3, nil))
+	assert.Empty(t, index.Search([]float32{1, 2}, 1), "次元数の異なる検索は空")
+}
+
+func TestVectorEncoding_RoundTrip(t *testing.T) {
+	vec := []float32{0.5, -1.25, 3.1415927, 0}
+	raw := vectorindex.EncodeVector(vec)
+	assert.Len(t, raw, 16)
+	decoded, err := vectorindex.DecodeVector(raw)
+	require.NoError(t, err)
+	assert.Equal(t, vec, decoded)
+
+	_, err = vectorindex.DecodeVector([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
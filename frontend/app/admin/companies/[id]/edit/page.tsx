@@ -90,9 +90,8 @@ export default function AdminCompanyEditPage() {
   const [devStyle, setDevStyle] = useState('')
 
   useEffect(() => {
-    const admin = authService.getStoredUser()
     fetch(`/api/admin/companies/${id}`, {
-      headers: { 'X-Admin-Email': admin?.email || '' },
+      headers: { Authorization: authService.authorizationHeader() },
     })
       .then((r) => r.json())
       .then((data) => {
@@ -108,11 +107,10 @@ export default function AdminCompanyEditPage() {
   const handleAiFill = async () => {
     setAiLoading(true)
     setError('')
-    const admin = authService.getStoredUser()
     try {
       const res = await fetch(`/api/admin/companies/${id}/tech-stack-search`, {
         method: 'POST',
-        headers: { 'X-Admin-Email': admin?.email || '' },
+        headers: { Authorization: authService.authorizationHeader() },
       })
       if (!res.ok) {
         const d = await res.json().catch(() => ({}))
@@ -133,12 +131,11 @@ export default function AdminCompanyEditPage() {
   const handleSave = async () => {
     setError('')
     setSuccess('')
-    const admin = authService.getStoredUser()
     const res = await fetch(`/api/admin/companies/${id}`, {
       method: 'PUT',
       headers: {
         'Content-Type': 'application/json',
-        'X-Admin-Email': admin?.email || '',
+        Authorization: authService.authorizationHeader(),
       },
       body: JSON.stringify({
         tech_stack: JSON.stringify(techStack),
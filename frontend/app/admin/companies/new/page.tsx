@@ -37,12 +37,11 @@ export default function AdminCompanyNewPage() {
 
   const handleCreate = async () => {
     setError('')
-    const admin = authService.getStoredUser()
     const res = await fetch('/api/admin/companies', {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-Admin-Email': admin?.email || '',
+        Authorization: authService.authorizationHeader(),
       },
       body: JSON.stringify({
         name,
@@ -110,9 +110,8 @@ export default function AdminCrawlingPage() {
 
   const loadSources = async () => {
     setError('')
-    const admin = authService.getStoredUser()
     const response = await fetch('/api/admin/crawl-sources', {
-      headers: { 'X-Admin-Email': admin?.email || '' },
+      headers: { Authorization: authService.authorizationHeader() },
     })
     const data = await response.json()
     if (!response.ok) {
@@ -123,9 +122,8 @@ export default function AdminCrawlingPage() {
   }
 
   const loadRuns = async () => {
-    const admin = authService.getStoredUser()
     const response = await fetch('/api/admin/crawl-runs', {
-      headers: { 'X-Admin-Email': admin?.email || '' },
+      headers: { Authorization: authService.authorizationHeader() },
     })
     const data = await response.json()
     if (response.ok) {
@@ -141,7 +139,6 @@ export default function AdminCrawlingPage() {
   const handleCreate = async () => {
     setError('')
     setLoading(true)
-    const admin = authService.getStoredUser()
     const payload = {
       name,
       target_type: targetType,
@@ -155,7 +152,7 @@ export default function AdminCrawlingPage() {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-Admin-Email': admin?.email || '',
+        Authorization: authService.authorizationHeader(),
       },
       body: JSON.stringify(payload),
     })
@@ -176,12 +173,11 @@ export default function AdminCrawlingPage() {
   }
 
   const handleToggleActive = async (source: CrawlSource) => {
-    const admin = authService.getStoredUser()
     const response = await fetch(`/api/admin/crawl-sources/${source.id}`, {
       method: 'PUT',
       headers: {
         'Content-Type': 'application/json',
-        'X-Admin-Email': admin?.email || '',
+        Authorization: authService.authorizationHeader(),
       },
       body: JSON.stringify({ is_active: !source.is_active }),
     })
@@ -191,11 +187,10 @@ export default function AdminCrawlingPage() {
   }
 
   const handleRun = async (source: CrawlSource) => {
-    const admin = authService.getStoredUser()
     const response = await fetch(`/api/admin/crawl-sources/${source.id}/run`, {
       method: 'POST',
       headers: {
-        'X-Admin-Email': admin?.email || '',
+        Authorization: authService.authorizationHeader(),
       },
     })
     if (response.ok) {
@@ -207,12 +202,11 @@ export default function AdminCrawlingPage() {
   const handleGraphCrawl = async () => {
     setGraphLoading(true)
     setGraphResult(null)
-    const admin = authService.getStoredUser()
     const response = await fetch('/api/admin/company-graph-crawl', {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-Admin-Email': admin?.email || '',
+        Authorization: authService.authorizationHeader(),
       },
       body: JSON.stringify({
         sites: graphSites,
@@ -232,10 +226,9 @@ export default function AdminCrawlingPage() {
     setGbizSearchError('')
     setGbizSearchResults([])
     setGbizRegisterMessage('')
-    const admin = authService.getStoredUser()
     const response = await fetch(
       `/api/admin/companies/search-gbiz?name=${encodeURIComponent(gbizSearchName)}`,
-      { headers: { 'X-Admin-Email': admin?.email || '' } },
+      { headers: { Authorization: authService.authorizationHeader() } },
     )
     const data = await response.json()
     if (!response.ok) {
@@ -252,12 +245,11 @@ export default function AdminCrawlingPage() {
   const handleGbizRegister = async (result: { corporate_number: string; name: string; location: string; company_url: string; employee_number: number }) => {
     setGbizRegisterLoading(result.corporate_number)
     setGbizRegisterMessage('')
-    const admin = authService.getStoredUser()
     const response = await fetch('/api/admin/companies', {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-Admin-Email': admin?.email || '',
+        Authorization: authService.authorizationHeader(),
       },
       body: JSON.stringify({
         name: result.name,
@@ -40,20 +40,18 @@ export default function AdminGraduateEmploymentNewPage() {
   const [note, setNote] = useState('')
 
   useEffect(() => {
-    const admin = authService.getStoredUser()
-    const headers = { 'X-Admin-Email': admin?.email || '' }
+    const headers = { Authorization: authService.authorizationHeader() }
     fetch('/api/admin/companies', { headers }).then((r) => r.json()).then((d) => setCompanies(d?.companies || []))
     fetch('/api/admin/job-positions?limit=100', { headers }).then((r) => r.json()).then((d) => setJobPositions(d?.positions || []))
   }, [])
 
   const handleCreate = async () => {
     setError('')
-    const admin = authService.getStoredUser()
     const res = await fetch('/api/admin/graduate-employments', {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-Admin-Email': admin?.email || '',
+        Authorization: authService.authorizationHeader(),
       },
       body: JSON.stringify({
         company_id: Number(companyId),
@@ -70,7 +70,7 @@ export default function AdminInterviewDetailPage() {
       setLoading(true)
       setError('')
       const response = await fetch(`/api/admin/interviews/${sessionId}/videos`, {
-        headers: { 'X-Admin-Email': admin.email || '' },
+        headers: { Authorization: authService.authorizationHeader() },
       })
       const data = await response.json()
       setLoading(false)
@@ -88,9 +88,8 @@ export default function AdminInterviewDetailPage() {
     setUrlLoading(video.id)
     setUrlError('')
     setPlayingURL(null)
-    const admin = authService.getStoredUser()
     const response = await fetch(`/api/admin/interviews/${sessionId}/videos/${video.id}/url`, {
-      headers: { 'X-Admin-Email': admin?.email || '' },
+      headers: { Authorization: authService.authorizationHeader() },
     })
     const data = await response.json()
     setUrlLoading(null)
@@ -66,7 +66,7 @@ export default function AdminInterviewsPage() {
         limit: String(rowsPerPage),
       })
       const response = await fetch(`/api/admin/interviews?${params}`, {
-        headers: { 'X-Admin-Email': admin.email || '' },
+        headers: { Authorization: authService.authorizationHeader() },
       })
       const data = await response.json()
       if (!response.ok) {
@@ -62,9 +62,8 @@ export default function AdminUsersPage() {
       })
       if (query.trim()) params.set('q', query.trim())
 
-      const admin = authService.getStoredUser()
       const response = await fetch(`/api/admin/users?${params}`, {
-        headers: { 'X-Admin-Email': admin?.email || '' },
+        headers: { Authorization: authService.authorizationHeader() },
       })
       const data = await response.json()
       if (cancelled) return
@@ -86,7 +85,7 @@ export default function AdminUsersPage() {
       method: 'PUT',
       headers: {
         'Content-Type': 'application/json',
-        'X-Admin-Email': admin.email,
+        Authorization: authService.authorizationHeader(),
       },
       body: JSON.stringify({ is_admin: !user.is_admin }),
     })
@@ -9,7 +9,7 @@ export async function PATCH(request: NextRequest, { params }: { params: Promise<
   const response = await fetch(`${BACKEND_URL}/api/admin/companies/${id}/publish`, {
     method: 'PATCH',
     headers: {
-      'X-Admin-Email': request.headers.get('x-admin-email') || '',
+      Authorization: request.headers.get('authorization') || '',
     },
   })
   const raw = await response.text()
@@ -10,7 +10,7 @@ export async function GET(
 ) {
   const { id } = await params
   const response = await fetch(`${BACKEND_URL}/api/admin/companies/${id}`, {
-    headers: { 'X-Admin-Email': request.headers.get('x-admin-email') || '' },
+    headers: { Authorization: request.headers.get('authorization') || '' },
   })
   const raw = await response.text()
   let data: any = {}
@@ -32,7 +32,7 @@ export async function PUT(
   const body = await request.text()
   const response = await fetch(`${BACKEND_URL}/api/admin/companies/${id}`, {
     method: 'PUT',
-    headers: { 'Content-Type': 'application/json', 'X-Admin-Email': request.headers.get('x-admin-email') || '' },
+    headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
     body,
   })
   const raw = await response.text()
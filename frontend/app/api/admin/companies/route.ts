@@ -7,7 +7,7 @@ export const dynamic = 'force-dynamic'
 export async function GET(request: NextRequest) {
   const query = request.nextUrl.searchParams.toString()
   const response = await fetch(`${BACKEND_URL}/api/admin/companies${query ? `?${query}` : ''}`, {
-    headers: { 'X-Admin-Email': request.headers.get('x-admin-email') || '' },
+    headers: { Authorization: request.headers.get('authorization') || '' },
   })
   const raw = await response.text()
   let data: any = {}
@@ -27,7 +27,7 @@ export async function POST(request: NextRequest) {
     method: 'POST',
     headers: {
       'Content-Type': 'application/json',
-      'X-Admin-Email': request.headers.get('x-admin-email') || '',
+      Authorization: request.headers.get('authorization') || '',
     },
     body,
   })
@@ -9,7 +9,7 @@ export async function GET(request: NextRequest) {
   const response = await fetch(
     `${BACKEND_URL}/api/admin/companies/search-gbiz?name=${encodeURIComponent(name)}`,
     {
-      headers: { 'X-Admin-Email': request.headers.get('x-admin-email') || '' },
+      headers: { Authorization: request.headers.get('authorization') || '' },
     },
   )
   const raw = await response.text()
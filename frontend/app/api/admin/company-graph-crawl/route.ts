@@ -13,7 +13,7 @@ export async function POST(request: NextRequest) {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
-        'X-Admin-Email': request.headers.get('X-Admin-Email') ?? '',
+        Authorization: request.headers.get('authorization') || '',
       },
       body: JSON.stringify(body),
       signal: AbortSignal.timeout(310_000), // 5 min + buffer
@@ -5,9 +5,9 @@ const BACKEND_URL = process.env.BACKEND_URL || 'http://app:8080'
 export const dynamic = 'force-dynamic'
 
 export async function GET(request: NextRequest) {
-  const admin = request.headers.get('x-admin-email') || ''
+  const authorization = request.headers.get('authorization') || ''
   const res = await fetch(`${BACKEND_URL}/api/admin/costs/summary`, {
-    headers: { 'X-Admin-Email': admin },
+    headers: { Authorization: authorization },
   })
   const data = await res.json()
   return NextResponse.json(data, { status: res.status })
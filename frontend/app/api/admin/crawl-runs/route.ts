@@ -9,7 +9,7 @@ export async function GET(request: NextRequest) {
   const sourceId = url.searchParams.get('source_id')
   const query = sourceId ? `?source_id=${sourceId}` : ''
   const response = await fetch(`${BACKEND_URL}/api/admin/crawl-runs${query}`, {
-    headers: { 'X-Admin-Email': request.headers.get('x-admin-email') || '' },
+    headers: { Authorization: request.headers.get('authorization') || '' },
   })
   const raw = await response.text()
   let data: any = {}
@@ -11,7 +11,7 @@ export async function PUT(request: NextRequest, { params }: { params: Promise<{
     method: 'PUT',
     headers: {
       'Content-Type': 'application/json',
-      'X-Admin-Email': request.headers.get('x-admin-email') || '',
+      Authorization: request.headers.get('authorization') || '',
     },
     body,
   })
@@ -9,7 +9,7 @@ export async function POST(request: Request, { params }: { params: Promise<{ id:
   const response = await fetch(`${BACKEND_URL}/api/admin/crawl-sources/${id}/run`, {
     method: 'POST',
     headers: {
-      'X-Admin-Email': request.headers.get('x-admin-email') || '',
+      Authorization: request.headers.get('authorization') || '',
     },
   })
   const raw = await response.text()
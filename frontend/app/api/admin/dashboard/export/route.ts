@@ -5,10 +5,10 @@ const BACKEND_URL = process.env.BACKEND_URL || 'http://app:8080'
 export const dynamic = 'force-dynamic'
 
 export async function GET(request: NextRequest) {
-  const admin = request.headers.get('x-admin-email') || ''
+  const authorization = request.headers.get('authorization') || ''
 
   const res = await fetch(`${BACKEND_URL}/api/admin/dashboard/export/csv`, {
-    headers: { 'X-Admin-Email': admin },
+    headers: { Authorization: authorization },
   })
   if (!res.ok) {
     return NextResponse.json({ error: 'Export failed' }, { status: res.status })
@@ -9,10 +9,10 @@ export async function GET(
   { params }: { params: Promise<{ id: string }> }
 ) {
   const { id } = await params
-  const admin = request.headers.get('x-admin-email') || ''
+  const authorization = request.headers.get('authorization') || ''
 
   const res = await fetch(`${BACKEND_URL}/api/admin/dashboard/users/${id}/sessions`, {
-    headers: { 'X-Admin-Email': admin },
+    headers: { Authorization: authorization },
   })
   const data = await res.json()
   return NextResponse.json(data, { status: res.status })
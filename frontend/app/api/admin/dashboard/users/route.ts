@@ -5,12 +5,12 @@ const BACKEND_URL = process.env.BACKEND_URL || 'http://app:8080'
 export const dynamic = 'force-dynamic'
 
 export async function GET(request: NextRequest) {
-  const admin = request.headers.get('x-admin-email') || ''
+  const authorization = request.headers.get('authorization') || ''
   const { searchParams } = new URL(request.url)
   const qs = searchParams.toString()
 
   const res = await fetch(`${BACKEND_URL}/api/admin/dashboard/users${qs ? '?' + qs : ''}`, {
-    headers: { 'X-Admin-Email': admin },
+    headers: { Authorization: authorization },
   })
   const data = await res.json()
   return NextResponse.json(data, { status: res.status })
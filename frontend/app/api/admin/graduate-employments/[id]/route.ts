@@ -7,7 +7,7 @@ export const dynamic = 'force-dynamic'
 export async function GET(req: NextRequest, { params }: { params: Promise<{ id: string }> }) {
   const { id } = await params
   const response = await fetch(`${BACKEND_URL}/api/admin/graduate-employments/${id}`, {
-    headers: { 'X-Admin-Email': req.headers.get('x-admin-email') || '' },
+    headers: { Authorization: req.headers.get('authorization') || '' },
   })
   const raw = await response.text()
   let data: any = {}
@@ -24,7 +24,7 @@ export async function PUT(request: NextRequest, { params }: { params: Promise<{
     method: 'PUT',
     headers: {
       'Content-Type': 'application/json',
-      'X-Admin-Email': request.headers.get('x-admin-email') || '',
+      Authorization: request.headers.get('authorization') || '',
     },
     body,
   })
@@ -11,7 +11,7 @@ export async function GET(
   const resolvedParams = await params
   const url = `${BACKEND_URL}/api/admin/interviews/${resolvedParams.id}/videos`
   const response = await fetch(url, {
-    headers: { 'X-Admin-Email': request.headers.get('x-admin-email') || '' },
+    headers: { Authorization: request.headers.get('authorization') || '' },
   })
   const data = await response.json().catch(() => ({}))
   return NextResponse.json(data, { status: response.status })
@@ -13,7 +13,7 @@ export async function GET(request: NextRequest) {
   }
   const url = `${BACKEND_URL}/api/admin/interviews?${params}`
   const response = await fetch(url, {
-    headers: { 'X-Admin-Email': request.headers.get('x-admin-email') || '' },
+    headers: { Authorization: request.headers.get('authorization') || '' },
   })
   const raw = await response.text()
   let data: any = {}
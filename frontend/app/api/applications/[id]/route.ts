@@ -8,7 +8,7 @@ export async function PUT(request: NextRequest, { params }: { params: Promise<{
     const body = await request.json()
     const response = await fetch(`${BACKEND_URL}/api/applications/${id}`, {
       method: 'PUT',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
       body: JSON.stringify(body),
     })
     const text = await response.text()
@@ -7,7 +7,7 @@ export async function POST(request: NextRequest) {
     const body = await request.json()
     const response = await fetch(`${BACKEND_URL}/api/applications`, {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
       body: JSON.stringify(body),
     })
     const text = await response.text()
@@ -28,7 +28,9 @@ export async function GET(request: NextRequest) {
     if (!userId) {
       return NextResponse.json({ error: 'user_id is required' }, { status: 400 })
     }
-    const response = await fetch(`${BACKEND_URL}/api/applications?user_id=${userId}`)
+    const response = await fetch(`${BACKEND_URL}/api/applications?user_id=${userId}`, {
+      headers: { Authorization: request.headers.get('authorization') || '' },
+    })
     const text = await response.text()
     if (!response.ok) {
       return NextResponse.json({ error: text }, { status: response.status })
@@ -11,6 +11,7 @@ export async function DELETE(request: NextRequest) {
     }
 
     const response = await fetch(`${BACKEND_URL}/api/auth/account?user_id=${userId}`, {
+      headers: { Authorization: request.headers.get('authorization') || '' },
       method: 'DELETE',
     })
 
@@ -11,7 +11,9 @@ export async function GET(request: NextRequest) {
     const v = searchParams.get(key)
     if (v !== null) params.set(key, v)
   }
-  const response = await fetch(`${BACKEND_URL}/api/chat/analysis?${params}`)
+  const response = await fetch(`${BACKEND_URL}/api/chat/analysis?${params}`, {
+    headers: { Authorization: request.headers.get('authorization') || '' },
+  })
   const raw = await response.text()
   let data: any = {}
   if (raw) {
@@ -8,7 +8,7 @@ export async function POST(request: NextRequest) {
 
     const response = await fetch(`${BACKEND_URL}/api/chat/favorite`, {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
       body: JSON.stringify(body),
     })
 
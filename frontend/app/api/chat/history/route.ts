@@ -19,6 +19,7 @@ export async function GET(request: NextRequest) {
     const response = await fetch(`${BACKEND_URL}/api/chat/history?session_id=${sessionId}`, {
       method: 'GET',
       headers: {
+        Authorization: request.headers.get('authorization') || '',
         'Content-Type': 'application/json',
       },
     })
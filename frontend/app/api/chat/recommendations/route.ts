@@ -23,6 +23,7 @@ export async function GET(request: NextRequest) {
       {
         method: 'GET',
         headers: {
+          Authorization: request.headers.get('authorization') || '',
           'Content-Type': 'application/json',
         },
       }
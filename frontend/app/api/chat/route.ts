@@ -9,6 +9,7 @@ export async function POST(request: NextRequest) {
     const response = await fetch(`${BACKEND_URL}/api/chat`, {
       method: 'POST',
       headers: {
+        Authorization: request.headers.get('authorization') || '',
         'Content-Type': 'application/json',
       },
       body: JSON.stringify(body),
@@ -14,6 +14,7 @@ export async function GET(
     const response = await fetch(`${BACKEND_URL}/api/companies/${id}`, {
       method: 'GET',
       headers: {
+        Authorization: request.headers.get('authorization') || '',
         'Content-Type': 'application/json',
       },
     })
@@ -13,7 +13,7 @@ export async function POST(request: NextRequest) {
 
     const response = await fetch(`${RAG_URL}/company/hints`, {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
       body: JSON.stringify({ company_name: company_name.trim(), position: position || '' }),
       signal: AbortSignal.timeout(30000),
     })
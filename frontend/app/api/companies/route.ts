@@ -27,6 +27,7 @@ export async function GET(request: NextRequest) {
     const response = await fetch(url, {
       method: 'GET',
       headers: {
+        Authorization: request.headers.get('authorization') || '',
         'Content-Type': 'application/json',
       },
       cache: 'no-store',
@@ -14,7 +14,7 @@ export async function GET(request: NextRequest) {
     const url = `${API_BASE_URL}/api/companies/web-search?q=${encodeURIComponent(q)}`
     const response = await fetch(url, {
       method: 'GET',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
       cache: 'no-store',
     })
 
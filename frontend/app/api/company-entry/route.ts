@@ -8,7 +8,7 @@ export async function POST(request: NextRequest) {
   const body = await request.text()
   const response = await fetch(`${BACKEND_URL}/api/company-entry`, {
     method: 'POST',
-    headers: { 'Content-Type': 'application/json' },
+    headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
     body,
   })
   const raw = await response.text()
@@ -12,7 +12,9 @@ export async function GET(request: NextRequest) {
       return NextResponse.json({ error: 'document_id is required' }, { status: 400 })
     }
 
-    const response = await fetch(`${BACKEND_URL}/api/resume/annotated?document_id=${documentId}`)
+    const response = await fetch(`${BACKEND_URL}/api/resume/annotated?document_id=${documentId}`, {
+      headers: { Authorization: request.headers.get('authorization') || '' },
+    })
     if (!response.ok) {
       const data = await response.json().catch(() => ({}))
       return NextResponse.json(data, { status: response.status })
@@ -19,6 +19,7 @@ export async function POST(request: NextRequest) {
     }
 
     const response = await fetch(`${BACKEND_URL}/api/resume/review?document_id=${documentId}`, {
+      headers: { Authorization: request.headers.get('authorization') || '' },
       method: 'POST',
       headers,
       body: body || undefined,
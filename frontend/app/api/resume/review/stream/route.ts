@@ -17,7 +17,7 @@ export async function POST(request: NextRequest) {
     `${BACKEND_URL}/api/resume/review/stream?document_id=${documentId}`,
     {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
       body: body || undefined,
     }
   )
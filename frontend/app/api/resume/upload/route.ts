@@ -8,6 +8,7 @@ export async function POST(request: NextRequest) {
   try {
     const formData = await request.formData()
     const response = await fetch(`${BACKEND_URL}/api/resume/upload`, {
+      headers: { Authorization: request.headers.get('authorization') || '' },
       method: 'POST',
       body: formData,
     })
@@ -11,7 +11,9 @@ export async function GET(request: NextRequest, { params }: { params: Promise<{
     const userId = searchParams.get('user_id')
     if (!userId) return NextResponse.json({ error: 'user_id is required' }, { status: 400 })
 
-    const res = await fetch(`${BACKEND_URL}/api/schedule/${id}?user_id=${userId}`)
+    const res = await fetch(`${BACKEND_URL}/api/schedule/${id}?user_id=${userId}`, {
+      headers: { Authorization: request.headers.get('authorization') || '' },
+    })
     if (!res.ok) {
       const errorText = await res.text()
       return NextResponse.json({ error: errorText.trim() }, { status: res.status })
@@ -37,7 +39,7 @@ export async function PUT(request: NextRequest, { params }: { params: Promise<{
     const body = await request.text()
     const res = await fetch(`${BACKEND_URL}/api/schedule/${id}?user_id=${userId}`, {
       method: 'PUT',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
       body,
     })
     if (!res.ok) {
@@ -63,6 +65,7 @@ export async function DELETE(request: NextRequest, { params }: { params: Promise
     if (!userId) return NextResponse.json({ error: 'user_id is required' }, { status: 400 })
 
     const res = await fetch(`${BACKEND_URL}/api/schedule/${id}?user_id=${userId}`, {
+      headers: { Authorization: request.headers.get('authorization') || '' },
       method: 'DELETE',
     })
     if (res.status === 204) return new NextResponse(null, { status: 204 })
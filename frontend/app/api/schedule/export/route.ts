@@ -9,7 +9,9 @@ export async function GET(request: NextRequest) {
   const userId = searchParams.get('user_id')
   if (!userId) return NextResponse.json({ error: 'user_id is required' }, { status: 400 })
 
-  const res = await fetch(`${BACKEND_URL}/api/schedule/export/ics?user_id=${userId}`)
+  const res = await fetch(`${BACKEND_URL}/api/schedule/export/ics?user_id=${userId}`, {
+      headers: { Authorization: request.headers.get('authorization') || '' },
+    })
   if (!res.ok) {
     return NextResponse.json({ error: 'Export failed' }, { status: res.status })
   }
@@ -10,7 +10,9 @@ export async function GET(request: NextRequest) {
     const userId = searchParams.get('user_id')
     if (!userId) return NextResponse.json({ error: 'user_id is required' }, { status: 400 })
 
-    const res = await fetch(`${BACKEND_URL}/api/schedule?user_id=${userId}`)
+    const res = await fetch(`${BACKEND_URL}/api/schedule?user_id=${userId}`, {
+      headers: { Authorization: request.headers.get('authorization') || '' },
+    })
     if (!res.ok) {
       const errorText = await res.text()
       return NextResponse.json({ error: errorText.trim() }, { status: res.status })
@@ -35,7 +37,7 @@ export async function POST(request: NextRequest) {
     const body = await request.text()
     const res = await fetch(`${BACKEND_URL}/api/schedule?user_id=${userId}`, {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', Authorization: request.headers.get('authorization') || '' },
       body,
     })
     if (!res.ok) {
@@ -1,16 +1,60 @@
 'use client'
 
-import { useEffect, useState, Suspense } from 'react'
+import { useEffect, useRef, useState, Suspense } from 'react'
 import { useRouter, useSearchParams } from 'next/navigation'
-import { Box, CircularProgress, Typography, Alert } from '@mui/material'
-import { authService } from '@/lib/auth'
-
-const BACKEND_URL = process.env.NEXT_PUBLIC_BACKEND_URL || 'http://localhost:80'
+import { Box, Button, CircularProgress, TextField, Typography, Alert } from '@mui/material'
+import { authService, type AuthResponse } from '@/lib/auth'
 
 function OAuthCallbackContent() {
   const router = useRouter()
   const searchParams = useSearchParams()
   const [error, setError] = useState('')
+  // 2段階認証を有効にしているユーザーは認証コードの入力を待つ
+  const [mfaToken, setMfaToken] = useState('')
+  const [mfaCode, setMfaCode] = useState('')
+  const [mfaError, setMfaError] = useState('')
+  const [verifying, setVerifying] = useState(false)
+  // ログインコードは一度しか交換できないため、effect の再実行で二重に交換しない
+  const exchanged = useRef(false)
+
+  const finishLogin = async (session: AuthResponse) => {
+    let userData = session
+    authService.saveAuth(userData)
+    try {
+      const fresh = await authService.getUser()
+      userData = { ...userData, ...fresh }
+    } catch {
+      // ignore and fall back to exchanged session
+    }
+
+    // ローカルストレージに保存
+    authService.saveAuth(userData)
+    localStorage.removeItem('oauth_state')
+
+    const needsOnboarding =
+      (userData.target_level !== '新卒' && userData.target_level !== '中途') ||
+      !userData.school_name
+    // 必須情報が未設定ならオンボーディングへ
+    if (needsOnboarding) {
+      router.push('/onboarding')
+      return
+    }
+
+    router.push('/')
+  }
+
+  const handleVerifyMFA = async () => {
+    setVerifying(true)
+    setMfaError('')
+    try {
+      const session = await authService.completeMFALogin(mfaToken, mfaCode.trim())
+      await finishLogin(session)
+    } catch {
+      setMfaError('認証コードが正しくありません')
+    } finally {
+      setVerifying(false)
+    }
+  }
 
   useEffect(() => {
     const handleCallback = async () => {
@@ -20,48 +64,26 @@ function OAuthCallbackContent() {
         return
       }
 
-      const userParam = searchParams.get('user')
-      const provider = searchParams.get('provider')
-      
-      if (!userParam) {
+      const loginCode = searchParams.get('login_code')
+
+      if (!loginCode) {
         setError('ユーザー情報が見つかりません')
         return
       }
 
-      try {
-        // Base64デコードしてユーザー情報を取得
-        // Properly handle URL-safe Base64 and UTF-8
-        const normalized = userParam.replace(/-/g, '+').replace(/_/g, '/')
-        const binary = atob(normalized)
-        const bytes = Uint8Array.from(binary, c => c.charCodeAt(0))
-        const userDataString = new TextDecoder('utf-8').decode(bytes)
-        const userDataRaw = JSON.parse(userDataString)
-        // Fallback repair for mojibake in name
-        const fixMojibake = (s: string) => /[Ãå][^\s]/.test(s) ? decodeURIComponent(escape(s)) : s
-        let userData = { ...userDataRaw, name: fixMojibake(userDataRaw.name) }
-        try {
-          const fresh = await authService.getUser(
-            typeof userData.user_id === 'string' ? Number(userData.user_id) : userData.user_id,
-          )
-          userData = { ...userData, ...fresh }
-        } catch {
-          // ignore and fall back to callback payload
-        }
-
-        // ローカルストレージに保存
-        authService.saveAuth(userData)
-        localStorage.removeItem('oauth_state')
+      if (exchanged.current) return
+      exchanged.current = true
 
-        const needsOnboarding =
-          (userData.target_level !== '新卒' && userData.target_level !== '中途') ||
-          !userData.school_name
-        // 必須情報が未設定ならオンボーディングへ
-        if (needsOnboarding) {
-          router.push('/onboarding')
+      try {
+        // 一度きりのコードをセッション（トークンを含むユーザー情報）と交換する
+        const session = await authService.exchangeOAuthCode(loginCode)
+        // URL からコードを消す（履歴に残さない）
+        window.history.replaceState(null, '', window.location.pathname)
+        if (session.mfa_required && session.mfa_token) {
+          setMfaToken(session.mfa_token)
           return
         }
-
-        router.push('/')
+        await finishLogin(session)
       } catch (err: any) {
         setError('認証データの処理に失敗しました: ' + err.message)
       }
@@ -97,6 +119,38 @@ function OAuthCallbackContent() {
     )
   }
 
+  if (mfaToken) {
+    return (
+      <Box
+        sx={{
+          display: 'flex',
+          flexDirection: 'column',
+          alignItems: 'center',
+          justifyContent: 'center',
+          minHeight: '100vh',
+          gap: 2,
+          p: 3,
+        }}
+      >
+        <Typography variant="h6">2段階認証</Typography>
+        <Typography variant="body2" color="text.secondary">
+          認証アプリに表示された6桁のコード、またはリカバリーコードを入力してください
+        </Typography>
+        {mfaError && <Alert severity="error">{mfaError}</Alert>}
+        <TextField
+          label="認証コード"
+          value={mfaCode}
+          onChange={(e) => setMfaCode(e.target.value)}
+          autoComplete="one-time-code"
+          autoFocus
+        />
+        <Button variant="contained" onClick={handleVerifyMFA} disabled={verifying || !mfaCode.trim()}>
+          {verifying ? '確認中...' : 'ログイン'}
+        </Button>
+      </Box>
+    )
+  }
+
   return (
     <Box
       sx={{
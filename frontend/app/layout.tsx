@@ -1,6 +1,7 @@
 import './globals.css'
 import type { Metadata } from 'next'
 import { MuiProvider } from '@/components/mui-provider'
+import { AuthFetch } from '@/components/auth-fetch'
 import { Analytics } from '@vercel/analytics/react'
 
 export const metadata: Metadata = {
@@ -19,6 +20,7 @@ export default function RootLayout({
         <meta charSet="UTF-8" />
       </head>
       <body style={{ margin: 0, padding: 0 }}>
+        <AuthFetch />
         <MuiProvider>
           {children}
         </MuiProvider>
@@ -87,7 +87,6 @@ export default function ProfilePage() {
     try {
       const finalSchoolName = schoolOption === 'other' ? schoolName : schoolOption
       const response = await authService.updateProfile(
-        user.user_id,
         name,
         targetLevel,
         finalSchoolName,
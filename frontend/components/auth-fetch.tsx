@@ -0,0 +1,10 @@
+'use client'
+
+import { installAuthFetch } from '@/lib/auth'
+
+// バックエンドへの fetch に Authorization ヘッダーを付ける（各ページの effect より先に読み込まれるよう、モジュール読み込み時に設定する）
+installAuthFetch()
+
+export function AuthFetch() {
+  return null
+}
@@ -273,7 +273,7 @@ export default function GitHubSkills({ userId }: { userId: number }) {
   const handleConnect = async () => {
     setConnecting(true)
     try {
-      const res = await fetch(`${BACKEND_URL}/api/auth/github`)
+      const res = await fetch(`${BACKEND_URL}/api/auth/github`, { credentials: 'include' })
       if (!res.ok) throw new Error()
       const { auth_url } = await res.json()
       window.location.href = auth_url
@@ -47,6 +47,9 @@ export interface AuthResponse {
   oauth_provider?: string
   avatar_url?: string
   token?: string
+  refresh_token?: string
+  mfa_required?: boolean
+  mfa_token?: string
 }
 
 export const authService = {
@@ -125,14 +128,62 @@ export const authService = {
     return res.json()
   },
 
-  async getUser(userId: number): Promise<User> {
-    const res = await fetch(`${BACKEND_URL}/api/auth/user?user_id=${userId}`)
+  async getUser(): Promise<User> {
+    const res = await fetch(`${BACKEND_URL}/api/auth/user`, {
+      headers: { Authorization: authService.authorizationHeader() },
+    })
     if (!res.ok) throw new Error('Failed to get user')
     return res.json()
   },
 
+  // OAuth コールバックで受け取った一度きりのコードをセッションと交換する
+  async exchangeOAuthCode(code: string): Promise<AuthResponse> {
+    const res = await fetch(`${BACKEND_URL}/api/auth/oauth/exchange`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ code }),
+    })
+    if (!res.ok) {
+      const error = await res.text()
+      throw new Error(error || 'Failed to exchange login code')
+    }
+    return res.json()
+  },
+
+  // 2段階認証コード（またはリカバリーコード）を検証してログインを完了する
+  async completeMFALogin(mfaToken: string, code: string): Promise<AuthResponse> {
+    const res = await fetch(`${BACKEND_URL}/api/auth/login/mfa`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ mfa_token: mfaToken, code }),
+    })
+    if (!res.ok) {
+      const error = await res.text()
+      throw new Error(error || 'Failed to verify code')
+    }
+    return res.json()
+  },
+
+  // リフレッシュトークンでアクセストークンを再発行する（失敗時は false）
+  async refreshSession(): Promise<boolean> {
+    const refreshToken = localStorage.getItem('refresh_token')
+    if (!refreshToken) return false
+    const res = await fetch(`${BACKEND_URL}/api/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken }),
+    })
+    if (!res.ok) return false
+    const data: AuthResponse = await res.json()
+    if (!data.token) return false
+    localStorage.setItem('token', data.token)
+    if (data.refresh_token) {
+      localStorage.setItem('refresh_token', data.refresh_token)
+    }
+    return true
+  },
+
   async updateProfile(
-    userId: number,
     name: string,
     targetLevel: string,
     schoolName: string,
@@ -141,9 +192,8 @@ export const authService = {
   ): Promise<AuthResponse> {
     const res = await fetch(`${BACKEND_URL}/api/auth/profile`, {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', Authorization: authService.authorizationHeader() },
       body: JSON.stringify({
-        user_id: userId,
         name,
         target_level: targetLevel,
         school_name: schoolName,
@@ -183,13 +233,13 @@ export const authService = {
   },
 
   async getGoogleAuthUrl(): Promise<{ auth_url: string; state: string }> {
-    const res = await fetch(`${BACKEND_URL}/api/auth/google`)
+    const res = await fetch(`${BACKEND_URL}/api/auth/google`, { credentials: 'include' })
     if (!res.ok) throw new Error('Failed to get Google auth URL')
     return res.json()
   },
 
   async getGithubAuthUrl(): Promise<{ auth_url: string; state: string }> {
-    const res = await fetch(`${BACKEND_URL}/api/auth/github`)
+    const res = await fetch(`${BACKEND_URL}/api/auth/github`, { credentials: 'include' })
     if (!res.ok) throw new Error('Failed to get GitHub auth URL')
     return res.json()
   },
@@ -212,6 +262,9 @@ export const authService = {
     if (authResponse.token) {
       localStorage.setItem('token', authResponse.token)
     }
+    if (authResponse.refresh_token) {
+      localStorage.setItem('refresh_token', authResponse.refresh_token)
+    }
   },
 
   getStoredUser(): User | null {
@@ -230,10 +283,18 @@ export const authService = {
     return localStorage.getItem('token')
   },
 
+  // API 呼び出しに付ける Authorization ヘッダーの値（未ログイン時は空文字）
+  authorizationHeader(): string {
+    if (typeof window === 'undefined') return ''
+    const token = localStorage.getItem('token')
+    return token ? `Bearer ${token}` : ''
+  },
+
   logout() {
     // ユーザー情報とトークンを削除
     localStorage.removeItem('user')
     localStorage.removeItem('token')
+    localStorage.removeItem('refresh_token')
     
     // チャットキャッシュを削除
     const sessionId = localStorage.getItem('chat_session_id')
@@ -243,3 +304,48 @@ export const authService = {
     localStorage.removeItem('chat_session_id')
   },
 }
+
+let authFetchInstalled = false
+
+// installAuthFetch はバックエンド（/api/ と NEXT_PUBLIC_BACKEND_URL）への fetch に
+// Authorization ヘッダーを付け、401 のときは一度だけトークンを再発行して再送する
+export function installAuthFetch() {
+  if (typeof window === 'undefined' || authFetchInstalled) return
+  authFetchInstalled = true
+  const originalFetch = window.fetch.bind(window)
+
+  const isBackendRequest = (url: string) => {
+    if (url.startsWith('/api/')) return true
+    if (url.startsWith(`${BACKEND_URL}/api/`)) return true
+    return url.startsWith(`${window.location.origin}/api/`)
+  }
+  const isAuthEndpoint = (url: string) =>
+    /\/api\/auth\/(refresh|login|oauth\/exchange|guest$|register|request-registration|forgot-password|reset-password)/.test(url)
+
+  const withAuthorization = (init: RequestInit | undefined): RequestInit => {
+    const headers = new Headers(init?.headers)
+    const authorization = authService.authorizationHeader()
+    if (authorization && !headers.has('Authorization')) {
+      headers.set('Authorization', authorization)
+    }
+    return { ...init, headers }
+  }
+
+  window.fetch = async (input: RequestInfo | URL, init?: RequestInit) => {
+    // Request オブジェクトはヘッダーを持っているためそのまま送る
+    if (typeof input !== 'string' && !(input instanceof URL)) {
+      return originalFetch(input, init)
+    }
+    const url = input.toString()
+    if (!isBackendRequest(url) || isAuthEndpoint(url)) {
+      return originalFetch(input, init)
+    }
+    const res = await originalFetch(input, withAuthorization(init))
+    if (res.status !== 401 || !(await authService.refreshSession())) {
+      return res
+    }
+    const headers = new Headers(init?.headers)
+    headers.set('Authorization', authService.authorizationHeader())
+    return originalFetch(input, { ...init, headers })
+  }
+}